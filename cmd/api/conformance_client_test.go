@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Client is a minimal API client used to drive a server under test.
+type Client struct {
+	baseURL  string
+	http     *http.Client
+	token    string
+	UserID   string
+	Username string
+}
+
+// Message mirrors the wire format of a chat message.
+type Message struct {
+	ID          string    `json:"id,omitempty"`
+	ClientMsgID string    `json:"client_msg_id,omitempty"`
+	Seq         int64     `json:"seq,omitempty"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id,omitempty"`
+	RoomID      string    `json:"room_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Envelope mirrors the wire format of a WebSocket frame.
+type Envelope struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	TS      time.Time       `json:"ts"`
+}
+
+// Ack mirrors the payload of an ack frame. FrameID is the envelope ID of the
+// acknowledged frame.
+type Ack struct {
+	FrameID     string `json:"-"`
+	MessageID   string `json:"message_id"`
+	ClientMsgID string `json:"client_msg_id,omitempty"`
+	Seq         int64  `json:"seq"`
+}
+
+// Typing mirrors the payload of a typing frame. TS is the time the server
+// received the typing frame it relays.
+type Typing struct {
+	UserID string    `json:"user_id"`
+	Typing bool      `json:"typing"`
+	TS     time.Time `json:"-"`
+}
+
+// ErrorFrame mirrors the payload of an error frame. FrameID is the envelope
+// ID of the rejected frame.
+type ErrorFrame struct {
+	FrameID string `json:"-"`
+	Code    string `json:"code"`
+	Reason  string `json:"reason"`
+}
+
+// NewUser registers a new user and logs it in.
+func NewUser(ctx context.Context, baseURL, username, password string) (*Client, error) {
+	c := &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		http:     &http.Client{Timeout: 10 * time.Second},
+		Username: username,
+	}
+
+	creds := map[string]string{"username": username, "password": password}
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/register", creds, http.StatusCreated, &user); err != nil {
+		return nil, fmt.Errorf("register %s: %w", username, err)
+	}
+	c.UserID = user.ID
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/login", creds, http.StatusOK, &login); err != nil {
+		return nil, fmt.Errorf("login %s: %w", username, err)
+	}
+	c.token = login.Token
+	return c, nil
+}
+
+// CreateRoom creates a room owned by the client and returns its ID.
+func (c *Client) CreateRoom(ctx context.Context, name string) (string, error) {
+	var room struct {
+		ID string `json:"id"`
+	}
+	err := c.do(ctx, http.MethodPost, "/rooms", map[string]string{"name": name}, http.StatusCreated, &room)
+	return room.ID, err
+}
+
+// JoinRoom adds the client to the room's members.
+func (c *Client) JoinRoom(ctx context.Context, roomID string) error {
+	return c.do(ctx, http.MethodPost, "/rooms/"+roomID+"/join", nil, http.StatusNoContent, nil)
+}
+
+// Kick removes a member from a room the client moderates.
+func (c *Client) Kick(ctx context.Context, roomID, userID string) error {
+	return c.do(ctx, http.MethodPost, "/rooms/"+roomID+"/kick/"+userID, nil, http.StatusNoContent, nil)
+}
+
+// Ban bans a user for good from a room the client moderates.
+func (c *Client) Ban(ctx context.Context, roomID, userID string) error {
+	return c.do(ctx, http.MethodPost, "/rooms/"+roomID+"/ban/"+userID, nil, http.StatusOK, nil)
+}
+
+// CreateConversation starts a group conversation between the client and the
+// given users and returns its ID.
+func (c *Client) CreateConversation(ctx context.Context, userIDs ...string) (string, error) {
+	var conversation struct {
+		ID string `json:"id"`
+	}
+	err := c.do(ctx, http.MethodPost, "/conversations", map[string][]string{"user_ids": userIDs}, http.StatusCreated, &conversation)
+	return conversation.ID, err
+}
+
+// SearchConversation returns the messages of a conversation that match q.
+func (c *Client) SearchConversation(ctx context.Context, conversationID, q string) ([]Message, error) {
+	var messages []Message
+	err := c.do(ctx, http.MethodGet, "/conversations/"+conversationID+"/search?q="+url.QueryEscape(q), nil, http.StatusOK, &messages)
+	return messages, err
+}
+
+// SearchRooms returns the names of the rooms visible to the client that
+// match q.
+func (c *Client) SearchRooms(ctx context.Context, q string) ([]string, error) {
+	var rooms []struct {
+		Name string `json:"name"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rooms/search?q="+url.QueryEscape(q), nil, http.StatusOK, &rooms); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(rooms))
+	for i, room := range rooms {
+		names[i] = room.Name
+	}
+	return names, nil
+}
+
+// UploadAvatar sets the avatar of a room the client moderates and returns
+// its URL.
+func (c *Client) UploadAvatar(ctx context.Context, roomID string, image []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(image); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+	var room struct {
+		AvatarURL string `json:"avatar_url"`
+	}
+	err = c.send(ctx, http.MethodPost, "/rooms/"+roomID+"/avatar", form.FormDataContentType(), &body, http.StatusOK, &room)
+	return room.AvatarURL, err
+}
+
+// DeleteAvatar removes the avatar of a room the client moderates.
+func (c *Client) DeleteAvatar(ctx context.Context, roomID string) error {
+	return c.do(ctx, http.MethodDelete, "/rooms/"+roomID+"/avatar", nil, http.StatusNoContent, nil)
+}
+
+// Connect opens a WebSocket connection to the room. A positive lastSeenSeq
+// asks the server to replay everything after it. It returns once the hub has
+// registered the connection, which it confirms with a presence.snapshot
+// frame, so that everything sent to the room from then on reaches it.
+func (c *Client) Connect(ctx context.Context, roomID string, lastSeenSeq int64) (*Conn, error) {
+	conn, status, err := c.dial(ctx, roomID, lastSeenSeq)
+	if err != nil {
+		return nil, fmt.Errorf("connect %s to %s: %w (status %d)", c.Username, roomID, err, status)
+	}
+	var snapshot struct{}
+	if _, err := conn.next("presence.snapshot", receiveTimeout, &snapshot); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect %s to %s: receive presence.snapshot: %w", c.Username, roomID, err)
+	}
+	return conn, nil
+}
+
+// dial opens a WebSocket connection to the room, returning the status the
+// server answered the handshake with.
+func (c *Client) dial(ctx context.Context, roomID string, lastSeenSeq int64) (*Conn, int, error) {
+	u, err := url.Parse(c.baseURL + "/ws/" + roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	if lastSeenSeq > 0 {
+		u.RawQuery = "last_seen_seq=" + strconv.FormatInt(lastSeenSeq, 10)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.token)
+	ws, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	if err != nil {
+		return nil, status, err
+	}
+	return &Conn{ws: ws}, status, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any, wantStatus int, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	return c.send(ctx, method, path, "application/json", reader, wantStatus, out)
+}
+
+// send sends a request with a body of the given content type, checking it is
+// answered with wantStatus and decoding the JSON response into out.
+func (c *Client) send(ctx context.Context, method, path, contentType string, body io.Reader, wantStatus int, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: got status %d, want %d: %s", method, path, resp.StatusCode, wantStatus, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// Conn is a live WebSocket connection to a room.
+type Conn struct {
+	ws *websocket.Conn
+	// pending holds received frames by type until they are asked for.
+	pending map[string][]Envelope
+}
+
+// Send sends a chat message to the room.
+func (c *Conn) Send(content string) error {
+	return c.SendFrame("message", uuid.NewString(), Message{Content: content})
+}
+
+// SendFrame sends a frame of any type with the given envelope ID.
+func (c *Conn) SendFrame(frameType, id string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.ws.WriteJSON(Envelope{Type: frameType, ID: id, Payload: data, TS: time.Now()})
+}
+
+// Receive returns the next chat message, waiting at most timeout.
+func (c *Conn) Receive(timeout time.Duration) (Message, error) {
+	var msg Message
+	_, err := c.next("message", timeout, &msg)
+	return msg, err
+}
+
+// ReceiveAck returns the next ack, waiting at most timeout.
+func (c *Conn) ReceiveAck(timeout time.Duration) (Ack, error) {
+	var ack Ack
+	env, err := c.next("ack", timeout, &ack)
+	ack.FrameID = env.ID
+	return ack, err
+}
+
+// SendTyping sends a typing frame.
+func (c *Conn) SendTyping(typing bool) error {
+	return c.SendFrame("typing", uuid.NewString(), map[string]bool{"typing": typing})
+}
+
+// ReceiveTyping returns the next typing frame, waiting at most timeout.
+func (c *Conn) ReceiveTyping(timeout time.Duration) (Typing, error) {
+	var typing Typing
+	env, err := c.next("typing", timeout, &typing)
+	typing.TS = env.TS
+	return typing, err
+}
+
+// ReceiveError returns the next error frame, waiting at most timeout.
+func (c *Conn) ReceiveError(timeout time.Duration) (ErrorFrame, error) {
+	var frame ErrorFrame
+	env, err := c.next("error", timeout, &frame)
+	frame.FrameID = env.ID
+	return frame, err
+}
+
+// next returns the next frame of the given type and decodes its payload into
+// out. Frames of other types are kept for later. The server may batch several
+// newline-separated frames into one WebSocket message.
+func (c *Conn) next(frameType string, timeout time.Duration, out any) (Envelope, error) {
+	if c.pending == nil {
+		c.pending = make(map[string][]Envelope)
+	}
+	for len(c.pending[frameType]) == 0 {
+		c.ws.SetReadDeadline(time.Now().Add(timeout))
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return Envelope{}, err
+		}
+		for _, line := range bytes.Split(data, []byte{'\n'}) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var env Envelope
+			if err := json.Unmarshal(line, &env); err != nil {
+				return Envelope{}, fmt.Errorf("decode frame: %w", err)
+			}
+			c.pending[env.Type] = append(c.pending[env.Type], env)
+		}
+	}
+	env := c.pending[frameType][0]
+	c.pending[frameType] = c.pending[frameType][1:]
+	if err := json.Unmarshal(env.Payload, out); err != nil {
+		return env, fmt.Errorf("decode %s payload: %w", frameType, err)
+	}
+	return env, nil
+}
+
+// WaitClosed waits at most timeout for the server to close the connection,
+// skipping frames received in the meantime, and returns the close frame it
+// sent.
+func (c *Conn) WaitClosed(timeout time.Duration) (*websocket.CloseError, error) {
+	c.ws.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		_, _, err := c.ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return closeErr, nil
+		}
+		return nil, err
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.ws.Close()
+}
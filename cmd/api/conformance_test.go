@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/fakes"
+	customMiddleware "github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// The conformance suite is an executable specification of the chat protocol.
+// It runs the server in-process, with fake mail, push and storage providers,
+// and drives it through its public HTTP and WebSocket API to check the
+// behavior clients rely on.
+//
+// The server needs Postgres: TEST_DATABASE_URL names the database to run it
+// on. The suite creates a schema of its own there, migrates it and drops it
+// when done, so the database's other contents are left alone. Without
+// TEST_DATABASE_URL the suite is skipped.
+
+// receiveTimeout bounds how long a test waits for an expected frame.
+const receiveTimeout = 5 * time.Second
+
+var (
+	// testServer serves the API under test, and testProviders records what
+	// it mailed, pushed and stored. Both are nil without a database.
+	testServer    *httptest.Server
+	testProviders *fakes.Providers
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+// runTests starts the server under test when TEST_DATABASE_URL is set, and
+// runs the tests.
+func runTests(m *testing.M) int {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		return m.Run()
+	}
+
+	ctx := context.Background()
+	schema := "conformance_" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+	dbPool, err := openTestDatabase(ctx, dbURL, schema)
+	if err != nil {
+		log.Printf("Unable to prepare the test database: %v", err)
+		return 1
+	}
+	defer dropTestSchema(dbURL, schema)
+	defer dbPool.Close()
+
+	secret := make([]byte, customMiddleware.MinJWTSecretLength)
+	rand.Read(secret)
+	err = customMiddleware.ConfigureJWT(customMiddleware.JWTConfig{Secret: secret, Issuer: "go-chat-app", Audience: "go-chat-app"})
+	if err != nil {
+		log.Printf("Unable to configure tokens: %v", err)
+		return 1
+	}
+	if os.Getenv("HOST") == "" {
+		os.Setenv("HOST", "localhost")
+	}
+
+	testProviders = fakes.NewProviders()
+	app := newApp(dbPool, testProviders.Service(), nil)
+	testServer = httptest.NewServer(app.router)
+	defer testServer.Close()
+
+	return m.Run()
+}
+
+// openTestDatabase creates schema in the database at dbURL, applies the
+// migrations to it and returns a pool whose connections use it.
+func openTestDatabase(ctx context.Context, dbURL, schema string) (*pgxpool.Pool, error) {
+	conn, err := pgx.Connect(ctx, dbURL)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.Exec(ctx, "CREATE SCHEMA "+schema)
+	conn.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	// Extensions already installed in public are used from there.
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	dbPool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(ctx, dbPool); err != nil {
+		dbPool.Close()
+		return nil, err
+	}
+	return dbPool, nil
+}
+
+// migrate applies the up migrations in ../../migrations, in order, as goose
+// does.
+func migrate(ctx context.Context, dbPool *pgxpool.Pool) error {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		// Without arguments the statements are sent as one simple query,
+		// which runs them in a single transaction.
+		if _, err := dbPool.Exec(ctx, up); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+// dropTestSchema removes the schema the suite ran in.
+func dropTestSchema(dbURL, schema string) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dbURL)
+	if err != nil {
+		log.Printf("Unable to drop schema %s: %v", schema, err)
+		return
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+		log.Printf("Unable to drop schema %s: %v", schema, err)
+	}
+}
+
+// Env gives a test access to the server under test.
+type Env struct {
+	t   *testing.T
+	ctx context.Context
+}
+
+// newEnv returns a test's environment, skipping the test when there is no
+// server to run it against.
+func newEnv(t *testing.T) *Env {
+	t.Helper()
+	if testServer == nil {
+		t.Skip("set TEST_DATABASE_URL to a Postgres database to run the conformance suite")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	return &Env{t: t, ctx: ctx}
+}
+
+// NewUser registers a uniquely named user.
+func (e *Env) NewUser(prefix string) *Client {
+	e.t.Helper()
+	c, err := NewUser(e.ctx, testServer.URL+"/v1", prefix+"-"+uuid.NewString()[:8], "conformance-password")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return c
+}
+
+// NewRoom creates a room owned by owner and joins every member to it.
+func (e *Env) NewRoom(owner *Client, members ...*Client) string {
+	e.t.Helper()
+	roomID, err := owner.CreateRoom(e.ctx, "conformance-"+uuid.NewString()[:8])
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	for _, c := range append([]*Client{owner}, members...) {
+		if err := c.JoinRoom(e.ctx, roomID); err != nil {
+			e.t.Fatal(err)
+		}
+	}
+	return roomID
+}
+
+// Connect connects c to the room, closing the connection when the test ends.
+func (e *Env) Connect(c *Client, roomID string, lastSeenSeq int64) *Conn {
+	e.t.Helper()
+	conn, err := c.Connect(e.ctx, roomID, lastSeenSeq)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	e.t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// TestBroadcastOrdering checks that every member receives a sender's
+// messages in the order they were sent, with increasing sequence numbers.
+func TestBroadcastOrdering(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	roomID := env.NewRoom(alice, bob)
+	aliceConn := env.Connect(alice, roomID, 0)
+	bobConn := env.Connect(bob, roomID, 0)
+
+	const count = 20
+	for i := 0; i < count; i++ {
+		if err := aliceConn.Send(fmt.Sprintf("message %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, conn := range map[string]*Conn{"alice": aliceConn, "bob": bobConn} {
+		var lastSeq int64
+		for i := 0; i < count; i++ {
+			msg, err := conn.Receive(receiveTimeout)
+			if err != nil {
+				t.Fatalf("%s: receive message %d: %v", name, i, err)
+			}
+			if want := fmt.Sprintf("message %d", i); msg.Content != want {
+				t.Fatalf("%s: got %q, want %q", name, msg.Content, want)
+			}
+			if msg.SenderID != alice.UserID {
+				t.Fatalf("%s: got sender %s, want %s", name, msg.SenderID, alice.UserID)
+			}
+			if msg.Seq <= lastSeq {
+				t.Fatalf("%s: sequence went from %d to %d", name, lastSeq, msg.Seq)
+			}
+			lastSeq = msg.Seq
+		}
+	}
+}
+
+// TestResumeAfterDisconnect checks that a client reconnecting with
+// last_seen_seq receives exactly the messages it missed, in order, before
+// live traffic.
+func TestResumeAfterDisconnect(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	roomID := env.NewRoom(alice, bob)
+	aliceConn := env.Connect(alice, roomID, 0)
+	bobConn := env.Connect(bob, roomID, 0)
+
+	if err := aliceConn.Send("before disconnect"); err != nil {
+		t.Fatal(err)
+	}
+	seen, err := bobConn.Receive(receiveTimeout)
+	if err != nil {
+		t.Fatalf("bob: receive before disconnect: %v", err)
+	}
+	bobConn.Close()
+
+	missed := []string{"missed 1", "missed 2", "missed 3"}
+	for _, content := range missed {
+		if err := aliceConn.Send(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Wait for alice's own echoes so the messages are stored before bob returns.
+	for i := 0; i <= len(missed); i++ {
+		if _, err := aliceConn.Receive(receiveTimeout); err != nil {
+			t.Fatalf("alice: receive echo: %v", err)
+		}
+	}
+
+	bobConn = env.Connect(bob, roomID, seen.Seq)
+	for _, want := range missed {
+		msg, err := bobConn.Receive(receiveTimeout)
+		if err != nil {
+			t.Fatalf("bob: receive replay: %v", err)
+		}
+		if msg.Content != want {
+			t.Fatalf("bob: replay got %q, want %q", msg.Content, want)
+		}
+	}
+
+	if err := aliceConn.Send("live"); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := bobConn.Receive(receiveTimeout)
+	if err != nil {
+		t.Fatalf("bob: receive live after replay: %v", err)
+	}
+	if msg.Content != "live" {
+		t.Fatalf("bob: after replay got %q, want %q", msg.Content, "live")
+	}
+}
+
+// TestAckSemantics checks that the sender of a message receives an ack
+// echoing its frame ID and naming the stored message.
+func TestAckSemantics(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	roomID := env.NewRoom(alice)
+	conn := env.Connect(alice, roomID, 0)
+
+	frameID := uuid.NewString()
+	if err := conn.SendFrame("message", frameID, Message{Content: "acknowledge me"}); err != nil {
+		t.Fatal(err)
+	}
+	ack, err := conn.ReceiveAck(receiveTimeout)
+	if err != nil {
+		t.Fatalf("receive ack: %v", err)
+	}
+	if ack.FrameID != frameID {
+		t.Fatalf("ack for frame %q, want %q", ack.FrameID, frameID)
+	}
+	msg, err := conn.Receive(receiveTimeout)
+	if err != nil {
+		t.Fatalf("receive echo: %v", err)
+	}
+	if ack.MessageID != msg.ID || ack.Seq != msg.Seq {
+		t.Fatalf("ack names message %s (seq %d), echo is %s (seq %d)", ack.MessageID, ack.Seq, msg.ID, msg.Seq)
+	}
+}
+
+// TestUnknownFrameTypes checks that a frame of an unknown type is answered
+// with an error frame and does not close the connection.
+func TestUnknownFrameTypes(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	roomID := env.NewRoom(alice)
+	conn := env.Connect(alice, roomID, 0)
+
+	frameID := uuid.NewString()
+	if err := conn.SendFrame("no-such-type", frameID, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	frame, err := conn.ReceiveError(receiveTimeout)
+	if err != nil {
+		t.Fatalf("receive error frame: %v", err)
+	}
+	if frame.FrameID != frameID {
+		t.Fatalf("error for frame %q, want %q", frame.FrameID, frameID)
+	}
+
+	if err := conn.Send("still connected"); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := conn.Receive(receiveTimeout)
+	if err != nil {
+		t.Fatalf("receive after error: %v", err)
+	}
+	if msg.Content != "still connected" {
+		t.Fatalf("after error got %q, want %q", msg.Content, "still connected")
+	}
+}
+
+// TestIdempotentRetries checks that a message resent with the same client
+// message ID is acknowledged with the original ack and not delivered twice.
+func TestIdempotentRetries(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	roomID := env.NewRoom(alice)
+	conn := env.Connect(alice, roomID, 0)
+
+	msg := Message{ClientMsgID: uuid.NewString(), Content: "send me once"}
+	var acks []Ack
+	for i := 0; i < 2; i++ {
+		frameID := uuid.NewString()
+		if err := conn.SendFrame("message", frameID, msg); err != nil {
+			t.Fatal(err)
+		}
+		ack, err := conn.ReceiveAck(receiveTimeout)
+		if err != nil {
+			t.Fatalf("receive ack %d: %v", i, err)
+		}
+		if ack.FrameID != frameID {
+			t.Fatalf("ack for frame %q, want %q", ack.FrameID, frameID)
+		}
+		acks = append(acks, ack)
+	}
+	if acks[0].MessageID != acks[1].MessageID || acks[0].Seq != acks[1].Seq {
+		t.Fatalf("retry acked as %s (seq %d), original as %s (seq %d)", acks[1].MessageID, acks[1].Seq, acks[0].MessageID, acks[0].Seq)
+	}
+
+	if err := conn.Send("after retry"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"send me once", "after retry"} {
+		got, err := conn.Receive(receiveTimeout)
+		if err != nil {
+			t.Fatalf("receive %q: %v", want, err)
+		}
+		if got.Content != want {
+			t.Fatalf("got %q, want %q", got.Content, want)
+		}
+	}
+}
+
+// TestTypingCoalescing checks that a burst of typing frames is relayed to
+// the rest of the room at most once per typing interval, and that the room
+// is told the user stopped typing when they send a message or stop sending
+// typing frames.
+func TestTypingCoalescing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits out the typing timeout")
+	}
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	roomID := env.NewRoom(alice, bob)
+	aliceConn := env.Connect(alice, roomID, 0)
+	bobConn := env.Connect(bob, roomID, 0)
+
+	// Alice types for a little over one interval.
+	burst := service.TypingInterval + time.Second
+	for start := time.Now(); time.Since(start) < burst; time.Sleep(100 * time.Millisecond) {
+		if err := aliceConn.SendTyping(true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Sending a message stops typing. The stop follows the frames relayed
+	// during the burst.
+	if err := aliceConn.Send("done typing"); err != nil {
+		t.Fatal(err)
+	}
+	var relayed []Typing
+	for {
+		typing, err := bobConn.ReceiveTyping(receiveTimeout)
+		if err != nil {
+			t.Fatalf("bob: receive typing: %v", err)
+		}
+		if typing.UserID != alice.UserID {
+			t.Fatalf("bob: got typing of %s, want alice's", typing.UserID)
+		}
+		if !typing.Typing {
+			break
+		}
+		relayed = append(relayed, typing)
+	}
+	if len(relayed) != 2 {
+		t.Fatalf("bob: got %d typing frames for a %s burst, want 2", len(relayed), burst)
+	}
+	// The frames are timed on arrival, a moment before the hub relays them.
+	if gap := relayed[1].TS.Sub(relayed[0].TS); gap < service.TypingInterval-100*time.Millisecond {
+		t.Fatalf("bob: typing frames relayed %s apart, want at least %s", gap, service.TypingInterval)
+	}
+
+	// So does sending no typing frame for the typing timeout.
+	if err := aliceConn.SendTyping(true); err != nil {
+		t.Fatal(err)
+	}
+	if typing, err := bobConn.ReceiveTyping(receiveTimeout); err != nil || !typing.Typing {
+		t.Fatalf("bob: after typing again got %+v (%v), want alice typing", typing, err)
+	}
+	idleSince := time.Now()
+	typing, err := bobConn.ReceiveTyping(service.TypingTimeout + receiveTimeout)
+	if err != nil {
+		t.Fatalf("bob: receive typing stop after going idle: %v", err)
+	}
+	if typing.UserID != alice.UserID || typing.Typing {
+		t.Fatalf("bob: after going idle got typing %+v, want alice stopped", typing)
+	}
+	if idle := time.Since(idleSince); idle < service.TypingTimeout-time.Second {
+		t.Fatalf("bob: typing stopped after %s idle, want %s", idle, service.TypingTimeout)
+	}
+}
+
+// TestKickEviction checks that kicking a member closes their connection to
+// the room and that they cannot reconnect until they join again, and that a
+// banned user cannot join again at all.
+func TestKickEviction(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	roomID := env.NewRoom(alice, bob)
+
+	bobConn := env.Connect(bob, roomID, 0)
+	if err := alice.Kick(env.ctx, roomID, bob.UserID); err != nil {
+		t.Fatal(err)
+	}
+	closed, err := bobConn.WaitClosed(receiveTimeout)
+	if err != nil {
+		t.Fatalf("bob: wait for close after kick: %v", err)
+	}
+	if closed.Code != websocket.ClosePolicyViolation || closed.Text != "kicked from the room" {
+		t.Fatalf("bob: closed with %d %q, want %d %q", closed.Code, closed.Text, websocket.ClosePolicyViolation, "kicked from the room")
+	}
+	if conn, status, err := bob.dial(env.ctx, roomID, 0); err == nil || status != http.StatusForbidden {
+		if conn != nil {
+			conn.Close()
+		}
+		t.Fatalf("bob: reconnect after kick answered %d (%v), want %d", status, err, http.StatusForbidden)
+	}
+
+	// Kicked members can join again; banned users cannot.
+	if err := bob.JoinRoom(env.ctx, roomID); err != nil {
+		t.Fatalf("bob: join after kick: %v", err)
+	}
+	bobConn = env.Connect(bob, roomID, 0)
+	if err := alice.Ban(env.ctx, roomID, bob.UserID); err != nil {
+		t.Fatal(err)
+	}
+	closed, err = bobConn.WaitClosed(receiveTimeout)
+	if err != nil {
+		t.Fatalf("bob: wait for close after ban: %v", err)
+	}
+	if closed.Text != "banned from the room" {
+		t.Fatalf("bob: closed with %q, want %q", closed.Text, "banned from the room")
+	}
+	err = bob.JoinRoom(env.ctx, roomID)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("got status %d", http.StatusForbidden)) {
+		t.Fatalf("bob: join after ban: got %v, want status %d", err, http.StatusForbidden)
+	}
+	if conn, status, err := bob.dial(env.ctx, roomID, 0); err == nil || status != http.StatusForbidden {
+		if conn != nil {
+			conn.Close()
+		}
+		t.Fatalf("bob: reconnect after ban answered %d (%v), want %d", status, err, http.StatusForbidden)
+	}
+}
+
+// TestClientMsgIDPerRoom checks that a client message ID only identifies
+// retries within a room: reused in another room, it sends a new message.
+func TestClientMsgIDPerRoom(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	firstRoom := env.NewRoom(alice)
+	secondRoom := env.NewRoom(alice)
+
+	clientMsgID := uuid.NewString()
+	var acks []Ack
+	for _, roomID := range []string{firstRoom, secondRoom} {
+		conn := env.Connect(alice, roomID, 0)
+		if err := conn.SendFrame("message", uuid.NewString(), Message{ClientMsgID: clientMsgID, Content: "same key"}); err != nil {
+			t.Fatal(err)
+		}
+		ack, err := conn.ReceiveAck(receiveTimeout)
+		if err != nil {
+			t.Fatalf("receive ack in %s: %v", roomID, err)
+		}
+		got, err := conn.Receive(receiveTimeout)
+		if err != nil {
+			t.Fatalf("receive message in %s: %v", roomID, err)
+		}
+		if got.RoomID != roomID || got.ID != ack.MessageID {
+			t.Fatalf("got message %s in room %s, want %s in room %s", got.ID, got.RoomID, ack.MessageID, roomID)
+		}
+		acks = append(acks, ack)
+	}
+	if acks[0].MessageID == acks[1].MessageID {
+		t.Fatalf("second room acked with the first room's message %s", acks[0].MessageID)
+	}
+}
@@ -1,22 +1,39 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/mxhdiqaim/go-chat-app/internal/database"
 	"github.com/mxhdiqaim/go-chat-app/internal/handler"
 	customMiddleware "github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/ratelimit"
+	"github.com/mxhdiqaim/go-chat-app/internal/server"
 	"github.com/mxhdiqaim/go-chat-app/internal/service"
 
 	"github.com/mxhdiqaim/go-chat-app/docs"
 
+	// Built-in extensions register themselves with the server.
+	_ "github.com/mxhdiqaim/go-chat-app/internal/extensions/autoreply"
+
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
@@ -24,12 +41,12 @@ import (
 // @version 1.0
 // @description This is a chat application backend API.
 // @host localhost:8080
-// @BasePath /
+// @BasePath /v1
 // @title Go Chat Application API
 // @version 1.0
 // @description This is a chat application backend API.
 // @host localhost:8080
-// @BasePath /
+// @BasePath /v1
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
@@ -58,27 +75,372 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is not set")
 	}
 
+	// Tokens are signed with the RSA key in JWT_PRIVATE_KEY_FILE, or with
+	// JWT_SECRET or the contents of JWT_SECRET_FILE; the server does not
+	// start without one.
+	jwtConfig, err := jwtConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid JWT settings: %v", err)
+	}
+	if err := customMiddleware.ConfigureJWT(jwtConfig); err != nil {
+		log.Fatalf("Invalid JWT settings: %v", err)
+	}
+
+	listenOpts, err := listenOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid listen settings: %v", err)
+	}
+
 	dbPool, err := pgxpool.New(context.Background(), dbURL)
 	if err != nil {
 		log.Fatalf("Unable to create connection pool: %v\n", err)
 	}
 
 	defer dbPool.Close()
+
+	// External providers. Mail and push are log-only until real providers are configured.
+	storageDir := os.Getenv("STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "./uploads"
+	}
+	storage, err := service.NewLocalStorage(storageDir, os.Getenv("STORAGE_BASE_URL"))
+	if err != nil {
+		log.Fatalf("Unable to initialize storage: %v", err)
+	}
+	providers := service.Providers{
+		Mailer:  service.LogMailer{},
+		Push:    service.LogPushSender{},
+		Storage: storage,
+	}
+	if translationURL := os.Getenv("TRANSLATION_URL"); translationURL != "" {
+		providers.Translator = service.NewHTTPTranslator(translationURL, os.Getenv("TRANSLATION_API_KEY"))
+	}
+	// Catch-up summaries send room messages to a third party, so they stay
+	// off unless explicitly enabled.
+	if os.Getenv("SUMMARIES_ENABLED") == "true" {
+		summaryURL := os.Getenv("SUMMARY_URL")
+		if summaryURL == "" {
+			log.Fatalf("SUMMARY_URL must be set when SUMMARIES_ENABLED is true")
+		}
+		providers.Summarizer = service.NewHTTPSummarizer(summaryURL, os.Getenv("SUMMARY_API_KEY"))
+	}
+
+	// With HUB_SNAPSHOT_FILE set, the users connected to each room when the
+	// server last shut down are saved there, and restored at startup if
+	// the server comes back within 10 minutes.
+	hubSnapshotFile := os.Getenv("HUB_SNAPSHOT_FILE")
+	var hubSnapshot *service.HubSnapshot
+	if hubSnapshotFile != "" {
+		hubSnapshot, err = service.LoadHubSnapshot(hubSnapshotFile)
+		switch {
+		case err == nil:
+			log.Printf("Restoring %d rooms from the hub snapshot", len(hubSnapshot.Users))
+		case errors.Is(err, fs.ErrNotExist), errors.Is(err, service.ErrStaleHubSnapshot):
+			// A first start, or one long after the last shutdown.
+		default:
+			log.Printf("Unable to load the hub snapshot: %v", err)
+		}
+	}
+
+	app := newApp(dbPool, providers, hubSnapshot)
+	serverOpts := app.options
+
+	srv := &http.Server{
+		Handler:           app.router,
+		ReadHeaderTimeout: serverOpts.ReadHeaderTimeout,
+		ReadTimeout:       serverOpts.ReadTimeout,
+		WriteTimeout:      serverOpts.WriteTimeout,
+		IdleTimeout:       serverOpts.IdleTimeout,
+		MaxHeaderBytes:    serverOpts.MaxHeaderBytes,
+	}
+
+	// Every listener is opened before serving starts, so a bad address fails
+	// startup instead of leaving the server half up.
+	listeners := make([]net.Listener, 0, len(listenOpts.Addrs))
+	for _, addr := range listenOpts.Addrs {
+		l, err := listen(addr, listenOpts.UnixSocketMode)
+		if err != nil {
+			log.Fatalf("Could not listen on %s: %v", addr, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	app.extensions.StartJobs()
+
+	serveErrs := make(chan error, len(listeners))
+	for i, l := range listeners {
+		addr := listenOpts.Addrs[i]
+		log.Printf("Server listening on %s", addr)
+		go func() {
+			err := srv.Serve(l)
+			if errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Stopped listening on %s", addr)
+				err = nil
+			}
+			serveErrs <- err
+		}()
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	var serveErr error
+	select {
+	case sig := <-stop:
+		log.Printf("Received %s, shutting down", sig)
+	case serveErr = <-serveErrs:
+		log.Printf("Listener failed, shutting down: %v", serveErr)
+	}
+
+	// The snapshot is taken before connections start to drop.
+	if hubSnapshotFile != "" {
+		if err := service.SaveHubSnapshot(hubSnapshotFile, app.hub.Snapshot()); err != nil {
+			log.Printf("Unable to save the hub snapshot: %v", err)
+		}
+	}
+
+	// Shutdown closes every listener, removing Unix socket files, and waits
+	// for in-flight requests. WebSocket connections are not waited for.
+	ctx, cancel := context.WithTimeout(context.Background(), serverOpts.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Graceful shutdown did not finish: %v", err)
+	}
+	if err := app.extensions.Shutdown(ctx); err != nil {
+		log.Printf("Extensions did not shut down cleanly: %v", err)
+	}
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
+
+// app is the chat server: its routes, and the parts of it that outlive
+// requests.
+type app struct {
+	router     http.Handler
+	hub        *service.Hub
+	extensions *server.Lifecycle
+	options    serverOptions
+}
+
+// newApp sets up the services, background jobs and routes of the server on
+// dbPool, sending mail and push notifications and storing files through
+// providers. The rest of its settings are read from the environment, and
+// invalid ones stop the program.
+func newApp(dbPool *pgxpool.Pool, providers service.Providers, hubSnapshot *service.HubSnapshot) *app {
+	var err error
 	dbQueries := database.New(dbPool)
 
+	// Messages are stored in their tables unless MESSAGE_STORAGE is "events",
+	// which keeps an append-only log of every change instead. Messages stored
+	// before the log was turned on are logged as they are now.
+	messageStorage := os.Getenv("MESSAGE_STORAGE")
+	if messageStorage == "" {
+		messageStorage = service.MessageStorageTable
+	}
+	if messageStorage != service.MessageStorageTable && messageStorage != service.MessageStorageEvents {
+		log.Fatalf("MESSAGE_STORAGE must be %q or %q", service.MessageStorageTable, service.MessageStorageEvents)
+	}
+	messageStore := service.NewMessageStore(dbQueries, messageStorage == service.MessageStorageEvents)
+	if n, err := messageStore.Backfill(context.Background()); err != nil {
+		log.Fatalf("Unable to backfill the message event log: %v", err)
+	} else if n > 0 {
+		log.Printf("Logged %d existing messages and annotations as events", n)
+	}
+
+	// The busiest rooms' members and latest messages are loaded before the
+	// server starts listening, so that the clients reconnecting after a deploy
+	// do not all query Postgres at once. WARM_CACHE_ROOMS=0 turns it off.
+	warmCacheRooms := service.DefaultWarmCacheRooms
+	if v := os.Getenv("WARM_CACHE_ROOMS"); v != "" {
+		if warmCacheRooms, err = strconv.Atoi(v); err != nil || warmCacheRooms < 0 {
+			log.Fatalf("WARM_CACHE_ROOMS must be a number of rooms, or 0 to turn the warm cache off")
+		}
+	}
+	var warmCache *service.WarmCache
+	if warmCacheRooms > 0 {
+		warmCache = service.NewWarmCache(dbQueries)
+		preloadCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// The rooms in the hub snapshot are the ones clients are about to
+		// reconnect to, so they go first, and its users are loaded too.
+		preloaded := 0
+		if hubSnapshot != nil {
+			n, err := warmCache.PreloadRooms(preloadCtx, hubSnapshot.ActiveRooms(), warmCacheRooms)
+			if err != nil {
+				log.Printf("Unable to preload the hub snapshot's rooms: %v", err)
+			}
+			preloaded += n
+			if n, err := warmCache.PreloadUsers(preloadCtx, hubSnapshot.UserIDs()); err != nil {
+				log.Printf("Unable to preload the hub snapshot's users: %v", err)
+			} else {
+				log.Printf("Preloaded %d users into the warm cache", n)
+			}
+		}
+		if n, err := warmCache.Preload(preloadCtx, warmCacheRooms); err != nil {
+			log.Printf("Unable to preload the warm cache: %v", err)
+		} else {
+			log.Printf("Preloaded %d rooms into the warm cache", preloaded+n)
+		}
+		cancel()
+		messageStore.SetWarmCache(warmCache)
+	}
+
 	// Initialize Services and Handlers
 	userService := service.NewUserService(dbQueries)
-	authHandler := handler.NewAuthHandler(userService)
-	roomHandler := handler.NewRoomHandler(dbQueries, dbPool)
-	userHandler := handler.NewUserHandler(dbQueries)
+	welcome, err := welcomeOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid welcome message settings: %v", err)
+	}
+	authHandler := handler.NewAuthHandler(userService, service.NewWelcomeService(dbQueries, messageStore, welcome))
+	// Background work such as webhook deliveries is queued in the database
+	// and retried until it succeeds or runs out of attempts.
+	jobQueue := service.NewJobQueue(dbQueries)
+	webhookService := service.NewWebhookService(dbQueries, jobQueue)
+
+	maxMessageSize := service.DefaultMaxMessageSize
+	if v := os.Getenv("MAX_MESSAGE_SIZE"); v != "" {
+		maxMessageSize, err = strconv.Atoi(v)
+		if err != nil || maxMessageSize < 1 || maxMessageSize > service.MaxMessageSizeLimit {
+			log.Fatalf("MAX_MESSAGE_SIZE must be between 1 and %d bytes", service.MaxMessageSizeLimit)
+		}
+	}
+
+	urgentDailyLimit := service.DefaultUrgentDailyLimit
+	if v := os.Getenv("URGENT_DAILY_LIMIT"); v != "" {
+		if urgentDailyLimit, err = strconv.Atoi(v); err != nil || urgentDailyLimit < 1 {
+			log.Fatalf("URGENT_DAILY_LIMIT must be a positive number")
+		}
+	}
 
-	hub := service.NewHub()
+	// Organizations that require real names or handles choose which names
+	// users are shown by.
+	displayNames, err := service.ParseDisplayNamePolicy(os.Getenv("DISPLAY_NAME_POLICY"))
+	if err != nil {
+		log.Fatalf("Invalid DISPLAY_NAME_POLICY: %v", err)
+	}
+
+	messageService := service.NewMessageService(dbQueries, messageStore, service.MessageOptions{
+		// Shortcode normalization is on unless explicitly disabled.
+		EmojiShortcodes:  os.Getenv("EMOJI_SHORTCODES") != "false",
+		MaxMessageSize:   maxMessageSize,
+		UrgentDailyLimit: urgentDailyLimit,
+		DisplayNames:     displayNames,
+	})
+
+	flood, err := floodControlFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid flood control settings: %v", err)
+	}
+	fairness, err := roomFairnessFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid room fairness settings: %v", err)
+	}
+	slo, err := broadcastSLOFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid broadcast SLO settings: %v", err)
+	}
+	hub := service.NewHub(messageService, providers, service.HubOptions{Flood: flood, Fairness: fairness, Webhooks: webhookService, Push: pushTemplatesFromEnv(), Hooks: server.HubHooks(), SLO: slo, Snapshot: hubSnapshot})
 	go hub.Run()
-	chatHandler := handler.NewChatHandler(hub, dbQueries)
+
+	retentionInterval := time.Hour
+	if v := os.Getenv("RETENTION_INTERVAL"); v != "" {
+		if retentionInterval, err = time.ParseDuration(v); err != nil || retentionInterval <= 0 {
+			log.Fatalf("RETENTION_INTERVAL must be a positive duration such as 1h")
+		}
+	}
+	retentionService := service.NewRetentionService(dbQueries, dbPool, messageStore)
+	go retentionService.Run(context.Background(), retentionInterval)
+
+	statsInterval := time.Hour
+	if v := os.Getenv("STATS_INTERVAL"); v != "" {
+		if statsInterval, err = time.ParseDuration(v); err != nil || statsInterval <= 0 {
+			log.Fatalf("STATS_INTERVAL must be a positive duration such as 1h")
+		}
+	}
+	statsService := service.NewStatsService(dbQueries)
+	go statsService.Run(context.Background(), statsInterval)
+
+	// Temporary rooms are deleted within a minute of expiring.
+	go service.NewRoomExpiryService(dbQueries, dbPool, hub).Run(context.Background(), time.Minute)
+
+	go service.NewMemberSync(dbQueries, dbPool, hub).Run(context.Background())
+	go jobQueue.Run(context.Background())
+
+	inviteService := service.NewInviteService(dbQueries, dbPool, hub)
+	roomHandler := handler.NewRoomHandler(dbQueries, dbPool, webhookService, inviteService, hub, providers.Storage)
+	inviteHandler := handler.NewInviteHandler(dbQueries, inviteService, webhookService, os.Getenv("INVITE_LINK_URL"))
+	chatHandler := handler.NewChatHandler(hub, dbQueries, messageService, warmCache)
+	userHandler := handler.NewUserHandler(dbQueries, service.NewAccountService(dbQueries, dbPool, messageStore, hub), warmCache)
+	messageHandler := handler.NewMessageHandler(dbQueries, messageService, service.NewAnnotationService(dbQueries, messageService, hub), service.NewRevisionService(dbQueries, messageService, hub))
+	reactionHandler := handler.NewReactionHandler(service.NewReactionService(dbQueries, messageService, hub))
+	pinService := service.NewPinService(dbQueries, messageService, hub, webhookService)
+	pinHandler := handler.NewPinHandler(pinService)
+	retentionHandler := handler.NewRetentionHandler(dbQueries, retentionService)
+	groupHandler := handler.NewGroupHandler(dbQueries, service.NewGroupService(dbQueries, dbPool))
+	moderationHandler := handler.NewModerationHandler(dbQueries, service.NewModerationService(dbQueries, dbPool, messageStore, hub, jobQueue), service.NewReportService(dbQueries, messageService, hub), webhookService)
+	pollHandler := handler.NewPollHandler(dbQueries, service.NewPollService(dbQueries, dbPool, messageStore, hub))
+	unreadHandler := handler.NewUnreadHandler(hub, messageService)
+	webhookHandler := handler.NewWebhookHandler(dbQueries, webhookService, service.NewIncomingWebhookService(dbQueries, messageService, hub))
+	statsHandler := handler.NewStatsHandler(dbQueries, statsService)
+	conversationHandler := handler.NewConversationHandler(service.NewConversationService(dbQueries, dbPool, hub), messageService)
+	summaryHandler := handler.NewSummaryHandler(dbQueries, service.NewSummaryService(messageService, providers.Summarizer))
+	// Impersonation lets administrators act as users who granted support
+	// access; it stays off unless explicitly enabled.
+	impersonationService := service.NewImpersonationService(dbQueries, dbPool, messageStore, hub, os.Getenv("IMPERSONATION_ENABLED") == "true")
+	impersonationHandler := handler.NewImpersonationHandler(dbQueries, impersonationService)
+	settingsHandler := handler.NewSettingsHandler(service.NewSettingsService(dbQueries, hub, impersonationService))
+	messageEventHandler := handler.NewMessageEventHandler(dbQueries, messageStore)
+	folderHandler := handler.NewFolderHandler(service.NewFolderService(dbQueries, hub))
+	notificationSettingsService := service.NewNotificationSettingsService(dbQueries)
+	notificationSettingsHandler := handler.NewNotificationSettingsHandler(notificationSettingsService)
+	// Slash commands such as /pin and /leave sent as chat messages are run
+	// instead of being sent.
+	hub.SetCommands(service.NewCommandDispatcher(dbQueries, hub, pinService, notificationSettingsService, webhookService))
+	privacyHandler := handler.NewPrivacyHandler(service.NewPrivacyService(dbQueries, hub))
+	jobHandler := handler.NewJobHandler(dbQueries, jobQueue)
+	roomBundleHandler := handler.NewRoomBundleHandler(dbQueries, service.NewRoomBundleService(dbQueries, dbPool, messageStore))
+	publicRoomHandler := handler.NewPublicRoomHandler(service.NewPublicRoomService(dbQueries, displayNames))
+	statusHandler := handler.NewStatusHandler(service.NewStatusService(dbQueries, hub))
+	presenceHandler := handler.NewPresenceHandler(dbQueries, hub)
+	workspaceHandler := handler.NewWorkspaceHandler(dbQueries, service.NewWorkspaceService(dbQueries, brandingFromEnv()))
+	legalHoldHandler := handler.NewLegalHoldHandler(dbQueries, service.NewLegalHoldService(dbQueries, dbPool))
+
+	// Extensions registered with server.RegisterExtension, such as by forks,
+	// are set up last so they can use the built-in services.
+	extensions, err := server.Init(context.Background(), server.Deps{
+		DB:        dbQueries,
+		Pool:      dbPool,
+		Hub:       hub,
+		Messages:  messageService,
+		Store:     messageStore,
+		Providers: providers,
+	})
+	if err != nil {
+		log.Fatalf("Unable to initialize extensions: %v", err)
+	}
+
+	serverOpts, err := serverOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid server settings: %v", err)
+	}
+
+	// Listing users is comparatively expensive, so it gets its own limiter.
+	userListLimiter := ratelimit.New(2, 10)
+	// Every summary may cost a call to the summarization provider.
+	summaryLimiter := ratelimit.New(0.1, 3)
+	// Joining by invite code is limited to slow down guessing codes.
+	inviteCodeLimiter := ratelimit.New(0.2, 5)
+	// Every reaction is fanned out to the room, so each user gets 30 a minute.
+	reactionLimiter := ratelimit.New(0.5, 30)
+	// Incoming webhooks are unauthenticated, so posts are limited per address.
+	incomingWebhookLimiter := ratelimit.New(1, 20)
+	// Public room logs are unauthenticated too; readers get one a second.
+	publicRoomLimiter := ratelimit.New(1, 10)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	// Requests to a workspace's custom domain are marked as made to it.
+	r.Use(workspaceHandler.Route)
 
 	// Read host from environment variable for production, default for local
     host := os.Getenv("HOST")
@@ -91,46 +453,542 @@ func main() {
 	docs.SwaggerInfo.Description = "This is a real-time chat application backend."
 	docs.SwaggerInfo.Version = "1.0"
 	docs.SwaggerInfo.Host = host
-	docs.SwaggerInfo.BasePath = "/"
+	docs.SwaggerInfo.BasePath = "/v1"
 	docs.SwaggerInfo.Schemes = []string{"http", "https"} // Support both http and https
     r.Get("/swagger/*", httpSwagger.Handler(
         httpSwagger.URL("/swagger/doc.json"),
     ))
 
-	// Public Routes
-	r.Post("/register", authHandler.RegisterUser)
-	r.Post("/login", authHandler.LoginUser)
-
-	// Protected Routes (with JWT middleware)
-	r.Group(func(r chi.Router) {
-		r.Use(customMiddleware.AuthMiddleware)
-
-		// User Endpoints
-		r.Get("/users", userHandler.GetAllUsers)
-		r.Get("/users/{id}", userHandler.GetUserByID)
-		r.Get("/users/search", userHandler.SearchUsers)
-		r.Put("/users/{id}", userHandler.UpdateUser)
-		r.Delete("/users/{id}", userHandler.DeleteUser)
-
-		// Room CRUD Endpoints
-		r.Post("/rooms", roomHandler.CreateRoom)
-		r.Get("/rooms", roomHandler.GetRooms)
-		r.Get("/rooms/{id}", roomHandler.GetRoomByID)
-		r.Put("/rooms/{id}", roomHandler.UpdateRoom)
-		r.Delete("/rooms/{id}", roomHandler.DeleteRoom)
-		r.Post("/rooms/{id}/join", roomHandler.JoinRoom)
-		r.Delete("/rooms/{id}/leave", roomHandler.LeaveRoom)
-
-		r.Get("/ws/{roomID}", chatHandler.ServeWs)
+	// Metrics are only served to scrapers holding METRICS_TOKEN.
+	if token := os.Getenv("METRICS_TOKEN"); token != "" {
+		r.Get("/metrics", handler.NewMetricsHandler(hub, jobQueue, token).GetMetrics)
+	}
+
+	// Other services verify RS256 tokens with the published keys.
+	r.Get("/.well-known/jwks.json", handler.NewJWKSHandler(customMiddleware.JWKS()).GetJWKS)
+
+	// Every route is served under /v1. The unversioned paths stay available
+	// for existing clients unless LEGACY_ROUTES is "false"; their responses
+	// are marked deprecated and link to the /v1 path.
+	api := func(r chi.Router) {
+		// Public Routes
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.Timeout(serverOpts.HandlerTimeout))
+			r.Post("/register", authHandler.RegisterUser)
+			r.Post("/login", authHandler.LoginUser)
+			r.Get("/branding", workspaceHandler.GetBranding)
+			r.With(customMiddleware.RateLimit(incomingWebhookLimiter)).Post("/webhooks/{token}", webhookHandler.PostIncomingWebhook)
+			r.With(customMiddleware.RateLimit(publicRoomLimiter)).Get("/public/rooms/{id}/messages", publicRoomHandler.GetPublicRoomMessages)
+			extensions.PublicRoutes(r)
+		})
+
+		// Protected Routes (with JWT middleware)
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.AuthMiddleware)
+			r.Use(impersonationHandler.Guard)
+
+			// WebSocket connections are long-lived and exempt from handler timeouts.
+			r.Get("/ws/admin", chatHandler.ServeAdminWs)
+			r.Get("/ws/{roomID}", chatHandler.ServeWs)
+
+			// Bulk operations touch many rows, so they get more time.
+			r.Group(func(r chi.Router) {
+				r.Use(customMiddleware.Timeout(serverOpts.SlowHandlerTimeout))
+				r.Delete("/users/{id}", userHandler.DeleteUser)
+				r.Post("/rooms/{id}/members/bulk", roomHandler.BulkUpdateMembers)
+				r.Post("/rooms/{id}/messages/bulk-delete", moderationHandler.BulkDeleteMessages)
+				r.Get("/rooms/{id}/export", roomBundleHandler.ExportRoom)
+				r.Post("/rooms/import", roomBundleHandler.ImportRoom)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(customMiddleware.Timeout(serverOpts.HandlerTimeout))
+
+				// User Endpoints
+				r.With(customMiddleware.RateLimit(userListLimiter)).Get("/users", userHandler.ListUsers)
+				r.Get("/users/{id}", userHandler.GetUserByID)
+				r.Get("/users/search", userHandler.SearchUsers)
+				r.Put("/users/me/language", userHandler.SetPreferredLanguage)
+				r.Put("/users/me/display-name", userHandler.SetDisplayName)
+				r.Put("/users/me/status", statusHandler.SetStatus)
+				r.Get("/presence", presenceHandler.GetPresence)
+				r.Get("/users/me/settings", settingsHandler.GetSettings)
+				r.Get("/users/me/privacy", privacyHandler.GetPrivacy)
+				r.Put("/users/me/privacy", privacyHandler.SetPrivacy)
+				r.Put("/users/{id}", userHandler.UpdateUser)
+				r.Get("/users/{id}/deletion-report", userHandler.GetDeletionReport)
+				r.Put("/users/me/support-access", impersonationHandler.GrantSupportAccess)
+				r.Get("/users/me/support-access", impersonationHandler.GetSupportAccess)
+				r.Delete("/users/me/support-access", impersonationHandler.RevokeSupportAccess)
+				r.Post("/users/{id}/impersonate", impersonationHandler.ImpersonateUser)
+
+				// Room CRUD Endpoints
+				r.Post("/rooms", roomHandler.CreateRoom)
+				r.Get("/rooms", roomHandler.GetRooms)
+				r.Get("/rooms/search", roomHandler.SearchRooms)
+				r.Get("/rooms/tags", roomHandler.GetTagDirectory)
+				r.Get("/directory", roomHandler.GetDirectory)
+				r.Get("/rooms/{id}", roomHandler.GetRoomByID)
+				r.Put("/rooms/{id}", roomHandler.UpdateRoom)
+				r.Patch("/rooms/{id}", roomHandler.UpdateRoom)
+				r.Delete("/rooms/{id}", roomHandler.DeleteRoom)
+				r.Post("/rooms/{id}/join", roomHandler.JoinRoom)
+				r.Delete("/rooms/{id}/leave", roomHandler.LeaveRoom)
+				r.Get("/rooms/{id}/join-requests", roomHandler.GetJoinRequests)
+				r.Post("/rooms/{id}/join-requests/{userID}/approve", roomHandler.ApproveJoinRequest)
+				r.Delete("/rooms/{id}/join-requests/{userID}", roomHandler.DeleteJoinRequest)
+				r.Get("/rooms/{id}/co-owners", roomHandler.GetCoOwners)
+				r.Post("/rooms/{id}/co-owners", roomHandler.AddCoOwner)
+				r.Delete("/rooms/{id}/co-owners/{userID}", roomHandler.RemoveCoOwner)
+				r.Get("/rooms/{id}/members", roomHandler.GetMembers)
+				r.Get("/rooms/{id}/members/changes", roomHandler.GetMemberChanges)
+				r.Put("/rooms/{id}/members/{userID}/role", roomHandler.SetMemberRole)
+				r.Post("/rooms/{id}/kick/{userID}", moderationHandler.KickMember)
+				r.Post("/rooms/{id}/ban/{userID}", moderationHandler.BanMember)
+				r.Delete("/rooms/{id}/ban/{userID}", moderationHandler.UnbanMember)
+				r.Get("/rooms/{id}/bans", moderationHandler.GetRoomBans)
+				r.Put("/rooms/{id}/settings", roomHandler.UpdateRoomSettings)
+				r.Put("/rooms/{id}/tags", roomHandler.SetRoomTags)
+				r.Put("/rooms/{id}/announcement", roomHandler.SetRoomAnnouncement)
+				r.Delete("/rooms/{id}/announcement", roomHandler.RemoveRoomAnnouncement)
+				r.Get("/rooms/{id}/permissions", roomHandler.GetRoomPermissions)
+				r.Put("/rooms/{id}/permissions", roomHandler.SetRoomPermissions)
+				r.Get("/rooms/{id}/pins", pinHandler.GetPins)
+				r.Put("/rooms/{id}/retention", retentionHandler.SetRetention)
+				r.Get("/rooms/{id}/stats", statsHandler.GetRoomStats)
+				r.Get("/rooms/{id}/analytics/export", statsHandler.ExportRoomAnalytics)
+				r.With(customMiddleware.RateLimit(summaryLimiter)).Get("/rooms/{id}/summary", summaryHandler.GetRoomSummary)
+				r.Post("/rooms/{id}/avatar", roomHandler.UploadAvatar)
+				r.Delete("/rooms/{id}/avatar", roomHandler.DeleteAvatar)
+
+				// Invitation Endpoints
+				r.Post("/rooms/{id}/invites", inviteHandler.CreateInvite)
+				r.Get("/users/me/invites", inviteHandler.GetInvites)
+				r.Post("/invites/{id}/accept", inviteHandler.AcceptInvite)
+				r.Post("/invites/{id}/decline", inviteHandler.DeclineInvite)
+				r.Post("/rooms/{id}/invite-codes", inviteHandler.CreateInviteCode)
+				r.Get("/rooms/{id}/invite-codes", inviteHandler.GetInviteCodes)
+				r.Delete("/rooms/{id}/invite-codes/{code}", inviteHandler.RevokeInviteCode)
+				r.With(customMiddleware.RateLimit(inviteCodeLimiter)).Post("/rooms/join-by-code", inviteHandler.JoinByCode)
+
+				// Group Conversation Endpoints
+				r.Post("/conversations", conversationHandler.CreateConversation)
+				r.Get("/conversations", conversationHandler.GetConversations)
+				r.Post("/conversations/{id}/participants", conversationHandler.AddParticipant)
+				r.Delete("/conversations/{id}/participants/{userID}", conversationHandler.RemoveParticipant)
+				r.Get("/conversations/{id}/search", conversationHandler.SearchConversation)
+				r.Post("/conversations/{id}/encryption", conversationHandler.EnableEncryption)
+
+				// Message Endpoints
+				// History pages are large and compress well.
+				r.With(middleware.Compress(5)).Get("/rooms/{id}/messages", messageHandler.GetRoomMessages)
+				r.Get("/rooms/{id}/reports", moderationHandler.GetRoomReports)
+				r.Get("/rooms/{id}/messages/purges/{purgeID}", moderationHandler.GetMessagePurge)
+				r.Post("/messages/{id}/report", moderationHandler.ReportMessage)
+				r.Patch("/messages/{id}", messageHandler.EditMessage)
+				r.Get("/messages/{id}/history", messageHandler.GetMessageHistory)
+				r.Get("/messages/{id}/events", messageEventHandler.GetMessageEvents)
+				r.Get("/message-events", messageEventHandler.ListMessageEvents)
+				r.Post("/messages/{id}/star", messageHandler.StarMessage)
+				r.Delete("/messages/{id}/star", messageHandler.UnstarMessage)
+				r.Post("/messages/{id}/annotations", messageHandler.AnnotateMessage)
+				r.Get("/messages/{id}/reactions", reactionHandler.GetReactions)
+				r.With(customMiddleware.RateLimit(reactionLimiter)).Put("/messages/{id}/reactions/{emoji}", reactionHandler.AddReaction)
+				r.With(customMiddleware.RateLimit(reactionLimiter)).Delete("/messages/{id}/reactions/{emoji}", reactionHandler.RemoveReaction)
+				r.Put("/messages/{id}/pin", pinHandler.PinMessage)
+				r.Delete("/messages/{id}/pin", pinHandler.UnpinMessage)
+				r.Get("/users/me/starred", messageHandler.GetStarredMessages)
+				r.Get("/users/me/feed", messageHandler.GetFeed)
+				r.Get("/users/me/unreads", unreadHandler.GetUnreads)
+				r.Get("/users/me/sidebar", folderHandler.GetSidebar)
+				r.Post("/users/me/folders", folderHandler.CreateFolder)
+				r.Get("/users/me/folders", folderHandler.GetFolders)
+				r.Patch("/users/me/folders/{id}", folderHandler.UpdateFolder)
+				r.Delete("/users/me/folders/{id}", folderHandler.DeleteFolder)
+				r.Put("/users/me/folders/{id}/rooms/{roomID}", folderHandler.AddFolderRoom)
+				r.Delete("/users/me/folders/{id}/rooms/{roomID}", folderHandler.RemoveFolderRoom)
+				r.Put("/rooms/{id}/read-marker", unreadHandler.MarkRoomRead)
+				r.Put("/rooms/{id}/read", unreadHandler.MarkRoomRead)
+				r.Get("/rooms/{id}/notification-settings", notificationSettingsHandler.GetNotificationSettings)
+				r.Put("/rooms/{id}/notification-settings", notificationSettingsHandler.SetNotificationSettings)
+
+				// Group Endpoints
+				r.Post("/rooms/{id}/groups", groupHandler.CreateGroup)
+				r.Get("/rooms/{id}/groups", groupHandler.GetGroups)
+				r.Put("/rooms/{id}/groups/{groupID}", groupHandler.UpdateGroup)
+				r.Delete("/rooms/{id}/groups/{groupID}", groupHandler.DeleteGroup)
+
+				// Webhook Endpoints
+				r.Post("/rooms/{id}/webhooks", webhookHandler.CreateWebhook)
+				r.Get("/rooms/{id}/webhooks", webhookHandler.GetWebhooks)
+				r.Put("/rooms/{id}/webhooks/{webhookID}", webhookHandler.UpdateWebhook)
+				r.Delete("/rooms/{id}/webhooks/{webhookID}", webhookHandler.DeleteWebhook)
+				r.Post("/rooms/{id}/incoming-webhooks", webhookHandler.CreateIncomingWebhook)
+				r.Get("/rooms/{id}/incoming-webhooks", webhookHandler.GetIncomingWebhooks)
+				r.Delete("/rooms/{id}/incoming-webhooks/{webhookID}", webhookHandler.DeleteIncomingWebhook)
+
+				// Poll Endpoints
+				r.Post("/rooms/{id}/polls", pollHandler.CreatePoll)
+				r.Get("/polls/{id}", pollHandler.GetPoll)
+				r.Post("/polls/{id}/votes", pollHandler.Vote)
+				r.Post("/polls/{id}/close", pollHandler.ClosePoll)
+
+				// Admin Endpoints
+				r.Get("/admin/connections", chatHandler.GetConnections)
+				r.Get("/admin/jobs", jobHandler.GetJobs)
+				r.Get("/admin/jobs/depth", jobHandler.GetJobQueueDepth)
+				r.Post("/admin/jobs/{id}/retry", jobHandler.RetryJob)
+				r.Post("/admin/jobs/{id}/cancel", jobHandler.CancelJob)
+				r.Get("/admin/legal-holds", legalHoldHandler.GetLegalHolds)
+				r.Post("/admin/legal-holds", legalHoldHandler.PlaceLegalHold)
+				r.Delete("/admin/legal-holds/{id}", legalHoldHandler.ReleaseLegalHold)
+				r.Get("/admin/workspaces", workspaceHandler.GetWorkspaces)
+				r.Post("/admin/workspaces", workspaceHandler.CreateWorkspace)
+				r.Put("/admin/workspaces/{id}", workspaceHandler.UpdateWorkspace)
+				r.Delete("/admin/workspaces/{id}", workspaceHandler.DeleteWorkspace)
+
+				extensions.Routes(r)
+			})
+		})
+	}
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(customMiddleware.Versioned)
+		api(r)
 	})
+	if os.Getenv("LEGACY_ROUTES") != "false" {
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.Unversioned("/v1"))
+			api(r)
+		})
+	}
 
-	port := os.Getenv("PORT")
-    if port == "" {
-       log.Fatalf("PORT environment variable is not set")
-    }
+	return &app{router: r, hub: hub, extensions: extensions, options: serverOpts}
+}
 
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-        log.Fatalf("Could not start server: %s\n", err)
-    }
-}
\ No newline at end of file
+// unixAddrPrefix marks listen addresses that are Unix socket paths.
+const unixAddrPrefix = "unix:"
+
+// listen opens a listener for addr: a TCP address such as ":8080", or a Unix
+// socket path prefixed with "unix:". A socket file left behind by a server
+// that did not shut down cleanly is replaced; one still in use is not.
+func listen(addr string, socketMode os.FileMode) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixAddrPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("socket %s is in use", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// serverOptions holds the HTTP server's timeouts and limits.
+type serverOptions struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// HandlerTimeout bounds REST handlers; SlowHandlerTimeout bounds bulk
+	// operations. WebSocket connections are exempt from both.
+	HandlerTimeout     time.Duration
+	SlowHandlerTimeout time.Duration
+	MaxHeaderBytes     int
+	// ShutdownTimeout bounds how long in-flight requests get to finish on
+	// shutdown.
+	ShutdownTimeout time.Duration
+}
+
+// listenOptions holds where the HTTP server listens.
+type listenOptions struct {
+	// Addrs are the TCP addresses and "unix:" socket paths to serve on.
+	Addrs []string
+	// UnixSocketMode is the file mode given to Unix sockets.
+	UnixSocketMode os.FileMode
+}
+
+// listenOptionsFromEnv reads the addresses the server listens on.
+// LISTEN_ADDRS is a comma-separated list of addresses and defaults to PORT on
+// every interface.
+func listenOptionsFromEnv() (listenOptions, error) {
+	opts := listenOptions{UnixSocketMode: 0o660}
+	for _, addr := range strings.Split(os.Getenv("LISTEN_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		if addr == unixAddrPrefix {
+			return opts, fmt.Errorf("LISTEN_ADDRS: %q has no socket path", addr)
+		}
+		opts.Addrs = append(opts.Addrs, addr)
+	}
+	if len(opts.Addrs) == 0 {
+		port := os.Getenv("PORT")
+		if port == "" {
+			return opts, fmt.Errorf("LISTEN_ADDRS or PORT must be set")
+		}
+		opts.Addrs = []string{":" + port}
+	}
+	if v := os.Getenv("UNIX_SOCKET_MODE"); v != "" {
+		mode, err := strconv.ParseUint(v, 8, 32)
+		if err != nil || mode > 0o777 {
+			return opts, fmt.Errorf("UNIX_SOCKET_MODE must be an octal file mode such as 660")
+		}
+		opts.UnixSocketMode = os.FileMode(mode)
+	}
+	return opts, nil
+}
+
+// serverOptionsFromEnv reads the HTTP server's timeouts and header size
+// limit. The write timeout has to outlast the handler timeouts, or slow
+// responses would be cut off instead of answered with 503.
+func serverOptionsFromEnv() (serverOptions, error) {
+	opts := serverOptions{
+		ReadHeaderTimeout:  5 * time.Second,
+		ReadTimeout:        30 * time.Second,
+		WriteTimeout:       75 * time.Second,
+		IdleTimeout:        2 * time.Minute,
+		HandlerTimeout:     15 * time.Second,
+		SlowHandlerTimeout: time.Minute,
+		MaxHeaderBytes:     64 << 10,
+		ShutdownTimeout:    30 * time.Second,
+	}
+	durations := []struct {
+		env string
+		d   *time.Duration
+	}{
+		{"READ_HEADER_TIMEOUT", &opts.ReadHeaderTimeout},
+		{"READ_TIMEOUT", &opts.ReadTimeout},
+		{"WRITE_TIMEOUT", &opts.WriteTimeout},
+		{"IDLE_TIMEOUT", &opts.IdleTimeout},
+		{"HANDLER_TIMEOUT", &opts.HandlerTimeout},
+		{"SLOW_HANDLER_TIMEOUT", &opts.SlowHandlerTimeout},
+		{"SHUTDOWN_TIMEOUT", &opts.ShutdownTimeout},
+	}
+	for _, setting := range durations {
+		v := os.Getenv(setting.env)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return opts, fmt.Errorf("%s must be a positive duration such as 30s", setting.env)
+		}
+		*setting.d = d
+	}
+	if v := os.Getenv("MAX_HEADER_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return opts, fmt.Errorf("MAX_HEADER_BYTES must be a positive number")
+		}
+		opts.MaxHeaderBytes = n
+	}
+	if opts.WriteTimeout <= opts.HandlerTimeout || opts.WriteTimeout <= opts.SlowHandlerTimeout {
+		return opts, fmt.Errorf("WRITE_TIMEOUT must be longer than HANDLER_TIMEOUT and SLOW_HANDLER_TIMEOUT")
+	}
+	return opts, nil
+}
+
+// floodControlFromEnv reads the per-user message rate limits. By default users
+// may send 5 messages per second with bursts of 30, and are never muted.
+func floodControlFromEnv() (service.FloodControl, error) {
+	flood := service.FloodControl{Rate: 5, Burst: 30, MuteDuration: time.Minute}
+	var err error
+	if v := os.Getenv("MESSAGE_RATE_LIMIT"); v != "" {
+		if flood.Rate, err = strconv.ParseFloat(v, 64); err != nil {
+			return flood, fmt.Errorf("MESSAGE_RATE_LIMIT: %w", err)
+		}
+	}
+	if v := os.Getenv("MESSAGE_BURST"); v != "" {
+		if flood.Burst, err = strconv.Atoi(v); err != nil {
+			return flood, fmt.Errorf("MESSAGE_BURST: %w", err)
+		}
+	}
+	if v := os.Getenv("MESSAGE_MUTE_AFTER"); v != "" {
+		if flood.MuteAfter, err = strconv.Atoi(v); err != nil {
+			return flood, fmt.Errorf("MESSAGE_MUTE_AFTER: %w", err)
+		}
+	}
+	if v := os.Getenv("MESSAGE_MUTE_DURATION"); v != "" {
+		if flood.MuteDuration, err = time.ParseDuration(v); err != nil {
+			return flood, fmt.Errorf("MESSAGE_MUTE_DURATION: %w", err)
+		}
+	}
+	return flood, nil
+}
+
+// roomFairnessFromEnv reads how busy rooms are shared between their senders.
+// By default a room may make 10000 deliveries every 10 seconds before each
+// sender is held to an equal share; ROOM_DELIVERY_BUDGET=0 turns this off.
+func roomFairnessFromEnv() (service.RoomFairness, error) {
+	fairness := service.RoomFairness{Budget: 10000, Window: 10 * time.Second}
+	var err error
+	if v := os.Getenv("ROOM_DELIVERY_BUDGET"); v != "" {
+		if fairness.Budget, err = strconv.Atoi(v); err != nil {
+			return fairness, fmt.Errorf("ROOM_DELIVERY_BUDGET: %w", err)
+		}
+	}
+	if v := os.Getenv("ROOM_DELIVERY_WINDOW"); v != "" {
+		if fairness.Window, err = time.ParseDuration(v); err != nil {
+			return fairness, fmt.Errorf("ROOM_DELIVERY_WINDOW: %w", err)
+		}
+	}
+	return fairness, nil
+}
+
+// broadcastSLOFromEnv reads the objective new messages are delivered against.
+// By default 99% of writes to clients should happen within 250ms of the
+// message being received.
+func broadcastSLOFromEnv() (service.BroadcastSLO, error) {
+	slo := service.DefaultBroadcastSLO()
+	var err error
+	if v := os.Getenv("BROADCAST_SLO_THRESHOLD"); v != "" {
+		if slo.Threshold, err = time.ParseDuration(v); err != nil {
+			return slo, fmt.Errorf("BROADCAST_SLO_THRESHOLD: %w", err)
+		}
+		if slo.Threshold <= 0 {
+			return slo, fmt.Errorf("BROADCAST_SLO_THRESHOLD must be positive")
+		}
+	}
+	if v := os.Getenv("BROADCAST_SLO_OBJECTIVE"); v != "" {
+		if slo.Objective, err = strconv.ParseFloat(v, 64); err != nil {
+			return slo, fmt.Errorf("BROADCAST_SLO_OBJECTIVE: %w", err)
+		}
+		if slo.Objective <= 0 || slo.Objective >= 1 {
+			return slo, fmt.Errorf("BROADCAST_SLO_OBJECTIVE must be between 0 and 1, such as 0.99")
+		}
+	}
+	return slo, nil
+}
+
+// pushTemplatesFromEnv reads the push notification templates. Message
+// content is shown unless PUSH_PREVIEW is "false".
+func pushTemplatesFromEnv() service.PushTemplates {
+	templates := service.DefaultPushTemplates()
+	if v := os.Getenv("PUSH_TITLE_MESSAGE"); v != "" {
+		templates.MessageTitle = v
+	}
+	if v := os.Getenv("PUSH_TITLE_MENTION"); v != "" {
+		templates.MentionTitle = v
+	}
+	if v := os.Getenv("PUSH_TITLE_URGENT"); v != "" {
+		templates.UrgentTitle = v
+	}
+	if v := os.Getenv("PUSH_BODY"); v != "" {
+		templates.Body = v
+	}
+	templates.Preview = os.Getenv("PUSH_PREVIEW") != "false"
+	return templates
+}
+
+// jwtConfigFromEnv reads the token settings. The secret comes from
+// JWT_SECRET or, for secrets mounted as files, JWT_SECRET_FILE; the issuer
+// and audience default to "go-chat-app". Tokens are signed with RS256 when
+// JWT_PRIVATE_KEY_FILE names a PEM-encoded RSA private key, and
+// JWT_VERIFY_KEY_FILES lists, comma-separated, PEM-encoded public keys also
+// accepted and published: retired keys and the next one.
+func jwtConfigFromEnv() (customMiddleware.JWTConfig, error) {
+	cfg := customMiddleware.JWTConfig{
+		Secret:   []byte(os.Getenv("JWT_SECRET")),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+	}
+	if path := os.Getenv("JWT_SECRET_FILE"); path != "" {
+		if len(cfg.Secret) > 0 {
+			return cfg, errors.New("set either JWT_SECRET or JWT_SECRET_FILE, not both")
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("JWT_SECRET_FILE: %w", err)
+		}
+		cfg.Secret = bytes.TrimSpace(secret)
+	}
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+		if cfg.SigningKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+			return cfg, fmt.Errorf("JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+	}
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFY_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("JWT_VERIFY_KEY_FILES: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return cfg, fmt.Errorf("JWT_VERIFY_KEY_FILES: %s: %w", path, err)
+		}
+		cfg.VerifyKeys = append(cfg.VerifyKeys, key)
+	}
+	if len(cfg.Secret) == 0 && cfg.SigningKey == nil {
+		return cfg, errors.New("JWT_SECRET, JWT_SECRET_FILE or JWT_PRIVATE_KEY_FILE must be set")
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = "go-chat-app"
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = "go-chat-app"
+	}
+	return cfg, nil
+}
+
+// brandingFromEnv reads the server's own branding, shown on domains no
+// workspace is registered for. The name defaults to "Go Chat"; the logo and
+// colors are optional.
+func brandingFromEnv() service.Branding {
+	branding := service.Branding{Name: os.Getenv("BRANDING_NAME")}
+	if branding.Name == "" {
+		branding.Name = "Go Chat"
+	}
+	for _, v := range []struct {
+		env   string
+		field **string
+	}{
+		{"BRANDING_LOGO_URL", &branding.LogoURL},
+		{"BRANDING_PRIMARY_COLOR", &branding.PrimaryColor},
+		{"BRANDING_ACCENT_COLOR", &branding.AccentColor},
+	} {
+		if value := os.Getenv(v.env); value != "" {
+			*v.field = &value
+		}
+	}
+	return branding
+}
+
+// welcomeOptionsFromEnv reads the welcome message settings. Welcome messages
+// are on unless WELCOME_DM is "false"; WELCOME_ROOMS is a comma-separated
+// list of room IDs to recommend.
+func welcomeOptionsFromEnv() (service.WelcomeOptions, error) {
+	opts := service.WelcomeOptions{
+		Enabled:  os.Getenv("WELCOME_DM") != "false",
+		Message:  os.Getenv("WELCOME_MESSAGE"),
+		RulesURL: os.Getenv("WELCOME_RULES_URL"),
+	}
+	for _, v := range strings.Split(os.Getenv("WELCOME_ROOMS"), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		roomID, err := uuid.Parse(v)
+		if err != nil {
+			return opts, fmt.Errorf("WELCOME_ROOMS: %w", err)
+		}
+		opts.RoomIDs = append(opts.RoomIDs, roomID)
+	}
+	return opts, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/fakes"
+)
+
+// TestOfflineMentionPush checks that mentioning a member who is not
+// connected to the room sends them a push notification.
+func TestOfflineMentionPush(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	carol := env.NewUser("carol")
+	roomID := env.NewRoom(alice, bob, carol)
+	aliceConn := env.Connect(alice, roomID, 0)
+	env.Connect(carol, roomID, 0)
+
+	for _, mentioned := range []*Client{bob, carol} {
+		if err := aliceConn.Send("hello @" + mentioned.Username); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := aliceConn.Receive(receiveTimeout); err != nil {
+			t.Fatalf("alice: receive her message: %v", err)
+		}
+	}
+
+	// Pushes are sent in the background after the message is delivered.
+	var pushes []fakes.Push
+	for deadline := time.Now().Add(receiveTimeout); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if pushes = testProviders.Push.PushedTo(bob.UserID); len(pushes) > 0 {
+			break
+		}
+	}
+	if len(pushes) != 1 {
+		t.Fatalf("bob: got %d pushes, want 1", len(pushes))
+	}
+	if got := pushes[0].Notification.Data["room_id"]; got != roomID {
+		t.Fatalf("bob: got push for room %q, want %s", got, roomID)
+	}
+	if pushes := testProviders.Push.PushedTo(carol.UserID); len(pushes) != 0 {
+		t.Fatalf("carol: got %d pushes while connected, want none", len(pushes))
+	}
+}
+
+// TestRoomAvatarStorage checks that room avatars are stored, and deleted
+// from storage when they are replaced or removed.
+func TestRoomAvatarStorage(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	roomID := env.NewRoom(alice)
+
+	var avatar bytes.Buffer
+	if err := png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	first := uploadAvatar(t, env, alice, roomID, avatar.Bytes())
+	second := uploadAvatar(t, env, alice, roomID, avatar.Bytes())
+	if first == second {
+		t.Fatalf("avatar key %s was reused", first)
+	}
+	if _, ok := testProviders.Storage.Get(first); ok {
+		t.Fatalf("replaced avatar %s is still stored", first)
+	}
+
+	if err := alice.DeleteAvatar(env.ctx, roomID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := testProviders.Storage.Get(second); ok {
+		t.Fatalf("removed avatar %s is still stored", second)
+	}
+	deleted := make(map[string]bool)
+	for _, key := range testProviders.Storage.Deleted() {
+		deleted[key] = true
+	}
+	if !deleted[first] || !deleted[second] {
+		t.Fatalf("deleted %v, want %s and %s", testProviders.Storage.Deleted(), first, second)
+	}
+}
+
+// uploadAvatar sets a room's avatar, checks the image was stored as PNG and
+// returns its storage key.
+func uploadAvatar(t *testing.T, env *Env, c *Client, roomID string, data []byte) string {
+	t.Helper()
+	url, err := c.UploadAvatar(env.ctx, roomID, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, ok := strings.CutPrefix(url, "memory://")
+	if !ok {
+		t.Fatalf("got avatar URL %q, want one from the fake storage", url)
+	}
+	object, ok := testProviders.Storage.Get(key)
+	if !ok {
+		t.Fatalf("avatar %s was not stored", key)
+	}
+	if object.ContentType != "image/png" || !bytes.Equal(object.Data, data) {
+		t.Fatalf("stored avatar %s as %s, %d bytes; want the %d byte PNG", key, object.ContentType, len(object.Data), len(data))
+	}
+	return key
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestConversationSearchEmoji checks that searching a conversation finds the
+// messages with an emoji whether the query and the messages write it as the
+// emoji or as its shortcode.
+func TestConversationSearchEmoji(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	bob := env.NewUser("bob")
+	conversationID, err := alice.CreateConversation(env.ctx, bob.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := env.Connect(alice, conversationID, 0)
+	for _, content := range []string{"ship it :rocket:", "smiling :smile: all day", "😄 again", "no emoji here"} {
+		if err := conn.Send(content); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Receive(receiveTimeout); err != nil {
+			t.Fatalf("receive %q: %v", content, err)
+		}
+	}
+
+	for q, want := range map[string][]string{
+		":smile:":         {"smiling 😄 all day", "😄 again"},
+		"😄":               {"smiling 😄 all day", "😄 again"},
+		"smiling :smile:": {"smiling 😄 all day"},
+		":rocket: ship":   {"ship it 🚀"},
+		"emoji -:smile:":  {"no emoji here"},
+		":tada: no emoji": nil,
+	} {
+		messages, err := bob.SearchConversation(env.ctx, conversationID, q)
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		var got []string
+		for _, message := range messages {
+			got = append(got, message.Content)
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("search %q: got %q, want %q", q, got, want)
+		}
+	}
+}
+
+// TestRoomSearchWildcards checks that LIKE wildcards and backslashes in a room
+// search are matched as written.
+func TestRoomSearchWildcards(t *testing.T) {
+	env := newEnv(t)
+	alice := env.NewUser("alice")
+	suffix := " " + uuid.NewString()[:8]
+	names := []string{"100% uptime" + suffix, "snake_case" + suffix, `C:\temp` + suffix, "plain" + suffix}
+	for _, name := range names {
+		if _, err := alice.CreateRoom(env.ctx, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The queries have no letters or digits, so no room is found by
+	// similarity either.
+	for q, want := range map[string]string{"%": names[0], "_": names[1], `\`: names[2]} {
+		found, err := alice.SearchRooms(env.ctx, q)
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		if len(found) != 1 || found[0] != want {
+			t.Errorf("search %q: got %q, want only %q", q, found, want)
+		}
+	}
+}
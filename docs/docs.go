@@ -15,51 +15,59 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "description": "Log in with username and password to receive a JWT",
-                "consumes": [
-                    "application/json"
+        "/admin/connections": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Lists the WebSocket connections open to this server, longest connected first, optionally only those to a room or of a user, for support triage. Connections that send heartbeat frames include the latency their client reports: the last, average, lowest and highest of their latest 20 round trips, and how far the client's clock is behind the server's. Connections to other servers are not listed. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Log in a user",
+                "summary": "List live connections",
                 "parameters": [
                     {
-                        "description": "User Credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Room ID, or admin for the admin channel",
+                        "name": "room_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.LoginResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Connection"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid room ID or user ID",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "401": {
-                        "description": "Invalid credentials",
+                        "description": "User not authenticated",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Failed to generate token",
+                    "403": {
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
@@ -67,45 +75,71 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Create a new user with a username and password",
-                "consumes": [
-                    "application/json"
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Lists background jobs, such as webhook deliveries, newest first, optionally only those with a status or type. Failed jobs keep the error of their last attempt in last_error. Succeeded and cancelled jobs are deleted after 7 days. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "List background jobs",
                 "parameters": [
                     {
-                        "description": "User Registration Info",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "pending, running, succeeded, failed or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job type, e.g. webhook.delivery",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of jobs (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Job"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid status or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Registration failed",
+                        "description": "Failed to list jobs",
                         "schema": {
                             "type": "string"
                         }
@@ -113,71 +147,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms": {
+        "/admin/jobs/depth": {
             "get": {
-                "description": "Retrieves a list of all available chat rooms.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Counts background jobs by type and status, with when the longest waiting job of each was due, so a backlog of pending jobs or a pile of failed ones stands out. The same counts are exported on /metrics. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Get all rooms",
+                "summary": "Get the job queue depth",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/handler.RoomResponse"
+                                "$ref": "#/definitions/service.JobQueueDepth"
                             }
                         }
                     },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to get rooms",
+                        "description": "Failed to get job queue depth",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/jobs/{id}/cancel": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new chat room. The authenticated user becomes the owner.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stops a pending job from running; it can be retried later. Jobs already running cannot be cancelled. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Create a new room",
+                "summary": "Cancel a background job",
                 "parameters": [
                     {
-                        "description": "Room Name",
-                        "name": "room",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.CreateRoomRequest"
-                        }
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.RoomResponse"
+                            "$ref": "#/definitions/service.Job"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid job ID",
                         "schema": {
                             "type": "string"
                         }
@@ -188,8 +236,26 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Only pending jobs can be cancelled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to create room",
+                        "description": "Failed to cancel job",
                         "schema": {
                             "type": "string"
                         }
@@ -197,20 +263,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{id}": {
-            "get": {
-                "description": "Retrieves details for a specific chat room.",
+        "/admin/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queues a failed or cancelled job to run again right away, with all its attempts available. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Get a single room by ID",
+                "summary": "Retry a background job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Room ID",
+                        "description": "Job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -220,30 +291,100 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.RoomResponse"
+                            "$ref": "#/definitions/service.Job"
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID",
+                        "description": "Invalid job ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Only failed or cancelled jobs can be retried",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retry job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/legal-holds": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists every legal hold in place, newest first. Administrators only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List legal holds",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.LegalHold"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to list legal holds",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates the name of a room. Only the room owner can perform this action.",
+                "description": "Preserves a user's or a room's messages until the hold is released: a message is held when its sender or its room is. Retention purges, bulk deletion and purges skip held messages, and accounts and rooms with held messages cannot be deleted, by their owners or when rooms expire. Placing the hold is recorded in the audit log. Administrators only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -251,36 +392,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Update a room",
+                "summary": "Place a legal hold",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Room ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "New Room Name",
-                        "name": "room",
+                        "description": "User or room, and reason",
+                        "name": "hold",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateRoomRequest"
+                            "$ref": "#/definitions/handler.LegalHoldRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.RoomResponse"
+                            "$ref": "#/definitions/service.LegalHold"
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID or request body",
+                        "description": "Invalid request body, subject or reason",
                         "schema": {
                             "type": "string"
                         }
@@ -292,40 +426,48 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden: You are not the owner",
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "User or room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Already under legal hold",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to update room",
+                        "description": "Failed to place legal hold",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/legal-holds/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a room. Only the room owner can perform this action.",
+                "description": "Lifts a legal hold, so its messages are subject to retention and deletion again. Releasing it is recorded in the audit log. Administrators only.",
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Delete a room",
+                "summary": "Release a legal hold",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Room ID",
+                        "description": "Legal hold ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -339,7 +481,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID",
+                        "description": "Invalid legal hold ID",
                         "schema": {
                             "type": "string"
                         }
@@ -351,19 +493,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden: You are not the owner",
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Legal hold not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete room",
+                        "description": "Failed to release legal hold",
                         "schema": {
                             "type": "string"
                         }
@@ -371,85 +513,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{id}/join": {
-            "post": {
+        "/admin/workspaces": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Adds the authenticated user to a room's member list.",
+                "description": "Lists every workspace, by domain. Administrators only.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "rooms"
-                ],
-                "summary": "Join a room",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Room ID to join",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List workspaces",
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Workspace"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid room ID",
+                    "401": {
+                        "description": "User not authenticated",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "403": {
+                        "description": "Forbidden: Administrators only",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to join room",
+                        "description": "Failed to list workspaces",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/rooms/{id}/leave": {
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Removes the authenticated user from a room's member list.",
+                "description": "Registers a workspace for a custom domain or subdomain, with its branding, for white-label deployments. Requests made to the domain are served as usual, with GET /branding returning the workspace's branding; point the domain's DNS at the server and serve it a certificate. Rooms and users are shared by every workspace. Other servers see the workspace within a minute. Administrators only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Leave a room",
+                "summary": "Register a workspace",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Room ID to leave",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Domain and branding",
+                        "name": "workspace",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WorkspaceRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Workspace"
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID",
+                        "description": "Invalid request body, domain or branding",
                         "schema": {
                             "type": "string"
                         }
@@ -460,8 +605,20 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Domain is already registered to a workspace",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to leave room",
+                        "description": "Failed to register workspace",
                         "schema": {
                             "type": "string"
                         }
@@ -469,72 +626,140 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
-            "get": {
-                "description": "Retrieves a list of all users in the system.",
+        "/admin/workspaces/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces a workspace's domain and branding. Other servers see the change within a minute. Administrators only.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "summary": "Update a workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Domain and branding",
+                        "name": "workspace",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WorkspaceRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handler.UserResponse"
-                            }
+                            "$ref": "#/definitions/service.Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid workspace ID, request body, domain or branding",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Domain is already registered to a workspace",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to get users",
+                        "description": "Failed to update workspace",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/users/search": {
-            "get": {
-                "description": "Searches for users by username.",
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Removes a workspace; its domain is then served with the server's own branding. Administrators only.",
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Search for users",
+                "summary": "Delete a workspace",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handler.UserResponse"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Query parameter 'q' is required",
+                        "description": "Invalid workspace ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to search users",
+                        "description": "Failed to delete workspace",
                         "schema": {
                             "type": "string"
                         }
@@ -542,53 +767,72 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/branding": {
             "get": {
-                "description": "Retrieves details for a specific user.",
+                "description": "Returns the name, logo and colors to show the server as: those of the workspace registered for the domain in the request's Host header, or the server's own otherwise. It needs no authentication, so clients can brand their sign-in screens.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "workspaces"
                 ],
-                "summary": "Get a single user by ID",
-                "parameters": [
+                "summary": "Get branding",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Branding"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Lists the group conversations the current user takes part in, with their participants, most recently active first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "List my group conversations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Conversation"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid user ID",
+                    "401": {
+                        "description": "User not authenticated",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "500": {
+                        "description": "Failed to get conversations",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a user's username and/or password. Users can only update their own account.",
+                "description": "Starts a private conversation between the current user and up to 49 others. Group conversations work like rooms: messages go through /ws/{id} and /rooms/{id}/messages. They are never listed, searched or joined, and only participants can see them.\nThe other participants' open WebSocket connections receive a conversation.added event with the conversation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -596,36 +840,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "conversations"
                 ],
-                "summary": "Update a user's account",
+                "summary": "Start a group conversation",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "User data to update",
-                        "name": "user",
+                        "description": "Participants and optional name",
+                        "name": "conversation",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UpdateUserRequest"
+                            "$ref": "#/definitions/handler.CreateConversationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.UserResponse"
+                            "$ref": "#/definitions/service.Conversation"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID or request body",
+                        "description": "Invalid request body, or too few or too many participants",
                         "schema": {
                             "type": "string"
                         }
@@ -636,49 +873,54 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden: You can only update your own account",
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user",
+                        "description": "Failed to create conversation",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/conversations/{id}/encryption": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a user's account. Users can only delete their own account.",
+                "description": "Marks a group conversation as end-to-end encrypted. From then on its WebSocket connections may only send encrypted frames, whose payload is a message with ciphertext as its content; message frames are rejected with an encryption_required error frame so plaintext cannot be sent by mistake. Encrypted messages are stored and relayed as they are, with kind \"encrypted\", and are left out of push previews and translation. Any participant can opt in, and it cannot be undone; opting in again changes nothing.\nThe conversation's open WebSocket connections receive a conversation.encrypted event with the conversation.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "users"
+                    "conversations"
                 ],
-                "summary": "Delete a user's account",
+                "summary": "Opt a group conversation in to end-to-end encryption",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Conversation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Conversation"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid conversation ID",
                         "schema": {
                             "type": "string"
                         }
@@ -689,14 +931,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden: You can only delete your own account",
+                    "404": {
+                        "description": "Conversation not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete user",
+                        "description": "Failed to enable encryption",
                         "schema": {
                             "type": "string"
                         }
@@ -704,36 +946,119 @@ const docTemplate = `{
                 }
             }
         },
-        "/ws/{roomID}": {
-            "get": {
+        "/conversations/{id}/participants": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Upgrades the HTTP connection to a WebSocket connection for a specific chat room. The user must be authenticated and a member of the room.",
-                "tags": [
-                    "chat"
+                "description": "Adds a user to a group conversation the current user takes part in. Any participant can add people, up to 50 participants. The added user's open WebSocket connections receive a conversation.added event.",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "Join and connect to a chat room",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Add a participant to a group conversation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Room ID to connect to",
-                        "name": "roomID",
+                        "description": "Conversation ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "User to add",
+                        "name": "participant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddParticipantRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Conversation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid conversation ID or request body, or the conversation is full",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Conversation or user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already a participant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add participant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/participants/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a user from a group conversation and closes their connection to it. Participants can leave by removing themselves, and the creator can remove anyone. When the creator leaves, the longest-standing participant takes over; when the last participant leaves, the conversation is deleted.",
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Remove a participant from a group conversation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Participant's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID",
+                        "description": "Invalid conversation or user ID",
                         "schema": {
                             "type": "string"
                         }
@@ -745,114 +1070,11409 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "User is not a member of this room",
+                        "description": "Forbidden: Only the conversation's creator can remove other participants",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Conversation or participant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove participant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Finds the messages of a group conversation that match q, best match first, then newest, with their senders' profiles. Queries are full-text and web search style: every word must appear, \"quoted phrases\" must appear as written, -word must not appear, and or matches either side. Words are matched whole, without stemming. Emoji, written as emoji or as :shortcodes:, must appear as written. Direct messages to other participants are never found. Only participants can search a conversation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Search a group conversation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search query, at most 200 characters",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of messages (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid conversation ID, q or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Conversation not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search conversation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/directory": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pages through the public rooms that are not archived, with their tags, member and message counts and last activity, for discovering rooms to join. Rooms with the most members come first by default, or those with the most messages (message_count) or the most recent message (last_activity). Pass the X-Next-Cursor response header back as cursor, with the same sort, to fetch the next page; it is absent on the last page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Browse the room directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "member_count",
+                            "message_count",
+                            "last_activity"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rooms with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.DirectoryRoom"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get the room directory",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites/{id}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Joins the room the current user was invited to, private rooms included, and removes the invitation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Invite"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid invitation ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are banned from this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Invitation not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Invitation has expired or room is archived",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error or failed to upgrade connection",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
+                    "500": {
+                        "description": "Failed to accept invitation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites/{id}/decline": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes an invitation addressed to the current user without joining the room.",
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Decline an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid invitation ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Invitation not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to decline invitation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Log in with username and password to receive a JWT",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "User Credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to generate token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/message-events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the events of every message, in log order, after the position given by after (0 for the start of the log). Another instance can replicate the messages by appending these events to its own log, which builds its read models the same way. Events from the last few seconds are held back until every transaction that could still add one before them has committed, so a reader that keeps asking from the last position it saw misses nothing. Administrators only; unavailable unless MESSAGE_STORAGE is events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Page through the message event log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Position of the last event already read",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.MessageEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid after or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message events not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to list message events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the content of one of the user's own text messages. The previous content is kept in the message's history, and the edited message is broadcast as a message.edited event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Edit a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New content",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.EditMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID or content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only the sender can edit a message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to edit message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/annotations": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Attaches structured data, such as a deployment marker, ticket link or sentiment score, to an existing message without changing its content. Intended for bots and integrations.\nThe annotation is broadcast to the message's audience as a message.annotated event and returned with the message in history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Annotate a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation kind and data",
+                        "name": "annotation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Annotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID or annotation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to annotate message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists everything that happened to a message, oldest first: its creation, edits, annotations and deletion, each with who did it and when. Deleted messages keep their history. Only room owners, moderators and administrators can see it, and only administrators that of direct messages. Unavailable unless MESSAGE_STORAGE is events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message's event history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.MessageEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found or message events not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get message events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the previous versions of a message, oldest first. Anyone who can see the message can view its history; administrators can view the history of any message.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message's edit history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get message history",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/pin": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pins the message in its room. Pinning a pinned message has no effect. Members need the role the room's pin permission requires, moderator by default. A room can have at most 50 pinned messages, and direct messages cannot be pinned. Members connected to the room receive a message.pinned event, and the room's pin webhooks are called.",
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Pin a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID, or it is a direct message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Your role in this room does not allow pinning messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "rooms can have at most 50 pinned messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to pin message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unpins the message. Unpinning a message that is not pinned has no effect. Members need the role the room's pin permission requires. Members connected to the room receive a message.unpinned event, and the room's pin webhooks are called.",
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Unpin a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID, or it is a direct message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Your role in this room does not allow pinning messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to unpin message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/reactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how many users reacted to the message with each emoji, in the order the emoji were first used, and the current user's own reactions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message's reactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.MessageReactions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get reactions",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/reactions/{emoji}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reacts to the message with an emoji, given as the URL-encoded emoji or a :shortcode:. Reacting twice with the same emoji has no effect. A message can have at most 20 different emoji, and each user can add or remove 30 reactions a minute. Changes are sent to the message's audience as reactions.updated events carrying the full counts; in busy rooms they are batched to at most one per message per second.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "React to a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Emoji or shortcode",
+                        "name": "emoji",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.MessageReactions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID or reaction",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "messages can have at most 20 different reactions",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add reaction",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Takes back the current user's reaction to the message. Removing a reaction that was not made has no effect. Removals count toward the same limit as reactions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Remove a reaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Emoji or shortcode",
+                        "name": "emoji",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.MessageReactions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID or reaction",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove reaction",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/report": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reports a message the user can see to the room's owners and the administrators. Connected administrators receive a message.reported event on the admin channel (/ws/admin). Each user can report a message once, and not their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Report a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the report",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID or reason, or the message is your own",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "You already reported this message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to report message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/star": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a message to the current user's starred list. Starring an already starred message has no effect.",
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Star a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to star message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a message from the current user's starred list.",
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Unstar a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to unstar message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/polls/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a poll with its current results. Only members of the poll's room can view it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Get a poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Poll ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid poll ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Poll not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/polls/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Closes a poll so no more votes are accepted. Only the poll creator or a room owner or co-owner can close it. The final results are broadcast to the room.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Close a poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Poll ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid poll ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only the creator or room owner can close the poll",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Poll not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Poll is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to close poll",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/polls/{id}/votes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records the authenticated user's vote, replacing any earlier vote. The new counts are broadcast to the room.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Vote on a poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Poll ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Chosen option",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.VoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid poll ID, body or option",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Poll not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Poll is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to vote",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/presence": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reports whether each of the users is online, away or offline, in the order given, so clients can fill in contact lists without connecting to each room. Users are online while connected to any room, and away once none of their connections has sent a message, typing or read frame for 5 minutes; heartbeats do not count. The presence frames sent over WebSocket report users as online until they go offline.\nPresence is only shared between users who have a room in common, as with presence frames: anyone else is reported offline. Only connections to this server are counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users' presence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated user IDs, at most 500",
+                        "name": "user_ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Presence"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user_ids",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get presence",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/rooms/{id}/messages": {
+            "get": {
+                "description": "Returns the name, topic and latest 50 messages, oldest first, of a room whose owners turned on public_readable, for embedding its chat log on websites. No authentication is needed, and any site may fetch it. Only messages sent to the whole room are included, with their sender's username and avatar; direct messages, metadata and mentions never are. Responses are cached for 30 seconds, so new messages, edits and settings changes can take that long to show, and requests are rate-limited per client address. Rooms that do not exist and rooms that are not public-readable are both reported as not found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Read a public-readable room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.PublicRoomLog"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "public, max-age=30"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Create a new user with a username and password. Unless disabled, the system bot sends the new user a welcome message.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User Registration Info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Registration failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a page of the chat rooms visible to the current user. Private rooms are only listed to their owners and members. Pass the X-Next-Cursor response header back as cursor, with the same sort, to fetch the next page; it is absent on the last page.\nRooms are sorted newest first by default, or by most recent message (last_activity) or most members (member_count). Each room includes its member_count and last_activity_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List rooms",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "last_activity",
+                            "member_count"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rooms owned by this user ID, or me",
+                        "name": "owned_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rooms the current user is a member of; me or the current user's ID",
+                        "name": "member_of",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Only rooms with this visibility",
+                        "name": "visibility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rooms with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.RoomResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You can only list your own memberships",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get rooms",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new chat room. The authenticated user becomes the owner. Rooms are public unless visibility is \"private\". With expires_in_minutes the room is temporary: it is deleted, along with everything in it, once expires_at passes, and live connections to it are closed with reason \"room.deleted\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Create a new room",
+                "parameters": [
+                    {
+                        "description": "Room name and visibility",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateRoomRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, visibility or expires_in_minutes",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new room, owned by the current user, from a bundle made by GET /rooms/{id}/export, all at once. Members and message senders are matched to this server's users by username. Members without an account here are skipped and listed in skipped_members; messages from senders without one are sent by the system bot. Imported messages are sent at the time of the import, each with {\"imported\": {\"id\", \"sender\", \"sent_at\"}} in its metadata recording the original, and count as read for the room's members. Bundles are limited to 64 MiB, 10000 members and 10000 messages.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Import a room",
+                "parameters": [
+                    {
+                        "description": "Room bundle",
+                        "name": "bundle",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomBundle"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or bundle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Bundle too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/join-by-code": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Joins the room an invite code is for, private rooms included, without being on its member list or invited. Codes are case-insensitive. Joining a room the user is already a member of succeeds without using the code. Attempts are rate limited to slow down guessing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Join a room with an invite code",
+                "parameters": [
+                    {
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.JoinByCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.JoinByCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are banned from this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Invite code not found, expired or used up",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to join room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Finds the rooms visible to the current user whose names contain the query or are similar to it, or whose descriptions contain it. Name matches come first, best match first. Private rooms are only found by their owners and members.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Search rooms",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of rooms (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.RoomResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Query parameter 'q' is required, or invalid limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search rooms",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the tags of the rooms visible to the current user, with how many of those rooms carry each, most used first. Together with GET /rooms?tag= it makes a categorized room directory.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List room tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.TagResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tags",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}": {
+            "get": {
+                "description": "Retrieves details for a specific chat room, with its tags and its announcement, if it has one that has not expired. Group conversations are only visible to their participants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a single room by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the name, topic or description of a room; omitted fields are left as they are. Only room owners and moderators can perform this action.\nSend the room's ETag in If-Match to update only if nobody else changed the room since it was read. Members connected to the room receive a room.updated event with its new details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the room version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New name, topic or description",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateRoomRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or request body, empty name, or topic or description too long",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Room was modified by someone else",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a room along with its pins, invitations, invite codes, messages and memberships, all at once. Live WebSocket connections to the room are closed with code 1001 and reason \"room.deleted\". Only room owners and co-owners can perform this action. Rooms under legal hold, or holding messages of a user who is, cannot be deleted until it is released.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Delete a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "The room's messages are under legal hold",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the name, topic or description of a room; omitted fields are left as they are. Only room owners and moderators can perform this action.\nSend the room's ETag in If-Match to update only if nobody else changed the room since it was read. Members connected to the room receive a room.updated event with its new details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the room version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New name, topic or description",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateRoomRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or request body, empty name, or topic or description too long",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Room was modified by someone else",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/analytics/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a CSV time series of a room's activity, one row per UTC day from from through to: date, messages sent and active members (members who sent a message, bots left out). Direct messages are not counted. The series is aggregated by the stats job every STATS_INTERVAL from the day the room opted in to stats (stats_enabled), going back up to a year, and outlives messages purged by retention; days before that are zero.\nDefaults to the last 30 days; at most 366 days at once. Only room owners and co-owners can export.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Export a room's analytics as CSV",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD (default 29 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD (default today, UTC)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "date,messages,active_members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or date range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only room owners can export analytics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or stats not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to export room analytics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/announcement": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pins an announcement at the top of the room, replacing the current one, until expires_at or until it is removed. It is included in GET /rooms/{id}, and members connected to the room receive a room.announcement event with it. No event is sent when it expires; clients hide it at its expires_at. Only room owners and co-owners can perform this action.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Set a room's announcement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Announcement text and expiry",
+                        "name": "announcement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AnnouncementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Announcement"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body, content or expiry",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set announcement",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Takes down the room's announcement. Members connected to the room receive a room.announcement event with a null announcement. Only room owners and co-owners can perform this action.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Remove a room's announcement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found, or it has no announcement",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove announcement",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/auto-replies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the room's automatic replies in the order they are checked, oldest first. Only owners and co-owners can see them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-replies"
+                ],
+                "summary": "List a room's automatic replies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/autoreply.Rule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only owners can manage automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds an automatic reply to the room: when a room message contains one of the keywords, the system bot answers in the room with the reply, quoting the message. Rules are checked oldest first and only the first matching enabled rule answers. Each sender gets a rule's reply at most once per its cooldown. Direct messages are never answered. A room can have up to 20 rules; only owners and co-owners can manage them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-replies"
+                ],
+                "summary": "Create an automatic reply",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Keywords, reply and cooldown",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/autoreply.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/autoreply.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body, keywords, reply or cooldown",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only owners can manage automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Rooms can have at most 20 automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create automatic reply",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/auto-replies/{ruleID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the keywords, reply, cooldown and enabled state of one of the room's automatic replies. Cooldowns already running keep their length.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-replies"
+                ],
+                "summary": "Replace an automatic reply",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Automatic reply ID",
+                        "name": "ruleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Keywords, reply, cooldown and enabled state",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/autoreply.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/autoreply.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, rule ID, request body, keywords, reply or cooldown",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only owners can manage automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or automatic reply not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update automatic reply",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes one of the room's automatic replies.",
+                "tags": [
+                    "auto-replies"
+                ],
+                "summary": "Delete an automatic reply",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Automatic reply ID",
+                        "name": "ruleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or rule ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Only owners can manage automatic replies",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or automatic reply not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete automatic reply",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets a room's avatar from a PNG, JPEG, GIF or WebP image of at most 2 MiB, sent as the avatar field of a multipart form. The previous avatar is deleted. Only room owners and moderators can change it.\nMembers connected to the room receive a room.updated event with the new avatar URL.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Upload a room avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or missing avatar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Avatar must be at most 2 MiB",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Avatar must be a PNG, JPEG, GIF or WebP image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to upload avatar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a room's avatar and deletes the image. Only room owners and moderators can change it. Members connected to the room receive a room.updated event.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Remove a room avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or has no avatar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove avatar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/ban/{userID}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Bans a user from the room for duration_minutes, or for good when it is omitted. Members are removed and their open WebSocket connection to the room is closed; banned users cannot join, be added or accept invitations until the ban ends. Banning a banned user replaces the ban.\nOwners can ban moderators, members and other users, moderators only members and other users. The room's owners cannot be banned. The room's webhooks subscribed to ban events receive the ban.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Ban a user from a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ban duration",
+                        "name": "ban",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Ban"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, user ID or duration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room, or the user outranks you",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to ban user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lifts a user's ban from the room so they can join it again. Only room owners and moderators can lift bans.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Lift a ban",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Banned user's ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or user is not banned",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to lift ban",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/bans": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the bans of a room that have not expired, newest first. Only room owners and moderators can see them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List a room's bans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Ban"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get bans",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/co-owners": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the co-owners of a room, the members with the owner role, longest-standing first. The owner the room was created by is its owner_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List room co-owners",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.UserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get co-owners",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Makes a member of the room a co-owner by giving them the owner role. Co-owners have the same rights as the owner, so the room stays managed if the owner leaves. Only room owners and co-owners can add co-owners. A co-owner who leaves the room or is removed from it stops being a co-owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Add a room co-owner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User to make a co-owner",
+                        "name": "owner",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CoOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body, or user is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already an owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add co-owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/co-owners/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes a user's co-ownership; they stay a member with the member role. Room owners and co-owners can remove any co-owner, and co-owners can step down themselves. The owner the room was created by cannot be removed.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Remove a room co-owner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Co-owner's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or user is not a co-owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove co-owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Downloads the room's configuration (name, topic, description, visibility, settings, retention limits, tags and permissions) and members as a JSON bundle, for backup or to import it on another deployment with POST /rooms/import. With messages=true, the bundle also holds the room's latest 10000 text messages, oldest first; direct messages and polls are left out. Users are named by username, so the bundle does not depend on this server's IDs. The avatar is not included. Only room owners and co-owners can export a room.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Export a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include messages",
+                        "name": "messages",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomBundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to export room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/groups": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the groups of a room with their members. Only members of the room can view them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "List a room's user groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Group"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get groups",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a named group of room members that can be mentioned together, e.g. @oncall. Only room owners and co-owners can manage groups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create a user group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Group name and members",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, name or members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Group name already taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/groups/{groupID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renames a group and/or replaces its members. Only room owners and co-owners can manage groups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Update a user group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "groupID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name and/or members",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, name or members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or group not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Group name already taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a group. Only room owners and co-owners can manage groups.",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete a user group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "groupID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or group not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/incoming-webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the incoming webhooks of a room, oldest first, with when each was last used but without their tokens. Only room owners and co-owners can manage webhooks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List a room's incoming webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.IncomingWebhook"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get incoming webhooks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds an incoming webhook to a room, so that an outside service such as a CI server or an alerting system can post to it. The response's url, which contains the webhook's secret token, is only returned here; anyone holding it can post to the room with POST /webhooks/{token}. A room can have up to 10. Only room owners and co-owners can manage webhooks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create an incoming webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Integration name",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.IncomingWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.IncomingWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body or name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "rooms can have at most 10 incoming webhooks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create incoming webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/incoming-webhooks/{webhookID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes an incoming webhook; its URL stops working right away. Messages it already posted stay. Only room owners and co-owners can manage webhooks.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete an incoming webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Incoming webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or incoming webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete incoming webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/invite-codes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the room's invite codes that can still be used, newest first. Owners and co-owners see every code; other users see the codes they created.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "List a room's invite codes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.InviteCode"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get invite codes",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a short-lived code that lets anyone holding it join the room with POST /rooms/join-by-code, private rooms included, until it expires or has been used max_uses times. The response includes a shareable link when the server sets INVITE_LINK_URL. Members need the role the room's invite permission requires: by default any member can create codes for a public room, and only owners and co-owners for a private one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Create an invite code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Expiry and use limit",
+                        "name": "code",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.InviteCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.InviteCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body, expiry or use limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room or cannot invite to it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create invite code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/invite-codes/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes an invite code so nobody else can join with it. Users who already joined stay members. Owners and co-owners can revoke any code of the room; other users only the codes they created.",
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Revoke an invite code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You did not create this invite code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or invite code not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke invite code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/invites": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Invites a user to join a room until the invitation expires. Members need the role the room's invite permission requires: by default any member can invite to a public room, and only owners and co-owners to a private one. Inviting the same user again renews the invitation.\nThe invited user's open WebSocket connections receive a room.invited event with the invitation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Invite a user to a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User to invite",
+                        "name": "invite",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.InviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Invite"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body or expiry, or the user is inviting themselves",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room or cannot invite to it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or invited user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to invite user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/join": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the authenticated user to a room's member list. Users invited to a private room join it straight away, which accepts their invitation; anyone else files a join request, answered with 202, which an owner or co-owner has to approve. Owners can also add members directly with /rooms/{id}/members/bulk.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Join a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID to join",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Join request sent",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are banned from this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to join room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/join-requests": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the users waiting to join a private room, oldest request first. Only room owners and co-owners can see them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List pending join requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.UserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get join requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/join-requests/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a pending join request without adding the user. Room owners and co-owners can decline any request, and users can withdraw their own.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Decline or withdraw a join request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Requesting user's ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or no pending request from this user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete join request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/join-requests/{userID}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the user who asked to join the room as a member. Only room owners and co-owners can approve requests.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Approve a join request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Requesting user's ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or no pending request from this user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to approve join request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/kick/{userID}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a member from the room and closes their open WebSocket connection to it; they can join again. Owners can kick moderators and members, moderators only members. The room's owners cannot be kicked.\nThe room's webhooks subscribed to leave events receive the change.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Kick a member from a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room, or the member outranks you",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or user is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to kick member",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/leave": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the authenticated user from a room's member list.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Leave a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID to leave",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to leave room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a page of a room's members in the order they joined, with their role, whether they are connected to the room right now and their custom status. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.\nOnly members and owners of the room can list its members. Keep the X-Member-Version header of the first page to catch up later with GET /rooms/{id}/members/changes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List room members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.MemberResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Member-Version": {
+                                "type": "integer",
+                                "description": "Version of the room's members when the page was read"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, limit or cursor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/members/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds and removes many users at once. Only room owners and co-owners can perform this action. Changes are applied in one transaction; individual changes that cannot be applied (unknown user, already a member, banned, not a member) are reported in failed without affecting the rest.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Add or remove many room members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Users to add and remove",
+                        "name": "members",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkMembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkMembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/members/changes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the changes to a room's members after since_version, oldest first, so clients holding a member list need not fetch it again. Each change adds, removes or changes the role of a member. Apply them in order and keep the returned version.\nWhen reset is set, the changes are no longer all known, or are too many, and the client must fetch the full list from GET /rooms/{id}/members. Connected members also receive each change as a members.changed event; a gap between versions means one was missed. Only members and owners of the room can see its changes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Catch up with changes to room members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Member version the client holds",
+                        "name": "since_version",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.MemberDelta"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or since_version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get member changes",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/members/{userID}/role": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Promotes or demotes a member of the room. Owners have every right over the room; moderators can also edit its settings, bulk-delete its messages and see its reports; members have no extra rights. Only room owners can change roles, and the owner the room was created by always stays an owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Change a member's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MemberRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, user ID or role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or user is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to change role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the most recent messages in a room, oldest first, with each sender's username and avatar included. Pass the seq of the oldest message as before_seq to page further back. Responses are gzip or deflate compressed when the client accepts it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get the latest messages in a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of messages (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return messages older than this sequence number",
+                        "name": "before_seq",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/messages/bulk-delete": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes up to 1000 messages by ID right away, answering 200; connected members receive a single messages.deleted event listing the deleted IDs. Only room owners, moderators and administrators can do this, and every deletion is recorded in the audit log.\nDeleting by user_id and/or time range [from, to), either end of which may be left open, starts a purge instead, answered with 202 and the pending purge. It deletes, in the background and in batches, the matching messages sent before it was requested; follow its progress with GET /rooms/{id}/messages/purges/{purgeID}. Once it finishes, connected members receive a single messages.purged event with the purge, and drop the messages sent by its user_id, if any, from its from, if any, until its to.\nMessages under legal hold are skipped either way.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Delete messages in bulk",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message IDs, or user and/or time range",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.BulkDelete"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.DeletedMessages"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/service.MessagePurge"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or filter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/messages/purges/{purgeID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a purge started with POST /rooms/{id}/messages/bulk-delete: its status, how many messages it covered when requested and how many it has deleted so far. Only room owners, moderators and administrators can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a purge's progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Purge ID",
+                        "name": "purgeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.MessagePurge"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room or purge ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or purge not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get purge",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/notification-settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how much the current user is notified about the room, \"mentions\" unless they chose otherwise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get my notification settings for a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomNotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get notification settings",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets how much the current user is notified about the room. With \"all\", every message is pushed while they are offline. With \"mentions\", the default, only mentions, direct messages and urgent messages are. With \"none\", the room is muted: nothing is pushed, urgent messages included, and mentions and replies are left out of the activity feed. Unread counts are kept at every level. The setting is removed when the user leaves the room.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Set my notification settings for a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notification level",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.NotificationSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomNotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body or level",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set notification settings",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the least role a member needs to invite users, send messages with attachments and pin messages in the room, with defaults applied, so clients can hide what the user cannot do. Owners and co-owners can always do all of it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a room's permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomPermissions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get permissions",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the least role (\"owner\", \"moderator\" or \"member\") a member needs to invite users and create invite codes (invite), send messages with attachments listed under \"attachments\" in their metadata (attachments), and pin or unpin messages (pin). Permissions left out keep their value, and an empty string restores the default: invite is member in public rooms and owner in private ones, attachments member and pin moderator. Messages with attachments from members below the required role are rejected with an attachments_not_allowed error frame. Only room owners and co-owners can perform this action.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Set a room's permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permissions to change",
+                        "name": "permissions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomPermissionsUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomPermissions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body or role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set permissions",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/pins": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the room's pinned messages, most recently pinned first, with who pinned them and when.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a room's pinned messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.PinnedMessage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get pinned messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/polls": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Posts a poll message to a room. The poll is broadcast to connected members and kept in history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Create a poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question and options",
+                        "name": "poll",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatePollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or poll",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create poll",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/read": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves the current user's read marker for a room forward to the message named by seq or message_id, or to the latest message when the body is omitted. The marker never moves back, and unread counts are counted from it.\nEvery WebSocket connection of the user, on any device and in any room, receives an \"unread\" frame with the new marker (last_read_seq) and counts, so devices stay in sync. Connected clients can send a \"read\" frame with the same payload instead. PUT /rooms/{id}/read is the older name of this endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Move a room's read marker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sequence number or ID of the last message read",
+                        "name": "cursor",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReadCursor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Unread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found in this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark room read",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/read-marker": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves the current user's read marker for a room forward to the message named by seq or message_id, or to the latest message when the body is omitted. The marker never moves back, and unread counts are counted from it.\nEvery WebSocket connection of the user, on any device and in any room, receives an \"unread\" frame with the new marker (last_read_seq) and counts, so devices stay in sync. Connected clients can send a \"read\" frame with the same payload instead. PUT /rooms/{id}/read is the older name of this endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Move a room's read marker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sequence number or ID of the last message read",
+                        "name": "cursor",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReadCursor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Unread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found in this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark room read",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/reports": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the reports filed about messages in a room, newest first. Only room owners, moderators and administrators can see them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "List a room's message reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of reports (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Report"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get reports",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/retention": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets how long a room keeps its messages, by age and/or count. Older messages are purged by a background job and each purge is recorded in the audit log.\nRoom owners and co-owners can set the limits. Admins can set them for any room and can place a room on hold, which suspends purging and locks the policy against changes by the owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Set a room's retention policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the room version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Retention policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RetentionPolicy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Room was modified by someone else",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set retention policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/settings": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a room's settings. Only room owners and moderators can perform this action. New connections use the updated settings.\nSend the room's ETag in If-Match to update only if nobody else changed the room since it was read.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update room settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the room version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Room settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or settings",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Room was modified by someone else",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update room settings",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a room's engagement stats: messages, busiest hour and top senders over the last 30 days, and its current and longest daily streaks. Direct messages are not counted and bots are not ranked.\nRooms opt in through their settings (stats_enabled). The stats are recomputed by a background job every STATS_INTERVAL, so they can lag behind the room. Only members can see them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a room's stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found, stats not enabled or not computed yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get room stats",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/summary": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends the room's messages after since, or after the current user's read cursor when since is omitted, to the configured summarization provider and returns a short digest. At most 64 KiB of content is summarized at once; when to_seq is not the room's latest message, ask again with since=to_seq for the rest.\nSummaries are off unless the server enables them (SUMMARIES_ENABLED) and the room opts in through its settings (summaries_enabled). Only members can ask for them. Direct messages are never sent to the provider, and only each message's sender username, content and time are. Summaries are cached in memory per span of messages and never stored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Summarize what I missed in a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last message to leave out",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.RoomSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or since",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found or summaries not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to summarize room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "502": {
+                        "description": "Summarization provider failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/tags": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the tags, or categories, of a room. Tags are 1-32 lowercase letters, digits or '-'; they are lowercased and deduplicated, and a room can have at most 10. List rooms with a tag with GET /rooms?tag=. Only room owners and moderators can perform this action.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Set a room's tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tags",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RoomTagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, request body or tags",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not a moderator of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set room tags",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the webhooks of a room, oldest first, without their secrets. Only room owners and co-owners can manage webhooks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List a room's webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Webhook"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get webhooks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds an outbound webhook to a room. Each event type it subscribes to is posted to its URL as JSON ({\"id\", \"event\", \"room_id\", \"timestamp\", \"data\"}) with the event type in X-Webhook-Event and an HMAC-SHA256 of the body, keyed with the webhook's secret, in X-Webhook-Signature (\"sha256=\u003chex\u003e\"). Failed deliveries are retried up to 5 times with exponential backoff, keeping the same id.\nEvent types: message (new messages, not direct messages), join and leave (membership changes, kicks included), ban (bans), and pin (messages pinned and unpinned). The secret is only returned here. Only room owners and co-owners can manage webhooks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a room webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook URL and event types",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID, URL or events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/webhooks/{webhookID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces a webhook's URL and event types; its secret stays the same. Only room owners and co-owners can manage webhooks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a room webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook URL and event types",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, URL or events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a webhook; nothing more is delivered to it. Only room owners and co-owners can manage webhooks.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a room webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You are not the owner of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieves a page of users ordered by creation time. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by username substring",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only users created after this RFC3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.UserResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to list users",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/display-name": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the current user's display name, their real name as opposed to their username. Whether others see it next to the user's messages, in member lists and among conversation participants, instead of or beside the username, depends on the server's display name policy. An empty display name clears it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set the display name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Display name",
+                        "name": "display_name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DisplayNameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated profile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or display name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Profile was modified elsewhere",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set display name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/feed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the current user's activity across all their rooms, newest first: mentions of them or their groups and replies to their messages. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get the activity feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.FeedItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/folders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the current user's folders in order, each with the IDs of the rooms and conversations in it that the user is still a member of.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "List folders",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Folder"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get folders",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates an empty folder for organizing the current user's rooms and conversations, placed after their other folders. Names are unique per user and 1-64 characters; a user can have up to 50 folders.\nEvery WebSocket connection of the user receives a \"folders.changed\" event with all of their folders whenever they change, so devices stay in sync.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Create a folder",
+                "parameters": [
+                    {
+                        "description": "Folder name",
+                        "name": "folder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.FolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Folder"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or folder name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "A folder with this name already exists, or the user has too many folders",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create folder",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/folders/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes one of the current user's folders. The rooms and conversations in it are not deleted or left; they are no longer in any folder.",
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Delete a folder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete folder",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the name or position of one of the current user's folders. Omitted fields are left as they are.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Rename or move a folder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name or position",
+                        "name": "folder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateFolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Folder"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder ID, request body or folder name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "A folder with this name already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update folder",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/folders/{id}/rooms/{roomID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Puts a room or conversation the current user is a member of in one of their folders. A room is in at most one folder, so it is taken out of the folder it was in. Leaving the room takes it out of the folder.",
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Put a room in a folder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "roomID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder or room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add room to folder",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Takes a room or conversation out of one of the current user's folders, leaving it in no folder.",
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Take a room out of a folder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "roomID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder or room ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found or room not in it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove room from folder",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/invites": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the current user's invitations that have not expired, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "List my invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Invite"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get invitations",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/language": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the language that chat messages from other users are translated into. An empty language turns translation off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set the preferred language",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Preferred language",
+                        "name": "language",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PreferredLanguageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated profile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Profile was modified elsewhere",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set preferred language",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/privacy": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns what the current user shares with others. Typing indicators are shared unless the user turned them off.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my privacy preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.PrivacyPreferences"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get privacy preferences",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets whether others see when the current user is typing. With typing indicators off, the server drops the user's typing frames instead of relaying them, whichever client sends them, starting with their open connections; the user still sees others typing. Read positions are never shared with other users, so there are no read receipts to turn off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set my privacy preferences",
+                "parameters": [
+                    {
+                        "description": "Privacy preferences",
+                        "name": "privacy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PrivacyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.PrivacyPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set privacy preferences",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the current user's complete, effective notification, privacy and do-not-disturb configuration in one document, with defaults applied, so clients can set up their settings screens without calling each settings endpoint. Behavior users cannot change yet, such as which push notifications are sent, is reported as the server applies it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.UserSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get settings",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sidebar": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns every room and conversation the current user is a member of, grouped into their folders in order, with the rest under rooms. Within each group the most recently active come first, with the user's unread and mention counts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Get the sidebar",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Sidebar"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get sidebar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/starred": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the current user's starred messages across all their rooms, most recently starred first. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "List starred messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.StarredMessage"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get starred messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the current user's custom status, an emoji and a short text shown beside their presence, replacing the current one, until expires_at or until it is changed. Send an empty emoji and text to clear it; the response is then null. Connected members of every room the user is a member of receive a presence.status event with it, and it is included in room member lists. No event is sent when it expires; clients hide it at its expires_at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set my custom status",
+                "parameters": [
+                    {
+                        "description": "Emoji, text and expiry",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Status"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, emoji, text or expiry",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/support-access": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the current user's support access grant, if it has not expired.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my support access",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SupportAccess"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No support access granted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get support access",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lets administrators impersonate the current user for support debugging until the grant expires, replacing any earlier grant. Each impersonation is announced to the user with a direct message from the system bot, an activity feed item and an account.impersonated event, and is recorded in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Grant support access",
+                "parameters": [
+                    {
+                        "description": "How long to grant access for",
+                        "name": "access",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SupportAccessRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SupportAccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or expiry",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Not allowed while impersonating a user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to grant support access",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Withdraws the current user's support access. Impersonation tokens issued under it stop working immediately.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Withdraw support access",
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Not allowed while impersonating a user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No support access granted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to withdraw support access",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/unreads": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns, for every room the current user is a member of, how many messages from others arrived after their read cursor and how many of those mention them.\nConnected clients also receive \"unread\" frames with a room's updated counts whenever they change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get unread counts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Unread"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get unread counts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "get": {
+                "description": "Searches for users by username.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search for users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.UserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Query parameter 'q' is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search users",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Retrieves details for a specific user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a single user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a user's username and/or password. Users can only update their own account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user's account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User data to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated profile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You can only update your own account",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Profile was modified elsewhere",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a user's account. Users can only delete their own account.\nGET /users/{id}/deletion-report previews the effects, so clients can ask for an informed confirmation.\nRooms the user owns pass to their longest-standing co-owner, administrator or member, in that order, and the room gets a message from the system bot announcing the new owner. Rooms nobody is left to inherit are archived.\nAccounts under legal hold, or with messages in a room that is, cannot be deleted until it is released.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user's account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You can only delete your own account",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "The account's messages are under legal hold",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/deletion-report": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Summarizes what deleting the account would remove and change: the rooms the user owns and who inherits each of them, how many messages and polls are deleted and how much storage they take. Nothing is changed. Users can only preview their own account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Preview the deletion of a user's account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.DeletionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: You can only delete your own account",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to build deletion report",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issues a short-lived token that lets an administrator act as a user who has granted support access, for support debugging. The token is valid for expires_in_minutes (15 by default, at most 60) and never past the end of the user's support access; it stops working as soon as the user withdraws access or impersonation is disabled.\nThe user gets a direct message from the system bot, an activity feed item and an account.impersonated event naming the administrator and reason. The session is recorded in the audit log, and so is every audited action taken with the token, marked with the administrator's ID. Only administrators can impersonate, and administrators cannot be impersonated. Unavailable unless IMPERSONATION_ENABLED is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason and duration",
+                        "name": "impersonate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ImpersonateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, request body, reason or expiry, or the user is an administrator",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only, or the user has not granted support access",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or impersonation disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to impersonate user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{token}": {
+            "post": {
+                "description": "Posts a message to the room of the incoming webhook the token belongs to. No other authentication is needed: the token is the secret. The message is sent by the system bot with metadata {\"integration\": {\"webhook_id\", \"name\"}}, so clients can show the webhook's name as its author, and reaches the room like any other message. Requests are rate-limited per client address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Post to a room through an incoming webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Incoming webhook token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message content",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.IncomingWebhookMessage"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, or empty or oversized content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Incoming webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "room is archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to post message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/admin": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upgrades the HTTP connection to a read-only WebSocket connection that receives moderation events from every room, such as message.reported. Only administrators can connect; frames sent on it are answered with a read_only error.",
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Connect to the admin channel",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: Administrators only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/{roomID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upgrades the HTTP connection to a WebSocket connection for a specific chat room. The user must be authenticated and a member of the room. A user can be connected to a room from several devices at once, and each receives everything sent to them; they stay online until their last connection closes.\nWhen last_seen_seq (or since) is given, messages missed since then are replayed before live messages. Replays are compressed when the client negotiates permessage-deflate, and clients that pass the compact_replay capability get them as one \"replay\" frame whose payload is {count, columns}: a map from each message field to its values, oldest message first, with null where a message leaves the field out.\nEvery frame is an envelope {type, id, payload, ts}. Acks, errors, heartbeats and presence.subscribed frames echo the id of the client frame they answer. The server sends \"message\", \"ack\", \"error\" and message event frames such as \"poll.updated\" as well as those below.\n\"message\" (client): payload is the message. In end-to-end encrypted conversations clients send \"encrypted\" frames instead, with ciphertext as the message's content, and \"message\" frames are rejected with an encryption_required error.\n\"read\" (client): payload {\"seq\": n} marks messages read; the server sends \"unread\" frames with a room's updated unread counts to every connection of the user.\n\"typing\" (both): clients repeat {\"typing\": true} while the user types. Typing frames are relayed at most once every 3 seconds per user, and the room is sent {\"typing\": false} once the user stops, sends a message or leaves, or after 10 seconds without a typing frame.\n\"heartbeat\" (both): payload {\"client_time\", \"latency_ms\"}. Heartbeats keep the connection alive like pongs do; each is answered with {client_time, server_time}, and latency_ms reports the round trip the client measured for the previous one.\n\"presence.snapshot\" (server): sent on connecting, {room_id, online} lists the users connected to this room. \"presence\" frames then report members connecting to or leaving it, \"presence.online\" and \"presence.offline\" frames a member of the room opening their first connection to any room or closing their last, and \"presence.status\" frames ({user_id, status}) a member setting or clearing their custom status.\n\"presence.subscribe\" (client): payload {\"user_ids\"} limits those presence frames to the users listed, up to 500 who share a room with the user, wherever they are. It is answered with a \"presence.subscribed\" frame ({user_ids, presence}) with the users subscribed to and their current presence; an empty list restores the room's presence.",
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Join and connect to a chat room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID to connect to",
+                        "name": "roomID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last message the client received",
+                        "name": "last_seen_seq",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp of the last message the client received",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated client capabilities: compact_replay",
+                        "name": "capabilities",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or replay parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not a member of this room",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error or failed to upgrade connection",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "autoreply.Rule": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "description": "CooldownSeconds is how long each sender waits before the rule\nanswers them again.",
+                    "type": "integer",
+                    "example": 600
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is absent once the creator's account is deleted.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string"
+                },
+                "keywords": {
+                    "description": "Keywords are matched as whole words or phrases, ignoring case and\npunctuation. They are stored lowercased.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hours",
+                        "opening times"
+                    ]
+                },
+                "reply": {
+                    "type": "string",
+                    "example": "Support is available 9:00-17:00 UTC, Monday to Friday."
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "autoreply.RuleRequest": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "description": "CooldownSeconds is how long each sender waits before the rule answers\nthem again, at most 86400; 600 when omitted.",
+                    "type": "integer",
+                    "example": 600
+                },
+                "enabled": {
+                    "description": "Enabled turns the rule on or off; true when omitted.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "keywords": {
+                    "description": "Keywords trigger the reply when a message contains one of them as\nwhole words, ignoring case and punctuation; 1-10 of up to 64\ncharacters.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hours",
+                        "opening times"
+                    ]
+                },
+                "reply": {
+                    "description": "Reply is the text the system bot answers with, up to 2000 characters.",
+                    "type": "string",
+                    "example": "Support is available 9:00-17:00 UTC, Monday to Friday."
+                }
+            }
+        },
+        "handler.AddParticipantRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.AnnotationRequest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "ticket"
+                }
+            }
+        },
+        "handler.AnnouncementRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Content is the announcement text, up to 500 characters.",
+                    "type": "string",
+                    "example": "Maintenance tonight from 22:00 UTC."
+                },
+                "expires_at": {
+                    "description": "ExpiresAt takes the announcement down at that time; omit it to keep\nthe announcement until it is removed or replaced.",
+                    "type": "string",
+                    "example": "2025-09-04T06:00:00Z"
+                }
+            }
+        },
+        "handler.BanRequest": {
+            "type": "object",
+            "properties": {
+                "duration_minutes": {
+                    "description": "DurationMinutes is how long the ban lasts; omitted or 0 bans for good.",
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "handler.BulkMemberFailure": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "add"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "user not found"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.BulkMembersRequest": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.BulkMembersResponse": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.BulkMemberFailure"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.CoOwnerRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.CreateConversationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is optional; clients usually show the participants instead.",
+                    "type": "string",
+                    "example": "Weekend plans"
+                },
+                "user_ids": {
+                    "description": "UserIDs are the other participants; the creator takes part anyway.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.CreateGroupRequest": {
+            "type": "object",
+            "properties": {
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "oncall"
+                }
+            }
+        },
+        "handler.CreatePollRequest": {
+            "type": "object",
+            "properties": {
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Pizza",
+                        "Sushi",
+                        "Tacos"
+                    ]
+                },
+                "question": {
+                    "type": "string",
+                    "example": "Where should we have lunch?"
+                }
+            }
+        },
+        "handler.CreateRoomRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_minutes": {
+                    "description": "ExpiresInMinutes makes the room temporary: it is deleted, with\neverything in it, that many minutes after it is created, at most 30\ndays. Rooms last until deleted by default.",
+                    "type": "integer",
+                    "example": 60
+                },
+                "name": {
+                    "type": "string",
+                    "example": "General"
+                },
+                "visibility": {
+                    "description": "Visibility is \"public\" (the default) or \"private\". Only room creation\nreads it; change it later through the room settings.",
+                    "type": "string",
+                    "example": "public"
+                }
+            }
+        },
+        "handler.DirectoryRoom": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Everything about the project that is not a bug report."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "last_activity_at": {
+                    "description": "LastActivityAt is when the latest message was sent, or when the room\nwas created if it has none.",
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "member_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "message_count": {
+                    "description": "MessageCount counts the messages the room holds, direct messages left\nout.",
+                    "type": "integer",
+                    "example": 1234
+                },
+                "name": {
+                    "type": "string",
+                    "example": "General"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gaming",
+                        "golang"
+                    ]
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "Release planning for v2"
+                }
+            }
+        },
+        "handler.DisplayNameRequest": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "description": "DisplayName is the user's real name, up to 64 characters; empty clears it.",
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                }
+            }
+        },
+        "handler.EditMessageRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Corrected message text"
+                }
+            }
+        },
+        "handler.FolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Work"
+                }
+            }
+        },
+        "handler.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_minutes": {
+                    "description": "ExpiresInMinutes is how long the token is valid; 15 when omitted, at\nmost 60, and never past the end of the user's support access.",
+                    "type": "integer",
+                    "example": 15
+                },
+                "reason": {
+                    "description": "Reason is shown to the user and kept in the audit log.",
+                    "type": "string",
+                    "example": "Ticket #4521: messages not arriving"
+                }
+            }
+        },
+        "handler.ImpersonateResponse": {
+            "type": "object",
+            "properties": {
+                "impersonation": {
+                    "$ref": "#/definitions/service.Impersonation"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "handler.IncomingWebhookMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Build #42 passed"
+                },
+                "text": {
+                    "description": "Text is accepted instead of content, as Slack-compatible tools send it.",
+                    "type": "string",
+                    "example": "Build #42 passed"
+                }
+            }
+        },
+        "handler.IncomingWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is shown as the author of the webhook's messages, up to 64\ncharacters.",
+                    "type": "string",
+                    "example": "CI"
+                }
+            }
+        },
+        "handler.InviteCodeRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "ExpiresInHours is how long the code can be used; 24 when omitted, at\nmost 168 (7 days).",
+                    "type": "integer",
+                    "example": 24
+                },
+                "max_uses": {
+                    "description": "MaxUses is how many users can join with the code, from 1 to 1000;\nunlimited when omitted.",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "handler.InviteRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "ExpiresInHours is how long the invitation stays valid; 168 (7 days)\nwhen omitted, at most 720 (30 days).",
+                    "type": "integer",
+                    "example": 48
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.JoinByCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "k7pq3xw9mh"
+                }
+            }
+        },
+        "handler.JoinByCodeResponse": {
+            "type": "object",
+            "properties": {
+                "joined": {
+                    "description": "Joined is false when the user was already a member of the room.",
+                    "type": "boolean"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "room_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LegalHoldRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason is up to 500 characters, such as a case reference.",
+                    "type": "string",
+                    "example": "Litigation case 2025-114"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newuser"
+                }
+            }
+        },
+        "handler.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "handler.MemberResponse": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                },
+                "is_bot": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "joined_at": {
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "online": {
+                    "description": "Online reports whether the member is connected to the room right now.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "role": {
+                    "description": "Role is owner, moderator or member.",
+                    "type": "string",
+                    "example": "member"
+                },
+                "status": {
+                    "description": "Status is the member's custom status; absent when they have none.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Status"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "username": {
+                    "description": "Username and DisplayName are shown according to the server's display\nname policy; either may be absent.",
+                    "type": "string",
+                    "example": "newuser"
+                }
+            }
+        },
+        "handler.MemberRoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "Role is owner, moderator or member.",
+                    "type": "string",
+                    "example": "moderator"
+                }
+            }
+        },
+        "handler.NotificationSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "description": "Level is \"all\", \"mentions\" or \"none\".",
+                    "type": "string",
+                    "example": "none"
+                }
+            }
+        },
+        "handler.PreferredLanguageRequest": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "description": "Language is a language code such as \"en\" or \"fr\"; empty clears the preference.",
+                    "type": "string",
+                    "example": "fr"
+                }
+            }
+        },
+        "handler.PrivacyRequest": {
+            "type": "object",
+            "properties": {
+                "typing_indicators": {
+                    "description": "TypingIndicators is whether others see when the user is typing.",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handler.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newuser"
+                }
+            }
+        },
+        "handler.ReportRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "spam"
+                }
+            }
+        },
+        "handler.RoomImportResponse": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "description": "Members counts the members added, the importer included.",
+                    "type": "integer",
+                    "example": 12
+                },
+                "messages": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "room": {
+                    "$ref": "#/definitions/handler.RoomResponse"
+                },
+                "skipped_members": {
+                    "description": "SkippedMembers lists the usernames with no account on this server.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "carol"
+                    ]
+                }
+            }
+        },
+        "handler.RoomResponse": {
+            "type": "object",
+            "properties": {
+                "allow_urgent": {
+                    "description": "AllowUrgent reports whether members may send urgent messages.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "announcement": {
+                    "description": "Announcement is the room's pinned announcement, only set when fetching\na single room that has one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Announcement"
+                        }
+                    ]
+                },
+                "archived_at": {
+                    "description": "ArchivedAt is set once the room has been archived because its owner\ndeleted their account and nobody was left to inherit it. Archived rooms\ncannot be joined.",
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "avatar_url": {
+                    "description": "AvatarURL is absent until an avatar is uploaded.",
+                    "type": "string",
+                    "example": "http://localhost:8080/uploads/rooms/a1b2c3d4-e5f6-7890-1234-567890abcdef/avatar-1.png"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Everything about the project that is not a bug report."
+                },
+                "encrypted": {
+                    "description": "Encrypted is set on group conversations that opted in to end-to-end\nencryption; only encrypted frames are accepted in them.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when a temporary room is deleted; absent for rooms that\nlast until deleted.",
+                    "type": "string",
+                    "example": "2025-09-03T13:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "kind": {
+                    "description": "Kind is \"room\", or \"group_dm\" for group conversations.",
+                    "type": "string",
+                    "example": "room"
+                },
+                "last_activity_at": {
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "last_message_at": {
+                    "description": "LastMessageAt is when the latest message was sent; absent until the\nroom has one.",
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "max_message_size": {
+                    "description": "MaxMessageSize is the room's own message size limit in bytes; absent\nwhen the server-wide limit applies.",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "member_count": {
+                    "description": "MemberCount and LastActivityAt are only set in room listings.\nLastActivityAt is LastMessageAt, or when the room was created if it has\nno messages.",
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "General"
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "b1c2d3e4-f5g6-7890-1234-567890abcdef"
+                },
+                "public_readable": {
+                    "description": "PublicReadable reports whether anyone can read the room's recent\nmessages without signing in.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retention": {
+                    "description": "Retention is the room's message retention policy; absent when messages\nare kept forever.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.RetentionPolicy"
+                        }
+                    ]
+                },
+                "room_mention_role": {
+                    "description": "RoomMentionRole is the lowest role allowed to use @room and @here.",
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "stats_enabled": {
+                    "description": "StatsEnabled reports whether the room's engagement stats are computed.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "summaries_enabled": {
+                    "description": "SummariesEnabled reports whether members can ask for catch-up summaries.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "Tags are the room's categories, set in room listings and when fetching\na single room.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gaming",
+                        "golang"
+                    ]
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "Release planning for v2"
+                },
+                "version": {
+                    "description": "Version increases with every change; it is also sent as the ETag.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "visibility": {
+                    "description": "Visibility is \"public\" or \"private\".",
+                    "type": "string",
+                    "example": "public"
+                }
+            }
+        },
+        "handler.RoomSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "allow_urgent": {
+                    "description": "AllowUrgent lets members send urgent messages, up to a daily limit per\nuser. Owners and co-owners can always send them.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "max_message_size": {
+                    "description": "MaxMessageSize overrides the server-wide message size limit in bytes.\nnull restores the server-wide limit.",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "public_readable": {
+                    "description": "PublicReadable serves the room's recent messages to anyone, without\nauthentication, at /public/rooms/{id}/messages. Only public rooms can\nbe public-readable.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "room_mention_role": {
+                    "description": "RoomMentionRole is the lowest role allowed to use @room and @here:\n\"member\", \"moderator\" or \"owner\". Omit it to keep the current one.",
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "stats_enabled": {
+                    "description": "StatsEnabled opts the room in to engagement stats, which members can\nread at /rooms/{id}/stats.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "summaries_enabled": {
+                    "description": "SummariesEnabled opts the room in to catch-up summaries at\n/rooms/{id}/summary, which send its messages to the server's\nsummarization provider.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "visibility": {
+                    "description": "Visibility is \"public\" or \"private\"; omit it to keep the current one.",
+                    "type": "string",
+                    "example": "private"
+                }
+            }
+        },
+        "handler.RoomTagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "description": "Tags replace the room's current tags; an empty list removes them all.\nTags are lowercased, and at most 10 are allowed.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gaming",
+                        "golang"
+                    ]
+                }
+            }
+        },
+        "handler.RoomTagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gaming",
+                        "golang"
+                    ]
+                }
+            }
+        },
+        "handler.StatusRequest": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "description": "Emoji is a single emoji or a known :shortcode:.",
+                    "type": "string",
+                    "example": "📅"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt clears the status at that time; omit it to keep the status\nuntil it is changed.",
+                    "type": "string",
+                    "example": "2025-09-04T06:00:00Z"
+                },
+                "text": {
+                    "description": "Text is up to 100 characters.",
+                    "type": "string",
+                    "example": "In a meeting"
+                }
+            }
+        },
+        "handler.SupportAccessRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "ExpiresInHours is how long administrators may impersonate you; 24 when\nomitted, at most 72.",
+                    "type": "integer",
+                    "example": 24
+                }
+            }
+        },
+        "handler.TagResponse": {
+            "type": "object",
+            "properties": {
+                "room_count": {
+                    "description": "RoomCount is how many of the rooms visible to the current user carry\nthe tag.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "golang"
+                }
+            }
+        },
+        "handler.UpdateFolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Work"
+                },
+                "position": {
+                    "description": "Position orders the user's folders, lowest first.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handler.UpdateGroupRequest": {
+            "type": "object",
+            "properties": {
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "oncall"
+                }
+            }
+        },
+        "handler.UpdateRoomRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description explains the room, at most 4000 characters.",
+                    "type": "string",
+                    "example": "Everything about the project that is not a bug report."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "General"
+                },
+                "topic": {
+                    "description": "Topic is a one-line summary of what the room is about, at most 250\ncharacters.",
+                    "type": "string",
+                    "example": "Release planning for v2"
+                }
+            }
+        },
+        "handler.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newpassword123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "updateduser"
+                }
+            }
+        },
+        "handler.UserResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-09-03T12:00:00Z"
+                },
+                "display_name": {
+                    "description": "DisplayName is the user's real name, if they set one.",
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "is_bot": {
+                    "description": "IsBot marks automated accounts such as the system bot.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newuser"
+                },
+                "version": {
+                    "description": "Version increases with every profile change; it is also sent as the ETag.",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handler.VoteRequest": {
+            "type": "object",
+            "properties": {
+                "option_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                }
+            }
+        },
+        "handler.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events are the event types to deliver: message, join, leave, ban and pin.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "join",
+                        "leave"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/chat"
+                }
+            }
+        },
+        "handler.WorkspaceRequest": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#fbbc04"
+                },
+                "domain": {
+                    "description": "Domain is the host name the workspace is served on, without a port.",
+                    "type": "string",
+                    "example": "chat.acme.com"
+                },
+                "logo_url": {
+                    "description": "LogoURL is an http(s) URL; omit it for no logo.",
+                    "type": "string",
+                    "example": "https://cdn.acme.com/logo.png"
+                },
+                "name": {
+                    "description": "Name is up to 100 characters.",
+                    "type": "string",
+                    "example": "Acme Chat"
+                },
+                "primary_color": {
+                    "description": "PrimaryColor and AccentColor are hex colors such as #1a73e8; omit them\nto leave colors to clients.",
+                    "type": "string",
+                    "example": "#1a73e8"
+                }
+            }
+        },
+        "service.Annotation": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "ticket"
+                }
+            }
+        },
+        "service.Announcement": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Maintenance tonight from 22:00 UTC."
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the announcement is taken down; absent when it stays\nuntil removed.",
+                    "type": "string",
+                    "example": "2025-09-04T06:00:00Z"
+                },
+                "set_by": {
+                    "description": "SetBy is absent once the account of whoever set it is deleted.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.AnnouncementUpdate": {
+            "type": "object",
+            "properties": {
+                "announcement": {
+                    "$ref": "#/definitions/service.Announcement"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Ban": {
+            "type": "object",
+            "properties": {
+                "banned_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Branding": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#fbbc04"
+                },
+                "logo_url": {
+                    "type": "string",
+                    "example": "https://cdn.acme.com/logo.png"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme Chat"
+                },
+                "primary_color": {
+                    "type": "string",
+                    "example": "#1a73e8"
+                }
+            }
+        },
+        "service.BulkDelete": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CommandResult": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string",
+                    "example": "pin"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Message pinned."
+                }
+            }
+        },
+        "service.Connection": {
+            "type": "object",
+            "properties": {
+                "connected_at": {
+                    "type": "string"
+                },
+                "heartbeat": {
+                    "description": "Heartbeat is absent until the client sends a heartbeat.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.HeartbeatStats"
+                        }
+                    ]
+                },
+                "read_only": {
+                    "type": "boolean"
+                },
+                "room_id": {
+                    "description": "RoomID is \"admin\" for the admin channel.",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Conversation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "encrypted": {
+                    "description": "Encrypted is set once the conversation opted in to end-to-end\nencryption, at EncryptedAt; only encrypted frames are accepted in it.",
+                    "type": "boolean"
+                },
+                "encrypted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Participant"
+                    }
+                }
+            }
+        },
+        "service.DNDSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "service.DeletedMessages": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "service.DeletionReport": {
+            "type": "object",
+            "properties": {
+                "consequences": {
+                    "description": "Consequences spells out the effects of the deletion in plain sentences.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "messages": {
+                    "description": "Messages counts every message the user sent, polls included.",
+                    "type": "integer"
+                },
+                "polls": {
+                    "type": "integer"
+                },
+                "rooms_owned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RoomHandover"
+                    }
+                },
+                "storage_bytes": {
+                    "description": "StorageBytes is the size of the user's message content and metadata.",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ErrorFrame": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "description": "RetryAfter is the number of seconds to wait before sending again, when\nthe rejection is temporary.",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.FeedItem": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Actor is the user whose action created the item, e.g. the author of a reply.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.SenderProfile"
+                        }
+                    ]
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "message": {
+                    "$ref": "#/definitions/service.Message"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "room_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.FeedSettings": {
+            "type": "object",
+            "properties": {
+                "impersonations": {
+                    "description": "Impersonations are the support sessions administrators start as the\nuser.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "mentions": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "replies": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.Folder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Work"
+                },
+                "position": {
+                    "description": "Position orders the user's folders, lowest first.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "room_ids": {
+                    "description": "RoomIDs are the rooms in the folder the user is still a member of.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "service.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.HeartbeatStats": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number",
+                    "example": 52.5
+                },
+                "clock_offset_ms": {
+                    "description": "ClockOffsetMS is how far ahead of the client's clock the server's was\nwhen the last heartbeat arrived, one-way latency included.",
+                    "type": "number",
+                    "example": 35.2
+                },
+                "heartbeats": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_heartbeat_at": {
+                    "type": "string"
+                },
+                "last_latency_ms": {
+                    "type": "number",
+                    "example": 48
+                },
+                "max_latency_ms": {
+                    "type": "number",
+                    "example": 140
+                },
+                "min_latency_ms": {
+                    "type": "number",
+                    "example": 31
+                },
+                "samples": {
+                    "description": "Samples counts the round trips the latency figures cover; they are\nzero when the client reported none.",
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "service.Impersonation": {
+            "type": "object",
+            "properties": {
+                "admin_id": {
+                    "type": "string"
+                },
+                "admin_username": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.IncomingWebhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is absent once the creator's account is deleted.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is shown as the author of the webhook's messages.",
+                    "type": "string",
+                    "example": "CI"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is where messages are posted, set along with Token.",
+                    "type": "string",
+                    "example": "https://chat.example.com/v1/webhooks/3f9a..."
+                }
+            }
+        },
+        "service.Invite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited_by": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "room_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.InviteCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "k7pq3xw9mh"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "link": {
+                    "description": "Link is the code's join link, when INVITE_LINK_URL is configured.",
+                    "type": "string",
+                    "example": "https://chat.example.com/join/k7pq3xw9mh"
+                },
+                "max_uses": {
+                    "description": "MaxUses is how many users can join with the code; unlimited when\nomitted.",
+                    "type": "integer",
+                    "example": 10
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "service.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts counts the tries so far, out of MaxAttempts.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "LastError is why the latest try failed.",
+                    "type": "string",
+                    "example": "https://example.com/hooks/chat returned status 503"
+                },
+                "max_attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "description": "RunAt is when the job is next due, for pending jobs.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "webhook.delivery"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.JobQueueDepth": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "oldest_run_at": {
+                    "description": "OldestRunAt is when the longest waiting of these jobs was due.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "webhook.delivery"
+                }
+            }
+        },
+        "service.LegalHold": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "placed_by": {
+                    "description": "PlacedBy is absent once the account of whoever placed it is deleted.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Litigation case 2025-114"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Exactly one of UserID and RoomID is set.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.MemberChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the member's role after an add or role change.",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.MemberDelta": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.MemberChange"
+                    }
+                },
+                "reset": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.Message": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "description": "Annotations holds structured data attached to the message after it\nwas sent; message.annotated events carry the new annotation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Annotation"
+                    }
+                },
+                "announcement": {
+                    "description": "Announcement is set on room.announcement events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.AnnouncementUpdate"
+                        }
+                    ]
+                },
+                "client_msg_id": {
+                    "description": "ClientMsgID is an optional idempotency key chosen by the sender. A\nmessage resent to its room with the same key is acknowledged but not\nstored again.",
+                    "type": "string"
+                },
+                "command": {
+                    "description": "Command is set on command.result events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.CommandResult"
+                        }
+                    ]
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation": {
+                    "description": "Conversation is set on conversation.added events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Conversation"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Deleted is set on messages.deleted events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DeletedMessages"
+                        }
+                    ]
+                },
+                "edited_at": {
+                    "description": "EditedAt is set once the message's content has been edited.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is set on error frames sent back to a client whose message was rejected.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.ErrorFrame"
+                        }
+                    ]
+                },
+                "folders": {
+                    "description": "Folders is set on folders.changed events.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Folder"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonation": {
+                    "description": "Impersonation is set on account.impersonated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Impersonation"
+                        }
+                    ]
+                },
+                "invite": {
+                    "description": "Invite is set on room.invited events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Invite"
+                        }
+                    ]
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Members is set on members.changed events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MemberDelta"
+                        }
+                    ]
+                },
+                "mentions": {
+                    "description": "Mentions lists the IDs of users mentioned directly or through a group,\nso clients can highlight the message for them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "description": "Metadata carries structured data attached by bots and clients. It is\npersisted and relayed untouched. Attachments are listed under\n\"attachments\", which rooms can restrict to some roles.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "original_content": {
+                    "description": "OriginalContent and Language are set on per-recipient translated copies.",
+                    "type": "string"
+                },
+                "pin": {
+                    "description": "Pin is set on message.pinned and message.unpinned events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.PinUpdate"
+                        }
+                    ]
+                },
+                "poll": {
+                    "description": "Poll is set on poll messages and their updates.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    ]
+                },
+                "priority": {
+                    "description": "Priority is \"normal\" or \"urgent\". Urgent messages are pushed to every\noffline member of the room.",
+                    "type": "string"
+                },
+                "purge": {
+                    "description": "Purge is set on messages.purged events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MessagePurge"
+                        }
+                    ]
+                },
+                "quote": {
+                    "$ref": "#/definitions/service.QuotedMessage"
+                },
+                "quoted_message_id": {
+                    "description": "QuotedMessageID references the message this one replies to; Quote is a\nserver-side snapshot of it.",
+                    "type": "string"
+                },
+                "reactions": {
+                    "description": "Reactions holds the message's reaction counts; reactions.updated\nevents carry the new counts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Reaction"
+                    }
+                },
+                "recipient_id": {
+                    "description": "Omit if empty for broadcast messages",
+                    "type": "string"
+                },
+                "report": {
+                    "description": "Report is set on message.reported events in the admin channel.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Report"
+                        }
+                    ]
+                },
+                "room": {
+                    "description": "Room is set on room.updated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.RoomUpdate"
+                        }
+                    ]
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "sender": {
+                    "description": "Sender is included in history responses so clients need not fetch profiles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.SenderProfile"
+                        }
+                    ]
+                },
+                "sender_id": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Monotonic sequence number used for replay on reconnect",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is empty for new messages and names the event for updates to\nexisting ones, such as poll.updated.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.MessageEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position orders the log; events are paged through by position.",
+                    "type": "integer",
+                    "example": 1042
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "message.edited"
+                }
+            }
+        },
+        "service.MessagePurge": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "integer",
+                    "example": 4500
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "LastError is why the latest batch failed; the purge is retried.",
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total is how many messages the purge covered when it was requested,\nand Deleted how many it has deleted so far.",
+                    "type": "integer",
+                    "example": 12000
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.MessageReactions": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string"
+                },
+                "mine": {
+                    "description": "Mine lists the emoji the current user reacted with.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Reaction"
+                    }
+                }
+            }
+        },
+        "service.NotificationSettings": {
+            "type": "object",
+            "properties": {
+                "default_room_level": {
+                    "description": "DefaultRoomLevel applies to every room not listed in Rooms, which\nholds the rooms the user chose a notification level for.",
+                    "type": "string",
+                    "example": "mentions"
+                },
+                "feed": {
+                    "$ref": "#/definitions/service.FeedSettings"
+                },
+                "push": {
+                    "$ref": "#/definitions/service.PushSettings"
+                },
+                "rooms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RoomNotificationSettings"
+                    }
+                }
+            }
+        },
+        "service.Participant": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.PinUpdate": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.PinnedMessage": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "description": "Annotations holds structured data attached to the message after it\nwas sent; message.annotated events carry the new annotation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Annotation"
+                    }
+                },
+                "announcement": {
+                    "description": "Announcement is set on room.announcement events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.AnnouncementUpdate"
+                        }
+                    ]
+                },
+                "client_msg_id": {
+                    "description": "ClientMsgID is an optional idempotency key chosen by the sender. A\nmessage resent to its room with the same key is acknowledged but not\nstored again.",
+                    "type": "string"
+                },
+                "command": {
+                    "description": "Command is set on command.result events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.CommandResult"
+                        }
+                    ]
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation": {
+                    "description": "Conversation is set on conversation.added events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Conversation"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Deleted is set on messages.deleted events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DeletedMessages"
+                        }
+                    ]
+                },
+                "edited_at": {
+                    "description": "EditedAt is set once the message's content has been edited.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is set on error frames sent back to a client whose message was rejected.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.ErrorFrame"
+                        }
+                    ]
+                },
+                "folders": {
+                    "description": "Folders is set on folders.changed events.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Folder"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonation": {
+                    "description": "Impersonation is set on account.impersonated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Impersonation"
+                        }
+                    ]
+                },
+                "invite": {
+                    "description": "Invite is set on room.invited events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Invite"
+                        }
+                    ]
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Members is set on members.changed events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MemberDelta"
+                        }
+                    ]
+                },
+                "mentions": {
+                    "description": "Mentions lists the IDs of users mentioned directly or through a group,\nso clients can highlight the message for them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "description": "Metadata carries structured data attached by bots and clients. It is\npersisted and relayed untouched. Attachments are listed under\n\"attachments\", which rooms can restrict to some roles.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "original_content": {
+                    "description": "OriginalContent and Language are set on per-recipient translated copies.",
+                    "type": "string"
+                },
+                "pin": {
+                    "description": "Pin is set on message.pinned and message.unpinned events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.PinUpdate"
+                        }
+                    ]
+                },
+                "pinned_at": {
+                    "type": "string"
+                },
+                "pinned_by": {
+                    "description": "PinnedBy is absent once the user who pinned it deleted their account.",
+                    "type": "string"
+                },
+                "poll": {
+                    "description": "Poll is set on poll messages and their updates.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    ]
+                },
+                "priority": {
+                    "description": "Priority is \"normal\" or \"urgent\". Urgent messages are pushed to every\noffline member of the room.",
+                    "type": "string"
+                },
+                "purge": {
+                    "description": "Purge is set on messages.purged events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MessagePurge"
+                        }
+                    ]
+                },
+                "quote": {
+                    "$ref": "#/definitions/service.QuotedMessage"
+                },
+                "quoted_message_id": {
+                    "description": "QuotedMessageID references the message this one replies to; Quote is a\nserver-side snapshot of it.",
+                    "type": "string"
+                },
+                "reactions": {
+                    "description": "Reactions holds the message's reaction counts; reactions.updated\nevents carry the new counts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Reaction"
+                    }
+                },
+                "recipient_id": {
+                    "description": "Omit if empty for broadcast messages",
+                    "type": "string"
+                },
+                "report": {
+                    "description": "Report is set on message.reported events in the admin channel.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Report"
+                        }
+                    ]
+                },
+                "room": {
+                    "description": "Room is set on room.updated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.RoomUpdate"
+                        }
+                    ]
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "sender": {
+                    "description": "Sender is included in history responses so clients need not fetch profiles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.SenderProfile"
+                        }
+                    ]
+                },
+                "sender_id": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Monotonic sequence number used for replay on reconnect",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is empty for new messages and names the event for updates to\nexisting ones, such as poll.updated.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.Poll": {
+            "type": "object",
+            "properties": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PollOption"
+                    }
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.PollOption": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.Presence": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.PrivacyPreferences": {
+            "type": "object",
+            "properties": {
+                "typing_indicators": {
+                    "description": "TypingIndicators reports whether others see when the user is typing.\nTurning it off does not hide others' typing from the user. Read\npositions are never shared with other users, so there are no read\nreceipts to turn off.",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.PrivacySettings": {
+            "type": "object",
+            "properties": {
+                "impersonation_enabled": {
+                    "description": "ImpersonationEnabled reports whether administrators can use support\naccess grants on this server.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "support_access": {
+                    "description": "SupportAccess is the user's unexpired support access grant; absent\nwhen there is none.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.SupportAccess"
+                        }
+                    ]
+                },
+                "typing_indicators": {
+                    "description": "TypingIndicators reports whether others see when the user is typing.",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.PublicMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "sender": {
+                    "$ref": "#/definitions/service.SenderProfile"
+                },
+                "seq": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PublicRoomLog": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "description": "GeneratedAt is when the messages were loaded; they may be up to 30\nseconds old.",
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PublicMessage"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.PushSettings": {
+            "type": "object",
+            "properties": {
+                "direct_messages": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mentions": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "preview": {
+                    "description": "Preview reports whether notifications show message content. It is\nset for the whole server.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "room_mention_window_seconds": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "room_mentions": {
+                    "description": "RoomMentions are @room and @here; those arriving within\nRoomMentionWindowSeconds of each other are pushed as one.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "urgent": {
+                    "description": "Urgent messages are pushed to every offline member of their room.",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "service.QuotedMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "sender_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Reaction": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "emoji": {
+                    "type": "string",
+                    "example": "👍"
+                }
+            }
+        },
+        "service.ReadCursor": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string"
+                },
+                "seq": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.Report": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message_content": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "message_sender_id": {
+                    "description": "MessageSenderID and MessageContent describe the reported message.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "spam"
+                },
+                "reporter_id": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.RetentionPolicy": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "hold": {
+                    "description": "Hold suspends purging regardless of the limits. Only admins can set it.",
+                    "type": "boolean"
+                },
+                "max_messages": {
+                    "type": "integer",
+                    "example": 10000
+                }
+            }
+        },
+        "service.Revision": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "edited_by": {
+                    "type": "string"
+                },
+                "replaced_at": {
+                    "description": "ReplacedAt is when this version was replaced by the next one.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.RoomBundle": {
+            "type": "object",
+            "properties": {
+                "exported_at": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Members lists everyone in the room except the owner who exported it.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RoomBundleMember"
+                    }
+                },
+                "messages": {
+                    "description": "Messages holds the room's latest text messages, oldest first; absent\nunless the export asked for them.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RoomBundleMessage"
+                    }
+                },
+                "room": {
+                    "$ref": "#/definitions/service.RoomBundleConfig"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.RoomBundleConfig": {
+            "type": "object",
+            "properties": {
+                "allow_urgent": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "max_message_size": {
+                    "type": "integer",
+                    "example": 2048
+                },
+                "name": {
+                    "type": "string",
+                    "example": "General"
+                },
+                "permissions": {
+                    "$ref": "#/definitions/service.RoomPermissions"
+                },
+                "retention": {
+                    "description": "Retention carries the retention limits; a legal hold is not exported.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.RetentionPolicy"
+                        }
+                    ]
+                },
+                "room_mention_role": {
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "stats_enabled": {
+                    "type": "boolean"
+                },
+                "summaries_enabled": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "gaming",
+                        "golang"
+                    ]
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "Release planning for v2"
+                },
+                "visibility": {
+                    "type": "string",
+                    "example": "public"
+                }
+            }
+        },
+        "service.RoomBundleMember": {
+            "type": "object",
+            "properties": {
+                "joined_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "service.RoomBundleMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the message's ID where it was exported; quotes refer to it.",
+                    "type": "string"
+                },
+                "mentions": {
+                    "description": "Mentions lists the usernames the message mentions.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bob"
+                    ]
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "priority": {
+                    "type": "string",
+                    "example": "normal"
+                },
+                "quoted_message_id": {
+                    "type": "string"
+                },
+                "sender": {
+                    "type": "string",
+                    "example": "alice"
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CreateRoomRequest": {
+        },
+        "service.RoomHandover": {
             "type": "object",
             "properties": {
                 "name": {
+                    "type": "string"
+                },
+                "new_owner_id": {
+                    "description": "NewOwnerID and NewOwnerUsername name who inherits a transferred room.",
+                    "type": "string"
+                },
+                "new_owner_username": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Outcome is \"transfer\" or \"archive\".",
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.RoomNotificationSettings": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "description": "Level is \"all\" to be pushed every message while offline, \"mentions\"\n(the default) for mentions and direct messages only, or \"none\" to\nmute the room: no pushes, urgent ones included, and no activity feed\nitems. Unread counts are kept either way.",
                     "type": "string",
-                    "example": "General"
+                    "example": "mentions"
+                },
+                "room_id": {
+                    "type": "string"
                 }
             }
         },
-        "handler.LoginRequest": {
+        "service.RoomPermissions": {
             "type": "object",
             "properties": {
-                "password": {
+                "attachments": {
+                    "description": "Attachments is who can send messages with attachments, listed under\n\"attachments\" in the message's metadata. It defaults to member.",
                     "type": "string",
-                    "example": "password123"
+                    "example": "member"
                 },
-                "username": {
+                "invite": {
+                    "description": "Invite is who can invite users and create invite codes. It defaults\nto member in public rooms and owner in private ones.",
                     "type": "string",
-                    "example": "newuser"
+                    "example": "member"
+                },
+                "pin": {
+                    "description": "Pin is who can pin and unpin messages. It defaults to moderator.",
+                    "type": "string",
+                    "example": "moderator"
                 }
             }
         },
-        "handler.LoginResponse": {
+        "service.RoomPermissionsUpdate": {
             "type": "object",
             "properties": {
-                "token": {
+                "attachments": {
                     "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                    "example": "member"
+                },
+                "invite": {
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "pin": {
+                    "type": "string",
+                    "example": "moderator"
                 }
             }
         },
-        "handler.RegisterRequest": {
+        "service.RoomStats": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string",
-                    "example": "password123"
+                "busiest_hour": {
+                    "description": "BusiestHour is the hour of the day, in UTC, with the most messages;\nabsent when the room had none.",
+                    "type": "integer",
+                    "example": 14
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "current_streak_days": {
+                    "description": "CurrentStreakDays counts the consecutive UTC days with messages up to\ntoday, or yesterday when nobody has written yet today.",
+                    "type": "integer",
+                    "example": 12
+                },
+                "longest_streak_days": {
+                    "description": "LongestStreakDays is the longest run of such days in the last year.",
+                    "type": "integer",
+                    "example": 40
+                },
+                "messages": {
+                    "type": "integer",
+                    "example": 1234
+                },
+                "top_senders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.StatsSender"
+                    }
+                },
+                "window_days": {
+                    "description": "WindowDays is how many days Messages, BusiestHour and TopSenders cover.",
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "service.RoomSummary": {
+            "type": "object",
+            "properties": {
+                "from_seq": {
+                    "description": "FromSeq and ToSeq are the sequence numbers of the first and last\nmessage summarized. When ToSeq is not the latest message of the room,\nthe rest can be summarized by asking again with since=ToSeq.",
+                    "type": "integer"
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "to_seq": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.RoomUpdate": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.SenderProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
                 },
                 "username": {
-                    "type": "string",
-                    "example": "newuser"
+                    "type": "string"
                 }
             }
         },
-        "handler.RoomResponse": {
+        "service.Sidebar": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SidebarFolder"
+                    }
+                },
+                "rooms": {
+                    "description": "Rooms are those not in any folder.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SidebarRoom"
+                    }
+                }
+            }
+        },
+        "service.SidebarFolder": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string",
-                    "example": "2025-09-03T12:00:00Z"
+                    "example": "Work"
                 },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "rooms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SidebarRoom"
+                    }
+                }
+            }
+        },
+        "service.SidebarRoom": {
+            "type": "object",
+            "properties": {
                 "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is \"room\", or \"group_dm\" for group conversations.",
                     "type": "string",
-                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                    "example": "room"
+                },
+                "last_activity_at": {
+                    "type": "string"
+                },
+                "mentions": {
+                    "type": "integer",
+                    "example": 1
                 },
                 "name": {
                     "type": "string",
                     "example": "General"
                 },
-                "owner_id": {
+                "unread": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "service.StarredMessage": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "description": "Annotations holds structured data attached to the message after it\nwas sent; message.annotated events carry the new annotation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Annotation"
+                    }
+                },
+                "announcement": {
+                    "description": "Announcement is set on room.announcement events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.AnnouncementUpdate"
+                        }
+                    ]
+                },
+                "client_msg_id": {
+                    "description": "ClientMsgID is an optional idempotency key chosen by the sender. A\nmessage resent to its room with the same key is acknowledged but not\nstored again.",
+                    "type": "string"
+                },
+                "command": {
+                    "description": "Command is set on command.result events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.CommandResult"
+                        }
+                    ]
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation": {
+                    "description": "Conversation is set on conversation.added events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Conversation"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Deleted is set on messages.deleted events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.DeletedMessages"
+                        }
+                    ]
+                },
+                "edited_at": {
+                    "description": "EditedAt is set once the message's content has been edited.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is set on error frames sent back to a client whose message was rejected.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.ErrorFrame"
+                        }
+                    ]
+                },
+                "folders": {
+                    "description": "Folders is set on folders.changed events.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Folder"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonation": {
+                    "description": "Impersonation is set on account.impersonated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Impersonation"
+                        }
+                    ]
+                },
+                "invite": {
+                    "description": "Invite is set on room.invited events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Invite"
+                        }
+                    ]
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Members is set on members.changed events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MemberDelta"
+                        }
+                    ]
+                },
+                "mentions": {
+                    "description": "Mentions lists the IDs of users mentioned directly or through a group,\nso clients can highlight the message for them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "description": "Metadata carries structured data attached by bots and clients. It is\npersisted and relayed untouched. Attachments are listed under\n\"attachments\", which rooms can restrict to some roles.",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "original_content": {
+                    "description": "OriginalContent and Language are set on per-recipient translated copies.",
+                    "type": "string"
+                },
+                "pin": {
+                    "description": "Pin is set on message.pinned and message.unpinned events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.PinUpdate"
+                        }
+                    ]
+                },
+                "poll": {
+                    "description": "Poll is set on poll messages and their updates.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Poll"
+                        }
+                    ]
+                },
+                "priority": {
+                    "description": "Priority is \"normal\" or \"urgent\". Urgent messages are pushed to every\noffline member of the room.",
+                    "type": "string"
+                },
+                "purge": {
+                    "description": "Purge is set on messages.purged events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.MessagePurge"
+                        }
+                    ]
+                },
+                "quote": {
+                    "$ref": "#/definitions/service.QuotedMessage"
+                },
+                "quoted_message_id": {
+                    "description": "QuotedMessageID references the message this one replies to; Quote is a\nserver-side snapshot of it.",
+                    "type": "string"
+                },
+                "reactions": {
+                    "description": "Reactions holds the message's reaction counts; reactions.updated\nevents carry the new counts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Reaction"
+                    }
+                },
+                "recipient_id": {
+                    "description": "Omit if empty for broadcast messages",
+                    "type": "string"
+                },
+                "report": {
+                    "description": "Report is set on message.reported events in the admin channel.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Report"
+                        }
+                    ]
+                },
+                "room": {
+                    "description": "Room is set on room.updated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.RoomUpdate"
+                        }
+                    ]
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "sender": {
+                    "description": "Sender is included in history responses so clients need not fetch profiles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.SenderProfile"
+                        }
+                    ]
+                },
+                "sender_id": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Monotonic sequence number used for replay on reconnect",
+                    "type": "integer"
+                },
+                "starred_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is empty for new messages and names the event for updates to\nexisting ones, such as poll.updated.",
+                    "type": "string"
+                }
+            }
+        },
+        "service.StatsSender": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "integer",
+                    "example": 321
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Status": {
+            "type": "object",
+            "properties": {
+                "emoji": {
                     "type": "string",
-                    "example": "b1c2d3e4-f5g6-7890-1234-567890abcdef"
+                    "example": "📅"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the status is cleared; absent when it stays until\nthe user changes it.",
+                    "type": "string",
+                    "example": "2025-09-04T06:00:00Z"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "In a meeting"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "handler.UpdateUserRequest": {
+        "service.SupportAccess": {
             "type": "object",
             "properties": {
-                "password": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "granted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Unread": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "last_read_seq": {
+                    "type": "integer"
+                },
+                "mentions": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.UserSettings": {
+            "type": "object",
+            "properties": {
+                "dnd": {
+                    "$ref": "#/definitions/service.DNDSettings"
+                },
+                "language": {
+                    "description": "Language is the user's preferred language, which messages from others\nare translated into; absent when none is set.",
                     "type": "string",
-                    "example": "newpassword123"
+                    "example": "fr"
                 },
-                "username": {
+                "notifications": {
+                    "$ref": "#/definitions/service.NotificationSettings"
+                },
+                "privacy": {
+                    "$ref": "#/definitions/service.PrivacySettings"
+                }
+            }
+        },
+        "service.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "join",
+                        "leave"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string",
-                    "example": "updateduser"
+                    "example": "https://example.com/hooks/chat"
                 }
             }
         },
-        "handler.UserResponse": {
+        "service.Workspace": {
             "type": "object",
             "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#fbbc04"
+                },
                 "created_at": {
+                    "type": "string"
+                },
+                "domain": {
                     "type": "string",
-                    "example": "2025-09-03T12:00:00Z"
+                    "example": "chat.acme.com"
                 },
                 "id": {
+                    "type": "string"
+                },
+                "logo_url": {
                     "type": "string",
-                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                    "example": "https://cdn.acme.com/logo.png"
                 },
-                "username": {
+                "name": {
                     "type": "string",
-                    "example": "newuser"
+                    "example": "Acme Chat"
+                },
+                "primary_color": {
+                    "type": "string",
+                    "example": "#1a73e8"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         }
@@ -870,7 +12490,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/",
+	BasePath:         "/v1",
 	Schemes:          []string{},
 	Title:            "Go Chat Application API",
 	Description:      "This is a chat application backend API.",
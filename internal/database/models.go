@@ -5,25 +5,393 @@
 package database
 
 import (
+	"time"
+
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AuditLog struct {
+	ID             uuid.UUID  `json:"id"`
+	ActorID        *uuid.UUID `json:"actor_id"`
+	Action         string     `json:"action"`
+	RoomID         *uuid.UUID `json:"room_id"`
+	Details        []byte     `json:"details"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ImpersonatorID *uuid.UUID `json:"impersonator_id"`
+}
+
+type Job struct {
+	ID          uuid.UUID `json:"id"`
+	Type        string    `json:"type"`
+	Payload     []byte    `json:"payload"`
+	Status      string    `json:"status"`
+	Attempts    int32     `json:"attempts"`
+	MaxAttempts int32     `json:"max_attempts"`
+	LastError   *string   `json:"last_error"`
+	RunAt       time.Time `json:"run_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type LegalHold struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"user_id"`
+	RoomID    *uuid.UUID `json:"room_id"`
+	Reason    string     `json:"reason"`
+	PlacedBy  *uuid.UUID `json:"placed_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type Message struct {
+	ID              uuid.UUID   `json:"id"`
+	Seq             int64       `json:"seq"`
+	RoomID          uuid.UUID   `json:"room_id"`
+	SenderID        uuid.UUID   `json:"sender_id"`
+	RecipientID     *uuid.UUID  `json:"recipient_id"`
+	Content         string      `json:"content"`
+	CreatedAt       time.Time   `json:"created_at"`
+	Metadata        []byte      `json:"metadata"`
+	Kind            string      `json:"kind"`
+	QuotedMessageID *uuid.UUID  `json:"quoted_message_id"`
+	Mentions        []uuid.UUID `json:"mentions"`
+	EditedAt        *time.Time  `json:"edited_at"`
+	Priority        string      `json:"priority"`
+	ClientMsgID     *string     `json:"client_msg_id"`
+}
+
+type MessageAnnotation struct {
+	ID        uuid.UUID `json:"id"`
+	MessageID uuid.UUID `json:"message_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
+	Kind      string    `json:"kind"`
+	Data      []byte    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MessageEvent struct {
+	Position  int64     `json:"position"`
+	ID        uuid.UUID `json:"id"`
+	MessageID uuid.UUID `json:"message_id"`
+	RoomID    uuid.UUID `json:"room_id"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	Type      string    `json:"type"`
+	Data      []byte    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MessagePurge struct {
+	ID          uuid.UUID  `json:"id"`
+	RoomID      uuid.UUID  `json:"room_id"`
+	RequestedBy uuid.UUID  `json:"requested_by"`
+	UserID      *uuid.UUID `json:"user_id"`
+	StartTime   *time.Time `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Status      string     `json:"status"`
+	Total       int64      `json:"total"`
+	Deleted     int64      `json:"deleted"`
+	LastError   *string    `json:"last_error"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+type MessageReaction struct {
+	MessageID uuid.UUID `json:"message_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MessageReport struct {
+	ID         uuid.UUID `json:"id"`
+	MessageID  uuid.UUID `json:"message_id"`
+	RoomID     uuid.UUID `json:"room_id"`
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MessageRevision struct {
+	ID        uuid.UUID `json:"id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Content   string    `json:"content"`
+	EditedBy  uuid.UUID `json:"edited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	MessageID uuid.UUID  `json:"message_id"`
+	Kind      string     `json:"kind"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+	ActorID   *uuid.UUID `json:"actor_id"`
+}
+
+type Poll struct {
+	ID        uuid.UUID  `json:"id"`
+	MessageID uuid.UUID  `json:"message_id"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	CreatorID uuid.UUID  `json:"creator_id"`
+	Question  string     `json:"question"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+type PollOption struct {
+	ID       uuid.UUID `json:"id"`
+	PollID   uuid.UUID `json:"poll_id"`
+	Position int32     `json:"position"`
+	Text     string    `json:"text"`
+}
+
+type PollVote struct {
+	PollID   uuid.UUID `json:"poll_id"`
+	OptionID uuid.UUID `json:"option_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	VotedAt  time.Time `json:"voted_at"`
+}
+
 type Room struct {
-	ID        uuid.UUID          `json:"id"`
-	Name      string             `json:"name"`
-	OwnerID   uuid.UUID          `json:"owner_id"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID                   uuid.UUID  `json:"id"`
+	Name                 string     `json:"name"`
+	OwnerID              uuid.UUID  `json:"owner_id"`
+	CreatedAt            time.Time  `json:"created_at"`
+	MaxMessageSize       *int32     `json:"max_message_size"`
+	RetentionDays        *int32     `json:"retention_days"`
+	RetentionMaxMessages *int32     `json:"retention_max_messages"`
+	RetentionHold        bool       `json:"retention_hold"`
+	Version              int32      `json:"version"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	AllowUrgent          bool       `json:"allow_urgent"`
+	ArchivedAt           *time.Time `json:"archived_at"`
+	Visibility           string     `json:"visibility"`
+	StatsEnabled         bool       `json:"stats_enabled"`
+	Topic                string     `json:"topic"`
+	Description          string     `json:"description"`
+	AvatarUrl            *string    `json:"avatar_url"`
+	AvatarKey            *string    `json:"avatar_key"`
+	RoomMentionRole      string     `json:"room_mention_role"`
+	MemberVersion        int64      `json:"member_version"`
+	Kind                 string     `json:"kind"`
+	SummariesEnabled     bool       `json:"summaries_enabled"`
+	LastMessageAt        *time.Time `json:"last_message_at"`
+	ExpiresAt            *time.Time `json:"expires_at"`
+	EncryptedAt          *time.Time `json:"encrypted_at"`
+	PublicReadable       bool       `json:"public_readable"`
+}
+
+type RoomAnnouncement struct {
+	RoomID    uuid.UUID  `json:"room_id"`
+	Content   string     `json:"content"`
+	SetBy     *uuid.UUID `json:"set_by"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RoomAutoReply struct {
+	ID              uuid.UUID  `json:"id"`
+	RoomID          uuid.UUID  `json:"room_id"`
+	Keywords        []string   `json:"keywords"`
+	Reply           string     `json:"reply"`
+	CooldownSeconds int32      `json:"cooldown_seconds"`
+	Enabled         bool       `json:"enabled"`
+	CreatedBy       *uuid.UUID `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type RoomBan struct {
+	RoomID    uuid.UUID  `json:"room_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	BannedBy  *uuid.UUID `json:"banned_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RoomDailyStat struct {
+	RoomID        uuid.UUID `json:"room_id"`
+	Day           time.Time `json:"day"`
+	Messages      int64     `json:"messages"`
+	ActiveMembers int32     `json:"active_members"`
+}
+
+type RoomGroup struct {
+	ID        uuid.UUID `json:"id"`
+	RoomID    uuid.UUID `json:"room_id"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RoomGroupMember struct {
+	GroupID uuid.UUID `json:"group_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+type RoomIncomingWebhook struct {
+	ID         uuid.UUID  `json:"id"`
+	RoomID     uuid.UUID  `json:"room_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"token_hash"`
+	CreatedBy  *uuid.UUID `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type RoomInvite struct {
+	ID        uuid.UUID  `json:"id"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+type RoomInviteCode struct {
+	Code      string     `json:"code"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	MaxUses   *int32     `json:"max_uses"`
+	Uses      int32      `json:"uses"`
+}
+
+type RoomJoinRequest struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type RoomMember struct {
+	RoomID      uuid.UUID `json:"room_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	JoinedAt    time.Time `json:"joined_at"`
+	LastReadSeq int64     `json:"last_read_seq"`
+	Role        string    `json:"role"`
+}
+
+type RoomMemberChange struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	Version   int64     `json:"version"`
+	UserID    uuid.UUID `json:"user_id"`
+	Action    string    `json:"action"`
+	Role      *string   `json:"role"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type RoomMessageCount struct {
+	RoomID        uuid.UUID `json:"room_id"`
+	Messages      int64     `json:"messages"`
+	LastMessageAt time.Time `json:"last_message_at"`
+}
+
+type RoomNotificationSetting struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Level     string    `json:"level"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RoomPermission struct {
+	RoomID    uuid.UUID  `json:"room_id"`
+	Settings  []byte     `json:"settings"`
+	UpdatedBy *uuid.UUID `json:"updated_by"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type RoomPin struct {
+	MessageID uuid.UUID  `json:"message_id"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	PinnedBy  *uuid.UUID `json:"pinned_by"`
+	PinnedAt  time.Time  `json:"pinned_at"`
+}
+
+type RoomStat struct {
+	RoomID     uuid.UUID `json:"room_id"`
+	Stats      []byte    `json:"stats"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+type RoomTag struct {
 	RoomID uuid.UUID `json:"room_id"`
-	UserID uuid.UUID `json:"user_id"`
+	Tag    string    `json:"tag"`
+}
+
+type RoomWebhook struct {
+	ID        uuid.UUID  `json:"id"`
+	RoomID    uuid.UUID  `json:"room_id"`
+	Url       string     `json:"url"`
+	Secret    string     `json:"secret"`
+	Events    []string   `json:"events"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type SavedMessage struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SupportAccessGrant struct {
+	UserID    uuid.UUID `json:"user_id"`
+	GrantedAt time.Time `json:"granted_at"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type User struct {
-	ID        uuid.UUID          `json:"id"`
-	Username  string             `json:"username"`
-	Password  string             `json:"password"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID                uuid.UUID `json:"id"`
+	Username          string    `json:"username"`
+	Password          string    `json:"password"`
+	CreatedAt         time.Time `json:"created_at"`
+	PreferredLanguage *string   `json:"preferred_language"`
+	AvatarUrl         *string   `json:"avatar_url"`
+	IsAdmin           bool      `json:"is_admin"`
+	Version           int32     `json:"version"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	IsBot             bool      `json:"is_bot"`
+	DisplayName       string    `json:"display_name"`
+}
+
+type UserFolder struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Position  int32     `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserFolderRoom struct {
+	UserID   uuid.UUID `json:"user_id"`
+	RoomID   uuid.UUID `json:"room_id"`
+	FolderID uuid.UUID `json:"folder_id"`
+}
+
+type UserPrivacySetting struct {
+	UserID           uuid.UUID `json:"user_id"`
+	TypingIndicators bool      `json:"typing_indicators"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type UserStatus struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Emoji     string     `json:"emoji"`
+	Text      string     `json:"text"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type Workspace struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Domain       string    `json:"domain"`
+	LogoUrl      *string   `json:"logo_url"`
+	PrimaryColor *string   `json:"primary_color"`
+	AccentColor  *string   `json:"accent_color"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -7,12 +7,14 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const addRoomMember = `-- name: AddRoomMember :exec
-INSERT INTO room_members (room_id, user_id) VALUES ($1, $2)
+INSERT INTO room_members (room_id, user_id, last_read_seq)
+SELECT $1, $2, COALESCE(MAX(seq), 0) FROM messages WHERE room_id = $1
 `
 
 type AddRoomMemberParams struct {
@@ -20,35 +22,111 @@ type AddRoomMemberParams struct {
 	UserID uuid.UUID `json:"user_id"`
 }
 
+// New members start with everything sent before they joined read.
 func (q *Queries) AddRoomMember(ctx context.Context, arg AddRoomMemberParams) error {
 	_, err := q.db.Exec(ctx, addRoomMember, arg.RoomID, arg.UserID)
 	return err
 }
 
-const createRoom = `-- name: CreateRoom :one
-INSERT INTO rooms (id, name, owner_id) VALUES ($1, $2, $3) RETURNING id, name, owner_id, created_at
+const archiveRoom = `-- name: ArchiveRoom :one
+UPDATE rooms SET owner_id = $2, archived_at = NOW(), version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
 `
 
-type CreateRoomParams struct {
+type ArchiveRoomParams struct {
 	ID      uuid.UUID `json:"id"`
-	Name    string    `json:"name"`
 	OwnerID uuid.UUID `json:"owner_id"`
 }
 
+func (q *Queries) ArchiveRoom(ctx context.Context, arg ArchiveRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, archiveRoom, arg.ID, arg.OwnerID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
+	)
+	return i, err
+}
+
+const createRoom = `-- name: CreateRoom :one
+INSERT INTO rooms (id, name, owner_id, visibility, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
+`
+
+type CreateRoomParams struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	OwnerID    uuid.UUID  `json:"owner_id"`
+	Visibility string     `json:"visibility"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
 func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
-	row := q.db.QueryRow(ctx, createRoom, arg.ID, arg.Name, arg.OwnerID)
+	row := q.db.QueryRow(ctx, createRoom,
+		arg.ID,
+		arg.Name,
+		arg.OwnerID,
+		arg.Visibility,
+		arg.ExpiresAt,
+	)
 	var i Room
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.OwnerID,
 		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
 	)
 	return i, err
 }
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, username, password) VALUES ($1, $2, $3) RETURNING id, username, password, created_at
+INSERT INTO users (id, username, password) VALUES ($1, $2, $3) RETURNING id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name
 `
 
 type CreateUserParams struct {
@@ -65,6 +143,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Username,
 		&i.Password,
 		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
 	)
 	return i, err
 }
@@ -78,6 +163,18 @@ func (q *Queries) DeleteRoom(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteRoomMembers = `-- name: DeleteRoomMembers :execrows
+DELETE FROM room_members WHERE room_id = $1
+`
+
+func (q *Queries) DeleteRoomMembers(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoomMembers, roomID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1
 `
@@ -88,9 +185,10 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 }
 
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, username, password, created_at FROM users
+SELECT id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name FROM users
 `
 
+// Deprecated: returns the whole table; use ListUsers.
 func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 	rows, err := q.db.Query(ctx, getAllUsers)
 	if err != nil {
@@ -105,6 +203,13 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 			&i.Username,
 			&i.Password,
 			&i.CreatedAt,
+			&i.PreferredLanguage,
+			&i.AvatarUrl,
+			&i.IsAdmin,
+			&i.Version,
+			&i.UpdatedAt,
+			&i.IsBot,
+			&i.DisplayName,
 		); err != nil {
 			return nil, err
 		}
@@ -116,8 +221,39 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const getExpiredRooms = `-- name: GetExpiredRooms :many
+SELECT id FROM rooms
+WHERE expires_at <= NOW()
+  AND NOT EXISTS (SELECT 1 FROM messages AS m WHERE m.room_id = rooms.id AND message_under_legal_hold(m.room_id, m.sender_id))
+  AND NOT EXISTS (SELECT 1 FROM legal_holds AS h WHERE h.room_id = rooms.id)
+ORDER BY expires_at
+LIMIT $1
+`
+
+// Lists the rooms whose expiry has passed, longest expired first. Rooms under
+// legal hold, or holding messages that are, are kept until it is released.
+func (q *Queries) GetExpiredRooms(ctx context.Context, maxRooms int32) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getExpiredRooms, maxRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoomByID = `-- name: GetRoomByID :one
-SELECT id, name, owner_id, created_at FROM rooms WHERE id = $1
+SELECT id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable FROM rooms WHERE id = $1
 `
 
 func (q *Queries) GetRoomByID(ctx context.Context, id uuid.UUID) (Room, error) {
@@ -128,17 +264,103 @@ func (q *Queries) GetRoomByID(ctx context.Context, id uuid.UUID) (Room, error) {
 		&i.Name,
 		&i.OwnerID,
 		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
 	)
 	return i, err
 }
 
+const getRoomMates = `-- name: GetRoomMates :many
+SELECT DISTINCT other.user_id FROM room_members AS mine
+JOIN room_members AS other ON other.room_id = mine.room_id
+WHERE mine.user_id = $1 AND other.user_id = ANY($2::uuid[])
+`
+
+type GetRoomMatesParams struct {
+	UserID  uuid.UUID   `json:"user_id"`
+	UserIds []uuid.UUID `json:"user_ids"`
+}
+
+// Returns those of the users who share a room with the user.
+func (q *Queries) GetRoomMates(ctx context.Context, arg GetRoomMatesParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getRoomMates, arg.UserID, arg.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMemberIDs = `-- name: GetRoomMemberIDs :many
+SELECT rm.user_id FROM room_members AS rm
+WHERE rm.room_id = $1
+  AND NOT EXISTS(
+      SELECT 1 FROM room_bans AS b
+      WHERE b.room_id = rm.room_id AND b.user_id = rm.user_id AND (b.expires_at IS NULL OR b.expires_at > NOW())
+  )
+`
+
+// Lists the room's members the way IsRoomMember sees them: banned users are
+// left out.
+func (q *Queries) GetRoomMemberIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getRoomMemberIDs, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoomMembers = `-- name: GetRoomMembers :many
-SELECT u.id, u.username FROM users AS u JOIN room_members AS rm ON u.id = rm.user_id WHERE rm.room_id = $1
+SELECT u.id, u.username, u.display_name FROM users AS u JOIN room_members AS rm ON u.id = rm.user_id WHERE rm.room_id = $1
 `
 
 type GetRoomMembersRow struct {
-	ID       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
 }
 
 func (q *Queries) GetRoomMembers(ctx context.Context, roomID uuid.UUID) ([]GetRoomMembersRow, error) {
@@ -150,7 +372,7 @@ func (q *Queries) GetRoomMembers(ctx context.Context, roomID uuid.UUID) ([]GetRo
 	var items []GetRoomMembersRow
 	for rows.Next() {
 		var i GetRoomMembersRow
-		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+		if err := rows.Scan(&i.ID, &i.Username, &i.DisplayName); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -161,12 +383,93 @@ func (q *Queries) GetRoomMembers(ctx context.Context, roomID uuid.UUID) ([]GetRo
 	return items, nil
 }
 
-const getRooms = `-- name: GetRooms :many
-SELECT id, name, owner_id, created_at FROM rooms ORDER BY created_at DESC
+const getRoomMembersPage = `-- name: GetRoomMembersPage :many
+SELECT u.id, u.username, u.is_bot, u.display_name, rm.role, rm.joined_at FROM room_members AS rm
+JOIN users AS u ON u.id = rm.user_id
+WHERE rm.room_id = $1
+  AND ($2::timestamptz IS NULL
+       OR (rm.joined_at, rm.user_id) > ($2::timestamptz, $3::uuid))
+ORDER BY rm.joined_at ASC, rm.user_id ASC
+LIMIT $4
 `
 
-func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
-	rows, err := q.db.Query(ctx, getRooms)
+type GetRoomMembersPageParams struct {
+	RoomID         uuid.UUID  `json:"room_id"`
+	CursorJoinedAt *time.Time `json:"cursor_joined_at"`
+	CursorUserID   *uuid.UUID `json:"cursor_user_id"`
+	MaxResults     int32      `json:"max_results"`
+}
+
+type GetRoomMembersPageRow struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username"`
+	IsBot       bool      `json:"is_bot"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+func (q *Queries) GetRoomMembersPage(ctx context.Context, arg GetRoomMembersPageParams) ([]GetRoomMembersPageRow, error) {
+	rows, err := q.db.Query(ctx, getRoomMembersPage,
+		arg.RoomID,
+		arg.CursorJoinedAt,
+		arg.CursorUserID,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomMembersPageRow
+	for rows.Next() {
+		var i GetRoomMembersPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.IsBot,
+			&i.DisplayName,
+			&i.Role,
+			&i.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomSuccessor = `-- name: GetRoomSuccessor :one
+SELECT rm.user_id FROM room_members AS rm
+JOIN users AS u ON u.id = rm.user_id
+WHERE rm.room_id = $1 AND rm.user_id <> $2 AND NOT u.is_bot
+ORDER BY rm.role = 'owner' DESC, rm.role = 'moderator' DESC, u.is_admin DESC, rm.joined_at ASC
+LIMIT 1
+`
+
+type GetRoomSuccessorParams struct {
+	RoomID  uuid.UUID `json:"room_id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+}
+
+// Picks who inherits a room from its owner: other owners first, then
+// moderators, administrators and other members, longest-standing first.
+// Bots never inherit rooms.
+func (q *Queries) GetRoomSuccessor(ctx context.Context, arg GetRoomSuccessorParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getRoomSuccessor, arg.RoomID, arg.OwnerID)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const getRoomsOwnedBy = `-- name: GetRoomsOwnedBy :many
+SELECT id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable FROM rooms WHERE owner_id = $1 ORDER BY created_at ASC FOR UPDATE
+`
+
+func (q *Queries) GetRoomsOwnedBy(ctx context.Context, ownerID uuid.UUID) ([]Room, error) {
+	rows, err := q.db.Query(ctx, getRoomsOwnedBy, ownerID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +482,28 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 			&i.Name,
 			&i.OwnerID,
 			&i.CreatedAt,
+			&i.MaxMessageSize,
+			&i.RetentionDays,
+			&i.RetentionMaxMessages,
+			&i.RetentionHold,
+			&i.Version,
+			&i.UpdatedAt,
+			&i.AllowUrgent,
+			&i.ArchivedAt,
+			&i.Visibility,
+			&i.StatsEnabled,
+			&i.Topic,
+			&i.Description,
+			&i.AvatarUrl,
+			&i.AvatarKey,
+			&i.RoomMentionRole,
+			&i.MemberVersion,
+			&i.Kind,
+			&i.SummariesEnabled,
+			&i.LastMessageAt,
+			&i.ExpiresAt,
+			&i.EncryptedAt,
+			&i.PublicReadable,
 		); err != nil {
 			return nil, err
 		}
@@ -191,7 +516,7 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, password, created_at FROM users WHERE id = $1
+SELECT id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -202,12 +527,19 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Username,
 		&i.Password,
 		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password, created_at FROM users WHERE username = $1
+SELECT id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name FROM users WHERE username = $1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -218,12 +550,100 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.Username,
 		&i.Password,
 		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
 	)
 	return i, err
 }
 
+const getUserContentUsage = `-- name: GetUserContentUsage :one
+SELECT COUNT(*) AS message_count,
+       COUNT(*) FILTER (WHERE kind = 'poll') AS poll_count,
+       COALESCE(SUM(octet_length(content) + octet_length(metadata::text)), 0)::bigint AS storage_bytes
+FROM messages
+WHERE sender_id = $1
+`
+
+type GetUserContentUsageRow struct {
+	MessageCount int64 `json:"message_count"`
+	PollCount    int64 `json:"poll_count"`
+	StorageBytes int64 `json:"storage_bytes"`
+}
+
+// Sums up what a user has posted, for the account deletion report. Storage
+// counts the bytes of message content and metadata.
+func (q *Queries) GetUserContentUsage(ctx context.Context, senderID uuid.UUID) (GetUserContentUsageRow, error) {
+	row := q.db.QueryRow(ctx, getUserContentUsage, senderID)
+	var i GetUserContentUsageRow
+	err := row.Scan(&i.MessageCount, &i.PollCount, &i.StorageBytes)
+	return i, err
+}
+
+const getUserLanguages = `-- name: GetUserLanguages :many
+SELECT id, preferred_language FROM users WHERE id = ANY($1::uuid[])
+`
+
+type GetUserLanguagesRow struct {
+	ID                uuid.UUID `json:"id"`
+	PreferredLanguage *string   `json:"preferred_language"`
+}
+
+// Returns the preferred languages of the users, for the warm cache.
+func (q *Queries) GetUserLanguages(ctx context.Context, ids []uuid.UUID) ([]GetUserLanguagesRow, error) {
+	rows, err := q.db.Query(ctx, getUserLanguages, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLanguagesRow
+	for rows.Next() {
+		var i GetUserLanguagesRow
+		if err := rows.Scan(&i.ID, &i.PreferredLanguage); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserRoomIDs = `-- name: GetUserRoomIDs :many
+SELECT room_id FROM room_members WHERE user_id = $1
+`
+
+func (q *Queries) GetUserRoomIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getUserRoomIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var room_id uuid.UUID
+		if err := rows.Scan(&room_id); err != nil {
+			return nil, err
+		}
+		items = append(items, room_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const isRoomMember = `-- name: IsRoomMember :one
 SELECT EXISTS(SELECT 1 FROM room_members WHERE room_id = $1 AND user_id = $2)
+    AND NOT EXISTS(
+        SELECT 1 FROM room_bans
+        WHERE room_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+    )
 `
 
 type IsRoomMemberParams struct {
@@ -238,6 +658,180 @@ func (q *Queries) IsRoomMember(ctx context.Context, arg IsRoomMemberParams) (boo
 	return exists, err
 }
 
+const listRooms = `-- name: ListRooms :many
+SELECT listed.id, listed.name, listed.owner_id, listed.created_at, listed.max_message_size, listed.retention_days, listed.retention_max_messages, listed.retention_hold, listed.version, listed.updated_at, listed.allow_urgent, listed.archived_at, listed.visibility, listed.stats_enabled, listed.topic, listed.description, listed.avatar_url, listed.avatar_key, listed.room_mention_role, listed.member_version, listed.kind, listed.summaries_enabled, listed.last_message_at, listed.expires_at, listed.encrypted_at, listed.public_readable, listed.member_count, listed.last_activity_at, listed.tags
+FROM (
+    SELECT r.id, r.name, r.owner_id, r.created_at, r.max_message_size, r.retention_days, r.retention_max_messages, r.retention_hold, r.version, r.updated_at, r.allow_urgent, r.archived_at, r.visibility, r.stats_enabled, r.topic, r.description, r.avatar_url, r.avatar_key, r.room_mention_role, r.member_version, r.kind, r.summaries_enabled, r.last_message_at, r.expires_at, r.encrypted_at, r.public_readable,
+        (SELECT COUNT(*) FROM room_members WHERE room_id = r.id) AS member_count,
+        COALESCE(r.last_message_at, r.created_at) AS last_activity_at,
+        ARRAY(SELECT tag FROM room_tags WHERE room_id = r.id ORDER BY tag)::text[] AS tags
+    FROM rooms AS r
+    WHERE r.kind = 'room'
+      AND (r.visibility = 'public' OR r.owner_id = $1
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = $1))
+      AND ($2::uuid IS NULL OR r.owner_id = $2::uuid
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = $2::uuid AND role = 'owner'))
+      AND ($3::uuid IS NULL
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = $3::uuid))
+      AND ($4::text IS NULL OR r.visibility = $4::text)
+      AND ($5::text IS NULL
+           OR EXISTS (SELECT 1 FROM room_tags WHERE room_id = r.id AND tag = $5::text))
+) AS listed
+WHERE $6::uuid IS NULL OR CASE $7::text
+    WHEN 'member_count' THEN (listed.member_count, listed.id) < ($8::bigint, $6::uuid)
+    WHEN 'last_activity' THEN (listed.last_activity_at, listed.id) < ($9::timestamptz, $6::uuid)
+    ELSE (listed.created_at, listed.id) < ($9::timestamptz, $6::uuid)
+END
+ORDER BY
+    CASE WHEN $7::text = 'member_count' THEN listed.member_count END DESC,
+    CASE WHEN $7::text = 'last_activity' THEN listed.last_activity_at END DESC,
+    listed.created_at DESC,
+    listed.id DESC
+LIMIT $10
+`
+
+type ListRoomsParams struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	OwnedBy     *uuid.UUID `json:"owned_by"`
+	MemberOf    *uuid.UUID `json:"member_of"`
+	Visibility  *string    `json:"visibility"`
+	Tag         *string    `json:"tag"`
+	CursorID    *uuid.UUID `json:"cursor_id"`
+	Sort        string     `json:"sort"`
+	CursorCount *int64     `json:"cursor_count"`
+	CursorTime  *time.Time `json:"cursor_time"`
+	MaxResults  int32      `json:"max_results"`
+}
+
+type ListRoomsRow struct {
+	Room           Room      `json:"room"`
+	MemberCount    int64     `json:"member_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Tags           []string  `json:"tags"`
+}
+
+// Lists a page of the rooms visible to a user, newest, most recently active
+// or largest first. Private rooms are only listed to their owners and
+// members, and group conversations never. The cursor holds the sort key and ID of the previous page's last
+// room: cursor_time for created_at and last_activity, cursor_count for
+// member_count.
+func (q *Queries) ListRooms(ctx context.Context, arg ListRoomsParams) ([]ListRoomsRow, error) {
+	rows, err := q.db.Query(ctx, listRooms,
+		arg.UserID,
+		arg.OwnedBy,
+		arg.MemberOf,
+		arg.Visibility,
+		arg.Tag,
+		arg.CursorID,
+		arg.Sort,
+		arg.CursorCount,
+		arg.CursorTime,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoomsRow
+	for rows.Next() {
+		var i ListRoomsRow
+		if err := rows.Scan(
+			&i.Room.ID,
+			&i.Room.Name,
+			&i.Room.OwnerID,
+			&i.Room.CreatedAt,
+			&i.Room.MaxMessageSize,
+			&i.Room.RetentionDays,
+			&i.Room.RetentionMaxMessages,
+			&i.Room.RetentionHold,
+			&i.Room.Version,
+			&i.Room.UpdatedAt,
+			&i.Room.AllowUrgent,
+			&i.Room.ArchivedAt,
+			&i.Room.Visibility,
+			&i.Room.StatsEnabled,
+			&i.Room.Topic,
+			&i.Room.Description,
+			&i.Room.AvatarUrl,
+			&i.Room.AvatarKey,
+			&i.Room.RoomMentionRole,
+			&i.Room.MemberVersion,
+			&i.Room.Kind,
+			&i.Room.SummariesEnabled,
+			&i.Room.LastMessageAt,
+			&i.Room.ExpiresAt,
+			&i.Room.EncryptedAt,
+			&i.Room.PublicReadable,
+			&i.MemberCount,
+			&i.LastActivityAt,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name FROM users
+WHERE ($1::text IS NULL OR username ILIKE '%' || $1::text || '%')
+  AND ($2::timestamptz IS NULL OR created_at > $2::timestamptz)
+  AND ($3::timestamptz IS NULL
+       OR (created_at, id) > ($3::timestamptz, $4::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type ListUsersParams struct {
+	Q               *string    `json:"q"`
+	CreatedAfter    *time.Time `json:"created_after"`
+	CursorCreatedAt *time.Time `json:"cursor_created_at"`
+	CursorID        *uuid.UUID `json:"cursor_id"`
+	MaxResults      int32      `json:"max_results"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Q,
+		arg.CreatedAfter,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Password,
+			&i.CreatedAt,
+			&i.PreferredLanguage,
+			&i.AvatarUrl,
+			&i.IsAdmin,
+			&i.Version,
+			&i.UpdatedAt,
+			&i.IsBot,
+			&i.DisplayName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const removeRoomMember = `-- name: RemoveRoomMember :exec
 DELETE FROM room_members WHERE room_id = $1 AND user_id = $2
 `
@@ -252,8 +846,81 @@ func (q *Queries) RemoveRoomMember(ctx context.Context, arg RemoveRoomMemberPara
 	return err
 }
 
+const searchRooms = `-- name: SearchRooms :many
+SELECT id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable FROM rooms
+WHERE kind = 'room'
+  AND (visibility = 'public' OR owner_id = $1
+       OR EXISTS (SELECT 1 FROM room_members WHERE room_id = rooms.id AND user_id = $1))
+  AND (name ILIKE $2::text ESCAPE '\' OR name % $3::text OR description ILIKE $2::text ESCAPE '\')
+ORDER BY similarity(name, $3::text) DESC, created_at DESC, id DESC
+LIMIT $4
+`
+
+type SearchRoomsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Pattern    string    `json:"pattern"`
+	Q          string    `json:"q"`
+	MaxResults int32     `json:"max_results"`
+}
+
+// Finds the rooms visible to a user whose names contain the query or are
+// similar to it, or whose descriptions contain it. Name matches come first,
+// best match first. Group conversations are never found. @pattern is the
+// query as a LIKE pattern, escaped with \.
+func (q *Queries) SearchRooms(ctx context.Context, arg SearchRoomsParams) ([]Room, error) {
+	rows, err := q.db.Query(ctx, searchRooms,
+		arg.UserID,
+		arg.Pattern,
+		arg.Q,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OwnerID,
+			&i.CreatedAt,
+			&i.MaxMessageSize,
+			&i.RetentionDays,
+			&i.RetentionMaxMessages,
+			&i.RetentionHold,
+			&i.Version,
+			&i.UpdatedAt,
+			&i.AllowUrgent,
+			&i.ArchivedAt,
+			&i.Visibility,
+			&i.StatsEnabled,
+			&i.Topic,
+			&i.Description,
+			&i.AvatarUrl,
+			&i.AvatarKey,
+			&i.RoomMentionRole,
+			&i.MemberVersion,
+			&i.Kind,
+			&i.SummariesEnabled,
+			&i.LastMessageAt,
+			&i.ExpiresAt,
+			&i.EncryptedAt,
+			&i.PublicReadable,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchUsers = `-- name: SearchUsers :many
-SELECT id, username, password, created_at FROM users WHERE username ILIKE $1
+SELECT id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name FROM users WHERE username ILIKE $1
 `
 
 func (q *Queries) SearchUsers(ctx context.Context, username string) ([]User, error) {
@@ -270,6 +937,13 @@ func (q *Queries) SearchUsers(ctx context.Context, username string) ([]User, err
 			&i.Username,
 			&i.Password,
 			&i.CreatedAt,
+			&i.PreferredLanguage,
+			&i.AvatarUrl,
+			&i.IsAdmin,
+			&i.Version,
+			&i.UpdatedAt,
+			&i.IsBot,
+			&i.DisplayName,
 		); err != nil {
 			return nil, err
 		}
@@ -281,45 +955,310 @@ func (q *Queries) SearchUsers(ctx context.Context, username string) ([]User, err
 	return items, nil
 }
 
+const setRoomAvatar = `-- name: SetRoomAvatar :one
+UPDATE rooms SET avatar_url = $2, avatar_key = $3, version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
+`
+
+type SetRoomAvatarParams struct {
+	ID        uuid.UUID `json:"id"`
+	AvatarUrl *string   `json:"avatar_url"`
+	AvatarKey *string   `json:"avatar_key"`
+}
+
+func (q *Queries) SetRoomAvatar(ctx context.Context, arg SetRoomAvatarParams) (Room, error) {
+	row := q.db.QueryRow(ctx, setRoomAvatar, arg.ID, arg.AvatarUrl, arg.AvatarKey)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
+	)
+	return i, err
+}
+
+const setRoomSettings = `-- name: SetRoomSettings :one
+UPDATE rooms SET max_message_size = $2, allow_urgent = $3, visibility = $4, stats_enabled = $5, room_mention_role = $6, summaries_enabled = $7, public_readable = $8, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND ($9::int IS NULL OR version = $9::int)
+RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
+`
+
+type SetRoomSettingsParams struct {
+	ID               uuid.UUID `json:"id"`
+	MaxMessageSize   *int32    `json:"max_message_size"`
+	AllowUrgent      bool      `json:"allow_urgent"`
+	Visibility       string    `json:"visibility"`
+	StatsEnabled     bool      `json:"stats_enabled"`
+	RoomMentionRole  string    `json:"room_mention_role"`
+	SummariesEnabled bool      `json:"summaries_enabled"`
+	PublicReadable   bool      `json:"public_readable"`
+	ExpectedVersion  *int32    `json:"expected_version"`
+}
+
+func (q *Queries) SetRoomSettings(ctx context.Context, arg SetRoomSettingsParams) (Room, error) {
+	row := q.db.QueryRow(ctx, setRoomSettings,
+		arg.ID,
+		arg.MaxMessageSize,
+		arg.AllowUrgent,
+		arg.Visibility,
+		arg.StatsEnabled,
+		arg.RoomMentionRole,
+		arg.SummariesEnabled,
+		arg.PublicReadable,
+		arg.ExpectedVersion,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
+	)
+	return i, err
+}
+
+const setUserDisplayName = `-- name: SetUserDisplayName :one
+UPDATE users SET display_name = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND ($3::int IS NULL OR version = $3::int)
+RETURNING id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name
+`
+
+type SetUserDisplayNameParams struct {
+	ID              uuid.UUID `json:"id"`
+	DisplayName     string    `json:"display_name"`
+	ExpectedVersion *int32    `json:"expected_version"`
+}
+
+func (q *Queries) SetUserDisplayName(ctx context.Context, arg SetUserDisplayNameParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserDisplayName, arg.ID, arg.DisplayName, arg.ExpectedVersion)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
+	)
+	return i, err
+}
+
+const setUserPreferredLanguage = `-- name: SetUserPreferredLanguage :one
+UPDATE users SET preferred_language = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND ($3::int IS NULL OR version = $3::int)
+RETURNING id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name
+`
+
+type SetUserPreferredLanguageParams struct {
+	ID                uuid.UUID `json:"id"`
+	PreferredLanguage *string   `json:"preferred_language"`
+	ExpectedVersion   *int32    `json:"expected_version"`
+}
+
+func (q *Queries) SetUserPreferredLanguage(ctx context.Context, arg SetUserPreferredLanguageParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPreferredLanguage, arg.ID, arg.PreferredLanguage, arg.ExpectedVersion)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
+	)
+	return i, err
+}
+
+const transferRoomOwnership = `-- name: TransferRoomOwnership :one
+UPDATE rooms SET owner_id = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
+`
+
+type TransferRoomOwnershipParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+}
+
+func (q *Queries) TransferRoomOwnership(ctx context.Context, arg TransferRoomOwnershipParams) (Room, error) {
+	row := q.db.QueryRow(ctx, transferRoomOwnership, arg.ID, arg.OwnerID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
+	)
+	return i, err
+}
+
 const updateRoom = `-- name: UpdateRoom :one
-UPDATE rooms SET name = $2 WHERE id = $1 RETURNING id, name, owner_id, created_at
+UPDATE rooms SET name = COALESCE($2, name), topic = COALESCE($3, topic),
+    description = COALESCE($4, description), version = version + 1, updated_at = NOW()
+WHERE id = $1 AND ($5::int IS NULL OR version = $5::int)
+RETURNING id, name, owner_id, created_at, max_message_size, retention_days, retention_max_messages, retention_hold, version, updated_at, allow_urgent, archived_at, visibility, stats_enabled, topic, description, avatar_url, avatar_key, room_mention_role, member_version, kind, summaries_enabled, last_message_at, expires_at, encrypted_at, public_readable
 `
 
 type UpdateRoomParams struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID              uuid.UUID `json:"id"`
+	Name            *string   `json:"name"`
+	Topic           *string   `json:"topic"`
+	Description     *string   `json:"description"`
+	ExpectedVersion *int32    `json:"expected_version"`
 }
 
+// Fields passed as NULL keep their current value.
 func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error) {
-	row := q.db.QueryRow(ctx, updateRoom, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateRoom,
+		arg.ID,
+		arg.Name,
+		arg.Topic,
+		arg.Description,
+		arg.ExpectedVersion,
+	)
 	var i Room
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.OwnerID,
 		&i.CreatedAt,
+		&i.MaxMessageSize,
+		&i.RetentionDays,
+		&i.RetentionMaxMessages,
+		&i.RetentionHold,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.AllowUrgent,
+		&i.ArchivedAt,
+		&i.Visibility,
+		&i.StatsEnabled,
+		&i.Topic,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.RoomMentionRole,
+		&i.MemberVersion,
+		&i.Kind,
+		&i.SummariesEnabled,
+		&i.LastMessageAt,
+		&i.ExpiresAt,
+		&i.EncryptedAt,
+		&i.PublicReadable,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
-UPDATE users SET username = $2, password = $3 WHERE id = $1 RETURNING id, username, password, created_at
+UPDATE users SET username = $2, password = $3, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND ($4::int IS NULL OR version = $4::int)
+RETURNING id, username, password, created_at, preferred_language, avatar_url, is_admin, version, updated_at, is_bot, display_name
 `
 
 type UpdateUserParams struct {
-	ID       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
-	Password string    `json:"password"`
+	ID              uuid.UUID `json:"id"`
+	Username        string    `json:"username"`
+	Password        string    `json:"password"`
+	ExpectedVersion *int32    `json:"expected_version"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.ID, arg.Username, arg.Password)
+	row := q.db.QueryRow(ctx, updateUser,
+		arg.ID,
+		arg.Username,
+		arg.Password,
+		arg.ExpectedVersion,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
 		&i.Password,
 		&i.CreatedAt,
+		&i.PreferredLanguage,
+		&i.AvatarUrl,
+		&i.IsAdmin,
+		&i.Version,
+		&i.UpdatedAt,
+		&i.IsBot,
+		&i.DisplayName,
 	)
 	return i, err
 }
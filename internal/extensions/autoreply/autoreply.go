@@ -0,0 +1,249 @@
+// Package autoreply lets room owners set up automatic replies, such as FAQ
+// answers or support hours: when a message mentions one of a rule's
+// keywords, the system bot answers it in the room. Each sender gets a rule's
+// reply at most once per the rule's cooldown.
+//
+// It is a built-in extension: it registers itself with the server, watches
+// new messages through the hub's hooks and mounts the routes owners manage
+// their rules with.
+package autoreply
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/server"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+const (
+	// MaxRules is how many automatic replies a room can have.
+	MaxRules = 20
+	// MaxKeywords is how many keywords a rule can have, and
+	// MaxKeywordLength how long each can be, in characters.
+	MaxKeywords      = 10
+	MaxKeywordLength = 64
+	// MaxReplyLength is the longest a reply can be, in characters.
+	MaxReplyLength = 2000
+	// DefaultCooldown is how long a sender waits for a rule's reply again
+	// when the rule sets no cooldown.
+	DefaultCooldown = 10 * time.Minute
+	// MaxCooldown is the longest cooldown a rule can set.
+	MaxCooldown = 24 * time.Hour
+)
+
+// pruneInterval is how often expired cooldowns are forgotten.
+const pruneInterval = 10 * time.Minute
+
+// Rule is an automatic reply of a room.
+type Rule struct {
+	ID string `json:"id"`
+	// Keywords are matched as whole words or phrases, ignoring case and
+	// punctuation. They are stored lowercased.
+	Keywords []string `json:"keywords" example:"hours,opening times"`
+	Reply    string   `json:"reply" example:"Support is available 9:00-17:00 UTC, Monday to Friday."`
+	// CooldownSeconds is how long each sender waits before the rule
+	// answers them again.
+	CooldownSeconds int32 `json:"cooldown_seconds" example:"600"`
+	Enabled         bool  `json:"enabled" example:"true"`
+	// CreatedBy is absent once the creator's account is deleted.
+	CreatedBy *string   `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Extension answers messages with their rooms' automatic replies.
+type Extension struct {
+	db       *database.Queries
+	hub      *service.Hub
+	messages *service.MessageService
+
+	mu sync.Mutex
+	// rules caches each room's rules; it is dropped whenever they change.
+	rules map[uuid.UUID][]database.RoomAutoReply
+	// cooldowns holds until when each rule stays quiet for each sender.
+	cooldowns map[cooldownKey]time.Time
+}
+
+type cooldownKey struct {
+	ruleID   uuid.UUID
+	senderID string
+}
+
+func init() {
+	server.RegisterExtension(New())
+}
+
+// New creates the extension. It does nothing until initialized.
+func New() *Extension {
+	return &Extension{
+		rules:     make(map[uuid.UUID][]database.RoomAutoReply),
+		cooldowns: make(map[cooldownKey]time.Time),
+	}
+}
+
+// Name implements server.Extension.
+func (e *Extension) Name() string {
+	return "autoreply"
+}
+
+// Init implements server.Initializer.
+func (e *Extension) Init(ctx context.Context, deps server.Deps) error {
+	e.db = deps.DB
+	e.hub = deps.Hub
+	e.messages = deps.Messages
+	return nil
+}
+
+// OnMessage implements server.HubObserver. New room messages that match one
+// of their room's enabled rules are answered with the first such rule's
+// reply, unless its cooldown for the sender has not passed. Direct messages
+// and the system bot's own messages are never answered.
+func (e *Extension) OnMessage(message service.Message) {
+	if e.db == nil || message.Type != "" || message.ID == "" || message.RecipientID != "" || message.SenderID == service.SystemUserID.String() {
+		return
+	}
+	roomID, err := uuid.Parse(message.RoomID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rules, err := e.roomRules(ctx, roomID)
+	if err != nil {
+		log.Printf("autoreply: failed to load rules of room %s: %v", roomID, err)
+		return
+	}
+	content := " " + normalize(message.Content) + " "
+	for _, rule := range rules {
+		if !rule.Enabled || !matches(rule.Keywords, content) {
+			continue
+		}
+		if e.claim(rule, message.SenderID) {
+			e.reply(ctx, rule, message)
+		}
+		return
+	}
+}
+
+// Run implements server.Job, forgetting expired cooldowns so they do not
+// pile up.
+func (e *Extension) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.mu.Lock()
+			for key, until := range e.cooldowns {
+				if now.After(until) {
+					delete(e.cooldowns, key)
+				}
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// roomRules returns the room's rules, loading them on first use.
+func (e *Extension) roomRules(ctx context.Context, roomID uuid.UUID) ([]database.RoomAutoReply, error) {
+	e.mu.Lock()
+	rules, ok := e.rules[roomID]
+	e.mu.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	rules, err := e.db.GetRoomAutoReplies(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.rules[roomID] = rules
+	e.mu.Unlock()
+	return rules, nil
+}
+
+// forget drops the cached rules of a room after they changed.
+func (e *Extension) forget(roomID uuid.UUID) {
+	e.mu.Lock()
+	delete(e.rules, roomID)
+	e.mu.Unlock()
+}
+
+// claim reports whether the rule may answer the sender now and, if so,
+// starts its cooldown for them.
+func (e *Extension) claim(rule database.RoomAutoReply, senderID string) bool {
+	now := time.Now()
+	key := cooldownKey{ruleID: rule.ID, senderID: senderID}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if until, ok := e.cooldowns[key]; ok && now.Before(until) {
+		return false
+	}
+	e.cooldowns[key] = now.Add(time.Duration(rule.CooldownSeconds) * time.Second)
+	return true
+}
+
+// reply sends the rule's reply to the room as the system bot, quoting the
+// message that triggered it.
+func (e *Extension) reply(ctx context.Context, rule database.RoomAutoReply, message service.Message) {
+	reply := &service.Message{
+		SenderID:        service.SystemUserID.String(),
+		RoomID:          message.RoomID,
+		Content:         rule.Reply,
+		Kind:            service.MessageKindText,
+		QuotedMessageID: message.ID,
+		Metadata:        map[string]any{"auto_reply": map[string]any{"rule_id": rule.ID.String()}},
+	}
+	if err := e.messages.SaveMessage(ctx, reply); err != nil {
+		log.Printf("autoreply: failed to send reply of rule %s: %v", rule.ID, err)
+		return
+	}
+	e.hub.Broadcast(reply)
+}
+
+// matches reports whether the normalized content, padded with a space on
+// each side, contains one of the keywords as whole words.
+func matches(keywords []string, content string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(content, " "+keyword+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases s and reduces every run of characters other than
+// letters and digits to a single space.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+func ruleFromRow(row database.RoomAutoReply) Rule {
+	rule := Rule{
+		ID:              row.ID.String(),
+		Keywords:        row.Keywords,
+		Reply:           row.Reply,
+		CooldownSeconds: row.CooldownSeconds,
+		Enabled:         row.Enabled,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+	if row.CreatedBy != nil {
+		createdBy := row.CreatedBy.String()
+		rule.CreatedBy = &createdBy
+	}
+	return rule
+}
@@ -0,0 +1,329 @@
+package autoreply
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// RuleRequest defines the request body for creating or replacing an
+// automatic reply.
+type RuleRequest struct {
+	// Keywords trigger the reply when a message contains one of them as
+	// whole words, ignoring case and punctuation; 1-10 of up to 64
+	// characters.
+	Keywords []string `json:"keywords" example:"hours,opening times"`
+	// Reply is the text the system bot answers with, up to 2000 characters.
+	Reply string `json:"reply" example:"Support is available 9:00-17:00 UTC, Monday to Friday."`
+	// CooldownSeconds is how long each sender waits before the rule answers
+	// them again, at most 86400; 600 when omitted.
+	CooldownSeconds *int32 `json:"cooldown_seconds,omitempty" example:"600"`
+	// Enabled turns the rule on or off; true when omitted.
+	Enabled *bool `json:"enabled,omitempty" example:"true"`
+}
+
+// Routes implements server.Router.
+func (e *Extension) Routes(r chi.Router) {
+	r.Get("/rooms/{id}/auto-replies", e.GetAutoReplies)
+	r.Post("/rooms/{id}/auto-replies", e.CreateAutoReply)
+	r.Put("/rooms/{id}/auto-replies/{ruleID}", e.UpdateAutoReply)
+	r.Delete("/rooms/{id}/auto-replies/{ruleID}", e.DeleteAutoReply)
+}
+
+// GetAutoReplies godoc
+// @Summary      List a room's automatic replies
+// @Description  Lists the room's automatic replies in the order they are checked, oldest first. Only owners and co-owners can see them.
+// @Tags         auto-replies
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {array}   autoreply.Rule
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Only owners can manage automatic replies"
+// @Failure      404  {string}  string "Room not found"
+// @Failure      500  {string}  string "Failed to get automatic replies"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/auto-replies [get]
+func (e *Extension) GetAutoReplies(w http.ResponseWriter, r *http.Request) {
+	room, _, ok := e.ownedRoom(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := e.db.GetRoomAutoReplies(r.Context(), room.ID)
+	if err != nil {
+		log.Printf("Failed to get automatic replies: %v", err)
+		http.Error(w, "Failed to get automatic replies", http.StatusInternalServerError)
+		return
+	}
+	rules := make([]Rule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, ruleFromRow(row))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreateAutoReply godoc
+// @Summary      Create an automatic reply
+// @Description  Adds an automatic reply to the room: when a room message contains one of the keywords, the system bot answers in the room with the reply, quoting the message. Rules are checked oldest first and only the first matching enabled rule answers. Each sender gets a rule's reply at most once per its cooldown. Direct messages are never answered. A room can have up to 20 rules; only owners and co-owners can manage them.
+// @Tags         auto-replies
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string       true  "Room ID"
+// @Param        rule  body      RuleRequest  true  "Keywords, reply and cooldown"
+// @Success      201   {object}  autoreply.Rule
+// @Failure      400   {string}  string "Invalid room ID, request body, keywords, reply or cooldown"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: Only owners can manage automatic replies"
+// @Failure      404   {string}  string "Room not found"
+// @Failure      409   {string}  string "Rooms can have at most 20 automatic replies"
+// @Failure      500   {string}  string "Failed to create automatic reply"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/auto-replies [post]
+func (e *Extension) CreateAutoReply(w http.ResponseWriter, r *http.Request) {
+	room, userID, ok := e.ownedRoom(w, r)
+	if !ok {
+		return
+	}
+
+	params, ok := decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := e.db.CountRoomAutoReplies(r.Context(), room.ID)
+	if err != nil {
+		log.Printf("Failed to create automatic reply: %v", err)
+		http.Error(w, "Failed to create automatic reply", http.StatusInternalServerError)
+		return
+	}
+	if count >= MaxRules {
+		http.Error(w, fmt.Sprintf("Rooms can have at most %d automatic replies", MaxRules), http.StatusConflict)
+		return
+	}
+
+	row, err := e.db.CreateRoomAutoReply(r.Context(), database.CreateRoomAutoReplyParams{
+		ID:              uuid.New(),
+		RoomID:          room.ID,
+		Keywords:        params.Keywords,
+		Reply:           params.Reply,
+		CooldownSeconds: params.CooldownSeconds,
+		Enabled:         params.Enabled,
+		CreatedBy:       &userID,
+	})
+	if err != nil {
+		log.Printf("Failed to create automatic reply: %v", err)
+		http.Error(w, "Failed to create automatic reply", http.StatusInternalServerError)
+		return
+	}
+	e.forget(room.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ruleFromRow(row))
+}
+
+// UpdateAutoReply godoc
+// @Summary      Replace an automatic reply
+// @Description  Replaces the keywords, reply, cooldown and enabled state of one of the room's automatic replies. Cooldowns already running keep their length.
+// @Tags         auto-replies
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string       true  "Room ID"
+// @Param        ruleID  path      string       true  "Automatic reply ID"
+// @Param        rule    body      RuleRequest  true  "Keywords, reply, cooldown and enabled state"
+// @Success      200     {object}  autoreply.Rule
+// @Failure      400     {string}  string "Invalid room ID, rule ID, request body, keywords, reply or cooldown"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: Only owners can manage automatic replies"
+// @Failure      404     {string}  string "Room or automatic reply not found"
+// @Failure      500     {string}  string "Failed to update automatic reply"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/auto-replies/{ruleID} [put]
+func (e *Extension) UpdateAutoReply(w http.ResponseWriter, r *http.Request) {
+	room, _, ok := e.ownedRoom(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	params, ok := decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	row, err := e.db.UpdateRoomAutoReply(r.Context(), params.update(ruleID, room.ID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Automatic reply not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update automatic reply: %v", err)
+		http.Error(w, "Failed to update automatic reply", http.StatusInternalServerError)
+		return
+	}
+	e.forget(room.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ruleFromRow(row))
+}
+
+// DeleteAutoReply godoc
+// @Summary      Delete an automatic reply
+// @Description  Deletes one of the room's automatic replies.
+// @Tags         auto-replies
+// @Param        id      path      string  true  "Room ID"
+// @Param        ruleID  path      string  true  "Automatic reply ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room ID or rule ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: Only owners can manage automatic replies"
+// @Failure      404     {string}  string "Room or automatic reply not found"
+// @Failure      500     {string}  string "Failed to delete automatic reply"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/auto-replies/{ruleID} [delete]
+func (e *Extension) DeleteAutoReply(w http.ResponseWriter, r *http.Request) {
+	room, _, ok := e.ownedRoom(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := e.db.DeleteRoomAutoReply(r.Context(), database.DeleteRoomAutoReplyParams{ID: ruleID, RoomID: room.ID})
+	if err != nil {
+		log.Printf("Failed to delete automatic reply: %v", err)
+		http.Error(w, "Failed to delete automatic reply", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Automatic reply not found", http.StatusNotFound)
+		return
+	}
+	e.forget(room.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownedRoom loads the room of the request and checks that the current user
+// owns or co-owns it, writing the error response if not.
+func (e *Extension) ownedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+	userIDString, ok := r.Context().Value(middleware.ContextUserIDKey).(string)
+	userID, err := uuid.Parse(userIDString)
+	if !ok || err != nil {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return database.Room{}, uuid.Nil, false
+	}
+
+	roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid room ID", http.StatusBadRequest)
+		return database.Room{}, uuid.Nil, false
+	}
+	room, err := e.db.GetRoomByID(r.Context(), roomID)
+	if err != nil || room.Kind == service.RoomKindGroupDM {
+		http.Error(w, "Room not found", http.StatusNotFound)
+		return database.Room{}, uuid.Nil, false
+	}
+	owner, err := service.IsRoomOwner(r.Context(), e.db, room, userID)
+	if err != nil {
+		log.Printf("Failed to check room ownership: %v", err)
+		http.Error(w, "Failed to check room ownership", http.StatusInternalServerError)
+		return database.Room{}, uuid.Nil, false
+	}
+	if !owner {
+		http.Error(w, "Forbidden: Only owners can manage automatic replies", http.StatusForbidden)
+		return database.Room{}, uuid.Nil, false
+	}
+	return room, userID, true
+}
+
+// ruleParams is a validated rule request.
+type ruleParams struct {
+	Keywords        []string
+	Reply           string
+	CooldownSeconds int32
+	Enabled         bool
+}
+
+func (p ruleParams) update(ruleID, roomID uuid.UUID) database.UpdateRoomAutoReplyParams {
+	return database.UpdateRoomAutoReplyParams{
+		ID:              ruleID,
+		RoomID:          roomID,
+		Keywords:        p.Keywords,
+		Reply:           p.Reply,
+		CooldownSeconds: p.CooldownSeconds,
+		Enabled:         p.Enabled,
+	}
+}
+
+// decodeRule decodes and validates a rule request, writing the error
+// response if it is invalid. Keywords are normalized the way messages are
+// before matching, and duplicates dropped.
+func decodeRule(w http.ResponseWriter, r *http.Request) (ruleParams, bool) {
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return ruleParams{}, false
+	}
+
+	params := ruleParams{
+		Keywords:        []string{},
+		Reply:           strings.TrimSpace(req.Reply),
+		CooldownSeconds: int32(DefaultCooldown.Seconds()),
+		Enabled:         true,
+	}
+	seen := make(map[string]bool)
+	for _, keyword := range req.Keywords {
+		keyword = normalize(keyword)
+		if keyword == "" || utf8.RuneCountInString(keyword) > MaxKeywordLength {
+			http.Error(w, fmt.Sprintf("Keywords must be 1-%d characters", MaxKeywordLength), http.StatusBadRequest)
+			return ruleParams{}, false
+		}
+		if !seen[keyword] {
+			seen[keyword] = true
+			params.Keywords = append(params.Keywords, keyword)
+		}
+	}
+	if len(params.Keywords) == 0 || len(params.Keywords) > MaxKeywords {
+		http.Error(w, fmt.Sprintf("Rules need 1-%d keywords", MaxKeywords), http.StatusBadRequest)
+		return ruleParams{}, false
+	}
+	if params.Reply == "" || utf8.RuneCountInString(params.Reply) > MaxReplyLength {
+		http.Error(w, fmt.Sprintf("Replies must be 1-%d characters", MaxReplyLength), http.StatusBadRequest)
+		return ruleParams{}, false
+	}
+	if req.CooldownSeconds != nil {
+		if *req.CooldownSeconds < 0 || *req.CooldownSeconds > int32(MaxCooldown.Seconds()) {
+			http.Error(w, fmt.Sprintf("cooldown_seconds must be between 0 and %d", int32(MaxCooldown.Seconds())), http.StatusBadRequest)
+			return ruleParams{}, false
+		}
+		params.CooldownSeconds = *req.CooldownSeconds
+	}
+	if req.Enabled != nil {
+		params.Enabled = *req.Enabled
+	}
+	return params, true
+}
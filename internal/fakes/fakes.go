@@ -0,0 +1,207 @@
+// Package fakes provides record-and-assert test doubles for the external
+// providers used by the services (mail, push and object storage).
+//
+// Each fake records every call it receives and can be told to fail, so
+// integration tests can exercise whole flows and then assert on what would
+// have been sent or stored.
+package fakes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// Providers bundles one of each fake together with the service.Providers
+// value that wires them in.
+type Providers struct {
+	Mailer  *Mailer
+	Push    *PushSender
+	Storage *Storage
+}
+
+// NewProviders creates a fresh set of fakes.
+func NewProviders() *Providers {
+	return &Providers{
+		Mailer:  &Mailer{},
+		Push:    &PushSender{},
+		Storage: NewStorage(),
+	}
+}
+
+// Service returns the fakes as a service.Providers value.
+func (p *Providers) Service() service.Providers {
+	return service.Providers{
+		Mailer:  p.Mailer,
+		Push:    p.Push,
+		Storage: p.Storage,
+	}
+}
+
+// failer holds an injectable error shared by all fakes.
+type failer struct {
+	mu  sync.Mutex
+	err error
+}
+
+// FailWith makes every subsequent call return err. Pass nil to recover.
+func (f *failer) FailWith(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *failer) failure() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+// Mail is a recorded SendMail call.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is a fake service.Mailer.
+type Mailer struct {
+	failer
+	mu   sync.Mutex
+	sent []Mail
+}
+
+// SendMail records the mail, or returns the injected failure.
+func (m *Mailer) SendMail(ctx context.Context, to, subject, body string) error {
+	if err := m.failure(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Mail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// Sent returns every mail recorded so far.
+func (m *Mailer) Sent() []Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Mail(nil), m.sent...)
+}
+
+// SentTo returns the mails recorded for a single recipient.
+func (m *Mailer) SentTo(to string) []Mail {
+	var mails []Mail
+	for _, mail := range m.Sent() {
+		if mail.To == to {
+			mails = append(mails, mail)
+		}
+	}
+	return mails
+}
+
+// Push is a recorded push notification.
+type Push struct {
+	UserID       string
+	Notification service.PushNotification
+}
+
+// PushSender is a fake service.PushSender.
+type PushSender struct {
+	failer
+	mu     sync.Mutex
+	pushed []Push
+}
+
+// Push records the notification, or returns the injected failure.
+func (p *PushSender) Push(ctx context.Context, userID string, notification service.PushNotification) error {
+	if err := p.failure(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pushed = append(p.pushed, Push{UserID: userID, Notification: notification})
+	return nil
+}
+
+// Pushed returns every notification recorded so far.
+func (p *PushSender) Pushed() []Push {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Push(nil), p.pushed...)
+}
+
+// PushedTo returns the notifications recorded for a single user.
+func (p *PushSender) PushedTo(userID string) []Push {
+	var pushes []Push
+	for _, push := range p.Pushed() {
+		if push.UserID == userID {
+			pushes = append(pushes, push)
+		}
+	}
+	return pushes
+}
+
+// Object is an object held by the fake storage.
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Storage is a fake, in-memory service.Storage.
+type Storage struct {
+	failer
+	mu      sync.Mutex
+	objects map[string]Object
+	deleted []string
+}
+
+// NewStorage creates an empty fake storage.
+func NewStorage() *Storage {
+	return &Storage{objects: make(map[string]Object)}
+}
+
+// Put records the object in memory and returns a fake URL for it.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	if err := s.failure(); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = Object{Data: buf.Bytes(), ContentType: contentType}
+	return "memory://" + key, nil
+}
+
+// Delete removes the object and records the deletion.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := s.failure(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+// Get returns the stored object for key, if present.
+func (s *Storage) Get(key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	return obj, ok
+}
+
+// Deleted returns the keys deleted so far.
+func (s *Storage) Deleted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.deleted...)
+}
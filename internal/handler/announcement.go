@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// AnnouncementRequest defines the request body for setting a room's
+// announcement.
+type AnnouncementRequest struct {
+    // Content is the announcement text, up to 500 characters.
+    Content string `json:"content" example:"Maintenance tonight from 22:00 UTC."`
+    // ExpiresAt takes the announcement down at that time; omit it to keep
+    // the announcement until it is removed or replaced.
+    ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-09-04T06:00:00Z"`
+}
+
+// SetRoomAnnouncement godoc
+// @Summary      Set a room's announcement
+// @Description  Pins an announcement at the top of the room, replacing the current one, until expires_at or until it is removed. It is included in GET /rooms/{id}, and members connected to the room receive a room.announcement event with it. No event is sent when it expires; clients hide it at its expires_at. Only room owners and co-owners can perform this action.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id            path      string               true  "Room ID"
+// @Param        announcement  body      AnnouncementRequest  true  "Announcement text and expiry"
+// @Success      200           {object}  service.Announcement
+// @Failure      400           {string}  string "Invalid room ID, request body, content or expiry"
+// @Failure      401           {string}  string "User not authenticated"
+// @Failure      403           {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404           {string}  string "Room not found"
+// @Failure      500           {string}  string "Failed to set announcement"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/announcement [put]
+func (h *RoomHandler) SetRoomAnnouncement(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req AnnouncementRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    content, err := service.NormalizeAnnouncement(req.Content, req.ExpiresAt)
+    if errors.Is(err, service.ErrInvalidAnnouncement) || errors.Is(err, service.ErrAnnouncementExpiry) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    announcement, err := service.SetRoomAnnouncement(r.Context(), h.db, room.ID, userID, content, req.ExpiresAt)
+    if err != nil {
+        log.Printf("Failed to set announcement: %v", err)
+        http.Error(w, "Failed to set announcement", http.StatusInternalServerError)
+        return
+    }
+    h.hub.BroadcastAnnouncement(room.ID, userID, announcement)
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(announcement)
+}
+
+// RemoveRoomAnnouncement godoc
+// @Summary      Remove a room's announcement
+// @Description  Takes down the room's announcement. Members connected to the room receive a room.announcement event with a null announcement. Only room owners and co-owners can perform this action.
+// @Tags         rooms
+// @Param        id   path      string  true  "Room ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404  {string}  string "Room not found, or it has no announcement"
+// @Failure      500  {string}  string "Failed to remove announcement"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/announcement [delete]
+func (h *RoomHandler) RemoveRoomAnnouncement(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    removed, err := service.RemoveRoomAnnouncement(r.Context(), h.db, room.ID)
+    if err != nil {
+        log.Printf("Failed to remove announcement: %v", err)
+        http.Error(w, "Failed to remove announcement", http.StatusInternalServerError)
+        return
+    }
+    if !removed {
+        http.Error(w, "Room has no announcement", http.StatusNotFound)
+        return
+    }
+    h.hub.BroadcastAnnouncement(room.ID, userID, nil)
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"time"
 
@@ -13,11 +14,12 @@ import (
 // AuthHandler handles authentication related requests
 type AuthHandler struct {
     userService *service.UserService
+    welcome     *service.WelcomeService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(userService *service.UserService) *AuthHandler {
-    return &AuthHandler{userService: userService}
+func NewAuthHandler(userService *service.UserService, welcome *service.WelcomeService) *AuthHandler {
+    return &AuthHandler{userService: userService, welcome: welcome}
 }
 
 // RegisterRequest defines the shape of the registration request body.
@@ -37,6 +39,12 @@ type UserResponse struct {
     ID        uuid.UUID `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
     Username  string    `json:"username" example:"newuser"`
     CreatedAt time.Time `json:"created_at" example:"2025-09-03T12:00:00Z"`
+    // Version increases with every profile change; it is also sent as the ETag.
+    Version int32 `json:"version" example:"1"`
+    // IsBot marks automated accounts such as the system bot.
+    IsBot bool `json:"is_bot" example:"false"`
+    // DisplayName is the user's real name, if they set one.
+    DisplayName string `json:"display_name,omitempty" example:"Ada Lovelace"`
 }
 
 // LoginResponse defines the shape of the successful login response.
@@ -46,7 +54,7 @@ type LoginResponse struct {
 
 // RegisterUser godoc
 // @Summary      Register a new user
-// @Description  Create a new user with a username and password
+// @Description  Create a new user with a username and password. Unless disabled, the system bot sends the new user a welcome message.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -76,12 +84,13 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    response := UserResponse{
-        ID:        user.ID,
-        Username:  user.Username,
-        CreatedAt: user.CreatedAt.Time,
+    // A failed welcome message should not fail the registration.
+    if err := h.welcome.Welcome(r.Context(), user); err != nil {
+        log.Printf("Failed to welcome user %s: %v", user.ID, err)
     }
 
+    response := toUserResponse(user)
+
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusCreated)
     json.NewEncoder(w).Encode(response)
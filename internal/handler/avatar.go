@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// maxAvatarSize is the largest avatar image accepted, in bytes.
+const maxAvatarSize = 2 << 20
+
+// avatarExtensions maps the accepted avatar image types to the extension
+// they are stored with.
+var avatarExtensions = map[string]string{
+    "image/png":  ".png",
+    "image/jpeg": ".jpg",
+    "image/gif":  ".gif",
+    "image/webp": ".webp",
+}
+
+// UploadAvatar godoc
+// @Summary      Upload a room avatar
+// @Description  Sets a room's avatar from a PNG, JPEG, GIF or WebP image of at most 2 MiB, sent as the avatar field of a multipart form. The previous avatar is deleted. Only room owners and moderators can change it.
+// @Description  Members connected to the room receive a room.updated event with the new avatar URL.
+// @Tags         rooms
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id      path      string  true  "Room ID"
+// @Param        avatar  formData  file    true  "Avatar image"
+// @Success      200     {object}  RoomResponse
+// @Header       200     {string}  ETag  "Version of the updated room"
+// @Failure      400     {string}  string "Invalid room ID or missing avatar"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404     {string}  string "Room not found"
+// @Failure      413     {string}  string "Avatar must be at most 2 MiB"
+// @Failure      415     {string}  string "Avatar must be a PNG, JPEG, GIF or WebP image"
+// @Failure      500     {string}  string "Failed to upload avatar"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/avatar [post]
+func (h *RoomHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    // Leave room for the multipart framing around the image.
+    r.Body = http.MaxBytesReader(w, r.Body, maxAvatarSize+64<<10)
+    file, _, err := r.FormFile("avatar")
+    var tooLarge *http.MaxBytesError
+    if errors.As(err, &tooLarge) {
+        http.Error(w, "Avatar must be at most 2 MiB", http.StatusRequestEntityTooLarge)
+        return
+    }
+    if err != nil {
+        http.Error(w, "Missing avatar file", http.StatusBadRequest)
+        return
+    }
+    defer file.Close()
+    data, err := io.ReadAll(io.LimitReader(file, maxAvatarSize+1))
+    if err != nil {
+        http.Error(w, "Missing avatar file", http.StatusBadRequest)
+        return
+    }
+    if len(data) > maxAvatarSize {
+        http.Error(w, "Avatar must be at most 2 MiB", http.StatusRequestEntityTooLarge)
+        return
+    }
+    contentType := http.DetectContentType(data)
+    ext, ok := avatarExtensions[contentType]
+    if !ok {
+        http.Error(w, "Avatar must be a PNG, JPEG, GIF or WebP image", http.StatusUnsupportedMediaType)
+        return
+    }
+
+    // Every upload gets a new key so clients never see a cached old image.
+    key := fmt.Sprintf("rooms/%s/avatar-%s%s", room.ID, uuid.New(), ext)
+    url, err := h.storage.Put(r.Context(), key, bytes.NewReader(data), contentType)
+    if err != nil {
+        log.Printf("Failed to upload avatar: %v", err)
+        http.Error(w, "Failed to upload avatar", http.StatusInternalServerError)
+        return
+    }
+    updated, err := h.db.SetRoomAvatar(r.Context(), database.SetRoomAvatarParams{ID: room.ID, AvatarUrl: &url, AvatarKey: &key})
+    if err != nil {
+        log.Printf("Failed to upload avatar: %v", err)
+        h.deleteAvatarObject(r, &key)
+        http.Error(w, "Failed to upload avatar", http.StatusInternalServerError)
+        return
+    }
+    h.deleteAvatarObject(r, room.AvatarKey)
+    h.hub.BroadcastRoomUpdate(updated, userID)
+
+    setETag(w, updated.Version)
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toRoomResponse(updated))
+}
+
+// DeleteAvatar godoc
+// @Summary      Remove a room avatar
+// @Description  Removes a room's avatar and deletes the image. Only room owners and moderators can change it. Members connected to the room receive a room.updated event.
+// @Tags         rooms
+// @Param        id  path      string  true  "Room ID"
+// @Success      204 {string}  string "No Content"
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404 {string}  string "Room not found or has no avatar"
+// @Failure      500 {string}  string "Failed to remove avatar"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/avatar [delete]
+func (h *RoomHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+    if room.AvatarKey == nil {
+        http.Error(w, "Room has no avatar", http.StatusNotFound)
+        return
+    }
+
+    updated, err := h.db.SetRoomAvatar(r.Context(), database.SetRoomAvatarParams{ID: room.ID})
+    if err != nil {
+        log.Printf("Failed to remove avatar: %v", err)
+        http.Error(w, "Failed to remove avatar", http.StatusInternalServerError)
+        return
+    }
+    h.deleteAvatarObject(r, room.AvatarKey)
+    h.hub.BroadcastRoomUpdate(updated, userID)
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteAvatarObject deletes a replaced avatar image from storage. Failures
+// only leave an orphaned object behind, so they are logged.
+func (h *RoomHandler) deleteAvatarObject(r *http.Request, key *string) {
+    if key == nil {
+        return
+    }
+    if err := h.storage.Delete(r.Context(), *key); err != nil {
+        log.Printf("Failed to delete avatar %s: %v", *key, err)
+    }
+}
+
+// loadModeratedRoom loads the room from the URL and checks that the
+// authenticated user moderates it, writing the error response if not.
+func (h *RoomHandler) loadModeratedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if moderator, err := service.CanModerateRoom(r.Context(), h.db, room, userID); err != nil || !moderator {
+        http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
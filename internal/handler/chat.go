@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/mxhdiqaim/go-chat-app/internal/database"
 	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
 	"github.com/mxhdiqaim/go-chat-app/internal/service"
@@ -13,22 +19,35 @@ import (
 
 // ChatHandler handles the WebSocket endpoint.
 type ChatHandler struct {
-    hub *service.Hub
-    db  *database.Queries
+    hub      *service.Hub
+    db       *database.Queries
+    messages *service.MessageService
+    warm     *service.WarmCache
 }
 
-// NewChatHandler creates a new chat handler.
-func NewChatHandler(hub *service.Hub, db *database.Queries) *ChatHandler {
-    return &ChatHandler{hub: hub, db: db}
+// NewChatHandler creates a new chat handler. warm may be nil.
+func NewChatHandler(hub *service.Hub, db *database.Queries, messages *service.MessageService, warm *service.WarmCache) *ChatHandler {
+    return &ChatHandler{hub: hub, db: db, messages: messages, warm: warm}
 }
 
 // ServeWs godoc
 // @Summary      Join and connect to a chat room
-// @Description  Upgrades the HTTP connection to a WebSocket connection for a specific chat room. The user must be authenticated and a member of the room.
+// @Description  Upgrades the HTTP connection to a WebSocket connection for a specific chat room. The user must be authenticated and a member of the room. A user can be connected to a room from several devices at once, and each receives everything sent to them; they stay online until their last connection closes.
+// @Description  When last_seen_seq (or since) is given, messages missed since then are replayed before live messages. Replays are compressed when the client negotiates permessage-deflate, and clients that pass the compact_replay capability get them as one "replay" frame whose payload is {count, columns}: a map from each message field to its values, oldest message first, with null where a message leaves the field out.
+// @Description  Every frame is an envelope {type, id, payload, ts}. Acks, errors, heartbeats and presence.subscribed frames echo the id of the client frame they answer. The server sends "message", "ack", "error" and message event frames such as "poll.updated" as well as those below.
+// @Description  "message" (client): payload is the message. In end-to-end encrypted conversations clients send "encrypted" frames instead, with ciphertext as the message's content, and "message" frames are rejected with an encryption_required error.
+// @Description  "read" (client): payload {"seq": n} marks messages read; the server sends "unread" frames with a room's updated unread counts to every connection of the user.
+// @Description  "typing" (both): clients repeat {"typing": true} while the user types. Typing frames are relayed at most once every 3 seconds per user, and the room is sent {"typing": false} once the user stops, sends a message or leaves, or after 10 seconds without a typing frame.
+// @Description  "heartbeat" (both): payload {"client_time", "latency_ms"}. Heartbeats keep the connection alive like pongs do; each is answered with {client_time, server_time}, and latency_ms reports the round trip the client measured for the previous one.
+// @Description  "presence.snapshot" (server): sent on connecting, {room_id, online} lists the users connected to this room. "presence" frames then report members connecting to or leaving it, "presence.online" and "presence.offline" frames a member of the room opening their first connection to any room or closing their last, and "presence.status" frames ({user_id, status}) a member setting or clearing their custom status.
+// @Description  "presence.subscribe" (client): payload {"user_ids"} limits those presence frames to the users listed, up to 500 who share a room with the user, wherever they are. It is answered with a "presence.subscribed" frame ({user_ids, presence}) with the users subscribed to and their current presence; an empty list restores the room's presence.
 // @Tags         chat
-// @Param        roomID  path      string  true  "Room ID to connect to"
+// @Param        roomID         path      string   true   "Room ID to connect to"
+// @Param        last_seen_seq  query     integer  false  "Sequence number of the last message the client received"
+// @Param        since          query     string   false  "RFC3339 timestamp of the last message the client received"
+// @Param        capabilities   query     string   false  "Comma-separated client capabilities: compact_replay"
 // @Success      101     {string}  string  "Switching Protocols"
-// @Failure      400     {string}  string  "Invalid room ID"
+// @Failure      400     {string}  string  "Invalid room ID or replay parameters"
 // @Failure      401     {string}  string  "User not authenticated"
 // @Failure      403     {string}  string  "User is not a member of this room"
 // @Failure      500     {string}  string  "Internal server error or failed to upgrade connection"
@@ -55,14 +74,59 @@ func (h *ChatHandler) ServeWs(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{
-        RoomID: roomUUID,
-        UserID: userUUID,
-    })
-    if err != nil || !isMember {
+    room, err := h.db.GetRoomByID(r.Context(), roomUUID)
+    if errors.Is(err, pgx.ErrNoRows) {
         http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
         return
     }
+    if err != nil {
+        http.Error(w, "Internal server error", http.StatusInternalServerError)
+        return
+    }
+
+    // Members of rooms in the warm cache are let in without a query.
+    if !h.warm.IsMember(room, userUUID) {
+        isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{
+            RoomID: roomUUID,
+            UserID: userUUID,
+        })
+        if err != nil || !isMember {
+            http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+            return
+        }
+    }
+
+    // Parse the optional replay cursor before upgrading, so bad input gets a 400.
+    var lastSeenSeq int64
+    var since time.Time
+    if v := r.URL.Query().Get("last_seen_seq"); v != "" {
+        lastSeenSeq, err = strconv.ParseInt(v, 10, 64)
+        if err != nil || lastSeenSeq < 0 {
+            http.Error(w, "Invalid last_seen_seq", http.StatusBadRequest)
+            return
+        }
+    }
+    if v := r.URL.Query().Get("since"); v != "" {
+        since, err = time.Parse(time.RFC3339, v)
+        if err != nil {
+            http.Error(w, "Invalid since timestamp, expected RFC3339", http.StatusBadRequest)
+            return
+        }
+    }
+    replay := lastSeenSeq > 0 || !since.IsZero()
+
+    opts := service.ClientOptions{MaxMessageSize: h.messages.MaxMessageSize(room), Encrypted: room.EncryptedAt != nil}
+    for _, capability := range strings.Split(r.URL.Query().Get("capabilities"), ",") {
+        if strings.TrimSpace(capability) == service.CapabilityCompactReplay {
+            opts.CompactReplay = true
+        }
+    }
+    // Messages from others are translated into the user's preferred language.
+    if language, ok := h.warm.Language(userUUID); ok {
+        opts.Language = language
+    } else if user, err := h.db.GetUserByID(r.Context(), userUUID); err == nil && user.PreferredLanguage != nil {
+        opts.Language = *user.PreferredLanguage
+    }
 
     conn, err := service.Upgrader.Upgrade(w, r, nil)
     if err != nil {
@@ -71,6 +135,97 @@ func (h *ChatHandler) ServeWs(w http.ResponseWriter, r *http.Request) {
     }
 
     // Pass the roomID to the NewClient function
-    client := service.NewClient(h.hub, conn, userID, roomID)
-    client.Serve()
-}
\ No newline at end of file
+    client := service.NewClient(h.hub, conn, userID, roomID, opts)
+
+    // The client is registered before loading the backlog so nothing sent in
+    // between is lost; duplicates are filtered by sequence number.
+    var backlog []*service.Message
+    if replay {
+        backlog, err = h.messages.GetMissedMessages(r.Context(), room, userUUID, lastSeenSeq, since)
+        if err != nil {
+            log.Printf("Failed to load missed messages: %v", err)
+        }
+    }
+    client.Serve(backlog)
+}
+
+// ServeAdminWs godoc
+// @Summary      Connect to the admin channel
+// @Description  Upgrades the HTTP connection to a read-only WebSocket connection that receives moderation events from every room, such as message.reported. Only administrators can connect; frames sent on it are answered with a read_only error.
+// @Tags         chat
+// @Success      101     {string}  string  "Switching Protocols"
+// @Failure      401     {string}  string  "User not authenticated"
+// @Failure      403     {string}  string  "Forbidden: Administrators only"
+// @Security     ApiKeyAuth
+// @Router       /ws/admin [get]
+func (h *ChatHandler) ServeAdminWs(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated for WebSocket", http.StatusUnauthorized)
+        return
+    }
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return
+    }
+
+    conn, err := service.Upgrader.Upgrade(w, r, nil)
+    if err != nil {
+        log.Println(err)
+        return
+    }
+    client := service.NewClient(h.hub, conn, userID.String(), service.AdminChannel, service.ClientOptions{ReadOnly: true})
+    client.Serve(nil)
+}
+
+// GetConnections godoc
+// @Summary      List live connections
+// @Description  Lists the WebSocket connections open to this server, longest connected first, optionally only those to a room or of a user, for support triage. Connections that send heartbeat frames include the latency their client reports: the last, average, lowest and highest of their latest 20 round trips, and how far the client's clock is behind the server's. Connections to other servers are not listed. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Param        room_id  query     string  false  "Room ID, or admin for the admin channel"
+// @Param        user_id  query     string  false  "User ID"
+// @Success      200      {array}   service.Connection
+// @Failure      400      {string}  string "Invalid room ID or user ID"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: Administrators only"
+// @Security     ApiKeyAuth
+// @Router       /admin/connections [get]
+func (h *ChatHandler) GetConnections(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var filter service.ConnectionFilter
+    if v := r.URL.Query().Get("room_id"); v != "" {
+        filter.RoomID = v
+        if v != service.AdminChannel {
+            id, err := uuid.Parse(v)
+            if err != nil {
+                http.Error(w, "Invalid room ID", http.StatusBadRequest)
+                return
+            }
+            filter.RoomID = id.String()
+        }
+    }
+    if v := r.URL.Query().Get("user_id"); v != "" {
+        id, err := uuid.Parse(v)
+        if err != nil {
+            http.Error(w, "Invalid user ID", http.StatusBadRequest)
+            return
+        }
+        filter.UserID = id.String()
+    }
+
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(h.hub.Connections(filter))
+}
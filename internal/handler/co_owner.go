@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// CoOwnerRequest defines the request body for adding a co-owner.
+type CoOwnerRequest struct {
+    UserID uuid.UUID `json:"user_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+}
+
+// GetCoOwners godoc
+// @Summary      List room co-owners
+// @Description  Lists the co-owners of a room, the members with the owner role, longest-standing first. The owner the room was created by is its owner_id.
+// @Tags         rooms
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   UserResponse
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get co-owners"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/co-owners [get]
+func (h *RoomHandler) GetCoOwners(w http.ResponseWriter, r *http.Request) {
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+
+    owners, err := h.db.GetRoomMembersByRole(r.Context(), database.GetRoomMembersByRoleParams{RoomID: room.ID, Role: service.RoomRoleOwner})
+    if err != nil {
+        log.Printf("Failed to get co-owners: %v", err)
+        http.Error(w, "Failed to get co-owners", http.StatusInternalServerError)
+        return
+    }
+
+    // The owner the room was created by is its owner_id, not a co-owner.
+    coOwners := make([]database.User, 0, len(owners))
+    for _, owner := range owners {
+        if owner.ID != room.OwnerID {
+            coOwners = append(coOwners, owner)
+        }
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toUserResponses(coOwners))
+}
+
+// AddCoOwner godoc
+// @Summary      Add a room co-owner
+// @Description  Makes a member of the room a co-owner by giving them the owner role. Co-owners have the same rights as the owner, so the room stays managed if the owner leaves. Only room owners and co-owners can add co-owners. A co-owner who leaves the room or is removed from it stops being a co-owner.
+// @Tags         rooms
+// @Accept       json
+// @Param        id     path      string          true  "Room ID"
+// @Param        owner  body      CoOwnerRequest  true  "User to make a co-owner"
+// @Success      204    {string}  string "No Content"
+// @Failure      400    {string}  string "Invalid room ID, request body, or user is not a member of this room"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      403    {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404    {string}  string "Room not found"
+// @Failure      409    {string}  string "User is already an owner of this room"
+// @Failure      500    {string}  string "Failed to add co-owner"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/co-owners [post]
+func (h *RoomHandler) AddCoOwner(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req CoOwnerRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if req.UserID == room.OwnerID {
+        http.Error(w, "User is already an owner of this room", http.StatusConflict)
+        return
+    }
+
+    role, err := h.db.GetRoomMemberRole(r.Context(), database.GetRoomMemberRoleParams{RoomID: room.ID, UserID: req.UserID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "User is not a member of this room", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to add co-owner: %v", err)
+        http.Error(w, "Failed to add co-owner", http.StatusInternalServerError)
+        return
+    }
+    if role == service.RoomRoleOwner {
+        http.Error(w, "User is already an owner of this room", http.StatusConflict)
+        return
+    }
+
+    if _, err := h.db.SetRoomMemberRole(r.Context(), database.SetRoomMemberRoleParams{RoomID: room.ID, UserID: req.UserID, Role: service.RoomRoleOwner}); err != nil {
+        log.Printf("Failed to add co-owner: %v", err)
+        http.Error(w, "Failed to add co-owner", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveCoOwner godoc
+// @Summary      Remove a room co-owner
+// @Description  Revokes a user's co-ownership; they stay a member with the member role. Room owners and co-owners can remove any co-owner, and co-owners can step down themselves. The owner the room was created by cannot be removed.
+// @Tags         rooms
+// @Param        id      path      string  true  "Room ID"
+// @Param        userID  path      string  true  "Co-owner's user ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404     {string}  string "Room not found or user is not a co-owner"
+// @Failure      500     {string}  string "Failed to remove co-owner"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/co-owners/{userID} [delete]
+func (h *RoomHandler) RemoveCoOwner(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    coOwnerID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if coOwnerID == room.OwnerID {
+        http.Error(w, "Forbidden: The room's owner cannot be removed", http.StatusForbidden)
+        return
+    }
+    if coOwnerID != userID {
+        if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+            http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+            return
+        }
+    }
+
+    role, err := h.db.GetRoomMemberRole(r.Context(), database.GetRoomMemberRoleParams{RoomID: roomID, UserID: coOwnerID})
+    if errors.Is(err, pgx.ErrNoRows) || (err == nil && role != service.RoomRoleOwner) {
+        http.Error(w, "User is not a co-owner of this room", http.StatusNotFound)
+        return
+    }
+    if err == nil {
+        _, err = h.db.SetRoomMemberRole(r.Context(), database.SetRoomMemberRoleParams{RoomID: roomID, UserID: coOwnerID, Role: service.RoomRoleMember})
+    }
+    if err != nil {
+        log.Printf("Failed to remove co-owner: %v", err)
+        http.Error(w, "Failed to remove co-owner", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnedRoom loads the room from the URL, checking that the authenticated
+// user owns or co-owns it.
+func (h *RoomHandler) loadOwnedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
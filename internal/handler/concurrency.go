@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// ifMatchVersion reads the expected resource version from the If-Match
+// header, as sent back from a previous ETag. It returns nil when the header is
+// absent or "*", in which case the update is unconditional.
+func ifMatchVersion(r *http.Request) (*int32, error) {
+    v := strings.TrimSpace(r.Header.Get("If-Match"))
+    if v == "" || v == "*" {
+        return nil, nil
+    }
+    v = strings.TrimPrefix(v, "W/")
+    unquoted, err := strconv.Unquote(v)
+    if err != nil {
+        return nil, errInvalidIfMatch
+    }
+    version, err := strconv.ParseInt(unquoted, 10, 32)
+    if err != nil {
+        return nil, errInvalidIfMatch
+    }
+    expected := int32(version)
+    return &expected, nil
+}
+
+// setETag advertises the resource version for use in a later If-Match header.
+func setETag(w http.ResponseWriter, version int32) {
+    w.Header().Set("ETag", strconv.Quote(strconv.Itoa(int(version))))
+}
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// ConversationHandler handles group conversations.
+type ConversationHandler struct {
+    conversations *service.ConversationService
+    messages      *service.MessageService
+}
+
+// NewConversationHandler creates a new conversation handler.
+func NewConversationHandler(conversations *service.ConversationService, messages *service.MessageService) *ConversationHandler {
+    return &ConversationHandler{conversations: conversations, messages: messages}
+}
+
+// CreateConversationRequest defines the request body for starting a group
+// conversation.
+type CreateConversationRequest struct {
+    // UserIDs are the other participants; the creator takes part anyway.
+    UserIDs []uuid.UUID `json:"user_ids"`
+    // Name is optional; clients usually show the participants instead.
+    Name string `json:"name,omitempty" example:"Weekend plans"`
+}
+
+// AddParticipantRequest defines the request body for adding a participant to
+// a group conversation.
+type AddParticipantRequest struct {
+    UserID uuid.UUID `json:"user_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+}
+
+// CreateConversation godoc
+// @Summary      Start a group conversation
+// @Description  Starts a private conversation between the current user and up to 49 others. Group conversations work like rooms: messages go through /ws/{id} and /rooms/{id}/messages. They are never listed, searched or joined, and only participants can see them.
+// @Description  The other participants' open WebSocket connections receive a conversation.added event with the conversation.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Param        conversation  body      CreateConversationRequest  true  "Participants and optional name"
+// @Success      201           {object}  service.Conversation
+// @Failure      400           {string}  string "Invalid request body, or too few or too many participants"
+// @Failure      401           {string}  string "User not authenticated"
+// @Failure      404           {string}  string "User not found"
+// @Failure      500           {string}  string "Failed to create conversation"
+// @Security     ApiKeyAuth
+// @Router       /conversations [post]
+func (h *ConversationHandler) CreateConversation(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req CreateConversationRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    conversation, err := h.conversations.Create(r.Context(), userID, strings.TrimSpace(req.Name), req.UserIDs)
+    switch {
+    case errors.Is(err, service.ErrNoParticipants), errors.Is(err, service.ErrTooManyParticipants):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrUserNotFound):
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to create conversation: %v", err)
+        http.Error(w, "Failed to create conversation", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(conversation)
+}
+
+// GetConversations godoc
+// @Summary      List my group conversations
+// @Description  Lists the group conversations the current user takes part in, with their participants, most recently active first.
+// @Tags         conversations
+// @Produce      json
+// @Success      200  {array}   service.Conversation
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get conversations"
+// @Security     ApiKeyAuth
+// @Router       /conversations [get]
+func (h *ConversationHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    conversations, err := h.conversations.List(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get conversations: %v", err)
+        http.Error(w, "Failed to get conversations", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(conversations)
+}
+
+// AddParticipant godoc
+// @Summary      Add a participant to a group conversation
+// @Description  Adds a user to a group conversation the current user takes part in. Any participant can add people, up to 50 participants. The added user's open WebSocket connections receive a conversation.added event.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Param        id           path      string                 true  "Conversation ID"
+// @Param        participant  body      AddParticipantRequest  true  "User to add"
+// @Success      200          {object}  service.Conversation
+// @Failure      400          {string}  string "Invalid conversation ID or request body, or the conversation is full"
+// @Failure      401          {string}  string "User not authenticated"
+// @Failure      404          {string}  string "Conversation or user not found"
+// @Failure      409          {string}  string "User is already a participant"
+// @Failure      500          {string}  string "Failed to add participant"
+// @Security     ApiKeyAuth
+// @Router       /conversations/{id}/participants [post]
+func (h *ConversationHandler) AddParticipant(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadConversation(w, r)
+    if !ok {
+        return
+    }
+
+    var req AddParticipantRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    conversation, err := h.conversations.AddParticipant(r.Context(), room, userID, req.UserID)
+    switch {
+    case errors.Is(err, service.ErrTooManyParticipants):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrUserNotFound):
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrAlreadyMember):
+        http.Error(w, "User is already a participant", http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to add participant: %v", err)
+        http.Error(w, "Failed to add participant", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(conversation)
+}
+
+// RemoveParticipant godoc
+// @Summary      Remove a participant from a group conversation
+// @Description  Removes a user from a group conversation and closes their connection to it. Participants can leave by removing themselves, and the creator can remove anyone. When the creator leaves, the longest-standing participant takes over; when the last participant leaves, the conversation is deleted.
+// @Tags         conversations
+// @Param        id      path      string  true  "Conversation ID"
+// @Param        userID  path      string  true  "Participant's user ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid conversation or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: Only the conversation's creator can remove other participants"
+// @Failure      404     {string}  string "Conversation or participant not found"
+// @Failure      500     {string}  string "Failed to remove participant"
+// @Security     ApiKeyAuth
+// @Router       /conversations/{id}/participants/{userID} [delete]
+func (h *ConversationHandler) RemoveParticipant(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadConversation(w, r)
+    if !ok {
+        return
+    }
+    participantID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.conversations.RemoveParticipant(r.Context(), room, userID, participantID)
+    switch {
+    case errors.Is(err, service.ErrNotConversationCreator):
+        http.Error(w, "Forbidden: Only the conversation's creator can remove other participants", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrNotParticipant):
+        http.Error(w, "Participant not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to remove participant: %v", err)
+        http.Error(w, "Failed to remove participant", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// EnableEncryption godoc
+// @Summary      Opt a group conversation in to end-to-end encryption
+// @Description  Marks a group conversation as end-to-end encrypted. From then on its WebSocket connections may only send encrypted frames, whose payload is a message with ciphertext as its content; message frames are rejected with an encryption_required error frame so plaintext cannot be sent by mistake. Encrypted messages are stored and relayed as they are, with kind "encrypted", and are left out of push previews and translation. Any participant can opt in, and it cannot be undone; opting in again changes nothing.
+// @Description  The conversation's open WebSocket connections receive a conversation.encrypted event with the conversation.
+// @Tags         conversations
+// @Produce      json
+// @Param        id   path      string  true  "Conversation ID"
+// @Success      200  {object}  service.Conversation
+// @Failure      400  {string}  string "Invalid conversation ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Conversation not found"
+// @Failure      500  {string}  string "Failed to enable encryption"
+// @Security     ApiKeyAuth
+// @Router       /conversations/{id}/encryption [post]
+func (h *ConversationHandler) EnableEncryption(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadConversation(w, r)
+    if !ok {
+        return
+    }
+
+    conversation, err := h.conversations.EnableEncryption(r.Context(), room, userID)
+    if err != nil {
+        log.Printf("Failed to enable encryption of conversation %s: %v", room.ID, err)
+        http.Error(w, "Failed to enable encryption", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(conversation)
+}
+
+// SearchConversation godoc
+// @Summary      Search a group conversation
+// @Description  Finds the messages of a group conversation that match q, best match first, then newest, with their senders' profiles. Queries are full-text and web search style: every word must appear, "quoted phrases" must appear as written, -word must not appear, and or matches either side. Words are matched whole, without stemming. Emoji, written as emoji or as :shortcodes:, must appear as written. Direct messages to other participants are never found. Only participants can search a conversation.
+// @Tags         conversations
+// @Produce      json
+// @Param        id     path      string   true   "Conversation ID"
+// @Param        q      query     string   true   "Search query, at most 200 characters"
+// @Param        limit  query     integer  false  "Maximum number of messages (default 50, max 200)"
+// @Success      200    {array}   service.Message
+// @Failure      400    {string}  string "Invalid conversation ID, q or limit"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      404    {string}  string "Conversation not found"
+// @Failure      500    {string}  string "Failed to search conversation"
+// @Security     ApiKeyAuth
+// @Router       /conversations/{id}/search [get]
+func (h *ConversationHandler) SearchConversation(w http.ResponseWriter, r *http.Request) {
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+    room, userID, ok := h.loadConversation(w, r)
+    if !ok {
+        return
+    }
+
+    messages, err := h.messages.Search(r.Context(), room.ID, userID, r.URL.Query().Get("q"), limit)
+    if errors.Is(err, service.ErrInvalidSearchQuery) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to search conversation %s: %v", room.ID, err)
+        http.Error(w, "Failed to search conversation", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(messages)
+}
+
+// loadConversation loads the group conversation from the URL, checking that
+// the authenticated user takes part in it, and writes the error response if
+// not.
+func (h *ConversationHandler) loadConversation(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid conversation ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.conversations.Get(r.Context(), roomID, userID)
+    if errors.Is(err, service.ErrConversationNotFound) {
+        http.Error(w, "Conversation not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if err != nil {
+        log.Printf("Failed to load conversation: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// toUserResponse converts a database user into its public DTO.
+func toUserResponse(user database.User) UserResponse {
+    return UserResponse{
+        ID:          user.ID,
+        Username:    user.Username,
+        CreatedAt:   user.CreatedAt,
+        Version:     user.Version,
+        IsBot:       user.IsBot,
+        DisplayName: user.DisplayName,
+    }
+}
+
+// toUserResponses converts database users into public DTOs. It never returns
+// nil, so empty results encode as [] rather than null.
+func toUserResponses(users []database.User) []UserResponse {
+    responses := make([]UserResponse, 0, len(users))
+    for _, user := range users {
+        responses = append(responses, toUserResponse(user))
+    }
+    return responses
+}
+
+// toRoomResponse converts a database room into its public DTO.
+func toRoomResponse(room database.Room) RoomResponse {
+    response := RoomResponse{
+        ID:               room.ID,
+        Name:             room.Name,
+        OwnerID:          room.OwnerID,
+        CreatedAt:        room.CreatedAt,
+        Version:          room.Version,
+        MaxMessageSize:   room.MaxMessageSize,
+        AllowUrgent:      room.AllowUrgent,
+        ArchivedAt:       room.ArchivedAt,
+        Visibility:       room.Visibility,
+        Kind:             room.Kind,
+        StatsEnabled:     room.StatsEnabled,
+        SummariesEnabled: room.SummariesEnabled,
+        PublicReadable:   room.PublicReadable,
+        Topic:            room.Topic,
+        Description:      room.Description,
+        AvatarURL:        room.AvatarUrl,
+        RoomMentionRole:  room.RoomMentionRole,
+        LastMessageAt:    room.LastMessageAt,
+        ExpiresAt:        room.ExpiresAt,
+        Encrypted:        room.EncryptedAt != nil,
+    }
+    if room.RetentionDays != nil || room.RetentionMaxMessages != nil || room.RetentionHold {
+        response.Retention = &service.RetentionPolicy{
+            Days:        room.RetentionDays,
+            MaxMessages: room.RetentionMaxMessages,
+            Hold:        room.RetentionHold,
+        }
+    }
+    return response
+}
+
+// toRoomResponses converts database rooms into public DTOs.
+func toRoomResponses(rooms []database.Room) []RoomResponse {
+    responses := make([]RoomResponse, 0, len(rooms))
+    for _, room := range rooms {
+        responses = append(responses, toRoomResponse(room))
+    }
+    return responses
+}
+
+// toRoomListResponses converts a page of listed rooms into public DTOs.
+func toRoomListResponses(rows []database.ListRoomsRow) []RoomResponse {
+    responses := make([]RoomResponse, 0, len(rows))
+    for _, row := range rows {
+        response := toRoomResponse(row.Room)
+        response.MemberCount = &row.MemberCount
+        response.LastActivityAt = &row.LastActivityAt
+        response.Tags = row.Tags
+        responses = append(responses, response)
+    }
+    return responses
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// DirectoryRoom is an entry of the public room directory.
+type DirectoryRoom struct {
+    ID          uuid.UUID `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+    Name        string    `json:"name" example:"General"`
+    Topic       string    `json:"topic" example:"Release planning for v2"`
+    Description string    `json:"description" example:"Everything about the project that is not a bug report."`
+    AvatarURL   *string   `json:"avatar_url,omitempty"`
+    Tags        []string  `json:"tags" example:"gaming,golang"`
+    CreatedAt   time.Time `json:"created_at" example:"2025-09-03T12:00:00Z"`
+    MemberCount int64     `json:"member_count" example:"12"`
+    // MessageCount counts the messages the room holds, direct messages left
+    // out.
+    MessageCount int64 `json:"message_count" example:"1234"`
+    // LastActivityAt is when the latest message was sent, or when the room
+    // was created if it has none.
+    LastActivityAt time.Time `json:"last_activity_at" example:"2025-09-03T12:00:00Z"`
+}
+
+// GetDirectory godoc
+// @Summary      Browse the room directory
+// @Description  Pages through the public rooms that are not archived, with their tags, member and message counts and last activity, for discovering rooms to join. Rooms with the most members come first by default, or those with the most messages (message_count) or the most recent message (last_activity). Pass the X-Next-Cursor response header back as cursor, with the same sort, to fetch the next page; it is absent on the last page.
+// @Tags         rooms
+// @Produce      json
+// @Param        limit   query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor  query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Param        sort    query     string   false  "Sort order"  Enums(member_count, message_count, last_activity)
+// @Param        tag     query     string   false  "Only rooms with this tag"
+// @Success      200  {array}   DirectoryRoom
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {string}  string "Invalid query parameters"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get the room directory"
+// @Security     ApiKeyAuth
+// @Router       /directory [get]
+func (h *RoomHandler) GetDirectory(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    // Fetch one extra row to know whether there is a next page.
+    params := database.GetRoomDirectoryParams{Sort: roomSortMemberCount, MaxResults: limit + 1}
+
+    switch v := query.Get("sort"); v {
+    case "":
+    case roomSortMemberCount, roomSortMessageCount, roomSortLastActivity:
+        params.Sort = v
+    default:
+        http.Error(w, "Invalid sort, expected member_count, message_count or last_activity", http.StatusBadRequest)
+        return
+    }
+    if v := query.Get("tag"); v != "" {
+        tag, err := service.NormalizeRoomTag(v)
+        if err != nil {
+            http.Error(w, "Invalid tag", http.StatusBadRequest)
+            return
+        }
+        params.Tag = &tag
+    }
+    if v := query.Get("cursor"); v != "" {
+        cursor, err := decodeRoomCursor(v, params.Sort)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        params.CursorID = &cursor.ID
+        params.CursorTime = &cursor.Time
+        params.CursorCount = &cursor.Count
+    }
+
+    rows, err := h.db.GetRoomDirectory(r.Context(), params)
+    if err != nil {
+        log.Printf("Failed to get the room directory: %v", err)
+        http.Error(w, "Failed to get the room directory", http.StatusInternalServerError)
+        return
+    }
+
+    if len(rows) > int(limit) {
+        rows = rows[:limit]
+        last := rows[len(rows)-1]
+        cursor := roomCursor{Sort: params.Sort, Count: last.MemberCount, ID: last.ID}
+        switch params.Sort {
+        case roomSortMessageCount:
+            cursor.Count = last.MessageCount
+        case roomSortLastActivity:
+            cursor.Time = last.LastActivityAt
+        }
+        w.Header().Set(nextCursorHeader, cursor.encode())
+    }
+
+    rooms := make([]DirectoryRoom, 0, len(rows))
+    for _, row := range rows {
+        rooms = append(rooms, DirectoryRoom{
+            ID:             row.ID,
+            Name:           row.Name,
+            Topic:          row.Topic,
+            Description:    row.Description,
+            AvatarURL:      row.AvatarUrl,
+            Tags:           row.Tags,
+            CreatedAt:      row.CreatedAt,
+            MemberCount:    row.MemberCount,
+            MessageCount:   row.MessageCount,
+            LastActivityAt: row.LastActivityAt,
+        })
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(rooms)
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// FolderRequest defines the request body for creating a folder.
+type FolderRequest struct {
+    Name string `json:"name" example:"Work"`
+}
+
+// UpdateFolderRequest defines the request body for changing a folder.
+// Omitted fields are left as they are.
+type UpdateFolderRequest struct {
+    Name *string `json:"name,omitempty" example:"Work"`
+    // Position orders the user's folders, lowest first.
+    Position *int32 `json:"position,omitempty" example:"2"`
+}
+
+// FolderHandler handles users' folders and sidebar.
+type FolderHandler struct {
+    folders *service.FolderService
+}
+
+// NewFolderHandler creates a new folder handler.
+func NewFolderHandler(folders *service.FolderService) *FolderHandler {
+    return &FolderHandler{folders: folders}
+}
+
+// CreateFolder godoc
+// @Summary      Create a folder
+// @Description  Creates an empty folder for organizing the current user's rooms and conversations, placed after their other folders. Names are unique per user and 1-64 characters; a user can have up to 50 folders.
+// @Description  Every WebSocket connection of the user receives a "folders.changed" event with all of their folders whenever they change, so devices stay in sync.
+// @Tags         folders
+// @Accept       json
+// @Produce      json
+// @Param        folder  body      FolderRequest  true  "Folder name"
+// @Success      201     {object}  service.Folder
+// @Failure      400     {string}  string "Invalid request body or folder name"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      409     {string}  string "A folder with this name already exists, or the user has too many folders"
+// @Failure      500     {string}  string "Failed to create folder"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders [post]
+func (h *FolderHandler) CreateFolder(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req FolderRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    folder, err := h.folders.Create(r.Context(), userID, req.Name)
+    switch {
+    case errors.Is(err, service.ErrInvalidFolderName):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrFolderNameTaken), errors.Is(err, service.ErrTooManyFolders):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to create folder: %v", err)
+        http.Error(w, "Failed to create folder", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(folder)
+}
+
+// GetFolders godoc
+// @Summary      List folders
+// @Description  Lists the current user's folders in order, each with the IDs of the rooms and conversations in it that the user is still a member of.
+// @Tags         folders
+// @Produce      json
+// @Success      200  {array}   service.Folder
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get folders"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders [get]
+func (h *FolderHandler) GetFolders(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    folders, err := h.folders.Folders(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get folders: %v", err)
+        http.Error(w, "Failed to get folders", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(folders)
+}
+
+// UpdateFolder godoc
+// @Summary      Rename or move a folder
+// @Description  Changes the name or position of one of the current user's folders. Omitted fields are left as they are.
+// @Tags         folders
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string               true  "Folder ID"
+// @Param        folder  body      UpdateFolderRequest  true  "New name or position"
+// @Success      200     {object}  service.Folder
+// @Failure      400     {string}  string "Invalid folder ID, request body or folder name"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      404     {string}  string "Folder not found"
+// @Failure      409     {string}  string "A folder with this name already exists"
+// @Failure      500     {string}  string "Failed to update folder"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders/{id} [patch]
+func (h *FolderHandler) UpdateFolder(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid folder ID", http.StatusBadRequest)
+        return
+    }
+
+    var req UpdateFolderRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    folder, err := h.folders.Update(r.Context(), userID, folderID, req.Name, req.Position)
+    switch {
+    case errors.Is(err, service.ErrInvalidFolderName):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrFolderNotFound):
+        http.Error(w, "Folder not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrFolderNameTaken):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to update folder: %v", err)
+        http.Error(w, "Failed to update folder", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(folder)
+}
+
+// DeleteFolder godoc
+// @Summary      Delete a folder
+// @Description  Deletes one of the current user's folders. The rooms and conversations in it are not deleted or left; they are no longer in any folder.
+// @Tags         folders
+// @Param        id   path      string  true  "Folder ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid folder ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Folder not found"
+// @Failure      500  {string}  string "Failed to delete folder"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders/{id} [delete]
+func (h *FolderHandler) DeleteFolder(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid folder ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.folders.Delete(r.Context(), userID, folderID)
+    if errors.Is(err, service.ErrFolderNotFound) {
+        http.Error(w, "Folder not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to delete folder: %v", err)
+        http.Error(w, "Failed to delete folder", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// AddFolderRoom godoc
+// @Summary      Put a room in a folder
+// @Description  Puts a room or conversation the current user is a member of in one of their folders. A room is in at most one folder, so it is taken out of the folder it was in. Leaving the room takes it out of the folder.
+// @Tags         folders
+// @Param        id      path      string  true  "Folder ID"
+// @Param        roomID  path      string  true  "Room ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid folder or room ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404     {string}  string "Folder not found"
+// @Failure      500     {string}  string "Failed to add room to folder"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders/{id}/rooms/{roomID} [put]
+func (h *FolderHandler) AddFolderRoom(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid folder ID", http.StatusBadRequest)
+        return
+    }
+    roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.folders.AddRoom(r.Context(), userID, folderID, roomID)
+    switch {
+    case errors.Is(err, service.ErrNotRoomMember):
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrFolderNotFound):
+        http.Error(w, "Folder not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to add room to folder: %v", err)
+        http.Error(w, "Failed to add room to folder", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFolderRoom godoc
+// @Summary      Take a room out of a folder
+// @Description  Takes a room or conversation out of one of the current user's folders, leaving it in no folder.
+// @Tags         folders
+// @Param        id      path      string  true  "Folder ID"
+// @Param        roomID  path      string  true  "Room ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid folder or room ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      404     {string}  string "Folder not found or room not in it"
+// @Failure      500     {string}  string "Failed to remove room from folder"
+// @Security     ApiKeyAuth
+// @Router       /users/me/folders/{id}/rooms/{roomID} [delete]
+func (h *FolderHandler) RemoveFolderRoom(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid folder ID", http.StatusBadRequest)
+        return
+    }
+    roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.folders.RemoveRoom(r.Context(), userID, folderID, roomID)
+    if errors.Is(err, service.ErrFolderNotFound) {
+        http.Error(w, "Folder not found or room not in it", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to remove room from folder: %v", err)
+        http.Error(w, "Failed to remove room from folder", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSidebar godoc
+// @Summary      Get the sidebar
+// @Description  Returns every room and conversation the current user is a member of, grouped into their folders in order, with the rest under rooms. Within each group the most recently active come first, with the user's unread and mention counts.
+// @Tags         folders
+// @Produce      json
+// @Success      200  {object}  service.Sidebar
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get sidebar"
+// @Security     ApiKeyAuth
+// @Router       /users/me/sidebar [get]
+func (h *FolderHandler) GetSidebar(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    sidebar, err := h.folders.Sidebar(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get sidebar: %v", err)
+        http.Error(w, "Failed to get sidebar", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(sidebar)
+}
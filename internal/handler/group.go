@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// GroupHandler handles the user group endpoints of rooms.
+type GroupHandler struct {
+    db     *database.Queries
+    groups *service.GroupService
+}
+
+// NewGroupHandler creates a new group handler.
+func NewGroupHandler(db *database.Queries, groups *service.GroupService) *GroupHandler {
+    return &GroupHandler{db: db, groups: groups}
+}
+
+// CreateGroupRequest defines the request body for creating a group.
+type CreateGroupRequest struct {
+    Name      string      `json:"name" example:"oncall"`
+    MemberIDs []uuid.UUID `json:"member_ids"`
+}
+
+// UpdateGroupRequest defines the request body for updating a group. Omitted
+// fields are left unchanged; member_ids replaces the whole member list.
+type UpdateGroupRequest struct {
+    Name      *string     `json:"name,omitempty" example:"oncall"`
+    MemberIDs []uuid.UUID `json:"member_ids,omitempty"`
+}
+
+// CreateGroup godoc
+// @Summary      Create a user group
+// @Description  Creates a named group of room members that can be mentioned together, e.g. @oncall. Only room owners and co-owners can manage groups.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string              true  "Room ID"
+// @Param        group  body      CreateGroupRequest  true  "Group name and members"
+// @Success      201    {object}  service.Group
+// @Failure      400    {string}  string "Invalid room ID, name or members"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      403    {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404    {string}  string "Room not found"
+// @Failure      409    {string}  string "Group name already taken"
+// @Failure      500    {string}  string "Failed to create group"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/groups [post]
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req CreateGroupRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    group, err := h.groups.CreateGroup(r.Context(), room.ID, userID, req.Name, req.MemberIDs)
+    if !writeGroupError(w, err, "Failed to create group") {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(group)
+}
+
+// GetGroups godoc
+// @Summary      List a room's user groups
+// @Description  Retrieves the groups of a room with their members. Only members of the room can view them.
+// @Tags         groups
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   service.Group
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "User is not a member of this room"
+// @Failure      500 {string}  string "Failed to get groups"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/groups [get]
+func (h *GroupHandler) GetGroups(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{
+        RoomID: roomID,
+        UserID: userID,
+    })
+    if err != nil || !isMember {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    groups, err := h.groups.ListGroups(r.Context(), roomID)
+    if err != nil {
+        log.Printf("Failed to get groups: %v", err)
+        http.Error(w, "Failed to get groups", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(groups)
+}
+
+// UpdateGroup godoc
+// @Summary      Update a user group
+// @Description  Renames a group and/or replaces its members. Only room owners and co-owners can manage groups.
+// @Tags         groups
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "Room ID"
+// @Param        groupID  path      string              true  "Group ID"
+// @Param        group    body      UpdateGroupRequest  true  "New name and/or members"
+// @Success      200      {object}  service.Group
+// @Failure      400      {string}  string "Invalid ID, name or members"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404      {string}  string "Room or group not found"
+// @Failure      409      {string}  string "Group name already taken"
+// @Failure      500      {string}  string "Failed to update group"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/groups/{groupID} [put]
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+    group, ok := h.loadGroup(w, r)
+    if !ok {
+        return
+    }
+
+    var req UpdateGroupRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    updated, err := h.groups.UpdateGroup(r.Context(), group, req.Name, req.MemberIDs)
+    if !writeGroupError(w, err, "Failed to update group") {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a user group
+// @Description  Deletes a group. Only room owners and co-owners can manage groups.
+// @Tags         groups
+// @Param        id       path  string  true  "Room ID"
+// @Param        groupID  path  string  true  "Group ID"
+// @Success      204
+// @Failure      400  {string}  string "Invalid ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404  {string}  string "Room or group not found"
+// @Failure      500  {string}  string "Failed to delete group"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/groups/{groupID} [delete]
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+    group, ok := h.loadGroup(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.groups.DeleteGroup(r.Context(), group.ID); err != nil {
+        log.Printf("Failed to delete group: %v", err)
+        http.Error(w, "Failed to delete group", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnedRoom loads the room from the URL and checks that the authenticated
+// user owns it, writing the error response if not.
+func (h *GroupHandler) loadOwnedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
+
+// loadGroup loads the group from the URL, checking that it belongs to a room
+// the authenticated user owns.
+func (h *GroupHandler) loadGroup(w http.ResponseWriter, r *http.Request) (database.RoomGroup, bool) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return database.RoomGroup{}, false
+    }
+
+    groupID, err := uuid.Parse(chi.URLParam(r, "groupID"))
+    if err != nil {
+        http.Error(w, "Invalid group ID", http.StatusBadRequest)
+        return database.RoomGroup{}, false
+    }
+
+    group, err := h.groups.GetGroup(r.Context(), groupID)
+    if err != nil || group.RoomID != room.ID {
+        http.Error(w, "Group not found", http.StatusNotFound)
+        return database.RoomGroup{}, false
+    }
+    return group, true
+}
+
+// writeGroupError writes the response for a group service error and reports
+// whether the request may continue.
+func writeGroupError(w http.ResponseWriter, err error, message string) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, service.ErrInvalidGroupName), errors.Is(err, service.ErrInvalidGroupMember):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+    case errors.Is(err, service.ErrGroupNameTaken):
+        http.Error(w, err.Error(), http.StatusConflict)
+    default:
+        log.Printf("%s: %v", message, err)
+        http.Error(w, message, http.StatusInternalServerError)
+    }
+    return false
+}
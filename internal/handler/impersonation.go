@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// maxImpersonationReason bounds the reason given for an impersonation.
+const maxImpersonationReason = 500
+
+// ImpersonationHandler handles support access and admin impersonation.
+type ImpersonationHandler struct {
+    db            *database.Queries
+    impersonation *service.ImpersonationService
+}
+
+// NewImpersonationHandler creates a new impersonation handler.
+func NewImpersonationHandler(db *database.Queries, impersonation *service.ImpersonationService) *ImpersonationHandler {
+    return &ImpersonationHandler{db: db, impersonation: impersonation}
+}
+
+// SupportAccessRequest defines the request body for granting support access.
+type SupportAccessRequest struct {
+    // ExpiresInHours is how long administrators may impersonate you; 24 when
+    // omitted, at most 72.
+    ExpiresInHours int `json:"expires_in_hours,omitempty" example:"24"`
+}
+
+// ImpersonateRequest defines the request body for impersonating a user.
+type ImpersonateRequest struct {
+    // Reason is shown to the user and kept in the audit log.
+    Reason string `json:"reason" example:"Ticket #4521: messages not arriving"`
+    // ExpiresInMinutes is how long the token is valid; 15 when omitted, at
+    // most 60, and never past the end of the user's support access.
+    ExpiresInMinutes int `json:"expires_in_minutes,omitempty" example:"15"`
+}
+
+// ImpersonateResponse defines the response to starting an impersonation.
+type ImpersonateResponse struct {
+    Token         string                 `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+    Impersonation *service.Impersonation `json:"impersonation"`
+}
+
+// Guard ends impersonation sessions that are no longer allowed and marks the
+// requests of those that are, so that audit log entries name the
+// administrator. Requests made with ordinary tokens pass straight through.
+func (h *ImpersonationHandler) Guard(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        impersonatorID, ok := r.Context().Value(middleware.ContextImpersonatorIDKey).(string)
+        if !ok {
+            next.ServeHTTP(w, r)
+            return
+        }
+        adminID, err := uuid.Parse(impersonatorID)
+        userID, ok := authUserID(r)
+        if err != nil || !ok {
+            http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+            return
+        }
+
+        err = h.impersonation.Check(r.Context(), adminID, userID)
+        if errors.Is(err, service.ErrImpersonationDisabled) || errors.Is(err, service.ErrNoSupportAccess) {
+            http.Error(w, "Impersonation session has ended", http.StatusUnauthorized)
+            return
+        }
+        if err != nil {
+            log.Printf("Failed to check impersonation: %v", err)
+            http.Error(w, "Failed to check impersonation", http.StatusInternalServerError)
+            return
+        }
+        next.ServeHTTP(w, r.WithContext(service.WithImpersonator(r.Context(), adminID)))
+    })
+}
+
+// GrantSupportAccess godoc
+// @Summary      Grant support access
+// @Description  Lets administrators impersonate the current user for support debugging until the grant expires, replacing any earlier grant. Each impersonation is announced to the user with a direct message from the system bot, an activity feed item and an account.impersonated event, and is recorded in the audit log.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        access  body      SupportAccessRequest  false  "How long to grant access for"
+// @Success      200     {object}  service.SupportAccess
+// @Failure      400     {string}  string "Invalid request body or expiry"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: Not allowed while impersonating a user"
+// @Failure      500     {string}  string "Failed to grant support access"
+// @Security     ApiKeyAuth
+// @Router       /users/me/support-access [put]
+func (h *ImpersonationHandler) GrantSupportAccess(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req SupportAccessRequest
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+    }
+    ttl := service.DefaultSupportAccessTTL
+    if req.ExpiresInHours != 0 {
+        ttl = time.Duration(req.ExpiresInHours) * time.Hour
+    }
+    if ttl <= 0 || ttl > service.MaxSupportAccessTTL {
+        http.Error(w, "expires_in_hours must be between 1 and 72", http.StatusBadRequest)
+        return
+    }
+
+    access, err := h.impersonation.Grant(r.Context(), userID, ttl)
+    if errors.Is(err, service.ErrImpersonating) {
+        http.Error(w, "Forbidden: Not allowed while impersonating a user", http.StatusForbidden)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to grant support access: %v", err)
+        http.Error(w, "Failed to grant support access", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(access)
+}
+
+// GetSupportAccess godoc
+// @Summary      Get my support access
+// @Description  Returns the current user's support access grant, if it has not expired.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  service.SupportAccess
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "No support access granted"
+// @Failure      500  {string}  string "Failed to get support access"
+// @Security     ApiKeyAuth
+// @Router       /users/me/support-access [get]
+func (h *ImpersonationHandler) GetSupportAccess(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    access, err := h.impersonation.Access(r.Context(), userID)
+    if errors.Is(err, service.ErrNoSupportAccess) {
+        http.Error(w, "No support access granted", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get support access: %v", err)
+        http.Error(w, "Failed to get support access", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(access)
+}
+
+// RevokeSupportAccess godoc
+// @Summary      Withdraw support access
+// @Description  Withdraws the current user's support access. Impersonation tokens issued under it stop working immediately.
+// @Tags         users
+// @Success      204  {string}  string "No Content"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Not allowed while impersonating a user"
+// @Failure      404  {string}  string "No support access granted"
+// @Failure      500  {string}  string "Failed to withdraw support access"
+// @Security     ApiKeyAuth
+// @Router       /users/me/support-access [delete]
+func (h *ImpersonationHandler) RevokeSupportAccess(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    err := h.impersonation.Revoke(r.Context(), userID)
+    switch {
+    case errors.Is(err, service.ErrImpersonating):
+        http.Error(w, "Forbidden: Not allowed while impersonating a user", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrNoSupportAccess):
+        http.Error(w, "No support access granted", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to withdraw support access: %v", err)
+        http.Error(w, "Failed to withdraw support access", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// ImpersonateUser godoc
+// @Summary      Impersonate a user
+// @Description  Issues a short-lived token that lets an administrator act as a user who has granted support access, for support debugging. The token is valid for expires_in_minutes (15 by default, at most 60) and never past the end of the user's support access; it stops working as soon as the user withdraws access or impersonation is disabled.
+// @Description  The user gets a direct message from the system bot, an activity feed item and an account.impersonated event naming the administrator and reason. The session is recorded in the audit log, and so is every audited action taken with the token, marked with the administrator's ID. Only administrators can impersonate, and administrators cannot be impersonated. Unavailable unless IMPERSONATION_ENABLED is true.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id           path      string              true  "User ID"
+// @Param        impersonate  body      ImpersonateRequest  true  "Reason and duration"
+// @Success      201          {object}  ImpersonateResponse
+// @Failure      400          {string}  string "Invalid user ID, request body, reason or expiry, or the user is an administrator"
+// @Failure      401          {string}  string "User not authenticated"
+// @Failure      403          {string}  string "Forbidden: Administrators only, or the user has not granted support access"
+// @Failure      404          {string}  string "User not found or impersonation disabled"
+// @Failure      500          {string}  string "Failed to impersonate user"
+// @Security     ApiKeyAuth
+// @Router       /users/{id}/impersonate [post]
+func (h *ImpersonationHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+    adminID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    userID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req ImpersonateRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    req.Reason = strings.TrimSpace(req.Reason)
+    if req.Reason == "" || len(req.Reason) > maxImpersonationReason {
+        http.Error(w, "reason is required and must be at most 500 characters", http.StatusBadRequest)
+        return
+    }
+    ttl := service.DefaultImpersonationTTL
+    if req.ExpiresInMinutes != 0 {
+        ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+    }
+    if ttl <= 0 || ttl > service.MaxImpersonationTTL {
+        http.Error(w, "expires_in_minutes must be between 1 and 60", http.StatusBadRequest)
+        return
+    }
+
+    admin, err := h.db.GetUserByID(r.Context(), adminID)
+    if err != nil || !admin.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return
+    }
+
+    session, err := h.impersonation.Start(r.Context(), admin, userID, req.Reason, ttl)
+    switch {
+    case errors.Is(err, service.ErrImpersonationDisabled):
+        http.Error(w, "Impersonation is disabled", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrUserNotFound):
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrCannotImpersonate):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrNoSupportAccess):
+        http.Error(w, "Forbidden: User has not granted support access", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrImpersonating):
+        http.Error(w, "Forbidden: Not allowed while impersonating a user", http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to impersonate user: %v", err)
+        http.Error(w, "Failed to impersonate user", http.StatusInternalServerError)
+        return
+    }
+
+    token, err := middleware.GenerateImpersonationJWT(userID.String(), adminID.String(), session.ExpiresAt)
+    if err != nil {
+        log.Printf("Failed to impersonate user: %v", err)
+        http.Error(w, "Failed to impersonate user", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(ImpersonateResponse{Token: token, Impersonation: session})
+}
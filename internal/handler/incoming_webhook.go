@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// IncomingWebhookRequest defines the request body for creating an incoming
+// webhook.
+type IncomingWebhookRequest struct {
+    // Name is shown as the author of the webhook's messages, up to 64
+    // characters.
+    Name string `json:"name" example:"CI"`
+}
+
+// IncomingWebhookMessage defines the body posted to an incoming webhook.
+type IncomingWebhookMessage struct {
+    Content string `json:"content" example:"Build #42 passed"`
+    // Text is accepted instead of content, as Slack-compatible tools send it.
+    Text string `json:"text,omitempty" example:"Build #42 passed"`
+}
+
+// CreateIncomingWebhook godoc
+// @Summary      Create an incoming webhook
+// @Description  Adds an incoming webhook to a room, so that an outside service such as a CI server or an alerting system can post to it. The response's url, which contains the webhook's secret token, is only returned here; anyone holding it can post to the room with POST /webhooks/{token}. A room can have up to 10. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Room ID"
+// @Param        webhook  body      IncomingWebhookRequest  true  "Integration name"
+// @Success      201      {object}  service.IncomingWebhook
+// @Failure      400      {string}  string "Invalid room ID, request body or name"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404      {string}  string "Room not found"
+// @Failure      409      {string}  string "rooms can have at most 10 incoming webhooks"
+// @Failure      500      {string}  string "Failed to create incoming webhook"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/incoming-webhooks [post]
+func (h *WebhookHandler) CreateIncomingWebhook(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req IncomingWebhookRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    webhook, err := h.incoming.Create(r.Context(), room.ID, userID, req.Name)
+    switch {
+    case errors.Is(err, service.ErrInvalidIntegrationName):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrTooManyIncomingWebhooks):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to create incoming webhook: %v", err)
+        http.Error(w, "Failed to create incoming webhook", http.StatusInternalServerError)
+        return
+    }
+    webhook.URL = incomingWebhookURL(r, webhook.Token)
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(webhook)
+}
+
+// GetIncomingWebhooks godoc
+// @Summary      List a room's incoming webhooks
+// @Description  Lists the incoming webhooks of a room, oldest first, with when each was last used but without their tokens. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   service.IncomingWebhook
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get incoming webhooks"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/incoming-webhooks [get]
+func (h *WebhookHandler) GetIncomingWebhooks(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    webhooks, err := h.incoming.List(r.Context(), room.ID)
+    if err != nil {
+        log.Printf("Failed to get incoming webhooks: %v", err)
+        http.Error(w, "Failed to get incoming webhooks", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(webhooks)
+}
+
+// DeleteIncomingWebhook godoc
+// @Summary      Delete an incoming webhook
+// @Description  Removes an incoming webhook; its URL stops working right away. Messages it already posted stay. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Param        id         path  string  true  "Room ID"
+// @Param        webhookID  path  string  true  "Incoming webhook ID"
+// @Success      204
+// @Failure      400  {string}  string "Invalid ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404  {string}  string "Room or incoming webhook not found"
+// @Failure      500  {string}  string "Failed to delete incoming webhook"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/incoming-webhooks/{webhookID} [delete]
+func (h *WebhookHandler) DeleteIncomingWebhook(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    webhookID, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+    if err != nil {
+        http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.incoming.Delete(r.Context(), room.ID, webhookID)
+    if errors.Is(err, service.ErrIncomingWebhookNotFound) {
+        http.Error(w, "Incoming webhook not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to delete incoming webhook: %v", err)
+        http.Error(w, "Failed to delete incoming webhook", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// PostIncomingWebhook godoc
+// @Summary      Post to a room through an incoming webhook
+// @Description  Posts a message to the room of the incoming webhook the token belongs to. No other authentication is needed: the token is the secret. The message is sent by the system bot with metadata {"integration": {"webhook_id", "name"}}, so clients can show the webhook's name as its author, and reaches the room like any other message. Requests are rate-limited per client address.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        token    path      string                  true  "Incoming webhook token"
+// @Param        message  body      IncomingWebhookMessage  true  "Message content"
+// @Success      201      {object}  service.Message
+// @Failure      400      {string}  string "Invalid request body, or empty or oversized content"
+// @Failure      404      {string}  string "Incoming webhook not found"
+// @Failure      409      {string}  string "room is archived"
+// @Failure      429      {string}  string "Too many requests"
+// @Failure      500      {string}  string "Failed to post message"
+// @Router       /webhooks/{token} [post]
+func (h *WebhookHandler) PostIncomingWebhook(w http.ResponseWriter, r *http.Request) {
+    var req IncomingWebhookMessage
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    content := req.Content
+    if content == "" {
+        content = req.Text
+    }
+
+    message, err := h.incoming.Post(r.Context(), chi.URLParam(r, "token"), content)
+    switch {
+    case errors.Is(err, service.ErrIncomingWebhookNotFound):
+        http.Error(w, "Incoming webhook not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrInvalidWebhookMessage):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrRoomArchived):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to post message through incoming webhook: %v", err)
+        http.Error(w, "Failed to post message", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(message)
+}
+
+// incomingWebhookURL returns the URL an incoming webhook's token is posted
+// to, on the host the request was made to.
+func incomingWebhookURL(r *http.Request, token string) string {
+    scheme := "http"
+    if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+        scheme = "https"
+    }
+    return scheme + "://" + r.Host + "/v1/webhooks/" + token
+}
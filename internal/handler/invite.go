@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// InviteHandler handles room invitations.
+type InviteHandler struct {
+    db       *database.Queries
+    invites  *service.InviteService
+    webhooks *service.WebhookService
+    // linkURL is the invite link template, with {code} standing for the
+    // invite code; empty when invite codes have no links.
+    linkURL string
+}
+
+// NewInviteHandler creates a new invite handler. Invite codes get links made
+// from linkURL, when it is not empty, by replacing {code} with the code.
+func NewInviteHandler(db *database.Queries, invites *service.InviteService, webhooks *service.WebhookService, linkURL string) *InviteHandler {
+    return &InviteHandler{db: db, invites: invites, webhooks: webhooks, linkURL: linkURL}
+}
+
+// InviteRequest defines the request body for inviting a user to a room.
+type InviteRequest struct {
+    UserID uuid.UUID `json:"user_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+    // ExpiresInHours is how long the invitation stays valid; 168 (7 days)
+    // when omitted, at most 720 (30 days).
+    ExpiresInHours int `json:"expires_in_hours,omitempty" example:"48"`
+}
+
+// CreateInvite godoc
+// @Summary      Invite a user to a room
+// @Description  Invites a user to join a room until the invitation expires. Members need the role the room's invite permission requires: by default any member can invite to a public room, and only owners and co-owners to a private one. Inviting the same user again renews the invitation.
+// @Description  The invited user's open WebSocket connections receive a room.invited event with the invitation.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string         true  "Room ID"
+// @Param        invite  body      InviteRequest  true  "User to invite"
+// @Success      201     {object}  service.Invite
+// @Failure      400     {string}  string "Invalid room ID, request body or expiry, or the user is inviting themselves"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: User is not a member of this room or cannot invite to it"
+// @Failure      404     {string}  string "Room or invited user not found"
+// @Failure      409     {string}  string "User is already a member of this room"
+// @Failure      410     {string}  string "Room is archived"
+// @Failure      500     {string}  string "Failed to invite user"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/invites [post]
+func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    var req InviteRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    ttl := service.DefaultInviteTTL
+    if req.ExpiresInHours != 0 {
+        ttl = time.Duration(req.ExpiresInHours) * time.Hour
+    }
+    if ttl <= 0 || ttl > service.MaxInviteTTL {
+        http.Error(w, "expires_in_hours must be between 1 and 720", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    // Participants are added to group conversations directly.
+    if err != nil || room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if room.ArchivedAt != nil {
+        http.Error(w, "Room is archived", http.StatusGone)
+        return
+    }
+    if !h.checkCanInvite(w, r, room, userID, "Failed to invite user") {
+        return
+    }
+
+    invite, err := h.invites.Invite(r.Context(), room, userID, req.UserID, ttl)
+    switch {
+    case errors.Is(err, service.ErrInvalidInvitee):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrInviteeNotFound):
+        http.Error(w, "Invited user not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrAlreadyMember):
+        http.Error(w, "User is already a member of this room", http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to invite user: %v", err)
+        http.Error(w, "Failed to invite user", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(invite)
+}
+
+// GetInvites godoc
+// @Summary      List my invitations
+// @Description  Lists the current user's invitations that have not expired, newest first.
+// @Tags         invites
+// @Produce      json
+// @Success      200  {array}   service.Invite
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get invitations"
+// @Security     ApiKeyAuth
+// @Router       /users/me/invites [get]
+func (h *InviteHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    invites, err := h.invites.Pending(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get invitations: %v", err)
+        http.Error(w, "Failed to get invitations", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(invites)
+}
+
+// AcceptInvite godoc
+// @Summary      Accept an invitation
+// @Description  Joins the room the current user was invited to, private rooms included, and removes the invitation.
+// @Tags         invites
+// @Produce      json
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  service.Invite
+// @Failure      400  {string}  string "Invalid invitation ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are banned from this room"
+// @Failure      404  {string}  string "Invitation not found"
+// @Failure      410  {string}  string "Invitation has expired or room is archived"
+// @Failure      500  {string}  string "Failed to accept invitation"
+// @Security     ApiKeyAuth
+// @Router       /invites/{id}/accept [post]
+func (h *InviteHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    inviteID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid invitation ID", http.StatusBadRequest)
+        return
+    }
+
+    invite, err := h.invites.Accept(r.Context(), inviteID, userID)
+    switch {
+    case errors.Is(err, service.ErrInviteNotFound):
+        http.Error(w, "Invitation not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrInviteExpired):
+        http.Error(w, "Invitation has expired", http.StatusGone)
+        return
+    case errors.Is(err, service.ErrRoomArchived):
+        http.Error(w, "Room is archived", http.StatusGone)
+        return
+    case errors.Is(err, service.ErrBanned):
+        http.Error(w, "Forbidden: You are banned from this room", http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to accept invitation: %v", err)
+        http.Error(w, "Failed to accept invitation", http.StatusInternalServerError)
+        return
+    }
+    if roomID, err := uuid.Parse(invite.RoomID); err == nil {
+        h.webhooks.Dispatch(roomID, service.WebhookEventJoin, service.MembershipChange{UserID: invite.UserID, ActorID: invite.InvitedBy})
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(invite)
+}
+
+// DeclineInvite godoc
+// @Summary      Decline an invitation
+// @Description  Removes an invitation addressed to the current user without joining the room.
+// @Tags         invites
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid invitation ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Invitation not found"
+// @Failure      500  {string}  string "Failed to decline invitation"
+// @Security     ApiKeyAuth
+// @Router       /invites/{id}/decline [post]
+func (h *InviteHandler) DeclineInvite(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    inviteID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid invitation ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.invites.Decline(r.Context(), inviteID, userID)
+    if errors.Is(err, service.ErrInviteNotFound) {
+        http.Error(w, "Invitation not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to decline invitation: %v", err)
+        http.Error(w, "Failed to decline invitation", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// checkCanInvite reports whether the user's role in the room allows inviting
+// users to it. If not, it writes the error response, logging failures with
+// failure as the message.
+func (h *InviteHandler) checkCanInvite(w http.ResponseWriter, r *http.Request, room database.Room, userID uuid.UUID, failure string) bool {
+    role, err := service.RoomRole(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("%s: %v", failure, err)
+        http.Error(w, failure, http.StatusInternalServerError)
+        return false
+    }
+    if role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return false
+    }
+    permissions, err := service.LoadRoomPermissions(r.Context(), h.db, room)
+    if err != nil {
+        log.Printf("%s: %v", failure, err)
+        http.Error(w, failure, http.StatusInternalServerError)
+        return false
+    }
+    if !service.HasRoomRole(role, permissions.Invite) {
+        http.Error(w, "Forbidden: Your role in this room cannot invite users", http.StatusForbidden)
+        return false
+    }
+    return true
+}
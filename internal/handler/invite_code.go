@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// InviteCodeRequest defines the request body for creating an invite code.
+type InviteCodeRequest struct {
+    // ExpiresInHours is how long the code can be used; 24 when omitted, at
+    // most 168 (7 days).
+    ExpiresInHours int `json:"expires_in_hours,omitempty" example:"24"`
+    // MaxUses is how many users can join with the code, from 1 to 1000;
+    // unlimited when omitted.
+    MaxUses *int32 `json:"max_uses,omitempty" example:"10"`
+}
+
+// JoinByCodeRequest defines the request body for joining a room with an
+// invite code.
+type JoinByCodeRequest struct {
+    Code string `json:"code" example:"k7pq3xw9mh"`
+}
+
+// JoinByCodeResponse names the room joined with an invite code.
+type JoinByCodeResponse struct {
+    RoomID   string `json:"room_id"`
+    RoomName string `json:"room_name"`
+    // Joined is false when the user was already a member of the room.
+    Joined bool `json:"joined"`
+}
+
+// CreateInviteCode godoc
+// @Summary      Create an invite code
+// @Description  Creates a short-lived code that lets anyone holding it join the room with POST /rooms/join-by-code, private rooms included, until it expires or has been used max_uses times. The response includes a shareable link when the server sets INVITE_LINK_URL. Members need the role the room's invite permission requires: by default any member can create codes for a public room, and only owners and co-owners for a private one.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string             true   "Room ID"
+// @Param        code  body      InviteCodeRequest  false  "Expiry and use limit"
+// @Success      201   {object}  service.InviteCode
+// @Failure      400   {string}  string "Invalid room ID, request body, expiry or use limit"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: User is not a member of this room or cannot invite to it"
+// @Failure      404   {string}  string "Room not found"
+// @Failure      410   {string}  string "Room is archived"
+// @Failure      500   {string}  string "Failed to create invite code"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/invite-codes [post]
+func (h *InviteHandler) CreateInviteCode(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    var req InviteCodeRequest
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+    }
+    ttl := service.DefaultInviteCodeTTL
+    if req.ExpiresInHours != 0 {
+        ttl = time.Duration(req.ExpiresInHours) * time.Hour
+    }
+    if ttl <= 0 || ttl > service.MaxInviteCodeTTL {
+        http.Error(w, "expires_in_hours must be between 1 and 168", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    // Participants are added to group conversations directly.
+    if err != nil || room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if room.ArchivedAt != nil {
+        http.Error(w, "Room is archived", http.StatusGone)
+        return
+    }
+    if !h.checkCanInvite(w, r, room, userID, "Failed to create invite code") {
+        return
+    }
+
+    code, err := h.invites.CreateCode(r.Context(), room, userID, ttl, req.MaxUses)
+    if errors.Is(err, service.ErrInvalidInviteCode) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to create invite code: %v", err)
+        http.Error(w, "Failed to create invite code", http.StatusInternalServerError)
+        return
+    }
+    code.Link = h.inviteLink(code.Code)
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(code)
+}
+
+// GetInviteCodes godoc
+// @Summary      List a room's invite codes
+// @Description  Lists the room's invite codes that can still be used, newest first. Owners and co-owners see every code; other users see the codes they created.
+// @Tags         invites
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {array}   service.InviteCode
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Room not found"
+// @Failure      500  {string}  string "Failed to get invite codes"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/invite-codes [get]
+func (h *InviteHandler) GetInviteCodes(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil || room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("Failed to get invite codes: %v", err)
+        http.Error(w, "Failed to get invite codes", http.StatusInternalServerError)
+        return
+    }
+    var createdBy *uuid.UUID
+    if !owner {
+        createdBy = &userID
+    }
+
+    codes, err := h.invites.Codes(r.Context(), roomID, createdBy)
+    if err != nil {
+        log.Printf("Failed to get invite codes: %v", err)
+        http.Error(w, "Failed to get invite codes", http.StatusInternalServerError)
+        return
+    }
+    for i := range codes {
+        codes[i].Link = h.inviteLink(codes[i].Code)
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(codes)
+}
+
+// RevokeInviteCode godoc
+// @Summary      Revoke an invite code
+// @Description  Deletes an invite code so nobody else can join with it. Users who already joined stay members. Owners and co-owners can revoke any code of the room; other users only the codes they created.
+// @Tags         invites
+// @Param        id    path      string  true  "Room ID"
+// @Param        code  path      string  true  "Invite code"
+// @Success      204   {string}  string "No Content"
+// @Failure      400   {string}  string "Invalid room ID"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: You did not create this invite code"
+// @Failure      404   {string}  string "Room or invite code not found"
+// @Failure      500   {string}  string "Failed to revoke invite code"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/invite-codes/{code} [delete]
+func (h *InviteHandler) RevokeInviteCode(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    code, err := h.invites.Code(r.Context(), roomID, chi.URLParam(r, "code"))
+    if errors.Is(err, service.ErrInviteCodeNotFound) {
+        http.Error(w, "Invite code not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to revoke invite code: %v", err)
+        http.Error(w, "Failed to revoke invite code", http.StatusInternalServerError)
+        return
+    }
+    if code.CreatedBy != userID.String() {
+        if owner, _ := service.IsRoomOwner(r.Context(), h.db, room, userID); !owner {
+            http.Error(w, "Forbidden: You did not create this invite code", http.StatusForbidden)
+            return
+        }
+    }
+
+    err = h.invites.RevokeCode(r.Context(), roomID, code.Code)
+    if errors.Is(err, service.ErrInviteCodeNotFound) {
+        http.Error(w, "Invite code not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to revoke invite code: %v", err)
+        http.Error(w, "Failed to revoke invite code", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// JoinByCode godoc
+// @Summary      Join a room with an invite code
+// @Description  Joins the room an invite code is for, private rooms included, without being on its member list or invited. Codes are case-insensitive. Joining a room the user is already a member of succeeds without using the code. Attempts are rate limited to slow down guessing.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Param        code  body      JoinByCodeRequest  true  "Invite code"
+// @Success      200   {object}  JoinByCodeResponse
+// @Failure      400   {string}  string "Invalid request body"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: You are banned from this room"
+// @Failure      404   {string}  string "Invite code not found, expired or used up"
+// @Failure      410   {string}  string "Room is archived"
+// @Failure      429   {string}  string "Too many requests"
+// @Failure      500   {string}  string "Failed to join room"
+// @Security     ApiKeyAuth
+// @Router       /rooms/join-by-code [post]
+func (h *InviteHandler) JoinByCode(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req JoinByCodeRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Code) == "" {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    room, joined, err := h.invites.JoinByCode(r.Context(), req.Code, userID)
+    switch {
+    case errors.Is(err, service.ErrInviteCodeNotFound):
+        http.Error(w, "Invite code not found, expired or used up", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrRoomArchived):
+        http.Error(w, "Room is archived", http.StatusGone)
+        return
+    case errors.Is(err, service.ErrBanned):
+        http.Error(w, "Forbidden: You are banned from this room", http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to join room: %v", err)
+        http.Error(w, "Failed to join room", http.StatusInternalServerError)
+        return
+    }
+    if joined {
+        h.webhooks.Dispatch(room.ID, service.WebhookEventJoin, service.MembershipChange{UserID: userID.String()})
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(JoinByCodeResponse{RoomID: room.ID.String(), RoomName: room.Name, Joined: joined})
+}
+
+// inviteLink returns the shareable link for an invite code, or "" when no
+// link URL is configured.
+func (h *InviteHandler) inviteLink(code string) string {
+    if h.linkURL == "" {
+        return ""
+    }
+    return strings.ReplaceAll(h.linkURL, "{code}", code)
+}
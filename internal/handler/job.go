@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// JobHandler lets administrators inspect and manage the background job
+// queue.
+type JobHandler struct {
+    db   *database.Queries
+    jobs *service.JobQueue
+}
+
+// NewJobHandler creates a new job handler.
+func NewJobHandler(db *database.Queries, jobs *service.JobQueue) *JobHandler {
+    return &JobHandler{db: db, jobs: jobs}
+}
+
+// GetJobs godoc
+// @Summary      List background jobs
+// @Description  Lists background jobs, such as webhook deliveries, newest first, optionally only those with a status or type. Failed jobs keep the error of their last attempt in last_error. Succeeded and cancelled jobs are deleted after 7 days. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Param        status  query     string   false  "pending, running, succeeded, failed or cancelled"
+// @Param        type    query     string   false  "Job type, e.g. webhook.delivery"
+// @Param        limit   query     integer  false  "Maximum number of jobs (default 50, max 200)"
+// @Success      200     {array}   service.Job
+// @Failure      400     {string}  string "Invalid status or limit"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: Administrators only"
+// @Failure      500     {string}  string "Failed to list jobs"
+// @Security     ApiKeyAuth
+// @Router       /admin/jobs [get]
+func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    jobs, err := h.jobs.List(r.Context(), r.URL.Query().Get("status"), r.URL.Query().Get("type"), limit)
+    if errors.Is(err, service.ErrInvalidJobStatus) {
+        http.Error(w, "Invalid status", http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to list jobs: %v", err)
+        http.Error(w, "Failed to list jobs", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(jobs)
+}
+
+// GetJobQueueDepth godoc
+// @Summary      Get the job queue depth
+// @Description  Counts background jobs by type and status, with when the longest waiting job of each was due, so a backlog of pending jobs or a pile of failed ones stands out. The same counts are exported on /metrics. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   service.JobQueueDepth
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      500  {string}  string "Failed to get job queue depth"
+// @Security     ApiKeyAuth
+// @Router       /admin/jobs/depth [get]
+func (h *JobHandler) GetJobQueueDepth(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+
+    depth, err := h.jobs.Depth(r.Context())
+    if err != nil {
+        log.Printf("Failed to get job queue depth: %v", err)
+        http.Error(w, "Failed to get job queue depth", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(depth)
+}
+
+// RetryJob godoc
+// @Summary      Retry a background job
+// @Description  Queues a failed or cancelled job to run again right away, with all its attempts available. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  service.Job
+// @Failure      400  {string}  string "Invalid job ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      404  {string}  string "Job not found"
+// @Failure      409  {string}  string "Only failed or cancelled jobs can be retried"
+// @Failure      500  {string}  string "Failed to retry job"
+// @Security     ApiKeyAuth
+// @Router       /admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+    h.changeJob(w, r, h.jobs.Retry, "Failed to retry job")
+}
+
+// CancelJob godoc
+// @Summary      Cancel a background job
+// @Description  Stops a pending job from running; it can be retried later. Jobs already running cannot be cancelled. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  service.Job
+// @Failure      400  {string}  string "Invalid job ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      404  {string}  string "Job not found"
+// @Failure      409  {string}  string "Only pending jobs can be cancelled"
+// @Failure      500  {string}  string "Failed to cancel job"
+// @Security     ApiKeyAuth
+// @Router       /admin/jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+    h.changeJob(w, r, h.jobs.Cancel, "Failed to cancel job")
+}
+
+// changeJob applies change to the job of the request and writes the job, or
+// the error response.
+func (h *JobHandler) changeJob(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, jobID uuid.UUID) (*service.Job, error), failure string) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+    jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid job ID", http.StatusBadRequest)
+        return
+    }
+
+    job, err := change(r.Context(), jobID)
+    switch {
+    case errors.Is(err, service.ErrJobNotFound):
+        http.Error(w, "Job not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrJobNotRetryable):
+        http.Error(w, "Only failed or cancelled jobs can be retried", http.StatusConflict)
+        return
+    case errors.Is(err, service.ErrJobNotCancellable):
+        http.Error(w, "Only pending jobs can be cancelled", http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("%s: %v", failure, err)
+        http.Error(w, failure, http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(job)
+}
+
+// requireAdmin checks that the current user is an administrator, writing the
+// error response if not.
+func (h *JobHandler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return false
+    }
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return false
+    }
+    return true
+}
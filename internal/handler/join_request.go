@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// requestToJoin handles JoinRoom for a private room: members are left alone,
+// owners, co-owners and invited users join straight away, and everyone else
+// files a join request for an owner to approve.
+func (h *RoomHandler) requestToJoin(w http.ResponseWriter, r *http.Request, room database.Room, userID uuid.UUID) {
+    isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{RoomID: room.ID, UserID: userID})
+    if err != nil {
+        http.Error(w, "Failed to join room", http.StatusInternalServerError)
+        return
+    }
+    if isMember {
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+
+    if owner, _ := service.IsRoomOwner(r.Context(), h.db, room, userID); owner {
+        if err := h.db.AddRoomMember(r.Context(), database.AddRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+            http.Error(w, "Failed to join room", http.StatusInternalServerError)
+            return
+        }
+        h.webhooks.Dispatch(room.ID, service.WebhookEventJoin, service.MembershipChange{UserID: userID.String()})
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+
+    joined, err := h.invites.AcceptForRoom(r.Context(), room.ID, userID)
+    if err != nil {
+        log.Printf("Failed to accept invitation: %v", err)
+        http.Error(w, "Failed to join room", http.StatusInternalServerError)
+        return
+    }
+    if joined {
+        h.webhooks.Dispatch(room.ID, service.WebhookEventJoin, service.MembershipChange{UserID: userID.String()})
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+
+    if err := h.db.CreateJoinRequest(r.Context(), database.CreateJoinRequestParams{RoomID: room.ID, UserID: userID}); err != nil {
+        log.Printf("Failed to request to join room: %v", err)
+        http.Error(w, "Failed to join room", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusAccepted)
+}
+
+// GetJoinRequests godoc
+// @Summary      List pending join requests
+// @Description  Lists the users waiting to join a private room, oldest request first. Only room owners and co-owners can see them.
+// @Tags         rooms
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   UserResponse
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get join requests"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/join-requests [get]
+func (h *RoomHandler) GetJoinRequests(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    users, err := h.db.GetJoinRequests(r.Context(), room.ID)
+    if err != nil {
+        log.Printf("Failed to get join requests: %v", err)
+        http.Error(w, "Failed to get join requests", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toUserResponses(users))
+}
+
+// ApproveJoinRequest godoc
+// @Summary      Approve a join request
+// @Description  Adds the user who asked to join the room as a member. Only room owners and co-owners can approve requests.
+// @Tags         rooms
+// @Param        id      path      string  true  "Room ID"
+// @Param        userID  path      string  true  "Requesting user's ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404     {string}  string "Room not found or no pending request from this user"
+// @Failure      500     {string}  string "Failed to approve join request"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/join-requests/{userID}/approve [post]
+func (h *RoomHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+    requesterID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    ctx := r.Context()
+    tx, err := h.pool.Begin(ctx)
+    if err != nil {
+        log.Printf("Failed to approve join request: %v", err)
+        http.Error(w, "Failed to approve join request", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback(ctx)
+
+    deleted, err := h.db.WithTx(tx).DeleteJoinRequest(ctx, database.DeleteJoinRequestParams{RoomID: room.ID, UserID: requesterID})
+    if err != nil {
+        log.Printf("Failed to approve join request: %v", err)
+        http.Error(w, "Failed to approve join request", http.StatusInternalServerError)
+        return
+    }
+    if deleted == 0 {
+        http.Error(w, "No pending join request from this user", http.StatusNotFound)
+        return
+    }
+    // Someone who became a member in the meantime just loses the request.
+    reason, err := addMemberSavepoint(ctx, tx, h.db, room.ID, requesterID)
+    if err != nil {
+        log.Printf("Failed to approve join request: %v", err)
+        http.Error(w, "Failed to approve join request", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(ctx); err != nil {
+        log.Printf("Failed to approve join request: %v", err)
+        http.Error(w, "Failed to approve join request", http.StatusInternalServerError)
+        return
+    }
+    if reason == "" {
+        h.webhooks.Dispatch(room.ID, service.WebhookEventJoin, service.MembershipChange{UserID: requesterID.String(), ActorID: userID.String()})
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteJoinRequest godoc
+// @Summary      Decline or withdraw a join request
+// @Description  Removes a pending join request without adding the user. Room owners and co-owners can decline any request, and users can withdraw their own.
+// @Tags         rooms
+// @Param        id      path      string  true  "Room ID"
+// @Param        userID  path      string  true  "Requesting user's ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404     {string}  string "Room not found or no pending request from this user"
+// @Failure      500     {string}  string "Failed to delete join request"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/join-requests/{userID} [delete]
+func (h *RoomHandler) DeleteJoinRequest(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    requesterID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if requesterID != userID {
+        if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+            http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+            return
+        }
+    }
+
+    deleted, err := h.db.DeleteJoinRequest(r.Context(), database.DeleteJoinRequestParams{RoomID: roomID, UserID: requesterID})
+    if err != nil {
+        log.Printf("Failed to delete join request: %v", err)
+        http.Error(w, "Failed to delete join request", http.StatusInternalServerError)
+        return
+    }
+    if deleted == 0 {
+        http.Error(w, "No pending join request from this user", http.StatusNotFound)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
+)
+
+// JWKSHandler publishes the keys the API's tokens can be verified with.
+type JWKSHandler struct {
+    keys middleware.JSONWebKeySet
+}
+
+// NewJWKSHandler creates a new JWKS handler publishing keys.
+func NewJWKSHandler(keys middleware.JSONWebKeySet) *JWKSHandler {
+    return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS serves the public keys of RS256 tokens as a JSON Web Key Set, so
+// that other services can verify the tokens the API issues without sharing
+// a secret. Each key is named by its kid, which tokens carry in their
+// header. Like /metrics it is infrastructure rather than API, so it lives at
+// the well-known path /.well-known/jwks.json, outside the versioned routes
+// and the API documentation.
+//
+// Verifiers may cache the set for 5 minutes, and should fetch it again on
+// meeting a kid they do not know. The set is empty when tokens are signed
+// with HS256 only.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", "public, max-age=300")
+    json.NewEncoder(w).Encode(h.keys)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// LegalHoldRequest defines the request body for placing a legal hold. Exactly
+// one of UserID and RoomID must be set.
+type LegalHoldRequest struct {
+    UserID *uuid.UUID `json:"user_id,omitempty" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+    RoomID *uuid.UUID `json:"room_id,omitempty"`
+    // Reason is up to 500 characters, such as a case reference.
+    Reason string `json:"reason" example:"Litigation case 2025-114"`
+}
+
+// LegalHoldHandler lets administrators place and release legal holds.
+type LegalHoldHandler struct {
+    db    *database.Queries
+    holds *service.LegalHoldService
+}
+
+// NewLegalHoldHandler creates a new legal hold handler.
+func NewLegalHoldHandler(db *database.Queries, holds *service.LegalHoldService) *LegalHoldHandler {
+    return &LegalHoldHandler{db: db, holds: holds}
+}
+
+// GetLegalHolds godoc
+// @Summary      List legal holds
+// @Description  Lists every legal hold in place, newest first. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   service.LegalHold
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      500  {string}  string "Failed to list legal holds"
+// @Security     ApiKeyAuth
+// @Router       /admin/legal-holds [get]
+func (h *LegalHoldHandler) GetLegalHolds(w http.ResponseWriter, r *http.Request) {
+    if _, ok := h.requireAdmin(w, r); !ok {
+        return
+    }
+
+    holds, err := h.holds.Holds(r.Context())
+    if err != nil {
+        log.Printf("Failed to list legal holds: %v", err)
+        http.Error(w, "Failed to list legal holds", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(holds)
+}
+
+// PlaceLegalHold godoc
+// @Summary      Place a legal hold
+// @Description  Preserves a user's or a room's messages until the hold is released: a message is held when its sender or its room is. Retention purges, bulk deletion and purges skip held messages, and accounts and rooms with held messages cannot be deleted, by their owners or when rooms expire. Placing the hold is recorded in the audit log. Administrators only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        hold  body      LegalHoldRequest  true  "User or room, and reason"
+// @Success      201   {object}  service.LegalHold
+// @Failure      400   {string}  string "Invalid request body, subject or reason"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: Administrators only"
+// @Failure      404   {string}  string "User or room not found"
+// @Failure      409   {string}  string "Already under legal hold"
+// @Failure      500   {string}  string "Failed to place legal hold"
+// @Security     ApiKeyAuth
+// @Router       /admin/legal-holds [post]
+func (h *LegalHoldHandler) PlaceLegalHold(w http.ResponseWriter, r *http.Request) {
+    userID, ok := h.requireAdmin(w, r)
+    if !ok {
+        return
+    }
+
+    var req LegalHoldRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    hold, err := h.holds.Place(r.Context(), userID, req.UserID, req.RoomID, req.Reason)
+    switch {
+    case errors.Is(err, service.ErrInvalidLegalHold):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrLegalHoldSubjectNotFound):
+        http.Error(w, "User or room not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrLegalHoldExists):
+        http.Error(w, "Already under legal hold", http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to place legal hold: %v", err)
+        http.Error(w, "Failed to place legal hold", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(hold)
+}
+
+// ReleaseLegalHold godoc
+// @Summary      Release a legal hold
+// @Description  Lifts a legal hold, so its messages are subject to retention and deletion again. Releasing it is recorded in the audit log. Administrators only.
+// @Tags         admin
+// @Param        id   path      string  true  "Legal hold ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid legal hold ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      404  {string}  string "Legal hold not found"
+// @Failure      500  {string}  string "Failed to release legal hold"
+// @Security     ApiKeyAuth
+// @Router       /admin/legal-holds/{id} [delete]
+func (h *LegalHoldHandler) ReleaseLegalHold(w http.ResponseWriter, r *http.Request) {
+    userID, ok := h.requireAdmin(w, r)
+    if !ok {
+        return
+    }
+    holdID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid legal hold ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.holds.Release(r.Context(), userID, holdID)
+    if errors.Is(err, service.ErrLegalHoldNotFound) {
+        http.Error(w, "Legal hold not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to release legal hold: %v", err)
+        http.Error(w, "Failed to release legal hold", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// requireAdmin checks that the authenticated user is an administrator,
+// writing the error response if not, and returns their ID.
+func (h *LegalHoldHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return uuid.Nil, false
+    }
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return uuid.Nil, false
+    }
+    return userID, true
+}
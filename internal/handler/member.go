@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// memberVersionHeader carries the version of a room's members that a member
+// list reflects.
+const memberVersionHeader = "X-Member-Version"
+
+// MemberResponse describes a member of a room.
+type MemberResponse struct {
+    UserID uuid.UUID `json:"user_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+    // Username and DisplayName are shown according to the server's display
+    // name policy; either may be absent.
+    Username    string `json:"username,omitempty" example:"newuser"`
+    DisplayName string `json:"display_name,omitempty" example:"Ada Lovelace"`
+    IsBot       bool   `json:"is_bot" example:"false"`
+    // Role is owner, moderator or member.
+    Role     string    `json:"role" example:"member"`
+    JoinedAt time.Time `json:"joined_at" example:"2025-09-03T12:00:00Z"`
+    // Online reports whether the member is connected to the room right now.
+    Online bool `json:"online" example:"true"`
+    // Status is the member's custom status; absent when they have none.
+    Status *service.Status `json:"status,omitempty"`
+}
+
+// GetMembers godoc
+// @Summary      List room members
+// @Description  Retrieves a page of a room's members in the order they joined, with their role, whether they are connected to the room right now and their custom status. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.
+// @Description  Only members and owners of the room can list its members. Keep the X-Member-Version header of the first page to catch up later with GET /rooms/{id}/members/changes.
+// @Tags         rooms
+// @Produce      json
+// @Param        id      path      string   true   "Room ID"
+// @Param        limit   query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor  query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Success      200     {array}   MemberResponse
+// @Header       200     {string}  X-Next-Cursor  "Cursor for the next page"
+// @Header       200     {integer} X-Member-Version  "Version of the room's members when the page was read"
+// @Failure      400     {string}  string "Invalid room ID, limit or cursor"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404     {string}  string "Room not found"
+// @Failure      500     {string}  string "Failed to get members"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/members [get]
+func (h *RoomHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    // Fetch one extra row to know whether there is a next page.
+    params := database.GetRoomMembersPageParams{RoomID: roomID, MaxResults: limit + 1}
+    if v := r.URL.Query().Get("cursor"); v != "" {
+        cursor, err := decodeCursor(v)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        params.CursorJoinedAt = &cursor.CreatedAt
+        params.CursorUserID = &cursor.ID
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    role, err := service.RoomRole(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("Failed to get members: %v", err)
+        http.Error(w, "Failed to get members", http.StatusInternalServerError)
+        return
+    }
+    if role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    rows, err := h.db.GetRoomMembersPage(r.Context(), params)
+    if err != nil {
+        log.Printf("Failed to get members: %v", err)
+        http.Error(w, "Failed to get members", http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set(memberVersionHeader, strconv.FormatInt(room.MemberVersion, 10))
+    if len(rows) > int(limit) {
+        rows = rows[:limit]
+        last := rows[len(rows)-1]
+        w.Header().Set(nextCursorHeader, pageCursor{CreatedAt: last.JoinedAt, ID: last.ID}.encode())
+    }
+
+    userIDs := make([]uuid.UUID, len(rows))
+    for i, row := range rows {
+        userIDs[i] = row.ID
+    }
+    statuses, err := service.UserStatuses(r.Context(), h.db, userIDs)
+    if err != nil {
+        log.Printf("Failed to get members: %v", err)
+        http.Error(w, "Failed to get members", http.StatusInternalServerError)
+        return
+    }
+
+    online := h.hub.OnlineUsers(roomID)
+    members := make([]MemberResponse, 0, len(rows))
+    for _, row := range rows {
+        member := MemberResponse{
+            UserID:   row.ID,
+            IsBot:    row.IsBot,
+            Role:     row.Role,
+            JoinedAt: row.JoinedAt,
+            Online:   online[row.ID.String()],
+            Status:   statuses[row.ID],
+        }
+        member.Username, member.DisplayName = h.hub.DisplayNames().Names(row.Username, row.DisplayName)
+        // The owner the room was created by is an owner whatever their row says.
+        if row.ID == room.OwnerID {
+            member.Role = service.RoomRoleOwner
+        }
+        members = append(members, member)
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(members)
+}
+
+// GetMemberChanges godoc
+// @Summary      Catch up with changes to room members
+// @Description  Returns the changes to a room's members after since_version, oldest first, so clients holding a member list need not fetch it again. Each change adds, removes or changes the role of a member. Apply them in order and keep the returned version.
+// @Description  When reset is set, the changes are no longer all known, or are too many, and the client must fetch the full list from GET /rooms/{id}/members. Connected members also receive each change as a members.changed event; a gap between versions means one was missed. Only members and owners of the room can see its changes.
+// @Tags         rooms
+// @Produce      json
+// @Param        id             path      string   true  "Room ID"
+// @Param        since_version  query     integer  true  "Member version the client holds"
+// @Success      200            {object}  service.MemberDelta
+// @Failure      400            {string}  string "Invalid room ID or since_version"
+// @Failure      401            {string}  string "User not authenticated"
+// @Failure      403            {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404            {string}  string "Room not found"
+// @Failure      500            {string}  string "Failed to get member changes"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/members/changes [get]
+func (h *RoomHandler) GetMemberChanges(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    since, err := strconv.ParseInt(r.URL.Query().Get("since_version"), 10, 64)
+    if err != nil || since < 0 {
+        http.Error(w, "Invalid since_version", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    role, err := service.RoomRole(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("Failed to get member changes: %v", err)
+        http.Error(w, "Failed to get member changes", http.StatusInternalServerError)
+        return
+    }
+    if role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    delta, err := service.MemberChangesSince(r.Context(), h.db, room, since)
+    if err != nil {
+        log.Printf("Failed to get member changes: %v", err)
+        http.Error(w, "Failed to get member changes", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(delta)
+}
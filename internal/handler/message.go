@@ -0,0 +1,445 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// MessageHandler handles message history endpoints.
+type MessageHandler struct {
+    db          *database.Queries
+    messages    *service.MessageService
+    annotations *service.AnnotationService
+    revisions   *service.RevisionService
+}
+
+// NewMessageHandler creates a new message handler.
+func NewMessageHandler(db *database.Queries, messages *service.MessageService, annotations *service.AnnotationService, revisions *service.RevisionService) *MessageHandler {
+    return &MessageHandler{db: db, messages: messages, annotations: annotations, revisions: revisions}
+}
+
+// EditMessageRequest defines the request body for editing a message.
+type EditMessageRequest struct {
+    Content string `json:"content" example:"Corrected message text"`
+}
+
+// AnnotationRequest defines the request body for annotating a message.
+type AnnotationRequest struct {
+    Kind string         `json:"kind" example:"ticket"`
+    Data map[string]any `json:"data"`
+}
+
+// GetRoomMessages godoc
+// @Summary      Get the latest messages in a room
+// @Description  Returns the most recent messages in a room, oldest first, with each sender's username and avatar included. Pass the seq of the oldest message as before_seq to page further back. Responses are gzip or deflate compressed when the client accepts it.
+// @Tags         messages
+// @Produce      json
+// @Param        id          path      string   true   "Room ID"
+// @Param        limit       query     integer  false  "Number of messages (default 50, max 200)"
+// @Param        before_seq  query     integer  false  "Only return messages older than this sequence number"
+// @Success      200  {array}   service.Message
+// @Failure      400  {string}  string "Invalid room ID or query parameters"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "User is not a member of this room"
+// @Failure      500  {string}  string "Failed to get messages"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/messages [get]
+func (h *MessageHandler) GetRoomMessages(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    var beforeSeq int64
+    if v := r.URL.Query().Get("before_seq"); v != "" {
+        beforeSeq, err = strconv.ParseInt(v, 10, 64)
+        if err != nil || beforeSeq < 1 {
+            http.Error(w, "Invalid before_seq", http.StatusBadRequest)
+            return
+        }
+    }
+
+    isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{
+        RoomID: roomID,
+        UserID: userID,
+    })
+    if err != nil || !isMember {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    messages, err := h.messages.GetLatestMessages(r.Context(), roomID, userID, limit, beforeSeq)
+    if err != nil {
+        log.Printf("Failed to get messages: %v", err)
+        http.Error(w, "Failed to get messages", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(messages)
+}
+
+// StarMessage godoc
+// @Summary      Star a message
+// @Description  Adds a message to the current user's starred list. Starring an already starred message has no effect.
+// @Tags         messages
+// @Param        id  path  string  true  "Message ID"
+// @Success      204
+// @Failure      400  {string}  string "Invalid message ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      500  {string}  string "Failed to star message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/star [post]
+func (h *MessageHandler) StarMessage(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.messages.StarMessage(r.Context(), userID, messageID)
+    if errors.Is(err, service.ErrMessageNotFound) {
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to star message: %v", err)
+        http.Error(w, "Failed to star message", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// EditMessage godoc
+// @Summary      Edit a message
+// @Description  Replaces the content of one of the user's own text messages. The previous content is kept in the message's history, and the edited message is broadcast as a message.edited event.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "Message ID"
+// @Param        message  body      EditMessageRequest  true  "New content"
+// @Success      200  {object}  service.Message
+// @Failure      400  {string}  string "Invalid message ID or content"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Only the sender can edit a message"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      500  {string}  string "Failed to edit message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id} [patch]
+func (h *MessageHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    var req EditMessageRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    message, err := h.revisions.Edit(r.Context(), userID, messageID, req.Content)
+    switch {
+    case errors.Is(err, service.ErrInvalidEdit):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrNotMessageSender):
+        http.Error(w, "Forbidden: Only the sender can edit a message", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to edit message: %v", err)
+        http.Error(w, "Failed to edit message", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(message)
+}
+
+// GetMessageHistory godoc
+// @Summary      Get a message's edit history
+// @Description  Returns the previous versions of a message, oldest first. Anyone who can see the message can view its history; administrators can view the history of any message.
+// @Tags         messages
+// @Produce      json
+// @Param        id  path  string  true  "Message ID"
+// @Success      200  {array}   service.Revision
+// @Failure      400  {string}  string "Invalid message ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      500  {string}  string "Failed to get message history"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/history [get]
+func (h *MessageHandler) GetMessageHistory(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    revisions, err := h.revisions.History(r.Context(), userID, messageID)
+    if errors.Is(err, service.ErrMessageNotFound) {
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get message history: %v", err)
+        http.Error(w, "Failed to get message history", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(revisions)
+}
+
+// AnnotateMessage godoc
+// @Summary      Annotate a message
+// @Description  Attaches structured data, such as a deployment marker, ticket link or sentiment score, to an existing message without changing its content. Intended for bots and integrations.
+// @Description  The annotation is broadcast to the message's audience as a message.annotated event and returned with the message in history.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id          path      string             true  "Message ID"
+// @Param        annotation  body      AnnotationRequest  true  "Annotation kind and data"
+// @Success      201  {object}  service.Annotation
+// @Failure      400  {string}  string "Invalid message ID or annotation"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      500  {string}  string "Failed to annotate message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/annotations [post]
+func (h *MessageHandler) AnnotateMessage(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    var req AnnotationRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    annotation, err := h.annotations.Annotate(r.Context(), userID, messageID, req.Kind, req.Data)
+    switch {
+    case errors.Is(err, service.ErrInvalidAnnotation):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to annotate message: %v", err)
+        http.Error(w, "Failed to annotate message", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(annotation)
+}
+
+// UnstarMessage godoc
+// @Summary      Unstar a message
+// @Description  Removes a message from the current user's starred list.
+// @Tags         messages
+// @Param        id  path  string  true  "Message ID"
+// @Success      204
+// @Failure      400  {string}  string "Invalid message ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to unstar message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/star [delete]
+func (h *MessageHandler) UnstarMessage(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.messages.UnstarMessage(r.Context(), userID, messageID); err != nil {
+        log.Printf("Failed to unstar message: %v", err)
+        http.Error(w, "Failed to unstar message", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStarredMessages godoc
+// @Summary      List starred messages
+// @Description  Retrieves the current user's starred messages across all their rooms, most recently starred first. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.
+// @Tags         messages
+// @Produce      json
+// @Param        limit   query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor  query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Success      200  {array}   service.StarredMessage
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {string}  string "Invalid query parameters"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get starred messages"
+// @Security     ApiKeyAuth
+// @Router       /users/me/starred [get]
+func (h *MessageHandler) GetStarredMessages(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    var (
+        cursorStarredAt *time.Time
+        cursorID        *uuid.UUID
+    )
+    if v := r.URL.Query().Get("cursor"); v != "" {
+        cursor, err := decodeCursor(v)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        cursorStarredAt = &cursor.CreatedAt
+        cursorID = &cursor.ID
+    }
+
+    // Fetch one extra message to know whether there is a next page.
+    starred, err := h.messages.GetStarredMessages(r.Context(), userID, limit+1, cursorStarredAt, cursorID)
+    if err != nil {
+        log.Printf("Failed to get starred messages: %v", err)
+        http.Error(w, "Failed to get starred messages", http.StatusInternalServerError)
+        return
+    }
+
+    if len(starred) > int(limit) {
+        starred = starred[:limit]
+        last := starred[len(starred)-1]
+        id, _ := uuid.Parse(last.ID)
+        w.Header().Set(nextCursorHeader, pageCursor{CreatedAt: last.StarredAt, ID: id}.encode())
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(starred)
+}
+
+// GetFeed godoc
+// @Summary      Get the activity feed
+// @Description  Retrieves the current user's activity across all their rooms, newest first: mentions of them or their groups and replies to their messages. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.
+// @Tags         messages
+// @Produce      json
+// @Param        limit   query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor  query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Success      200  {array}   service.FeedItem
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {string}  string "Invalid query parameters"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get feed"
+// @Security     ApiKeyAuth
+// @Router       /users/me/feed [get]
+func (h *MessageHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    var (
+        cursorCreatedAt *time.Time
+        cursorID        *uuid.UUID
+    )
+    if v := r.URL.Query().Get("cursor"); v != "" {
+        cursor, err := decodeCursor(v)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        cursorCreatedAt = &cursor.CreatedAt
+        cursorID = &cursor.ID
+    }
+
+    // Fetch one extra item to know whether there is a next page.
+    items, err := h.messages.GetFeed(r.Context(), userID, limit+1, cursorCreatedAt, cursorID)
+    if err != nil {
+        log.Printf("Failed to get feed: %v", err)
+        http.Error(w, "Failed to get feed", http.StatusInternalServerError)
+        return
+    }
+
+    if len(items) > int(limit) {
+        items = items[:limit]
+        last := items[len(items)-1]
+        id, _ := uuid.Parse(last.ID)
+        w.Header().Set(nextCursorHeader, pageCursor{CreatedAt: last.CreatedAt, ID: id}.encode())
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(items)
+}
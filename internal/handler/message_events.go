@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// MessageEventHandler serves the message event log kept when messages are
+// stored as events.
+type MessageEventHandler struct {
+    db    *database.Queries
+    store *service.MessageStore
+}
+
+// NewMessageEventHandler creates a new message event handler.
+func NewMessageEventHandler(db *database.Queries, store *service.MessageStore) *MessageEventHandler {
+    return &MessageEventHandler{db: db, store: store}
+}
+
+// GetMessageEvents godoc
+// @Summary      Get a message's event history
+// @Description  Lists everything that happened to a message, oldest first: its creation, edits, annotations and deletion, each with who did it and when. Deleted messages keep their history. Only room owners, moderators and administrators can see it, and only administrators that of direct messages. Unavailable unless MESSAGE_STORAGE is events.
+// @Tags         messages
+// @Produce      json
+// @Param        id   path      string  true  "Message ID"
+// @Success      200  {array}   service.MessageEvent
+// @Failure      400  {string}  string "Invalid message ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404  {string}  string "Message not found or message events not enabled"
+// @Failure      500  {string}  string "Failed to get message events"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/events [get]
+func (h *MessageEventHandler) GetMessageEvents(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    events, err := h.store.History(r.Context(), messageID, user.IsAdmin)
+    switch {
+    case errors.Is(err, service.ErrMessageEventsDisabled):
+        http.Error(w, "Message events are not enabled", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to get message events: %v", err)
+        http.Error(w, "Failed to get message events", http.StatusInternalServerError)
+        return
+    }
+
+    if !user.IsAdmin {
+        roomID, _ := uuid.Parse(events[0].RoomID)
+        room, err := h.db.GetRoomByID(r.Context(), roomID)
+        if err != nil {
+            http.Error(w, "Message not found", http.StatusNotFound)
+            return
+        }
+        if moderator, _ := service.CanModerateRoom(r.Context(), h.db, room, userID); !moderator {
+            http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+            return
+        }
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(events)
+}
+
+// ListMessageEvents godoc
+// @Summary      Page through the message event log
+// @Description  Returns the events of every message, in log order, after the position given by after (0 for the start of the log). Another instance can replicate the messages by appending these events to its own log, which builds its read models the same way. Events from the last few seconds are held back until every transaction that could still add one before them has committed, so a reader that keeps asking from the last position it saw misses nothing. Administrators only; unavailable unless MESSAGE_STORAGE is events.
+// @Tags         admin
+// @Produce      json
+// @Param        after  query     integer  false  "Position of the last event already read"
+// @Param        limit  query     integer  false  "Maximum number of events (default 50, max 200)"
+// @Success      200    {array}   service.MessageEvent
+// @Failure      400    {string}  string "Invalid after or limit"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      403    {string}  string "Forbidden: Administrators only"
+// @Failure      404    {string}  string "Message events not enabled"
+// @Failure      500    {string}  string "Failed to list message events"
+// @Security     ApiKeyAuth
+// @Router       /message-events [get]
+func (h *MessageEventHandler) ListMessageEvents(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var after int64
+    if v := r.URL.Query().Get("after"); v != "" {
+        var err error
+        if after, err = strconv.ParseInt(v, 10, 64); err != nil || after < 0 {
+            http.Error(w, "Invalid after", http.StatusBadRequest)
+            return
+        }
+    }
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return
+    }
+
+    events, err := h.store.Events(r.Context(), after, limit)
+    if errors.Is(err, service.ErrMessageEventsDisabled) {
+        http.Error(w, "Message events are not enabled", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to list message events: %v", err)
+        http.Error(w, "Failed to list message events", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// MetricsHandler exposes the server's metrics to monitoring.
+type MetricsHandler struct {
+    hub   *service.Hub
+    jobs  *service.JobQueue
+    token string
+}
+
+// NewMetricsHandler creates a new metrics handler. Scrapers authenticate
+// with token as a bearer token.
+func NewMetricsHandler(hub *service.Hub, jobs *service.JobQueue, token string) *MetricsHandler {
+    return &MetricsHandler{hub: hub, jobs: jobs, token: token}
+}
+
+// GetMetrics serves metrics in the Prometheus text format to requests with
+// the metrics token. It is infrastructure rather than API, so it lives at
+// /metrics, outside the versioned routes and the API documentation.
+//
+// chat_broadcast_latency_seconds is a histogram, per room size, of the time
+// from receiving a new message to writing it to each client of the room.
+// chat_broadcast_latency_recent_seconds holds its 0.5, 0.95 and 0.99
+// quantiles over the last 5 minutes, and chat_broadcast_slo_burn_rate how
+// fast the last 5 minutes and hour used up the broadcast SLO's error budget.
+//
+// chat_jobs counts background jobs by type and status, and
+// chat_job_oldest_pending_seconds is how long the longest waiting pending
+// job of each type has been due, which grows while a queue is stuck.
+func (h *MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+    token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+    if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+        http.Error(w, "Invalid metrics token", http.StatusUnauthorized)
+        return
+    }
+
+    var b strings.Builder
+    slo := h.hub.SLO()
+    snapshots := h.hub.BroadcastLatency()
+
+    b.WriteString("# HELP chat_broadcast_latency_seconds Time from receiving a new message to writing it to a client, by room size.\n")
+    b.WriteString("# TYPE chat_broadcast_latency_seconds histogram\n")
+    for _, s := range snapshots {
+        var count uint64
+        for i, n := range s.Counts {
+            count += n
+            le := "+Inf"
+            if i < len(service.LatencyBounds) {
+                le = formatSeconds(service.LatencyBounds[i])
+            }
+            fmt.Fprintf(&b, "chat_broadcast_latency_seconds_bucket{room_size=%q,le=%q} %d\n", s.RoomSize, le, count)
+        }
+        fmt.Fprintf(&b, "chat_broadcast_latency_seconds_sum{room_size=%q} %s\n", s.RoomSize, formatSeconds(s.Sum))
+        fmt.Fprintf(&b, "chat_broadcast_latency_seconds_count{room_size=%q} %d\n", s.RoomSize, count)
+    }
+
+    b.WriteString("# HELP chat_broadcast_latency_recent_seconds Quantiles of the broadcast latency over the last 5 minutes, by room size.\n")
+    b.WriteString("# TYPE chat_broadcast_latency_recent_seconds gauge\n")
+    for _, s := range snapshots {
+        for _, q := range []float64{0.5, 0.95, 0.99} {
+            if latency, ok := s.Quantiles[q]; ok {
+                fmt.Fprintf(&b, "chat_broadcast_latency_recent_seconds{room_size=%q,quantile=%q} %s\n", s.RoomSize, strconv.FormatFloat(q, 'f', -1, 64), formatSeconds(latency))
+            }
+        }
+    }
+
+    b.WriteString("# HELP chat_broadcast_slo_burn_rate Rate at which late broadcasts use up the SLO's error budget, by room size and window.\n")
+    b.WriteString("# TYPE chat_broadcast_slo_burn_rate gauge\n")
+    for _, s := range snapshots {
+        for _, window := range []time.Duration{service.LatencyWindowShort, service.LatencyWindowLong} {
+            if rate, ok := s.BurnRate[window]; ok {
+                fmt.Fprintf(&b, "chat_broadcast_slo_burn_rate{room_size=%q,window=%q} %s\n", s.RoomSize, promDuration(window), strconv.FormatFloat(rate, 'f', -1, 64))
+            }
+        }
+    }
+
+    b.WriteString("# HELP chat_broadcast_slo_threshold_seconds Latency within which broadcasts count as on time.\n")
+    b.WriteString("# TYPE chat_broadcast_slo_threshold_seconds gauge\n")
+    fmt.Fprintf(&b, "chat_broadcast_slo_threshold_seconds %s\n", formatSeconds(slo.Threshold))
+    b.WriteString("# HELP chat_broadcast_slo_objective Fraction of broadcasts that should be on time.\n")
+    b.WriteString("# TYPE chat_broadcast_slo_objective gauge\n")
+    fmt.Fprintf(&b, "chat_broadcast_slo_objective %s\n", strconv.FormatFloat(slo.Objective, 'f', -1, 64))
+
+    depth, err := h.jobs.Depth(r.Context())
+    if err != nil {
+        log.Printf("Failed to get job queue depth: %v", err)
+    }
+    b.WriteString("# HELP chat_jobs Background jobs, by type and status.\n")
+    b.WriteString("# TYPE chat_jobs gauge\n")
+    for _, d := range depth {
+        fmt.Fprintf(&b, "chat_jobs{type=%q,status=%q} %d\n", d.Type, d.Status, d.Count)
+    }
+    b.WriteString("# HELP chat_job_oldest_pending_seconds How long the longest waiting pending job has been due, by type.\n")
+    b.WriteString("# TYPE chat_job_oldest_pending_seconds gauge\n")
+    for _, d := range depth {
+        if d.Status == service.JobStatusPending {
+            fmt.Fprintf(&b, "chat_job_oldest_pending_seconds{type=%q} %s\n", d.Type, formatSeconds(max(time.Since(d.OldestRunAt), 0)))
+        }
+    }
+
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    w.Write([]byte(b.String()))
+}
+
+// formatSeconds formats d as a number of seconds.
+func formatSeconds(d time.Duration) string {
+    return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// promDuration formats whole minutes or hours the way Prometheus does, such
+// as 5m or 1h.
+func promDuration(d time.Duration) string {
+    if d%time.Hour == 0 {
+        return fmt.Sprintf("%dh", d/time.Hour)
+    }
+    return fmt.Sprintf("%dm", d/time.Minute)
+}
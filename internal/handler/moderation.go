@@ -0,0 +1,479 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// ModerationHandler handles moderator endpoints.
+type ModerationHandler struct {
+    db         *database.Queries
+    moderation *service.ModerationService
+    reports    *service.ReportService
+    webhooks   *service.WebhookService
+}
+
+// NewModerationHandler creates a new moderation handler.
+func NewModerationHandler(db *database.Queries, moderation *service.ModerationService, reports *service.ReportService, webhooks *service.WebhookService) *ModerationHandler {
+    return &ModerationHandler{db: db, moderation: moderation, reports: reports, webhooks: webhooks}
+}
+
+// ReportRequest defines the request body for reporting a message.
+type ReportRequest struct {
+    Reason string `json:"reason" example:"spam"`
+}
+
+// BulkDeleteMessages godoc
+// @Summary      Delete messages in bulk
+// @Description  Deletes up to 1000 messages by ID right away, answering 200; connected members receive a single messages.deleted event listing the deleted IDs. Only room owners, moderators and administrators can do this, and every deletion is recorded in the audit log.
+// @Description  Deleting by user_id and/or time range [from, to), either end of which may be left open, starts a purge instead, answered with 202 and the pending purge. It deletes, in the background and in batches, the matching messages sent before it was requested; follow its progress with GET /rooms/{id}/messages/purges/{purgeID}. Once it finishes, connected members receive a single messages.purged event with the purge, and drop the messages sent by its user_id, if any, from its from, if any, until its to.
+// @Description  Messages under legal hold are skipped either way.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string              true  "Room ID"
+// @Param        filter  body      service.BulkDelete  true  "Message IDs, or user and/or time range"
+// @Success      200     {object}  service.DeletedMessages
+// @Success      202     {object}  service.MessagePurge
+// @Failure      400     {string}  string "Invalid room ID or filter"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404     {string}  string "Room not found"
+// @Failure      500     {string}  string "Failed to delete messages"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/messages/bulk-delete [post]
+func (h *ModerationHandler) BulkDeleteMessages(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadDeletionRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req service.BulkDelete
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    var result any
+    status := http.StatusOK
+    var err error
+    if len(req.IDs) > 0 {
+        result, err = h.moderation.BulkDeleteMessages(r.Context(), room.ID, userID, req)
+    } else {
+        result, err = h.moderation.PurgeMessages(r.Context(), room.ID, userID, req)
+        status = http.StatusAccepted
+    }
+    if errors.Is(err, service.ErrInvalidBulkDelete) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to delete messages: %v", err)
+        http.Error(w, "Failed to delete messages", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(result)
+}
+
+// GetMessagePurge godoc
+// @Summary      Get a purge's progress
+// @Description  Returns a purge started with POST /rooms/{id}/messages/bulk-delete: its status, how many messages it covered when requested and how many it has deleted so far. Only room owners, moderators and administrators can see it.
+// @Tags         messages
+// @Produce      json
+// @Param        id       path      string  true  "Room ID"
+// @Param        purgeID  path      string  true  "Purge ID"
+// @Success      200      {object}  service.MessagePurge
+// @Failure      400      {string}  string "Invalid room or purge ID"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404      {string}  string "Room or purge not found"
+// @Failure      500      {string}  string "Failed to get purge"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/messages/purges/{purgeID} [get]
+func (h *ModerationHandler) GetMessagePurge(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadDeletionRoom(w, r)
+    if !ok {
+        return
+    }
+    purgeID, err := uuid.Parse(chi.URLParam(r, "purgeID"))
+    if err != nil {
+        http.Error(w, "Invalid purge ID", http.StatusBadRequest)
+        return
+    }
+
+    purge, err := h.moderation.Purge(r.Context(), room.ID, purgeID)
+    if errors.Is(err, service.ErrPurgeNotFound) {
+        http.Error(w, "Purge not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get purge: %v", err)
+        http.Error(w, "Failed to get purge", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(purge)
+}
+
+// loadDeletionRoom loads the room from the URL as loadModeratedRoom does, but
+// also lets administrators through, who can delete messages in bulk in any
+// room.
+func (h *ModerationHandler) loadDeletionRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if moderator, _ := service.CanModerateRoom(r.Context(), h.db, room, userID); !moderator {
+        user, err := h.db.GetUserByID(r.Context(), userID)
+        if err != nil || !user.IsAdmin {
+            http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+            return database.Room{}, uuid.Nil, false
+        }
+    }
+    return room, userID, true
+}
+
+// ReportMessage godoc
+// @Summary      Report a message
+// @Description  Reports a message the user can see to the room's owners and the administrators. Connected administrators receive a message.reported event on the admin channel (/ws/admin). Each user can report a message once, and not their own.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string         true  "Message ID"
+// @Param        report  body      ReportRequest  true  "Reason for the report"
+// @Success      201     {object}  service.Report
+// @Failure      400     {string}  string "Invalid message ID or reason, or the message is your own"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      404     {string}  string "Message not found"
+// @Failure      409     {string}  string "You already reported this message"
+// @Failure      500     {string}  string "Failed to report message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/report [post]
+func (h *ModerationHandler) ReportMessage(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    var req ReportRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    report, err := h.reports.Report(r.Context(), userID, messageID, req.Reason)
+    switch {
+    case errors.Is(err, service.ErrInvalidReport), errors.Is(err, service.ErrOwnMessageReport):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrAlreadyReported):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to report message: %v", err)
+        http.Error(w, "Failed to report message", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(report)
+}
+
+// GetRoomReports godoc
+// @Summary      List a room's message reports
+// @Description  Lists the reports filed about messages in a room, newest first. Only room owners, moderators and administrators can see them.
+// @Tags         messages
+// @Produce      json
+// @Param        id     path      string   true   "Room ID"
+// @Param        limit  query     integer  false  "Maximum number of reports (default 50, max 200)"
+// @Success      200    {array}   service.Report
+// @Failure      400    {string}  string "Invalid room ID or limit"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      403    {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404    {string}  string "Room not found"
+// @Failure      500    {string}  string "Failed to get reports"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/reports [get]
+func (h *ModerationHandler) GetRoomReports(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if moderator, _ := service.CanModerateRoom(r.Context(), h.db, room, userID); !moderator {
+        user, err := h.db.GetUserByID(r.Context(), userID)
+        if err != nil || !user.IsAdmin {
+            http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+            return
+        }
+    }
+
+    reports, err := h.reports.RoomReports(r.Context(), roomID, limit)
+    if err != nil {
+        log.Printf("Failed to get reports: %v", err)
+        http.Error(w, "Failed to get reports", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(reports)
+}
+
+// BanRequest defines the optional request body for banning a user.
+type BanRequest struct {
+    // DurationMinutes is how long the ban lasts; omitted or 0 bans for good.
+    DurationMinutes int `json:"duration_minutes,omitempty" example:"60"`
+}
+
+// KickMember godoc
+// @Summary      Kick a member from a room
+// @Description  Removes a member from the room and closes their open WebSocket connection to it; they can join again. Owners can kick moderators and members, moderators only members. The room's owners cannot be kicked.
+// @Description  The room's webhooks subscribed to leave events receive the change.
+// @Tags         rooms
+// @Param        id      path      string  true  "Room ID"
+// @Param        userID  path      string  true  "Member's user ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not a moderator of this room, or the member outranks you"
+// @Failure      404     {string}  string "Room not found or user is not a member of this room"
+// @Failure      500     {string}  string "Failed to kick member"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/kick/{userID} [post]
+func (h *ModerationHandler) KickMember(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+    memberID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.moderation.Kick(r.Context(), room, userID, memberID)
+    switch {
+    case errors.Is(err, service.ErrNotRoomMember):
+        http.Error(w, "User is not a member of this room", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrMemberProtected):
+        http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to kick member: %v", err)
+        http.Error(w, "Failed to kick member", http.StatusInternalServerError)
+        return
+    }
+    h.webhooks.Dispatch(room.ID, service.WebhookEventLeave, service.MembershipChange{UserID: memberID.String(), ActorID: userID.String()})
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// BanMember godoc
+// @Summary      Ban a user from a room
+// @Description  Bans a user from the room for duration_minutes, or for good when it is omitted. Members are removed and their open WebSocket connection to the room is closed; banned users cannot join, be added or accept invitations until the ban ends. Banning a banned user replaces the ban.
+// @Description  Owners can ban moderators, members and other users, moderators only members and other users. The room's owners cannot be banned. The room's webhooks subscribed to ban events receive the ban.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string      true   "Room ID"
+// @Param        userID  path      string      true   "User ID"
+// @Param        ban     body      BanRequest  false  "Ban duration"
+// @Success      200     {object}  service.Ban
+// @Failure      400     {string}  string "Invalid room ID, user ID or duration"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not a moderator of this room, or the user outranks you"
+// @Failure      404     {string}  string "Room or user not found"
+// @Failure      500     {string}  string "Failed to ban user"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/ban/{userID} [post]
+func (h *ModerationHandler) BanMember(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+    bannedID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req BanRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if req.DurationMinutes < 0 {
+        http.Error(w, "duration_minutes cannot be negative", http.StatusBadRequest)
+        return
+    }
+
+    ban, err := h.moderation.Ban(r.Context(), room, userID, bannedID, time.Duration(req.DurationMinutes)*time.Minute)
+    switch {
+    case errors.Is(err, service.ErrUserNotFound):
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrMemberProtected):
+        http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to ban user: %v", err)
+        http.Error(w, "Failed to ban user", http.StatusInternalServerError)
+        return
+    }
+    h.webhooks.Dispatch(room.ID, service.WebhookEventBan, ban)
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(ban)
+}
+
+// UnbanMember godoc
+// @Summary      Lift a ban
+// @Description  Lifts a user's ban from the room so they can join it again. Only room owners and moderators can lift bans.
+// @Tags         rooms
+// @Param        id      path      string  true  "Room ID"
+// @Param        userID  path      string  true  "Banned user's ID"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room or user ID"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404     {string}  string "Room not found or user is not banned"
+// @Failure      500     {string}  string "Failed to lift ban"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/ban/{userID} [delete]
+func (h *ModerationHandler) UnbanMember(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+    bannedID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    err = h.moderation.Unban(r.Context(), room.ID, userID, bannedID)
+    if errors.Is(err, service.ErrBanNotFound) {
+        http.Error(w, "User is not banned from this room", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to lift ban: %v", err)
+        http.Error(w, "Failed to lift ban", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRoomBans godoc
+// @Summary      List a room's bans
+// @Description  Lists the bans of a room that have not expired, newest first. Only room owners and moderators can see them.
+// @Tags         rooms
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   service.Ban
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get bans"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/bans [get]
+func (h *ModerationHandler) GetRoomBans(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    bans, err := h.moderation.Bans(r.Context(), room.ID)
+    if err != nil {
+        log.Printf("Failed to get bans: %v", err)
+        http.Error(w, "Failed to get bans", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bans)
+}
+
+// loadModeratedRoom loads the room from the URL, checking that the
+// authenticated user is one of its owners or moderators.
+func (h *ModerationHandler) loadModeratedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if moderator, err := service.CanModerateRoom(r.Context(), h.db, room, userID); err != nil || !moderator {
+        http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// NotificationSettingsRequest defines the request body for setting a room's
+// notification level.
+type NotificationSettingsRequest struct {
+    // Level is "all", "mentions" or "none".
+    Level string `json:"level" example:"none"`
+}
+
+// NotificationSettingsHandler handles members' room notification settings.
+type NotificationSettingsHandler struct {
+    settings *service.NotificationSettingsService
+}
+
+// NewNotificationSettingsHandler creates a new notification settings handler.
+func NewNotificationSettingsHandler(settings *service.NotificationSettingsService) *NotificationSettingsHandler {
+    return &NotificationSettingsHandler{settings: settings}
+}
+
+// GetNotificationSettings godoc
+// @Summary      Get my notification settings for a room
+// @Description  Returns how much the current user is notified about the room, "mentions" unless they chose otherwise.
+// @Tags         rooms
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {object}  service.RoomNotificationSettings
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: User is not a member of this room"
+// @Failure      500  {string}  string "Failed to get notification settings"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/notification-settings [get]
+func (h *NotificationSettingsHandler) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    settings, err := h.settings.RoomSettings(r.Context(), roomID, userID)
+    if errors.Is(err, service.ErrNotRoomMember) {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get notification settings: %v", err)
+        http.Error(w, "Failed to get notification settings", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(settings)
+}
+
+// SetNotificationSettings godoc
+// @Summary      Set my notification settings for a room
+// @Description  Sets how much the current user is notified about the room. With "all", every message is pushed while they are offline. With "mentions", the default, only mentions, direct messages and urgent messages are. With "none", the room is muted: nothing is pushed, urgent messages included, and mentions and replies are left out of the activity feed. Unread counts are kept at every level. The setting is removed when the user leaves the room.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                       true  "Room ID"
+// @Param        settings  body      NotificationSettingsRequest  true  "Notification level"
+// @Success      200       {object}  service.RoomNotificationSettings
+// @Failure      400       {string}  string "Invalid room ID, request body or level"
+// @Failure      401       {string}  string "User not authenticated"
+// @Failure      403       {string}  string "Forbidden: User is not a member of this room"
+// @Failure      500       {string}  string "Failed to set notification settings"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/notification-settings [put]
+func (h *NotificationSettingsHandler) SetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    var req NotificationSettingsRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    settings, err := h.settings.SetRoomLevel(r.Context(), roomID, userID, req.Level)
+    switch {
+    case errors.Is(err, service.ErrInvalidNotificationLevel):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrNotRoomMember):
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to set notification settings: %v", err)
+        http.Error(w, "Failed to set notification settings", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(settings)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+    defaultPageSize = 50
+    maxPageSize     = 200
+)
+
+// nextCursorHeader carries the cursor for the next page of a listing. It is
+// absent on the last page.
+const nextCursorHeader = "X-Next-Cursor"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor identifies the last row of a page in (created_at, id) order.
+type pageCursor struct {
+    CreatedAt time.Time
+    ID        uuid.UUID
+}
+
+// encode returns the opaque string form of the cursor.
+func (c pageCursor) encode() string {
+    raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+    return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor produced by encode.
+func decodeCursor(s string) (pageCursor, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return pageCursor{}, errInvalidCursor
+    }
+    createdAt, id, ok := strings.Cut(string(raw), "|")
+    if !ok {
+        return pageCursor{}, errInvalidCursor
+    }
+    t, err := time.Parse(time.RFC3339Nano, createdAt)
+    if err != nil {
+        return pageCursor{}, errInvalidCursor
+    }
+    u, err := uuid.Parse(id)
+    if err != nil {
+        return pageCursor{}, errInvalidCursor
+    }
+    return pageCursor{CreatedAt: t, ID: u}, nil
+}
+
+// parseLimit reads the limit query parameter, applying the default and maximum page size.
+func parseLimit(r *http.Request) (int32, error) {
+    v := r.URL.Query().Get("limit")
+    if v == "" {
+        return defaultPageSize, nil
+    }
+    limit, err := strconv.Atoi(v)
+    if err != nil || limit < 1 {
+        return 0, errors.New("invalid limit")
+    }
+    if limit > maxPageSize {
+        limit = maxPageSize
+    }
+    return int32(limit), nil
+}
+
+// roomCursor identifies the last room of a page in the order of one of the
+// room list sorts. Time holds the created_at or last_activity sort key and
+// Count the member_count or message_count one.
+type roomCursor struct {
+    Sort  string
+    Time  time.Time
+    Count int64
+    ID    uuid.UUID
+}
+
+// encode returns the opaque string form of the cursor.
+func (c roomCursor) encode() string {
+    key := c.Time.UTC().Format(time.RFC3339Nano)
+    if c.countSort() {
+        key = strconv.FormatInt(c.Count, 10)
+    }
+    raw := c.Sort + "|" + key + "|" + c.ID.String()
+    return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeRoomCursor parses a cursor produced by roomCursor.encode for a page
+// in the given sort order.
+func decodeRoomCursor(s, sort string) (roomCursor, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return roomCursor{}, errInvalidCursor
+    }
+    parts := strings.Split(string(raw), "|")
+    if len(parts) != 3 || parts[0] != sort {
+        return roomCursor{}, errInvalidCursor
+    }
+    c := roomCursor{Sort: sort}
+    if c.ID, err = uuid.Parse(parts[2]); err != nil {
+        return roomCursor{}, errInvalidCursor
+    }
+    if c.countSort() {
+        c.Count, err = strconv.ParseInt(parts[1], 10, 64)
+    } else {
+        c.Time, err = time.Parse(time.RFC3339Nano, parts[1])
+    }
+    if err != nil {
+        return roomCursor{}, errInvalidCursor
+    }
+    return c, nil
+}
+
+// countSort reports whether the cursor's sort key is a count.
+func (c roomCursor) countSort() bool {
+    return c.Sort == roomSortMemberCount || c.Sort == roomSortMessageCount
+}
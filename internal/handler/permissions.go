@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// GetRoomPermissions godoc
+// @Summary      Get a room's permissions
+// @Description  Returns the least role a member needs to invite users, send messages with attachments and pin messages in the room, with defaults applied, so clients can hide what the user cannot do. Owners and co-owners can always do all of it.
+// @Tags         rooms
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {object}  service.RoomPermissions
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404  {string}  string "Room not found"
+// @Failure      500  {string}  string "Failed to get permissions"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/permissions [get]
+func (h *RoomHandler) GetRoomPermissions(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    role, err := service.RoomRole(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("Failed to get permissions: %v", err)
+        http.Error(w, "Failed to get permissions", http.StatusInternalServerError)
+        return
+    }
+    if role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    permissions, err := service.LoadRoomPermissions(r.Context(), h.db, room)
+    if err != nil {
+        log.Printf("Failed to get permissions: %v", err)
+        http.Error(w, "Failed to get permissions", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(permissions)
+}
+
+// SetRoomPermissions godoc
+// @Summary      Set a room's permissions
+// @Description  Sets the least role ("owner", "moderator" or "member") a member needs to invite users and create invite codes (invite), send messages with attachments listed under "attachments" in their metadata (attachments), and pin or unpin messages (pin). Permissions left out keep their value, and an empty string restores the default: invite is member in public rooms and owner in private ones, attachments member and pin moderator. Messages with attachments from members below the required role are rejected with an attachments_not_allowed error frame. Only room owners and co-owners can perform this action.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id           path      string                         true  "Room ID"
+// @Param        permissions  body      service.RoomPermissionsUpdate  true  "Permissions to change"
+// @Success      200          {object}  service.RoomPermissions
+// @Failure      400          {string}  string "Invalid room ID, request body or role"
+// @Failure      401          {string}  string "User not authenticated"
+// @Failure      403          {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404          {string}  string "Room not found"
+// @Failure      500          {string}  string "Failed to set permissions"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/permissions [put]
+func (h *RoomHandler) SetRoomPermissions(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req service.RoomPermissionsUpdate
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    permissions, err := service.SetRoomPermissions(r.Context(), h.db, room, userID, req)
+    if errors.Is(err, service.ErrInvalidPermissions) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to set permissions: %v", err)
+        http.Error(w, "Failed to set permissions", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(permissions)
+}
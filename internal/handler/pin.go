@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// PinHandler handles pinned messages.
+type PinHandler struct {
+    pins *service.PinService
+}
+
+// NewPinHandler creates a new pin handler.
+func NewPinHandler(pins *service.PinService) *PinHandler {
+    return &PinHandler{pins: pins}
+}
+
+// GetPins godoc
+// @Summary      Get a room's pinned messages
+// @Description  Returns the room's pinned messages, most recently pinned first, with who pinned them and when.
+// @Tags         rooms
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {array}   service.PinnedMessage
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: User is not a member of this room"
+// @Failure      500  {string}  string "Failed to get pinned messages"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/pins [get]
+func (h *PinHandler) GetPins(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    pins, err := h.pins.Pins(r.Context(), roomID, userID)
+    if errors.Is(err, service.ErrNotRoomMember) {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get pinned messages: %v", err)
+        http.Error(w, "Failed to get pinned messages", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(pins)
+}
+
+// PinMessage godoc
+// @Summary      Pin a message
+// @Description  Pins the message in its room. Pinning a pinned message has no effect. Members need the role the room's pin permission requires, moderator by default. A room can have at most 50 pinned messages, and direct messages cannot be pinned. Members connected to the room receive a message.pinned event, and the room's pin webhooks are called.
+// @Tags         messages
+// @Param        id   path      string  true  "Message ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid message ID, or it is a direct message"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Your role in this room does not allow pinning messages"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      409  {string}  string "rooms can have at most 50 pinned messages"
+// @Failure      410  {string}  string "Room is archived"
+// @Failure      500  {string}  string "Failed to pin message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/pin [put]
+func (h *PinHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+    userID, messageID, ok := pinParams(w, r)
+    if !ok {
+        return
+    }
+
+    err := h.pins.Pin(r.Context(), userID, messageID)
+    if writePinError(w, err, "Failed to pin message") {
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// UnpinMessage godoc
+// @Summary      Unpin a message
+// @Description  Unpins the message. Unpinning a message that is not pinned has no effect. Members need the role the room's pin permission requires. Members connected to the room receive a message.unpinned event, and the room's pin webhooks are called.
+// @Tags         messages
+// @Param        id   path      string  true  "Message ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid message ID, or it is a direct message"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Your role in this room does not allow pinning messages"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      410  {string}  string "Room is archived"
+// @Failure      500  {string}  string "Failed to unpin message"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/pin [delete]
+func (h *PinHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+    userID, messageID, ok := pinParams(w, r)
+    if !ok {
+        return
+    }
+
+    err := h.pins.Unpin(r.Context(), userID, messageID)
+    if writePinError(w, err, "Failed to unpin message") {
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// pinParams reads the authenticated user and the message ID from the request.
+func pinParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return uuid.Nil, uuid.Nil, false
+    }
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return uuid.Nil, uuid.Nil, false
+    }
+    return userID, messageID, true
+}
+
+// writePinError writes the response for an error from pinning or unpinning,
+// logging unexpected ones with failure as the message. It reports whether
+// there was an error.
+func writePinError(w http.ResponseWriter, err error, failure string) bool {
+    switch {
+    case err == nil:
+        return false
+    case errors.Is(err, service.ErrInvalidPin):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+    case errors.Is(err, service.ErrPinNotAllowed):
+        http.Error(w, "Forbidden: Your role in this room does not allow pinning messages", http.StatusForbidden)
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+    case errors.Is(err, service.ErrTooManyPins):
+        http.Error(w, err.Error(), http.StatusConflict)
+    case errors.Is(err, service.ErrRoomArchived):
+        http.Error(w, "Room is archived", http.StatusGone)
+    default:
+        log.Printf("%s: %v", failure, err)
+        http.Error(w, failure, http.StatusInternalServerError)
+    }
+    return true
+}
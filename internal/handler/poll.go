@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// PollHandler handles poll endpoints.
+type PollHandler struct {
+    db    *database.Queries
+    polls *service.PollService
+}
+
+// NewPollHandler creates a new poll handler.
+func NewPollHandler(db *database.Queries, polls *service.PollService) *PollHandler {
+    return &PollHandler{db: db, polls: polls}
+}
+
+// CreatePollRequest defines the request body for creating a poll.
+type CreatePollRequest struct {
+    Question string   `json:"question" example:"Where should we have lunch?"`
+    Options  []string `json:"options" example:"Pizza,Sushi,Tacos"`
+}
+
+// VoteRequest defines the request body for voting on a poll.
+type VoteRequest struct {
+    OptionID uuid.UUID `json:"option_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+}
+
+// CreatePoll godoc
+// @Summary      Create a poll
+// @Description  Posts a poll message to a room. The poll is broadcast to connected members and kept in history.
+// @Tags         polls
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string             true  "Room ID"
+// @Param        poll  body      CreatePollRequest  true  "Question and options"
+// @Success      201   {object}  service.Message
+// @Failure      400   {string}  string "Invalid room ID or poll"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "User is not a member of this room"
+// @Failure      500   {string}  string "Failed to create poll"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/polls [post]
+func (h *PollHandler) CreatePoll(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    if !h.isMember(r, roomID, userID) {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    var req CreatePollRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    message, err := h.polls.CreatePoll(r.Context(), roomID, userID, req.Question, req.Options)
+    if errors.Is(err, service.ErrInvalidPoll) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to create poll: %v", err)
+        http.Error(w, "Failed to create poll", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(message)
+}
+
+// GetPoll godoc
+// @Summary      Get a poll
+// @Description  Retrieves a poll with its current results. Only members of the poll's room can view it.
+// @Tags         polls
+// @Produce      json
+// @Param        id  path      string  true  "Poll ID"
+// @Success      200 {object}  service.Poll
+// @Failure      400 {string}  string "Invalid poll ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      404 {string}  string "Poll not found"
+// @Security     ApiKeyAuth
+// @Router       /polls/{id} [get]
+func (h *PollHandler) GetPoll(w http.ResponseWriter, r *http.Request) {
+    poll, ok := h.loadPoll(w, r)
+    if !ok {
+        return
+    }
+
+    _, snapshot, err := h.polls.GetPoll(r.Context(), poll.ID)
+    if err != nil {
+        http.Error(w, "Failed to get poll", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(snapshot)
+}
+
+// Vote godoc
+// @Summary      Vote on a poll
+// @Description  Records the authenticated user's vote, replacing any earlier vote. The new counts are broadcast to the room.
+// @Tags         polls
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string       true  "Poll ID"
+// @Param        vote  body      VoteRequest  true  "Chosen option"
+// @Success      200   {object}  service.Poll
+// @Failure      400   {string}  string "Invalid poll ID, body or option"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      404   {string}  string "Poll not found"
+// @Failure      409   {string}  string "Poll is closed"
+// @Failure      500   {string}  string "Failed to vote"
+// @Security     ApiKeyAuth
+// @Router       /polls/{id}/votes [post]
+func (h *PollHandler) Vote(w http.ResponseWriter, r *http.Request) {
+    poll, ok := h.loadPoll(w, r)
+    if !ok {
+        return
+    }
+    userID, _ := authUserID(r)
+
+    var req VoteRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    snapshot, err := h.polls.Vote(r.Context(), poll, userID, req.OptionID)
+    switch {
+    case errors.Is(err, service.ErrPollClosed):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case errors.Is(err, service.ErrInvalidPollOption):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case err != nil:
+        log.Printf("Failed to vote: %v", err)
+        http.Error(w, "Failed to vote", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(snapshot)
+}
+
+// ClosePoll godoc
+// @Summary      Close a poll
+// @Description  Closes a poll so no more votes are accepted. Only the poll creator or a room owner or co-owner can close it. The final results are broadcast to the room.
+// @Tags         polls
+// @Produce      json
+// @Param        id  path      string  true  "Poll ID"
+// @Success      200 {object}  service.Poll
+// @Failure      400 {string}  string "Invalid poll ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: Only the creator or room owner can close the poll"
+// @Failure      404 {string}  string "Poll not found"
+// @Failure      409 {string}  string "Poll is closed"
+// @Failure      500 {string}  string "Failed to close poll"
+// @Security     ApiKeyAuth
+// @Router       /polls/{id}/close [post]
+func (h *PollHandler) ClosePoll(w http.ResponseWriter, r *http.Request) {
+    poll, ok := h.loadPoll(w, r)
+    if !ok {
+        return
+    }
+    userID, _ := authUserID(r)
+
+    if poll.CreatorID != userID && !ownsRoom(r, h.db, poll.RoomID, userID) {
+        http.Error(w, "Forbidden: Only the creator or room owner can close the poll", http.StatusForbidden)
+        return
+    }
+
+    snapshot, err := h.polls.ClosePoll(r.Context(), poll)
+    if errors.Is(err, service.ErrPollClosed) {
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to close poll: %v", err)
+        http.Error(w, "Failed to close poll", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(snapshot)
+}
+
+// loadPoll resolves the poll in the URL and checks that the authenticated user
+// is a member of its room. Polls in other rooms are reported as not found.
+func (h *PollHandler) loadPoll(w http.ResponseWriter, r *http.Request) (database.Poll, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Poll{}, false
+    }
+
+    pollID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid poll ID", http.StatusBadRequest)
+        return database.Poll{}, false
+    }
+
+    poll, err := h.db.GetPollByID(r.Context(), pollID)
+    if err != nil || !h.isMember(r, poll.RoomID, userID) {
+        http.Error(w, "Poll not found", http.StatusNotFound)
+        return database.Poll{}, false
+    }
+    return poll, true
+}
+
+func (h *PollHandler) isMember(r *http.Request, roomID, userID uuid.UUID) bool {
+    isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{
+        RoomID: roomID,
+        UserID: userID,
+    })
+    return err == nil && isMember
+}
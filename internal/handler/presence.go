@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// maxPresenceUsers caps how many users a single presence request can ask about.
+const maxPresenceUsers = 500
+
+// PresenceHandler reports users' presence over HTTP.
+type PresenceHandler struct {
+    db  *database.Queries
+    hub *service.Hub
+}
+
+// NewPresenceHandler creates a new presence handler.
+func NewPresenceHandler(db *database.Queries, hub *service.Hub) *PresenceHandler {
+    return &PresenceHandler{db: db, hub: hub}
+}
+
+// GetPresence godoc
+// @Summary      Get users' presence
+// @Description  Reports whether each of the users is online, away or offline, in the order given, so clients can fill in contact lists without connecting to each room. Users are online while connected to any room, and away once none of their connections has sent a message, typing or read frame for 5 minutes; heartbeats do not count. The presence frames sent over WebSocket report users as online until they go offline.
+// @Description  Presence is only shared between users who have a room in common, as with presence frames: anyone else is reported offline. Only connections to this server are counted.
+// @Tags         users
+// @Produce      json
+// @Param        user_ids  query     string  true  "Comma-separated user IDs, at most 500"
+// @Success      200       {array}   service.Presence
+// @Failure      400       {string}  string "Invalid user_ids"
+// @Failure      401       {string}  string "User not authenticated"
+// @Failure      500       {string}  string "Failed to get presence"
+// @Security     ApiKeyAuth
+// @Router       /presence [get]
+func (h *PresenceHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var userIDs []uuid.UUID
+    for _, raw := range strings.Split(r.URL.Query().Get("user_ids"), ",") {
+        if raw = strings.TrimSpace(raw); raw == "" {
+            continue
+        }
+        id, err := uuid.Parse(raw)
+        if err != nil {
+            http.Error(w, "Invalid user_ids", http.StatusBadRequest)
+            return
+        }
+        userIDs = append(userIDs, id)
+    }
+    if len(userIDs) == 0 {
+        http.Error(w, "Invalid user_ids", http.StatusBadRequest)
+        return
+    }
+    if len(userIDs) > maxPresenceUsers {
+        http.Error(w, fmt.Sprintf("At most %d users can be asked about at once", maxPresenceUsers), http.StatusBadRequest)
+        return
+    }
+
+    mates, err := h.db.GetRoomMates(r.Context(), database.GetRoomMatesParams{UserID: userID, UserIds: userIDs})
+    if err != nil {
+        log.Printf("Failed to get presence: %v", err)
+        http.Error(w, "Failed to get presence", http.StatusInternalServerError)
+        return
+    }
+    visible := map[uuid.UUID]bool{userID: true}
+    for _, id := range mates {
+        visible[id] = true
+    }
+
+    ids := make([]string, len(userIDs))
+    for i, id := range userIDs {
+        ids[i] = id.String()
+    }
+    presence := h.hub.PresenceOf(ids)
+    for i, id := range userIDs {
+        if !visible[id] {
+            presence[i].Status = service.PresenceOffline
+        }
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(presence)
+}
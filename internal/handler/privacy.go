@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// PrivacyRequest defines the request body for setting privacy preferences.
+type PrivacyRequest struct {
+    // TypingIndicators is whether others see when the user is typing.
+    TypingIndicators *bool `json:"typing_indicators" example:"false"`
+}
+
+// PrivacyHandler handles users' privacy preferences.
+type PrivacyHandler struct {
+    privacy *service.PrivacyService
+}
+
+// NewPrivacyHandler creates a new privacy handler.
+func NewPrivacyHandler(privacy *service.PrivacyService) *PrivacyHandler {
+    return &PrivacyHandler{privacy: privacy}
+}
+
+// GetPrivacy godoc
+// @Summary      Get my privacy preferences
+// @Description  Returns what the current user shares with others. Typing indicators are shared unless the user turned them off.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  service.PrivacyPreferences
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get privacy preferences"
+// @Security     ApiKeyAuth
+// @Router       /users/me/privacy [get]
+func (h *PrivacyHandler) GetPrivacy(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    preferences, err := h.privacy.Preferences(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get privacy preferences: %v", err)
+        http.Error(w, "Failed to get privacy preferences", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(preferences)
+}
+
+// SetPrivacy godoc
+// @Summary      Set my privacy preferences
+// @Description  Sets whether others see when the current user is typing. With typing indicators off, the server drops the user's typing frames instead of relaying them, whichever client sends them, starting with their open connections; the user still sees others typing. Read positions are never shared with other users, so there are no read receipts to turn off.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        privacy  body      PrivacyRequest  true  "Privacy preferences"
+// @Success      200      {object}  service.PrivacyPreferences
+// @Failure      400      {string}  string "Invalid request body"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      500      {string}  string "Failed to set privacy preferences"
+// @Security     ApiKeyAuth
+// @Router       /users/me/privacy [put]
+func (h *PrivacyHandler) SetPrivacy(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req PrivacyRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TypingIndicators == nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    preferences, err := h.privacy.SetTypingIndicators(r.Context(), userID, *req.TypingIndicators)
+    if err != nil {
+        log.Printf("Failed to set privacy preferences: %v", err)
+        http.Error(w, "Failed to set privacy preferences", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(preferences)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// PublicRoomHandler serves public-readable rooms to anyone.
+type PublicRoomHandler struct {
+    rooms *service.PublicRoomService
+}
+
+// NewPublicRoomHandler creates a new public room handler.
+func NewPublicRoomHandler(rooms *service.PublicRoomService) *PublicRoomHandler {
+    return &PublicRoomHandler{rooms: rooms}
+}
+
+// GetPublicRoomMessages godoc
+// @Summary      Read a public-readable room
+// @Description  Returns the name, topic and latest 50 messages, oldest first, of a room whose owners turned on public_readable, for embedding its chat log on websites. No authentication is needed, and any site may fetch it. Only messages sent to the whole room are included, with their sender's username and avatar; direct messages, metadata and mentions never are. Responses are cached for 30 seconds, so new messages, edits and settings changes can take that long to show, and requests are rate-limited per client address. Rooms that do not exist and rooms that are not public-readable are both reported as not found.
+// @Tags         public
+// @Produce      json
+// @Param        id   path      string  true  "Room ID"
+// @Success      200  {object}  service.PublicRoomLog
+// @Header       200  {string}  Cache-Control  "public, max-age=30"
+// @Failure      400  {string}  string "Invalid room ID"
+// @Failure      404  {string}  string "Room not found"
+// @Failure      429  {string}  string "Too many requests"
+// @Failure      500  {string}  string "Failed to get messages"
+// @Router       /public/rooms/{id}/messages [get]
+func (h *PublicRoomHandler) GetPublicRoomMessages(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Access-Control-Allow-Origin", "*")
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    roomLog, err := h.rooms.RecentMessages(r.Context(), roomID)
+    if errors.Is(err, service.ErrRoomNotPublic) {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get public messages of room %s: %v", roomID, err)
+        http.Error(w, "Failed to get messages", http.StatusInternalServerError)
+        return
+    }
+
+    maxAge := int(service.PublicRoomCacheTTL.Seconds())
+    w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(roomLog)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// ReactionHandler handles message reactions.
+type ReactionHandler struct {
+    reactions *service.ReactionService
+}
+
+// NewReactionHandler creates a new reaction handler.
+func NewReactionHandler(reactions *service.ReactionService) *ReactionHandler {
+    return &ReactionHandler{reactions: reactions}
+}
+
+// GetReactions godoc
+// @Summary      Get a message's reactions
+// @Description  Returns how many users reacted to the message with each emoji, in the order the emoji were first used, and the current user's own reactions.
+// @Tags         messages
+// @Produce      json
+// @Param        id   path      string  true  "Message ID"
+// @Success      200  {object}  service.MessageReactions
+// @Failure      400  {string}  string "Invalid message ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      404  {string}  string "Message not found"
+// @Failure      500  {string}  string "Failed to get reactions"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/reactions [get]
+func (h *ReactionHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return
+    }
+
+    reactions, err := h.reactions.Reactions(r.Context(), userID, messageID)
+    if errors.Is(err, service.ErrMessageNotFound) {
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get reactions: %v", err)
+        http.Error(w, "Failed to get reactions", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(reactions)
+}
+
+// AddReaction godoc
+// @Summary      React to a message
+// @Description  Reacts to the message with an emoji, given as the URL-encoded emoji or a :shortcode:. Reacting twice with the same emoji has no effect. A message can have at most 20 different emoji, and each user can add or remove 30 reactions a minute. Changes are sent to the message's audience as reactions.updated events carrying the full counts; in busy rooms they are batched to at most one per message per second.
+// @Tags         messages
+// @Produce      json
+// @Param        id     path      string  true  "Message ID"
+// @Param        emoji  path      string  true  "Emoji or shortcode"
+// @Success      200    {object}  service.MessageReactions
+// @Failure      400    {string}  string "Invalid message ID or reaction"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      404    {string}  string "Message not found"
+// @Failure      409    {string}  string "messages can have at most 20 different reactions"
+// @Failure      429    {string}  string "Too many requests"
+// @Failure      500    {string}  string "Failed to add reaction"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/reactions/{emoji} [put]
+func (h *ReactionHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+    userID, messageID, emoji, ok := reactionParams(w, r)
+    if !ok {
+        return
+    }
+
+    reactions, err := h.reactions.Add(r.Context(), userID, messageID, emoji)
+    switch {
+    case errors.Is(err, service.ErrInvalidReaction):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrTooManyReactions):
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    case err != nil:
+        log.Printf("Failed to add reaction: %v", err)
+        http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(reactions)
+}
+
+// RemoveReaction godoc
+// @Summary      Remove a reaction
+// @Description  Takes back the current user's reaction to the message. Removing a reaction that was not made has no effect. Removals count toward the same limit as reactions.
+// @Tags         messages
+// @Produce      json
+// @Param        id     path      string  true  "Message ID"
+// @Param        emoji  path      string  true  "Emoji or shortcode"
+// @Success      200    {object}  service.MessageReactions
+// @Failure      400    {string}  string "Invalid message ID or reaction"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      404    {string}  string "Message not found"
+// @Failure      429    {string}  string "Too many requests"
+// @Failure      500    {string}  string "Failed to remove reaction"
+// @Security     ApiKeyAuth
+// @Router       /messages/{id}/reactions/{emoji} [delete]
+func (h *ReactionHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+    userID, messageID, emoji, ok := reactionParams(w, r)
+    if !ok {
+        return
+    }
+
+    reactions, err := h.reactions.Remove(r.Context(), userID, messageID, emoji)
+    switch {
+    case errors.Is(err, service.ErrInvalidReaction):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to remove reaction: %v", err)
+        http.Error(w, "Failed to remove reaction", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(reactions)
+}
+
+// reactionParams reads the user, message and emoji of a reaction request,
+// writing the error response if one is missing or invalid.
+func reactionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, string, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return uuid.Nil, uuid.Nil, "", false
+    }
+
+    messageID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid message ID", http.StatusBadRequest)
+        return uuid.Nil, uuid.Nil, "", false
+    }
+
+    // The router matches on the escaped path when there is one, leaving the
+    // emoji percent-encoded.
+    emoji, err := url.PathUnescape(chi.URLParam(r, "emoji"))
+    if err != nil {
+        http.Error(w, "Invalid reaction", http.StatusBadRequest)
+        return uuid.Nil, uuid.Nil, "", false
+    }
+    return userID, messageID, emoji, true
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// authUserID returns the authenticated user's ID set by the JWT middleware.
+func authUserID(r *http.Request) (uuid.UUID, bool) {
+    userID, ok := r.Context().Value(middleware.ContextUserIDKey).(string)
+    if !ok {
+        return uuid.Nil, false
+    }
+    id, err := uuid.Parse(userID)
+    if err != nil {
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// ownsRoom reports whether the user owns or co-owns the room. Lookup errors
+// count as not owning it.
+func ownsRoom(r *http.Request, db *database.Queries, roomID, userID uuid.UUID) bool {
+    room, err := db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        return false
+    }
+    owner, err := service.IsRoomOwner(r.Context(), db, room, userID)
+    return err == nil && owner
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// RetentionHandler handles room retention policy endpoints.
+type RetentionHandler struct {
+    db        *database.Queries
+    retention *service.RetentionService
+}
+
+// NewRetentionHandler creates a new retention handler.
+func NewRetentionHandler(db *database.Queries, retention *service.RetentionService) *RetentionHandler {
+    return &RetentionHandler{db: db, retention: retention}
+}
+
+// SetRetention godoc
+// @Summary      Set a room's retention policy
+// @Description  Sets how long a room keeps its messages, by age and/or count. Older messages are purged by a background job and each purge is recorded in the audit log.
+// @Description  Room owners and co-owners can set the limits. Admins can set them for any room and can place a room on hold, which suspends purging and locks the policy against changes by the owner.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                   true   "Room ID"
+// @Param        If-Match  header    string                   false  "ETag of the room version being updated"
+// @Param        policy    body      service.RetentionPolicy  true   "Retention policy"
+// @Success      200     {object}  RoomResponse
+// @Header       200     {string}  ETag  "Version of the updated room"
+// @Failure      400     {string}  string "Invalid room ID or policy"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden"
+// @Failure      404     {string}  string "Room not found"
+// @Failure      409     {string}  string "Room was modified by someone else"
+// @Failure      500     {string}  string "Failed to set retention policy"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/retention [put]
+func (h *RetentionHandler) SetRetention(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+    if expectedVersion != nil && *expectedVersion != room.Version {
+        http.Error(w, "Conflict: The room was modified by someone else", http.StatusConflict)
+        return
+    }
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var policy service.RetentionPolicy
+    if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID)
+    if err != nil {
+        log.Printf("Failed to check room ownership: %v", err)
+        http.Error(w, "Failed to set retention policy", http.StatusInternalServerError)
+        return
+    }
+    if !user.IsAdmin {
+        switch {
+        case !owner:
+            http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+            return
+        case room.RetentionHold:
+            http.Error(w, "Forbidden: Retention is on hold by an administrator", http.StatusForbidden)
+            return
+        case policy.Hold:
+            http.Error(w, "Forbidden: Only administrators can place retention on hold", http.StatusForbidden)
+            return
+        }
+    }
+
+    room, err = h.retention.SetPolicy(r.Context(), room, userID, policy)
+    if errors.Is(err, service.ErrInvalidRetention) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if errors.Is(err, service.ErrVersionConflict) {
+        http.Error(w, "Conflict: The room was modified by someone else", http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to set retention policy: %v", err)
+        http.Error(w, "Failed to set retention policy", http.StatusInternalServerError)
+        return
+    }
+
+    setETag(w, room.Version)
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toRoomResponse(room))
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// MemberRoleRequest defines the request body for changing a member's role.
+type MemberRoleRequest struct {
+    // Role is owner, moderator or member.
+    Role string `json:"role" example:"moderator"`
+}
+
+// SetMemberRole godoc
+// @Summary      Change a member's role
+// @Description  Promotes or demotes a member of the room. Owners have every right over the room; moderators can also edit its settings, bulk-delete its messages and see its reports; members have no extra rights. Only room owners can change roles, and the owner the room was created by always stays an owner.
+// @Tags         rooms
+// @Accept       json
+// @Param        id      path      string             true  "Room ID"
+// @Param        userID  path      string             true  "Member's user ID"
+// @Param        role    body      MemberRoleRequest  true  "New role"
+// @Success      204     {string}  string "No Content"
+// @Failure      400     {string}  string "Invalid room ID, user ID or role"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404     {string}  string "Room not found or user is not a member of this room"
+// @Failure      500     {string}  string "Failed to change role"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/members/{userID}/role [put]
+func (h *RoomHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+    memberID, err := uuid.Parse(chi.URLParam(r, "userID"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req MemberRoleRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if !service.ValidRoomRole(req.Role) {
+        http.Error(w, "role must be owner, moderator or member", http.StatusBadRequest)
+        return
+    }
+    if memberID == room.OwnerID {
+        http.Error(w, "Forbidden: The room's owner cannot change role", http.StatusForbidden)
+        return
+    }
+
+    updated, err := h.db.SetRoomMemberRole(r.Context(), database.SetRoomMemberRoleParams{RoomID: room.ID, UserID: memberID, Role: req.Role})
+    if err != nil {
+        log.Printf("Failed to change role: %v", err)
+        http.Error(w, "Failed to change role", http.StatusInternalServerError)
+        return
+    }
+    if updated == 0 {
+        http.Error(w, "User is not a member of this room", http.StatusNotFound)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -1,32 +1,83 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mxhdiqaim/go-chat-app/internal/database"
 	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
 )
 
 // RoomHandler handles requests related to chat rooms
 type RoomHandler struct {
     db *database.Queries
     pool *pgxpool.Pool
+    webhooks *service.WebhookService
+    invites *service.InviteService
+    hub *service.Hub
+    storage service.Storage
 }
 
 // NewRoomHandler creates a new room handler
-func NewRoomHandler(db *database.Queries, pool *pgxpool.Pool) *RoomHandler {
-    return &RoomHandler{db: db, pool: pool}
+func NewRoomHandler(db *database.Queries, pool *pgxpool.Pool, webhooks *service.WebhookService, invites *service.InviteService, hub *service.Hub, storage service.Storage) *RoomHandler {
+    return &RoomHandler{db: db, pool: pool, webhooks: webhooks, invites: invites, hub: hub, storage: storage}
 }
 
+// Room visibilities; see service.RoomVisibilityPublic.
+const (
+    RoomVisibilityPublic  = service.RoomVisibilityPublic
+    RoomVisibilityPrivate = service.RoomVisibilityPrivate
+)
+
+// Room list sorts. Every sort lists the highest value first.
+const (
+    roomSortCreatedAt    = "created_at"
+    roomSortLastActivity = "last_activity"
+    roomSortMemberCount  = "member_count"
+    // roomSortMessageCount is only offered by the room directory.
+    roomSortMessageCount = "message_count"
+)
+
+// Length limits of a room's topic and description, in characters.
+const (
+    maxRoomTopicLength       = 250
+    maxRoomDescriptionLength = 4000
+)
+
 // CreateRoomRequest defines the request body for creating a room.
 type CreateRoomRequest struct {
     Name string `json:"name" example:"General"`
+    // Visibility is "public" (the default) or "private". Only room creation
+    // reads it; change it later through the room settings.
+    Visibility string `json:"visibility,omitempty" example:"public"`
+    // ExpiresInMinutes makes the room temporary: it is deleted, with
+    // everything in it, that many minutes after it is created, at most 30
+    // days. Rooms last until deleted by default.
+    ExpiresInMinutes int `json:"expires_in_minutes,omitempty" example:"60"`
+}
+
+// UpdateRoomRequest defines the request body for updating a room. Omitted
+// fields keep their current value; an empty topic or description clears it.
+type UpdateRoomRequest struct {
+    Name *string `json:"name,omitempty" example:"General"`
+    // Topic is a one-line summary of what the room is about, at most 250
+    // characters.
+    Topic *string `json:"topic,omitempty" example:"Release planning for v2"`
+    // Description explains the room, at most 4000 characters.
+    Description *string `json:"description,omitempty" example:"Everything about the project that is not a bug report."`
 }
 
 // RoomResponse defines the public shape of a room object.
@@ -35,17 +86,99 @@ type RoomResponse struct {
     Name      string    `json:"name" example:"General"`
     OwnerID   uuid.UUID `json:"owner_id" example:"b1c2d3e4-f5g6-7890-1234-567890abcdef"`
     CreatedAt time.Time `json:"created_at" example:"2025-09-03T12:00:00Z"`
+    // Version increases with every change; it is also sent as the ETag.
+    Version int32 `json:"version" example:"1"`
+    // MaxMessageSize is the room's own message size limit in bytes; absent
+    // when the server-wide limit applies.
+    MaxMessageSize *int32 `json:"max_message_size,omitempty" example:"2048"`
+    // AllowUrgent reports whether members may send urgent messages.
+    AllowUrgent bool `json:"allow_urgent" example:"false"`
+    // Retention is the room's message retention policy; absent when messages
+    // are kept forever.
+    Retention *service.RetentionPolicy `json:"retention,omitempty"`
+    // ArchivedAt is set once the room has been archived because its owner
+    // deleted their account and nobody was left to inherit it. Archived rooms
+    // cannot be joined.
+    ArchivedAt *time.Time `json:"archived_at,omitempty" example:"2025-09-03T12:00:00Z"`
+    // Visibility is "public" or "private".
+    Visibility string `json:"visibility" example:"public"`
+    // Kind is "room", or "group_dm" for group conversations.
+    Kind string `json:"kind" example:"room"`
+    // StatsEnabled reports whether the room's engagement stats are computed.
+    StatsEnabled bool `json:"stats_enabled" example:"false"`
+    // SummariesEnabled reports whether members can ask for catch-up summaries.
+    SummariesEnabled bool   `json:"summaries_enabled" example:"false"`
+    // PublicReadable reports whether anyone can read the room's recent
+    // messages without signing in.
+    PublicReadable bool `json:"public_readable" example:"false"`
+    Topic            string `json:"topic" example:"Release planning for v2"`
+    Description      string `json:"description" example:"Everything about the project that is not a bug report."`
+    // RoomMentionRole is the lowest role allowed to use @room and @here.
+    RoomMentionRole string `json:"room_mention_role" example:"moderator"`
+    // AvatarURL is absent until an avatar is uploaded.
+    AvatarURL *string `json:"avatar_url,omitempty" example:"http://localhost:8080/uploads/rooms/a1b2c3d4-e5f6-7890-1234-567890abcdef/avatar-1.png"`
+    // Tags are the room's categories, set in room listings and when fetching
+    // a single room.
+    Tags []string `json:"tags,omitempty" example:"gaming,golang"`
+    // LastMessageAt is when the latest message was sent; absent until the
+    // room has one.
+    LastMessageAt *time.Time `json:"last_message_at,omitempty" example:"2025-09-03T12:00:00Z"`
+    // ExpiresAt is when a temporary room is deleted; absent for rooms that
+    // last until deleted.
+    ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-09-03T13:00:00Z"`
+    // Encrypted is set on group conversations that opted in to end-to-end
+    // encryption; only encrypted frames are accepted in them.
+    Encrypted bool `json:"encrypted,omitempty"`
+    // Announcement is the room's pinned announcement, only set when fetching
+    // a single room that has one.
+    Announcement *service.Announcement `json:"announcement,omitempty"`
+    // MemberCount and LastActivityAt are only set in room listings.
+    // LastActivityAt is LastMessageAt, or when the room was created if it has
+    // no messages.
+    MemberCount    *int64     `json:"member_count,omitempty" example:"12"`
+    LastActivityAt *time.Time `json:"last_activity_at,omitempty" example:"2025-09-03T12:00:00Z"`
+}
+
+// RoomSettingsRequest defines the request body for updating room settings.
+type RoomSettingsRequest struct {
+    // MaxMessageSize overrides the server-wide message size limit in bytes.
+    // null restores the server-wide limit.
+    MaxMessageSize *int32 `json:"max_message_size" example:"2048"`
+    // AllowUrgent lets members send urgent messages, up to a daily limit per
+    // user. Owners and co-owners can always send them.
+    AllowUrgent bool `json:"allow_urgent" example:"false"`
+    // Visibility is "public" or "private"; omit it to keep the current one.
+    Visibility string `json:"visibility,omitempty" example:"private"`
+    // StatsEnabled opts the room in to engagement stats, which members can
+    // read at /rooms/{id}/stats.
+    StatsEnabled bool `json:"stats_enabled" example:"false"`
+    // SummariesEnabled opts the room in to catch-up summaries at
+    // /rooms/{id}/summary, which send its messages to the server's
+    // summarization provider.
+    SummariesEnabled bool `json:"summaries_enabled" example:"false"`
+    // PublicReadable serves the room's recent messages to anyone, without
+    // authentication, at /public/rooms/{id}/messages. Only public rooms can
+    // be public-readable.
+    PublicReadable bool `json:"public_readable" example:"false"`
+    // RoomMentionRole is the lowest role allowed to use @room and @here:
+    // "member", "moderator" or "owner". Omit it to keep the current one.
+    RoomMentionRole string `json:"room_mention_role,omitempty" example:"moderator"`
+}
+
+// validVisibility reports whether v names a room visibility.
+func validVisibility(v string) bool {
+    return v == RoomVisibilityPublic || v == RoomVisibilityPrivate
 }
 
 // CreateRoom godoc
 // @Summary      Create a new room
-// @Description  Creates a new chat room. The authenticated user becomes the owner.
+// @Description  Creates a new chat room. The authenticated user becomes the owner. Rooms are public unless visibility is "private". With expires_in_minutes the room is temporary: it is deleted, along with everything in it, once expires_at passes, and live connections to it are closed with reason "room.deleted".
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
-// @Param        room  body      CreateRoomRequest  true  "Room Name"
+// @Param        room  body      CreateRoomRequest  true  "Room name and visibility"
 // @Success      201   {object}  RoomResponse
-// @Failure      400   {string}  string "Invalid request body"
+// @Failure      400   {string}  string "Invalid request body, visibility or expires_in_minutes"
 // @Failure      401   {string}  string "User not authenticated"
 // @Failure      500   {string}  string "Failed to create room"
 // @Security     ApiKeyAuth
@@ -63,10 +196,8 @@ func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    // Decode the room name from the request body.
-    var req struct {
-        Name string `json:"name"`
-    }
+    // Decode the room name and visibility from the request body.
+    var req CreateRoomRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
         http.Error(w, "Invalid request body", http.StatusBadRequest)
         return
@@ -75,12 +206,28 @@ func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Room name is required", http.StatusBadRequest)
         return
     }
+    if req.Visibility == "" {
+        req.Visibility = RoomVisibilityPublic
+    }
+    if !validVisibility(req.Visibility) {
+        http.Error(w, "visibility must be public or private", http.StatusBadRequest)
+        return
+    }
+    if req.ExpiresInMinutes < 0 || req.ExpiresInMinutes > int(service.MaxRoomTTL/time.Minute) {
+        http.Error(w, "expires_in_minutes must be between 1 and 43200", http.StatusBadRequest)
+        return
+    }
 
     // Call the database to create the room with a NEW UUID.
     params := database.CreateRoomParams{
-        ID:      uuid.New(),
-        Name:    req.Name,
-        OwnerID: ownerID,
+        ID:         uuid.New(),
+        Name:       req.Name,
+        OwnerID:    ownerID,
+        Visibility: req.Visibility,
+    }
+    if req.ExpiresInMinutes > 0 {
+        expiresAt := time.Now().Add(time.Duration(req.ExpiresInMinutes) * time.Minute)
+        params.ExpiresAt = &expiresAt
     }
 
     room, err := h.db.CreateRoom(r.Context(), params)
@@ -93,47 +240,184 @@ func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
     // Respond with the newly created room.
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusCreated)
-    json.NewEncoder(w).Encode(room)
+    json.NewEncoder(w).Encode(toRoomResponse(room))
 }
 
 // GetRooms godoc
-// @Summary      Get all rooms
-// @Description  Retrieves a list of all available chat rooms.
+// @Summary      List rooms
+// @Description  Retrieves a page of the chat rooms visible to the current user. Private rooms are only listed to their owners and members. Pass the X-Next-Cursor response header back as cursor, with the same sort, to fetch the next page; it is absent on the last page.
+// @Description  Rooms are sorted newest first by default, or by most recent message (last_activity) or most members (member_count). Each room includes its member_count and last_activity_at.
 // @Tags         rooms
 // @Produce      json
+// @Param        limit       query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor      query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Param        sort        query     string   false  "Sort order"  Enums(created_at, last_activity, member_count)
+// @Param        owned_by    query     string   false  "Only rooms owned by this user ID, or me"
+// @Param        member_of   query     string   false  "Only rooms the current user is a member of; me or the current user's ID"
+// @Param        visibility  query     string   false  "Only rooms with this visibility"  Enums(public, private)
+// @Param        tag         query     string   false  "Only rooms with this tag"
 // @Success      200  {array}   RoomResponse
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {string}  string "Invalid query parameters"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You can only list your own memberships"
 // @Failure      500  {string}  string "Failed to get rooms"
+// @Security     ApiKeyAuth
 // @Router       /rooms [get]
 func (h *RoomHandler) GetRooms(w http.ResponseWriter, r *http.Request) {
-    rooms, err := h.db.GetRooms(r.Context())
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+    query := r.URL.Query()
+
+    limit, err := parseLimit(r)
     if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    // Fetch one extra row to know whether there is a next page.
+    params := database.ListRoomsParams{UserID: userID, Sort: roomSortCreatedAt, MaxResults: limit + 1}
+
+    switch v := query.Get("sort"); v {
+    case "":
+    case roomSortCreatedAt, roomSortLastActivity, roomSortMemberCount:
+        params.Sort = v
+    default:
+        http.Error(w, "Invalid sort, expected created_at, last_activity or member_count", http.StatusBadRequest)
+        return
+    }
+    if v := query.Get("owned_by"); v != "" {
+        ownerID, err := parseUserFilter(v, userID)
+        if err != nil {
+            http.Error(w, "Invalid owned_by user ID", http.StatusBadRequest)
+            return
+        }
+        params.OwnedBy = &ownerID
+    }
+    if v := query.Get("member_of"); v != "" {
+        memberID, err := parseUserFilter(v, userID)
+        if err != nil {
+            http.Error(w, "Invalid member_of user ID", http.StatusBadRequest)
+            return
+        }
+        // Memberships of public rooms are only shown to their members.
+        if memberID != userID {
+            http.Error(w, "Forbidden: You can only list your own memberships", http.StatusForbidden)
+            return
+        }
+        params.MemberOf = &memberID
+    }
+    if v := query.Get("visibility"); v != "" {
+        if !validVisibility(v) {
+            http.Error(w, "Invalid visibility, expected public or private", http.StatusBadRequest)
+            return
+        }
+        params.Visibility = &v
+    }
+    if v := query.Get("tag"); v != "" {
+        tag, err := service.NormalizeRoomTag(v)
+        if err != nil {
+            http.Error(w, "Invalid tag", http.StatusBadRequest)
+            return
+        }
+        params.Tag = &tag
+    }
+    if v := query.Get("cursor"); v != "" {
+        cursor, err := decodeRoomCursor(v, params.Sort)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        params.CursorID = &cursor.ID
+        params.CursorTime = &cursor.Time
+        params.CursorCount = &cursor.Count
+    }
+
+    rows, err := h.db.ListRooms(r.Context(), params)
+    if err != nil {
+        log.Printf("Failed to get rooms: %v", err)
         http.Error(w, "Failed to get rooms", http.StatusInternalServerError)
         return
     }
 
-     // Convert database models to response DTOs
-    var responses []RoomResponse
-    for _, room := range rooms {
-        responses = append(responses, RoomResponse{
-            ID:        room.ID,
-            Name:      room.Name,
-            OwnerID:   room.OwnerID,
-            CreatedAt: room.CreatedAt.Time,
-        })
+    if len(rows) > int(limit) {
+        rows = rows[:limit]
+        last := rows[len(rows)-1]
+        cursor := roomCursor{Sort: params.Sort, Time: last.Room.CreatedAt, Count: last.MemberCount, ID: last.Room.ID}
+        if params.Sort == roomSortLastActivity {
+            cursor.Time = last.LastActivityAt
+        }
+        w.Header().Set(nextCursorHeader, cursor.encode())
     }
+
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(responses)
+    json.NewEncoder(w).Encode(toRoomListResponses(rows))
+}
+
+// SearchRooms godoc
+// @Summary      Search rooms
+// @Description  Finds the rooms visible to the current user whose names contain the query or are similar to it, or whose descriptions contain it. Name matches come first, best match first. Private rooms are only found by their owners and members.
+// @Tags         rooms
+// @Produce      json
+// @Param        q      query     string   true   "Search text"
+// @Param        limit  query     integer  false  "Maximum number of rooms (default 50, max 200)"
+// @Success      200  {array}   RoomResponse
+// @Failure      400  {string}  string "Query parameter 'q' is required, or invalid limit"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to search rooms"
+// @Security     ApiKeyAuth
+// @Router       /rooms/search [get]
+func (h *RoomHandler) SearchRooms(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    q := strings.TrimSpace(r.URL.Query().Get("q"))
+    if q == "" {
+        http.Error(w, "Query parameter 'q' is required", http.StatusBadRequest)
+        return
+    }
+    limit, err := parseLimit(r)
+    if err != nil {
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
+        return
+    }
+
+    rooms, err := h.db.SearchRooms(r.Context(), database.SearchRoomsParams{UserID: userID, Pattern: service.ContainsPattern(q), Q: q, MaxResults: limit})
+    if err != nil {
+        log.Printf("Failed to search rooms: %v", err)
+        http.Error(w, "Failed to search rooms", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toRoomResponses(rooms))
+}
+
+// parseUserFilter parses a user ID query filter, which may also be "me" for
+// the current user.
+func parseUserFilter(v string, userID uuid.UUID) (uuid.UUID, error) {
+    if v == "me" {
+        return userID, nil
+    }
+    return uuid.Parse(v)
 }
 
 // GetRoomByID godoc
 // @Summary      Get a single room by ID
-// @Description  Retrieves details for a specific chat room.
+// @Description  Retrieves details for a specific chat room, with its tags and its announcement, if it has one that has not expired. Group conversations are only visible to their participants.
 // @Tags         rooms
 // @Produce      json
 // @Param        id  path      string  true  "Room ID"
 // @Success      200 {object}  RoomResponse
 // @Failure      400 {string}  string "Invalid room ID"
 // @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get room"
 // @Router       /rooms/{id} [get]
 func (h *RoomHandler) GetRoomByID(w http.ResponseWriter, r *http.Request) {
     roomIDParam := chi.URLParam(r, "id")
@@ -148,35 +432,57 @@ func (h *RoomHandler) GetRoomByID(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Room not found", http.StatusNotFound)
         return
     }
-
-    response := RoomResponse{
-        ID:        room.ID,
-        Name:      room.Name,
-        OwnerID:   room.OwnerID,
-        CreatedAt: room.CreatedAt.Time,
+    // Group conversations are only visible to their participants.
+    if room.Kind == service.RoomKindGroupDM {
+        userID, _ := authUserID(r)
+        isMember, err := h.db.IsRoomMember(r.Context(), database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+        if err != nil || !isMember {
+            http.Error(w, "Room not found", http.StatusNotFound)
+            return
+        }
+    }
+    tags, err := h.db.GetRoomTags(r.Context(), roomID)
+    if err != nil {
+        log.Printf("Failed to get room tags: %v", err)
+        http.Error(w, "Failed to get room", http.StatusInternalServerError)
+        return
+    }
+    announcement, err := service.RoomAnnouncement(r.Context(), h.db, roomID)
+    if err != nil {
+        log.Printf("Failed to get room announcement: %v", err)
+        http.Error(w, "Failed to get room", http.StatusInternalServerError)
+        return
     }
 
-    
+    response := toRoomResponse(room)
+    response.Tags = tags
+    response.Announcement = announcement
+    setETag(w, room.Version)
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
 }
 
 // UpdateRoom godoc
 // @Summary      Update a room
-// @Description  Updates the name of a room. Only the room owner can perform this action.
+// @Description  Updates the name, topic or description of a room; omitted fields are left as they are. Only room owners and moderators can perform this action.
+// @Description  Send the room's ETag in If-Match to update only if nobody else changed the room since it was read. Members connected to the room receive a room.updated event with its new details.
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string             true  "Room ID"
-// @Param        room  body      CreateRoomRequest  true  "New Room Name"
+// @Param        id        path      string             true   "Room ID"
+// @Param        If-Match  header    string             false  "ETag of the room version being updated"
+// @Param        room      body      UpdateRoomRequest  true   "New name, topic or description"
 // @Success      200   {object}  RoomResponse
-// @Failure      400   {string}  string "Invalid room ID or request body"
+// @Header       200   {string}  ETag  "Version of the updated room"
+// @Failure      400   {string}  string "Invalid room ID or request body, empty name, or topic or description too long"
 // @Failure      401   {string}  string "User not authenticated"
-// @Failure      403   {string}  string "Forbidden: You are not the owner"
+// @Failure      403   {string}  string "Forbidden: You are not a moderator of this room"
 // @Failure      404   {string}  string "Room not found"
+// @Failure      409   {string}  string "Room was modified by someone else"
 // @Failure      500   {string}  string "Failed to update room"
 // @Security     ApiKeyAuth
 // @Router       /rooms/{id} [put]
+// @Router       /rooms/{id} [patch]
 func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
     roomIDParam := chi.URLParam(r, "id")
     roomID, err := uuid.Parse(roomIDParam)
@@ -186,54 +492,73 @@ func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
     }
 
     // Get user ID from the JWT token in the context
-    userID, ok := r.Context().Value(middleware.ContextUserIDKey).(string)
+    userID, ok := authUserID(r)
     if !ok {
         http.Error(w, "User not authenticated", http.StatusUnauthorized)
         return
     }
     
-    // Check if the authenticated user is the room owner
+    // Check if the authenticated user owns the room
     room, err := h.db.GetRoomByID(r.Context(), roomID)
     if err != nil {
         http.Error(w, "Room not found", http.StatusNotFound)
         return
     }
 
-    if room.OwnerID.String() != userID {
-        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+    if moderator, err := service.CanModerateRoom(r.Context(), h.db, room, userID); err != nil || !moderator {
+        http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
         return
     }
 
-    var req CreateRoomRequest
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+
+    var req UpdateRoomRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
         http.Error(w, "Invalid request body", http.StatusBadRequest)
         return
     }
+    if req.Name != nil && *req.Name == "" {
+        http.Error(w, "Room name cannot be empty", http.StatusBadRequest)
+        return
+    }
+    if req.Topic != nil && utf8.RuneCountInString(*req.Topic) > maxRoomTopicLength {
+        http.Error(w, "topic must be at most 250 characters", http.StatusBadRequest)
+        return
+    }
+    if req.Description != nil && utf8.RuneCountInString(*req.Description) > maxRoomDescriptionLength {
+        http.Error(w, "description must be at most 4000 characters", http.StatusBadRequest)
+        return
+    }
 
     updatedRoom, err := h.db.UpdateRoom(r.Context(), database.UpdateRoomParams{
-        ID:   roomID,
-        Name: req.Name,
+        ID:              roomID,
+        Name:            req.Name,
+        Topic:           req.Topic,
+        Description:     req.Description,
+        ExpectedVersion: expectedVersion,
     })
-
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "Conflict: The room was modified by someone else", http.StatusConflict)
+        return
+    }
     if err != nil {
         http.Error(w, "Failed to update room", http.StatusInternalServerError)
         return
     }
+    h.hub.BroadcastRoomUpdate(updatedRoom, userID)
 
-    response := RoomResponse{
-        ID:        updatedRoom.ID,
-        Name:      updatedRoom.Name,
-        OwnerID:   updatedRoom.OwnerID,
-        CreatedAt: updatedRoom.CreatedAt.Time,
-    }
-
+    setETag(w, updatedRoom.Version)
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(response)
+    json.NewEncoder(w).Encode(toRoomResponse(updatedRoom))
 }
 
 // DeleteRoom godoc
 // @Summary      Delete a room
-// @Description  Deletes a room. Only the room owner can perform this action.
+// @Description  Deletes a room along with its pins, invitations, invite codes, messages and memberships, all at once. Live WebSocket connections to the room are closed with code 1001 and reason "room.deleted". Only room owners and co-owners can perform this action. Rooms under legal hold, or holding messages of a user who is, cannot be deleted until it is released.
 // @Tags         rooms
 // @Param        id  path      string  true  "Room ID"
 // @Success      204 {string}  string  "No Content"
@@ -241,6 +566,7 @@ func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 // @Failure      401 {string}  string  "User not authenticated"
 // @Failure      403 {string}  string  "Forbidden: You are not the owner"
 // @Failure      404 {string}  string  "Room not found"
+// @Failure      409 {string}  string  "The room's messages are under legal hold"
 // @Failure      500 {string}  string  "Failed to delete room"
 // @Security     ApiKeyAuth
 // @Router       /rooms/{id} [delete]
@@ -253,25 +579,31 @@ func (h *RoomHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
     }
 
     // Get user ID from the JWT token in the context
-    userID, ok := r.Context().Value(middleware.ContextUserIDKey).(string)
+    userID, ok := authUserID(r)
     if !ok {
         http.Error(w, "User not authenticated", http.StatusUnauthorized)
         return
     }
     
-    // Check if the authenticated user is the room owner
+    // Check if the authenticated user owns the room
     room, err := h.db.GetRoomByID(r.Context(), roomID)
     if err != nil {
         http.Error(w, "Room not found", http.StatusNotFound)
         return
     }
 
-    if room.OwnerID.String() != userID {
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
         http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
         return
     }
 
-    if err := h.db.DeleteRoom(r.Context(), roomID); err != nil {
+    err = service.DeleteRoom(r.Context(), h.db, h.pool, h.hub, roomID)
+    if errors.Is(err, service.ErrLegalHold) {
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to delete room %s: %v", roomID, err)
         http.Error(w, "Failed to delete room", http.StatusInternalServerError)
         return
     }
@@ -282,12 +614,16 @@ func (h *RoomHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
 
 // JoinRoom godoc
 // @Summary      Join a room
-// @Description  Adds the authenticated user to a room's member list.
+// @Description  Adds the authenticated user to a room's member list. Users invited to a private room join it straight away, which accepts their invitation; anyone else files a join request, answered with 202, which an owner or co-owner has to approve. Owners can also add members directly with /rooms/{id}/members/bulk.
 // @Tags         rooms
 // @Param        id  path      string  true  "Room ID to join"
+// @Success      202 {string}  string  "Join request sent"
 // @Success      204 {string}  string  "No Content"
 // @Failure      400 {string}  string  "Invalid room ID"
 // @Failure      401 {string}  string  "User not authenticated"
+// @Failure      403 {string}  string  "Forbidden: You are banned from this room"
+// @Failure      404 {string}  string  "Room not found"
+// @Failure      410 {string}  string  "Room is archived"
 // @Failure      500 {string}  string  "Failed to join room"
 // @Security     ApiKeyAuth
 // @Router       /rooms/{id}/join [post]
@@ -311,6 +647,30 @@ func (h *RoomHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    // Group conversations are joined by being added to them.
+    if err != nil || room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if room.ArchivedAt != nil {
+        http.Error(w, "Room is archived", http.StatusGone)
+        return
+    }
+    banned, err := h.db.IsRoomBanned(r.Context(), database.IsRoomBannedParams{RoomID: roomID, UserID: userUUID})
+    if err != nil {
+        http.Error(w, "Failed to join room", http.StatusInternalServerError)
+        return
+    }
+    if banned {
+        http.Error(w, "Forbidden: You are banned from this room", http.StatusForbidden)
+        return
+    }
+    if room.Visibility == RoomVisibilityPrivate {
+        h.requestToJoin(w, r, room, userUUID)
+        return
+    }
+
     err = h.db.AddRoomMember(r.Context(), database.AddRoomMemberParams{
         RoomID: roomID,
         UserID: userUUID,
@@ -319,6 +679,7 @@ func (h *RoomHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Failed to join room", http.StatusInternalServerError)
         return
     }
+    h.webhooks.Dispatch(roomID, service.WebhookEventJoin, service.MembershipChange{UserID: userID})
 
     w.WriteHeader(http.StatusNoContent)
 }
@@ -362,6 +723,277 @@ func (h *RoomHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Failed to leave room", http.StatusInternalServerError)
         return
     }
+    h.webhooks.Dispatch(roomID, service.WebhookEventLeave, service.MembershipChange{UserID: userID})
 
     w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+// maxBulkMembers caps how many users a single bulk membership request can touch.
+const maxBulkMembers = 500
+
+// BulkMembersRequest defines the request body for bulk membership changes.
+type BulkMembersRequest struct {
+    Add    []uuid.UUID `json:"add"`
+    Remove []uuid.UUID `json:"remove"`
+}
+
+// BulkMemberFailure describes a membership change that could not be applied.
+type BulkMemberFailure struct {
+    UserID uuid.UUID `json:"user_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+    Action string    `json:"action" example:"add"`
+    Reason string    `json:"reason" example:"user not found"`
+}
+
+// BulkMembersResponse reports the outcome of a bulk membership request.
+type BulkMembersResponse struct {
+    Added   []uuid.UUID         `json:"added"`
+    Removed []uuid.UUID         `json:"removed"`
+    Failed  []BulkMemberFailure `json:"failed"`
+}
+
+// BulkUpdateMembers godoc
+// @Summary      Add or remove many room members
+// @Description  Adds and removes many users at once. Only room owners and co-owners can perform this action. Changes are applied in one transaction; individual changes that cannot be applied (unknown user, already a member, banned, not a member) are reported in failed without affecting the rest.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "Room ID"
+// @Param        members  body      BulkMembersRequest  true  "Users to add and remove"
+// @Success      200      {object}  BulkMembersResponse
+// @Failure      400      {string}  string "Invalid room ID or request body"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404      {string}  string "Room not found"
+// @Failure      500      {string}  string "Failed to update members"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/members/bulk [post]
+func (h *RoomHandler) BulkUpdateMembers(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+        return
+    }
+
+    var req BulkMembersRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if len(req.Add)+len(req.Remove) > maxBulkMembers {
+        http.Error(w, fmt.Sprintf("At most %d members can be changed at once", maxBulkMembers), http.StatusBadRequest)
+        return
+    }
+
+    ctx := r.Context()
+    tx, err := h.pool.Begin(ctx)
+    if err != nil {
+        log.Printf("Failed to update members: %v", err)
+        http.Error(w, "Failed to update members", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback(ctx)
+
+    resp := BulkMembersResponse{Added: []uuid.UUID{}, Removed: []uuid.UUID{}, Failed: []BulkMemberFailure{}}
+    for _, memberID := range req.Add {
+        reason, err := addMemberSavepoint(ctx, tx, h.db, roomID, memberID)
+        if err != nil {
+            log.Printf("Failed to update members: %v", err)
+            http.Error(w, "Failed to update members", http.StatusInternalServerError)
+            return
+        }
+        if reason != "" {
+            resp.Failed = append(resp.Failed, BulkMemberFailure{UserID: memberID, Action: "add", Reason: reason})
+            continue
+        }
+        resp.Added = append(resp.Added, memberID)
+    }
+
+    qtx := h.db.WithTx(tx)
+    for _, memberID := range req.Remove {
+        if memberID == room.OwnerID {
+            resp.Failed = append(resp.Failed, BulkMemberFailure{UserID: memberID, Action: "remove", Reason: "cannot remove the room owner"})
+            continue
+        }
+        isMember, err := qtx.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: memberID})
+        if err == nil && isMember {
+            err = qtx.RemoveRoomMember(ctx, database.RemoveRoomMemberParams{RoomID: roomID, UserID: memberID})
+        }
+        if err != nil {
+            log.Printf("Failed to update members: %v", err)
+            http.Error(w, "Failed to update members", http.StatusInternalServerError)
+            return
+        }
+        if !isMember {
+            resp.Failed = append(resp.Failed, BulkMemberFailure{UserID: memberID, Action: "remove", Reason: "not a member"})
+            continue
+        }
+        resp.Removed = append(resp.Removed, memberID)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        log.Printf("Failed to update members: %v", err)
+        http.Error(w, "Failed to update members", http.StatusInternalServerError)
+        return
+    }
+    for _, memberID := range resp.Added {
+        h.webhooks.Dispatch(roomID, service.WebhookEventJoin, service.MembershipChange{UserID: memberID.String(), ActorID: userID.String()})
+    }
+    for _, memberID := range resp.Removed {
+        h.webhooks.Dispatch(roomID, service.WebhookEventLeave, service.MembershipChange{UserID: memberID.String(), ActorID: userID.String()})
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(resp)
+}
+
+// addMemberSavepoint adds a member inside a savepoint so a failed insert does
+// not abort the surrounding transaction. It returns the reason for expected
+// failures and an error for anything else.
+func addMemberSavepoint(ctx context.Context, tx pgx.Tx, db *database.Queries, roomID, userID uuid.UUID) (string, error) {
+    sp, err := tx.Begin(ctx)
+    if err != nil {
+        return "", err
+    }
+    defer sp.Rollback(ctx)
+
+    banned, err := db.WithTx(sp).IsRoomBanned(ctx, database.IsRoomBannedParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return "", err
+    }
+    if banned {
+        return "banned from this room", nil
+    }
+    err = db.WithTx(sp).AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: roomID, UserID: userID})
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) {
+        switch pgErr.Code {
+        case "23505": // unique_violation
+            return "already a member", nil
+        case "23503": // foreign_key_violation
+            return "user not found", nil
+        }
+    }
+    if err != nil {
+        return "", err
+    }
+    return "", sp.Commit(ctx)
+}
+
+// UpdateRoomSettings godoc
+// @Summary      Update room settings
+// @Description  Updates a room's settings. Only room owners and moderators can perform this action. New connections use the updated settings.
+// @Description  Send the room's ETag in If-Match to update only if nobody else changed the room since it was read.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string               true   "Room ID"
+// @Param        If-Match  header    string               false  "ETag of the room version being updated"
+// @Param        settings  body      RoomSettingsRequest  true   "Room settings"
+// @Success      200       {object}  RoomResponse
+// @Header       200       {string}  ETag  "Version of the updated room"
+// @Failure      400       {string}  string "Invalid room ID or settings"
+// @Failure      401       {string}  string "User not authenticated"
+// @Failure      403       {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404       {string}  string "Room not found"
+// @Failure      409       {string}  string "Room was modified by someone else"
+// @Failure      500       {string}  string "Failed to update room settings"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/settings [put]
+func (h *RoomHandler) UpdateRoomSettings(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if moderator, err := service.CanModerateRoom(r.Context(), h.db, room, userID); err != nil || !moderator {
+        http.Error(w, "Forbidden: You are not a moderator of this room", http.StatusForbidden)
+        return
+    }
+
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+
+    var req RoomSettingsRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if req.MaxMessageSize != nil && (*req.MaxMessageSize < 1 || *req.MaxMessageSize > service.MaxMessageSizeLimit) {
+        http.Error(w, fmt.Sprintf("max_message_size must be between 1 and %d bytes", service.MaxMessageSizeLimit), http.StatusBadRequest)
+        return
+    }
+
+    if req.Visibility == "" {
+        req.Visibility = room.Visibility
+    }
+    if !validVisibility(req.Visibility) {
+        http.Error(w, "visibility must be public or private", http.StatusBadRequest)
+        return
+    }
+    if req.PublicReadable && (req.Visibility != RoomVisibilityPublic || room.Kind != service.RoomKindRoom) {
+        http.Error(w, "public_readable requires a public room", http.StatusBadRequest)
+        return
+    }
+    if req.RoomMentionRole == "" {
+        req.RoomMentionRole = room.RoomMentionRole
+    }
+    if !service.ValidRoomRole(req.RoomMentionRole) {
+        http.Error(w, "room_mention_role must be member, moderator or owner", http.StatusBadRequest)
+        return
+    }
+
+    room, err = h.db.SetRoomSettings(r.Context(), database.SetRoomSettingsParams{
+        ID:               roomID,
+        MaxMessageSize:   req.MaxMessageSize,
+        AllowUrgent:      req.AllowUrgent,
+        Visibility:       req.Visibility,
+        StatsEnabled:     req.StatsEnabled,
+        RoomMentionRole:  req.RoomMentionRole,
+        SummariesEnabled: req.SummariesEnabled,
+        PublicReadable:   req.PublicReadable,
+        ExpectedVersion:  expectedVersion,
+    })
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "Conflict: The room was modified by someone else", http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to update room settings: %v", err)
+        http.Error(w, "Failed to update room settings", http.StatusInternalServerError)
+        return
+    }
+
+    setETag(w, room.Version)
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(toRoomResponse(room))
+}
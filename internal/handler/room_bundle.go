@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// maxRoomBundleSize is the largest room bundle an import accepts, in bytes.
+const maxRoomBundleSize = 64 << 20
+
+// RoomImportResponse describes the room an import created.
+type RoomImportResponse struct {
+    Room RoomResponse `json:"room"`
+    service.RoomImport
+}
+
+// RoomBundleHandler exports rooms as portable bundles and imports them.
+type RoomBundleHandler struct {
+    db      *database.Queries
+    bundles *service.RoomBundleService
+}
+
+// NewRoomBundleHandler creates a new room bundle handler.
+func NewRoomBundleHandler(db *database.Queries, bundles *service.RoomBundleService) *RoomBundleHandler {
+    return &RoomBundleHandler{db: db, bundles: bundles}
+}
+
+// ExportRoom godoc
+// @Summary      Export a room
+// @Description  Downloads the room's configuration (name, topic, description, visibility, settings, retention limits, tags and permissions) and members as a JSON bundle, for backup or to import it on another deployment with POST /rooms/import. With messages=true, the bundle also holds the room's latest 10000 text messages, oldest first; direct messages and polls are left out. Users are named by username, so the bundle does not depend on this server's IDs. The avatar is not included. Only room owners and co-owners can export a room.
+// @Tags         rooms
+// @Produce      json
+// @Param        id        path      string   true   "Room ID"
+// @Param        messages  query     boolean  false  "Include messages"
+// @Success      200       {object}  service.RoomBundle
+// @Failure      400       {string}  string "Invalid room ID or messages"
+// @Failure      401       {string}  string "User not authenticated"
+// @Failure      403       {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404       {string}  string "Room not found"
+// @Failure      500       {string}  string "Failed to export room"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/export [get]
+func (h *RoomBundleHandler) ExportRoom(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+    withMessages := false
+    switch r.URL.Query().Get("messages") {
+    case "", "false":
+    case "true":
+        withMessages = true
+    default:
+        http.Error(w, "Invalid messages: expected true or false", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil || room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+        return
+    }
+
+    bundle, err := h.bundles.Export(r.Context(), room, userID, withMessages)
+    if err != nil {
+        log.Printf("Failed to export room %s: %v", room.ID, err)
+        http.Error(w, "Failed to export room", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="room-%s.json"`, room.ID))
+    json.NewEncoder(w).Encode(bundle)
+}
+
+// ImportRoom godoc
+// @Summary      Import a room
+// @Description  Creates a new room, owned by the current user, from a bundle made by GET /rooms/{id}/export, all at once. Members and message senders are matched to this server's users by username. Members without an account here are skipped and listed in skipped_members; messages from senders without one are sent by the system bot. Imported messages are sent at the time of the import, each with {"imported": {"id", "sender", "sent_at"}} in its metadata recording the original, and count as read for the room's members. Bundles are limited to 64 MiB, 10000 members and 10000 messages.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        bundle  body      service.RoomBundle  true  "Room bundle"
+// @Success      201     {object}  RoomImportResponse
+// @Failure      400     {string}  string "Invalid request body or bundle"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      413     {string}  string "Bundle too large"
+// @Failure      500     {string}  string "Failed to import room"
+// @Security     ApiKeyAuth
+// @Router       /rooms/import [post]
+func (h *RoomBundleHandler) ImportRoom(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    r.Body = http.MaxBytesReader(w, r.Body, maxRoomBundleSize)
+    var bundle service.RoomBundle
+    if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+        var tooLarge *http.MaxBytesError
+        if errors.As(err, &tooLarge) {
+            http.Error(w, "Bundle too large", http.StatusRequestEntityTooLarge)
+            return
+        }
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if msg := checkBundleConfig(&bundle.Room); msg != "" {
+        http.Error(w, msg, http.StatusBadRequest)
+        return
+    }
+
+    imported, err := h.bundles.Import(r.Context(), userID, bundle)
+    if errors.Is(err, service.ErrInvalidRoomBundle) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to import room: %v", err)
+        http.Error(w, "Failed to import room", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(RoomImportResponse{Room: toRoomResponse(imported.Room), RoomImport: *imported})
+}
+
+// checkBundleConfig applies the limits rooms are created and updated with to
+// a bundled room's configuration, defaulting its visibility to public. It
+// returns what is wrong, or "" if nothing is.
+func checkBundleConfig(config *service.RoomBundleConfig) string {
+    if config.Name == "" {
+        return "Room name is required"
+    }
+    if utf8.RuneCountInString(config.Topic) > maxRoomTopicLength {
+        return "topic must be at most 250 characters"
+    }
+    if utf8.RuneCountInString(config.Description) > maxRoomDescriptionLength {
+        return "description must be at most 4000 characters"
+    }
+    if config.Visibility == "" {
+        config.Visibility = RoomVisibilityPublic
+    }
+    if !validVisibility(config.Visibility) {
+        return "visibility must be public or private"
+    }
+    if size := config.MaxMessageSize; size != nil && (*size < 1 || *size > service.MaxMessageSizeLimit) {
+        return fmt.Sprintf("max_message_size must be between 1 and %d bytes", service.MaxMessageSizeLimit)
+    }
+    return ""
+}
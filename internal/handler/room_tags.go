@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// RoomTagsRequest defines the request body for setting a room's tags.
+type RoomTagsRequest struct {
+    // Tags replace the room's current tags; an empty list removes them all.
+    // Tags are lowercased, and at most 10 are allowed.
+    Tags []string `json:"tags" example:"gaming,golang"`
+}
+
+// RoomTagsResponse lists a room's tags.
+type RoomTagsResponse struct {
+    Tags []string `json:"tags" example:"gaming,golang"`
+}
+
+// TagResponse is an entry of the room directory.
+type TagResponse struct {
+    Tag string `json:"tag" example:"golang"`
+    // RoomCount is how many of the rooms visible to the current user carry
+    // the tag.
+    RoomCount int64 `json:"room_count" example:"4"`
+}
+
+// SetRoomTags godoc
+// @Summary      Set a room's tags
+// @Description  Replaces the tags, or categories, of a room. Tags are 1-32 lowercase letters, digits or '-'; they are lowercased and deduplicated, and a room can have at most 10. List rooms with a tag with GET /rooms?tag=. Only room owners and moderators can perform this action.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string           true  "Room ID"
+// @Param        tags  body      RoomTagsRequest  true  "New tags"
+// @Success      200   {object}  RoomTagsResponse
+// @Failure      400   {string}  string "Invalid room ID, request body or tags"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: You are not a moderator of this room"
+// @Failure      404   {string}  string "Room not found"
+// @Failure      500   {string}  string "Failed to set room tags"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/tags [put]
+func (h *RoomHandler) SetRoomTags(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadModeratedRoom(w, r)
+    if !ok {
+        return
+    }
+    // Group conversations are not listed, so they have nothing to tag.
+    if room.Kind == service.RoomKindGroupDM {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+
+    var req RoomTagsRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tags == nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    tags, err := service.NormalizeRoomTags(req.Tags)
+    if errors.Is(err, service.ErrInvalidRoomTag) || errors.Is(err, service.ErrTooManyRoomTags) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    ctx := r.Context()
+    tx, err := h.pool.Begin(ctx)
+    if err != nil {
+        log.Printf("Failed to set room tags: %v", err)
+        http.Error(w, "Failed to set room tags", http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback(ctx)
+    qtx := h.db.WithTx(tx)
+
+    if err := qtx.DeleteRoomTags(ctx, room.ID); err != nil {
+        log.Printf("Failed to set room tags: %v", err)
+        http.Error(w, "Failed to set room tags", http.StatusInternalServerError)
+        return
+    }
+    if err := qtx.AddRoomTags(ctx, database.AddRoomTagsParams{RoomID: room.ID, Tags: tags}); err != nil {
+        log.Printf("Failed to set room tags: %v", err)
+        http.Error(w, "Failed to set room tags", http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(ctx); err != nil {
+        log.Printf("Failed to set room tags: %v", err)
+        http.Error(w, "Failed to set room tags", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(RoomTagsResponse{Tags: tags})
+}
+
+// GetTagDirectory godoc
+// @Summary      List room tags
+// @Description  Lists the tags of the rooms visible to the current user, with how many of those rooms carry each, most used first. Together with GET /rooms?tag= it makes a categorized room directory.
+// @Tags         rooms
+// @Produce      json
+// @Success      200  {array}   TagResponse
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get tags"
+// @Security     ApiKeyAuth
+// @Router       /rooms/tags [get]
+func (h *RoomHandler) GetTagDirectory(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    rows, err := h.db.GetTagDirectory(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get tags: %v", err)
+        http.Error(w, "Failed to get tags", http.StatusInternalServerError)
+        return
+    }
+
+    tags := make([]TagResponse, 0, len(rows))
+    for _, row := range rows {
+        tags = append(tags, TagResponse{Tag: row.Tag, RoomCount: row.RoomCount})
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tags)
+}
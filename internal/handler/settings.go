@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// SettingsHandler handles users' settings.
+type SettingsHandler struct {
+    settings *service.SettingsService
+}
+
+// NewSettingsHandler creates a new settings handler.
+func NewSettingsHandler(settings *service.SettingsService) *SettingsHandler {
+    return &SettingsHandler{settings: settings}
+}
+
+// GetSettings godoc
+// @Summary      Get my settings
+// @Description  Returns the current user's complete, effective notification, privacy and do-not-disturb configuration in one document, with defaults applied, so clients can set up their settings screens without calling each settings endpoint. Behavior users cannot change yet, such as which push notifications are sent, is reported as the server applies it.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  service.UserSettings
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get settings"
+// @Security     ApiKeyAuth
+// @Router       /users/me/settings [get]
+func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    settings, err := h.settings.Settings(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get settings: %v", err)
+        http.Error(w, "Failed to get settings", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(settings)
+}
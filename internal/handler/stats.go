@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// StatsHandler handles room engagement stats.
+type StatsHandler struct {
+    db    *database.Queries
+    stats *service.StatsService
+}
+
+// NewStatsHandler creates a new stats handler.
+func NewStatsHandler(db *database.Queries, stats *service.StatsService) *StatsHandler {
+    return &StatsHandler{db: db, stats: stats}
+}
+
+// GetRoomStats godoc
+// @Summary      Get a room's stats
+// @Description  Returns a room's engagement stats: messages, busiest hour and top senders over the last 30 days, and its current and longest daily streaks. Direct messages are not counted and bots are not ranked.
+// @Description  Rooms opt in through their settings (stats_enabled). The stats are recomputed by a background job every STATS_INTERVAL, so they can lag behind the room. Only members can see them.
+// @Tags         rooms
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {object}  service.RoomStats
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404 {string}  string "Room not found, stats not enabled or not computed yet"
+// @Failure      500 {string}  string "Failed to get room stats"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/stats [get]
+func (h *StatsHandler) GetRoomStats(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if role, err := service.RoomRole(r.Context(), h.db, room, userID); err != nil || role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+    if !room.StatsEnabled {
+        http.Error(w, "Stats are not enabled for this room", http.StatusNotFound)
+        return
+    }
+
+    stats, err := h.stats.Stats(r.Context(), room.ID)
+    if errors.Is(err, service.ErrStatsNotReady) {
+        http.Error(w, "Stats have not been computed yet", http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to get room stats: %v", err)
+        http.Error(w, "Failed to get room stats", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(stats)
+}
+
+// ExportRoomAnalytics godoc
+// @Summary      Export a room's analytics as CSV
+// @Description  Returns a CSV time series of a room's activity, one row per UTC day from from through to: date, messages sent and active members (members who sent a message, bots left out). Direct messages are not counted. The series is aggregated by the stats job every STATS_INTERVAL from the day the room opted in to stats (stats_enabled), going back up to a year, and outlives messages purged by retention; days before that are zero.
+// @Description  Defaults to the last 30 days; at most 366 days at once. Only room owners and co-owners can export.
+// @Tags         rooms
+// @Produce      text/csv
+// @Param        id    path      string  true   "Room ID"
+// @Param        from  query     string  false  "First day, YYYY-MM-DD (default 29 days before to)"
+// @Param        to    query     string  false  "Last day, YYYY-MM-DD (default today, UTC)"
+// @Success      200   {string}  string "date,messages,active_members"
+// @Failure      400   {string}  string "Invalid room ID or date range"
+// @Failure      401   {string}  string "User not authenticated"
+// @Failure      403   {string}  string "Forbidden: Only room owners can export analytics"
+// @Failure      404   {string}  string "Room not found or stats not enabled"
+// @Failure      500   {string}  string "Failed to export room analytics"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/analytics/export [get]
+func (h *StatsHandler) ExportRoomAnalytics(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    to := time.Now().UTC()
+    if v := r.URL.Query().Get("to"); v != "" {
+        if to, err = time.Parse(time.DateOnly, v); err != nil {
+            http.Error(w, "Invalid to: expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    from := to.AddDate(0, 0, -29)
+    if v := r.URL.Query().Get("from"); v != "" {
+        if from, err = time.Parse(time.DateOnly, v); err != nil {
+            http.Error(w, "Invalid from: expected YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+    }
+    if from.After(to) || to.Sub(from) >= service.MaxDailyStatsDays*24*time.Hour {
+        http.Error(w, "Invalid date range: from must not be after to, and at most 366 days apart", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: Only room owners can export analytics", http.StatusForbidden)
+        return
+    }
+    if !room.StatsEnabled {
+        http.Error(w, "Stats are not enabled for this room", http.StatusNotFound)
+        return
+    }
+
+    days, err := h.stats.DailyStats(r.Context(), room.ID, from, to)
+    if err != nil {
+        log.Printf("Failed to export room analytics: %v", err)
+        http.Error(w, "Failed to export room analytics", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="room-%s-analytics.csv"`, room.ID))
+    out := csv.NewWriter(w)
+    out.Write([]string{"date", "messages", "active_members"})
+    for _, day := range days {
+        out.Write([]string{
+            day.Day.Format(time.DateOnly),
+            strconv.FormatInt(day.Messages, 10),
+            strconv.Itoa(int(day.ActiveMembers)),
+        })
+    }
+    out.Flush()
+    if err := out.Error(); err != nil {
+        log.Printf("Failed to export room analytics: %v", err)
+    }
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// StatusRequest defines the request body for setting a custom status.
+type StatusRequest struct {
+    // Emoji is a single emoji or a known :shortcode:.
+    Emoji string `json:"emoji" example:"📅"`
+    // Text is up to 100 characters.
+    Text string `json:"text" example:"In a meeting"`
+    // ExpiresAt clears the status at that time; omit it to keep the status
+    // until it is changed.
+    ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-09-04T06:00:00Z"`
+}
+
+// StatusHandler handles users' custom statuses.
+type StatusHandler struct {
+    statuses *service.StatusService
+}
+
+// NewStatusHandler creates a new status handler.
+func NewStatusHandler(statuses *service.StatusService) *StatusHandler {
+    return &StatusHandler{statuses: statuses}
+}
+
+// SetStatus godoc
+// @Summary      Set my custom status
+// @Description  Sets the current user's custom status, an emoji and a short text shown beside their presence, replacing the current one, until expires_at or until it is changed. Send an empty emoji and text to clear it; the response is then null. Connected members of every room the user is a member of receive a presence.status event with it, and it is included in room member lists. No event is sent when it expires; clients hide it at its expires_at.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        status  body      StatusRequest  true  "Emoji, text and expiry"
+// @Success      200     {object}  service.Status
+// @Failure      400     {string}  string "Invalid request body, emoji, text or expiry"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      500     {string}  string "Failed to set status"
+// @Security     ApiKeyAuth
+// @Router       /users/me/status [put]
+func (h *StatusHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    var req StatusRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    emoji, text, err := service.NormalizeStatus(req.Emoji, req.Text, req.ExpiresAt)
+    if errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, service.ErrStatusExpiry) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    status, err := h.statuses.SetStatus(r.Context(), userID, emoji, text, req.ExpiresAt)
+    if err != nil {
+        log.Printf("Failed to set status: %v", err)
+        http.Error(w, "Failed to set status", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// SummaryHandler handles catch-up summaries of rooms.
+type SummaryHandler struct {
+    db        *database.Queries
+    summaries *service.SummaryService
+}
+
+// NewSummaryHandler creates a new summary handler.
+func NewSummaryHandler(db *database.Queries, summaries *service.SummaryService) *SummaryHandler {
+    return &SummaryHandler{db: db, summaries: summaries}
+}
+
+// GetRoomSummary godoc
+// @Summary      Summarize what I missed in a room
+// @Description  Sends the room's messages after since, or after the current user's read cursor when since is omitted, to the configured summarization provider and returns a short digest. At most 64 KiB of content is summarized at once; when to_seq is not the room's latest message, ask again with since=to_seq for the rest.
+// @Description  Summaries are off unless the server enables them (SUMMARIES_ENABLED) and the room opts in through its settings (summaries_enabled). Only members can ask for them. Direct messages are never sent to the provider, and only each message's sender username, content and time are. Summaries are cached in memory per span of messages and never stored.
+// @Tags         rooms
+// @Produce      json
+// @Param        id     path      string  true   "Room ID"
+// @Param        since  query     int     false  "Sequence number of the last message to leave out"
+// @Success      200    {object}  service.RoomSummary
+// @Failure      400    {string}  string "Invalid room ID or since"
+// @Failure      401    {string}  string "User not authenticated"
+// @Failure      403    {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404    {string}  string "Room not found or summaries not enabled"
+// @Failure      429    {string}  string "Too many requests"
+// @Failure      500    {string}  string "Failed to summarize room"
+// @Failure      502    {string}  string "Summarization provider failed"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/summary [get]
+func (h *SummaryHandler) GetRoomSummary(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    var since *int64
+    if v := r.URL.Query().Get("since"); v != "" {
+        seq, err := strconv.ParseInt(v, 10, 64)
+        if err != nil || seq < 0 {
+            http.Error(w, "Invalid since", http.StatusBadRequest)
+            return
+        }
+        since = &seq
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return
+    }
+    if role, err := service.RoomRole(r.Context(), h.db, room, userID); err != nil || role == "" {
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    }
+
+    summary, err := h.summaries.Summarize(r.Context(), room, userID, since)
+    switch {
+    case errors.Is(err, service.ErrSummariesDisabled), errors.Is(err, service.ErrRoomSummariesDisabled):
+        http.Error(w, "Summaries are not enabled for this room", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrNotRoomMember):
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    case errors.Is(err, service.ErrSummarizerFailed):
+        log.Printf("Failed to summarize room: %v", err)
+        http.Error(w, "Summarization provider failed", http.StatusBadGateway)
+        return
+    case err != nil:
+        log.Printf("Failed to summarize room: %v", err)
+        http.Error(w, "Failed to summarize room", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// UnreadHandler handles read cursors and unread counts.
+type UnreadHandler struct {
+    hub      *service.Hub
+    messages *service.MessageService
+}
+
+// NewUnreadHandler creates a new unread handler.
+func NewUnreadHandler(hub *service.Hub, messages *service.MessageService) *UnreadHandler {
+    return &UnreadHandler{hub: hub, messages: messages}
+}
+
+// GetUnreads godoc
+// @Summary      Get unread counts
+// @Description  Returns, for every room the current user is a member of, how many messages from others arrived after their read cursor and how many of those mention them.
+// @Description  Connected clients also receive "unread" frames with a room's updated counts whenever they change.
+// @Tags         messages
+// @Produce      json
+// @Success      200  {array}   service.Unread
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      500  {string}  string "Failed to get unread counts"
+// @Security     ApiKeyAuth
+// @Router       /users/me/unreads [get]
+func (h *UnreadHandler) GetUnreads(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    unreads, err := h.messages.Unreads(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to get unread counts: %v", err)
+        http.Error(w, "Failed to get unread counts", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(unreads)
+}
+
+// MarkRoomRead godoc
+// @Summary      Move a room's read marker
+// @Description  Moves the current user's read marker for a room forward to the message named by seq or message_id, or to the latest message when the body is omitted. The marker never moves back, and unread counts are counted from it.
+// @Description  Every WebSocket connection of the user, on any device and in any room, receives an "unread" frame with the new marker (last_read_seq) and counts, so devices stay in sync. Connected clients can send a "read" frame with the same payload instead. PUT /rooms/{id}/read is the older name of this endpoint.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string              true   "Room ID"
+// @Param        cursor  body      service.ReadCursor  false  "Sequence number or ID of the last message read"
+// @Success      200     {object}  service.Unread
+// @Failure      400     {string}  string "Invalid room ID or request body"
+// @Failure      401     {string}  string "User not authenticated"
+// @Failure      403     {string}  string "Forbidden: User is not a member of this room"
+// @Failure      404     {string}  string "Message not found in this room"
+// @Failure      500     {string}  string "Failed to mark room read"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/read-marker [put]
+// @Router       /rooms/{id}/read [put]
+func (h *UnreadHandler) MarkRoomRead(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return
+    }
+
+    var cursor service.ReadCursor
+    if err := json.NewDecoder(r.Body).Decode(&cursor); err != nil && err != io.EOF {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    unread, err := h.hub.MarkRead(r.Context(), roomID, userID, cursor)
+    switch {
+    case errors.Is(err, service.ErrInvalidReadCursor):
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    case errors.Is(err, service.ErrMessageNotFound):
+        http.Error(w, "Message not found in this room", http.StatusNotFound)
+        return
+    case errors.Is(err, service.ErrNotRoomMember):
+        http.Error(w, "Forbidden: User is not a member of this room", http.StatusForbidden)
+        return
+    case err != nil:
+        log.Printf("Failed to mark room read: %v", err)
+        http.Error(w, "Failed to mark room read", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(unread)
+}
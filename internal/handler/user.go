@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/mxhdiqaim/go-chat-app/internal/database"
 	"github.com/mxhdiqaim/go-chat-app/internal/middleware"
 	"github.com/mxhdiqaim/go-chat-app/internal/service"
@@ -14,12 +17,14 @@ import (
 
 // UserHandler handles user-related endpoints.
 type UserHandler struct {
-    db *database.Queries
+    db       *database.Queries
+    accounts *service.AccountService
+    warm     *service.WarmCache
 }
 
-// NewUserHandler creates a new user handler.
-func NewUserHandler(db *database.Queries) *UserHandler {
-    return &UserHandler{db: db}
+// NewUserHandler creates a new user handler. warm may be nil.
+func NewUserHandler(db *database.Queries, accounts *service.AccountService, warm *service.WarmCache) *UserHandler {
+    return &UserHandler{db: db, accounts: accounts, warm: warm}
 }
 
 // UpdateUserRequest defines the request body for updating a user.
@@ -28,34 +33,69 @@ type UpdateUserRequest struct {
     Password string `json:"password" example:"newpassword123"`
 }
 
-// GetAllUsers godoc
-// @Summary      Get all users
-// @Description  Retrieves a list of all users in the system.
+// ListUsers godoc
+// @Summary      List users
+// @Description  Retrieves a page of users ordered by creation time. Pass the X-Next-Cursor response header back as cursor to fetch the next page; it is absent on the last page.
 // @Tags         users
 // @Produce      json
+// @Param        limit          query     integer  false  "Page size (default 50, max 200)"
+// @Param        cursor         query     string   false  "Cursor from the previous page's X-Next-Cursor header"
+// @Param        q              query     string   false  "Filter by username substring"
+// @Param        created_after  query     string   false  "Only users created after this RFC3339 timestamp"
 // @Success      200  {array}   UserResponse
-// @Failure      500  {string}  string "Failed to get users"
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page"
+// @Failure      400  {string}  string "Invalid query parameters"
+// @Failure      429  {string}  string "Too many requests"
+// @Failure      500  {string}  string "Failed to list users"
 // @Router       /users [get]
-func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-    users, err := h.db.GetAllUsers(r.Context())
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+
+    limit, err := parseLimit(r)
     if err != nil {
-        http.Error(w, "Failed to get all users", http.StatusInternalServerError)
+        http.Error(w, "Invalid limit", http.StatusBadRequest)
         return
     }
 
-    // Convert database models to response DTOs
-    var responses []UserResponse
+    // Fetch one extra row to know whether there is a next page.
+    params := database.ListUsersParams{MaxResults: limit + 1}
+
+    if q := query.Get("q"); q != "" {
+        params.Q = &q
+    }
+    if v := query.Get("created_after"); v != "" {
+        createdAfter, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            http.Error(w, "Invalid created_after timestamp, expected RFC3339", http.StatusBadRequest)
+            return
+        }
+        params.CreatedAfter = &createdAfter
+    }
+    if v := query.Get("cursor"); v != "" {
+        cursor, err := decodeCursor(v)
+        if err != nil {
+            http.Error(w, "Invalid cursor", http.StatusBadRequest)
+            return
+        }
+        params.CursorCreatedAt = &cursor.CreatedAt
+        params.CursorID = &cursor.ID
+    }
+
+    users, err := h.db.ListUsers(r.Context(), params)
+    if err != nil {
+        log.Println("Failed to list users:", err)
+        http.Error(w, "Failed to list users", http.StatusInternalServerError)
+        return
+    }
 
-    for _, user := range users {
-        responses = append(responses, UserResponse{
-            ID:        user.ID,
-            Username:  user.Username,
-            CreatedAt: user.CreatedAt.Time,
-        })
+    if len(users) > int(limit) {
+        users = users[:limit]
+        last := users[len(users)-1]
+        w.Header().Set(nextCursorHeader, pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode())
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(responses)
+    json.NewEncoder(w).Encode(toUserResponses(users))
 }
 
 // GetUserByID godoc
@@ -82,14 +122,9 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    response := UserResponse{
-        ID:        user.ID,
-        Username:  user.Username,
-        CreatedAt: user.CreatedAt.Time,
-    }
-
+    setETag(w, user.Version)
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(response)
+    json.NewEncoder(w).Encode(toUserResponse(user))
 }
 
 // SearchUsers godoc
@@ -115,17 +150,8 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    var responses []UserResponse
-    for _, user := range users {
-        responses = append(responses, UserResponse{
-            ID:        user.ID,
-            Username:  user.Username,
-            CreatedAt: user.CreatedAt.Time,
-        })
-    }
-
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(responses)
+    json.NewEncoder(w).Encode(toUserResponses(users))
 }
 
 // UpdateUser godoc
@@ -134,12 +160,15 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string             true  "User ID"
-// @Param        user  body      UpdateUserRequest  true  "User data to update"
+// @Param        id        path      string             true   "User ID"
+// @Param        If-Match  header    string             false  "ETag of the profile version being updated"
+// @Param        user      body      UpdateUserRequest  true   "User data to update"
 // @Success      200   {object}  UserResponse
+// @Header       200   {string}  ETag  "Version of the updated profile"
 // @Failure      400   {string}  string "Invalid user ID or request body"
 // @Failure      401   {string}  string "User not authenticated"
 // @Failure      403   {string}  string "Forbidden: You can only update your own account"
+// @Failure      409   {string}  string "Profile was modified elsewhere"
 // @Failure      500   {string}  string "Failed to update user"
 // @Security     ApiKeyAuth
 // @Router       /users/{id} [put]
@@ -167,6 +196,12 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+
     var req UpdateUserRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
         http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -181,35 +216,40 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
     }
 
     updatedUser, err := h.db.UpdateUser(r.Context(), database.UpdateUserParams{
-        ID:       userID,
-        Username: req.Username,
-        Password: hashedPassword,
+        ID:              userID,
+        Username:        req.Username,
+        Password:        hashedPassword,
+        ExpectedVersion: expectedVersion,
     })
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "Conflict: The profile was modified elsewhere", http.StatusConflict)
+        return
+    }
     if err != nil {
         log.Println("Failed to update user:", err)
         http.Error(w, "Failed to update user", http.StatusInternalServerError)
         return
     }
 
-    response := UserResponse{
-        ID:        updatedUser.ID,
-        Username:  updatedUser.Username,
-        CreatedAt: updatedUser.CreatedAt.Time,
-    }
-
+    setETag(w, updatedUser.Version)
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(response)
+    json.NewEncoder(w).Encode(toUserResponse(updatedUser))
 }
 
 // DeleteUser godoc
 // @Summary      Delete a user's account
 // @Description  Deletes a user's account. Users can only delete their own account.
+// @Description  GET /users/{id}/deletion-report previews the effects, so clients can ask for an informed confirmation.
+// @Description  Rooms the user owns pass to their longest-standing co-owner, administrator or member, in that order, and the room gets a message from the system bot announcing the new owner. Rooms nobody is left to inherit are archived.
+// @Description  Accounts under legal hold, or with messages in a room that is, cannot be deleted until it is released.
 // @Tags         users
 // @Param        id  path      string  true  "User ID"
 // @Success      204 {string}  string  "No Content"
 // @Failure      400 {string}  string  "Invalid user ID"
 // @Failure      401 {string}  string  "User not authenticated"
 // @Failure      403 {string}  string  "Forbidden: You can only delete your own account"
+// @Failure      404 {string}  string  "User not found"
+// @Failure      409 {string}  string  "The account's messages are under legal hold"
 // @Failure      500 {string}  string  "Failed to delete user"
 // @Security     ApiKeyAuth
 // @Router       /users/{id} [delete]
@@ -237,11 +277,200 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    if err := h.db.DeleteUser(r.Context(), userID); err != nil {
+    err = h.accounts.DeleteUser(r.Context(), userID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    }
+    if errors.Is(err, service.ErrLegalHold) {
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    }
+    if err != nil {
         log.Println("Failed to delete user:", err)
         http.Error(w, "Failed to delete user", http.StatusInternalServerError)
         return
     }
 
     w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// GetDeletionReport godoc
+// @Summary      Preview the deletion of a user's account
+// @Description  Summarizes what deleting the account would remove and change: the rooms the user owns and who inherits each of them, how many messages and polls are deleted and how much storage they take. Nothing is changed. Users can only preview their own account.
+// @Tags         users
+// @Produce      json
+// @Param        id  path      string  true  "User ID"
+// @Success      200 {object}  service.DeletionReport
+// @Failure      400 {string}  string  "Invalid user ID"
+// @Failure      401 {string}  string  "User not authenticated"
+// @Failure      403 {string}  string  "Forbidden: You can only delete your own account"
+// @Failure      500 {string}  string  "Failed to build deletion report"
+// @Security     ApiKeyAuth
+// @Router       /users/{id}/deletion-report [get]
+func (h *UserHandler) GetDeletionReport(w http.ResponseWriter, r *http.Request) {
+    authID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    userID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+    if userID != authID {
+        http.Error(w, "Forbidden: You can only delete your own account", http.StatusForbidden)
+        return
+    }
+
+    report, err := h.accounts.DeletionReport(r.Context(), userID)
+    if err != nil {
+        log.Printf("Failed to build deletion report: %v", err)
+        http.Error(w, "Failed to build deletion report", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(report)
+}
+
+// PreferredLanguageRequest defines the request body for setting a user's preferred language.
+type PreferredLanguageRequest struct {
+    // Language is a language code such as "en" or "fr"; empty clears the preference.
+    Language string `json:"language" example:"fr"`
+}
+
+// SetPreferredLanguage godoc
+// @Summary      Set the preferred language
+// @Description  Sets the language that chat messages from other users are translated into. An empty language turns translation off.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        If-Match  header    string                    false  "ETag of the profile version being updated"
+// @Param        language  body      PreferredLanguageRequest  true   "Preferred language"
+// @Success      204       {string}  string "No Content"
+// @Header       204       {string}  ETag  "Version of the updated profile"
+// @Failure      400       {string}  string "Invalid request body"
+// @Failure      401       {string}  string "User not authenticated"
+// @Failure      409       {string}  string "Profile was modified elsewhere"
+// @Failure      500       {string}  string "Failed to set preferred language"
+// @Security     ApiKeyAuth
+// @Router       /users/me/language [put]
+func (h *UserHandler) SetPreferredLanguage(w http.ResponseWriter, r *http.Request) {
+    authUserID, ok := r.Context().Value(middleware.ContextUserIDKey).(string)
+    if !ok {
+        http.Error(w, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+    userID, err := uuid.Parse(authUserID)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+
+    var req PreferredLanguageRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    if len(req.Language) > 16 {
+        http.Error(w, "Invalid language code", http.StatusBadRequest)
+        return
+    }
+
+    var language *string
+    if req.Language != "" {
+        language = &req.Language
+    }
+
+    user, err := h.db.SetUserPreferredLanguage(r.Context(), database.SetUserPreferredLanguageParams{
+        ID:                userID,
+        PreferredLanguage: language,
+        ExpectedVersion:   expectedVersion,
+    })
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "Conflict: The profile was modified elsewhere", http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Println("Failed to set preferred language:", err)
+        http.Error(w, "Failed to set preferred language", http.StatusInternalServerError)
+        return
+    }
+    h.warm.DropUser(userID)
+
+    setETag(w, user.Version)
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// DisplayNameRequest defines the request body for setting a display name.
+type DisplayNameRequest struct {
+    // DisplayName is the user's real name, up to 64 characters; empty clears it.
+    DisplayName string `json:"display_name" example:"Ada Lovelace"`
+}
+
+// SetDisplayName godoc
+// @Summary      Set the display name
+// @Description  Sets the current user's display name, their real name as opposed to their username. Whether others see it next to the user's messages, in member lists and among conversation participants, instead of or beside the username, depends on the server's display name policy. An empty display name clears it.
+// @Tags         users
+// @Accept       json
+// @Param        If-Match      header    string              false  "ETag of the profile version being updated"
+// @Param        display_name  body      DisplayNameRequest  true   "Display name"
+// @Success      204           {string}  string "No Content"
+// @Header       204           {string}  ETag  "Version of the updated profile"
+// @Failure      400           {string}  string "Invalid request body or display name"
+// @Failure      401           {string}  string "User not authenticated"
+// @Failure      409           {string}  string "Profile was modified elsewhere"
+// @Failure      500           {string}  string "Failed to set display name"
+// @Security     ApiKeyAuth
+// @Router       /users/me/display-name [put]
+func (h *UserHandler) SetDisplayName(w http.ResponseWriter, r *http.Request) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+
+    expectedVersion, err := ifMatchVersion(r)
+    if err != nil {
+        http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+        return
+    }
+
+    var req DisplayNameRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    displayName, err := service.NormalizeDisplayName(req.DisplayName)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    user, err := h.db.SetUserDisplayName(r.Context(), database.SetUserDisplayNameParams{
+        ID:              userID,
+        DisplayName:     displayName,
+        ExpectedVersion: expectedVersion,
+    })
+    if errors.Is(err, pgx.ErrNoRows) {
+        http.Error(w, "Conflict: The profile was modified elsewhere", http.StatusConflict)
+        return
+    }
+    if err != nil {
+        log.Println("Failed to set display name:", err)
+        http.Error(w, "Failed to set display name", http.StatusInternalServerError)
+        return
+    }
+
+    setETag(w, user.Version)
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// WebhookHandler handles the outbound and incoming webhook endpoints of
+// rooms.
+type WebhookHandler struct {
+    db       *database.Queries
+    webhooks *service.WebhookService
+    incoming *service.IncomingWebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler.
+func NewWebhookHandler(db *database.Queries, webhooks *service.WebhookService, incoming *service.IncomingWebhookService) *WebhookHandler {
+    return &WebhookHandler{db: db, webhooks: webhooks, incoming: incoming}
+}
+
+// WebhookRequest defines the request body for creating or updating a webhook.
+type WebhookRequest struct {
+    URL string `json:"url" example:"https://example.com/hooks/chat"`
+    // Events are the event types to deliver: message, join, leave, ban and pin.
+    Events []string `json:"events" example:"join,leave"`
+}
+
+// CreateWebhook godoc
+// @Summary      Create a room webhook
+// @Description  Adds an outbound webhook to a room. Each event type it subscribes to is posted to its URL as JSON ({"id", "event", "room_id", "timestamp", "data"}) with the event type in X-Webhook-Event and an HMAC-SHA256 of the body, keyed with the webhook's secret, in X-Webhook-Signature ("sha256=<hex>"). Failed deliveries are retried up to 5 times with exponential backoff, keeping the same id.
+// @Description  Event types: message (new messages, not direct messages), join and leave (membership changes, kicks included), ban (bans), and pin (messages pinned and unpinned). The secret is only returned here. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string          true  "Room ID"
+// @Param        webhook  body      WebhookRequest  true  "Webhook URL and event types"
+// @Success      201      {object}  service.Webhook
+// @Failure      400      {string}  string "Invalid room ID, URL or events"
+// @Failure      401      {string}  string "User not authenticated"
+// @Failure      403      {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404      {string}  string "Room not found"
+// @Failure      500      {string}  string "Failed to create webhook"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+    room, userID, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    var req WebhookRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    webhook, err := h.webhooks.CreateWebhook(r.Context(), room.ID, userID, req.URL, req.Events)
+    if !writeWebhookError(w, err, "Failed to create webhook") {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(webhook)
+}
+
+// GetWebhooks godoc
+// @Summary      List a room's webhooks
+// @Description  Lists the webhooks of a room, oldest first, without their secrets. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id  path      string  true  "Room ID"
+// @Success      200 {array}   service.Webhook
+// @Failure      400 {string}  string "Invalid room ID"
+// @Failure      401 {string}  string "User not authenticated"
+// @Failure      403 {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404 {string}  string "Room not found"
+// @Failure      500 {string}  string "Failed to get webhooks"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return
+    }
+
+    webhooks, err := h.webhooks.ListWebhooks(r.Context(), room.ID)
+    if err != nil {
+        log.Printf("Failed to get webhooks: %v", err)
+        http.Error(w, "Failed to get webhooks", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(webhooks)
+}
+
+// UpdateWebhook godoc
+// @Summary      Update a room webhook
+// @Description  Replaces a webhook's URL and event types; its secret stays the same. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string          true  "Room ID"
+// @Param        webhookID  path      string          true  "Webhook ID"
+// @Param        webhook    body      WebhookRequest  true  "Webhook URL and event types"
+// @Success      200        {object}  service.Webhook
+// @Failure      400        {string}  string "Invalid ID, URL or events"
+// @Failure      401        {string}  string "User not authenticated"
+// @Failure      403        {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404        {string}  string "Room or webhook not found"
+// @Failure      500        {string}  string "Failed to update webhook"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/webhooks/{webhookID} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+    webhook, ok := h.loadWebhook(w, r)
+    if !ok {
+        return
+    }
+
+    var req WebhookRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    updated, err := h.webhooks.UpdateWebhook(r.Context(), webhook.ID, req.URL, req.Events)
+    if !writeWebhookError(w, err, "Failed to update webhook") {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a room webhook
+// @Description  Removes a webhook; nothing more is delivered to it. Only room owners and co-owners can manage webhooks.
+// @Tags         webhooks
+// @Param        id         path  string  true  "Room ID"
+// @Param        webhookID  path  string  true  "Webhook ID"
+// @Success      204
+// @Failure      400  {string}  string "Invalid ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: You are not the owner of this room"
+// @Failure      404  {string}  string "Room or webhook not found"
+// @Failure      500  {string}  string "Failed to delete webhook"
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/webhooks/{webhookID} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+    webhook, ok := h.loadWebhook(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.webhooks.DeleteWebhook(r.Context(), webhook.ID); err != nil {
+        log.Printf("Failed to delete webhook: %v", err)
+        http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnedRoom loads the room from the URL and checks that the authenticated
+// user owns it, writing the error response if not.
+func (h *WebhookHandler) loadOwnedRoom(w http.ResponseWriter, r *http.Request) (database.Room, uuid.UUID, bool) {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    roomID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid room ID", http.StatusBadRequest)
+        return database.Room{}, uuid.Nil, false
+    }
+
+    room, err := h.db.GetRoomByID(r.Context(), roomID)
+    if err != nil {
+        http.Error(w, "Room not found", http.StatusNotFound)
+        return database.Room{}, uuid.Nil, false
+    }
+    if owner, err := service.IsRoomOwner(r.Context(), h.db, room, userID); err != nil || !owner {
+        http.Error(w, "Forbidden: You are not the owner of this room", http.StatusForbidden)
+        return database.Room{}, uuid.Nil, false
+    }
+    return room, userID, true
+}
+
+// loadWebhook loads the webhook from the URL, checking that it belongs to a
+// room the authenticated user owns.
+func (h *WebhookHandler) loadWebhook(w http.ResponseWriter, r *http.Request) (database.RoomWebhook, bool) {
+    room, _, ok := h.loadOwnedRoom(w, r)
+    if !ok {
+        return database.RoomWebhook{}, false
+    }
+
+    webhookID, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+    if err != nil {
+        http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+        return database.RoomWebhook{}, false
+    }
+
+    webhook, err := h.webhooks.GetWebhook(r.Context(), webhookID)
+    if err != nil || webhook.RoomID != room.ID {
+        http.Error(w, "Webhook not found", http.StatusNotFound)
+        return database.RoomWebhook{}, false
+    }
+    return webhook, true
+}
+
+// writeWebhookError writes the response for a webhook service error and
+// reports whether the request may continue.
+func writeWebhookError(w http.ResponseWriter, err error, message string) bool {
+    switch {
+    case err == nil:
+        return true
+    case errors.Is(err, service.ErrInvalidWebhookURL), errors.Is(err, service.ErrInvalidWebhookEvents):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+    default:
+        log.Printf("%s: %v", message, err)
+        http.Error(w, message, http.StatusInternalServerError)
+    }
+    return false
+}
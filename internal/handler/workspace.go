@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// WorkspaceRequest defines the request body for registering or updating a
+// workspace.
+type WorkspaceRequest struct {
+    // Domain is the host name the workspace is served on, without a port.
+    Domain string `json:"domain" example:"chat.acme.com"`
+    // Name is up to 100 characters.
+    Name string `json:"name" example:"Acme Chat"`
+    // LogoURL is an http(s) URL; omit it for no logo.
+    LogoURL *string `json:"logo_url,omitempty" example:"https://cdn.acme.com/logo.png"`
+    // PrimaryColor and AccentColor are hex colors such as #1a73e8; omit them
+    // to leave colors to clients.
+    PrimaryColor *string `json:"primary_color,omitempty" example:"#1a73e8"`
+    AccentColor  *string `json:"accent_color,omitempty" example:"#fbbc04"`
+}
+
+// WorkspaceHandler serves workspaces' branding and lets administrators
+// manage workspaces.
+type WorkspaceHandler struct {
+    db         *database.Queries
+    workspaces *service.WorkspaceService
+}
+
+// NewWorkspaceHandler creates a new workspace handler.
+func NewWorkspaceHandler(db *database.Queries, workspaces *service.WorkspaceService) *WorkspaceHandler {
+    return &WorkspaceHandler{db: db, workspaces: workspaces}
+}
+
+// Route finds the workspace whose domain a request was made to, by its Host
+// header, and marks the request as made to it. Requests to other hosts pass
+// straight through, as do all requests when workspaces cannot be read.
+func (h *WorkspaceHandler) Route(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        workspace, err := h.workspaces.ForHost(r.Context(), r.Host)
+        if err != nil {
+            log.Printf("Failed to find the workspace of %s: %v", r.Host, err)
+        }
+        if workspace != nil {
+            r = r.WithContext(service.WithWorkspace(r.Context(), workspace))
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// GetBranding godoc
+// @Summary      Get branding
+// @Description  Returns the name, logo and colors to show the server as: those of the workspace registered for the domain in the request's Host header, or the server's own otherwise. It needs no authentication, so clients can brand their sign-in screens.
+// @Tags         workspaces
+// @Produce      json
+// @Success      200  {object}  service.Branding
+// @Router       /branding [get]
+func (h *WorkspaceHandler) GetBranding(w http.ResponseWriter, r *http.Request) {
+    branding := h.workspaces.DefaultBranding()
+    if workspace, ok := service.WorkspaceFrom(r.Context()); ok {
+        branding = workspace.Branding
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(branding)
+}
+
+// GetWorkspaces godoc
+// @Summary      List workspaces
+// @Description  Lists every workspace, by domain. Administrators only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   service.Workspace
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      500  {string}  string "Failed to list workspaces"
+// @Security     ApiKeyAuth
+// @Router       /admin/workspaces [get]
+func (h *WorkspaceHandler) GetWorkspaces(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+
+    workspaces, err := h.workspaces.Workspaces(r.Context())
+    if err != nil {
+        log.Printf("Failed to list workspaces: %v", err)
+        http.Error(w, "Failed to list workspaces", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(workspaces)
+}
+
+// CreateWorkspace godoc
+// @Summary      Register a workspace
+// @Description  Registers a workspace for a custom domain or subdomain, with its branding, for white-label deployments. Requests made to the domain are served as usual, with GET /branding returning the workspace's branding; point the domain's DNS at the server and serve it a certificate. Rooms and users are shared by every workspace. Other servers see the workspace within a minute. Administrators only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        workspace  body      WorkspaceRequest  true  "Domain and branding"
+// @Success      201        {object}  service.Workspace
+// @Failure      400        {string}  string "Invalid request body, domain or branding"
+// @Failure      401        {string}  string "User not authenticated"
+// @Failure      403        {string}  string "Forbidden: Administrators only"
+// @Failure      409        {string}  string "Domain is already registered to a workspace"
+// @Failure      500        {string}  string "Failed to register workspace"
+// @Security     ApiKeyAuth
+// @Router       /admin/workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+
+    var req WorkspaceRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    workspace, err := h.workspaces.Create(r.Context(), req.Domain, req.branding())
+    if err != nil {
+        h.writeError(w, err, "Failed to register workspace")
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(workspace)
+}
+
+// UpdateWorkspace godoc
+// @Summary      Update a workspace
+// @Description  Replaces a workspace's domain and branding. Other servers see the change within a minute. Administrators only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string            true  "Workspace ID"
+// @Param        workspace  body      WorkspaceRequest  true  "Domain and branding"
+// @Success      200        {object}  service.Workspace
+// @Failure      400        {string}  string "Invalid workspace ID, request body, domain or branding"
+// @Failure      401        {string}  string "User not authenticated"
+// @Failure      403        {string}  string "Forbidden: Administrators only"
+// @Failure      404        {string}  string "Workspace not found"
+// @Failure      409        {string}  string "Domain is already registered to a workspace"
+// @Failure      500        {string}  string "Failed to update workspace"
+// @Security     ApiKeyAuth
+// @Router       /admin/workspaces/{id} [put]
+func (h *WorkspaceHandler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+    workspaceID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+        return
+    }
+
+    var req WorkspaceRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    workspace, err := h.workspaces.Update(r.Context(), workspaceID, req.Domain, req.branding())
+    if err != nil {
+        h.writeError(w, err, "Failed to update workspace")
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(workspace)
+}
+
+// DeleteWorkspace godoc
+// @Summary      Delete a workspace
+// @Description  Removes a workspace; its domain is then served with the server's own branding. Administrators only.
+// @Tags         admin
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      204  {string}  string "No Content"
+// @Failure      400  {string}  string "Invalid workspace ID"
+// @Failure      401  {string}  string "User not authenticated"
+// @Failure      403  {string}  string "Forbidden: Administrators only"
+// @Failure      404  {string}  string "Workspace not found"
+// @Failure      500  {string}  string "Failed to delete workspace"
+// @Security     ApiKeyAuth
+// @Router       /admin/workspaces/{id} [delete]
+func (h *WorkspaceHandler) DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+    if !h.requireAdmin(w, r) {
+        return
+    }
+    workspaceID, err := uuid.Parse(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.workspaces.Delete(r.Context(), workspaceID); err != nil {
+        h.writeError(w, err, "Failed to delete workspace")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// branding returns the branding in the request.
+func (req WorkspaceRequest) branding() service.Branding {
+    return service.Branding{
+        Name:         req.Name,
+        LogoURL:      req.LogoURL,
+        PrimaryColor: req.PrimaryColor,
+        AccentColor:  req.AccentColor,
+    }
+}
+
+// writeError answers a failed workspace change, logging unexpected errors
+// and answering them with message.
+func (h *WorkspaceHandler) writeError(w http.ResponseWriter, err error, message string) {
+    switch {
+    case errors.Is(err, service.ErrInvalidWorkspace):
+        http.Error(w, err.Error(), http.StatusBadRequest)
+    case errors.Is(err, service.ErrWorkspaceNotFound):
+        http.Error(w, "Workspace not found", http.StatusNotFound)
+    case errors.Is(err, service.ErrWorkspaceDomainTaken):
+        http.Error(w, "Domain is already registered to a workspace", http.StatusConflict)
+    default:
+        log.Printf("%s: %v", message, err)
+        http.Error(w, message, http.StatusInternalServerError)
+    }
+}
+
+// requireAdmin checks that the authenticated user is an administrator,
+// writing the error response if not.
+func (h *WorkspaceHandler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+    userID, ok := authUserID(r)
+    if !ok {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return false
+    }
+    user, err := h.db.GetUserByID(r.Context(), userID)
+    if err != nil || !user.IsAdmin {
+        http.Error(w, "Forbidden: Administrators only", http.StatusForbidden)
+        return false
+    }
+    return true
+}
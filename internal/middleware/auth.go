@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -9,25 +12,165 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("super-secret-key")
+// MinJWTSecretLength is the shortest secret tokens can be signed with, in
+// bytes: the size of an HS256 hash.
+const MinJWTSecretLength = 32
+
+// MinJWTKeyBits is the smallest RSA key tokens can be signed or verified
+// with.
+const MinJWTKeyBits = 2048
+
+// ErrJWTSecret is returned when configuring tokens with a secret shorter
+// than MinJWTSecretLength.
+var ErrJWTSecret = errors.New("the JWT secret must be at least 32 bytes")
+
+// JWTConfig configures how tokens are signed and validated.
+type JWTConfig struct {
+	// Secret signs tokens with HS256 when there is no SigningKey. With one,
+	// tokens signed with the secret are still accepted, so that sessions
+	// survive a move from HS256 to RS256; leave it out once they have
+	// expired.
+	Secret []byte
+	// SigningKey signs tokens with RS256. Its public key is published with
+	// JWKS so that other services can verify the tokens.
+	SigningKey *rsa.PrivateKey
+	// VerifyKeys are further public keys whose RS256 tokens are accepted and
+	// which are published with JWKS: retired signing keys, until the tokens
+	// they signed have expired, and the next signing key ahead of a
+	// rotation, so that other services have it before it is used.
+	VerifyKeys []*rsa.PublicKey
+	// Issuer is set as the iss claim of the tokens issued, and Audience as
+	// their aud claim. Tokens without both are rejected.
+	Issuer   string
+	Audience string
+}
+
+// jwtConfig is set once by ConfigureJWT, before the server starts.
+var jwtConfig JWTConfig
+
+// jwtKeys holds the public keys RS256 tokens are verified with, by key ID,
+// and jwtMethods the signing methods accepted. Both are set with jwtConfig.
+var (
+	jwtKeys    map[string]*rsa.PublicKey
+	jwtMethods []string
+)
+
+// ConfigureJWT sets how tokens are signed and validated. It must be called
+// before tokens are issued or requests served.
+func ConfigureJWT(cfg JWTConfig) error {
+	if len(cfg.Secret) > 0 && len(cfg.Secret) < MinJWTSecretLength {
+		return ErrJWTSecret
+	}
+	if len(cfg.Secret) == 0 && cfg.SigningKey == nil {
+		return errors.New("a JWT secret or signing key must be set")
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return errors.New("the JWT issuer and audience must be set")
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	var methods []string
+	if len(cfg.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	publicKeys := cfg.VerifyKeys
+	if cfg.SigningKey != nil {
+		publicKeys = append([]*rsa.PublicKey{&cfg.SigningKey.PublicKey}, publicKeys...)
+	}
+	for _, key := range publicKeys {
+		if key.N.BitLen() < MinJWTKeyBits {
+			return fmt.Errorf("JWT keys must be at least %d bits", MinJWTKeyBits)
+		}
+		keys[keyID(key)] = key
+	}
+	if len(keys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	jwtConfig = cfg
+	jwtKeys = keys
+	jwtMethods = methods
+	return nil
+}
+
+// registeredClaims returns the claims of a token for userID issued now and
+// valid until expiresAt.
+func registeredClaims(userID string, expiresAt time.Time) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    jwtConfig.Issuer,
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{jwtConfig.Audience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
 
 // ContextUserIDKey is a custom type for context key to avoid collisions.
 type contextKey string
 
 const ContextUserIDKey contextKey = "userID"
 
+// ContextImpersonatorIDKey holds the ID of the administrator behind a request
+// made with an impersonation token.
+const ContextImpersonatorIDKey contextKey = "impersonatorID"
+
+// Claims are the claims of the tokens issued by the API.
+type Claims struct {
+	jwt.RegisteredClaims
+	// ImpersonatorID is set on impersonation tokens to the administrator
+	// acting as the subject.
+	ImpersonatorID string `json:"imp,omitempty"`
+}
+
 // GenerateJWT generates a new JWT token for a given user ID.
 func GenerateJWT(userID string, expiry time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+	claims := registeredClaims(userID, time.Now().Add(expiry))
+	return signToken(claims)
+}
+
+// GenerateImpersonationJWT generates a token that lets the administrator
+// adminID act as userID until expiresAt.
+func GenerateImpersonationJWT(userID, adminID string, expiresAt time.Time) (string, error) {
+	claims := Claims{
+		RegisteredClaims: registeredClaims(userID, expiresAt),
+		ImpersonatorID:   adminID,
 	}
+	return signToken(claims)
+}
 
+// signToken signs claims with RS256 and the signing key, whose ID is set as
+// the token's kid header, or with HS256 and the secret when there is none.
+func signToken(claims jwt.Claims) (string, error) {
+	if key := jwtConfig.SigningKey; key != nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = keyID(&key.PublicKey)
+		return token.SignedString(key)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(jwtConfig.Secret)
 }
 
-// AuthMiddleware is a middleware that validates a JWT token.
+// verificationKey returns the key a token's signature is checked with: the
+// secret for HS256 tokens, and the public key named by the kid header for
+// RS256 ones.
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return jwtConfig.Secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := jwtKeys[kid]; ok {
+			return key, nil
+		}
+		return nil, errors.New("unknown signing key")
+	}
+	return nil, errors.New("unexpected signing method")
+}
+
+// AuthMiddleware is a middleware that validates a JWT token: its signature,
+// expiry, issuer and audience. HS256 tokens are only accepted while a secret
+// is configured, and RS256 ones while signed by a key it knows.
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -44,10 +187,13 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenString := parts[1]
 
-		claims := &jwt.RegisteredClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey,
+			jwt.WithValidMethods(jwtMethods),
+			jwt.WithIssuer(jwtConfig.Issuer),
+			jwt.WithAudience(jwtConfig.Audience),
+			jwt.WithExpirationRequired(),
+		)
 
 		if err != nil || !token.Valid {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
@@ -56,6 +202,9 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		// Set the user ID in the request context for subsequent handlers
 		ctx := context.WithValue(r.Context(), ContextUserIDKey, claims.Subject)
+		if claims.ImpersonatorID != "" {
+			ctx = context.WithValue(ctx, ContextImpersonatorIDKey, claims.ImpersonatorID)
+		}
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
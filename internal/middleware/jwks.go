@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// JSONWebKey is the public half of an RS256 signing key, as published in a
+// JSON Web Key Set (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// N and E are the key's modulus and exponent, base64url-encoded.
+	N string `json:"n"`
+	E string `json:"e"`
+}
+
+// JSONWebKeySet lists the keys tokens can be verified with.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys RS256 tokens are verified with: the signing
+// key first, then the verify keys. It is empty when tokens are signed with
+// HS256 only, whose secret cannot be published.
+func JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	publicKeys := jwtConfig.VerifyKeys
+	if jwtConfig.SigningKey != nil {
+		publicKeys = append([]*rsa.PublicKey{&jwtConfig.SigningKey.PublicKey}, publicKeys...)
+	}
+	seen := make(map[string]bool)
+	for _, key := range publicKeys {
+		jwk := jsonWebKey(key)
+		if seen[jwk.Kid] {
+			continue
+		}
+		seen[jwk.Kid] = true
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// jsonWebKey describes key as a JSON Web Key.
+func jsonWebKey(key *rsa.PublicKey) JSONWebKey {
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	return JSONWebKey{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: thumbprint(n, e), N: n, E: e}
+}
+
+// keyID returns the ID tokens signed by key name it with in their kid header.
+func keyID(key *rsa.PublicKey) string {
+	return jsonWebKey(key).Kid
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the RSA key with the
+// base64url-encoded modulus n and exponent e, which identifies it however
+// it is stored.
+func thumbprint(n, e string) string {
+	// The members are required in lexicographic order, without whitespace.
+	members, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: e, Kty: "RSA", N: n})
+	sum := sha256.Sum256(members)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
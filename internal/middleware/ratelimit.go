@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/ratelimit"
+)
+
+// RateLimit rejects requests with 429 Too Many Requests once the caller has
+// exceeded the limiter. Authenticated callers are limited by user ID, anonymous
+// callers by remote address.
+//
+// Every response carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers, so clients can slow down before being rejected;
+// rejections also carry Retry-After. Times are in whole seconds, rounded up.
+func RateLimit(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := limiter.Take(rateLimitKey(r))
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(status.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+			h.Set("RateLimit-Reset", seconds(status.Reset))
+			if !status.Allowed {
+				h.Set("Retry-After", seconds(status.RetryAfter))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds formats d as a whole number of seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+func rateLimitKey(r *http.Request) string {
+	if userID, ok := r.Context().Value(ContextUserIDKey).(string); ok {
+		return "user:" + userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
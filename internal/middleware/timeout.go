@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// Timeout bounds how long the handlers it wraps may take. Requests that run
+// longer are answered with 503 Service Unavailable. It buffers responses and
+// cannot hijack connections, so WebSocket routes must not use it.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, "Request timed out")
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// APIVersion is the current version of the REST API. Its routes are served
+// under /v1.
+const APIVersion = "1"
+
+// APIVersionHeader names the API version that served a response.
+const APIVersionHeader = "API-Version"
+
+// Versioned adds the API-Version header to every response, so clients can
+// tell which version of the API answered them.
+func Versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(APIVersionHeader, APIVersion)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Unversioned serves the unversioned paths kept for existing clients. Their
+// responses are marked deprecated and link to the same path under prefix,
+// e.g. /v1/rooms for /rooms.
+func Unversioned(prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, APIVersion)
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+prefix+r.URL.Path+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package ratelimit implements a keyed token-bucket rate limiter.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often idle, fully refilled buckets are dropped.
+const pruneInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a set of token buckets keyed by an arbitrary string, such as a
+// user ID or a client address. It is safe for concurrent use.
+type Limiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens added per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+// New creates a limiter that allows rate events per second per key, with
+// bursts of up to burst events.
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// Status describes a key's bucket after an attempted event.
+type Status struct {
+	// Allowed reports whether the event may happen.
+	Allowed bool
+	// Limit is the burst, the most events allowed at once.
+	Limit int
+	// Remaining is how many more events are allowed right now.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next event is allowed; zero while
+	// Remaining is positive.
+	RetryAfter time.Duration
+}
+
+// Allow reports whether an event for key may happen now, consuming a token if so.
+func (l *Limiter) Allow(key string) bool {
+	return l.Take(key).Allowed
+}
+
+// Take is like Allow but also reports the state of the key's bucket.
+func (l *Limiter) Take(key string) Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b := l.refill(key, now)
+	l.prune(now)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	status := Status{
+		Allowed:   allowed,
+		Limit:     int(l.burst),
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     l.wait(l.burst - b.tokens),
+	}
+	if b.tokens < 1 {
+		status.RetryAfter = l.wait(1 - b.tokens)
+	}
+	return status
+}
+
+// wait returns how long refilling the given number of tokens takes.
+func (l *Limiter) wait(tokens float64) time.Duration {
+	if tokens <= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// refill returns the bucket for key with tokens added for the time elapsed
+// since it was last used.
+func (l *Limiter) refill(key string, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	return b
+}
+
+// prune drops buckets that would be full again, as they carry no state.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package server lets extensions hook into the API server's lifecycle, so
+// forks can add features without patching cmd/api.
+//
+// An extension registers itself from an init function, typically in a package
+// that cmd/api imports for its side effects:
+//
+//	func init() { server.RegisterExtension(&myExtension{}) }
+//
+// Every extension has a name; what else it takes part in depends on which of
+// the hook interfaces it implements: Initializer, PublicRouter, Router,
+// HubObserver, Job and Shutdowner. Hooks run in registration order, except
+// for Shutdown, which runs in reverse.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+	"github.com/mxhdiqaim/go-chat-app/internal/service"
+)
+
+// Extension is a feature added to the server from outside cmd/api.
+type Extension interface {
+	// Name identifies the extension in logs and errors. It must be unique.
+	Name() string
+}
+
+// Deps are the server's shared dependencies, handed to extensions on Init.
+type Deps struct {
+	DB        *database.Queries
+	Pool      *pgxpool.Pool
+	Hub       *service.Hub
+	Messages  *service.MessageService
+	Store     *service.MessageStore
+	Providers service.Providers
+}
+
+// Initializer is implemented by extensions that set up state before the
+// server starts. An error stops the server from starting.
+type Initializer interface {
+	Init(ctx context.Context, deps Deps) error
+}
+
+// PublicRouter is implemented by extensions with routes that need no
+// authentication. They are mounted next to /register and /login.
+type PublicRouter interface {
+	PublicRoutes(r chi.Router)
+}
+
+// Router is implemented by extensions with authenticated routes. They are
+// mounted with the built-in ones, behind the JWT middleware and the handler
+// timeout; the user ID is in the request context as for any other handler.
+type Router interface {
+	Routes(r chi.Router)
+}
+
+// HubObserver is implemented by extensions that watch the hub's traffic.
+// OnMessage is called, on its own goroutine, with a copy of every message
+// and event the hub routes.
+type HubObserver interface {
+	OnMessage(message service.Message)
+}
+
+// Job is implemented by extensions with background work. Run is started on
+// its own goroutine once the server is set up, and its context is cancelled
+// on shutdown.
+type Job interface {
+	Run(ctx context.Context)
+}
+
+// Shutdowner is implemented by extensions that release resources when the
+// server stops. It is called after the HTTP server has shut down and the
+// extensions' jobs have returned, or ctx has expired.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+var (
+	mu         sync.Mutex
+	extensions []Extension
+)
+
+// RegisterExtension adds an extension to the server. It is meant to be
+// called from init functions and panics if ext is nil or its name is
+// already taken.
+func RegisterExtension(ext Extension) {
+	if ext == nil {
+		panic("server: RegisterExtension called with nil extension")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, registered := range extensions {
+		if registered.Name() == ext.Name() {
+			panic("server: RegisterExtension called twice for " + ext.Name())
+		}
+	}
+	extensions = append(extensions, ext)
+}
+
+// Extensions returns the registered extensions in registration order.
+func Extensions() []Extension {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Extension(nil), extensions...)
+}
+
+// HubHooks returns the OnMessage hooks of the registered extensions, for
+// service.HubOptions.
+func HubHooks() []func(service.Message) {
+	var hooks []func(service.Message)
+	for _, ext := range Extensions() {
+		if o, ok := ext.(HubObserver); ok {
+			hooks = append(hooks, o.OnMessage)
+		}
+	}
+	return hooks
+}
+
+// Lifecycle drives the registered extensions through the server's startup
+// and shutdown.
+type Lifecycle struct {
+	extensions []Extension
+	cancel     context.CancelFunc
+	jobs       sync.WaitGroup
+}
+
+// Init initializes the registered extensions. Extensions registered later
+// are not part of the returned lifecycle.
+func Init(ctx context.Context, deps Deps) (*Lifecycle, error) {
+	l := &Lifecycle{extensions: Extensions()}
+	for _, ext := range l.extensions {
+		if i, ok := ext.(Initializer); ok {
+			if err := i.Init(ctx, deps); err != nil {
+				return nil, fmt.Errorf("extension %s: %w", ext.Name(), err)
+			}
+		}
+		log.Printf("Loaded extension %s", ext.Name())
+	}
+	return l, nil
+}
+
+// PublicRoutes mounts the extensions' unauthenticated routes on r.
+func (l *Lifecycle) PublicRoutes(r chi.Router) {
+	for _, ext := range l.extensions {
+		if p, ok := ext.(PublicRouter); ok {
+			p.PublicRoutes(r)
+		}
+	}
+}
+
+// Routes mounts the extensions' authenticated routes on r.
+func (l *Lifecycle) Routes(r chi.Router) {
+	for _, ext := range l.extensions {
+		if p, ok := ext.(Router); ok {
+			p.Routes(r)
+		}
+	}
+}
+
+// StartJobs starts the extensions' background jobs.
+func (l *Lifecycle) StartJobs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	for _, ext := range l.extensions {
+		if j, ok := ext.(Job); ok {
+			l.jobs.Add(1)
+			go func() {
+				defer l.jobs.Done()
+				j.Run(ctx)
+			}()
+		}
+	}
+}
+
+// Shutdown stops the extensions' jobs, waiting for them until ctx expires,
+// then shuts the extensions down in reverse registration order. Every
+// extension gets to shut down; their errors are joined.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	if l.cancel != nil {
+		l.cancel()
+		done := make(chan struct{})
+		go func() {
+			l.jobs.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			log.Printf("Extension jobs did not stop: %v", ctx.Err())
+		}
+	}
+
+	var errs []error
+	for i := len(l.extensions) - 1; i >= 0; i-- {
+		ext := l.extensions[i]
+		if s, ok := ext.(Shutdowner); ok {
+			if err := s.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("extension %s: %w", ext.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Audit log actions for rooms handed over when their owner is deleted.
+const (
+    AuditActionRoomOwnershipTransfer = "room.ownership_transfer"
+    AuditActionRoomArchive           = "room.archive"
+)
+
+// Outcomes for rooms whose owner deletes their account.
+const (
+    HandoverTransfer = "transfer"
+    HandoverArchive  = "archive"
+)
+
+// RoomHandover describes what happens to a room when its owner deletes their
+// account.
+type RoomHandover struct {
+    RoomID string `json:"room_id"`
+    Name   string `json:"name"`
+    // Outcome is "transfer" or "archive".
+    Outcome string `json:"outcome"`
+    // NewOwnerID and NewOwnerUsername name who inherits a transferred room.
+    NewOwnerID       string `json:"new_owner_id,omitempty"`
+    NewOwnerUsername string `json:"new_owner_username,omitempty"`
+}
+
+// DeletionReport summarizes what deleting an account removes and changes, so
+// clients can ask for an informed confirmation.
+type DeletionReport struct {
+    RoomsOwned []RoomHandover `json:"rooms_owned"`
+    // Messages counts every message the user sent, polls included.
+    Messages int64 `json:"messages"`
+    Polls    int64 `json:"polls"`
+    // StorageBytes is the size of the user's message content and metadata.
+    StorageBytes int64 `json:"storage_bytes"`
+    // Consequences spells out the effects of the deletion in plain sentences.
+    Consequences []string `json:"consequences"`
+}
+
+// AccountService manages the lifecycle of user accounts.
+type AccountService struct {
+    db    *database.Queries
+    pool  *pgxpool.Pool
+    store *MessageStore
+    hub   *Hub
+}
+
+// NewAccountService creates a new AccountService.
+func NewAccountService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore, hub *Hub) *AccountService {
+    return &AccountService{db: db, pool: pool, store: store, hub: hub}
+}
+
+// DeletionReport reports what DeleteUser would do for the user, without
+// changing anything.
+func (s *AccountService) DeletionReport(ctx context.Context, userID uuid.UUID) (*DeletionReport, error) {
+    usage, err := s.db.GetUserContentUsage(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    rooms, err := s.db.GetRoomsOwnedBy(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    report := &DeletionReport{
+        RoomsOwned:   make([]RoomHandover, 0, len(rooms)),
+        Messages:     usage.MessageCount,
+        Polls:        usage.PollCount,
+        StorageBytes: usage.StorageBytes,
+        Consequences: []string{
+            fmt.Sprintf("Your %d messages, including %d polls, will be deleted permanently. They are not anonymized.", usage.MessageCount, usage.PollCount),
+            "Your room memberships, co-ownerships, starred messages and poll votes will be removed.",
+        },
+    }
+    for _, room := range rooms {
+        handover := RoomHandover{RoomID: room.ID.String(), Name: room.Name, Outcome: HandoverArchive}
+        successorID, err := s.db.GetRoomSuccessor(ctx, database.GetRoomSuccessorParams{RoomID: room.ID, OwnerID: userID})
+        switch {
+        case errors.Is(err, pgx.ErrNoRows):
+            report.Consequences = append(report.Consequences, fmt.Sprintf("%s will be archived because nobody is left to inherit it.", room.Name))
+        case err != nil:
+            return nil, err
+        default:
+            successor, err := s.db.GetUserByID(ctx, successorID)
+            if err != nil {
+                return nil, err
+            }
+            handover.Outcome = HandoverTransfer
+            handover.NewOwnerID = successor.ID.String()
+            handover.NewOwnerUsername = successor.Username
+            report.Consequences = append(report.Consequences, fmt.Sprintf("Ownership of %s will pass to %s.", room.Name, successor.Username))
+        }
+        report.RoomsOwned = append(report.RoomsOwned, handover)
+    }
+    return report, nil
+}
+
+// DeleteUser deletes a user's account. Every room the user owns is first
+// handed to its longest-standing co-owner, moderator, administrator or member,
+// in that order, and the room is told about it. Rooms with nobody left to
+// inherit them are archived under the system bot. Everything happens in one
+// transaction. Accounts whose messages are under legal hold are kept, with
+// ErrLegalHold.
+func (s *AccountService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    held, err := qtx.IsUserContentHeld(ctx, userID)
+    if err != nil {
+        return err
+    }
+    if held {
+        return ErrLegalHold
+    }
+
+    user, err := qtx.GetUserByID(ctx, userID)
+    if err != nil {
+        return err
+    }
+    rooms, err := qtx.GetRoomsOwnedBy(ctx, userID)
+    if err != nil {
+        return err
+    }
+
+    var notices []database.Message
+    for _, room := range rooms {
+        notice, err := s.handOver(ctx, qtx, room, user)
+        if err != nil {
+            return fmt.Errorf("hand over room %s: %w", room.ID, err)
+        }
+        if notice != nil {
+            notices = append(notices, *notice)
+        }
+    }
+
+    if err := qtx.DeleteUser(ctx, userID); err != nil {
+        return err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return err
+    }
+
+    for _, notice := range notices {
+        s.hub.Broadcast(messageFromRow(notice))
+    }
+    return nil
+}
+
+// handOver transfers or archives a room owned by a user being deleted. For a
+// transfer it returns the system message announcing the new owner, to be
+// broadcast once the transaction commits.
+func (s *AccountService) handOver(ctx context.Context, qtx *database.Queries, room database.Room, owner database.User) (*database.Message, error) {
+    successorID, err := qtx.GetRoomSuccessor(ctx, database.GetRoomSuccessorParams{RoomID: room.ID, OwnerID: owner.ID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        if _, err := qtx.ArchiveRoom(ctx, database.ArchiveRoomParams{ID: room.ID, OwnerID: SystemUserID}); err != nil {
+            return nil, err
+        }
+        log.Printf("archived room %s: its owner %s was deleted and nobody is left to inherit it", room.ID, owner.ID)
+        return nil, RecordAudit(ctx, qtx, AuditEntry{
+            Action:  AuditActionRoomArchive,
+            RoomID:  &room.ID,
+            Details: map[string]any{"previous_owner_id": owner.ID},
+        })
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if _, err := qtx.TransferRoomOwnership(ctx, database.TransferRoomOwnershipParams{ID: room.ID, OwnerID: successorID}); err != nil {
+        return nil, err
+    }
+    // The new owner keeps the owner role among the members too.
+    if _, err := qtx.SetRoomMemberRole(ctx, database.SetRoomMemberRoleParams{RoomID: room.ID, UserID: successorID, Role: RoomRoleOwner}); err != nil {
+        return nil, err
+    }
+    if err := RecordAudit(ctx, qtx, AuditEntry{
+        Action:  AuditActionRoomOwnershipTransfer,
+        RoomID:  &room.ID,
+        Details: map[string]any{"previous_owner_id": owner.ID, "owner_id": successorID},
+    }); err != nil {
+        return nil, err
+    }
+
+    successor, err := qtx.GetUserByID(ctx, successorID)
+    if err != nil {
+        return nil, err
+    }
+    // Clients can tell the announcement apart from chat by its metadata.
+    metadata, err := json.Marshal(map[string]any{"event": "room.owner_changed", "owner_id": successorID})
+    if err != nil {
+        return nil, err
+    }
+    notice, err := s.store.Create(ctx, qtx, database.CreateMessageParams{
+        ID:       uuid.New(),
+        RoomID:   room.ID,
+        SenderID: SystemUserID,
+        Content:  fmt.Sprintf("%s deleted their account. %s is now the owner of this room.", owner.Username, successor.Username),
+        Metadata: metadata,
+        Kind:     MessageKindText,
+        Mentions: []uuid.UUID{},
+        Priority: MessagePriorityNormal,
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &notice, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventMessageAnnotated is the type of the event carrying a new annotation.
+const EventMessageAnnotated = "message.annotated"
+
+const (
+    maxAnnotationKindLength = 64
+    maxAnnotationDataSize   = 4 * 1024
+)
+
+// ErrInvalidAnnotation is returned for annotations without a kind, or with a
+// kind or data that is too large.
+var ErrInvalidAnnotation = errors.New("an annotation needs a kind of at most 64 characters and at most 4KB of data")
+
+// Annotation is structured data attached to a message after it was sent, such
+// as a deployment marker, a ticket link or a sentiment score. It never changes
+// the message's content.
+type Annotation struct {
+    ID        string         `json:"id"`
+    AuthorID  string         `json:"author_id"`
+    Kind      string         `json:"kind" example:"ticket"`
+    Data      map[string]any `json:"data,omitempty"`
+    CreatedAt time.Time      `json:"created_at"`
+}
+
+// AnnotationService attaches annotations to messages and broadcasts them
+// through the Hub.
+type AnnotationService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+}
+
+// NewAnnotationService creates a new AnnotationService.
+func NewAnnotationService(db *database.Queries, messages *MessageService, hub *Hub) *AnnotationService {
+    return &AnnotationService{db: db, messages: messages, hub: hub}
+}
+
+// Annotate attaches an annotation by authorID to a message they can see and
+// sends it to the message's audience as an enrichment event.
+func (s *AnnotationService) Annotate(ctx context.Context, authorID, messageID uuid.UUID, kind string, data map[string]any) (*Annotation, error) {
+    if kind == "" || len(kind) > maxAnnotationKindLength {
+        return nil, ErrInvalidAnnotation
+    }
+    encoded := []byte("{}")
+    if len(data) > 0 {
+        var err error
+        if encoded, err = json.Marshal(data); err != nil {
+            return nil, err
+        }
+        if len(encoded) > maxAnnotationDataSize {
+            return nil, ErrInvalidAnnotation
+        }
+    }
+
+    message, err := s.messages.visibleMessage(ctx, authorID, messageID)
+    if err != nil {
+        return nil, err
+    }
+
+    row, err := s.messages.store.Annotate(ctx, s.db, message, database.CreateMessageAnnotationParams{
+        ID:        uuid.New(),
+        MessageID: messageID,
+        AuthorID:  authorID,
+        Kind:      kind,
+        Data:      encoded,
+    })
+    if err != nil {
+        return nil, err
+    }
+    annotation := annotationFromRow(row)
+
+    event := &Message{
+        Type:        EventMessageAnnotated,
+        ID:          message.ID.String(),
+        SenderID:    authorID.String(),
+        RoomID:      message.RoomID.String(),
+        CreatedAt:   row.CreatedAt,
+        Annotations: []Annotation{*annotation},
+    }
+    s.hub.broadcastUpdate(message, event)
+    return annotation, nil
+}
+
+// loadAnnotations fetches the annotations of rows in a single query, keyed by
+// message ID.
+func (s *MessageService) loadAnnotations(ctx context.Context, rows []database.Message) (map[uuid.UUID][]Annotation, error) {
+    annotations := make(map[uuid.UUID][]Annotation)
+    if len(rows) == 0 {
+        return annotations, nil
+    }
+
+    ids := make([]uuid.UUID, len(rows))
+    for i, row := range rows {
+        ids[i] = row.ID
+    }
+    found, err := s.db.GetMessageAnnotations(ctx, ids)
+    if err != nil {
+        return nil, err
+    }
+    for _, row := range found {
+        annotations[row.MessageID] = append(annotations[row.MessageID], *annotationFromRow(row))
+    }
+    return annotations, nil
+}
+
+// annotationFromRow converts a database annotation into its wire representation.
+func annotationFromRow(row database.MessageAnnotation) *Annotation {
+    annotation := &Annotation{
+        ID:        row.ID.String(),
+        AuthorID:  row.AuthorID.String(),
+        Kind:      row.Kind,
+        CreatedAt: row.CreatedAt,
+    }
+    if len(row.Data) > 0 {
+        if err := json.Unmarshal(row.Data, &annotation.Data); err != nil {
+            log.Printf("invalid data on annotation %s: %v", annotation.ID, err)
+        }
+    }
+    return annotation
+}
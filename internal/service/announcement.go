@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventRoomAnnouncement is the type of the event sent to a room's connected
+// members when its announcement is set or removed.
+const EventRoomAnnouncement = "room.announcement"
+
+// MaxAnnouncementLength is the longest an announcement can be, in characters.
+const MaxAnnouncementLength = 500
+
+var (
+    // ErrInvalidAnnouncement is returned for empty or overlong announcements.
+    ErrInvalidAnnouncement = errors.New("announcements must be 1-500 characters")
+    // ErrAnnouncementExpiry is returned for announcements that would expire
+    // right away.
+    ErrAnnouncementExpiry = errors.New("expires_at must be in the future")
+)
+
+// Announcement is the text pinned at the top of a room, such as a planned
+// outage or a link to the rules.
+type Announcement struct {
+    Content string `json:"content" example:"Maintenance tonight from 22:00 UTC."`
+    // SetBy is absent once the account of whoever set it is deleted.
+    SetBy     *string   `json:"set_by,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    // ExpiresAt is when the announcement is taken down; absent when it stays
+    // until removed.
+    ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-09-04T06:00:00Z"`
+}
+
+// AnnouncementUpdate carries a room's announcement in room.announcement
+// events. Announcement is null once it has been removed.
+type AnnouncementUpdate struct {
+    RoomID       string        `json:"room_id"`
+    Announcement *Announcement `json:"announcement"`
+}
+
+// NormalizeAnnouncement trims an announcement and checks its length and
+// expiry.
+func NormalizeAnnouncement(content string, expiresAt *time.Time) (string, error) {
+    content = strings.TrimSpace(content)
+    if content == "" || utf8.RuneCountInString(content) > MaxAnnouncementLength {
+        return "", ErrInvalidAnnouncement
+    }
+    if expiresAt != nil && !expiresAt.After(time.Now()) {
+        return "", ErrAnnouncementExpiry
+    }
+    return content, nil
+}
+
+// RoomAnnouncement returns the room's announcement, or nil if it has none or
+// it has expired.
+func RoomAnnouncement(ctx context.Context, db *database.Queries, roomID uuid.UUID) (*Announcement, error) {
+    row, err := db.GetRoomAnnouncement(ctx, roomID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return announcementFromRow(row), nil
+}
+
+// SetRoomAnnouncement pins an announcement by userID in the room, replacing
+// the current one. content must have been normalized.
+func SetRoomAnnouncement(ctx context.Context, db *database.Queries, roomID, userID uuid.UUID, content string, expiresAt *time.Time) (*Announcement, error) {
+    row, err := db.SetRoomAnnouncement(ctx, database.SetRoomAnnouncementParams{
+        RoomID:    roomID,
+        Content:   content,
+        SetBy:     &userID,
+        ExpiresAt: expiresAt,
+    })
+    if err != nil {
+        return nil, err
+    }
+    return announcementFromRow(row), nil
+}
+
+// RemoveRoomAnnouncement takes down the room's announcement. It reports
+// whether there was one that had not expired.
+func RemoveRoomAnnouncement(ctx context.Context, db *database.Queries, roomID uuid.UUID) (bool, error) {
+    removed, err := db.DeleteRoomAnnouncement(ctx, roomID)
+    return removed > 0, err
+}
+
+// BroadcastAnnouncement sends the room's announcement, or nil once it was
+// removed, to its connected members, announcing a change made by actorID.
+// Nothing is sent when an announcement expires; clients hide it at its
+// expires_at.
+func (h *Hub) BroadcastAnnouncement(roomID, actorID uuid.UUID, announcement *Announcement) {
+    h.Broadcast(&Message{
+        Type:         EventRoomAnnouncement,
+        SenderID:     actorID.String(),
+        RoomID:       roomID.String(),
+        CreatedAt:    time.Now(),
+        Announcement: &AnnouncementUpdate{RoomID: roomID.String(), Announcement: announcement},
+    })
+}
+
+func announcementFromRow(row database.RoomAnnouncement) *Announcement {
+    announcement := &Announcement{
+        Content:   row.Content,
+        CreatedAt: row.CreatedAt,
+        ExpiresAt: row.ExpiresAt,
+    }
+    if row.SetBy != nil {
+        setBy := row.SetBy.String()
+        announcement.SetBy = &setBy
+    }
+    return announcement
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Audit log actions.
+const (
+    AuditActionRetentionUpdate    = "retention.update"
+    AuditActionRetentionPurge     = "retention.purge"
+    AuditActionMessagesBulkDelete = "messages.bulk_delete"
+    AuditActionMemberKick         = "member.kick"
+    AuditActionMemberBan          = "member.ban"
+    AuditActionMemberUnban        = "member.unban"
+    AuditActionLegalHoldPlace     = "legal_hold.place"
+    AuditActionLegalHoldRelease   = "legal_hold.release"
+)
+
+// AuditEntry is a record of an administrative action.
+type AuditEntry struct {
+    // ActorID is the user who performed the action; nil for system jobs.
+    ActorID *uuid.UUID
+    Action  string
+    RoomID  *uuid.UUID
+    Details map[string]any
+}
+
+// RecordAudit appends an entry to the audit log. Entries recorded while an
+// administrator impersonates the actor name that administrator.
+func RecordAudit(ctx context.Context, db *database.Queries, entry AuditEntry) error {
+    details := []byte("{}")
+    if len(entry.Details) > 0 {
+        var err error
+        if details, err = json.Marshal(entry.Details); err != nil {
+            return err
+        }
+    }
+    params := database.CreateAuditLogParams{
+        ID:      uuid.New(),
+        ActorID: entry.ActorID,
+        Action:  entry.Action,
+        RoomID:  entry.RoomID,
+        Details: details,
+    }
+    if adminID, ok := Impersonator(ctx); ok {
+        params.ImpersonatorID = &adminID
+    }
+    return db.CreateAuditLog(ctx, params)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// roomMentionPushWindow is how long pushes for @room and @here mentions are
+// held back, so that a burst of them reaches each offline member as one
+// notification instead of one per message.
+const roomMentionPushWindow = 30 * time.Second
+
+// mentionCoalescer batches the pushes for room mentions per room. The first
+// room mention in a room opens a window; when it closes, each user mentioned
+// in it gets a single push for the latest message, counting the others.
+type mentionCoalescer struct {
+    mu      sync.Mutex
+    window  time.Duration
+    pending map[string]*mentionBatch
+    flush   func(message *Message, counts map[string]int)
+}
+
+// mentionBatch collects the room mentions of one room during a window.
+type mentionBatch struct {
+    latest *Message
+    // counts holds how many of the batch's messages mention each user.
+    counts map[string]int
+}
+
+// newMentionCoalescer creates a coalescer that hands every closed batch to
+// flush.
+func newMentionCoalescer(window time.Duration, flush func(message *Message, counts map[string]int)) *mentionCoalescer {
+    return &mentionCoalescer{window: window, pending: make(map[string]*mentionBatch), flush: flush}
+}
+
+// add queues pushes for userIDs, mentioned by message.
+func (c *mentionCoalescer) add(message *Message, userIDs []string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    batch, ok := c.pending[message.RoomID]
+    if !ok {
+        batch = &mentionBatch{counts: make(map[string]int)}
+        c.pending[message.RoomID] = batch
+        roomID := message.RoomID
+        time.AfterFunc(c.window, func() { c.close(roomID) })
+    }
+    batch.latest = message
+    for _, userID := range userIDs {
+        batch.counts[userID]++
+    }
+}
+
+// close ends the room's window and flushes its batch.
+func (c *mentionCoalescer) close(roomID string) {
+    c.mu.Lock()
+    batch := c.pending[roomID]
+    delete(c.pending, roomID)
+    c.mu.Unlock()
+    if batch != nil {
+        c.flush(batch.latest, batch.counts)
+    }
+}
+
+// pushRoomMentions sends one push per user for a closed batch of room
+// mentions, one after another so a large room does not hit the push provider
+// all at once.
+func (h *Hub) pushRoomMentions(message *Message, counts map[string]int) {
+    base := h.pushNotification(message, h.pushTemplates.MentionTitle)
+    for userID, count := range counts {
+        notification := base
+        notification.Data = maps.Clone(base.Data)
+        if count > 1 {
+            notification.Body = fmt.Sprintf("%d new mentions", count)
+            notification.Data["mentions"] = strconv.Itoa(count)
+        }
+        if err := h.push.Push(context.Background(), userID, notification); err != nil {
+            log.Printf("failed to push mention to %s: %v", userID, err)
+        }
+    }
+}
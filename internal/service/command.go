@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventCommandResult is the type of the event confirming a slash command to
+// the connection that sent it.
+const EventCommandResult = "command.result"
+
+// Error codes sent in error frames for slash commands.
+const (
+    ErrorCodeUnknownCommand = "unknown_command"
+    ErrorCodeCommandFailed  = "command_failed"
+)
+
+// CommandResult confirms a slash command. It is only sent to the connection
+// the command came from and is not stored.
+type CommandResult struct {
+    Command string `json:"command" example:"pin"`
+    Text    string `json:"text" example:"Message pinned."`
+}
+
+// commandFunc runs a slash command sent by userID in roomID and returns the
+// confirmation to show them.
+type commandFunc func(ctx context.Context, cmd command) (string, error)
+
+// command is a slash command sent as a chat message.
+type command struct {
+    name   string
+    args   []string
+    userID uuid.UUID
+    roomID uuid.UUID
+    // quotedMessageID is the message the command was sent in reply to.
+    quotedMessageID string
+}
+
+// CommandDispatcher runs the slash commands users send as chat messages, so
+// keyboard-centric clients can pin, mute and leave without separate REST
+// calls. Commands are checked against the same permissions as the REST
+// endpoints.
+type CommandDispatcher struct {
+    db            *database.Queries
+    hub           *Hub
+    pins          *PinService
+    notifications *NotificationSettingsService
+    webhooks      *WebhookService
+    commands      map[string]commandFunc
+}
+
+// NewCommandDispatcher creates a CommandDispatcher with the built-in
+// commands: /pin, /unpin, /mute, /unmute and /leave.
+func NewCommandDispatcher(db *database.Queries, hub *Hub, pins *PinService, notifications *NotificationSettingsService, webhooks *WebhookService) *CommandDispatcher {
+    d := &CommandDispatcher{db: db, hub: hub, pins: pins, notifications: notifications, webhooks: webhooks}
+    d.commands = map[string]commandFunc{
+        "pin":    d.pin,
+        "unpin":  d.unpin,
+        "mute":   d.mute,
+        "unmute": d.unmute,
+        "leave":  d.leave,
+    }
+    return d
+}
+
+// parseCommand splits a chat message into a slash command's name and
+// arguments. Only messages starting with a slash and a name made of letters
+// are commands, so a message such as "/etc/hosts is missing" is sent as is.
+func parseCommand(content string) (name string, args []string, ok bool) {
+    if len(content) < 2 || content[0] != '/' || unicode.IsSpace(rune(content[1])) {
+        return "", nil, false
+    }
+    fields := strings.Fields(content[1:])
+    if len(fields) == 0 {
+        return "", nil, false
+    }
+    for _, r := range fields[0] {
+        if !unicode.IsLetter(r) {
+            return "", nil, false
+        }
+    }
+    return strings.ToLower(fields[0]), fields[1:], true
+}
+
+// run runs a command for the client and answers its frame with the
+// confirmation, or with an error frame.
+func (d *CommandDispatcher) run(c *Client, frameID, name string, args []string, quotedMessageID string) {
+    run, ok := d.commands[name]
+    if !ok {
+        c.reject(frameID, &ErrorFrame{Code: ErrorCodeUnknownCommand, Reason: "unknown command /" + name + "; available commands are " + d.names()})
+        return
+    }
+    userID, err := uuid.Parse(c.userID)
+    if err != nil {
+        return
+    }
+    roomID, err := uuid.Parse(c.roomID)
+    if err != nil {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    text, err := run(ctx, command{name: name, args: args, userID: userID, roomID: roomID, quotedMessageID: quotedMessageID})
+    if err != nil {
+        c.reject(frameID, commandError(name, err))
+        return
+    }
+    c.hub.broadcast <- &Message{
+        Type:        EventCommandResult,
+        SenderID:    c.userID,
+        RecipientID: c.userID,
+        RoomID:      c.roomID,
+        CreatedAt:   time.Now(),
+        Command:     &CommandResult{Command: name, Text: text},
+        FrameID:     frameID,
+    }
+    if name == "leave" {
+        d.hub.Disconnect(roomID, userID, "left the room")
+    }
+}
+
+// names lists the available commands, for unknown command errors.
+func (d *CommandDispatcher) names() string {
+    names := make([]string, 0, len(d.commands))
+    for name := range d.commands {
+        names = append(names, "/"+name)
+    }
+    sort.Strings(names)
+    return strings.Join(names, ", ")
+}
+
+// commandError turns a command's error into the error frame sent back for it.
+// Unexpected errors are logged and not shown.
+func commandError(name string, err error) *ErrorFrame {
+    switch {
+    case errors.Is(err, ErrPinNotAllowed), errors.Is(err, ErrTooManyPins), errors.Is(err, ErrInvalidPin),
+        errors.Is(err, ErrMessageNotFound), errors.Is(err, ErrNotRoomMember), errors.Is(err, ErrRoomArchived),
+        errors.Is(err, errNothingToPin):
+        return &ErrorFrame{Code: ErrorCodeCommandFailed, Reason: err.Error()}
+    default:
+        log.Printf("failed to run command /%s: %v", name, err)
+        return &ErrorFrame{Code: ErrorCodeCommandFailed, Reason: "/" + name + " failed"}
+    }
+}
+
+// errNothingToPin is returned by /pin and /unpin in rooms without messages.
+var errNothingToPin = errors.New("there is no message to pin; reply to one or give its ID")
+
+// pin pins the message the command replies to, the one whose ID it gives, or
+// else the room's latest message.
+func (d *CommandDispatcher) pin(ctx context.Context, cmd command) (string, error) {
+    messageID, err := d.target(ctx, cmd)
+    if err != nil {
+        return "", err
+    }
+    if err := d.pins.Pin(ctx, cmd.userID, messageID); err != nil {
+        return "", err
+    }
+    return "Message pinned.", nil
+}
+
+// unpin unpins a message, chosen like pin's.
+func (d *CommandDispatcher) unpin(ctx context.Context, cmd command) (string, error) {
+    messageID, err := d.target(ctx, cmd)
+    if err != nil {
+        return "", err
+    }
+    if err := d.pins.Unpin(ctx, cmd.userID, messageID); err != nil {
+        return "", err
+    }
+    return "Message unpinned.", nil
+}
+
+// target returns the message a pin command is about.
+func (d *CommandDispatcher) target(ctx context.Context, cmd command) (uuid.UUID, error) {
+    ref := cmd.quotedMessageID
+    if ref == "" && len(cmd.args) > 0 {
+        ref = cmd.args[0]
+    }
+    if ref != "" {
+        messageID, err := uuid.Parse(ref)
+        if err != nil {
+            return uuid.Nil, ErrMessageNotFound
+        }
+        return messageID, nil
+    }
+
+    latest, err := d.db.GetLatestRoomMessages(ctx, database.GetLatestRoomMessagesParams{RoomID: cmd.roomID, UserID: cmd.userID, MaxMessages: 1})
+    if err != nil {
+        return uuid.Nil, err
+    }
+    if len(latest) == 0 {
+        return uuid.Nil, errNothingToPin
+    }
+    return latest[0].Message.ID, nil
+}
+
+// mute sets the user's notification level for the room to none.
+func (d *CommandDispatcher) mute(ctx context.Context, cmd command) (string, error) {
+    if _, err := d.notifications.SetRoomLevel(ctx, cmd.roomID, cmd.userID, NotificationLevelNone); err != nil {
+        return "", err
+    }
+    return "Room muted. You will not be notified of its messages.", nil
+}
+
+// unmute restores the user's default notification level for the room.
+func (d *CommandDispatcher) unmute(ctx context.Context, cmd command) (string, error) {
+    if _, err := d.notifications.SetRoomLevel(ctx, cmd.roomID, cmd.userID, DefaultNotificationLevel); err != nil {
+        return "", err
+    }
+    return "Room unmuted. You will be notified of mentions and direct messages.", nil
+}
+
+// leave removes the user from the room. Their connection to it is closed once
+// the confirmation is sent.
+func (d *CommandDispatcher) leave(ctx context.Context, cmd command) (string, error) {
+    isMember, err := d.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: cmd.roomID, UserID: cmd.userID})
+    if err != nil {
+        return "", err
+    }
+    if !isMember {
+        return "", ErrNotRoomMember
+    }
+    if err := d.db.RemoveRoomMember(ctx, database.RemoveRoomMemberParams{RoomID: cmd.roomID, UserID: cmd.userID}); err != nil {
+        return "", err
+    }
+    d.webhooks.Dispatch(cmd.roomID, WebhookEventLeave, MembershipChange{UserID: cmd.userID.String()})
+    return "You left the room.", nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"sort"
+	"time"
+)
+
+// Connection describes a live WebSocket connection to this server.
+type Connection struct {
+    UserID string `json:"user_id"`
+    // RoomID is "admin" for the admin channel.
+    RoomID      string    `json:"room_id"`
+    ConnectedAt time.Time `json:"connected_at"`
+    ReadOnly    bool      `json:"read_only,omitempty"`
+    // Heartbeat is absent until the client sends a heartbeat.
+    Heartbeat *HeartbeatStats `json:"heartbeat,omitempty"`
+}
+
+// ConnectionFilter narrows the connections listed to a room, a user or both.
+// Empty fields match everything.
+type ConnectionFilter struct {
+    RoomID string
+    UserID string
+}
+
+// connectionsRequest asks the hub for the connections matching filter.
+type connectionsRequest struct {
+    filter ConnectionFilter
+    reply  chan []Connection
+}
+
+// Connections returns the live connections to this server that match filter,
+// longest connected first.
+func (h *Hub) Connections(filter ConnectionFilter) []Connection {
+    req := connectionsRequest{filter: filter, reply: make(chan []Connection, 1)}
+    h.connections <- req
+    return <-req.reply
+}
+
+// listConnections builds the reply to a connectionsRequest.
+func (h *Hub) listConnections(filter ConnectionFilter) []Connection {
+    connections := []Connection{}
+    for roomID, clients := range h.clients {
+        if filter.RoomID != "" && roomID != filter.RoomID {
+            continue
+        }
+        for userID, devices := range clients {
+            if filter.UserID != "" && userID != filter.UserID {
+                continue
+            }
+            for client := range devices {
+                connections = append(connections, Connection{
+                    UserID:      userID,
+                    RoomID:      roomID,
+                    ConnectedAt: client.connectedAt,
+                    ReadOnly:    client.readOnly,
+                    Heartbeat:   client.heartbeats.stats(),
+                })
+            }
+        }
+    }
+    sort.Slice(connections, func(i, j int) bool {
+        return connections[i].ConnectedAt.Before(connections[j].ConnectedAt)
+    })
+    return connections
+}
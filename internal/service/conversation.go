@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Room kinds. Group conversations are private rooms that only their
+// participants can see; they are never listed, searched or joined.
+const (
+    RoomKindRoom    = "room"
+    RoomKindGroupDM = "group_dm"
+)
+
+// EventConversationAdded is the type of the event sent to every connection of
+// a user who is added to a group conversation.
+const EventConversationAdded = "conversation.added"
+
+// MaxConversationParticipants caps the participants of a group conversation,
+// its creator included.
+const MaxConversationParticipants = 50
+
+var (
+    // ErrConversationNotFound is returned for group conversations that do not
+    // exist or that the user does not take part in.
+    ErrConversationNotFound = errors.New("conversation not found")
+    // ErrNoParticipants is returned when creating a conversation with nobody
+    // but its creator.
+    ErrNoParticipants = errors.New("a conversation needs at least one other participant")
+    // ErrTooManyParticipants is returned when a conversation would exceed
+    // MaxConversationParticipants.
+    ErrTooManyParticipants = errors.New("a conversation has at most 50 participants")
+    // ErrNotParticipant is returned when removing someone who does not take
+    // part in the conversation.
+    ErrNotParticipant = errors.New("user is not a participant of this conversation")
+    // ErrNotConversationCreator is returned when a participant other than the
+    // creator removes someone else.
+    ErrNotConversationCreator = errors.New("only the conversation's creator can remove other participants")
+)
+
+// Participant is a user taking part in a group conversation. Which names it
+// holds depends on the server's DisplayNamePolicy.
+type Participant struct {
+    UserID      string `json:"user_id"`
+    Username    string `json:"username,omitempty"`
+    DisplayName string `json:"display_name,omitempty"`
+}
+
+// Conversation is a group conversation and its participants.
+type Conversation struct {
+    ID           string        `json:"id"`
+    Name         string        `json:"name,omitempty"`
+    CreatedBy    string        `json:"created_by"`
+    CreatedAt    time.Time     `json:"created_at"`
+    Participants []Participant `json:"participants"`
+    // Encrypted is set once the conversation opted in to end-to-end
+    // encryption, at EncryptedAt; only encrypted frames are accepted in it.
+    Encrypted   bool       `json:"encrypted"`
+    EncryptedAt *time.Time `json:"encrypted_at,omitempty"`
+}
+
+// ConversationService manages group conversations. Messages are sent through
+// the room endpoints and the hub, as for any room.
+type ConversationService struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+    hub  *Hub
+}
+
+// NewConversationService creates a new ConversationService.
+func NewConversationService(db *database.Queries, pool *pgxpool.Pool, hub *Hub) *ConversationService {
+    return &ConversationService{db: db, pool: pool, hub: hub}
+}
+
+// Create starts a group conversation between its creator and userIDs and
+// notifies the other participants' open connections.
+func (s *ConversationService) Create(ctx context.Context, creatorID uuid.UUID, name string, userIDs []uuid.UUID) (*Conversation, error) {
+    seen := map[uuid.UUID]bool{creatorID: true}
+    participants := []uuid.UUID{creatorID}
+    for _, userID := range userIDs {
+        if !seen[userID] {
+            seen[userID] = true
+            participants = append(participants, userID)
+        }
+    }
+    if len(participants) < 2 {
+        return nil, ErrNoParticipants
+    }
+    if len(participants) > MaxConversationParticipants {
+        return nil, ErrTooManyParticipants
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    room, err := qtx.CreateGroupConversation(ctx, database.CreateGroupConversationParams{
+        ID:      uuid.New(),
+        Name:    name,
+        OwnerID: creatorID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    for _, userID := range participants {
+        err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: room.ID, UserID: userID})
+        var pgErr *pgconn.PgError
+        if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+            return nil, ErrUserNotFound
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    conversation, err := s.conversation(ctx, room)
+    if err != nil {
+        return nil, err
+    }
+    for _, userID := range participants[1:] {
+        s.notifyAdded(conversation, creatorID, userID)
+    }
+    return conversation, nil
+}
+
+// List returns the group conversations the user takes part in, most recently
+// active first.
+func (s *ConversationService) List(ctx context.Context, userID uuid.UUID) ([]Conversation, error) {
+    rooms, err := s.db.GetUserGroupConversations(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    conversations := make([]Conversation, 0, len(rooms))
+    for _, room := range rooms {
+        conversation, err := s.conversation(ctx, room)
+        if err != nil {
+            return nil, err
+        }
+        conversations = append(conversations, *conversation)
+    }
+    return conversations, nil
+}
+
+// Get loads a group conversation the user takes part in.
+func (s *ConversationService) Get(ctx context.Context, roomID, userID uuid.UUID) (database.Room, error) {
+    room, err := s.db.GetRoomByID(ctx, roomID)
+    if errors.Is(err, pgx.ErrNoRows) || (err == nil && room.Kind != RoomKindGroupDM) {
+        return database.Room{}, ErrConversationNotFound
+    }
+    if err != nil {
+        return database.Room{}, err
+    }
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return database.Room{}, err
+    }
+    if !isMember {
+        return database.Room{}, ErrConversationNotFound
+    }
+    return room, nil
+}
+
+// AddParticipant adds a user to the conversation and notifies their open
+// connections. Any participant can add people. Callers are responsible for
+// checking that actorID takes part in the conversation.
+func (s *ConversationService) AddParticipant(ctx context.Context, room database.Room, actorID, userID uuid.UUID) (*Conversation, error) {
+    count, err := s.db.CountRoomMembers(ctx, room.ID)
+    if err != nil {
+        return nil, err
+    }
+    if count >= MaxConversationParticipants {
+        return nil, ErrTooManyParticipants
+    }
+
+    err = s.db.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: room.ID, UserID: userID})
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) {
+        switch pgErr.Code {
+        case "23503": // foreign_key_violation
+            return nil, ErrUserNotFound
+        case "23505": // unique_violation
+            return nil, ErrAlreadyMember
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    conversation, err := s.conversation(ctx, room)
+    if err != nil {
+        return nil, err
+    }
+    s.notifyAdded(conversation, actorID, userID)
+    return conversation, nil
+}
+
+// RemoveParticipant removes a user from the conversation and closes their
+// live connection to it. Participants can leave, and the creator can remove
+// anyone. When the creator leaves, the longest-standing participant becomes
+// the creator; when the last participant leaves, the conversation is
+// deleted. Callers are responsible for checking that actorID takes part in
+// the conversation.
+func (s *ConversationService) RemoveParticipant(ctx context.Context, room database.Room, actorID, userID uuid.UUID) error {
+    if actorID != userID && actorID != room.OwnerID {
+        return ErrNotConversationCreator
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    isMember, err := qtx.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: room.ID, UserID: userID})
+    if err != nil {
+        return err
+    }
+    if !isMember {
+        return ErrNotParticipant
+    }
+    if err := qtx.RemoveRoomMember(ctx, database.RemoveRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+        return err
+    }
+    if userID == room.OwnerID {
+        successorID, err := qtx.GetRoomSuccessor(ctx, database.GetRoomSuccessorParams{RoomID: room.ID, OwnerID: userID})
+        switch {
+        case errors.Is(err, pgx.ErrNoRows):
+            if err := deleteEmptyConversation(ctx, qtx, room.ID); err != nil {
+                return err
+            }
+        case err != nil:
+            return err
+        default:
+            if _, err := qtx.TransferRoomOwnership(ctx, database.TransferRoomOwnershipParams{ID: room.ID, OwnerID: successorID}); err != nil {
+                return err
+            }
+        }
+    } else {
+        count, err := qtx.CountRoomMembers(ctx, room.ID)
+        if err != nil {
+            return err
+        }
+        if count == 0 {
+            if err := deleteEmptyConversation(ctx, qtx, room.ID); err != nil {
+                return err
+            }
+        }
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return err
+    }
+
+    s.hub.Disconnect(room.ID, userID, "removed from the conversation")
+    return nil
+}
+
+// deleteEmptyConversation deletes a conversation its last participant left,
+// unless its messages are under legal hold; then it stays, with nobody in
+// it, until the hold is released.
+func deleteEmptyConversation(ctx context.Context, qtx *database.Queries, roomID uuid.UUID) error {
+    held, err := qtx.IsRoomContentHeld(ctx, roomID)
+    if err != nil || held {
+        return err
+    }
+    return qtx.DeleteRoom(ctx, roomID)
+}
+
+// conversation loads the participants of a group conversation.
+func (s *ConversationService) conversation(ctx context.Context, room database.Room) (*Conversation, error) {
+    members, err := s.db.GetRoomMembers(ctx, room.ID)
+    if err != nil {
+        return nil, err
+    }
+    conversation := &Conversation{
+        ID:           room.ID.String(),
+        Name:         room.Name,
+        CreatedBy:    room.OwnerID.String(),
+        CreatedAt:    room.CreatedAt,
+        Participants: make([]Participant, 0, len(members)),
+        Encrypted:    room.EncryptedAt != nil,
+        EncryptedAt:  room.EncryptedAt,
+    }
+    for _, member := range members {
+        participant := Participant{UserID: member.ID.String()}
+        participant.Username, participant.DisplayName = s.hub.DisplayNames().Names(member.Username, member.DisplayName)
+        conversation.Participants = append(conversation.Participants, participant)
+    }
+    return conversation, nil
+}
+
+// notifyAdded tells userID's open connections that actorID added them to the
+// conversation.
+func (s *ConversationService) notifyAdded(conversation *Conversation, actorID, userID uuid.UUID) {
+    s.hub.Broadcast(&Message{
+        Type:         EventConversationAdded,
+        SenderID:     actorID.String(),
+        RecipientID:  userID.String(),
+        RoomID:       conversation.ID,
+        CreatedAt:    time.Now(),
+        Conversation: conversation,
+    })
+}
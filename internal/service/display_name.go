@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DisplayNamePolicy decides which of a user's names other users see: next to
+// their messages, in member lists and among conversation participants. It is
+// set for the whole server, for organizations that require real names or
+// handles.
+type DisplayNamePolicy string
+
+// Display name policies.
+const (
+    // DisplayNameUsername shows usernames only.
+    DisplayNameUsername DisplayNamePolicy = "username"
+    // DisplayNameOnly shows display names instead of usernames. Users who
+    // have not set a display name are still shown by username.
+    DisplayNameOnly DisplayNamePolicy = "display_name"
+    // DisplayNameBoth shows usernames and, when set, display names.
+    DisplayNameBoth DisplayNamePolicy = "both"
+)
+
+// MaxDisplayNameLength is the longest a display name can be, in characters.
+const MaxDisplayNameLength = 64
+
+// ErrInvalidDisplayName is returned for overlong display names.
+var ErrInvalidDisplayName = errors.New("display_name must be at most 64 characters")
+
+// ParseDisplayNamePolicy parses a policy name; the empty string means
+// DisplayNameUsername.
+func ParseDisplayNamePolicy(v string) (DisplayNamePolicy, error) {
+    switch policy := DisplayNamePolicy(v); policy {
+    case "":
+        return DisplayNameUsername, nil
+    case DisplayNameUsername, DisplayNameOnly, DisplayNameBoth:
+        return policy, nil
+    }
+    return "", fmt.Errorf("display name policy must be username, display_name or both, not %q", v)
+}
+
+// Names returns the username and display name to show for a user under the
+// policy; either is empty when it is not shown.
+func (p DisplayNamePolicy) Names(username, displayName string) (string, string) {
+    switch {
+    case displayName == "" || p == DisplayNameUsername || p == "":
+        return username, ""
+    case p == DisplayNameOnly:
+        return "", displayName
+    }
+    return username, displayName
+}
+
+// Profile builds the sender profile shown for a user under the policy.
+func (p DisplayNamePolicy) Profile(username, displayName string, avatarURL *string) *SenderProfile {
+    profile := &SenderProfile{AvatarURL: avatarURL}
+    profile.Username, profile.DisplayName = p.Names(username, displayName)
+    return profile
+}
+
+// NormalizeDisplayName trims a display name and checks its length. The empty
+// string clears it.
+func NormalizeDisplayName(displayName string) (string, error) {
+    displayName = strings.TrimSpace(displayName)
+    if utf8.RuneCountInString(displayName) > MaxDisplayNameLength {
+        return "", ErrInvalidDisplayName
+    }
+    return displayName, nil
+}
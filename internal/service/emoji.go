@@ -0,0 +1,119 @@
+package service
+
+import "regexp"
+
+// shortcodePattern matches :smile:-style emoji shortcodes.
+var shortcodePattern = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+
+// emojiShortcodes maps the commonly used shortcodes to their Unicode emoji.
+var emojiShortcodes = map[string]string{
+    ":smile:":                 "😄",
+    ":smiley:":                "😃",
+    ":grin:":                  "😁",
+    ":laughing:":              "😆",
+    ":joy:":                   "😂",
+    ":rofl:":                  "🤣",
+    ":slightly_smiling_face:": "🙂",
+    ":wink:":                  "😉",
+    ":blush:":                 "😊",
+    ":innocent:":              "😇",
+    ":heart_eyes:":            "😍",
+    ":kissing_heart:":         "😘",
+    ":yum:":                   "😋",
+    ":stuck_out_tongue:":      "😛",
+    ":thinking:":              "🤔",
+    ":neutral_face:":          "😐",
+    ":expressionless:":        "😑",
+    ":unamused:":              "😒",
+    ":roll_eyes:":             "🙄",
+    ":grimacing:":             "😬",
+    ":relieved:":              "😌",
+    ":pensive:":               "😔",
+    ":sleepy:":                "😪",
+    ":sleeping:":              "😴",
+    ":mask:":                  "😷",
+    ":sunglasses:":            "😎",
+    ":confused:":              "😕",
+    ":worried:":               "😟",
+    ":open_mouth:":            "😮",
+    ":astonished:":            "😲",
+    ":flushed:":               "😳",
+    ":pleading_face:":         "🥺",
+    ":cry:":                   "😢",
+    ":sob:":                   "😭",
+    ":scream:":                "😱",
+    ":angry:":                 "😠",
+    ":rage:":                  "😡",
+    ":skull:":                 "💀",
+    ":poop:":                  "💩",
+    ":clown_face:":            "🤡",
+    ":ghost:":                 "👻",
+    ":robot:":                 "🤖",
+    ":wave:":                  "👋",
+    ":ok_hand:":               "👌",
+    ":v:":                     "✌️",
+    ":crossed_fingers:":       "🤞",
+    ":point_up:":              "☝️",
+    ":point_down:":            "👇",
+    ":point_left:":            "👈",
+    ":point_right:":           "👉",
+    ":+1:":                    "👍",
+    ":thumbsup:":              "👍",
+    ":-1:":                    "👎",
+    ":thumbsdown:":            "👎",
+    ":clap:":                  "👏",
+    ":raised_hands:":          "🙌",
+    ":pray:":                  "🙏",
+    ":muscle:":                "💪",
+    ":eyes:":                  "👀",
+    ":heart:":                 "❤️",
+    ":broken_heart:":          "💔",
+    ":sparkling_heart:":       "💖",
+    ":100:":                   "💯",
+    ":fire:":                  "🔥",
+    ":star:":                  "⭐",
+    ":sparkles:":              "✨",
+    ":tada:":                  "🎉",
+    ":confetti_ball:":         "🎊",
+    ":gift:":                  "🎁",
+    ":trophy:":                "🏆",
+    ":rocket:":                "🚀",
+    ":zap:":                   "⚡",
+    ":boom:":                  "💥",
+    ":bulb:":                  "💡",
+    ":warning:":               "⚠️",
+    ":x:":                     "❌",
+    ":white_check_mark:":      "✅",
+    ":heavy_check_mark:":      "✔️",
+    ":question:":              "❓",
+    ":exclamation:":           "❗",
+    ":coffee:":                "☕",
+    ":beer:":                  "🍺",
+    ":pizza:":                 "🍕",
+    ":cake:":                  "🍰",
+    ":sun:":                   "☀️",
+    ":rainbow:":               "🌈",
+    ":snowflake:":             "❄️",
+    ":bug:":                   "🐛",
+    ":dog:":                   "🐶",
+    ":cat:":                   "🐱",
+    ":see_no_evil:":           "🙈",
+    ":memo:":                  "📝",
+    ":calendar:":              "📆",
+    ":lock:":                  "🔒",
+    ":key:":                   "🔑",
+    ":bell:":                  "🔔",
+    ":link:":                  "🔗",
+    ":hourglass:":             "⌛",
+}
+
+// NormalizeShortcodes replaces known emoji shortcodes in content with their
+// Unicode form. Unknown shortcodes are left as they are.
+func NormalizeShortcodes(content string) string {
+    return shortcodePattern.ReplaceAllStringFunc(content, func(code string) string {
+        if emoji, ok := emojiShortcodes[code]; ok {
+            return emoji
+        }
+        return code
+    })
+}
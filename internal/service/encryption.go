@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// FrameEncrypted is sent by clients in end-to-end encrypted conversations
+// instead of message frames. Its payload is a message whose content is
+// ciphertext; the server stores and relays it as an encrypted message without
+// reading it.
+const FrameEncrypted = "encrypted"
+
+// MessageKindEncrypted is the kind of messages whose content is ciphertext.
+const MessageKindEncrypted = "encrypted"
+
+// EventConversationEncrypted is the type of the event sent to a conversation
+// when it opts in to end-to-end encryption.
+const EventConversationEncrypted = "conversation.encrypted"
+
+// ErrorCodeEncryptionRequired is sent for message frames in an end-to-end
+// encrypted conversation.
+const ErrorCodeEncryptionRequired = "encryption_required"
+
+var (
+    // ErrEncryptionRequired is returned for plaintext messages sent to an
+    // end-to-end encrypted conversation.
+    ErrEncryptionRequired = errors.New("this conversation is end-to-end encrypted; send encrypted frames only")
+    // ErrNotEncrypted is returned for encrypted messages sent to a
+    // conversation that has not opted in to end-to-end encryption.
+    ErrNotEncrypted = errors.New("this conversation is not end-to-end encrypted")
+)
+
+// EnableEncryption opts the conversation in to end-to-end encryption and tells
+// its connected participants. From then on only encrypted frames are accepted
+// in it; opting in cannot be undone. Any participant can opt in, and opting in
+// again changes nothing. Callers are responsible for checking that actorID
+// takes part in the conversation.
+func (s *ConversationService) EnableEncryption(ctx context.Context, room database.Room, actorID uuid.UUID) (*Conversation, error) {
+    if room.EncryptedAt == nil {
+        var err error
+        room, err = s.db.EnableRoomEncryption(ctx, room.ID)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    conversation, err := s.conversation(ctx, room)
+    if err != nil {
+        return nil, err
+    }
+    s.hub.Broadcast(&Message{
+        Type:         EventConversationEncrypted,
+        SenderID:     actorID.String(),
+        RoomID:       conversation.ID,
+        CreatedAt:    time.Now(),
+        Conversation: conversation,
+    })
+    return conversation, nil
+}
+
+// markEncrypted makes the room's connections, and those opened later, accept
+// encrypted frames only. It runs on the hub goroutine; rooms that opted in
+// before the server started are flagged by their connections' options.
+func (h *Hub) markEncrypted(roomID string) {
+    h.encryptedRooms[roomID] = true
+    for _, devices := range h.clients[roomID] {
+        for client := range devices {
+            client.encrypted.Store(true)
+        }
+    }
+}
+
+// handleEncrypted stores and relays an encrypted message, as handleMessage
+// does for plaintext ones.
+func (c *Client) handleEncrypted(env Envelope) {
+    if !c.encrypted.Load() {
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: ErrNotEncrypted.Error()})
+        return
+    }
+    c.handleMessage(env, MessageKindEncrypted)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Frame types carried in envelopes. Server events about existing messages,
+// such as poll.updated, use the event name as their frame type.
+const (
+    FrameMessage  = "message"
+    FrameTyping   = "typing"
+    FramePresence = "presence"
+    FrameAck      = "ack"
+    FrameError    = "error"
+)
+
+// Event types for hub traffic that is not a chat message.
+const (
+    EventTyping   = "typing"
+    EventPresence = "presence"
+    EventAck      = "ack"
+)
+
+// Presence statuses. Away is only reported by Hub.PresenceOf; presence
+// frames report users as online until they go offline.
+const (
+    PresenceOnline  = "online"
+    PresenceAway    = "away"
+    PresenceOffline = "offline"
+)
+
+// ErrorCodeUnknownFrame is sent for frames of a type clients may not send.
+const ErrorCodeUnknownFrame = "unknown_frame_type"
+
+// Envelope wraps every WebSocket frame in either direction. For frames sent by
+// a client, ID is chosen by the client and echoed in the matching ack or error;
+// for messages sent by the server it is the message ID.
+type Envelope struct {
+    Type    string          `json:"type"`
+    ID      string          `json:"id,omitempty"`
+    Payload json.RawMessage `json:"payload,omitempty"`
+    TS      time.Time       `json:"ts"`
+}
+
+// Ack confirms to its sender that a message was stored. A resent message is
+// answered with the same ack as the original.
+type Ack struct {
+    MessageID   string    `json:"message_id"`
+    ClientMsgID string    `json:"client_msg_id,omitempty"`
+    Seq         int64     `json:"seq"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+// Typing reports that a user started or stopped typing.
+type Typing struct {
+    UserID string `json:"user_id"`
+    Typing bool   `json:"typing"`
+}
+
+// Presence reports that a user connected to or left the room.
+type Presence struct {
+    UserID string `json:"user_id"`
+    Status string `json:"status"`
+}
+
+// envelopeFor wraps a hub message in the envelope clients receive.
+func envelopeFor(message *Message) (Envelope, error) {
+    env := Envelope{Type: message.Type, ID: message.ID, TS: message.CreatedAt}
+    var payload any = message
+    switch message.Type {
+    case "":
+        env.Type = FrameMessage
+    case EventError:
+        env.Type, env.ID, payload = FrameError, message.FrameID, message.Error
+    case EventAck:
+        env.Type, env.ID, payload = FrameAck, message.FrameID, message.Ack
+    case EventTyping:
+        env.Type, payload = FrameTyping, message.Typing
+    case EventPresence:
+        env.Type, payload = FramePresence, message.Presence
+    case EventPresenceOnline, EventPresenceOffline:
+        payload = message.Presence
+    case EventPresenceSnapshot:
+        payload = message.PresenceSnapshot
+    case EventPresenceStatus:
+        payload = message.Status
+    case EventPresenceSubscribed:
+        env.ID, payload = message.FrameID, message.PresenceSubscription
+    case EventUnread:
+        env.Type, payload = FrameUnread, message.Unread
+    case EventHeartbeat:
+        env.Type, env.ID, payload = FrameHeartbeat, message.FrameID, message.Heartbeat
+    case EventMessagesPurged:
+        payload = message.Purge
+    case EventMessageReported:
+        payload = message.Report
+    case EventInvite:
+        payload = message.Invite
+    case EventRoomUpdated:
+        payload = message.Room
+    case EventRoomAnnouncement:
+        payload = message.Announcement
+    case EventMessagePinned, EventMessageUnpinned:
+        payload = message.Pin
+    case EventCommandResult:
+        env.ID, payload = message.FrameID, message.Command
+    case EventMembersChanged:
+        payload = message.Members
+    case EventConversationAdded, EventConversationEncrypted:
+        payload = message.Conversation
+    case EventImpersonated:
+        payload = message.Impersonation
+    case EventFoldersChanged:
+        payload = message.Folders
+    }
+
+    var err error
+    env.Payload, err = json.Marshal(payload)
+    return env, err
+}
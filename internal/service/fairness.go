@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrorCodeThrottled is sent when a busy room's fair-share scheduling holds
+// back a user's message.
+const ErrorCodeThrottled = "throttled"
+
+// RoomFairness configures how the hub shares a busy room between its senders.
+// Every message costs one delivery per client connected to the room, so
+// messages in large rooms cost more. While a room's deliveries within Window
+// stay under Budget everyone may send; once the room is saturated, each
+// sender is held to an equal share of Budget until their older messages
+// leave the window.
+type RoomFairness struct {
+    // Budget is how many deliveries a room may make within Window before
+    // senders are held to their share. Zero disables fair sharing.
+    Budget int
+    Window time.Duration
+}
+
+// roomScheduler accounts the cost of the messages sent to each room.
+type roomScheduler struct {
+    opts RoomFairness
+
+    mu        sync.Mutex
+    rooms     map[string][]sentCost
+    connected map[string]int
+}
+
+// sentCost is the cost of one message sent to a room.
+type sentCost struct {
+    at     time.Time
+    userID string
+    cost   int
+}
+
+func newRoomScheduler(opts RoomFairness) *roomScheduler {
+    if opts.Budget <= 0 || opts.Window <= 0 {
+        return nil
+    }
+    return &roomScheduler{
+        opts:      opts,
+        rooms:     make(map[string][]sentCost),
+        connected: make(map[string]int),
+    }
+}
+
+// setConnected records how many clients are connected to the room, which
+// sets the cost of its messages. A nil scheduler ignores it.
+func (s *roomScheduler) setConnected(roomID string, n int) {
+    if s == nil {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if n == 0 {
+        delete(s.connected, roomID)
+        return
+    }
+    s.connected[roomID] = n
+}
+
+// allow reports whether the user may send a message to the room now and, if
+// so, accounts for it. If not, it returns the error frame to send back. A nil
+// scheduler allows everything.
+func (s *roomScheduler) allow(roomID, userID string) (*ErrorFrame, bool) {
+    if s == nil {
+        return nil, true
+    }
+    now := time.Now()
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    sent := s.rooms[roomID]
+    start := 0
+    for start < len(sent) && now.Sub(sent[start].at) >= s.opts.Window {
+        start++
+    }
+    sent = sent[start:]
+
+    cost := max(1, s.connected[roomID])
+    total, used := 0, 0
+    senders := map[string]bool{userID: true}
+    for _, c := range sent {
+        total += c.cost
+        senders[c.userID] = true
+        if c.userID == userID {
+            used += c.cost
+        }
+    }
+    share := s.opts.Budget / len(senders)
+    if total+cost > s.opts.Budget && used+cost > share {
+        s.rooms[roomID] = sent
+        return throttledFrame(s.retryAfter(sent, userID, used+cost-share, now)), false
+    }
+
+    s.rooms[roomID] = append(sent, sentCost{at: now, userID: userID, cost: cost})
+    return nil, true
+}
+
+// retryAfter returns how long until excess of the user's cost has left the
+// window.
+func (s *roomScheduler) retryAfter(sent []sentCost, userID string, excess int, now time.Time) time.Duration {
+    for _, c := range sent {
+        if c.userID != userID {
+            continue
+        }
+        excess -= c.cost
+        if excess <= 0 {
+            return c.at.Add(s.opts.Window).Sub(now)
+        }
+    }
+    return s.opts.Window
+}
+
+func throttledFrame(wait time.Duration) *ErrorFrame {
+    return &ErrorFrame{
+        Code:       ErrorCodeThrottled,
+        Reason:     "this room is busy and you have used your share of it",
+        RetryAfter: retryAfterSeconds(wait),
+    }
+}
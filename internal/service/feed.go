@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Notification kinds shown in the activity feed.
+const (
+    NotificationKindMention = "mention"
+    NotificationKindReply   = "reply"
+)
+
+// FeedItem is an entry in a user's activity feed.
+type FeedItem struct {
+    ID       string `json:"id"`
+    Kind     string `json:"kind"`
+    RoomID   string `json:"room_id"`
+    RoomName string `json:"room_name"`
+    // Actor is the user whose action created the item, e.g. the author of a reply.
+    Actor     *SenderProfile `json:"actor,omitempty"`
+    ActorID   string         `json:"actor_id,omitempty"`
+    Message   *Message       `json:"message"`
+    CreatedAt time.Time      `json:"created_at"`
+    ReadAt    *time.Time     `json:"read_at,omitempty"`
+}
+
+// GetFeed returns up to limit of the user's activity feed items across all
+// rooms they belong to, newest first. When cursorCreatedAt is non-nil only
+// items older than (cursorCreatedAt, cursorID) are returned.
+func (s *MessageService) GetFeed(ctx context.Context, userID uuid.UUID, limit int32, cursorCreatedAt *time.Time, cursorID *uuid.UUID) ([]*FeedItem, error) {
+    feed, err := s.db.GetUserFeed(ctx, database.GetUserFeedParams{
+        UserID:          userID,
+        CursorCreatedAt: cursorCreatedAt,
+        CursorID:        cursorID,
+        MaxResults:      limit,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    rows := make([]database.Message, len(feed))
+    for i, row := range feed {
+        rows[i] = row.Message
+    }
+    messages, err := s.hydrate(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+
+    items := make([]*FeedItem, len(feed))
+    for i, row := range feed {
+        n := row.Notification
+        item := &FeedItem{
+            ID:        n.ID.String(),
+            Kind:      n.Kind,
+            RoomID:    n.RoomID.String(),
+            RoomName:  row.RoomName,
+            Message:   messages[i],
+            CreatedAt: n.CreatedAt,
+            ReadAt:    n.ReadAt,
+        }
+        if n.ActorID != nil && row.ActorUsername != nil {
+            item.ActorID = n.ActorID.String()
+            var displayName string
+            if row.ActorDisplayName != nil {
+                displayName = *row.ActorDisplayName
+            }
+            item.Actor = s.opts.DisplayNames.Profile(*row.ActorUsername, displayName, row.ActorAvatarUrl)
+        }
+        items[i] = item
+    }
+    return items, nil
+}
+
+// notifyReply records a reply notification for the author of the quoted
+// message, unless they wrote the reply, cannot see it, were already notified
+// of it as a mention or muted the room.
+func (s *MessageService) notifyReply(ctx context.Context, reply, quoted database.Message) {
+    author := quoted.SenderID
+    if author == reply.SenderID || slices.Contains(reply.Mentions, author) {
+        return
+    }
+    if reply.RecipientID != nil && *reply.RecipientID != author {
+        return
+    }
+    level, err := roomNotificationLevel(ctx, s.db, reply.RoomID, author)
+    if err != nil {
+        log.Printf("failed to record reply notification for message %s: %v", reply.ID, err)
+        return
+    }
+    if level == NotificationLevelNone {
+        return
+    }
+    err = s.db.CreateNotifications(ctx, database.CreateNotificationsParams{
+        UserIds:   []uuid.UUID{author},
+        RoomID:    reply.RoomID,
+        MessageID: reply.ID,
+        Kind:      NotificationKindReply,
+        ActorID:   reply.SenderID,
+    })
+    if err != nil {
+        log.Printf("failed to record reply notification for message %s: %v", reply.ID, err)
+    }
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mxhdiqaim/go-chat-app/internal/ratelimit"
+)
+
+// Error codes sent when flood control rejects a message.
+const (
+    ErrorCodeRateLimited = "rate_limited"
+    ErrorCodeMuted       = "muted"
+)
+
+// strikeWindow is how long a rate limit violation counts towards a mute.
+const strikeWindow = time.Minute
+
+// FloodControl configures per-user message rate limiting in the hub.
+type FloodControl struct {
+    // Rate is the sustained number of messages per second a user may send.
+    // Zero disables flood control.
+    Rate float64
+    // Burst is how many messages a user may send at once.
+    Burst int
+    // MuteAfter mutes a user once they exceed the rate this many times within
+    // a minute. Zero disables muting.
+    MuteAfter int
+    // MuteDuration is how long a muted user's messages are rejected.
+    MuteDuration time.Duration
+}
+
+// floodGuard applies FloodControl to users across all their connections.
+type floodGuard struct {
+    opts    FloodControl
+    limiter *ratelimit.Limiter
+
+    mu         sync.Mutex
+    strikes    map[string][]time.Time
+    mutedUntil map[string]time.Time
+}
+
+func newFloodGuard(opts FloodControl) *floodGuard {
+    if opts.Rate <= 0 {
+        return nil
+    }
+    if opts.Burst < 1 {
+        opts.Burst = 1
+    }
+    return &floodGuard{
+        opts:       opts,
+        limiter:    ratelimit.New(opts.Rate, opts.Burst),
+        strikes:    make(map[string][]time.Time),
+        mutedUntil: make(map[string]time.Time),
+    }
+}
+
+// allow reports whether the user may send a message now. If not, it returns
+// the error frame to send back. A nil guard allows everything.
+func (g *floodGuard) allow(userID string) (*ErrorFrame, bool) {
+    if g == nil {
+        return nil, true
+    }
+    now := time.Now()
+
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    if until, ok := g.mutedUntil[userID]; ok {
+        if now.Before(until) {
+            return mutedFrame(until.Sub(now)), false
+        }
+        delete(g.mutedUntil, userID)
+    }
+
+    if g.limiter.Allow(userID) {
+        return nil, true
+    }
+
+    if g.opts.MuteAfter > 0 {
+        strikes := append(recentStrikes(g.strikes[userID], now), now)
+        if len(strikes) >= g.opts.MuteAfter {
+            delete(g.strikes, userID)
+            g.mutedUntil[userID] = now.Add(g.opts.MuteDuration)
+            return mutedFrame(g.opts.MuteDuration), false
+        }
+        g.strikes[userID] = strikes
+    }
+    return &ErrorFrame{
+        Code:       ErrorCodeRateLimited,
+        Reason:     "you are sending messages too fast",
+        RetryAfter: retryAfterSeconds(time.Duration(float64(time.Second) / g.opts.Rate)),
+    }, false
+}
+
+// recentStrikes drops strikes older than strikeWindow.
+func recentStrikes(strikes []time.Time, now time.Time) []time.Time {
+    recent := strikes[:0]
+    for _, t := range strikes {
+        if now.Sub(t) < strikeWindow {
+            recent = append(recent, t)
+        }
+    }
+    return recent
+}
+
+func mutedFrame(remaining time.Duration) *ErrorFrame {
+    seconds := retryAfterSeconds(remaining)
+    return &ErrorFrame{
+        Code:       ErrorCodeMuted,
+        Reason:     fmt.Sprintf("you are muted for %d seconds for flooding", seconds),
+        RetryAfter: seconds,
+    }
+}
+
+// retryAfterSeconds rounds d up to whole seconds, with a minimum of one.
+func retryAfterSeconds(d time.Duration) int {
+    return max(1, int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventFoldersChanged is the type of the event sent to every connection of a
+// user whose folders changed, so their other devices stay in sync. It carries
+// the user's folders.
+const EventFoldersChanged = "folders.changed"
+
+const (
+    // MaxFolders is how many folders a user can have.
+    MaxFolders = 50
+    // MaxFolderNameLength is the longest a folder name can be, in characters.
+    MaxFolderNameLength = 64
+)
+
+var (
+    // ErrFolderNotFound is returned for folders that do not exist or belong
+    // to someone else.
+    ErrFolderNotFound = errors.New("folder not found")
+    // ErrInvalidFolderName is returned for empty or overlong folder names.
+    ErrInvalidFolderName = errors.New("folder names must be 1-64 characters")
+    // ErrFolderNameTaken is returned when the user already has a folder with
+    // the name.
+    ErrFolderNameTaken = errors.New("a folder with this name already exists")
+    // ErrTooManyFolders is returned when a user with MaxFolders folders
+    // creates another.
+    ErrTooManyFolders = errors.New("users can have at most 50 folders")
+)
+
+// Folder is a named group of rooms and conversations a user made to organize
+// them.
+type Folder struct {
+    ID   string `json:"id"`
+    Name string `json:"name" example:"Work"`
+    // Position orders the user's folders, lowest first.
+    Position  int32     `json:"position" example:"0"`
+    CreatedAt time.Time `json:"created_at"`
+    // RoomIDs are the rooms in the folder the user is still a member of.
+    RoomIDs []string `json:"room_ids"`
+}
+
+// Sidebar lists the rooms and conversations a user is a member of, sorted
+// into their folders.
+type Sidebar struct {
+    Folders []SidebarFolder `json:"folders"`
+    // Rooms are those not in any folder.
+    Rooms []SidebarRoom `json:"rooms"`
+}
+
+// SidebarFolder is one of a user's folders with its rooms.
+type SidebarFolder struct {
+    ID       string        `json:"id"`
+    Name     string        `json:"name" example:"Work"`
+    Position int32         `json:"position" example:"0"`
+    Rooms    []SidebarRoom `json:"rooms"`
+}
+
+// SidebarRoom is a room or conversation in a user's sidebar, most recently
+// active first, with the user's unread counts for it.
+type SidebarRoom struct {
+    ID   string `json:"id"`
+    Name string `json:"name" example:"General"`
+    // Kind is "room", or "group_dm" for group conversations.
+    Kind           string    `json:"kind" example:"room"`
+    LastActivityAt time.Time `json:"last_activity_at"`
+    Unread         int64     `json:"unread" example:"3"`
+    Mentions       int64     `json:"mentions" example:"1"`
+}
+
+// FolderService manages users' folders.
+type FolderService struct {
+    db  *database.Queries
+    hub *Hub
+}
+
+// NewFolderService creates a new FolderService.
+func NewFolderService(db *database.Queries, hub *Hub) *FolderService {
+    return &FolderService{db: db, hub: hub}
+}
+
+// Create adds an empty folder after the user's other folders.
+func (s *FolderService) Create(ctx context.Context, userID uuid.UUID, name string) (*Folder, error) {
+    name, err := normalizeFolderName(name)
+    if err != nil {
+        return nil, err
+    }
+    count, err := s.db.CountUserFolders(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    if count >= MaxFolders {
+        return nil, ErrTooManyFolders
+    }
+
+    row, err := s.db.CreateUserFolder(ctx, database.CreateUserFolderParams{ID: uuid.New(), UserID: userID, Name: name})
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+        return nil, ErrFolderNameTaken
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.notify(ctx, userID)
+    return folderFromRow(row, nil), nil
+}
+
+// Folders returns the user's folders in order.
+func (s *FolderService) Folders(ctx context.Context, userID uuid.UUID) ([]Folder, error) {
+    rows, err := s.db.GetUserFolders(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    rooms, err := s.db.GetUserFolderRooms(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    roomIDs := make(map[uuid.UUID][]string)
+    for _, room := range rooms {
+        roomIDs[room.FolderID] = append(roomIDs[room.FolderID], room.RoomID.String())
+    }
+    folders := make([]Folder, 0, len(rows))
+    for _, row := range rows {
+        folders = append(folders, *folderFromRow(row, roomIDs[row.ID]))
+    }
+    return folders, nil
+}
+
+// Update renames or moves one of the user's folders. Nil fields are left as
+// they are.
+func (s *FolderService) Update(ctx context.Context, userID, folderID uuid.UUID, name *string, position *int32) (*Folder, error) {
+    if name != nil {
+        normalized, err := normalizeFolderName(*name)
+        if err != nil {
+            return nil, err
+        }
+        name = &normalized
+    }
+
+    row, err := s.db.UpdateUserFolder(ctx, database.UpdateUserFolderParams{ID: folderID, UserID: userID, Name: name, Position: position})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrFolderNotFound
+    }
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+        return nil, ErrFolderNameTaken
+    }
+    if err != nil {
+        return nil, err
+    }
+    // The folder's rooms come with the folders sent to the user's devices.
+    folders, _ := s.notify(ctx, userID)
+    for _, folder := range folders {
+        if folder.ID == row.ID.String() {
+            return &folder, nil
+        }
+    }
+    return folderFromRow(row, nil), nil
+}
+
+// Delete deletes one of the user's folders. Its rooms are not in any folder
+// afterwards.
+func (s *FolderService) Delete(ctx context.Context, userID, folderID uuid.UUID) error {
+    deleted, err := s.db.DeleteUserFolder(ctx, database.DeleteUserFolderParams{ID: folderID, UserID: userID})
+    if err != nil {
+        return err
+    }
+    if deleted == 0 {
+        return ErrFolderNotFound
+    }
+    s.notify(ctx, userID)
+    return nil
+}
+
+// AddRoom puts a room the user is a member of in one of their folders,
+// taking it out of the folder it was in.
+func (s *FolderService) AddRoom(ctx context.Context, userID, folderID, roomID uuid.UUID) error {
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return err
+    }
+    if !isMember {
+        return ErrNotRoomMember
+    }
+
+    err = s.db.SetUserFolderRoom(ctx, database.SetUserFolderRoomParams{UserID: userID, RoomID: roomID, FolderID: folderID})
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+        return ErrFolderNotFound
+    }
+    if err != nil {
+        return err
+    }
+    s.notify(ctx, userID)
+    return nil
+}
+
+// RemoveRoom takes a room out of one of the user's folders.
+func (s *FolderService) RemoveRoom(ctx context.Context, userID, folderID, roomID uuid.UUID) error {
+    removed, err := s.db.RemoveUserFolderRoom(ctx, database.RemoveUserFolderRoomParams{UserID: userID, RoomID: roomID, FolderID: folderID})
+    if err != nil {
+        return err
+    }
+    if removed == 0 {
+        return ErrFolderNotFound
+    }
+    s.notify(ctx, userID)
+    return nil
+}
+
+// Sidebar returns the rooms and conversations the user is a member of,
+// sorted into their folders, with unread counts.
+func (s *FolderService) Sidebar(ctx context.Context, userID uuid.UUID) (*Sidebar, error) {
+    folders, err := s.db.GetUserFolders(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    rooms, err := s.db.GetUserSidebarRooms(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    unreads, err := s.db.GetUserUnreads(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    unreadByRoom := make(map[uuid.UUID]database.GetUserUnreadsRow, len(unreads))
+    for _, unread := range unreads {
+        unreadByRoom[unread.RoomID] = unread
+    }
+
+    sidebar := &Sidebar{Folders: make([]SidebarFolder, 0, len(folders)), Rooms: []SidebarRoom{}}
+    index := make(map[uuid.UUID]int, len(folders))
+    for i, folder := range folders {
+        index[folder.ID] = i
+        sidebar.Folders = append(sidebar.Folders, SidebarFolder{
+            ID:       folder.ID.String(),
+            Name:     folder.Name,
+            Position: folder.Position,
+            Rooms:    []SidebarRoom{},
+        })
+    }
+    for _, room := range rooms {
+        unread := unreadByRoom[room.ID]
+        entry := SidebarRoom{
+            ID:             room.ID.String(),
+            Name:           room.Name,
+            Kind:           room.Kind,
+            LastActivityAt: room.LastActivityAt,
+            Unread:         unread.UnreadCount,
+            Mentions:       unread.MentionCount,
+        }
+        if room.FolderID != nil {
+            if i, ok := index[*room.FolderID]; ok {
+                sidebar.Folders[i].Rooms = append(sidebar.Folders[i].Rooms, entry)
+                continue
+            }
+        }
+        sidebar.Rooms = append(sidebar.Rooms, entry)
+    }
+    return sidebar, nil
+}
+
+// notify sends the user's folders to all of their connections and returns
+// them.
+func (s *FolderService) notify(ctx context.Context, userID uuid.UUID) ([]Folder, error) {
+    folders, err := s.Folders(ctx, userID)
+    if err != nil {
+        log.Printf("failed to sync folders of %s: %v", userID, err)
+        return nil, err
+    }
+    s.hub.Broadcast(&Message{
+        Type:        EventFoldersChanged,
+        SenderID:    userID.String(),
+        RecipientID: userID.String(),
+        CreatedAt:   time.Now(),
+        Folders:     folders,
+    })
+    return folders, nil
+}
+
+// normalizeFolderName trims a folder name and checks its length.
+func normalizeFolderName(name string) (string, error) {
+    name = strings.TrimSpace(name)
+    if name == "" || utf8.RuneCountInString(name) > MaxFolderNameLength {
+        return "", ErrInvalidFolderName
+    }
+    return name, nil
+}
+
+func folderFromRow(row database.UserFolder, roomIDs []string) *Folder {
+    if roomIDs == nil {
+        roomIDs = []string{}
+    }
+    return &Folder{
+        ID:        row.ID.String(),
+        Name:      row.Name,
+        Position:  row.Position,
+        CreatedAt: row.CreatedAt,
+        RoomIDs:   roomIDs,
+    }
+}
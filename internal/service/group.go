@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+var (
+    // ErrInvalidGroupName is returned for group names that cannot be mentioned.
+    ErrInvalidGroupName = errors.New("group names must be 1-32 lowercase letters, digits, '_' or '-'")
+    // ErrGroupNameTaken is returned when the room already has a group with the name.
+    ErrGroupNameTaken = errors.New("a group with this name already exists in the room")
+    // ErrInvalidGroupMember is returned when a group member is not a member of the room.
+    ErrInvalidGroupMember = errors.New("group members must be members of the room")
+)
+
+// groupNamePattern restricts group names to what mentionPattern can match.
+var groupNamePattern = regexp.MustCompile(`^[a-z0-9_\-]{1,32}$`)
+
+// Group is a named set of room members that can be mentioned together, e.g. @oncall.
+type Group struct {
+    ID        string    `json:"id"`
+    RoomID    string    `json:"room_id"`
+    Name      string    `json:"name"`
+    MemberIDs []string  `json:"member_ids"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// GroupService manages the user groups of rooms.
+type GroupService struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+}
+
+// NewGroupService creates a new GroupService.
+func NewGroupService(db *database.Queries, pool *pgxpool.Pool) *GroupService {
+    return &GroupService{db: db, pool: pool}
+}
+
+// CreateGroup creates a group in a room with the given members.
+func (s *GroupService) CreateGroup(ctx context.Context, roomID, creatorID uuid.UUID, name string, memberIDs []uuid.UUID) (*Group, error) {
+    name = strings.ToLower(name)
+    if !groupNamePattern.MatchString(name) {
+        return nil, ErrInvalidGroupName
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    group, err := qtx.CreateRoomGroup(ctx, database.CreateRoomGroupParams{
+        ID:        uuid.New(),
+        RoomID:    roomID,
+        Name:      name,
+        CreatedBy: creatorID,
+    })
+    if err != nil {
+        return nil, groupError(err)
+    }
+    if err := setGroupMembers(ctx, qtx, group, memberIDs); err != nil {
+        return nil, err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    return groupFromRow(group, dedupeIDs(memberIDs)), nil
+}
+
+// GetGroup returns a group by ID.
+func (s *GroupService) GetGroup(ctx context.Context, groupID uuid.UUID) (database.RoomGroup, error) {
+    return s.db.GetRoomGroupByID(ctx, groupID)
+}
+
+// ListGroups returns the groups of a room with their current members.
+func (s *GroupService) ListGroups(ctx context.Context, roomID uuid.UUID) ([]*Group, error) {
+    rows, err := s.db.GetRoomGroups(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    members, err := s.db.GetRoomGroupMembers(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+
+    byGroup := make(map[uuid.UUID][]uuid.UUID)
+    for _, member := range members {
+        byGroup[member.GroupID] = append(byGroup[member.GroupID], member.UserID)
+    }
+    groups := make([]*Group, 0, len(rows))
+    for _, row := range rows {
+        groups = append(groups, groupFromRow(row, byGroup[row.ID]))
+    }
+    return groups, nil
+}
+
+// UpdateGroup renames a group and/or replaces its members. A nil name or
+// memberIDs leaves that part unchanged.
+func (s *GroupService) UpdateGroup(ctx context.Context, group database.RoomGroup, name *string, memberIDs []uuid.UUID) (*Group, error) {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    if name != nil {
+        lower := strings.ToLower(*name)
+        if !groupNamePattern.MatchString(lower) {
+            return nil, ErrInvalidGroupName
+        }
+        group, err = qtx.UpdateRoomGroupName(ctx, database.UpdateRoomGroupNameParams{ID: group.ID, Name: lower})
+        if err != nil {
+            return nil, groupError(err)
+        }
+    }
+    if memberIDs != nil {
+        if err := qtx.ClearRoomGroupMembers(ctx, group.ID); err != nil {
+            return nil, err
+        }
+        if err := setGroupMembers(ctx, qtx, group, memberIDs); err != nil {
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    groups, err := s.ListGroups(ctx, group.RoomID)
+    if err != nil {
+        return nil, err
+    }
+    for _, g := range groups {
+        if g.ID == group.ID.String() {
+            return g, nil
+        }
+    }
+    return groupFromRow(group, nil), nil
+}
+
+// DeleteGroup deletes a group.
+func (s *GroupService) DeleteGroup(ctx context.Context, groupID uuid.UUID) error {
+    return s.db.DeleteRoomGroup(ctx, groupID)
+}
+
+// setGroupMembers adds the users to the group, failing if any of them is not a
+// member of the group's room.
+func setGroupMembers(ctx context.Context, db *database.Queries, group database.RoomGroup, memberIDs []uuid.UUID) error {
+    memberIDs = dedupeIDs(memberIDs)
+    if len(memberIDs) == 0 {
+        return nil
+    }
+    added, err := db.AddRoomGroupMembers(ctx, database.AddRoomGroupMembersParams{
+        GroupID: group.ID,
+        RoomID:  group.RoomID,
+        UserIds: memberIDs,
+    })
+    if err != nil {
+        return err
+    }
+    if added != int64(len(memberIDs)) {
+        return ErrInvalidGroupMember
+    }
+    return nil
+}
+
+// groupError maps a unique violation on the group name to ErrGroupNameTaken.
+func groupError(err error) error {
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+        return ErrGroupNameTaken
+    }
+    return err
+}
+
+// dedupeIDs returns ids without duplicates, keeping their order.
+func dedupeIDs(ids []uuid.UUID) []uuid.UUID {
+    seen := make(map[uuid.UUID]bool, len(ids))
+    unique := make([]uuid.UUID, 0, len(ids))
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            unique = append(unique, id)
+        }
+    }
+    return unique
+}
+
+// groupFromRow converts a database group and its members into the API representation.
+func groupFromRow(row database.RoomGroup, memberIDs []uuid.UUID) *Group {
+    members := make([]string, len(memberIDs))
+    for i, id := range memberIDs {
+        members[i] = id.String()
+    }
+    return &Group{
+        ID:        row.ID.String(),
+        RoomID:    row.RoomID.String(),
+        Name:      row.Name,
+        MemberIDs: members,
+        CreatedAt: row.CreatedAt,
+    }
+}
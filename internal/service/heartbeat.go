@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// FrameHeartbeat is sent by clients to keep their connection alive and report
+// the latency they see; the server answers each with a heartbeat frame.
+const FrameHeartbeat = "heartbeat"
+
+// EventHeartbeat is the type of hub traffic answering a heartbeat.
+const EventHeartbeat = "heartbeat"
+
+const (
+    // heartbeatSamples is how many of a connection's latest reported
+    // latencies its stats cover.
+    heartbeatSamples = 20
+    // maxHeartbeatLatency is the highest latency a client can report;
+    // anything higher is ignored as bogus.
+    maxHeartbeatLatency = time.Minute
+)
+
+// Heartbeat is the payload of heartbeat frames. Clients send their clock and,
+// from the second heartbeat on, the round trip they measured for the previous
+// one. The server echoes ClientTime along with its own clock, so the client
+// can measure the next round trip.
+type Heartbeat struct {
+    ClientTime time.Time `json:"client_time"`
+    // LatencyMS is the round trip the client measured, in milliseconds.
+    LatencyMS  *float64  `json:"latency_ms,omitempty"`
+    ServerTime time.Time `json:"server_time,omitempty"`
+}
+
+// HeartbeatStats summarizes the heartbeats of a connection. The latency
+// figures cover the latest 20 reported round trips.
+type HeartbeatStats struct {
+    Heartbeats      int64     `json:"heartbeats" example:"42"`
+    LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+    // ClockOffsetMS is how far ahead of the client's clock the server's was
+    // when the last heartbeat arrived, one-way latency included.
+    ClockOffsetMS float64 `json:"clock_offset_ms" example:"35.2"`
+    // Samples counts the round trips the latency figures cover; they are
+    // zero when the client reported none.
+    Samples         int     `json:"samples" example:"20"`
+    LastLatencyMS   float64 `json:"last_latency_ms" example:"48"`
+    AvgLatencyMS    float64 `json:"avg_latency_ms" example:"52.5"`
+    MinLatencyMS    float64 `json:"min_latency_ms" example:"31"`
+    MaxLatencyMS    float64 `json:"max_latency_ms" example:"140"`
+}
+
+// heartbeatTracker keeps the rolling heartbeat stats of a connection. The
+// client's read pump records heartbeats while the hub reads the stats.
+type heartbeatTracker struct {
+    mu              sync.Mutex
+    heartbeats      int64
+    lastHeartbeatAt time.Time
+    clockOffset     time.Duration
+    // latencies is a ring of the latest reported round trips, in
+    // milliseconds; next is where the next one goes.
+    latencies []float64
+    next      int
+}
+
+// record counts a heartbeat received at, with its reported latency if valid.
+func (t *heartbeatTracker) record(heartbeat Heartbeat, at time.Time) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.heartbeats++
+    t.lastHeartbeatAt = at
+    if !heartbeat.ClientTime.IsZero() {
+        t.clockOffset = at.Sub(heartbeat.ClientTime)
+    }
+    latency := heartbeat.LatencyMS
+    if latency == nil || *latency < 0 || *latency > float64(maxHeartbeatLatency/time.Millisecond) {
+        return
+    }
+    if len(t.latencies) < heartbeatSamples {
+        t.latencies = append(t.latencies, *latency)
+    } else {
+        t.latencies[t.next] = *latency
+    }
+    t.next = (t.next + 1) % heartbeatSamples
+}
+
+// stats returns the connection's stats, or nil before its first heartbeat.
+func (t *heartbeatTracker) stats() *HeartbeatStats {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.heartbeats == 0 {
+        return nil
+    }
+    stats := &HeartbeatStats{
+        Heartbeats:      t.heartbeats,
+        LastHeartbeatAt: t.lastHeartbeatAt,
+        ClockOffsetMS:   float64(t.clockOffset) / float64(time.Millisecond),
+        Samples:         len(t.latencies),
+    }
+    if len(t.latencies) == 0 {
+        return stats
+    }
+    stats.LastLatencyMS = t.latencies[(t.next+heartbeatSamples-1)%heartbeatSamples]
+    stats.MinLatencyMS, stats.MaxLatencyMS = t.latencies[0], t.latencies[0]
+    var sum float64
+    for _, latency := range t.latencies {
+        sum += latency
+        stats.MinLatencyMS = min(stats.MinLatencyMS, latency)
+        stats.MaxLatencyMS = max(stats.MaxLatencyMS, latency)
+    }
+    stats.AvgLatencyMS = sum / float64(len(t.latencies))
+    return stats
+}
+
+// handleHeartbeat keeps the connection alive like a pong, records the
+// heartbeat and answers it.
+func (c *Client) handleHeartbeat(env Envelope) {
+    receivedAt := time.Now()
+    var heartbeat Heartbeat
+    if len(env.Payload) > 0 {
+        if err := json.Unmarshal(env.Payload, &heartbeat); err != nil {
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "heartbeat payload is not valid JSON"})
+            return
+        }
+    }
+    c.conn.SetReadDeadline(receivedAt.Add(pongWait))
+    c.heartbeats.record(heartbeat, receivedAt)
+
+    c.hub.broadcast <- &Message{
+        Type:        EventHeartbeat,
+        SenderID:    c.userID,
+        RecipientID: c.userID,
+        RoomID:      c.roomID,
+        CreatedAt:   receivedAt,
+        Heartbeat:   &Heartbeat{ClientTime: heartbeat.ClientTime, ServerTime: receivedAt},
+        FrameID:     env.ID,
+    }
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// hubSnapshotMaxAge is how old a snapshot can be and still be restored: the
+// clients it found are only expected back shortly after a restart.
+const hubSnapshotMaxAge = 10 * time.Minute
+
+// ErrStaleHubSnapshot is returned when loading a snapshot taken too long ago
+// for its clients to still be reconnecting.
+var ErrStaleHubSnapshot = errors.New("hub snapshot is too old to restore")
+
+// HubSnapshot records which users were connected to each room when the
+// server shut down. Restoring it at startup sizes the hub's room structures
+// for the clients about to reconnect, lists those users online in presence
+// snapshots until they do, and tells the warm cache which rooms and users to
+// load first.
+type HubSnapshot struct {
+    TakenAt time.Time `json:"taken_at"`
+    // Users holds the IDs of the users connected to each room.
+    Users map[string][]string `json:"users"`
+}
+
+// hubSnapshotRequest asks the hub for a snapshot of its state.
+type hubSnapshotRequest struct {
+    reply chan HubSnapshot
+}
+
+// Snapshot returns the users connected to each room of the hub right now.
+// The admin channel is left out.
+func (h *Hub) Snapshot() HubSnapshot {
+    req := hubSnapshotRequest{reply: make(chan HubSnapshot, 1)}
+    h.snapshots <- req
+    return <-req.reply
+}
+
+// snapshot builds the reply to a hubSnapshotRequest. It runs on the hub
+// goroutine.
+func (h *Hub) snapshot() HubSnapshot {
+    snapshot := HubSnapshot{TakenAt: time.Now(), Users: make(map[string][]string)}
+    for roomID, clients := range h.clients {
+        if roomID == AdminChannel || len(clients) == 0 {
+            continue
+        }
+        userIDs := make([]string, 0, len(clients))
+        for userID := range clients {
+            userIDs = append(userIDs, userID)
+        }
+        sort.Strings(userIDs)
+        snapshot.Users[roomID] = userIDs
+    }
+    return snapshot
+}
+
+// restore creates the structures of the snapshot's rooms, sized for the
+// clients it found, and has its users listed online until they reconnect or
+// the snapshot is hubSnapshotMaxAge old. It runs before the hub does.
+func (h *Hub) restore(snapshot *HubSnapshot) {
+    online := make(map[string]bool)
+    h.restored = make(map[string]map[string]bool, len(snapshot.Users))
+    for roomID, userIDs := range snapshot.Users {
+        h.clients[roomID] = make(map[string]clientSet, len(userIDs))
+        h.restored[roomID] = make(map[string]bool, len(userIDs))
+        for _, userID := range userIDs {
+            h.restored[roomID][userID] = true
+            online[userID] = true
+        }
+    }
+    h.presence = make(map[string]int, len(online))
+    h.restoredExpiry = time.After(time.Until(snapshot.TakenAt.Add(hubSnapshotMaxAge)))
+}
+
+// unrestore stops listing a user restored from the snapshot as online in a
+// room once they reconnect to it. It runs on the hub goroutine.
+func (h *Hub) unrestore(roomID, userID string) {
+    delete(h.restored[roomID], userID)
+    if len(h.restored[roomID]) == 0 {
+        delete(h.restored, roomID)
+    }
+}
+
+// expireRestored tells the rooms the users restored from the snapshot who
+// have not reconnected are offline after all. It runs on the hub goroutine.
+func (h *Hub) expireRestored() {
+    now := time.Now()
+    for roomID, userIDs := range h.restored {
+        for userID := range userIDs {
+            h.fanOutPresence(&Message{
+                Type:      EventPresence,
+                SenderID:  userID,
+                RoomID:    roomID,
+                CreatedAt: now,
+                Presence:  &Presence{UserID: userID, Status: PresenceOffline},
+            }, userID)
+        }
+    }
+    h.restored = nil
+    h.restoredExpiry = nil
+}
+
+// ActiveRooms returns the snapshot's rooms, those that had the most users
+// connected first.
+func (s *HubSnapshot) ActiveRooms() []uuid.UUID {
+    roomIDs := make([]uuid.UUID, 0, len(s.Users))
+    for roomID := range s.Users {
+        if id, err := uuid.Parse(roomID); err == nil {
+            roomIDs = append(roomIDs, id)
+        }
+    }
+    sort.Slice(roomIDs, func(i, j int) bool {
+        a, b := len(s.Users[roomIDs[i].String()]), len(s.Users[roomIDs[j].String()])
+        if a != b {
+            return a > b
+        }
+        return roomIDs[i].String() < roomIDs[j].String()
+    })
+    return roomIDs
+}
+
+// UserIDs returns the users connected to any of the snapshot's rooms.
+func (s *HubSnapshot) UserIDs() []uuid.UUID {
+    seen := make(map[uuid.UUID]bool)
+    var userIDs []uuid.UUID
+    for _, ids := range s.Users {
+        for _, userID := range ids {
+            if id, err := uuid.Parse(userID); err == nil && !seen[id] {
+                seen[id] = true
+                userIDs = append(userIDs, id)
+            }
+        }
+    }
+    return userIDs
+}
+
+// SaveHubSnapshot writes the snapshot to path, replacing any earlier one. It
+// is written to a temporary file first, so a crash midway leaves the earlier
+// snapshot whole.
+func SaveHubSnapshot(path string, snapshot HubSnapshot) error {
+    data, err := json.Marshal(snapshot)
+    if err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+// LoadHubSnapshot reads the snapshot saved at path. It returns
+// ErrStaleHubSnapshot for snapshots taken more than 10 minutes ago, and an
+// error satisfying errors.Is(err, fs.ErrNotExist) when there is none.
+func LoadHubSnapshot(path string) (*HubSnapshot, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var snapshot HubSnapshot
+    if err := json.Unmarshal(data, &snapshot); err != nil {
+        return nil, err
+    }
+    if time.Since(snapshot.TakenAt) > hubSnapshotMaxAge {
+        return nil, ErrStaleHubSnapshot
+    }
+    return &snapshot, nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventImpersonated is the type of the event sent to every connection of a
+// user when an administrator starts impersonating them.
+const EventImpersonated = "account.impersonated"
+
+// NotificationKindImpersonation marks activity feed items about
+// impersonation sessions.
+const NotificationKindImpersonation = "impersonation"
+
+// Audit log actions for support access and impersonation.
+const (
+    AuditActionSupportAccessGrant  = "support_access.grant"
+    AuditActionSupportAccessRevoke = "support_access.revoke"
+    AuditActionImpersonate         = "user.impersonate"
+)
+
+// DefaultSupportAccessTTL is how long users grant support access for unless
+// they choose otherwise; MaxSupportAccessTTL is the longest they can.
+const (
+    DefaultSupportAccessTTL = 24 * time.Hour
+    MaxSupportAccessTTL     = 72 * time.Hour
+)
+
+// DefaultImpersonationTTL is how long an impersonation token is valid unless
+// the administrator chooses otherwise; MaxImpersonationTTL is the longest.
+// Tokens never outlive the user's support access.
+const (
+    DefaultImpersonationTTL = 15 * time.Minute
+    MaxImpersonationTTL     = time.Hour
+)
+
+var (
+    // ErrImpersonationDisabled is returned when impersonation is turned off.
+    ErrImpersonationDisabled = errors.New("impersonation is disabled")
+    // ErrNoSupportAccess is returned when the user has not granted support
+    // access, or their grant has expired.
+    ErrNoSupportAccess = errors.New("user has not granted support access")
+    // ErrCannotImpersonate is returned when administrators try to
+    // impersonate themselves or another administrator.
+    ErrCannotImpersonate = errors.New("administrators cannot be impersonated")
+    // ErrImpersonating is returned for actions that are not allowed while
+    // impersonating a user, such as granting support access on their behalf.
+    ErrImpersonating = errors.New("not allowed while impersonating a user")
+)
+
+// impersonatorKey is the context key of the administrator behind a request.
+type impersonatorKey struct{}
+
+// WithImpersonator marks ctx as belonging to a request made by the
+// administrator adminID while impersonating its user.
+func WithImpersonator(ctx context.Context, adminID uuid.UUID) context.Context {
+    return context.WithValue(ctx, impersonatorKey{}, adminID)
+}
+
+// Impersonator returns the administrator impersonating the user behind ctx,
+// if any.
+func Impersonator(ctx context.Context) (uuid.UUID, bool) {
+    adminID, ok := ctx.Value(impersonatorKey{}).(uuid.UUID)
+    return adminID, ok
+}
+
+// SupportAccess is a user's consent to being impersonated by administrators
+// until ExpiresAt.
+type SupportAccess struct {
+    GrantedAt time.Time `json:"granted_at"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Impersonation describes an impersonation session.
+type Impersonation struct {
+    UserID    string    `json:"user_id"`
+    Username  string    `json:"username"`
+    AdminID   string    `json:"admin_id"`
+    AdminName string    `json:"admin_username"`
+    Reason    string    `json:"reason"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImpersonationService lets administrators act as users who granted support
+// access, for debugging. Every session is audited and announced to the user.
+type ImpersonationService struct {
+    db      *database.Queries
+    pool    *pgxpool.Pool
+    store   *MessageStore
+    hub     *Hub
+    enabled bool
+}
+
+// NewImpersonationService creates a new ImpersonationService. When enabled is
+// false no sessions can be started and existing tokens stop working.
+func NewImpersonationService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore, hub *Hub, enabled bool) *ImpersonationService {
+    return &ImpersonationService{db: db, pool: pool, store: store, hub: hub, enabled: enabled}
+}
+
+// Enabled reports whether administrators can impersonate users who granted
+// support access.
+func (s *ImpersonationService) Enabled() bool {
+    return s.enabled
+}
+
+// Grant lets administrators impersonate the user for ttl, replacing any
+// earlier grant.
+func (s *ImpersonationService) Grant(ctx context.Context, userID uuid.UUID, ttl time.Duration) (*SupportAccess, error) {
+    if _, ok := Impersonator(ctx); ok {
+        return nil, ErrImpersonating
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.GrantSupportAccess(ctx, database.GrantSupportAccessParams{UserID: userID, ExpiresAt: time.Now().Add(ttl)})
+    if err != nil {
+        return nil, err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &userID,
+        Action:  AuditActionSupportAccessGrant,
+        Details: map[string]any{"expires_at": row.ExpiresAt},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    return &SupportAccess{GrantedAt: row.GrantedAt, ExpiresAt: row.ExpiresAt}, nil
+}
+
+// Access returns the user's support access, or ErrNoSupportAccess.
+func (s *ImpersonationService) Access(ctx context.Context, userID uuid.UUID) (*SupportAccess, error) {
+    row, err := s.db.GetSupportAccess(ctx, userID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrNoSupportAccess
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &SupportAccess{GrantedAt: row.GrantedAt, ExpiresAt: row.ExpiresAt}, nil
+}
+
+// Revoke withdraws the user's support access. Impersonation tokens issued
+// under it stop working straight away.
+func (s *ImpersonationService) Revoke(ctx context.Context, userID uuid.UUID) error {
+    if _, ok := Impersonator(ctx); ok {
+        return ErrImpersonating
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    revoked, err := qtx.RevokeSupportAccess(ctx, userID)
+    if err != nil {
+        return err
+    }
+    if revoked == 0 {
+        return ErrNoSupportAccess
+    }
+    if err := RecordAudit(ctx, qtx, AuditEntry{ActorID: &userID, Action: AuditActionSupportAccessRevoke}); err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+// Start begins an impersonation of the user by the administrator for ttl,
+// cut short to when the user's support access ends. The session is recorded
+// in the audit log, and the user gets a direct message from the system bot,
+// an activity feed item and an account.impersonated event about it. Callers
+// are responsible for checking that admin is an administrator and for
+// issuing the token.
+func (s *ImpersonationService) Start(ctx context.Context, admin database.User, userID uuid.UUID, reason string, ttl time.Duration) (*Impersonation, error) {
+    if !s.enabled {
+        return nil, ErrImpersonationDisabled
+    }
+    if _, ok := Impersonator(ctx); ok {
+        return nil, ErrImpersonating
+    }
+
+    user, err := s.db.GetUserByID(ctx, userID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if user.IsAdmin || user.ID == admin.ID {
+        return nil, ErrCannotImpersonate
+    }
+    access, err := s.Access(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    expiresAt := time.Now().Add(ttl)
+    if access.ExpiresAt.Before(expiresAt) {
+        expiresAt = access.ExpiresAt
+    }
+    session := &Impersonation{
+        UserID:    user.ID.String(),
+        Username:  user.Username,
+        AdminID:   admin.ID.String(),
+        AdminName: admin.Username,
+        Reason:    reason,
+        ExpiresAt: expiresAt,
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &admin.ID,
+        Action:  AuditActionImpersonate,
+        Details: map[string]any{"user_id": session.UserID, "reason": reason, "expires_at": expiresAt},
+    })
+    if err != nil {
+        return nil, err
+    }
+    message, err := s.notify(ctx, qtx, session, user.ID, admin.ID)
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    s.hub.Broadcast(message)
+    s.hub.Broadcast(&Message{
+        Type:          EventImpersonated,
+        ID:            uuid.NewString(),
+        SenderID:      session.AdminID,
+        RecipientID:   session.UserID,
+        CreatedAt:     time.Now(),
+        Impersonation: session,
+    })
+    return session, nil
+}
+
+// Check reports whether the administrator may still act as the user: the
+// feature must be enabled, the administrator must still be one and the user's
+// support access must not have ended.
+func (s *ImpersonationService) Check(ctx context.Context, adminID, userID uuid.UUID) error {
+    if !s.enabled {
+        return ErrImpersonationDisabled
+    }
+    admin, err := s.db.GetUserByID(ctx, adminID)
+    if err != nil || !admin.IsAdmin {
+        return ErrImpersonationDisabled
+    }
+    _, err = s.Access(ctx, userID)
+    return err
+}
+
+// notify sends the user a direct message from the system bot in the welcome
+// room about the session, and adds it to their activity feed.
+func (s *ImpersonationService) notify(ctx context.Context, qtx *database.Queries, session *Impersonation, userID, adminID uuid.UUID) (*Message, error) {
+    isMember, err := qtx.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: WelcomeRoomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if !isMember {
+        if err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: WelcomeRoomID, UserID: userID}); err != nil {
+            return nil, err
+        }
+    }
+
+    metadata, err := json.Marshal(map[string]any{"impersonation": session})
+    if err != nil {
+        return nil, err
+    }
+    row, err := s.store.Create(ctx, qtx, database.CreateMessageParams{
+        ID:          uuid.New(),
+        RoomID:      WelcomeRoomID,
+        SenderID:    SystemUserID,
+        RecipientID: &userID,
+        Content: fmt.Sprintf("Administrator %s is signed in as you for support until %s UTC. Reason: %s. You can end this at any time by withdrawing support access.",
+            session.AdminName, session.ExpiresAt.UTC().Format("2006-01-02 15:04"), session.Reason),
+        Metadata: metadata,
+        Kind:     MessageKindText,
+        Mentions: []uuid.UUID{},
+        Priority: MessagePriorityNormal,
+    })
+    if err != nil {
+        return nil, err
+    }
+    err = qtx.CreateNotifications(ctx, database.CreateNotificationsParams{
+        UserIds:   []uuid.UUID{userID},
+        RoomID:    WelcomeRoomID,
+        MessageID: row.ID,
+        Kind:      NotificationKindImpersonation,
+        ActorID:   adminID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    return messageFromRow(row), nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // MaxIncomingWebhooks is how many incoming webhooks a room can have.
+    MaxIncomingWebhooks = 10
+    // MaxIntegrationNameLength is the longest an incoming webhook's name can
+    // be, in characters.
+    MaxIntegrationNameLength = 64
+)
+
+var (
+    // ErrInvalidIntegrationName is returned for empty or overlong incoming
+    // webhook names.
+    ErrInvalidIntegrationName = errors.New("webhook names must be 1-64 characters")
+    // ErrTooManyIncomingWebhooks is returned when a room with
+    // MaxIncomingWebhooks incoming webhooks gets another.
+    ErrTooManyIncomingWebhooks = errors.New("rooms can have at most 10 incoming webhooks")
+    // ErrIncomingWebhookNotFound is returned for unknown webhook tokens and
+    // IDs.
+    ErrIncomingWebhookNotFound = errors.New("incoming webhook not found")
+    // ErrInvalidWebhookMessage is returned for posts without content or with
+    // content over the room's message size limit.
+    ErrInvalidWebhookMessage = errors.New("content must not be empty or exceed the room's message size limit")
+)
+
+// IncomingWebhook lets an outside service, such as a CI server or an
+// alerting system, post to a room. Token and URL are only set when the
+// webhook is created.
+type IncomingWebhook struct {
+    ID     string `json:"id"`
+    RoomID string `json:"room_id"`
+    // Name is shown as the author of the webhook's messages.
+    Name  string `json:"name" example:"CI"`
+    Token string `json:"token,omitempty"`
+    // URL is where messages are posted, set along with Token.
+    URL string `json:"url,omitempty" example:"https://chat.example.com/v1/webhooks/3f9a..."`
+    // CreatedBy is absent once the creator's account is deleted.
+    CreatedBy  *string    `json:"created_by,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// IncomingWebhookService manages incoming webhooks and posts their messages.
+type IncomingWebhookService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+}
+
+// NewIncomingWebhookService creates a new IncomingWebhookService.
+func NewIncomingWebhookService(db *database.Queries, messages *MessageService, hub *Hub) *IncomingWebhookService {
+    return &IncomingWebhookService{db: db, messages: messages, hub: hub}
+}
+
+// Create adds an incoming webhook to a room and returns it with its token.
+func (s *IncomingWebhookService) Create(ctx context.Context, roomID, creatorID uuid.UUID, name string) (*IncomingWebhook, error) {
+    name = strings.TrimSpace(name)
+    if name == "" || utf8.RuneCountInString(name) > MaxIntegrationNameLength {
+        return nil, ErrInvalidIntegrationName
+    }
+    count, err := s.db.CountRoomIncomingWebhooks(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    if count >= MaxIncomingWebhooks {
+        return nil, ErrTooManyIncomingWebhooks
+    }
+
+    token, err := newWebhookToken()
+    if err != nil {
+        return nil, err
+    }
+    row, err := s.db.CreateIncomingWebhook(ctx, database.CreateIncomingWebhookParams{
+        ID:        uuid.New(),
+        RoomID:    roomID,
+        Name:      name,
+        TokenHash: hashWebhookToken(token),
+        CreatedBy: &creatorID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    webhook := incomingWebhookFromRow(row)
+    webhook.Token = token
+    return webhook, nil
+}
+
+// List returns the incoming webhooks of a room, oldest first, without their
+// tokens.
+func (s *IncomingWebhookService) List(ctx context.Context, roomID uuid.UUID) ([]*IncomingWebhook, error) {
+    rows, err := s.db.GetRoomIncomingWebhooks(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    webhooks := make([]*IncomingWebhook, 0, len(rows))
+    for _, row := range rows {
+        webhooks = append(webhooks, incomingWebhookFromRow(row))
+    }
+    return webhooks, nil
+}
+
+// Delete removes one of the room's incoming webhooks; its token stops
+// working right away.
+func (s *IncomingWebhookService) Delete(ctx context.Context, roomID, webhookID uuid.UUID) error {
+    deleted, err := s.db.DeleteIncomingWebhook(ctx, database.DeleteIncomingWebhookParams{ID: webhookID, RoomID: roomID})
+    if err != nil {
+        return err
+    }
+    if deleted == 0 {
+        return ErrIncomingWebhookNotFound
+    }
+    return nil
+}
+
+// Post sends content to the room of the webhook the token belongs to. The
+// message is sent by the system bot, with the webhook's ID and name in its
+// integration metadata so clients can show it as the author.
+func (s *IncomingWebhookService) Post(ctx context.Context, token, content string) (*Message, error) {
+    webhook, err := s.db.GetIncomingWebhookByTokenHash(ctx, hashWebhookToken(token))
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrIncomingWebhookNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    room, err := s.db.GetRoomByID(ctx, webhook.RoomID)
+    if err != nil {
+        return nil, err
+    }
+    if room.ArchivedAt != nil {
+        return nil, ErrRoomArchived
+    }
+    content = strings.TrimSpace(content)
+    if content == "" || len(content) > s.messages.MaxMessageSize(room) {
+        return nil, ErrInvalidWebhookMessage
+    }
+
+    message := &Message{
+        SenderID: SystemUserID.String(),
+        RoomID:   room.ID.String(),
+        Content:  content,
+        Kind:     MessageKindText,
+        Metadata: map[string]any{"integration": map[string]any{"webhook_id": webhook.ID.String(), "name": webhook.Name}},
+    }
+    if err := s.messages.SaveMessage(ctx, message); err != nil {
+        return nil, err
+    }
+    s.hub.Broadcast(message)
+
+    if err := s.db.TouchIncomingWebhook(ctx, webhook.ID); err != nil {
+        log.Printf("failed to record use of incoming webhook %s: %v", webhook.ID, err)
+    }
+    return message, nil
+}
+
+// newWebhookToken returns a random token for an incoming webhook.
+func newWebhookToken() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("generate webhook token: %w", err)
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// hashWebhookToken returns the hash incoming webhooks are looked up by, so a
+// leaked database does not leak working tokens.
+func hashWebhookToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+func incomingWebhookFromRow(row database.RoomIncomingWebhook) *IncomingWebhook {
+    webhook := &IncomingWebhook{
+        ID:         row.ID.String(),
+        RoomID:     row.RoomID.String(),
+        Name:       row.Name,
+        CreatedAt:  row.CreatedAt,
+        LastUsedAt: row.LastUsedAt,
+    }
+    if row.CreatedBy != nil {
+        createdBy := row.CreatedBy.String()
+        webhook.CreatedBy = &createdBy
+    }
+    return webhook
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventInvite is the type of the event sent to every connection of a user who
+// is invited to a room.
+const EventInvite = "room.invited"
+
+// DefaultInviteTTL is how long invitations stay valid unless the inviter
+// chooses otherwise; MaxInviteTTL is the longest they can be made valid for.
+const (
+    DefaultInviteTTL = 7 * 24 * time.Hour
+    MaxInviteTTL     = 30 * 24 * time.Hour
+)
+
+var (
+    // ErrInviteNotFound is returned for invitations that do not exist or are
+    // addressed to someone else.
+    ErrInviteNotFound = errors.New("invitation not found")
+    // ErrInviteExpired is returned when accepting an expired invitation.
+    ErrInviteExpired = errors.New("invitation has expired")
+    // ErrInvalidInvitee is returned when users invite themselves.
+    ErrInvalidInvitee = errors.New("you cannot invite yourself")
+    // ErrInviteeNotFound is returned when the invited user does not exist.
+    ErrInviteeNotFound = errors.New("invited user not found")
+    // ErrAlreadyMember is returned when inviting a member of the room.
+    ErrAlreadyMember = errors.New("user is already a member of this room")
+    // ErrRoomArchived is returned when joining an archived room.
+    ErrRoomArchived = errors.New("room is archived")
+    // ErrBanned is returned when a user banned from a room tries to join it.
+    ErrBanned = errors.New("you are banned from this room")
+)
+
+// Invite is a pending invitation for a user to join a room.
+type Invite struct {
+    ID        string    `json:"id"`
+    RoomID    string    `json:"room_id"`
+    RoomName  string    `json:"room_name"`
+    UserID    string    `json:"user_id"`
+    InvitedBy string    `json:"invited_by,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
+
+// InviteService manages room invitations.
+type InviteService struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+    hub  *Hub
+}
+
+// NewInviteService creates a new InviteService.
+func NewInviteService(db *database.Queries, pool *pgxpool.Pool, hub *Hub) *InviteService {
+    return &InviteService{db: db, pool: pool, hub: hub}
+}
+
+// Invite invites a user to a room for ttl and notifies the user's open
+// connections. Inviting a user again renews their invitation. Callers are
+// responsible for checking that the inviter may invite to the room.
+func (s *InviteService) Invite(ctx context.Context, room database.Room, inviterID, userID uuid.UUID, ttl time.Duration) (*Invite, error) {
+    if userID == inviterID {
+        return nil, ErrInvalidInvitee
+    }
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: room.ID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if isMember {
+        return nil, ErrAlreadyMember
+    }
+
+    row, err := s.db.CreateRoomInvite(ctx, database.CreateRoomInviteParams{
+        ID:        uuid.New(),
+        RoomID:    room.ID,
+        UserID:    userID,
+        InvitedBy: &inviterID,
+        ExpiresAt: time.Now().Add(ttl),
+    })
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+        return nil, ErrInviteeNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    invite := inviteFromRow(row, room.Name)
+    s.hub.Broadcast(&Message{
+        Type:        EventInvite,
+        ID:          invite.ID,
+        SenderID:    inviterID.String(),
+        RecipientID: invite.UserID,
+        RoomID:      invite.RoomID,
+        CreatedAt:   row.CreatedAt,
+        Invite:      invite,
+    })
+    return invite, nil
+}
+
+// Pending returns the user's invitations that have not expired, newest first.
+func (s *InviteService) Pending(ctx context.Context, userID uuid.UUID) ([]Invite, error) {
+    rows, err := s.db.GetUserInvites(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    invites := make([]Invite, 0, len(rows))
+    for _, row := range rows {
+        invites = append(invites, *inviteFromRow(database.RoomInvite{
+            ID:        row.ID,
+            RoomID:    row.RoomID,
+            UserID:    row.UserID,
+            InvitedBy: row.InvitedBy,
+            CreatedAt: row.CreatedAt,
+            ExpiresAt: row.ExpiresAt,
+        }, row.RoomName))
+    }
+    return invites, nil
+}
+
+// Accept makes the user a member of the room they were invited to and
+// deletes the invitation. Expired invitations are deleted too, and
+// ErrInviteExpired is returned.
+func (s *InviteService) Accept(ctx context.Context, inviteID, userID uuid.UUID) (*Invite, error) {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.GetRoomInviteByID(ctx, inviteID)
+    if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.UserID != userID) {
+        return nil, ErrInviteNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    deleted, err := qtx.DeleteRoomInvite(ctx, inviteID)
+    if err != nil {
+        return nil, err
+    }
+    if deleted == 0 {
+        // Accepted or declined concurrently.
+        return nil, ErrInviteNotFound
+    }
+    if time.Now().After(row.ExpiresAt) {
+        if err := tx.Commit(ctx); err != nil {
+            return nil, err
+        }
+        return nil, ErrInviteExpired
+    }
+
+    room, err := qtx.GetRoomByID(ctx, row.RoomID)
+    if err != nil {
+        return nil, err
+    }
+    if room.ArchivedAt != nil {
+        return nil, ErrRoomArchived
+    }
+    banned, err := qtx.IsRoomBanned(ctx, database.IsRoomBannedParams{RoomID: row.RoomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if banned {
+        // The invitation stays deleted.
+        if err := tx.Commit(ctx); err != nil {
+            return nil, err
+        }
+        return nil, ErrBanned
+    }
+    isMember, err := qtx.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: row.RoomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if !isMember {
+        if err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: row.RoomID, UserID: userID}); err != nil {
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    return inviteFromRow(row, room.Name), nil
+}
+
+// AcceptForRoom accepts the user's invitation to a room, if they have one that
+// has not expired, and reports whether they joined the room.
+func (s *InviteService) AcceptForRoom(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+    row, err := s.db.GetRoomInviteForUser(ctx, database.GetRoomInviteForUserParams{RoomID: roomID, UserID: userID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+
+    _, err = s.Accept(ctx, row.ID, userID)
+    if errors.Is(err, ErrInviteExpired) || errors.Is(err, ErrInviteNotFound) {
+        return false, nil
+    }
+    return err == nil, err
+}
+
+// Decline deletes an invitation addressed to the user.
+func (s *InviteService) Decline(ctx context.Context, inviteID, userID uuid.UUID) error {
+    row, err := s.db.GetRoomInviteByID(ctx, inviteID)
+    if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.UserID != userID) {
+        return ErrInviteNotFound
+    }
+    if err != nil {
+        return err
+    }
+    _, err = s.db.DeleteRoomInvite(ctx, inviteID)
+    return err
+}
+
+// inviteFromRow converts an invitation row for a room with the given name.
+func inviteFromRow(row database.RoomInvite, roomName string) *Invite {
+    invite := &Invite{
+        ID:        row.ID.String(),
+        RoomID:    row.RoomID.String(),
+        RoomName:  roomName,
+        UserID:    row.UserID.String(),
+        CreatedAt: row.CreatedAt,
+        ExpiresAt: row.ExpiresAt,
+    }
+    if row.InvitedBy != nil {
+        invite.InvitedBy = row.InvitedBy.String()
+    }
+    return invite
+}
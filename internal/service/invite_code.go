@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// DefaultInviteCodeTTL is how long invite codes stay valid unless their
+// creator chooses otherwise; MaxInviteCodeTTL is the longest they can be.
+const (
+    DefaultInviteCodeTTL = 24 * time.Hour
+    MaxInviteCodeTTL     = 7 * 24 * time.Hour
+)
+
+// MaxInviteCodeUses is the highest use limit an invite code can have.
+const MaxInviteCodeUses = 1000
+
+// inviteCodeAlphabet leaves out characters that are easily confused, such as
+// 0 and o or 1 and l, so codes can be read out and typed.
+const inviteCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// inviteCodeLength gives codes about 50 bits of randomness.
+const inviteCodeLength = 10
+
+var (
+    // ErrInviteCodeNotFound is returned for invite codes that do not exist,
+    // have expired or have been used up.
+    ErrInviteCodeNotFound = errors.New("invite code not found")
+    // ErrInvalidInviteCode is returned for use limits out of range.
+    ErrInvalidInviteCode = errors.New("max_uses must be between 1 and 1000")
+)
+
+// InviteCode is a shareable code that lets anyone holding it join a room.
+type InviteCode struct {
+    Code      string `json:"code" example:"k7pq3xw9mh"`
+    RoomID    string `json:"room_id"`
+    CreatedBy string `json:"created_by,omitempty"`
+    // Link is the code's join link, when INVITE_LINK_URL is configured.
+    Link      string    `json:"link,omitempty" example:"https://chat.example.com/join/k7pq3xw9mh"`
+    CreatedAt time.Time `json:"created_at"`
+    ExpiresAt time.Time `json:"expires_at"`
+    // MaxUses is how many users can join with the code; unlimited when
+    // omitted.
+    MaxUses *int32 `json:"max_uses,omitempty" example:"10"`
+    Uses    int32  `json:"uses" example:"3"`
+}
+
+// CreateCode creates an invite code for the room, valid for ttl and maxUses
+// joins, or any number of them when maxUses is nil. Callers are responsible
+// for checking that the creator may invite to the room.
+func (s *InviteService) CreateCode(ctx context.Context, room database.Room, creatorID uuid.UUID, ttl time.Duration, maxUses *int32) (*InviteCode, error) {
+    if maxUses != nil && (*maxUses < 1 || *maxUses > MaxInviteCodeUses) {
+        return nil, ErrInvalidInviteCode
+    }
+    for {
+        code, err := newInviteCode()
+        if err != nil {
+            return nil, err
+        }
+        row, err := s.db.CreateRoomInviteCode(ctx, database.CreateRoomInviteCodeParams{
+            Code:      code,
+            RoomID:    room.ID,
+            CreatedBy: &creatorID,
+            ExpiresAt: time.Now().Add(ttl),
+            MaxUses:   maxUses,
+        })
+        var pgErr *pgconn.PgError
+        if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        return inviteCodeFromRow(row), nil
+    }
+}
+
+// Codes lists the room's invite codes that can still be used, newest first:
+// all of them, or only those created by createdBy when it is not nil.
+func (s *InviteService) Codes(ctx context.Context, roomID uuid.UUID, createdBy *uuid.UUID) ([]InviteCode, error) {
+    rows, err := s.db.GetRoomInviteCodes(ctx, database.GetRoomInviteCodesParams{RoomID: roomID, CreatedBy: createdBy})
+    if err != nil {
+        return nil, err
+    }
+    codes := make([]InviteCode, 0, len(rows))
+    for _, row := range rows {
+        codes = append(codes, *inviteCodeFromRow(row))
+    }
+    return codes, nil
+}
+
+// Code returns the room's invite code, whether or not it can still be used.
+func (s *InviteService) Code(ctx context.Context, roomID uuid.UUID, code string) (*InviteCode, error) {
+    row, err := s.db.GetRoomInviteCode(ctx, NormalizeInviteCode(code))
+    if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.RoomID != roomID) {
+        return nil, ErrInviteCodeNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return inviteCodeFromRow(row), nil
+}
+
+// RevokeCode deletes the room's invite code.
+func (s *InviteService) RevokeCode(ctx context.Context, roomID uuid.UUID, code string) error {
+    deleted, err := s.db.DeleteRoomInviteCode(ctx, database.DeleteRoomInviteCodeParams{RoomID: roomID, Code: NormalizeInviteCode(code)})
+    if err != nil {
+        return err
+    }
+    if deleted == 0 {
+        return ErrInviteCodeNotFound
+    }
+    return nil
+}
+
+// JoinByCode makes the user a member of the room the invite code is for,
+// private rooms included, and counts a use of the code. Members of the room
+// join nothing and use nothing; joined reports whether the user joined.
+func (s *InviteService) JoinByCode(ctx context.Context, code string, userID uuid.UUID) (room database.Room, joined bool, err error) {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return room, false, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.GetRoomInviteCode(ctx, NormalizeInviteCode(code))
+    if errors.Is(err, pgx.ErrNoRows) {
+        return room, false, ErrInviteCodeNotFound
+    }
+    if err != nil {
+        return room, false, err
+    }
+    room, err = qtx.GetRoomByID(ctx, row.RoomID)
+    if err != nil {
+        return room, false, err
+    }
+    if room.ArchivedAt != nil {
+        return room, false, ErrRoomArchived
+    }
+    banned, err := qtx.IsRoomBanned(ctx, database.IsRoomBannedParams{RoomID: room.ID, UserID: userID})
+    if err != nil {
+        return room, false, err
+    }
+    if banned {
+        return room, false, ErrBanned
+    }
+    isMember, err := qtx.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: room.ID, UserID: userID})
+    if err != nil {
+        return room, false, err
+    }
+    if isMember {
+        return room, false, nil
+    }
+
+    // Expired and used up codes are turned away here, so that concurrent
+    // joins cannot use a code more than max_uses times.
+    if _, err := qtx.UseRoomInviteCode(ctx, row.Code); errors.Is(err, pgx.ErrNoRows) {
+        return room, false, ErrInviteCodeNotFound
+    } else if err != nil {
+        return room, false, err
+    }
+    if err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+        return room, false, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return room, false, err
+    }
+    return room, true, nil
+}
+
+// NormalizeInviteCode lowercases a code and trims surrounding space, so codes
+// can be typed in any case.
+func NormalizeInviteCode(code string) string {
+    return strings.ToLower(strings.TrimSpace(code))
+}
+
+// newInviteCode returns a random invite code.
+func newInviteCode() (string, error) {
+    max := big.NewInt(int64(len(inviteCodeAlphabet)))
+    code := make([]byte, inviteCodeLength)
+    for i := range code {
+        n, err := rand.Int(rand.Reader, max)
+        if err != nil {
+            return "", err
+        }
+        code[i] = inviteCodeAlphabet[n.Int64()]
+    }
+    return string(code), nil
+}
+
+func inviteCodeFromRow(row database.RoomInviteCode) *InviteCode {
+    code := &InviteCode{
+        Code:      row.Code,
+        RoomID:    row.RoomID.String(),
+        CreatedAt: row.CreatedAt,
+        ExpiresAt: row.ExpiresAt,
+        MaxUses:   row.MaxUses,
+        Uses:      row.Uses,
+    }
+    if row.CreatedBy != nil {
+        code.CreatedBy = row.CreatedBy.String()
+    }
+    return code
+}
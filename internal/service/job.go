@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Job statuses. Jobs wait as pending until due, are running while a worker
+// has them, and end up succeeded, failed once out of attempts, or cancelled
+// by an administrator.
+const (
+    JobStatusPending   = "pending"
+    JobStatusRunning   = "running"
+    JobStatusSucceeded = "succeeded"
+    JobStatusFailed    = "failed"
+    JobStatusCancelled = "cancelled"
+)
+
+const (
+    // DefaultJobAttempts is how many times a job is tried before it fails.
+    DefaultJobAttempts = 5
+    // jobPollInterval is how often workers look for due jobs.
+    jobPollInterval = time.Second
+    // jobBatchSize is how many jobs a worker claims at once.
+    jobBatchSize = 20
+    // jobLease is how long a job may run before it counts as abandoned by a
+    // worker that died, and is claimed again.
+    jobLease = 5 * time.Minute
+    // jobRetryBase and jobRetryMax bound the exponential backoff between
+    // attempts.
+    jobRetryBase = 10 * time.Second
+    jobRetryMax  = time.Hour
+    // jobRetention is how long succeeded and cancelled jobs are kept; failed
+    // ones are kept until retried.
+    jobRetention = 7 * 24 * time.Hour
+    // jobPruneInterval is how often old jobs are deleted.
+    jobPruneInterval = time.Hour
+)
+
+var (
+    // ErrJobNotFound is returned for unknown job IDs.
+    ErrJobNotFound = errors.New("job not found")
+    // ErrJobNotRetryable is returned when retrying a job that has not failed
+    // or been cancelled.
+    ErrJobNotRetryable = errors.New("only failed or cancelled jobs can be retried")
+    // ErrJobNotCancellable is returned when cancelling a job that is not
+    // pending.
+    ErrJobNotCancellable = errors.New("only pending jobs can be cancelled")
+    // ErrInvalidJobStatus is returned when filtering by an unknown status.
+    ErrInvalidJobStatus = errors.New("status must be pending, running, succeeded, failed or cancelled")
+)
+
+// Job is a unit of background work, such as a webhook delivery.
+type Job struct {
+    ID      string          `json:"id"`
+    Type    string          `json:"type" example:"webhook.delivery"`
+    Payload json.RawMessage `json:"payload" swaggertype:"object"`
+    Status  string          `json:"status" example:"pending"`
+    // Attempts counts the tries so far, out of MaxAttempts.
+    Attempts    int32 `json:"attempts" example:"2"`
+    MaxAttempts int32 `json:"max_attempts" example:"5"`
+    // LastError is why the latest try failed.
+    LastError *string `json:"last_error,omitempty" example:"https://example.com/hooks/chat returned status 503"`
+    // RunAt is when the job is next due, for pending jobs.
+    RunAt     time.Time `json:"run_at"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobQueueDepth counts the jobs of a type in a status.
+type JobQueueDepth struct {
+    Type   string `json:"type" example:"webhook.delivery"`
+    Status string `json:"status" example:"pending"`
+    Count  int64  `json:"count" example:"12"`
+    // OldestRunAt is when the longest waiting of these jobs was due.
+    OldestRunAt time.Time `json:"oldest_run_at"`
+}
+
+// JobFunc runs a job of one type with its payload. An error fails the
+// attempt; the job is tried again later while it has attempts left.
+type JobFunc func(ctx context.Context, payload []byte) error
+
+// JobQueue stores background jobs in the database and runs them, retrying
+// failures with exponential backoff. Jobs survive restarts, and several
+// servers can run the same queue.
+type JobQueue struct {
+    db *database.Queries
+
+    mu    sync.RWMutex
+    funcs map[string]JobFunc
+}
+
+// NewJobQueue creates a new JobQueue. Jobs run once Run is started.
+func NewJobQueue(db *database.Queries) *JobQueue {
+    return &JobQueue{db: db, funcs: make(map[string]JobFunc)}
+}
+
+// Handle sets the function jobs of jobType are run with.
+func (q *JobQueue) Handle(jobType string, fn JobFunc) {
+    q.mu.Lock()
+    q.funcs[jobType] = fn
+    q.mu.Unlock()
+}
+
+// Enqueue queues a job of jobType with payload encoded as JSON, due right
+// away.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, payload any) error {
+    return q.EnqueueTx(ctx, q.db, jobType, payload)
+}
+
+// EnqueueTx queues a job as Enqueue does, on the queries of the caller's
+// transaction, so that the job only exists once the transaction commits.
+func (q *JobQueue) EnqueueTx(ctx context.Context, tx *database.Queries, jobType string, payload any) error {
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return fmt.Errorf("encode %s job: %w", jobType, err)
+    }
+    return tx.EnqueueJob(ctx, database.EnqueueJobParams{
+        ID:          uuid.New(),
+        Type:        jobType,
+        Payload:     data,
+        MaxAttempts: DefaultJobAttempts,
+    })
+}
+
+// List returns the most recently created jobs, newest first, optionally only
+// those with the given status or type.
+func (q *JobQueue) List(ctx context.Context, status, jobType string, limit int32) ([]*Job, error) {
+    params := database.ListJobsParams{MaxResults: limit}
+    if status != "" {
+        if !validJobStatus(status) {
+            return nil, ErrInvalidJobStatus
+        }
+        params.Status = &status
+    }
+    if jobType != "" {
+        params.Type = &jobType
+    }
+    rows, err := q.db.ListJobs(ctx, params)
+    if err != nil {
+        return nil, err
+    }
+    jobs := make([]*Job, 0, len(rows))
+    for _, row := range rows {
+        jobs = append(jobs, jobFromRow(row))
+    }
+    return jobs, nil
+}
+
+// Retry queues a failed or cancelled job to run again right away, with all
+// its attempts.
+func (q *JobQueue) Retry(ctx context.Context, jobID uuid.UUID) (*Job, error) {
+    row, err := q.db.RetryJob(ctx, jobID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, q.whyNot(ctx, jobID, ErrJobNotRetryable)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return jobFromRow(row), nil
+}
+
+// Cancel stops a pending job from running. Running jobs cannot be cancelled.
+func (q *JobQueue) Cancel(ctx context.Context, jobID uuid.UUID) (*Job, error) {
+    row, err := q.db.CancelJob(ctx, jobID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, q.whyNot(ctx, jobID, ErrJobNotCancellable)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return jobFromRow(row), nil
+}
+
+// whyNot tells apart a job that does not exist from one in the wrong status
+// for a change that matched no job.
+func (q *JobQueue) whyNot(ctx context.Context, jobID uuid.UUID, wrongStatus error) error {
+    _, err := q.db.GetJob(ctx, jobID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return ErrJobNotFound
+    }
+    if err != nil {
+        return err
+    }
+    return wrongStatus
+}
+
+// Depth counts the jobs by type and status.
+func (q *JobQueue) Depth(ctx context.Context) ([]JobQueueDepth, error) {
+    rows, err := q.db.GetJobQueueDepth(ctx)
+    if err != nil {
+        return nil, err
+    }
+    depth := make([]JobQueueDepth, 0, len(rows))
+    for _, row := range rows {
+        depth = append(depth, JobQueueDepth{Type: row.Type, Status: row.Status, Count: row.Count, OldestRunAt: row.OldestRunAt})
+    }
+    return depth, nil
+}
+
+// Run claims and runs due jobs until ctx is cancelled, and deletes finished
+// jobs once they are old.
+func (q *JobQueue) Run(ctx context.Context) {
+    ticker := time.NewTicker(jobPollInterval)
+    defer ticker.Stop()
+    lastPrune := time.Now()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            q.runDue(ctx)
+            if now.Sub(lastPrune) >= jobPruneInterval {
+                lastPrune = now
+                if n, err := q.db.DeleteFinishedJobs(ctx, now.Add(-jobRetention)); err != nil {
+                    log.Printf("failed to delete finished jobs: %v", err)
+                } else if n > 0 {
+                    log.Printf("Deleted %d finished jobs", n)
+                }
+            }
+        }
+    }
+}
+
+// runDue claims a batch of due jobs and runs them side by side, until no due
+// jobs are left.
+func (q *JobQueue) runDue(ctx context.Context) {
+    for ctx.Err() == nil {
+        jobs, err := q.db.ClaimJobs(ctx, database.ClaimJobsParams{LeaseExpiredBefore: time.Now().Add(-jobLease), MaxJobs: jobBatchSize})
+        if err != nil {
+            log.Printf("failed to claim jobs: %v", err)
+            return
+        }
+        var wg sync.WaitGroup
+        for _, job := range jobs {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                q.run(ctx, job)
+            }()
+        }
+        wg.Wait()
+        if len(jobs) < jobBatchSize {
+            return
+        }
+    }
+}
+
+// run runs a claimed job and records the outcome: done, due again after a
+// backoff, or failed for good once out of attempts.
+func (q *JobQueue) run(ctx context.Context, job database.Job) {
+    q.mu.RLock()
+    fn, ok := q.funcs[job.Type]
+    q.mu.RUnlock()
+
+    var err error
+    switch {
+    case !ok:
+        err = fmt.Errorf("no handler for job type %q", job.Type)
+    case job.Attempts > job.MaxAttempts:
+        err = errors.New("abandoned by its worker too many times")
+    default:
+        runCtx, cancel := context.WithTimeout(ctx, jobLease)
+        err = fn(runCtx, job.Payload)
+        cancel()
+    }
+
+    if err == nil {
+        if err := q.db.CompleteJob(ctx, job.ID); err != nil {
+            log.Printf("failed to complete job %s: %v", job.ID, err)
+        }
+        return
+    }
+    message := err.Error()
+    if !ok || job.Attempts >= job.MaxAttempts {
+        log.Printf("%s job %s failed: %v", job.Type, job.ID, err)
+        if err := q.db.FailJob(ctx, database.FailJobParams{ID: job.ID, LastError: &message}); err != nil {
+            log.Printf("failed to record failure of job %s: %v", job.ID, err)
+        }
+        return
+    }
+    runAt := time.Now().Add(jobBackoff(job.Attempts))
+    if err := q.db.RescheduleJob(ctx, database.RescheduleJobParams{ID: job.ID, LastError: &message, RunAt: runAt}); err != nil {
+        log.Printf("failed to reschedule job %s: %v", job.ID, err)
+    }
+}
+
+// jobBackoff returns how long to wait after a job's attempts-th failed try:
+// jobRetryBase, doubling with each attempt, up to jobRetryMax.
+func jobBackoff(attempts int32) time.Duration {
+    backoff := jobRetryBase
+    for i := int32(1); i < attempts && backoff < jobRetryMax; i++ {
+        backoff *= 2
+    }
+    return min(backoff, jobRetryMax)
+}
+
+func validJobStatus(status string) bool {
+    switch status {
+    case JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled:
+        return true
+    }
+    return false
+}
+
+func jobFromRow(row database.Job) *Job {
+    return &Job{
+        ID:          row.ID.String(),
+        Type:        row.Type,
+        Payload:     json.RawMessage(row.Payload),
+        Status:      row.Status,
+        Attempts:    row.Attempts,
+        MaxAttempts: row.MaxAttempts,
+        LastError:   row.LastError,
+        RunAt:       row.RunAt,
+        CreatedAt:   row.CreatedAt,
+        UpdatedAt:   row.UpdatedAt,
+    }
+}
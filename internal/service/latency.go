@@ -0,0 +1,237 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// BroadcastSLO is the service level objective for delivering new messages:
+// Objective of the writes of a message to its room's clients should happen
+// within Threshold of the server receiving the message.
+type BroadcastSLO struct {
+    Threshold time.Duration
+    // Objective is the fraction of writes that should be on time, such as
+    // 0.99.
+    Objective float64
+}
+
+// DefaultBroadcastSLO returns the objective used when none is configured.
+func DefaultBroadcastSLO() BroadcastSLO {
+    return BroadcastSLO{Threshold: 250 * time.Millisecond, Objective: 0.99}
+}
+
+// LatencyBounds are the upper bounds of the broadcast latency histogram
+// buckets; a last bucket holds everything slower.
+var LatencyBounds = []time.Duration{
+    time.Millisecond,
+    2500 * time.Microsecond,
+    5 * time.Millisecond,
+    10 * time.Millisecond,
+    25 * time.Millisecond,
+    50 * time.Millisecond,
+    100 * time.Millisecond,
+    250 * time.Millisecond,
+    500 * time.Millisecond,
+    time.Second,
+    2500 * time.Millisecond,
+    5 * time.Second,
+    10 * time.Second,
+}
+
+// RoomSizes name the buckets rooms are grouped in by how many clients are
+// connected when a message is fanned out.
+var RoomSizes = []string{"1-10", "11-100", "101-1000", "1000+"}
+
+// Windows over which recent latency is summarized.
+const (
+    LatencyWindowShort = 5 * time.Minute
+    LatencyWindowLong  = time.Hour
+)
+
+// latencySlots is how many one-minute slots of recent writes are kept, enough
+// for the long window.
+const latencySlots = 60
+
+// latencyQuantiles are the quantiles reported for each room size.
+var latencyQuantiles = []float64{0.5, 0.95, 0.99}
+
+// LatencySnapshot is the broadcast latency of one room size bucket.
+type LatencySnapshot struct {
+    RoomSize string
+    // Counts holds the number of writes per LatencyBounds bucket, plus one
+    // for slower writes, since the server started; Sum is their total
+    // latency.
+    Counts []uint64
+    Sum    time.Duration
+    // Quantiles maps 0.5, 0.95 and 0.99 to the latency under which that
+    // fraction of the writes in the short window fell. Empty when there
+    // were none.
+    Quantiles map[float64]time.Duration
+    // BurnRate maps each window to how fast writes in it used up the error
+    // budget: 1 uses it exactly over the SLO period, higher sooner. Windows
+    // without writes are left out.
+    BurnRate map[time.Duration]float64
+}
+
+// latencyTracker records how long new messages take from being received to
+// being written to each client, per room size.
+type latencyTracker struct {
+    slo   BroadcastSLO
+    sizes []*latencyHistogram
+}
+
+// latencyHistogram holds the writes to rooms of one size.
+type latencyHistogram struct {
+    mu     sync.Mutex
+    counts []uint64
+    sum    time.Duration
+    slots  [latencySlots]latencySlot
+}
+
+// latencySlot counts the writes within one minute.
+type latencySlot struct {
+    minute int64
+    counts []uint64
+    late   uint64
+}
+
+func newLatencyTracker(slo BroadcastSLO) *latencyTracker {
+    t := &latencyTracker{slo: slo}
+    for range RoomSizes {
+        t.sizes = append(t.sizes, &latencyHistogram{counts: make([]uint64, len(LatencyBounds)+1)})
+    }
+    return t
+}
+
+// observe records a write of a message received at receivedAt to one of the
+// clients of a room that had audience clients connected.
+func (t *latencyTracker) observe(receivedAt time.Time, audience int) {
+    now := time.Now()
+    latency := now.Sub(receivedAt)
+    bucket := len(LatencyBounds)
+    for i, bound := range LatencyBounds {
+        if latency <= bound {
+            bucket = i
+            break
+        }
+    }
+
+    h := t.sizes[roomSizeIndex(audience)]
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.counts[bucket]++
+    h.sum += latency
+    slot := h.slot(now)
+    slot.counts[bucket]++
+    if latency > t.slo.Threshold {
+        slot.late++
+    }
+}
+
+// slot returns the slot of the minute of now, clearing it if it last held an
+// older minute.
+func (h *latencyHistogram) slot(now time.Time) *latencySlot {
+    minute := now.Unix() / 60
+    slot := &h.slots[minute%latencySlots]
+    if slot.minute != minute || slot.counts == nil {
+        slot.minute = minute
+        slot.counts = make([]uint64, len(LatencyBounds)+1)
+        slot.late = 0
+    }
+    return slot
+}
+
+// snapshot summarizes the writes recorded so far.
+func (t *latencyTracker) snapshot() []LatencySnapshot {
+    now := time.Now().Unix() / 60
+    snapshots := make([]LatencySnapshot, len(RoomSizes))
+    for i, h := range t.sizes {
+        h.mu.Lock()
+        s := LatencySnapshot{
+            RoomSize:  RoomSizes[i],
+            Counts:    append([]uint64(nil), h.counts...),
+            Sum:       h.sum,
+            Quantiles: make(map[float64]time.Duration),
+            BurnRate:  make(map[time.Duration]float64),
+        }
+        for _, window := range []time.Duration{LatencyWindowShort, LatencyWindowLong} {
+            counts, late := h.window(now, int64(window/time.Minute))
+            total := sumCounts(counts)
+            if total == 0 {
+                continue
+            }
+            if window == LatencyWindowShort {
+                for _, q := range latencyQuantiles {
+                    s.Quantiles[q] = quantile(counts, total, q)
+                }
+            }
+            s.BurnRate[window] = float64(late) / float64(total) / (1 - t.slo.Objective)
+        }
+        h.mu.Unlock()
+        snapshots[i] = s
+    }
+    return snapshots
+}
+
+// window adds up the slots of the last minutes minutes, the current one
+// included.
+func (h *latencyHistogram) window(now, minutes int64) ([]uint64, uint64) {
+    counts := make([]uint64, len(LatencyBounds)+1)
+    var late uint64
+    for _, slot := range h.slots {
+        if slot.counts == nil || slot.minute <= now-minutes {
+            continue
+        }
+        for i, n := range slot.counts {
+            counts[i] += n
+        }
+        late += slot.late
+    }
+    return counts, late
+}
+
+// quantile estimates the q quantile of the histogram counts, interpolating
+// linearly within the bucket it falls in. Writes slower than the last bound
+// are reported at it.
+func quantile(counts []uint64, total uint64, q float64) time.Duration {
+    rank := q * float64(total)
+    var seen float64
+    for i, n := range counts {
+        if n == 0 || seen+float64(n) < rank {
+            seen += float64(n)
+            continue
+        }
+        if i == len(LatencyBounds) {
+            break
+        }
+        var lower time.Duration
+        if i > 0 {
+            lower = LatencyBounds[i-1]
+        }
+        fraction := (rank - seen) / float64(n)
+        return lower + time.Duration(fraction*float64(LatencyBounds[i]-lower))
+    }
+    return LatencyBounds[len(LatencyBounds)-1]
+}
+
+func sumCounts(counts []uint64) uint64 {
+    var total uint64
+    for _, n := range counts {
+        total += n
+    }
+    return total
+}
+
+// roomSizeIndex returns the index in RoomSizes of rooms with n clients.
+func roomSizeIndex(n int) int {
+    switch {
+    case n <= 10:
+        return 0
+    case n <= 100:
+        return 1
+    case n <= 1000:
+        return 2
+    default:
+        return 3
+    }
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// MaxLegalHoldReasonLength is the longest a legal hold's reason can be, in
+// characters.
+const MaxLegalHoldReasonLength = 500
+
+var (
+    // ErrInvalidLegalHold is returned for holds that name neither or both of
+    // a user and a room, or have no reason or an overlong one.
+    ErrInvalidLegalHold = errors.New("a legal hold needs either a user_id or a room_id, and a reason of 1-500 characters")
+    // ErrLegalHoldExists is returned when placing a hold on a user or room
+    // that is already under one.
+    ErrLegalHoldExists = errors.New("already under legal hold")
+    // ErrLegalHoldNotFound is returned for unknown holds.
+    ErrLegalHoldNotFound = errors.New("legal hold not found")
+    // ErrLegalHoldSubjectNotFound is returned when placing a hold on a user or
+    // room that does not exist.
+    ErrLegalHoldSubjectNotFound = errors.New("user or room not found")
+    // ErrLegalHold is returned when deleting an account or a room whose
+    // messages are under legal hold.
+    ErrLegalHold = errors.New("messages are under legal hold and cannot be deleted until it is released")
+)
+
+// LegalHold preserves a user's or a room's messages until it is released: a
+// message is held when its sender or its room is. Retention, bulk deletion
+// and purges skip held messages, and accounts and rooms with held messages
+// cannot be deleted.
+type LegalHold struct {
+    ID string `json:"id"`
+    // Exactly one of UserID and RoomID is set.
+    UserID *string `json:"user_id,omitempty"`
+    RoomID *string `json:"room_id,omitempty"`
+    Reason string  `json:"reason" example:"Litigation case 2025-114"`
+    // PlacedBy is absent once the account of whoever placed it is deleted.
+    PlacedBy  *string   `json:"placed_by,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// LegalHoldService lets administrators place and release legal holds.
+type LegalHoldService struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+}
+
+// NewLegalHoldService creates a new LegalHoldService.
+func NewLegalHoldService(db *database.Queries, pool *pgxpool.Pool) *LegalHoldService {
+    return &LegalHoldService{db: db, pool: pool}
+}
+
+// Holds returns every legal hold in place, newest first.
+func (s *LegalHoldService) Holds(ctx context.Context) ([]LegalHold, error) {
+    rows, err := s.db.GetLegalHolds(ctx)
+    if err != nil {
+        return nil, err
+    }
+    holds := make([]LegalHold, 0, len(rows))
+    for _, row := range rows {
+        holds = append(holds, *legalHoldFromRow(row))
+    }
+    return holds, nil
+}
+
+// Place puts a user or a room, whichever is given, under legal hold and
+// records it in the audit log. Callers are responsible for checking that
+// actorID is an administrator.
+func (s *LegalHoldService) Place(ctx context.Context, actorID uuid.UUID, userID, roomID *uuid.UUID, reason string) (*LegalHold, error) {
+    reason = strings.TrimSpace(reason)
+    if (userID == nil) == (roomID == nil) || reason == "" || utf8.RuneCountInString(reason) > MaxLegalHoldReasonLength {
+        return nil, ErrInvalidLegalHold
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.CreateLegalHold(ctx, database.CreateLegalHoldParams{
+        ID:       uuid.New(),
+        UserID:   userID,
+        RoomID:   roomID,
+        Reason:   reason,
+        PlacedBy: &actorID,
+    })
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) {
+        switch pgErr.Code {
+        case "23505": // unique_violation
+            return nil, ErrLegalHoldExists
+        case "23503": // foreign_key_violation
+            return nil, ErrLegalHoldSubjectNotFound
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionLegalHoldPlace,
+        RoomID:  roomID,
+        Details: map[string]any{"hold_id": row.ID, "user_id": userID, "reason": reason},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    return legalHoldFromRow(row), nil
+}
+
+// Release lifts a legal hold and records it in the audit log. Callers are
+// responsible for checking that actorID is an administrator.
+func (s *LegalHoldService) Release(ctx context.Context, actorID, holdID uuid.UUID) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.DeleteLegalHold(ctx, holdID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return ErrLegalHoldNotFound
+    }
+    if err != nil {
+        return err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionLegalHoldRelease,
+        RoomID:  row.RoomID,
+        Details: map[string]any{"hold_id": row.ID, "user_id": row.UserID, "reason": row.Reason},
+    })
+    if err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+func legalHoldFromRow(row database.LegalHold) *LegalHold {
+    hold := &LegalHold{
+        ID:        row.ID.String(),
+        Reason:    row.Reason,
+        CreatedAt: row.CreatedAt,
+    }
+    if row.UserID != nil {
+        userID := row.UserID.String()
+        hold.UserID = &userID
+    }
+    if row.RoomID != nil {
+        roomID := row.RoomID.String()
+        hold.RoomID = &roomID
+    }
+    if row.PlacedBy != nil {
+        placedBy := row.PlacedBy.String()
+        hold.PlacedBy = &placedBy
+    }
+    return hold
+}
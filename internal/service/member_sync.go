@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventMembersChanged is the type of the event sent to a room's connected
+// members when someone joins or leaves it or changes role.
+const EventMembersChanged = "members.changed"
+
+// Member change actions.
+const (
+    MemberAdded       = "add"
+    MemberRemoved     = "remove"
+    MemberRoleChanged = "role"
+)
+
+// MemberChangeRetention is how long changes to room members are kept for
+// clients to catch up with.
+const MemberChangeRetention = 30 * 24 * time.Hour
+
+// maxMemberChanges caps the changes in a delta; clients further behind are
+// sent back to the full member list.
+const maxMemberChanges = 1000
+
+// memberChangesChannel is the Postgres notification channel the
+// room_members trigger sends each change on.
+const memberChangesChannel = "room_member_changes"
+
+// memberSyncRetry is how long MemberSync waits before listening again after
+// losing its connection.
+const memberSyncRetry = 5 * time.Second
+
+// MemberChange is one change to a room's members.
+type MemberChange struct {
+    Version int64  `json:"version"`
+    UserID  string `json:"user_id"`
+    Action  string `json:"action"`
+    // Role is the member's role after an add or role change.
+    Role string `json:"role,omitempty"`
+}
+
+// MemberDelta brings a member list up to Version. A client holding an older
+// version applies Changes in order; if Reset is set, the changes since its
+// version are no longer known and it must fetch the full list instead.
+type MemberDelta struct {
+    Version int64          `json:"version"`
+    Reset   bool           `json:"reset,omitempty"`
+    Changes []MemberChange `json:"changes"`
+}
+
+// MemberChangesSince returns the changes to the room's members after the
+// given member version.
+func MemberChangesSince(ctx context.Context, db *database.Queries, room database.Room, since int64) (*MemberDelta, error) {
+    delta := &MemberDelta{Version: room.MemberVersion, Changes: []MemberChange{}}
+    if since == room.MemberVersion {
+        return delta, nil
+    }
+    if since > room.MemberVersion {
+        delta.Reset = true
+        return delta, nil
+    }
+
+    rows, err := db.GetRoomMemberChanges(ctx, database.GetRoomMemberChangesParams{
+        RoomID:       room.ID,
+        SinceVersion: since,
+        MaxResults:   maxMemberChanges + 1,
+    })
+    if err != nil {
+        return nil, err
+    }
+    // Changes older than the log, or too many to be worth sending.
+    if len(rows) == 0 || len(rows) > maxMemberChanges || rows[0].Version != since+1 {
+        delta.Reset = true
+        return delta, nil
+    }
+    for _, row := range rows {
+        change := MemberChange{Version: row.Version, UserID: row.UserID.String(), Action: row.Action}
+        if row.Role != nil {
+            change.Role = *row.Role
+        }
+        delta.Changes = append(delta.Changes, ownerRole(change, room))
+    }
+    delta.Version = delta.Changes[len(delta.Changes)-1].Version
+    return delta, nil
+}
+
+// ownerRole reports the owner the room was created by as an owner, whatever
+// their row says.
+func ownerRole(change MemberChange, room database.Room) MemberChange {
+    if change.Action != MemberRemoved && change.UserID == room.OwnerID.String() {
+        change.Role = RoomRoleOwner
+    }
+    return change
+}
+
+// MemberSync relays committed changes to room members, from any server
+// instance, to the connected members of the room as members.changed events.
+type MemberSync struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+    hub  *Hub
+}
+
+// NewMemberSync creates a new MemberSync.
+func NewMemberSync(db *database.Queries, pool *pgxpool.Pool, hub *Hub) *MemberSync {
+    return &MemberSync{db: db, pool: pool, hub: hub}
+}
+
+// Run listens for member changes until ctx is done, listening again whenever
+// the connection is lost. Changes made while it is not listening are not
+// relayed; clients notice the gap in versions and catch up over REST.
+func (s *MemberSync) Run(ctx context.Context) {
+    for {
+        err := s.listen(ctx)
+        if ctx.Err() != nil {
+            return
+        }
+        log.Printf("member change listener failed: %v", err)
+        select {
+        case <-ctx.Done():
+            return
+        case <-time.After(memberSyncRetry):
+        }
+    }
+}
+
+// listen relays member changes over a connection of its own until it fails.
+func (s *MemberSync) listen(ctx context.Context) error {
+    pooled, err := s.pool.Acquire(ctx)
+    if err != nil {
+        return err
+    }
+    // The connection stays subscribed, so it must not go back to the pool.
+    conn := pooled.Hijack()
+    defer conn.Close(context.Background())
+
+    if _, err := conn.Exec(ctx, "LISTEN "+memberChangesChannel); err != nil {
+        return err
+    }
+    for {
+        notification, err := conn.WaitForNotification(ctx)
+        if err != nil {
+            return err
+        }
+        s.relay(ctx, notification.Payload)
+    }
+}
+
+// relay broadcasts one change sent by the trigger.
+func (s *MemberSync) relay(ctx context.Context, payload string) {
+    var change struct {
+        MemberChange
+        RoomID uuid.UUID `json:"room_id"`
+    }
+    if err := json.Unmarshal([]byte(payload), &change); err != nil {
+        log.Printf("invalid member change %q: %v", payload, err)
+        return
+    }
+    room, err := s.db.GetRoomByID(ctx, change.RoomID)
+    if err != nil {
+        // Deleted since.
+        return
+    }
+    s.hub.Broadcast(&Message{
+        Type:      EventMembersChanged,
+        RoomID:    room.ID.String(),
+        CreatedAt: time.Now(),
+        Members: &MemberDelta{
+            Version: change.Version,
+            Changes: []MemberChange{ownerRole(change.MemberChange, room)},
+        },
+    })
+}
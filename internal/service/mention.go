@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Room mentions: @room mentions every member of the room and @here the
+// members connected to it. They take precedence over users and groups of the
+// same name.
+const (
+    MentionRoom = "room"
+    MentionHere = "here"
+)
+
+// ErrorCodeRoomMentionNotAllowed is sent in error frames for messages using
+// @room or @here without the room's required role.
+const ErrorCodeRoomMentionNotAllowed = "room_mention_not_allowed"
+
+// ErrRoomMentionNotAllowed is returned when the sender's role in the room is
+// below the one it requires for @room and @here.
+var ErrRoomMentionNotAllowed = errors.New("your role in this room does not allow @room or @here")
+
+// mentionPattern matches @name mentions of users and groups. The @ must not be
+// preceded by a word character so email addresses are not treated as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.\-]+)`)
+
+// parseMentions returns the distinct, lower-cased names mentioned in content.
+func parseMentions(content string) []string {
+    var names []string
+    seen := make(map[string]bool)
+    for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+        name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+        if name == "" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        names = append(names, name)
+    }
+    return names
+}
+
+// resolveMentions expands the users, groups and room mentions in content
+// into the IDs of the room members they refer to, checking that the sender
+// may use room mentions.
+func (s *MessageService) resolveMentions(ctx context.Context, roomID, senderID uuid.UUID, content string) ([]uuid.UUID, error) {
+    var names []string
+    var roomMention string
+    for _, name := range parseMentions(content) {
+        switch {
+        case name == MentionRoom:
+            roomMention = MentionRoom
+        case name == MentionHere:
+            if roomMention == "" {
+                roomMention = MentionHere
+            }
+        default:
+            names = append(names, name)
+        }
+    }
+    if roomMention != "" {
+        if err := s.checkRoomMention(ctx, roomID, senderID); err != nil {
+            return nil, err
+        }
+    }
+
+    ids := []uuid.UUID{}
+    if len(names) > 0 {
+        resolved, err := s.db.ResolveMentions(ctx, database.ResolveMentionsParams{RoomID: roomID, Names: names})
+        if err != nil {
+            return nil, err
+        }
+        ids = append(ids, resolved...)
+    }
+    seen := make(map[uuid.UUID]bool, len(ids))
+    for _, id := range ids {
+        seen[id] = true
+    }
+    switch roomMention {
+    case MentionRoom:
+        members, err := s.db.GetRoomMembers(ctx, roomID)
+        if err != nil {
+            return nil, err
+        }
+        for _, member := range members {
+            if !seen[member.ID] {
+                seen[member.ID] = true
+                ids = append(ids, member.ID)
+            }
+        }
+    case MentionHere:
+        if s.online == nil {
+            break
+        }
+        for userID := range s.online(roomID) {
+            id, err := uuid.Parse(userID)
+            if err == nil && !seen[id] {
+                seen[id] = true
+                ids = append(ids, id)
+            }
+        }
+    }
+    return ids, nil
+}
+
+// checkRoomMention returns ErrRoomMentionNotAllowed unless the sender has at
+// least the role the room requires for @room and @here.
+func (s *MessageService) checkRoomMention(ctx context.Context, roomID, senderID uuid.UUID) error {
+    room, err := s.db.GetRoomByID(ctx, roomID)
+    if err != nil {
+        return err
+    }
+    role, err := RoomRole(ctx, s.db, room, senderID)
+    if err != nil {
+        return err
+    }
+    if !HasRoomRole(role, room.RoomMentionRole) {
+        return ErrRoomMentionNotAllowed
+    }
+    return nil
+}
+
+// hasRoomMention reports whether content uses @room or @here.
+func hasRoomMention(content string) bool {
+    for _, name := range parseMentions(content) {
+        if name == MentionRoom || name == MentionHere {
+            return true
+        }
+    }
+    return false
+}
+
+// notifyMentions records a mention notification for every mentioned user
+// except the sender and those who muted the room. Failures are logged; the
+// message has already been saved.
+func (s *MessageService) notifyMentions(ctx context.Context, message database.Message) {
+    if len(message.Mentions) == 0 {
+        return
+    }
+    levels, err := roomNotificationLevels(ctx, s.db, message.RoomID)
+    if err != nil {
+        log.Printf("failed to record mentions for message %s: %v", message.ID, err)
+        return
+    }
+    var userIDs []uuid.UUID
+    for _, id := range message.Mentions {
+        if id != message.SenderID && levels[id.String()] != NotificationLevelNone {
+            userIDs = append(userIDs, id)
+        }
+    }
+    if len(userIDs) == 0 {
+        return
+    }
+    err = s.db.CreateNotifications(ctx, database.CreateNotificationsParams{
+        UserIds:   userIDs,
+        RoomID:    message.RoomID,
+        MessageID: message.ID,
+        Kind:      NotificationKindMention,
+        ActorID:   message.SenderID,
+    })
+    if err != nil {
+        log.Printf("failed to record mentions for message %s: %v", message.ID, err)
+    }
+}
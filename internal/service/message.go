@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// maxReplayMessages caps how many missed messages are replayed on reconnect.
+const maxReplayMessages = 500
+
+// ErrInvalidQuote is returned when a message quotes a message that does not
+// exist or that the sender cannot see.
+var ErrInvalidQuote = errors.New("quoted message not found in this room")
+
+// maxClientMsgIDLength caps the length of client-supplied idempotency keys.
+const maxClientMsgIDLength = 128
+
+var (
+    // ErrDuplicateMessage is returned by SaveMessage when the sender already
+    // sent a message with the same client message ID to the room. The message
+    // is filled in from the stored original instead of being saved again.
+    ErrDuplicateMessage = errors.New("message already sent")
+    // ErrInvalidClientMsgID is returned for client message IDs that are too long.
+    ErrInvalidClientMsgID = errors.New("client_msg_id is too long")
+)
+
+// QuotedMessage is a snapshot of the message a reply quotes.
+type QuotedMessage struct {
+    ID        string    `json:"id"`
+    SenderID  string    `json:"sender_id"`
+    Content   string    `json:"content"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// SenderProfile is the public profile of a message's sender. Which names it
+// holds depends on the server's DisplayNamePolicy.
+type SenderProfile struct {
+    Username    string  `json:"username,omitempty"`
+    DisplayName string  `json:"display_name,omitempty"`
+    AvatarURL   *string `json:"avatar_url,omitempty"`
+}
+
+// MessageOptions configures how incoming messages are processed.
+type MessageOptions struct {
+    // EmojiShortcodes converts :smile:-style shortcodes to Unicode before
+    // messages are stored and broadcast.
+    EmojiShortcodes bool
+    // MaxMessageSize is the global message size limit in bytes, used for
+    // rooms without their own. Zero means DefaultMaxMessageSize.
+    MaxMessageSize int
+    // UrgentDailyLimit is how many urgent messages a user may send per day.
+    // Zero means DefaultUrgentDailyLimit.
+    UrgentDailyLimit int
+    // DisplayNames decides which names of senders, members and participants
+    // are shown. Empty means DisplayNameUsername.
+    DisplayNames DisplayNamePolicy
+}
+
+// MessageService provides message persistence and history retrieval.
+type MessageService struct {
+    db    *database.Queries
+    store *MessageStore
+    opts  MessageOptions
+    // online reports who is connected to a room, for @here mentions. The
+    // hub sets it; without a hub @here mentions nobody.
+    online func(roomID uuid.UUID) map[string]bool
+}
+
+// NewMessageService creates a new MessageService.
+func NewMessageService(db *database.Queries, store *MessageStore, opts MessageOptions) *MessageService {
+    return &MessageService{db: db, store: store, opts: opts}
+}
+
+// MaxMessageSize returns the message size limit for a room: its own setting
+// if it has one, otherwise the global limit.
+func (s *MessageService) MaxMessageSize(room database.Room) int {
+    if room.MaxMessageSize != nil {
+        return int(*room.MaxMessageSize)
+    }
+    if s.opts.MaxMessageSize > 0 {
+        return s.opts.MaxMessageSize
+    }
+    return DefaultMaxMessageSize
+}
+
+// SaveMessage persists a chat message and fills in its ID, sequence number and
+// timestamp. A message carrying a client message ID the sender already used in
+// the room is not saved again; see ErrDuplicateMessage. Messages are stored as text unless
+// their kind is MessageKindEncrypted, whose content is stored as it is.
+func (s *MessageService) SaveMessage(ctx context.Context, msg *Message) error {
+    roomID, err := uuid.Parse(msg.RoomID)
+    if err != nil {
+        return err
+    }
+    senderID, err := uuid.Parse(msg.SenderID)
+    if err != nil {
+        return err
+    }
+
+    var recipientID *uuid.UUID
+    if msg.RecipientID != "" {
+        id, err := uuid.Parse(msg.RecipientID)
+        if err != nil {
+            return err
+        }
+        recipientID = &id
+    }
+
+    // Retries are resolved before anything else so they are not counted
+    // against limits such as the daily urgent quota a second time.
+    var clientMsgID *string
+    if msg.ClientMsgID != "" {
+        if len(msg.ClientMsgID) > maxClientMsgIDLength {
+            return ErrInvalidClientMsgID
+        }
+        clientMsgID = &msg.ClientMsgID
+        if err := s.findDuplicate(ctx, roomID, senderID, clientMsgID, msg); !errors.Is(err, pgx.ErrNoRows) {
+            return err
+        }
+    }
+
+    priority, err := s.resolvePriority(ctx, msg.Priority, roomID, senderID)
+    if err != nil {
+        return err
+    }
+
+    var quoted database.Message
+    var quotedID *uuid.UUID
+    msg.Quote = nil
+    if msg.QuotedMessageID != "" {
+        quoted, err = s.resolveQuote(ctx, msg.QuotedMessageID, roomID, senderID)
+        if err != nil {
+            return err
+        }
+        quotedID = &quoted.ID
+        msg.Quote = quoteFromRow(quoted)
+    }
+
+    // The system bot posts for integrations, which rooms do not restrict.
+    if hasAttachments(msg.Metadata) && senderID != SystemUserID {
+        if err := s.checkAttachments(ctx, roomID, senderID); err != nil {
+            return err
+        }
+    }
+
+    kind := MessageKindText
+    if msg.Kind == MessageKindEncrypted {
+        kind = MessageKindEncrypted
+    }
+
+    if s.opts.EmojiShortcodes && kind == MessageKindText {
+        msg.Content = NormalizeShortcodes(msg.Content)
+    }
+
+    // Mentions are only expanded for room messages; a direct message already
+    // reaches its only other participant. Ciphertext cannot mention anyone.
+    mentions := []uuid.UUID{}
+    if recipientID == nil && kind == MessageKindText {
+        mentions, err = s.resolveMentions(ctx, roomID, senderID, msg.Content)
+        if err != nil {
+            return err
+        }
+    }
+
+    metadata := []byte("{}")
+    if len(msg.Metadata) > 0 {
+        metadata, err = json.Marshal(msg.Metadata)
+        if err != nil {
+            return err
+        }
+    }
+
+    saved, err := s.store.Create(ctx, s.db, database.CreateMessageParams{
+        ID:              uuid.New(),
+        RoomID:          roomID,
+        SenderID:        senderID,
+        RecipientID:     recipientID,
+        Content:         msg.Content,
+        Metadata:        metadata,
+        Kind:            kind,
+        QuotedMessageID: quotedID,
+        Mentions:        mentions,
+        Priority:        priority,
+        ClientMsgID:     clientMsgID,
+    })
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" && clientMsgID != nil { // unique_violation
+        // A concurrent retry on another connection stored it first.
+        return s.findDuplicate(ctx, roomID, senderID, clientMsgID, msg)
+    }
+    if err != nil {
+        return err
+    }
+    s.notifyMentions(ctx, saved)
+    if quotedID != nil {
+        s.notifyReply(ctx, saved, quoted)
+    }
+
+    msg.ID = saved.ID.String()
+    msg.Seq = saved.Seq
+    msg.CreatedAt = saved.CreatedAt
+    msg.Mentions = mentionIDs(saved.Mentions)
+    msg.Priority = saved.Priority
+    return nil
+}
+
+// findDuplicate looks up the message the sender already sent to the room with
+// the client message ID. If there is one, msg is replaced by it and
+// ErrDuplicateMessage is returned; otherwise the lookup error, pgx.ErrNoRows,
+// is.
+func (s *MessageService) findDuplicate(ctx context.Context, roomID, senderID uuid.UUID, clientMsgID *string, msg *Message) error {
+    original, err := s.db.GetMessageByClientMsgID(ctx, database.GetMessageByClientMsgIDParams{
+        RoomID:      roomID,
+        SenderID:    senderID,
+        ClientMsgID: clientMsgID,
+    })
+    if err != nil {
+        return err
+    }
+    *msg = *messageFromRow(original)
+    return ErrDuplicateMessage
+}
+
+// GetMissedMessages returns the messages a user missed in a room, either after
+// the given sequence number or, when lastSeenSeq is zero, after the given time.
+// Rooms preloaded into the warm cache are answered from it while it is
+// current.
+func (s *MessageService) GetMissedMessages(ctx context.Context, room database.Room, userID uuid.UUID, lastSeenSeq int64, since time.Time) ([]*Message, error) {
+    if rows, ok := s.store.warm.missedMessages(room, userID, lastSeenSeq, since); ok {
+        return s.hydrate(ctx, rows)
+    }
+
+    var (
+        rows []database.Message
+        err  error
+    )
+
+    if lastSeenSeq > 0 {
+        rows, err = s.db.GetRoomMessagesAfterSeq(ctx, database.GetRoomMessagesAfterSeqParams{
+            RoomID:      room.ID,
+            Seq:         lastSeenSeq,
+            UserID:      userID,
+            MaxMessages: maxReplayMessages,
+        })
+    } else {
+        rows, err = s.db.GetRoomMessagesSince(ctx, database.GetRoomMessagesSinceParams{
+            RoomID:      room.ID,
+            Since:       since,
+            UserID:      userID,
+            MaxMessages: maxReplayMessages,
+        })
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    return s.hydrate(ctx, rows)
+}
+
+// GetLatestMessages returns up to limit of the most recent messages in a room
+// visible to the user, oldest first, with sender profiles attached. When
+// beforeSeq is non-zero only messages older than it are returned, for paging
+// back through history.
+func (s *MessageService) GetLatestMessages(ctx context.Context, roomID, userID uuid.UUID, limit int32, beforeSeq int64) ([]*Message, error) {
+    params := database.GetLatestRoomMessagesParams{
+        RoomID:      roomID,
+        UserID:      userID,
+        MaxMessages: limit,
+    }
+    if beforeSeq > 0 {
+        params.BeforeSeq = &beforeSeq
+    }
+
+    latest, err := s.db.GetLatestRoomMessages(ctx, params)
+    if err != nil {
+        return nil, err
+    }
+
+    // The query returns newest first; history is presented oldest first.
+    rows := make([]database.Message, len(latest))
+    senders := make([]*SenderProfile, len(latest))
+    for i, row := range latest {
+        j := len(latest) - 1 - i
+        rows[j] = row.Message
+        senders[j] = s.opts.DisplayNames.Profile(row.SenderUsername, row.SenderDisplayName, row.SenderAvatarUrl)
+    }
+
+    messages, err := s.hydrate(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    for i, message := range messages {
+        message.Sender = senders[i]
+    }
+    return messages, nil
+}
+
+// hydrate converts database rows into wire messages, attaching quoted
+// message snapshots, annotations and poll results.
+func (s *MessageService) hydrate(ctx context.Context, rows []database.Message) ([]*Message, error) {
+    quotes, err := s.loadQuotes(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    annotations, err := s.loadAnnotations(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    reactions, err := s.loadReactions(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+
+    messages := make([]*Message, 0, len(rows))
+    for _, row := range rows {
+        message := messageFromRow(row)
+        if row.QuotedMessageID != nil {
+            message.Quote = quotes[*row.QuotedMessageID]
+        }
+        message.Annotations = annotations[row.ID]
+        message.Reactions = reactions[row.ID]
+        // Polls are returned with their current (or final) results.
+        if row.Kind == MessageKindPoll {
+            poll, err := s.db.GetPollByMessageID(ctx, row.ID)
+            if err != nil {
+                return nil, err
+            }
+            if message.Poll, err = pollSnapshot(ctx, s.db, poll); err != nil {
+                return nil, err
+            }
+        }
+        messages = append(messages, message)
+    }
+    return messages, nil
+}
+
+// resolveQuote loads the quoted message and checks that it belongs to the room
+// and is visible to the sender.
+func (s *MessageService) resolveQuote(ctx context.Context, quotedMessageID string, roomID, senderID uuid.UUID) (database.Message, error) {
+    id, err := uuid.Parse(quotedMessageID)
+    if err != nil {
+        return database.Message{}, ErrInvalidQuote
+    }
+    quoted, err := s.db.GetMessageByID(ctx, id)
+    if err != nil || quoted.RoomID != roomID {
+        return database.Message{}, ErrInvalidQuote
+    }
+    // Private messages can only be quoted by their sender or recipient.
+    if quoted.RecipientID != nil && *quoted.RecipientID != senderID && quoted.SenderID != senderID {
+        return database.Message{}, ErrInvalidQuote
+    }
+    return quoted, nil
+}
+
+// loadQuotes fetches the messages quoted by rows in a single query.
+func (s *MessageService) loadQuotes(ctx context.Context, rows []database.Message) (map[uuid.UUID]*QuotedMessage, error) {
+    var ids []uuid.UUID
+    for _, row := range rows {
+        if row.QuotedMessageID != nil {
+            ids = append(ids, *row.QuotedMessageID)
+        }
+    }
+    quotes := make(map[uuid.UUID]*QuotedMessage, len(ids))
+    if len(ids) == 0 {
+        return quotes, nil
+    }
+
+    quoted, err := s.db.GetMessagesByIDs(ctx, ids)
+    if err != nil {
+        return nil, err
+    }
+    for _, row := range quoted {
+        quotes[row.ID] = quoteFromRow(row)
+    }
+    return quotes, nil
+}
+
+// quoteFromRow snapshots a database message for embedding in a reply.
+func quoteFromRow(row database.Message) *QuotedMessage {
+    return &QuotedMessage{
+        ID:        row.ID.String(),
+        SenderID:  row.SenderID.String(),
+        Content:   row.Content,
+        CreatedAt: row.CreatedAt,
+    }
+}
+
+// messageFromRow converts a database message into its wire representation.
+func messageFromRow(row database.Message) *Message {
+    msg := &Message{
+        ID:        row.ID.String(),
+        Seq:       row.Seq,
+        SenderID:  row.SenderID.String(),
+        RoomID:    row.RoomID.String(),
+        Content:   row.Content,
+        Kind:      row.Kind,
+        CreatedAt: row.CreatedAt,
+        EditedAt:  row.EditedAt,
+        Priority:  row.Priority,
+    }
+    if row.ClientMsgID != nil {
+        msg.ClientMsgID = *row.ClientMsgID
+    }
+    if row.RecipientID != nil {
+        msg.RecipientID = row.RecipientID.String()
+    }
+    if row.QuotedMessageID != nil {
+        msg.QuotedMessageID = row.QuotedMessageID.String()
+    }
+    msg.Mentions = mentionIDs(row.Mentions)
+    if len(row.Metadata) > 0 {
+        if err := json.Unmarshal(row.Metadata, &msg.Metadata); err != nil {
+            log.Printf("invalid metadata on message %s: %v", msg.ID, err)
+        }
+    }
+    return msg
+}
+
+// mentionIDs converts mentioned user IDs into their wire form.
+func mentionIDs(ids []uuid.UUID) []string {
+    if len(ids) == 0 {
+        return nil
+    }
+    mentions := make([]string, len(ids))
+    for i, id := range ids {
+        mentions[i] = id.String()
+    }
+    return mentions
+}
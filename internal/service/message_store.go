@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Message storage modes. In table mode messages are written to their tables
+// directly; in events mode every change is appended to the message event log,
+// and the tables are projections of it.
+const (
+    MessageStorageTable  = "table"
+    MessageStorageEvents = "events"
+)
+
+// Types of message events.
+const (
+    MessageEventCreated   = "message.created"
+    MessageEventEdited    = "message.edited"
+    MessageEventDeleted   = "message.deleted"
+    MessageEventAnnotated = "message.annotated"
+)
+
+// messageEventSettleDelay is how old events must be before the log is paged
+// through, so that readers never skip an event appended by a transaction that
+// committed late.
+const messageEventSettleDelay = 5 * time.Second
+
+// ErrMessageEventsDisabled is returned when reading the message event log
+// while messages are not stored as events.
+var ErrMessageEventsDisabled = errors.New("message events are not enabled")
+
+// MessageEvent is an entry of the message event log.
+type MessageEvent struct {
+    // Position orders the log; events are paged through by position.
+    Position  int64           `json:"position" example:"1042"`
+    ID        string          `json:"id"`
+    MessageID string          `json:"message_id"`
+    RoomID    string          `json:"room_id"`
+    ActorID   string          `json:"actor_id"`
+    Type      string          `json:"type" example:"message.edited"`
+    Data      json.RawMessage `json:"data" swaggertype:"object"`
+    CreatedAt time.Time       `json:"created_at"`
+}
+
+// messageCreated is the data of a message.created event. It holds the whole
+// message, so that the log alone can rebuild it.
+type messageCreated struct {
+    RecipientID     *uuid.UUID      `json:"recipient_id"`
+    Content         string          `json:"content"`
+    Metadata        json.RawMessage `json:"metadata"`
+    Kind            string          `json:"kind"`
+    QuotedMessageID *uuid.UUID      `json:"quoted_message_id"`
+    Mentions        []uuid.UUID     `json:"mentions"`
+    Priority        string          `json:"priority"`
+    ClientMsgID     *string         `json:"client_msg_id"`
+}
+
+// MessageStore writes messages and the changes made to them, either to the
+// message tables or, when event sourced, to the message event log. Every
+// write to messages, their revisions and their annotations goes through it.
+// Methods take the queries to run on, so writes can join the caller's
+// transaction; in events mode each write is a single statement, so they are
+// atomic either way.
+type MessageStore struct {
+    db           *database.Queries
+    eventSourced bool
+    // warm is the cache changed messages are dropped from, if any.
+    warm *WarmCache
+}
+
+// NewMessageStore creates a new MessageStore. When eventSourced is true,
+// messages are stored as events.
+func NewMessageStore(db *database.Queries, eventSourced bool) *MessageStore {
+    return &MessageStore{db: db, eventSourced: eventSourced}
+}
+
+// SetWarmCache sets the cache of recent messages that edits and deletions
+// are dropped from.
+func (s *MessageStore) SetWarmCache(warm *WarmCache) {
+    s.warm = warm
+}
+
+// EventSourced reports whether messages are stored as events.
+func (s *MessageStore) EventSourced() bool {
+    return s.eventSourced
+}
+
+// Create stores a new message.
+func (s *MessageStore) Create(ctx context.Context, q *database.Queries, params database.CreateMessageParams) (database.Message, error) {
+    if !s.eventSourced {
+        return q.CreateMessage(ctx, params)
+    }
+
+    metadata := json.RawMessage(params.Metadata)
+    if len(metadata) == 0 {
+        metadata = json.RawMessage("{}")
+    }
+    mentions := params.Mentions
+    if mentions == nil {
+        mentions = []uuid.UUID{}
+    }
+    data, err := json.Marshal(messageCreated{
+        RecipientID:     params.RecipientID,
+        Content:         params.Content,
+        Metadata:        metadata,
+        Kind:            params.Kind,
+        QuotedMessageID: params.QuotedMessageID,
+        Mentions:        mentions,
+        Priority:        params.Priority,
+        ClientMsgID:     params.ClientMsgID,
+    })
+    if err != nil {
+        return database.Message{}, err
+    }
+    if _, err := s.append(ctx, q, params.ID, params.RoomID, params.SenderID, MessageEventCreated, data); err != nil {
+        return database.Message{}, err
+    }
+    return q.GetMessageByID(ctx, params.ID)
+}
+
+// Edit replaces the content of a message, keeping the previous content as a
+// revision.
+func (s *MessageStore) Edit(ctx context.Context, q *database.Queries, message database.Message, editorID uuid.UUID, content string) (database.Message, error) {
+    s.warm.dropMessages(message.RoomID)
+    revisionID := uuid.New()
+    if !s.eventSourced {
+        return q.EditMessage(ctx, database.EditMessageParams{
+            RevisionID: revisionID,
+            EditedBy:   editorID,
+            ID:         message.ID,
+            Content:    content,
+        })
+    }
+
+    data, err := json.Marshal(map[string]any{"content": content, "revision_id": revisionID})
+    if err != nil {
+        return database.Message{}, err
+    }
+    if _, err := s.append(ctx, q, message.ID, message.RoomID, editorID, MessageEventEdited, data); err != nil {
+        return database.Message{}, err
+    }
+    return q.GetMessageByID(ctx, message.ID)
+}
+
+// Annotate attaches an annotation to a message.
+func (s *MessageStore) Annotate(ctx context.Context, q *database.Queries, message database.Message, params database.CreateMessageAnnotationParams) (database.MessageAnnotation, error) {
+    if !s.eventSourced {
+        return q.CreateMessageAnnotation(ctx, params)
+    }
+
+    data, err := json.Marshal(map[string]any{
+        "annotation_id": params.ID,
+        "kind":          params.Kind,
+        "data":          json.RawMessage(params.Data),
+    })
+    if err != nil {
+        return database.MessageAnnotation{}, err
+    }
+    if _, err := s.append(ctx, q, message.ID, message.RoomID, params.AuthorID, MessageEventAnnotated, data); err != nil {
+        return database.MessageAnnotation{}, err
+    }
+    annotations, err := q.GetMessageAnnotations(ctx, []uuid.UUID{message.ID})
+    if err != nil {
+        return database.MessageAnnotation{}, err
+    }
+    for _, annotation := range annotations {
+        if annotation.ID == params.ID {
+            return annotation, nil
+        }
+    }
+    return database.MessageAnnotation{}, pgx.ErrNoRows
+}
+
+// Delete deletes the room's messages with the given IDs or, when ids is
+// empty, those sent from from until to, and returns the IDs of the deleted
+// messages.
+func (s *MessageStore) Delete(ctx context.Context, q *database.Queries, roomID, actorID uuid.UUID, ids []uuid.UUID, from, to *time.Time) ([]uuid.UUID, error) {
+    s.warm.dropMessages(roomID)
+    if !s.eventSourced {
+        if len(ids) > 0 {
+            return q.DeleteRoomMessagesByIDs(ctx, database.DeleteRoomMessagesByIDsParams{RoomID: roomID, Ids: ids})
+        }
+        return q.DeleteRoomMessagesBetween(ctx, database.DeleteRoomMessagesBetweenParams{RoomID: roomID, StartTime: *from, EndTime: *to})
+    }
+
+    params := database.AppendMessageDeletionsParams{
+        ActorID: actorID,
+        Data:    []byte("{}"),
+        RoomID:  roomID,
+    }
+    if len(ids) > 0 {
+        params.Ids = ids
+    } else {
+        params.StartTime = from
+        params.EndTime = to
+    }
+    return q.AppendMessageDeletions(ctx, params)
+}
+
+// Forget drops the events of the room's messages that are no longer stored,
+// such as those purged by retention, from the log.
+func (s *MessageStore) Forget(ctx context.Context, q *database.Queries, roomID uuid.UUID) error {
+    s.warm.dropMessages(roomID)
+    if !s.eventSourced {
+        return nil
+    }
+    _, err := q.ForgetMessageEvents(ctx, roomID)
+    return err
+}
+
+// Backfill logs the messages and annotations stored before event sourcing was
+// turned on, as they are now, so that the log covers every stored message.
+// It does nothing in table mode.
+func (s *MessageStore) Backfill(ctx context.Context) (int64, error) {
+    if !s.eventSourced {
+        return 0, nil
+    }
+    messages, err := s.db.BackfillMessageEvents(ctx)
+    if err != nil {
+        return 0, err
+    }
+    annotations, err := s.db.BackfillAnnotationEvents(ctx)
+    if err != nil {
+        return messages, err
+    }
+    return messages + annotations, nil
+}
+
+// History returns the events of a message, oldest first, including those of
+// deleted messages. Direct messages are left out, as ErrMessageNotFound,
+// unless includeDirect is true.
+func (s *MessageStore) History(ctx context.Context, messageID uuid.UUID, includeDirect bool) ([]MessageEvent, error) {
+    if !s.eventSourced {
+        return nil, ErrMessageEventsDisabled
+    }
+    rows, err := s.db.GetMessageEvents(ctx, messageID)
+    if err != nil {
+        return nil, err
+    }
+    if len(rows) == 0 {
+        return nil, ErrMessageNotFound
+    }
+    if !includeDirect {
+        for _, row := range rows {
+            var created messageCreated
+            if row.Type == MessageEventCreated && json.Unmarshal(row.Data, &created) == nil && created.RecipientID != nil {
+                return nil, ErrMessageNotFound
+            }
+        }
+    }
+    return messageEventsFromRows(rows), nil
+}
+
+// Events pages through the log, returning up to limit events after the
+// position after. Only events older than a few seconds are returned.
+func (s *MessageStore) Events(ctx context.Context, after int64, limit int32) ([]MessageEvent, error) {
+    if !s.eventSourced {
+        return nil, ErrMessageEventsDisabled
+    }
+    rows, err := s.db.ListMessageEvents(ctx, database.ListMessageEventsParams{
+        After:         after,
+        SettledBefore: time.Now().Add(-messageEventSettleDelay),
+        MaxEvents:     limit,
+    })
+    if err != nil {
+        return nil, err
+    }
+    return messageEventsFromRows(rows), nil
+}
+
+// append adds an event to the log; the projection applies it in the same
+// statement.
+func (s *MessageStore) append(ctx context.Context, q *database.Queries, messageID, roomID, actorID uuid.UUID, eventType string, data []byte) (database.MessageEvent, error) {
+    return q.AppendMessageEvent(ctx, database.AppendMessageEventParams{
+        ID:        uuid.New(),
+        MessageID: messageID,
+        RoomID:    roomID,
+        ActorID:   actorID,
+        Type:      eventType,
+        Data:      data,
+    })
+}
+
+func messageEventsFromRows(rows []database.MessageEvent) []MessageEvent {
+    events := make([]MessageEvent, len(rows))
+    for i, row := range rows {
+        events[i] = MessageEvent{
+            Position:  row.Position,
+            ID:        row.ID.String(),
+            MessageID: row.MessageID.String(),
+            RoomID:    row.RoomID.String(),
+            ActorID:   row.ActorID.String(),
+            Type:      row.Type,
+            Data:      row.Data,
+            CreatedAt: row.CreatedAt,
+        }
+    }
+    return events
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventMessagesDeleted is the type of the event announcing a bulk deletion.
+const EventMessagesDeleted = "messages.deleted"
+
+// maxBulkDeleteIDs caps how many message IDs a single bulk delete may list.
+const maxBulkDeleteIDs = 1000
+
+// ErrInvalidBulkDelete is returned when a bulk delete names neither message
+// IDs nor a user or time range, or both, or a time range that does not end
+// after it starts.
+var ErrInvalidBulkDelete = errors.New("provide either up to 1000 message ids, or a user_id and/or a time range with from before to")
+
+// BulkDelete selects the messages of a room to delete: either by ID, or all
+// messages sent by UserID and/or created in [From, To). Either end of the
+// range may be left open.
+type BulkDelete struct {
+    IDs    []uuid.UUID `json:"ids,omitempty"`
+    UserID *uuid.UUID  `json:"user_id,omitempty"`
+    From   *time.Time  `json:"from,omitempty"`
+    To     *time.Time  `json:"to,omitempty"`
+}
+
+// DeletedMessages summarizes a bulk deletion for connected clients.
+type DeletedMessages struct {
+    Count int      `json:"count"`
+    IDs   []string `json:"ids"`
+}
+
+// ModerationService performs moderator actions on room content.
+type ModerationService struct {
+    db    *database.Queries
+    pool  *pgxpool.Pool
+    store *MessageStore
+    hub   *Hub
+    jobs  *JobQueue
+}
+
+// NewModerationService creates a new ModerationService. Purges are queued as
+// jobs, which it registers to run.
+func NewModerationService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore, hub *Hub, jobs *JobQueue) *ModerationService {
+    s := &ModerationService{db: db, pool: pool, store: store, hub: hub, jobs: jobs}
+    jobs.Handle(JobTypeMessagePurge, s.runPurge)
+    return s
+}
+
+// BulkDeleteMessages deletes the room's messages with the given IDs, records
+// the deletion in the audit log and announces it to the room in a single
+// event. Deletions by user or time range are purges; see PurgeMessages.
+// Callers are responsible for checking that actorID may do so.
+func (s *ModerationService) BulkDeleteMessages(ctx context.Context, roomID, actorID uuid.UUID, req BulkDelete) (*DeletedMessages, error) {
+    if len(req.IDs) == 0 || len(req.IDs) > maxBulkDeleteIDs || req.UserID != nil || req.From != nil || req.To != nil {
+        return nil, ErrInvalidBulkDelete
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    deleted, err := s.store.Delete(ctx, qtx, roomID, actorID, req.IDs, nil, nil)
+    if err != nil {
+        return nil, err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionMessagesBulkDelete,
+        RoomID:  &roomID,
+        Details: map[string]any{"deleted": len(deleted)},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    summary := &DeletedMessages{Count: len(deleted), IDs: make([]string, len(deleted))}
+    for i, id := range deleted {
+        summary.IDs[i] = id.String()
+    }
+    if summary.Count > 0 {
+        s.hub.Broadcast(&Message{
+            Type:      EventMessagesDeleted,
+            SenderID:  actorID.String(),
+            RoomID:    roomID.String(),
+            CreatedAt: time.Now(),
+            Deleted:   summary,
+        })
+    }
+    return summary, nil
+}
+
+var (
+    // ErrMemberProtected is returned when the actor does not outrank the
+    // member they try to kick or ban.
+    ErrMemberProtected = errors.New("owners cannot be kicked or banned, and moderators only by owners")
+    // ErrBanNotFound is returned when lifting a ban that does not exist.
+    ErrBanNotFound = errors.New("user is not banned from this room")
+    // ErrUserNotFound is returned when banning a user who does not exist.
+    ErrUserNotFound = errors.New("user not found")
+)
+
+// Ban is a user's ban from a room. Bans without an expiry are permanent.
+type Ban struct {
+    UserID    string     `json:"user_id"`
+    RoomID    string     `json:"room_id"`
+    BannedBy  string     `json:"banned_by,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+    ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Kick removes a member from the room and closes their live connection to it.
+// They can join again. Callers are responsible for checking that actorID can
+// moderate the room.
+func (s *ModerationService) Kick(ctx context.Context, room database.Room, actorID, userID uuid.UUID) error {
+    role, err := s.db.GetRoomMemberRole(ctx, database.GetRoomMemberRoleParams{RoomID: room.ID, UserID: userID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return ErrNotRoomMember
+    }
+    if err != nil {
+        return err
+    }
+    if err := s.checkOutranks(ctx, room, actorID, userID, role); err != nil {
+        return err
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    if err := qtx.RemoveRoomMember(ctx, database.RemoveRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+        return err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionMemberKick,
+        RoomID:  &room.ID,
+        Details: map[string]any{"user_id": userID},
+    })
+    if err != nil {
+        return err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return err
+    }
+
+    s.hub.Disconnect(room.ID, userID, "kicked from the room")
+    return nil
+}
+
+// Ban bans a user from the room for duration, or for good when duration is 0.
+// Members are removed and their live connection to the room is closed, and any
+// pending join request is dropped. Banning a banned user replaces the ban.
+// Callers are responsible for checking that actorID can moderate the room.
+func (s *ModerationService) Ban(ctx context.Context, room database.Room, actorID, userID uuid.UUID, duration time.Duration) (*Ban, error) {
+    role, err := s.db.GetRoomMemberRole(ctx, database.GetRoomMemberRoleParams{RoomID: room.ID, UserID: userID})
+    if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+        return nil, err
+    }
+    if err := s.checkOutranks(ctx, room, actorID, userID, role); err != nil {
+        return nil, err
+    }
+
+    var expiresAt *time.Time
+    if duration > 0 {
+        t := time.Now().Add(duration)
+        expiresAt = &t
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.BanRoomMember(ctx, database.BanRoomMemberParams{
+        RoomID:    room.ID,
+        UserID:    userID,
+        BannedBy:  &actorID,
+        ExpiresAt: expiresAt,
+    })
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if err := qtx.RemoveRoomMember(ctx, database.RemoveRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+        return nil, err
+    }
+    if _, err := qtx.DeleteJoinRequest(ctx, database.DeleteJoinRequestParams{RoomID: room.ID, UserID: userID}); err != nil {
+        return nil, err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionMemberBan,
+        RoomID:  &room.ID,
+        Details: map[string]any{"user_id": userID, "expires_at": expiresAt},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    s.hub.Disconnect(room.ID, userID, "banned from the room")
+    return banFromRow(row), nil
+}
+
+// Unban lifts a user's ban from the room. Callers are responsible for checking
+// that actorID can moderate the room.
+func (s *ModerationService) Unban(ctx context.Context, roomID, actorID, userID uuid.UUID) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    deleted, err := qtx.UnbanRoomMember(ctx, database.UnbanRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return err
+    }
+    if deleted == 0 {
+        return ErrBanNotFound
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionMemberUnban,
+        RoomID:  &roomID,
+        Details: map[string]any{"user_id": userID},
+    })
+    if err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+// Bans returns the room's bans that have not expired, newest first.
+func (s *ModerationService) Bans(ctx context.Context, roomID uuid.UUID) ([]Ban, error) {
+    rows, err := s.db.GetRoomBans(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    bans := make([]Ban, 0, len(rows))
+    for _, row := range rows {
+        bans = append(bans, *banFromRow(row))
+    }
+    return bans, nil
+}
+
+// checkOutranks returns ErrMemberProtected unless the actor may kick or ban a
+// user with the given role in the room: owners can act on moderators and
+// members, moderators only on members. The owner the room was created by is
+// always protected.
+func (s *ModerationService) checkOutranks(ctx context.Context, room database.Room, actorID, userID uuid.UUID, role string) error {
+    if userID == room.OwnerID || role == RoomRoleOwner {
+        return ErrMemberProtected
+    }
+    if role != RoomRoleModerator {
+        return nil
+    }
+    owner, err := IsRoomOwner(ctx, s.db, room, actorID)
+    if err != nil {
+        return err
+    }
+    if !owner {
+        return ErrMemberProtected
+    }
+    return nil
+}
+
+// banFromRow converts a ban row.
+func banFromRow(row database.RoomBan) *Ban {
+    ban := &Ban{
+        UserID:    row.UserID.String(),
+        RoomID:    row.RoomID.String(),
+        CreatedAt: row.CreatedAt,
+        ExpiresAt: row.ExpiresAt,
+    }
+    if row.BannedBy != nil {
+        ban.BannedBy = row.BannedBy.String()
+    }
+    return ban
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Room notification levels. Members are notified of mentions and direct
+// messages in a room by default; they can choose to be notified of every
+// message instead, or of nothing.
+const (
+    NotificationLevelAll      = "all"
+    NotificationLevelMentions = "mentions"
+    NotificationLevelNone     = "none"
+    // DefaultNotificationLevel applies to members who have not chosen one.
+    DefaultNotificationLevel = NotificationLevelMentions
+)
+
+// ErrInvalidNotificationLevel is returned for unknown notification levels.
+var ErrInvalidNotificationLevel = errors.New("level must be all, mentions or none")
+
+// RoomNotificationSettings is how much a member wants to be notified about a
+// room.
+type RoomNotificationSettings struct {
+    RoomID string `json:"room_id"`
+    // Level is "all" to be pushed every message while offline, "mentions"
+    // (the default) for mentions and direct messages only, or "none" to
+    // mute the room: no pushes, urgent ones included, and no activity feed
+    // items. Unread counts are kept either way.
+    Level string `json:"level" example:"mentions"`
+}
+
+// NotificationSettingsService manages members' room notification levels.
+type NotificationSettingsService struct {
+    db *database.Queries
+}
+
+// NewNotificationSettingsService creates a new NotificationSettingsService.
+func NewNotificationSettingsService(db *database.Queries) *NotificationSettingsService {
+    return &NotificationSettingsService{db: db}
+}
+
+// RoomSettings returns the member's notification settings for the room.
+func (s *NotificationSettingsService) RoomSettings(ctx context.Context, roomID, userID uuid.UUID) (*RoomNotificationSettings, error) {
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if !isMember {
+        return nil, ErrNotRoomMember
+    }
+    level, err := roomNotificationLevel(ctx, s.db, roomID, userID)
+    if err != nil {
+        return nil, err
+    }
+    return &RoomNotificationSettings{RoomID: roomID.String(), Level: level}, nil
+}
+
+// SetRoomLevel sets the member's notification level for the room.
+func (s *NotificationSettingsService) SetRoomLevel(ctx context.Context, roomID, userID uuid.UUID, level string) (*RoomNotificationSettings, error) {
+    switch level {
+    case NotificationLevelAll, NotificationLevelMentions, NotificationLevelNone:
+    default:
+        return nil, ErrInvalidNotificationLevel
+    }
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if !isMember {
+        return nil, ErrNotRoomMember
+    }
+
+    err = s.db.SetRoomNotificationLevel(ctx, database.SetRoomNotificationLevelParams{RoomID: roomID, UserID: userID, Level: level})
+    if err != nil {
+        return nil, err
+    }
+    return &RoomNotificationSettings{RoomID: roomID.String(), Level: level}, nil
+}
+
+// roomNotificationLevel returns the member's notification level for the
+// room, with the default applied.
+func roomNotificationLevel(ctx context.Context, db *database.Queries, roomID, userID uuid.UUID) (string, error) {
+    level, err := db.GetRoomNotificationLevel(ctx, database.GetRoomNotificationLevelParams{RoomID: roomID, UserID: userID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return DefaultNotificationLevel, nil
+    }
+    return level, err
+}
+
+// roomNotificationLevels returns the levels the room's members chose, by user
+// ID. Members who never chose one are left out; the default applies to them.
+func roomNotificationLevels(ctx context.Context, db *database.Queries, roomID uuid.UUID) (map[string]string, error) {
+    rows, err := db.GetRoomNotificationLevels(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    levels := make(map[string]string, len(rows))
+    for _, row := range rows {
+        levels[row.UserID.String()] = row.Level
+    }
+    return levels, nil
+}
+
+// notifyRoom pushes a new room message to the members who are not connected
+// to the room and want to hear of it: the mentioned ones, unless they muted
+// the room, and those who chose to be notified of every message.
+func (h *Hub) notifyRoom(message *Message, online map[string]bool) {
+    roomID, err := uuid.Parse(message.RoomID)
+    if err != nil {
+        return
+    }
+    levels, err := roomNotificationLevels(context.Background(), h.messages.db, roomID)
+    if err != nil {
+        log.Printf("failed to load notification levels of room %s: %v", message.RoomID, err)
+        return
+    }
+
+    mentioned := make(map[string]bool, len(message.Mentions))
+    var offline []string
+    for _, userID := range message.Mentions {
+        mentioned[userID] = true
+        if !online[userID] && userID != message.SenderID && levels[userID] != NotificationLevelNone {
+            offline = append(offline, userID)
+        }
+    }
+    switch {
+    case len(offline) == 0:
+    case hasRoomMention(message.Content):
+        h.roomMentions.add(message, offline)
+    default:
+        h.notifyMentioned(message, offline)
+    }
+
+    var notification *PushNotification
+    for userID, level := range levels {
+        if level != NotificationLevelAll || online[userID] || mentioned[userID] || userID == message.SenderID {
+            continue
+        }
+        if notification == nil {
+            built := h.pushNotification(message, h.pushTemplates.MessageTitle)
+            notification = &built
+        }
+        if err := h.push.Push(context.Background(), userID, *notification); err != nil {
+            log.Printf("failed to push message to %s: %v", userID, err)
+        }
+    }
+}
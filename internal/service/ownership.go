@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Roles a member can have in a room. Owners have every right over the room;
+// moderators can also edit its settings and moderate its members and
+// messages.
+const (
+    RoomRoleOwner     = "owner"
+    RoomRoleModerator = "moderator"
+    RoomRoleMember    = "member"
+)
+
+// ValidRoomRole reports whether role is one of the room roles.
+func ValidRoomRole(role string) bool {
+    return role == RoomRoleOwner || role == RoomRoleModerator || role == RoomRoleMember
+}
+
+// roomRoleRanks orders the room roles from least to most privileged.
+var roomRoleRanks = map[string]int{RoomRoleMember: 1, RoomRoleModerator: 2, RoomRoleOwner: 3}
+
+// HasRoomRole reports whether role is at least as privileged as minimum.
+// Users without a role have none of the roles.
+func HasRoomRole(role, minimum string) bool {
+    return role != "" && roomRoleRanks[role] >= roomRoleRanks[minimum]
+}
+
+// RoomRole returns the user's role in the room. The owner the room was created
+// by is always an owner, member or not; other users who are not members have
+// no role and get "".
+func RoomRole(ctx context.Context, db *database.Queries, room database.Room, userID uuid.UUID) (string, error) {
+    if room.OwnerID == userID {
+        return RoomRoleOwner, nil
+    }
+    role, err := db.GetRoomMemberRole(ctx, database.GetRoomMemberRoleParams{RoomID: room.ID, UserID: userID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", nil
+    }
+    return role, err
+}
+
+// IsRoomOwner reports whether the user owns the room, either as the owner it
+// was created by or as a member with the owner role (a co-owner). Co-owners
+// have the same rights as the owner.
+func IsRoomOwner(ctx context.Context, db *database.Queries, room database.Room, userID uuid.UUID) (bool, error) {
+    role, err := RoomRole(ctx, db, room, userID)
+    return role == RoomRoleOwner, err
+}
+
+// CanModerateRoom reports whether the user is an owner or moderator of the
+// room.
+func CanModerateRoom(ctx context.Context, db *database.Queries, room database.Room, userID uuid.UUID) (bool, error) {
+    role, err := RoomRole(ctx, db, room, userID)
+    return role == RoomRoleOwner || role == RoomRoleModerator, err
+}
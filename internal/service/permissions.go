@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// ErrorCodeAttachmentsNotAllowed is sent in error frames for messages with
+// attachments from members whose role the room does not allow them for.
+const ErrorCodeAttachmentsNotAllowed = "attachments_not_allowed"
+
+// AttachmentsMetadataKey is the message metadata key clients list a message's
+// attachments under. Messages with it count as posting attachments.
+const AttachmentsMetadataKey = "attachments"
+
+var (
+    // ErrInvalidPermissions is returned for permissions that are not a room
+    // role.
+    ErrInvalidPermissions = errors.New("permissions must be owner, moderator or member")
+    // ErrAttachmentsNotAllowed is returned when the sender's role in the room
+    // is below the one it requires for attachments.
+    ErrAttachmentsNotAllowed = errors.New("your role in this room does not allow attachments")
+)
+
+// RoomPermissions name the least role a member needs for each action in a
+// room. Owners can always do everything.
+type RoomPermissions struct {
+    // Invite is who can invite users and create invite codes. It defaults
+    // to member in public rooms and owner in private ones.
+    Invite string `json:"invite" example:"member"`
+    // Attachments is who can send messages with attachments, listed under
+    // "attachments" in the message's metadata. It defaults to member.
+    Attachments string `json:"attachments" example:"member"`
+    // Pin is who can pin and unpin messages. It defaults to moderator.
+    Pin string `json:"pin" example:"moderator"`
+}
+
+// RoomPermissionsUpdate changes some of a room's permissions. Fields left
+// out keep their value, and an empty string restores the default.
+type RoomPermissionsUpdate struct {
+    Invite      *string `json:"invite,omitempty" example:"moderator"`
+    Attachments *string `json:"attachments,omitempty" example:"member"`
+    Pin         *string `json:"pin,omitempty" example:"moderator"`
+}
+
+// defaultRoomPermissions returns the permissions of a room whose owners have
+// not changed them.
+func defaultRoomPermissions(room database.Room) RoomPermissions {
+    permissions := RoomPermissions{Invite: RoomRoleMember, Attachments: RoomRoleMember, Pin: RoomRoleModerator}
+    if room.Visibility == RoomVisibilityPrivate {
+        permissions.Invite = RoomRoleOwner
+    }
+    return permissions
+}
+
+// LoadRoomPermissions returns the room's permissions, with defaults for the
+// ones its owners have not set.
+func LoadRoomPermissions(ctx context.Context, db *database.Queries, room database.Room) (RoomPermissions, error) {
+    permissions := defaultRoomPermissions(room)
+    stored, err := storedRoomPermissions(ctx, db, room.ID)
+    if err != nil {
+        return permissions, err
+    }
+    if stored.Invite != "" {
+        permissions.Invite = stored.Invite
+    }
+    if stored.Attachments != "" {
+        permissions.Attachments = stored.Attachments
+    }
+    if stored.Pin != "" {
+        permissions.Pin = stored.Pin
+    }
+    return permissions, nil
+}
+
+// SetRoomPermissions applies an update to the room's permissions and returns
+// the result. Only the permissions the owners set are stored, so the others
+// keep following the defaults, such as when the room's visibility changes.
+func SetRoomPermissions(ctx context.Context, db *database.Queries, room database.Room, actorID uuid.UUID, update RoomPermissionsUpdate) (RoomPermissions, error) {
+    for _, role := range []*string{update.Invite, update.Attachments, update.Pin} {
+        if role != nil && *role != "" && !ValidRoomRole(*role) {
+            return RoomPermissions{}, ErrInvalidPermissions
+        }
+    }
+    stored, err := storedRoomPermissions(ctx, db, room.ID)
+    if err != nil {
+        return RoomPermissions{}, err
+    }
+    if update.Invite != nil {
+        stored.Invite = *update.Invite
+    }
+    if update.Attachments != nil {
+        stored.Attachments = *update.Attachments
+    }
+    if update.Pin != nil {
+        stored.Pin = *update.Pin
+    }
+
+    settings, err := json.Marshal(stored)
+    if err != nil {
+        return RoomPermissions{}, err
+    }
+    err = db.SetRoomPermissions(ctx, database.SetRoomPermissionsParams{RoomID: room.ID, Settings: settings, UpdatedBy: &actorID})
+    if err != nil {
+        return RoomPermissions{}, err
+    }
+    return LoadRoomPermissions(ctx, db, room)
+}
+
+// storedRoomPermissions returns the permissions the room's owners set; the
+// others are empty.
+func storedRoomPermissions(ctx context.Context, db *database.Queries, roomID uuid.UUID) (storedPermissions, error) {
+    var stored storedPermissions
+    settings, err := db.GetRoomPermissions(ctx, roomID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return stored, nil
+    }
+    if err != nil {
+        return stored, err
+    }
+    if err := json.Unmarshal(settings, &stored); err != nil {
+        log.Printf("invalid permissions of room %s: %v", roomID, err)
+        return storedPermissions{}, nil
+    }
+    return stored, nil
+}
+
+// storedPermissions is how room permissions are kept in the database.
+type storedPermissions struct {
+    Invite      string `json:"invite,omitempty"`
+    Attachments string `json:"attachments,omitempty"`
+    Pin         string `json:"pin,omitempty"`
+}
+
+// hasAttachments reports whether message metadata lists attachments.
+func hasAttachments(metadata map[string]any) bool {
+    attachments, ok := metadata[AttachmentsMetadataKey]
+    return ok && attachments != nil
+}
+
+// checkAttachments returns ErrAttachmentsNotAllowed unless the sender has at
+// least the role the room requires for attachments.
+func (s *MessageService) checkAttachments(ctx context.Context, roomID, senderID uuid.UUID) error {
+    room, err := s.db.GetRoomByID(ctx, roomID)
+    if err != nil {
+        return err
+    }
+    role, err := RoomRole(ctx, s.db, room, senderID)
+    if err != nil {
+        return err
+    }
+    permissions, err := LoadRoomPermissions(ctx, s.db, room)
+    if err != nil {
+        return err
+    }
+    if !HasRoomRole(role, permissions.Attachments) {
+        return ErrAttachmentsNotAllowed
+    }
+    return nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Types of the events sent when a message is pinned or unpinned.
+const (
+    EventMessagePinned   = "message.pinned"
+    EventMessageUnpinned = "message.unpinned"
+)
+
+// MaxRoomPins is how many messages a room can have pinned.
+const MaxRoomPins = 50
+
+var (
+    // ErrPinNotAllowed is returned when the user's role in the room is below
+    // the one it requires for pinning.
+    ErrPinNotAllowed = errors.New("your role in this room does not allow pinning messages")
+    // ErrTooManyPins is returned when pinning a message in a room with
+    // MaxRoomPins pinned messages.
+    ErrTooManyPins = errors.New("rooms can have at most 50 pinned messages")
+    // ErrInvalidPin is returned for direct messages, which cannot be pinned.
+    ErrInvalidPin = errors.New("direct messages cannot be pinned")
+)
+
+// PinnedMessage is a message pinned in its room.
+type PinnedMessage struct {
+    *Message
+    // PinnedBy is absent once the user who pinned it deleted their account.
+    PinnedBy *string   `json:"pinned_by,omitempty"`
+    PinnedAt time.Time `json:"pinned_at"`
+}
+
+// PinUpdate is set on message.pinned and message.unpinned events, and is the
+// data of pin webhook deliveries.
+type PinUpdate struct {
+    MessageID string `json:"message_id"`
+    Pinned    bool   `json:"pinned"`
+    ActorID   string `json:"actor_id"`
+}
+
+// PinService pins messages in rooms.
+type PinService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+    webhooks *WebhookService
+}
+
+// NewPinService creates a new PinService.
+func NewPinService(db *database.Queries, messages *MessageService, hub *Hub, webhooks *WebhookService) *PinService {
+    return &PinService{db: db, messages: messages, hub: hub, webhooks: webhooks}
+}
+
+// Pins returns the room's pinned messages, most recently pinned first.
+func (s *PinService) Pins(ctx context.Context, roomID, userID uuid.UUID) ([]*PinnedMessage, error) {
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: roomID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if !isMember {
+        return nil, ErrNotRoomMember
+    }
+
+    pins, err := s.db.GetRoomPins(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    rows := make([]database.Message, len(pins))
+    for i, pin := range pins {
+        rows[i] = pin.Message
+    }
+    messages, err := s.messages.hydrate(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    result := make([]*PinnedMessage, len(messages))
+    for i, message := range messages {
+        result[i] = &PinnedMessage{Message: message, PinnedAt: pins[i].PinnedAt}
+        if pins[i].PinnedBy != nil {
+            pinnedBy := pins[i].PinnedBy.String()
+            result[i].PinnedBy = &pinnedBy
+        }
+    }
+    return result, nil
+}
+
+// Pin pins a message of the room for everyone in it. Pinning a pinned message
+// is not an error.
+func (s *PinService) Pin(ctx context.Context, userID, messageID uuid.UUID) error {
+    message, room, err := s.pinnable(ctx, userID, messageID)
+    if err != nil {
+        return err
+    }
+    count, err := s.db.CountRoomPins(ctx, room.ID)
+    if err != nil {
+        return err
+    }
+    if count >= MaxRoomPins {
+        return ErrTooManyPins
+    }
+
+    pinned, err := s.db.PinMessage(ctx, database.PinMessageParams{MessageID: message.ID, RoomID: room.ID, PinnedBy: &userID})
+    if err != nil {
+        return err
+    }
+    if pinned > 0 {
+        s.changed(message, userID, true)
+    }
+    return nil
+}
+
+// Unpin unpins a message of the room. Unpinning a message that is not pinned
+// is not an error.
+func (s *PinService) Unpin(ctx context.Context, userID, messageID uuid.UUID) error {
+    message, _, err := s.pinnable(ctx, userID, messageID)
+    if err != nil {
+        return err
+    }
+    unpinned, err := s.db.UnpinMessage(ctx, message.ID)
+    if err != nil {
+        return err
+    }
+    if unpinned > 0 {
+        s.changed(message, userID, false)
+    }
+    return nil
+}
+
+// pinnable loads a room message the user can see and checks that their role
+// allows pinning in its room.
+func (s *PinService) pinnable(ctx context.Context, userID, messageID uuid.UUID) (database.Message, database.Room, error) {
+    message, err := s.messages.visibleMessage(ctx, userID, messageID)
+    if err != nil {
+        return database.Message{}, database.Room{}, err
+    }
+    if message.RecipientID != nil {
+        return database.Message{}, database.Room{}, ErrInvalidPin
+    }
+    room, err := s.db.GetRoomByID(ctx, message.RoomID)
+    if err != nil {
+        return database.Message{}, database.Room{}, err
+    }
+    if room.ArchivedAt != nil {
+        return database.Message{}, database.Room{}, ErrRoomArchived
+    }
+    role, err := RoomRole(ctx, s.db, room, userID)
+    if err != nil {
+        return database.Message{}, database.Room{}, err
+    }
+    permissions, err := LoadRoomPermissions(ctx, s.db, room)
+    if err != nil {
+        return database.Message{}, database.Room{}, err
+    }
+    if !HasRoomRole(role, permissions.Pin) {
+        return database.Message{}, database.Room{}, ErrPinNotAllowed
+    }
+    return message, room, nil
+}
+
+// changed tells the room's connected members and its pin webhooks that the
+// message was pinned or unpinned.
+func (s *PinService) changed(message database.Message, actorID uuid.UUID, pinned bool) {
+    update := &PinUpdate{MessageID: message.ID.String(), Pinned: pinned, ActorID: actorID.String()}
+    event := EventMessagePinned
+    if !pinned {
+        event = EventMessageUnpinned
+    }
+    s.hub.Broadcast(&Message{
+        Type:      event,
+        ID:        message.ID.String(),
+        SenderID:  actorID.String(),
+        RoomID:    message.RoomID.String(),
+        CreatedAt: time.Now(),
+        Pin:       update,
+    })
+    s.webhooks.Dispatch(message.RoomID, WebhookEventPin, update)
+}
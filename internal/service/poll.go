@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // MessageKindText is an ordinary chat message.
+    MessageKindText = "text"
+    // MessageKindPoll is a message carrying a poll.
+    MessageKindPoll = "poll"
+)
+
+// Event types for server-originated updates to existing messages.
+const (
+    EventPollUpdated = "poll.updated"
+    EventPollClosed  = "poll.closed"
+)
+
+const (
+    minPollOptions = 2
+    maxPollOptions = 10
+)
+
+var (
+    // ErrInvalidPoll is returned when a poll's question or options are invalid.
+    ErrInvalidPoll = errors.New("a poll needs a question and between 2 and 10 non-empty options")
+    // ErrPollClosed is returned when voting on a closed poll.
+    ErrPollClosed = errors.New("poll is closed")
+    // ErrInvalidPollOption is returned when voting for an option that is not part of the poll.
+    ErrInvalidPollOption = errors.New("option does not belong to this poll")
+)
+
+// Poll is the wire representation of a poll with its current results.
+type Poll struct {
+    ID       string       `json:"id"`
+    Question string       `json:"question"`
+    Options  []PollOption `json:"options"`
+    Closed   bool         `json:"closed"`
+    ClosedAt *time.Time   `json:"closed_at,omitempty"`
+}
+
+// PollOption is a poll choice with its vote count.
+type PollOption struct {
+    ID    string `json:"id"`
+    Text  string `json:"text"`
+    Votes int64  `json:"votes"`
+}
+
+// PollService manages polls and broadcasts their results through the Hub.
+type PollService struct {
+    db    *database.Queries
+    pool  *pgxpool.Pool
+    store *MessageStore
+    hub   *Hub
+}
+
+// NewPollService creates a new PollService.
+func NewPollService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore, hub *Hub) *PollService {
+    return &PollService{db: db, pool: pool, store: store, hub: hub}
+}
+
+// CreatePoll posts a poll message to a room and broadcasts it to connected members.
+func (s *PollService) CreatePoll(ctx context.Context, roomID, creatorID uuid.UUID, question string, options []string) (*Message, error) {
+    if question == "" || len(options) < minPollOptions || len(options) > maxPollOptions {
+        return nil, ErrInvalidPoll
+    }
+    for _, option := range options {
+        if option == "" {
+            return nil, ErrInvalidPoll
+        }
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    saved, err := s.store.Create(ctx, qtx, database.CreateMessageParams{
+        ID:       uuid.New(),
+        RoomID:   roomID,
+        SenderID: creatorID,
+        Content:  question,
+        Metadata: []byte("{}"),
+        Kind:     MessageKindPoll,
+        Mentions: []uuid.UUID{},
+        Priority: MessagePriorityNormal,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    poll, err := qtx.CreatePoll(ctx, database.CreatePollParams{
+        ID:        uuid.New(),
+        MessageID: saved.ID,
+        RoomID:    roomID,
+        CreatorID: creatorID,
+        Question:  question,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    for i, option := range options {
+        _, err := qtx.CreatePollOption(ctx, database.CreatePollOptionParams{
+            ID:       uuid.New(),
+            PollID:   poll.ID,
+            Position: int32(i),
+            Text:     option,
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+
+    message := messageFromRow(saved)
+    message.Poll, err = pollSnapshot(ctx, s.db, poll)
+    if err != nil {
+        return nil, err
+    }
+    s.hub.Broadcast(message)
+    return message, nil
+}
+
+// GetPoll returns a poll with its current results.
+func (s *PollService) GetPoll(ctx context.Context, pollID uuid.UUID) (database.Poll, *Poll, error) {
+    poll, err := s.db.GetPollByID(ctx, pollID)
+    if err != nil {
+        return database.Poll{}, nil, err
+    }
+    snapshot, err := pollSnapshot(ctx, s.db, poll)
+    return poll, snapshot, err
+}
+
+// Vote records (or changes) a user's vote and broadcasts the new counts.
+func (s *PollService) Vote(ctx context.Context, poll database.Poll, userID, optionID uuid.UUID) (*Poll, error) {
+    if poll.ClosedAt != nil {
+        return nil, ErrPollClosed
+    }
+
+    err := s.db.UpsertPollVote(ctx, database.UpsertPollVoteParams{
+        PollID:   poll.ID,
+        OptionID: optionID,
+        UserID:   userID,
+    })
+    if err != nil {
+        var pgErr *pgconn.PgError
+        if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+            return nil, ErrInvalidPollOption
+        }
+        return nil, err
+    }
+
+    return s.broadcastResults(ctx, poll, EventPollUpdated)
+}
+
+// ClosePoll closes a poll so no more votes are accepted and broadcasts the final results.
+func (s *PollService) ClosePoll(ctx context.Context, poll database.Poll) (*Poll, error) {
+    if poll.ClosedAt != nil {
+        return nil, ErrPollClosed
+    }
+    closed, err := s.db.ClosePoll(ctx, poll.ID)
+    if err != nil {
+        return nil, err
+    }
+    return s.broadcastResults(ctx, closed, EventPollClosed)
+}
+
+// broadcastResults sends the poll's current results to the room as an update
+// to the original poll message.
+func (s *PollService) broadcastResults(ctx context.Context, poll database.Poll, event string) (*Poll, error) {
+    snapshot, err := pollSnapshot(ctx, s.db, poll)
+    if err != nil {
+        return nil, err
+    }
+    s.hub.Broadcast(&Message{
+        Type:      event,
+        ID:        poll.MessageID.String(),
+        SenderID:  poll.CreatorID.String(),
+        RoomID:    poll.RoomID.String(),
+        Kind:      MessageKindPoll,
+        CreatedAt: time.Now(),
+        Poll:      snapshot,
+    })
+    return snapshot, nil
+}
+
+// pollSnapshot loads the poll's options and vote counts.
+func pollSnapshot(ctx context.Context, db *database.Queries, poll database.Poll) (*Poll, error) {
+    results, err := db.GetPollResults(ctx, poll.ID)
+    if err != nil {
+        return nil, err
+    }
+
+    snapshot := &Poll{
+        ID:       poll.ID.String(),
+        Question: poll.Question,
+        Options:  make([]PollOption, 0, len(results)),
+        Closed:   poll.ClosedAt != nil,
+        ClosedAt: poll.ClosedAt,
+    }
+    for _, result := range results {
+        snapshot.Options = append(snapshot.Options, PollOption{
+            ID:    result.ID.String(),
+            Text:  result.Text,
+            Votes: result.Votes,
+        })
+    }
+    return snapshot, nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Events sent to the rooms a user is a member of when they come online, with
+// their first connection to any room, and when they go offline, with their
+// last. The presence frames sent when someone connects to or leaves a single
+// room are unaffected.
+const (
+    EventPresenceOnline  = "presence.online"
+    EventPresenceOffline = "presence.offline"
+)
+
+// FramePresenceSubscribe is sent by clients to receive presence only for
+// the users they list; EventPresenceSubscribed answers it with their current
+// presence.
+const (
+    FramePresenceSubscribe  = "presence.subscribe"
+    EventPresenceSubscribed = "presence.subscribed"
+)
+
+// MaxPresenceSubscriptions is how many users a connection can subscribe to
+// the presence of.
+const MaxPresenceSubscriptions = 500
+
+// EventPresenceSnapshot is sent to a client as it connects to a room, listing
+// who is connected to the room, the client's own user included. The presence
+// frames sent as members connect and leave keep the list up to date.
+const EventPresenceSnapshot = "presence.snapshot"
+
+// PresenceSnapshot lists the users connected to a room.
+type PresenceSnapshot struct {
+    RoomID string   `json:"room_id"`
+    Online []string `json:"online"`
+}
+
+// PresenceAwayAfter is how long a connected user can go without sending a
+// message, typing or read frame on any connection before they are away.
+const PresenceAwayAfter = 5 * time.Minute
+
+// PresenceSubscription is the payload of presence.subscribe frames, listing
+// the users to receive presence for, and of the presence.subscribed frames
+// answering them, listing those subscribed to: the users sharing a room with
+// the client's user, the user included.
+type PresenceSubscription struct {
+    UserIDs []string `json:"user_ids"`
+    // Presence is the current presence of each user subscribed to, as
+    // Hub.PresenceOf reports it; presence.subscribed frames only.
+    Presence []Presence `json:"presence,omitempty"`
+}
+
+// subscriptionRequest asks the hub to replace a client's presence
+// subscription, answering frameID.
+type subscriptionRequest struct {
+    client  *Client
+    userIDs []string
+    frameID string
+}
+
+// presenceRequest asks the hub whether a user has any connection.
+type presenceRequest struct {
+    userID string
+    reply  chan bool
+}
+
+// IsOnline reports whether the user has a connection to this server, in any
+// room.
+func (h *Hub) IsOnline(userID string) bool {
+    req := presenceRequest{userID: userID, reply: make(chan bool, 1)}
+    h.presenceQueries <- req
+    return <-req.reply
+}
+
+// presenceStatusRequest asks the hub for the presence of several users.
+type presenceStatusRequest struct {
+    userIDs []string
+    reply   chan []Presence
+}
+
+// PresenceOf reports whether each of the users is online, away or offline on
+// this server, in the order given.
+func (h *Hub) PresenceOf(userIDs []string) []Presence {
+    req := presenceStatusRequest{userIDs: userIDs, reply: make(chan []Presence, 1)}
+    h.presenceStatuses <- req
+    return <-req.reply
+}
+
+// presenceOf reports the users' presence from the latest activity of their
+// connections. It runs on the hub goroutine.
+func (h *Hub) presenceOf(userIDs []string) []Presence {
+    lastActive := make(map[string]int64, len(userIDs))
+    for _, userID := range userIDs {
+        if h.presence[userID] > 0 {
+            lastActive[userID] = 0
+        }
+    }
+    if len(lastActive) > 0 {
+        for _, clients := range h.clients {
+            for userID := range lastActive {
+                for client := range clients[userID] {
+                    lastActive[userID] = max(lastActive[userID], client.lastActive.Load())
+                }
+            }
+        }
+    }
+
+    awaySince := time.Now().Add(-PresenceAwayAfter).UnixNano()
+    presence := make([]Presence, len(userIDs))
+    for i, userID := range userIDs {
+        status := PresenceOffline
+        if latest, ok := lastActive[userID]; ok {
+            status = PresenceOnline
+            if latest < awaySince {
+                status = PresenceAway
+            }
+        }
+        presence[i] = Presence{UserID: userID, Status: status}
+    }
+    return presence
+}
+
+// trackPresence counts a connection of the user opening (delta 1) or closing
+// (delta -1), announcing the user to their rooms when their first connection
+// opens or their last closes. It runs on the hub goroutine.
+func (h *Hub) trackPresence(userID string, delta int) {
+    h.presence[userID] += delta
+    switch {
+    case h.presence[userID] <= 0:
+        delete(h.presence, userID)
+        go h.announcePresence(userID)
+    case h.presence[userID] == 1 && delta > 0:
+        go h.announcePresence(userID)
+    }
+}
+
+// announcePresence sends the user's presence to the connected members of
+// every room they are a member of. The status is read once the rooms are
+// loaded, so when a user comes and goes quickly the last announcement is
+// always the current status.
+func (h *Hub) announcePresence(userID string) {
+    id, err := uuid.Parse(userID)
+    if err != nil {
+        return
+    }
+    roomIDs, err := h.messages.db.GetUserRoomIDs(context.Background(), id)
+    if err != nil {
+        log.Printf("failed to load rooms of %s for presence: %v", userID, err)
+        return
+    }
+
+    event, status := EventPresenceOffline, PresenceOffline
+    if h.IsOnline(userID) {
+        event, status = EventPresenceOnline, PresenceOnline
+    }
+    now := time.Now()
+    for _, roomID := range roomIDs {
+        h.Broadcast(&Message{
+            Type:      event,
+            SenderID:  userID,
+            RoomID:    roomID.String(),
+            CreatedAt: now,
+            Presence:  &Presence{UserID: userID, Status: status},
+        })
+    }
+    h.Broadcast(&Message{
+        Type:          event,
+        SenderID:      userID,
+        CreatedAt:     now,
+        Presence:      &Presence{UserID: userID, Status: status},
+        toSubscribers: true,
+    })
+}
+
+// sendPresenceSnapshot sends a newly registered client the users connected to
+// its room, and those restored from the hub snapshot. It runs on the hub
+// goroutine, so no presence frame can slip in between the snapshot and the
+// live updates.
+func (h *Hub) sendPresenceSnapshot(client *Client) {
+    online := make([]string, 0, len(h.clients[client.roomID])+len(h.restored[client.roomID]))
+    for userID := range h.clients[client.roomID] {
+        online = append(online, userID)
+    }
+    // Users connected before a restart are expected back shortly.
+    for userID := range h.restored[client.roomID] {
+        online = append(online, userID)
+    }
+    sort.Strings(online)
+    h.send(client, &Message{
+        Type:             EventPresenceSnapshot,
+        SenderID:         client.userID,
+        RecipientID:      client.userID,
+        RoomID:           client.roomID,
+        CreatedAt:        time.Now(),
+        PresenceSnapshot: &PresenceSnapshot{RoomID: client.roomID, Online: online},
+    })
+}
+
+// handlePresenceSubscribe replaces the connection's presence subscription
+// with the users in the frame it shares a room with. An empty list
+// subscribes it to its room's presence again.
+func (c *Client) handlePresenceSubscribe(env Envelope) {
+    var subscription PresenceSubscription
+    if err := json.Unmarshal(env.Payload, &subscription); err != nil {
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "presence.subscribe payload is not valid JSON"})
+        return
+    }
+    if len(subscription.UserIDs) > MaxPresenceSubscriptions {
+        c.reject(env.ID, &ErrorFrame{
+            Code:   ErrorCodeInvalidMessage,
+            Reason: fmt.Sprintf("at most %d users can be subscribed to", MaxPresenceSubscriptions),
+        })
+        return
+    }
+    userIDs := make([]uuid.UUID, 0, len(subscription.UserIDs))
+    for _, raw := range subscription.UserIDs {
+        id, err := uuid.Parse(raw)
+        if err != nil {
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "user_ids must be user IDs"})
+            return
+        }
+        userIDs = append(userIDs, id)
+    }
+    self, err := uuid.Parse(c.userID)
+    if err != nil {
+        return
+    }
+
+    // Presence is only shared between users who have a room in common.
+    mates, err := c.hub.messages.db.GetRoomMates(context.Background(), database.GetRoomMatesParams{UserID: self, UserIds: userIDs})
+    if err != nil {
+        log.Printf("failed to load room mates of %s: %v", c.userID, err)
+        return
+    }
+    visible := map[uuid.UUID]bool{self: true}
+    for _, id := range mates {
+        visible[id] = true
+    }
+    subscribed := make([]string, 0, len(userIDs))
+    seen := make(map[uuid.UUID]bool, len(userIDs))
+    for _, id := range userIDs {
+        if visible[id] && !seen[id] {
+            seen[id] = true
+            subscribed = append(subscribed, id.String())
+        }
+    }
+    c.hub.subscribe <- subscriptionRequest{client: c, userIDs: subscribed, frameID: env.ID}
+}
+
+// setSubscription replaces a client's presence subscription and answers it
+// with the current presence of the users subscribed to. It runs on the hub
+// goroutine, so no presence frame can slip in between the answer and the
+// live updates.
+func (h *Hub) setSubscription(req subscriptionRequest) {
+    client := req.client
+    if !h.clients[client.roomID][client.userID][client] {
+        return
+    }
+    h.unsubscribe(client)
+    if len(req.userIDs) > 0 {
+        client.subscriptions = make(map[string]bool, len(req.userIDs))
+        for _, userID := range req.userIDs {
+            client.subscriptions[userID] = true
+            if h.subscribers[userID] == nil {
+                h.subscribers[userID] = make(clientSet)
+            }
+            h.subscribers[userID][client] = true
+        }
+    }
+    h.send(client, &Message{
+        Type:        EventPresenceSubscribed,
+        SenderID:    client.userID,
+        RecipientID: client.userID,
+        RoomID:      client.roomID,
+        CreatedAt:   time.Now(),
+        FrameID:     req.frameID,
+        PresenceSubscription: &PresenceSubscription{
+            UserIDs:  req.userIDs,
+            Presence: h.presenceOf(req.userIDs),
+        },
+    })
+}
+
+// unsubscribe drops a client's presence subscription, if any. It runs on the
+// hub goroutine.
+func (h *Hub) unsubscribe(client *Client) {
+    for userID := range client.subscriptions {
+        delete(h.subscribers[userID], client)
+        if len(h.subscribers[userID]) == 0 {
+            delete(h.subscribers, userID)
+        }
+    }
+    client.subscriptions = nil
+}
+
+// fanOutPresence sends a presence event about its sender to the clients in
+// its room, except those of skipUserID. Clients with a presence subscription
+// only get room presence frames about the users they subscribed to, and get
+// the rest through sendToSubscribers instead.
+func (h *Hub) fanOutPresence(message *Message, skipUserID string) {
+    for userID, devices := range h.clients[message.RoomID] {
+        if userID == skipUserID {
+            continue
+        }
+        for client := range devices {
+            if client.subscriptions == nil || message.Type == EventPresence && client.subscriptions[message.SenderID] {
+                h.send(client, message)
+            }
+        }
+    }
+}
+
+// sendToSubscribers sends a presence event about its sender to the clients
+// subscribed to them, whichever room they are connected to.
+func (h *Hub) sendToSubscribers(message *Message) {
+    for client := range h.subscribers[message.SenderID] {
+        h.send(client, message)
+    }
+}
+
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Message priorities.
+const (
+    MessagePriorityNormal = "normal"
+    MessagePriorityUrgent = "urgent"
+)
+
+// DefaultUrgentDailyLimit is how many urgent messages a user may send per
+// day when no limit is configured.
+const DefaultUrgentDailyLimit = 3
+
+// Error codes sent in error frames for rejected urgent messages.
+const (
+    ErrorCodeUrgentNotAllowed = "urgent_not_allowed"
+    ErrorCodeUrgentLimit      = "urgent_limit_reached"
+)
+
+var (
+    // ErrInvalidPriority is returned for priorities other than normal and urgent.
+    ErrInvalidPriority = errors.New("priority must be normal or urgent")
+    // ErrUrgentNotAllowed is returned when the sender may not send urgent
+    // messages in the room.
+    ErrUrgentNotAllowed = errors.New("urgent messages are not allowed in this room")
+    // ErrUrgentLimitReached is returned when the sender has used up their
+    // urgent messages for the day.
+    ErrUrgentLimitReached = errors.New("daily urgent message limit reached")
+)
+
+// resolvePriority validates the priority of a new message. Urgent messages
+// can be sent by the room owner, and by members of rooms that allow them, up
+// to the daily limit per user.
+func (s *MessageService) resolvePriority(ctx context.Context, priority string, roomID, senderID uuid.UUID) (string, error) {
+    switch priority {
+    case "", MessagePriorityNormal:
+        return MessagePriorityNormal, nil
+    case MessagePriorityUrgent:
+    default:
+        return "", ErrInvalidPriority
+    }
+
+    room, err := s.db.GetRoomByID(ctx, roomID)
+    if err != nil {
+        return "", err
+    }
+    if !room.AllowUrgent {
+        owner, err := IsRoomOwner(ctx, s.db, room, senderID)
+        if err != nil {
+            return "", err
+        }
+        if !owner {
+            return "", ErrUrgentNotAllowed
+        }
+    }
+
+    limit := s.opts.UrgentDailyLimit
+    if limit <= 0 {
+        limit = DefaultUrgentDailyLimit
+    }
+    sent, err := s.db.CountUrgentMessagesSince(ctx, database.CountUrgentMessagesSinceParams{
+        SenderID:  senderID,
+        CreatedAt: time.Now().Add(-24 * time.Hour),
+    })
+    if err != nil {
+        return "", err
+    }
+    if sent >= int64(limit) {
+        return "", ErrUrgentLimitReached
+    }
+    return MessagePriorityUrgent, nil
+}
+
+// urgentPush builds the push notification for an urgent message. It is
+// flagged so providers deliver it with high priority.
+func (h *Hub) urgentPush(message *Message) PushNotification {
+    notification := h.pushNotification(message, h.pushTemplates.UrgentTitle)
+    notification.Data["priority"] = MessagePriorityUrgent
+    notification.Urgent = true
+    return notification
+}
+
+// escalate pushes an urgent room message to every member of the room who is
+// not connected to it, not only to those mentioned, unless they muted the
+// room.
+func (h *Hub) escalate(message *Message, online map[string]bool) {
+    roomID, err := uuid.Parse(message.RoomID)
+    if err != nil {
+        return
+    }
+    members, err := h.messages.db.GetRoomMembers(context.Background(), roomID)
+    if err != nil {
+        log.Printf("failed to load members of room %s: %v", message.RoomID, err)
+        return
+    }
+    levels, err := roomNotificationLevels(context.Background(), h.messages.db, roomID)
+    if err != nil {
+        log.Printf("failed to load notification levels of room %s: %v", message.RoomID, err)
+        return
+    }
+    notification := h.urgentPush(message)
+    for _, member := range members {
+        userID := member.ID.String()
+        if online[userID] || userID == message.SenderID || levels[userID] == NotificationLevelNone {
+            continue
+        }
+        if err := h.push.Push(context.Background(), userID, notification); err != nil {
+            log.Printf("failed to push urgent message to %s: %v", userID, err)
+        }
+    }
+}
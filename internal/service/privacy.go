@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// maxCachedTypingSettings bounds how many users' typing indicator settings
+// the hub remembers before starting over.
+const maxCachedTypingSettings = 10000
+
+// PrivacyPreferences is what the user chose to share with others.
+type PrivacyPreferences struct {
+    // TypingIndicators reports whether others see when the user is typing.
+    // Turning it off does not hide others' typing from the user. Read
+    // positions are never shared with other users, so there are no read
+    // receipts to turn off.
+    TypingIndicators bool `json:"typing_indicators" example:"true"`
+}
+
+// PrivacyService manages users' privacy preferences. They are enforced by the
+// server, so they hold whatever clients the user connects with.
+type PrivacyService struct {
+    db  *database.Queries
+    hub *Hub
+}
+
+// NewPrivacyService creates a new PrivacyService.
+func NewPrivacyService(db *database.Queries, hub *Hub) *PrivacyService {
+    return &PrivacyService{db: db, hub: hub}
+}
+
+// Preferences returns the user's privacy preferences, with defaults applied.
+func (s *PrivacyService) Preferences(ctx context.Context, userID uuid.UUID) (*PrivacyPreferences, error) {
+    typing, err := typingIndicators(ctx, s.db, userID)
+    if err != nil {
+        return nil, err
+    }
+    return &PrivacyPreferences{TypingIndicators: typing}, nil
+}
+
+// SetTypingIndicators sets whether others see when the user is typing. It
+// applies right away to the user's open connections.
+func (s *PrivacyService) SetTypingIndicators(ctx context.Context, userID uuid.UUID, enabled bool) (*PrivacyPreferences, error) {
+    err := s.db.SetUserTypingIndicators(ctx, database.SetUserTypingIndicatorsParams{UserID: userID, TypingIndicators: enabled})
+    if err != nil {
+        return nil, err
+    }
+    s.hub.typingSettings.set(userID.String(), enabled)
+    return &PrivacyPreferences{TypingIndicators: enabled}, nil
+}
+
+// typingIndicators returns whether the user shares typing indicators; users
+// who never chose do.
+func typingIndicators(ctx context.Context, db *database.Queries, userID uuid.UUID) (bool, error) {
+    enabled, err := db.GetUserTypingIndicators(ctx, userID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return true, nil
+    }
+    return enabled, err
+}
+
+// sharesTyping reports whether the user's typing notifications may be relayed
+// to others, loading the setting on first use. If it cannot be loaded the
+// notification is withheld: a missing indicator is harmless, a leaked one is
+// not.
+func (h *Hub) sharesTyping(userID string) bool {
+    if enabled, ok := h.typingSettings.get(userID); ok {
+        return enabled
+    }
+    id, err := uuid.Parse(userID)
+    if err != nil {
+        return false
+    }
+    enabled, err := typingIndicators(context.Background(), h.messages.db, id)
+    if err != nil {
+        log.Printf("failed to load typing indicator setting of %s: %v", userID, err)
+        return false
+    }
+    return h.typingSettings.load(userID, enabled)
+}
+
+// typingSettingsCache remembers whether users share typing indicators, so
+// typing notifications do not each cost a query.
+type typingSettingsCache struct {
+    mu      sync.Mutex
+    entries map[string]bool
+}
+
+func newTypingSettingsCache() *typingSettingsCache {
+    return &typingSettingsCache{entries: make(map[string]bool)}
+}
+
+func (c *typingSettingsCache) get(userID string) (bool, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    enabled, ok := c.entries[userID]
+    return enabled, ok
+}
+
+// set records a setting the user just changed.
+func (c *typingSettingsCache) set(userID string, enabled bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if len(c.entries) >= maxCachedTypingSettings {
+        c.entries = make(map[string]bool)
+    }
+    c.entries[userID] = enabled
+}
+
+// load records a setting read from the database and returns the cached one.
+// A change made while it was being read wins over it.
+func (c *typingSettingsCache) load(userID string, enabled bool) bool {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if cached, ok := c.entries[userID]; ok {
+        return cached
+    }
+    if len(c.entries) >= maxCachedTypingSettings {
+        c.entries = make(map[string]bool)
+    }
+    c.entries[userID] = enabled
+    return enabled
+}
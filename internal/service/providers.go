@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mailer sends transactional email.
+type Mailer interface {
+    SendMail(ctx context.Context, to, subject, body string) error
+}
+
+// PushNotification is the provider-agnostic payload of a push notification.
+type PushNotification struct {
+    Title string
+    Body  string
+    Data  map[string]string
+    // Urgent asks the provider to deliver the notification with high
+    // priority, as for urgent messages.
+    Urgent bool
+}
+
+// PushSender delivers push notifications to a user's devices.
+type PushSender interface {
+    Push(ctx context.Context, userID string, notification PushNotification) error
+}
+
+// Storage stores binary objects such as avatars and attachments.
+type Storage interface {
+    // Put stores the object under key and returns a URL clients can fetch it from.
+    Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+    Delete(ctx context.Context, key string) error
+}
+
+// Providers groups the external providers used by the services.
+type Providers struct {
+    Mailer  Mailer
+    Push    PushSender
+    Storage Storage
+    // Translator is optional; messages are delivered untranslated when nil.
+    Translator Translator
+    // Summarizer is optional; catch-up summaries are disabled when nil.
+    Summarizer Summarizer
+}
+
+// LogMailer is a Mailer that only logs outgoing mail. Useful for development.
+type LogMailer struct{}
+
+// SendMail logs the mail instead of sending it.
+func (LogMailer) SendMail(ctx context.Context, to, subject, body string) error {
+    log.Printf("mail to %s: %s", to, subject)
+    return nil
+}
+
+// LogPushSender is a PushSender that only logs notifications.
+type LogPushSender struct{}
+
+// Push logs the notification instead of delivering it.
+func (LogPushSender) Push(ctx context.Context, userID string, notification PushNotification) error {
+    log.Printf("push to %s: %s", userID, notification.Title)
+    return nil
+}
+
+// LocalStorage stores objects on the local filesystem.
+type LocalStorage struct {
+    dir     string
+    baseURL string
+}
+
+// NewLocalStorage creates a LocalStorage rooted at dir, serving objects under baseURL.
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return nil, err
+    }
+    return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Put writes the object to disk.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+    path, err := s.path(key)
+    if err != nil {
+        return "", err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return "", err
+    }
+
+    f, err := os.Create(path)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+
+    if _, err := io.Copy(f, r); err != nil {
+        return "", err
+    }
+    return s.baseURL + "/" + key, nil
+}
+
+// Delete removes the object from disk. Missing objects are not an error.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+    path, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    return nil
+}
+
+// path resolves key inside the storage directory, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+    path := filepath.Join(s.dir, filepath.FromSlash(key))
+    if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+        return "", fmt.Errorf("invalid storage key %q", key)
+    }
+    return path, nil
+}
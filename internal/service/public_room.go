@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // PublicMessagesLimit is how many of a public-readable room's latest
+    // messages are served.
+    PublicMessagesLimit = 50
+    // PublicRoomCacheTTL is how long a public-readable room's messages are
+    // served from memory before they are loaded again.
+    PublicRoomCacheTTL = 30 * time.Second
+    // maxCachedPublicRooms bounds the public room cache; it is reset when full.
+    maxCachedPublicRooms = 1000
+)
+
+// ErrRoomNotPublic is returned for rooms that do not exist or are not
+// public-readable; the two are not told apart.
+var ErrRoomNotPublic = errors.New("room not found")
+
+// PublicMessage is a room message as shown to anyone. It leaves out metadata,
+// mentions and everything else that is only meant for members.
+type PublicMessage struct {
+    ID        string         `json:"id"`
+    Seq       int64          `json:"seq"`
+    Sender    *SenderProfile `json:"sender"`
+    Content   string         `json:"content"`
+    Kind      string         `json:"kind"`
+    CreatedAt time.Time      `json:"created_at"`
+    EditedAt  *time.Time     `json:"edited_at,omitempty"`
+}
+
+// PublicRoomLog is what anyone can see of a public-readable room: its name and
+// topic, and its latest messages, oldest first.
+type PublicRoomLog struct {
+    RoomID   string          `json:"room_id"`
+    Name     string          `json:"name"`
+    Topic    string          `json:"topic"`
+    Messages []PublicMessage `json:"messages"`
+    // GeneratedAt is when the messages were loaded; they may be up to 30
+    // seconds old.
+    GeneratedAt time.Time `json:"generated_at"`
+}
+
+// publicRoomEntry is a cached PublicRoomLog, or a nil one for a room that is
+// not public-readable.
+type publicRoomEntry struct {
+    log       *PublicRoomLog
+    expiresAt time.Time
+}
+
+// PublicRoomService serves the latest messages of public-readable rooms
+// without authentication. Each room's messages are loaded at most once every
+// PublicRoomCacheTTL, however many readers there are, and so are lookups of
+// rooms that are not public-readable.
+type PublicRoomService struct {
+    db      *database.Queries
+    names   DisplayNamePolicy
+    mu      sync.Mutex
+    entries map[uuid.UUID]publicRoomEntry
+}
+
+// NewPublicRoomService creates a new PublicRoomService that names senders
+// according to names.
+func NewPublicRoomService(db *database.Queries, names DisplayNamePolicy) *PublicRoomService {
+    return &PublicRoomService{db: db, names: names, entries: make(map[uuid.UUID]publicRoomEntry)}
+}
+
+// RecentMessages returns the public-readable room's latest messages. Direct
+// messages are never included.
+func (s *PublicRoomService) RecentMessages(ctx context.Context, roomID uuid.UUID) (*PublicRoomLog, error) {
+    now := time.Now()
+    s.mu.Lock()
+    entry, ok := s.entries[roomID]
+    s.mu.Unlock()
+    if !ok || now.After(entry.expiresAt) {
+        roomLog, err := s.load(ctx, roomID, now)
+        if err != nil && !errors.Is(err, ErrRoomNotPublic) {
+            return nil, err
+        }
+        entry = publicRoomEntry{log: roomLog, expiresAt: now.Add(PublicRoomCacheTTL)}
+        s.mu.Lock()
+        if len(s.entries) >= maxCachedPublicRooms {
+            s.entries = make(map[uuid.UUID]publicRoomEntry)
+        }
+        s.entries[roomID] = entry
+        s.mu.Unlock()
+    }
+    if entry.log == nil {
+        return nil, ErrRoomNotPublic
+    }
+    return entry.log, nil
+}
+
+// load reads the room and its latest messages from the database.
+func (s *PublicRoomService) load(ctx context.Context, roomID uuid.UUID, now time.Time) (*PublicRoomLog, error) {
+    room, err := s.db.GetRoomByID(ctx, roomID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrRoomNotPublic
+    }
+    if err != nil {
+        return nil, err
+    }
+    if !room.PublicReadable || room.Visibility != RoomVisibilityPublic || room.Kind != RoomKindRoom {
+        return nil, ErrRoomNotPublic
+    }
+
+    // No user has the nil ID, so only messages sent to the whole room match.
+    latest, err := s.db.GetLatestRoomMessages(ctx, database.GetLatestRoomMessagesParams{
+        RoomID:      roomID,
+        UserID:      uuid.Nil,
+        MaxMessages: PublicMessagesLimit,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    // The query returns newest first; logs are presented oldest first.
+    messages := make([]PublicMessage, len(latest))
+    for i, row := range latest {
+        messages[len(latest)-1-i] = PublicMessage{
+            ID:        row.Message.ID.String(),
+            Seq:       row.Message.Seq,
+            Sender:    s.names.Profile(row.SenderUsername, row.SenderDisplayName, row.SenderAvatarUrl),
+            Content:   row.Message.Content,
+            Kind:      row.Message.Kind,
+            CreatedAt: row.Message.CreatedAt,
+            EditedAt:  row.Message.EditedAt,
+        }
+    }
+    return &PublicRoomLog{
+        RoomID:      room.ID.String(),
+        Name:        room.Name,
+        Topic:       room.Topic,
+        Messages:    messages,
+        GeneratedAt: now,
+    }, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventMessagesPurged is the type of the event announcing a finished purge.
+// Clients drop the messages it covers instead of receiving one deletion per
+// message.
+const EventMessagesPurged = "messages.purged"
+
+// JobTypeMessagePurge is the type of the jobs that run message purges.
+const JobTypeMessagePurge = "message.purge"
+
+// Purge statuses. Purges wait as pending until their job starts, are running
+// until every message they cover is deleted, and end up succeeded. A purge
+// whose batch failed stays running, with the error, until its job is retried.
+const (
+    MessagePurgePending   = "pending"
+    MessagePurgeRunning   = "running"
+    MessagePurgeSucceeded = "succeeded"
+)
+
+// messagePurgeBatchSize is how many messages a purge deletes per transaction.
+const messagePurgeBatchSize = 500
+
+// ErrPurgeNotFound is returned for unknown purges, and for purges of another
+// room.
+var ErrPurgeNotFound = errors.New("purge not found")
+
+// MessagePurge is a deletion of a room's messages by sender and/or time range,
+// run in the background. It covers the messages sent by UserID, when set,
+// from From, when set, until To, which is never later than when the purge was
+// requested, so messages sent since are kept.
+type MessagePurge struct {
+    ID          string     `json:"id"`
+    RoomID      string     `json:"room_id"`
+    RequestedBy string     `json:"requested_by"`
+    UserID      *string    `json:"user_id,omitempty"`
+    From        *time.Time `json:"from,omitempty"`
+    To          time.Time  `json:"to"`
+    Status      string     `json:"status" example:"running"`
+    // Total is how many messages the purge covered when it was requested,
+    // and Deleted how many it has deleted so far.
+    Total   int64 `json:"total" example:"12000"`
+    Deleted int64 `json:"deleted" example:"4500"`
+    // LastError is why the latest batch failed; the purge is retried.
+    LastError  *string    `json:"last_error,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    UpdatedAt  time.Time  `json:"updated_at"`
+    FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// messagePurgeJob is the payload of message purge jobs.
+type messagePurgeJob struct {
+    PurgeID uuid.UUID `json:"purge_id"`
+}
+
+// PurgeMessages queues the deletion of the room's messages sent by the user
+// and/or within the time range, records it in the audit log and returns it
+// pending. It runs in batches, recording its progress, and announces the
+// messages it deleted to the room in a single messages.purged event once it
+// finishes. Callers are responsible for checking that actorID may do so.
+func (s *ModerationService) PurgeMessages(ctx context.Context, roomID, actorID uuid.UUID, req BulkDelete) (*MessagePurge, error) {
+    if len(req.IDs) > 0 || (req.UserID == nil && req.From == nil && req.To == nil) {
+        return nil, ErrInvalidBulkDelete
+    }
+    end := time.Now()
+    if req.To != nil && req.To.Before(end) {
+        end = *req.To
+    }
+    if req.From != nil && !req.From.Before(end) {
+        return nil, ErrInvalidBulkDelete
+    }
+
+    total, err := s.db.CountPurgeableMessages(ctx, database.CountPurgeableMessagesParams{
+        RoomID:    roomID,
+        SenderID:  req.UserID,
+        StartTime: req.From,
+        EndTime:   end,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    row, err := qtx.CreateMessagePurge(ctx, database.CreateMessagePurgeParams{
+        ID:          uuid.New(),
+        RoomID:      roomID,
+        RequestedBy: actorID,
+        UserID:      req.UserID,
+        StartTime:   req.From,
+        EndTime:     end,
+        Total:       total,
+    })
+    if err != nil {
+        return nil, err
+    }
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionMessagesBulkDelete,
+        RoomID:  &roomID,
+        Details: map[string]any{"purge_id": row.ID, "user_id": req.UserID, "from": req.From, "to": end, "matched": total},
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := s.jobs.EnqueueTx(ctx, qtx, JobTypeMessagePurge, messagePurgeJob{PurgeID: row.ID}); err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    return messagePurgeFromRow(row), nil
+}
+
+// Purge returns one of the room's purges, with its progress.
+func (s *ModerationService) Purge(ctx context.Context, roomID, purgeID uuid.UUID) (*MessagePurge, error) {
+    row, err := s.db.GetMessagePurge(ctx, database.GetMessagePurgeParams{ID: purgeID, RoomID: roomID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrPurgeNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return messagePurgeFromRow(row), nil
+}
+
+// runPurge runs a message purge job, picking up where an earlier attempt
+// left off. Purges that finished, or went with their room or moderator, are
+// dropped.
+func (s *ModerationService) runPurge(ctx context.Context, payload []byte) error {
+    var job messagePurgeJob
+    if err := json.Unmarshal(payload, &job); err != nil {
+        return fmt.Errorf("decode message purge: %w", err)
+    }
+    row, err := s.db.StartMessagePurge(ctx, job.PurgeID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    if err := s.purge(ctx, row); err != nil {
+        message := err.Error()
+        if err := s.db.SetMessagePurgeError(context.WithoutCancel(ctx), database.SetMessagePurgeErrorParams{ID: row.ID, LastError: &message}); err != nil {
+            log.Printf("failed to record failure of purge %s: %v", row.ID, err)
+        }
+        return err
+    }
+    return nil
+}
+
+// purge deletes the purge's messages batch by batch, then marks it finished
+// and announces it to the room.
+func (s *ModerationService) purge(ctx context.Context, row database.MessagePurge) error {
+    params := database.GetPurgeableMessageIDsParams{
+        RoomID:      row.RoomID,
+        SenderID:    row.UserID,
+        StartTime:   row.StartTime,
+        EndTime:     row.EndTime,
+        MaxMessages: messagePurgeBatchSize,
+    }
+    for {
+        ids, err := s.db.GetPurgeableMessageIDs(ctx, params)
+        if err != nil {
+            return err
+        }
+        if len(ids) == 0 {
+            break
+        }
+        if err := s.purgeBatch(ctx, row, ids); err != nil {
+            return err
+        }
+    }
+
+    row, err := s.db.FinishMessagePurge(ctx, row.ID)
+    if err != nil {
+        return err
+    }
+    if row.Deleted > 0 {
+        s.hub.Broadcast(&Message{
+            Type:      EventMessagesPurged,
+            SenderID:  row.RequestedBy.String(),
+            RoomID:    row.RoomID.String(),
+            CreatedAt: time.Now(),
+            Purge:     messagePurgeFromRow(row),
+        })
+    }
+    return nil
+}
+
+// purgeBatch deletes a batch of the purge's messages and counts them towards
+// its progress, in one transaction.
+func (s *ModerationService) purgeBatch(ctx context.Context, row database.MessagePurge, ids []uuid.UUID) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    deleted, err := s.store.Delete(ctx, qtx, row.RoomID, row.RequestedBy, ids, nil, nil)
+    if err != nil {
+        return err
+    }
+    err = qtx.AddMessagePurgeProgress(ctx, database.AddMessagePurgeProgressParams{Deleted: int64(len(deleted)), ID: row.ID})
+    if err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+func messagePurgeFromRow(row database.MessagePurge) *MessagePurge {
+    purge := &MessagePurge{
+        ID:          row.ID.String(),
+        RoomID:      row.RoomID.String(),
+        RequestedBy: row.RequestedBy.String(),
+        From:        row.StartTime,
+        To:          row.EndTime,
+        Status:      row.Status,
+        Total:       row.Total,
+        Deleted:     row.Deleted,
+        LastError:   row.LastError,
+        CreatedAt:   row.CreatedAt,
+        UpdatedAt:   row.UpdatedAt,
+        FinishedAt:  row.FinishedAt,
+    }
+    if row.UserID != nil {
+        userID := row.UserID.String()
+        purge.UserID = &userID
+    }
+    return purge
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PushTemplates sets the title and body of the push notifications sent for
+// messages. Each may use {sender}, {room} and {preview}, which expand to the
+// sender's username, the room's name and the message's content.
+type PushTemplates struct {
+    // MessageTitle, MentionTitle and UrgentTitle are the titles of
+    // notifications for messages, mentions and urgent messages. Messages are
+    // direct ones, and room messages for members notified of all of them.
+    MessageTitle string
+    MentionTitle string
+    UrgentTitle  string
+    Body         string
+    // Preview lets notifications show message content. When it is false,
+    // {preview} expands to nothing, so nothing a message says reaches the
+    // push provider.
+    Preview bool
+}
+
+// DefaultPushTemplates returns the templates used unless the deployment
+// configures its own.
+func DefaultPushTemplates() PushTemplates {
+    return PushTemplates{
+        MessageTitle: "New message",
+        MentionTitle: "You were mentioned",
+        UrgentTitle:  "Urgent message",
+        Body:         "{preview}",
+        Preview:      true,
+    }
+}
+
+// pushNotification builds the push notification for a message from the
+// title template and the body template. The sender and room are only looked
+// up when a template names them.
+func (h *Hub) pushNotification(message *Message, title string) PushNotification {
+    vars := []string{"{sender}", "", "{room}", "", "{preview}", ""}
+    templates := title + h.pushTemplates.Body
+    if strings.Contains(templates, "{sender}") {
+        if senderID, err := uuid.Parse(message.SenderID); err == nil {
+            if sender, err := h.messages.db.GetUserByID(context.Background(), senderID); err == nil {
+                vars[1] = sender.Username
+            }
+        }
+    }
+    if strings.Contains(templates, "{room}") {
+        if roomID, err := uuid.Parse(message.RoomID); err == nil {
+            if room, err := h.messages.db.GetRoomByID(context.Background(), roomID); err == nil {
+                vars[3] = room.Name
+            }
+        }
+    }
+    // Ciphertext is no use as a preview.
+    if h.pushTemplates.Preview && message.Kind != MessageKindEncrypted {
+        vars[5] = message.Content
+    }
+    replacer := strings.NewReplacer(vars...)
+    return PushNotification{
+        Title: strings.TrimSpace(replacer.Replace(title)),
+        Body:  strings.TrimSpace(replacer.Replace(h.pushTemplates.Body)),
+        Data: map[string]string{
+            "room_id":    message.RoomID,
+            "message_id": message.ID,
+        },
+    }
+}
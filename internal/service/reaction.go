@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventReactionsUpdated is the type of the event carrying a message's new
+// reaction counts.
+const EventReactionsUpdated = "reactions.updated"
+
+const (
+    // MaxReactionEmojis is how many different emoji a message can be reacted
+    // with.
+    MaxReactionEmojis = 20
+    // maxReactionLength is the longest a reaction can be, in bytes; enough
+    // for emoji sequences such as flags and families.
+    maxReactionLength = 32
+)
+
+const (
+    // reactionBurst is how many reaction updates a room gets one by one
+    // within reactionWindow. Past that the room counts as busy: each changed
+    // message's counts are sent at most once per window until a window
+    // passes without reactions.
+    reactionBurst  = 10
+    reactionWindow = time.Second
+)
+
+var (
+    // ErrInvalidReaction is returned for reactions that are not a single
+    // emoji or a known shortcode.
+    ErrInvalidReaction = errors.New("a reaction must be a single emoji or a known :shortcode:")
+    // ErrTooManyReactions is returned when reacting to a message with a new
+    // emoji once it has MaxReactionEmojis different ones.
+    ErrTooManyReactions = errors.New("messages can have at most 20 different reactions")
+)
+
+// Reaction is how many users reacted to a message with an emoji.
+type Reaction struct {
+    Emoji string `json:"emoji" example:"👍"`
+    Count int64  `json:"count" example:"3"`
+}
+
+// MessageReactions is a message's reactions as seen by one user.
+type MessageReactions struct {
+    MessageID string     `json:"message_id"`
+    Reactions []Reaction `json:"reactions"`
+    // Mine lists the emoji the current user reacted with.
+    Mine []string `json:"mine"`
+}
+
+// ReactionService manages message reactions. Reacting is idempotent: each
+// user reacts to a message with each emoji at most once, and repeating an
+// add or a remove changes nothing and sends no update.
+type ReactionService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+    batches  *reactionBatcher
+}
+
+// NewReactionService creates a new ReactionService.
+func NewReactionService(db *database.Queries, messages *MessageService, hub *Hub) *ReactionService {
+    s := &ReactionService{db: db, messages: messages, hub: hub}
+    s.batches = newReactionBatcher(reactionBurst, reactionWindow, s.broadcastAll)
+    return s
+}
+
+// Reactions returns the reactions to a message the user can see.
+func (s *ReactionService) Reactions(ctx context.Context, userID, messageID uuid.UUID) (*MessageReactions, error) {
+    if _, err := s.messages.visibleMessage(ctx, userID, messageID); err != nil {
+        return nil, err
+    }
+    return s.userReactions(ctx, userID, messageID)
+}
+
+// Add reacts to a message the user can see with emoji, which may be a
+// shortcode. Reacting again with the same emoji is not an error.
+func (s *ReactionService) Add(ctx context.Context, userID, messageID uuid.UUID, emoji string) (*MessageReactions, error) {
+    emoji, err := normalizeReaction(emoji)
+    if err != nil {
+        return nil, err
+    }
+    message, err := s.messages.visibleMessage(ctx, userID, messageID)
+    if err != nil {
+        return nil, err
+    }
+
+    // The cap only keeps messages from growing an unbounded row of
+    // reactions, so two users racing past it is tolerated.
+    counts, err := reactionCounts(ctx, s.db, messageID)
+    if err != nil {
+        return nil, err
+    }
+    if len(counts) >= MaxReactionEmojis && !hasReaction(counts, emoji) {
+        return nil, ErrTooManyReactions
+    }
+
+    added, err := s.db.AddMessageReaction(ctx, database.AddMessageReactionParams{MessageID: messageID, UserID: userID, Emoji: emoji})
+    if err != nil {
+        return nil, err
+    }
+    if added > 0 {
+        s.changed(ctx, message)
+    }
+    return s.userReactions(ctx, userID, messageID)
+}
+
+// Remove takes back the user's reaction to a message. Removing a reaction
+// the user did not make is not an error.
+func (s *ReactionService) Remove(ctx context.Context, userID, messageID uuid.UUID, emoji string) (*MessageReactions, error) {
+    emoji, err := normalizeReaction(emoji)
+    if err != nil {
+        return nil, err
+    }
+    message, err := s.messages.visibleMessage(ctx, userID, messageID)
+    if err != nil {
+        return nil, err
+    }
+
+    removed, err := s.db.RemoveMessageReaction(ctx, database.RemoveMessageReactionParams{MessageID: messageID, UserID: userID, Emoji: emoji})
+    if err != nil {
+        return nil, err
+    }
+    if removed > 0 {
+        s.changed(ctx, message)
+    }
+    return s.userReactions(ctx, userID, messageID)
+}
+
+// changed sends the message's new counts right away, or leaves them to the
+// next batch if its room is busy.
+func (s *ReactionService) changed(ctx context.Context, message database.Message) {
+    if s.batches.add(message) {
+        s.broadcast(ctx, message)
+    }
+}
+
+// broadcastAll sends the current counts of a closed batch's messages.
+func (s *ReactionService) broadcastAll(messages []database.Message) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    for _, message := range messages {
+        s.broadcast(ctx, message)
+    }
+}
+
+// broadcast sends the message's current reaction counts to the clients who
+// can see it. Updates carry the full counts rather than a delta, so a client
+// that misses some stays correct from the next one.
+func (s *ReactionService) broadcast(ctx context.Context, message database.Message) {
+    counts, err := reactionCounts(ctx, s.db, message.ID)
+    if err != nil {
+        log.Printf("failed to load reactions of message %s: %v", message.ID, err)
+        return
+    }
+    s.hub.broadcastUpdate(message, &Message{
+        Type:      EventReactionsUpdated,
+        ID:        message.ID.String(),
+        SenderID:  message.SenderID.String(),
+        RoomID:    message.RoomID.String(),
+        CreatedAt: time.Now(),
+        Reactions: counts,
+    })
+}
+
+// userReactions loads a message's reaction counts and the user's own
+// reactions.
+func (s *ReactionService) userReactions(ctx context.Context, userID, messageID uuid.UUID) (*MessageReactions, error) {
+    counts, err := reactionCounts(ctx, s.db, messageID)
+    if err != nil {
+        return nil, err
+    }
+    mine, err := s.db.GetUserMessageReactions(ctx, database.GetUserMessageReactionsParams{MessageID: messageID, UserID: userID})
+    if err != nil {
+        return nil, err
+    }
+    if mine == nil {
+        mine = []string{}
+    }
+    return &MessageReactions{MessageID: messageID.String(), Reactions: counts, Mine: mine}, nil
+}
+
+// loadReactions fetches the reaction counts of rows in a single query, keyed
+// by message ID.
+func (s *MessageService) loadReactions(ctx context.Context, rows []database.Message) (map[uuid.UUID][]Reaction, error) {
+    reactions := make(map[uuid.UUID][]Reaction)
+    if len(rows) == 0 {
+        return reactions, nil
+    }
+
+    ids := make([]uuid.UUID, len(rows))
+    for i, row := range rows {
+        ids[i] = row.ID
+    }
+    found, err := s.db.GetMessageReactionCounts(ctx, ids)
+    if err != nil {
+        return nil, err
+    }
+    for _, row := range found {
+        reactions[row.MessageID] = append(reactions[row.MessageID], Reaction{Emoji: row.Emoji, Count: row.Count})
+    }
+    return reactions, nil
+}
+
+// reactionCounts returns a message's reaction counts, in the order the emoji
+// were first used.
+func reactionCounts(ctx context.Context, db *database.Queries, messageID uuid.UUID) ([]Reaction, error) {
+    rows, err := db.GetMessageReactionCounts(ctx, []uuid.UUID{messageID})
+    if err != nil {
+        return nil, err
+    }
+    counts := make([]Reaction, 0, len(rows))
+    for _, row := range rows {
+        counts = append(counts, Reaction{Emoji: row.Emoji, Count: row.Count})
+    }
+    return counts, nil
+}
+
+func hasReaction(counts []Reaction, emoji string) bool {
+    for _, count := range counts {
+        if count.Emoji == emoji {
+            return true
+        }
+    }
+    return false
+}
+
+// normalizeReaction converts a shortcode reaction to its emoji, so both
+// forms count together, and checks that the reaction looks like an emoji:
+// short, without spaces and without letters.
+func normalizeReaction(emoji string) (string, error) {
+    emoji = NormalizeShortcodes(strings.TrimSpace(emoji))
+    if emoji == "" || len(emoji) > maxReactionLength || !utf8.ValidString(emoji) {
+        return "", ErrInvalidReaction
+    }
+    for _, r := range emoji {
+        if unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsLetter(r) {
+            return "", ErrInvalidReaction
+        }
+    }
+    return emoji, nil
+}
+
+// reactionBatcher decides per room whether reaction updates are sent one by
+// one or batched. A room's first burst updates within window are sent right
+// away; past that, changed messages are collected and flushed once per
+// window until a window closes with nothing in it.
+type reactionBatcher struct {
+    burst  int
+    window time.Duration
+    flush  func(messages []database.Message)
+
+    mu    sync.Mutex
+    rooms map[uuid.UUID]*reactionRoom
+}
+
+// reactionRoom tracks the recent reaction updates of one room.
+type reactionRoom struct {
+    // sent holds when the updates sent right away within the window were.
+    sent []time.Time
+    // pending holds the messages changed in the open batch; it is nil while
+    // updates are sent right away.
+    pending map[uuid.UUID]database.Message
+}
+
+func newReactionBatcher(burst int, window time.Duration, flush func(messages []database.Message)) *reactionBatcher {
+    return &reactionBatcher{burst: burst, window: window, flush: flush, rooms: make(map[uuid.UUID]*reactionRoom)}
+}
+
+// add records that the message's reactions changed. It reports whether the
+// update should be sent now; if not, it is sent when the room's batch closes.
+func (b *reactionBatcher) add(message database.Message) bool {
+    now := time.Now()
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    room, ok := b.rooms[message.RoomID]
+    if !ok {
+        room = &reactionRoom{}
+        b.rooms[message.RoomID] = room
+    }
+    if room.pending != nil {
+        room.pending[message.ID] = message
+        return false
+    }
+
+    start := 0
+    for start < len(room.sent) && now.Sub(room.sent[start]) >= b.window {
+        start++
+    }
+    room.sent = room.sent[start:]
+    if len(room.sent) < b.burst {
+        room.sent = append(room.sent, now)
+        return true
+    }
+
+    room.sent = nil
+    room.pending = map[uuid.UUID]database.Message{message.ID: message}
+    roomID := message.RoomID
+    time.AfterFunc(b.window, func() { b.close(roomID) })
+    return false
+}
+
+// close flushes the room's batch. The room stays busy, with a new batch,
+// while its reactions keep changing.
+func (b *reactionBatcher) close(roomID uuid.UUID) {
+    b.mu.Lock()
+    room := b.rooms[roomID]
+    if room == nil || len(room.pending) == 0 {
+        delete(b.rooms, roomID)
+        b.mu.Unlock()
+        return
+    }
+    messages := make([]database.Message, 0, len(room.pending))
+    for _, message := range room.pending {
+        messages = append(messages, message)
+    }
+    room.pending = make(map[uuid.UUID]database.Message)
+    time.AfterFunc(b.window, func() { b.close(roomID) })
+    b.mu.Unlock()
+
+    b.flush(messages)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// FrameReplay carries every replayed message at once, in columns, to clients
+// with the compact_replay capability.
+const FrameReplay = "replay"
+
+// CapabilityCompactReplay is the capability clients advertise when they
+// connect to receive missed messages as a single replay frame.
+const CapabilityCompactReplay = "compact_replay"
+
+// ReplayBatch holds replayed messages in columnar form: each column lists one
+// message field for every message, oldest first, with null where a message
+// leaves the field out. Field names are only sent once, which makes large
+// replays much smaller.
+type ReplayBatch struct {
+    Count   int                          `json:"count"`
+    Columns map[string][]json.RawMessage `json:"columns"`
+}
+
+var jsonNull = json.RawMessage("null")
+
+// replay writes the client's backlog, either as one replay frame or as a
+// frame per message, and returns the sequence number of the last message.
+// Frames are compressed if the client negotiated permessage-deflate. It
+// reports false if the connection failed.
+func (c *Client) replay() (int64, bool) {
+    if len(c.backlog) == 0 {
+        return 0, true
+    }
+    c.conn.EnableWriteCompression(true)
+    defer c.conn.EnableWriteCompression(false)
+
+    var frames [][]byte
+    if c.compactReplay {
+        frame, err := c.replayFrame()
+        if err != nil {
+            log.Printf("json marshal error: %v", err)
+            return 0, false
+        }
+        frames = append(frames, frame)
+    } else {
+        for _, message := range c.backlog {
+            frame, err := c.frame(message)
+            if err != nil {
+                log.Printf("json marshal error: %v", err)
+                return 0, false
+            }
+            frames = append(frames, frame)
+        }
+    }
+    for _, frame := range frames {
+        c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+        if err := c.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+            return 0, false
+        }
+    }
+    return c.backlog[len(c.backlog)-1].Seq, true
+}
+
+// replayFrame encodes the backlog as a replay frame.
+func (c *Client) replayFrame() ([]byte, error) {
+    batch := ReplayBatch{Count: len(c.backlog), Columns: make(map[string][]json.RawMessage)}
+    for i, message := range c.backlog {
+        data, err := json.Marshal(c.localize(message))
+        if err != nil {
+            return nil, err
+        }
+        var fields map[string]json.RawMessage
+        if err := json.Unmarshal(data, &fields); err != nil {
+            return nil, err
+        }
+        for name, value := range fields {
+            column, ok := batch.Columns[name]
+            if !ok {
+                column = make([]json.RawMessage, len(c.backlog))
+                for j := range column {
+                    column[j] = jsonNull
+                }
+                batch.Columns[name] = column
+            }
+            column[i] = value
+        }
+    }
+
+    payload, err := json.Marshal(batch)
+    if err != nil {
+        return nil, err
+    }
+    last := c.backlog[len(c.backlog)-1]
+    return json.Marshal(Envelope{Type: FrameReplay, Payload: payload, TS: last.CreatedAt})
+}
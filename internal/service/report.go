@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// AdminChannel is the hub channel administrators connect to for moderation
+// events from every room. It is read-only for its clients.
+const AdminChannel = "admin"
+
+// EventMessageReported is the type of the moderation event sent to the admin
+// channel when a message is reported.
+const EventMessageReported = "message.reported"
+
+// maxReportReasonLength caps the length of a report's reason.
+const maxReportReasonLength = 1000
+
+var (
+    // ErrInvalidReport is returned for reports without a reason or with one
+    // that is too long.
+    ErrInvalidReport = errors.New("a report needs a reason of at most 1000 characters")
+    // ErrOwnMessageReport is returned when users report their own message.
+    ErrOwnMessageReport = errors.New("you cannot report your own message")
+    // ErrAlreadyReported is returned when the user already reported the message.
+    ErrAlreadyReported = errors.New("you already reported this message")
+)
+
+// Report is a user's complaint about a message, for room owners and
+// administrators to review.
+type Report struct {
+    ID         string    `json:"id"`
+    MessageID  string    `json:"message_id"`
+    RoomID     string    `json:"room_id"`
+    ReporterID string    `json:"reporter_id"`
+    Reason     string    `json:"reason" example:"spam"`
+    CreatedAt  time.Time `json:"created_at"`
+    // MessageSenderID and MessageContent describe the reported message.
+    MessageSenderID string `json:"message_sender_id"`
+    MessageContent  string `json:"message_content"`
+}
+
+// ReportService records message reports and announces them to administrators.
+type ReportService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+}
+
+// NewReportService creates a new ReportService.
+func NewReportService(db *database.Queries, messages *MessageService, hub *Hub) *ReportService {
+    return &ReportService{db: db, messages: messages, hub: hub}
+}
+
+// Report records reporterID's report of a message they can see and sends it
+// to the admin channel as a message.reported event.
+func (s *ReportService) Report(ctx context.Context, reporterID, messageID uuid.UUID, reason string) (*Report, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" || len(reason) > maxReportReasonLength {
+        return nil, ErrInvalidReport
+    }
+
+    message, err := s.messages.visibleMessage(ctx, reporterID, messageID)
+    if err != nil {
+        return nil, err
+    }
+    if message.SenderID == reporterID {
+        return nil, ErrOwnMessageReport
+    }
+
+    row, err := s.db.CreateMessageReport(ctx, database.CreateMessageReportParams{
+        ID:         uuid.New(),
+        MessageID:  message.ID,
+        RoomID:     message.RoomID,
+        ReporterID: reporterID,
+        Reason:     reason,
+    })
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+        return nil, ErrAlreadyReported
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    report := &Report{
+        ID:              row.ID.String(),
+        MessageID:       row.MessageID.String(),
+        RoomID:          row.RoomID.String(),
+        ReporterID:      row.ReporterID.String(),
+        Reason:          row.Reason,
+        CreatedAt:       row.CreatedAt,
+        MessageSenderID: message.SenderID.String(),
+        MessageContent:  message.Content,
+    }
+    s.hub.Broadcast(&Message{
+        Type:      EventMessageReported,
+        ID:        report.MessageID,
+        SenderID:  report.ReporterID,
+        RoomID:    AdminChannel,
+        CreatedAt: row.CreatedAt,
+        Report:    report,
+    })
+    return report, nil
+}
+
+// RoomReports returns up to limit of a room's reports, newest first. Callers
+// are responsible for checking that the user may see them.
+func (s *ReportService) RoomReports(ctx context.Context, roomID uuid.UUID, limit int32) ([]Report, error) {
+    rows, err := s.db.GetRoomReports(ctx, database.GetRoomReportsParams{RoomID: roomID, Limit: limit})
+    if err != nil {
+        return nil, err
+    }
+    reports := make([]Report, 0, len(rows))
+    for _, row := range rows {
+        reports = append(reports, Report{
+            ID:              row.ID.String(),
+            MessageID:       row.MessageID.String(),
+            RoomID:          row.RoomID.String(),
+            ReporterID:      row.ReporterID.String(),
+            Reason:          row.Reason,
+            CreatedAt:       row.CreatedAt,
+            MessageSenderID: row.MessageSenderID.String(),
+            MessageContent:  row.MessageContent,
+        })
+    }
+    return reports, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// ErrInvalidRetention is returned for retention limits below one.
+var ErrInvalidRetention = errors.New("retention days and max messages must be at least 1")
+
+// ErrVersionConflict is returned when a room changed after the caller read it.
+var ErrVersionConflict = errors.New("room was modified by someone else")
+
+// RetentionPolicy limits how long a room keeps its messages. Nil limits keep
+// messages forever; when both are set, messages exceeding either are purged.
+type RetentionPolicy struct {
+    Days        *int32 `json:"days,omitempty" example:"30"`
+    MaxMessages *int32 `json:"max_messages,omitempty" example:"10000"`
+    // Hold suspends purging regardless of the limits. Only admins can set it.
+    Hold bool `json:"hold"`
+}
+
+// RetentionService enforces per-room retention policies.
+type RetentionService struct {
+    db    *database.Queries
+    pool  *pgxpool.Pool
+    store *MessageStore
+}
+
+// NewRetentionService creates a new RetentionService.
+func NewRetentionService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore) *RetentionService {
+    return &RetentionService{db: db, pool: pool, store: store}
+}
+
+// Run purges expired messages every interval until ctx is cancelled.
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if err := s.PurgeAll(ctx); err != nil {
+            log.Printf("retention purge failed: %v", err)
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// PurgeAll applies the retention policy of every room that has one and is not
+// on hold, and forgets member changes older than MemberChangeRetention and
+// invite codes that can no longer be used.
+func (s *RetentionService) PurgeAll(ctx context.Context) error {
+    if _, err := s.db.PruneRoomMemberChanges(ctx, time.Now().Add(-MemberChangeRetention)); err != nil {
+        log.Printf("pruning member changes failed: %v", err)
+    }
+    if _, err := s.db.PruneRoomInviteCodes(ctx); err != nil {
+        log.Printf("pruning invite codes failed: %v", err)
+    }
+    rooms, err := s.db.GetRoomsWithRetention(ctx)
+    if err != nil {
+        return err
+    }
+    for _, room := range rooms {
+        if err := s.purgeRoom(ctx, room); err != nil {
+            log.Printf("retention purge of room %s failed: %v", room.ID, err)
+        }
+    }
+    return nil
+}
+
+// purgeRoom deletes the room's messages that fall outside its policy and
+// records the purge in the audit log.
+func (s *RetentionService) purgeRoom(ctx context.Context, room database.Room) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    var deleted int64
+    if room.RetentionDays != nil {
+        cutoff := time.Now().AddDate(0, 0, -int(*room.RetentionDays))
+        n, err := qtx.PurgeMessagesBefore(ctx, database.PurgeMessagesBeforeParams{RoomID: room.ID, CreatedAt: cutoff})
+        if err != nil {
+            return err
+        }
+        deleted += n
+    }
+    if room.RetentionMaxMessages != nil {
+        n, err := qtx.PurgeMessagesBeyondCount(ctx, database.PurgeMessagesBeyondCountParams{RoomID: room.ID, Keep: *room.RetentionMaxMessages})
+        if err != nil {
+            return err
+        }
+        deleted += n
+    }
+    if deleted == 0 {
+        return nil
+    }
+    // Purged messages are forgotten, not deleted, so their events go too.
+    if err := s.store.Forget(ctx, qtx, room.ID); err != nil {
+        return err
+    }
+
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        Action: AuditActionRetentionPurge,
+        RoomID: &room.ID,
+        Details: map[string]any{
+            "deleted":      deleted,
+            "days":         room.RetentionDays,
+            "max_messages": room.RetentionMaxMessages,
+        },
+    })
+    if err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+// SetPolicy changes a room's retention policy and records the change in the
+// audit log. Callers are responsible for checking that actorID may do so.
+// The update only applies while the room is still at room.Version, so a
+// decision made on a stale read fails with ErrVersionConflict.
+func (s *RetentionService) SetPolicy(ctx context.Context, room database.Room, actorID uuid.UUID, policy RetentionPolicy) (database.Room, error) {
+    if (policy.Days != nil && *policy.Days < 1) || (policy.MaxMessages != nil && *policy.MaxMessages < 1) {
+        return database.Room{}, ErrInvalidRetention
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return database.Room{}, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    updated, err := qtx.SetRoomRetention(ctx, database.SetRoomRetentionParams{
+        ID:                   room.ID,
+        RetentionDays:        policy.Days,
+        RetentionMaxMessages: policy.MaxMessages,
+        RetentionHold:        policy.Hold,
+        ExpectedVersion:      &room.Version,
+    })
+    if errors.Is(err, pgx.ErrNoRows) {
+        return database.Room{}, ErrVersionConflict
+    }
+    if err != nil {
+        return database.Room{}, err
+    }
+
+    err = RecordAudit(ctx, qtx, AuditEntry{
+        ActorID: &actorID,
+        Action:  AuditActionRetentionUpdate,
+        RoomID:  &room.ID,
+        Details: map[string]any{
+            "from": RetentionPolicy{Days: room.RetentionDays, MaxMessages: room.RetentionMaxMessages, Hold: room.RetentionHold},
+            "to":   policy,
+        },
+    })
+    if err != nil {
+        return database.Room{}, err
+    }
+    return updated, tx.Commit(ctx)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventMessageEdited is the type of the event carrying an edited message.
+const EventMessageEdited = "message.edited"
+
+var (
+    // ErrNotMessageSender is returned when someone other than the sender
+    // tries to edit a message.
+    ErrNotMessageSender = errors.New("only the sender can edit a message")
+    // ErrInvalidEdit is returned for empty or oversized edits and for edits
+    // to messages that are not plain text, such as polls.
+    ErrInvalidEdit = errors.New("only text messages can be edited, and the new content must not be empty or exceed the room's message size limit")
+)
+
+// Revision is a previous version of an edited message.
+type Revision struct {
+    Content  string `json:"content"`
+    EditedBy string `json:"edited_by"`
+    // ReplacedAt is when this version was replaced by the next one.
+    ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// RevisionService edits messages, keeping their previous versions, and
+// broadcasts edits through the Hub.
+type RevisionService struct {
+    db       *database.Queries
+    messages *MessageService
+    hub      *Hub
+}
+
+// NewRevisionService creates a new RevisionService.
+func NewRevisionService(db *database.Queries, messages *MessageService, hub *Hub) *RevisionService {
+    return &RevisionService{db: db, messages: messages, hub: hub}
+}
+
+// Edit replaces the content of the user's own text message and announces the
+// edit to the message's audience. Mentions are not re-resolved, so editing
+// never notifies anyone.
+func (s *RevisionService) Edit(ctx context.Context, userID, messageID uuid.UUID, content string) (*Message, error) {
+    original, err := s.messages.visibleMessage(ctx, userID, messageID)
+    if err != nil {
+        return nil, err
+    }
+    if original.SenderID != userID {
+        return nil, ErrNotMessageSender
+    }
+
+    room, err := s.db.GetRoomByID(ctx, original.RoomID)
+    if err != nil {
+        return nil, err
+    }
+    if s.messages.opts.EmojiShortcodes {
+        content = NormalizeShortcodes(content)
+    }
+    if original.Kind != MessageKindText || content == "" || len(content) > s.messages.MaxMessageSize(room) {
+        return nil, ErrInvalidEdit
+    }
+
+    row, err := s.messages.store.Edit(ctx, s.db, original, userID, content)
+    if err != nil {
+        return nil, err
+    }
+
+    hydrated, err := s.messages.hydrate(ctx, []database.Message{row})
+    if err != nil {
+        return nil, err
+    }
+    message := hydrated[0]
+
+    event := *message
+    event.Type = EventMessageEdited
+    s.hub.broadcastUpdate(row, &event)
+    return message, nil
+}
+
+// History returns the previous versions of a message, oldest first. Users see
+// the history of messages visible to them; administrators see any message's.
+func (s *RevisionService) History(ctx context.Context, userID, messageID uuid.UUID) ([]Revision, error) {
+    if _, err := s.messages.visibleMessage(ctx, userID, messageID); err != nil {
+        if !errors.Is(err, ErrMessageNotFound) {
+            return nil, err
+        }
+        user, userErr := s.db.GetUserByID(ctx, userID)
+        if userErr != nil || !user.IsAdmin {
+            return nil, err
+        }
+        if _, err := s.db.GetMessageByID(ctx, messageID); err != nil {
+            return nil, ErrMessageNotFound
+        }
+    }
+
+    rows, err := s.db.GetMessageRevisions(ctx, messageID)
+    if err != nil {
+        return nil, err
+    }
+    revisions := make([]Revision, len(rows))
+    for i, row := range rows {
+        revisions[i] = Revision{
+            Content:    row.Content,
+            EditedBy:   row.EditedBy.String(),
+            ReplacedAt: row.CreatedAt,
+        }
+    }
+    return revisions, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Room visibilities. Private rooms are listed only to their members, and
+// joining one takes an owner's approval.
+const (
+    RoomVisibilityPublic  = "public"
+    RoomVisibilityPrivate = "private"
+)
+
+// EventRoomUpdated is the type of the event sent to a room's connected
+// members when its name, topic, description or avatar changes.
+const EventRoomUpdated = "room.updated"
+
+// CloseReasonRoomDeleted is the reason in the close frame of connections to
+// a room that was deleted.
+const CloseReasonRoomDeleted = "room.deleted"
+
+// RoomUpdate carries a room's details in room.updated events.
+type RoomUpdate struct {
+    Name        string `json:"name"`
+    Topic       string `json:"topic"`
+    Description string `json:"description"`
+    AvatarURL   string `json:"avatar_url,omitempty"`
+    Version     int32  `json:"version"`
+}
+
+// BroadcastRoomUpdate sends the room's current details to its connected
+// members, announcing a change made by actorID.
+func (h *Hub) BroadcastRoomUpdate(room database.Room, actorID uuid.UUID) {
+    update := &RoomUpdate{
+        Name:        room.Name,
+        Topic:       room.Topic,
+        Description: room.Description,
+        Version:     room.Version,
+    }
+    if room.AvatarUrl != nil {
+        update.AvatarURL = *room.AvatarUrl
+    }
+    h.Broadcast(&Message{
+        Type:      EventRoomUpdated,
+        SenderID:  actorID.String(),
+        RoomID:    room.ID.String(),
+        CreatedAt: time.Now(),
+        Room:      update,
+    })
+}
+
+// DeleteRoom deletes a room and everything in it in a single transaction:
+// its pins, invitations, invite codes, messages and memberships, then the
+// room itself. Once committed, every live connection to the room is closed
+// with a room.deleted close frame. Rooms whose messages are under legal hold
+// are kept, with ErrLegalHold.
+func DeleteRoom(ctx context.Context, db *database.Queries, pool *pgxpool.Pool, hub *Hub, roomID uuid.UUID) error {
+    tx, err := pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+    qtx := db.WithTx(tx)
+
+    held, err := qtx.IsRoomContentHeld(ctx, roomID)
+    if err != nil {
+        return err
+    }
+    if held {
+        return ErrLegalHold
+    }
+
+    if _, err := qtx.DeleteRoomPins(ctx, roomID); err != nil {
+        return err
+    }
+    if _, err := qtx.DeleteRoomInvites(ctx, roomID); err != nil {
+        return err
+    }
+    if _, err := qtx.DeleteRoomInviteCodes(ctx, roomID); err != nil {
+        return err
+    }
+    if _, err := qtx.DeleteRoomMessages(ctx, roomID); err != nil {
+        return err
+    }
+    if _, err := qtx.DeleteRoomMembers(ctx, roomID); err != nil {
+        return err
+    }
+    if err := qtx.DeleteRoom(ctx, roomID); err != nil {
+        return err
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return err
+    }
+
+    hub.CloseRoom(roomID, CloseReasonRoomDeleted)
+    return nil
+}
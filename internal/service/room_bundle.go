@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // RoomBundleVersion is the version of the room bundle format written by
+    // exports; imports only accept this version.
+    RoomBundleVersion = 1
+    // MaxBundleMessages is how many of a room's latest messages an export
+    // includes, and how many an import accepts.
+    MaxBundleMessages = 10000
+    // MaxBundleMembers is how many members an import accepts.
+    MaxBundleMembers = 10000
+    // roomBundleMemberPage is how many members an export loads at a time.
+    roomBundleMemberPage = 1000
+)
+
+// ErrInvalidRoomBundle is returned, wrapped with the reason, for bundles an
+// import cannot use.
+var ErrInvalidRoomBundle = errors.New("invalid room bundle")
+
+// RoomBundle is a portable copy of a room: its configuration, its members
+// and, optionally, its messages. Users are identified by username rather
+// than ID, so a bundle can be imported on another deployment.
+type RoomBundle struct {
+    Version    int              `json:"version" example:"1"`
+    ExportedAt time.Time        `json:"exported_at"`
+    Room       RoomBundleConfig `json:"room"`
+    // Members lists everyone in the room except the owner who exported it.
+    Members []RoomBundleMember `json:"members"`
+    // Messages holds the room's latest text messages, oldest first; absent
+    // unless the export asked for them.
+    Messages []RoomBundleMessage `json:"messages,omitempty"`
+}
+
+// RoomBundleConfig is a room's configuration in a bundle. The avatar is not
+// included, as it lives in the exporting deployment's storage.
+type RoomBundleConfig struct {
+    Name             string   `json:"name" example:"General"`
+    Topic            string   `json:"topic" example:"Release planning for v2"`
+    Description      string   `json:"description"`
+    Visibility       string   `json:"visibility" example:"public"`
+    MaxMessageSize   *int32   `json:"max_message_size,omitempty" example:"2048"`
+    AllowUrgent      bool     `json:"allow_urgent"`
+    StatsEnabled     bool     `json:"stats_enabled"`
+    SummariesEnabled bool     `json:"summaries_enabled"`
+    RoomMentionRole  string   `json:"room_mention_role" example:"moderator"`
+    Tags             []string `json:"tags" example:"gaming,golang"`
+    // Retention carries the retention limits; a legal hold is not exported.
+    Retention   *RetentionPolicy `json:"retention,omitempty"`
+    Permissions RoomPermissions  `json:"permissions"`
+}
+
+// RoomBundleMember is a member of a bundled room.
+type RoomBundleMember struct {
+    Username string    `json:"username" example:"alice"`
+    Role     string    `json:"role" example:"moderator"`
+    JoinedAt time.Time `json:"joined_at"`
+}
+
+// RoomBundleMessage is a message of a bundled room.
+type RoomBundleMessage struct {
+    // ID is the message's ID where it was exported; quotes refer to it.
+    ID              string          `json:"id"`
+    Sender          string          `json:"sender" example:"alice"`
+    Content         string          `json:"content"`
+    Metadata        json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+    QuotedMessageID string          `json:"quoted_message_id,omitempty"`
+    // Mentions lists the usernames the message mentions.
+    Mentions  []string  `json:"mentions,omitempty" example:"bob"`
+    Priority  string    `json:"priority" example:"normal"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// RoomImport reports what importing a bundle created.
+type RoomImport struct {
+    Room database.Room `json:"-"`
+    // Members counts the members added, the importer included.
+    Members int `json:"members" example:"12"`
+    // SkippedMembers lists the usernames with no account on this server.
+    SkippedMembers []string `json:"skipped_members" example:"carol"`
+    Messages       int      `json:"messages" example:"250"`
+}
+
+// RoomBundleService exports rooms as bundles and imports bundles as new
+// rooms.
+type RoomBundleService struct {
+    db    *database.Queries
+    pool  *pgxpool.Pool
+    store *MessageStore
+}
+
+// NewRoomBundleService creates a new RoomBundleService.
+func NewRoomBundleService(db *database.Queries, pool *pgxpool.Pool, store *MessageStore) *RoomBundleService {
+    return &RoomBundleService{db: db, pool: pool, store: store}
+}
+
+// Export bundles the room's configuration and members, leaving out
+// exporterID, and with withMessages its latest MaxBundleMessages text
+// messages to everyone.
+func (s *RoomBundleService) Export(ctx context.Context, room database.Room, exporterID uuid.UUID, withMessages bool) (*RoomBundle, error) {
+    tags, err := s.db.GetRoomTags(ctx, room.ID)
+    if err != nil {
+        return nil, err
+    }
+    permissions, err := LoadRoomPermissions(ctx, s.db, room)
+    if err != nil {
+        return nil, err
+    }
+    bundle := &RoomBundle{
+        Version:    RoomBundleVersion,
+        ExportedAt: time.Now(),
+        Room: RoomBundleConfig{
+            Name:             room.Name,
+            Topic:            room.Topic,
+            Description:      room.Description,
+            Visibility:       room.Visibility,
+            MaxMessageSize:   room.MaxMessageSize,
+            AllowUrgent:      room.AllowUrgent,
+            StatsEnabled:     room.StatsEnabled,
+            SummariesEnabled: room.SummariesEnabled,
+            RoomMentionRole:  room.RoomMentionRole,
+            Tags:             tags,
+            Permissions:      permissions,
+        },
+        Members: []RoomBundleMember{},
+    }
+    if bundle.Room.Tags == nil {
+        bundle.Room.Tags = []string{}
+    }
+    if room.RetentionDays != nil || room.RetentionMaxMessages != nil {
+        bundle.Room.Retention = &RetentionPolicy{Days: room.RetentionDays, MaxMessages: room.RetentionMaxMessages}
+    }
+
+    params := database.GetRoomMembersPageParams{RoomID: room.ID, MaxResults: roomBundleMemberPage}
+    for {
+        members, err := s.db.GetRoomMembersPage(ctx, params)
+        if err != nil {
+            return nil, err
+        }
+        for _, member := range members {
+            if member.ID != exporterID {
+                bundle.Members = append(bundle.Members, RoomBundleMember{Username: member.Username, Role: member.Role, JoinedAt: member.JoinedAt})
+            }
+        }
+        if len(members) < roomBundleMemberPage {
+            break
+        }
+        last := members[len(members)-1]
+        params.CursorJoinedAt, params.CursorUserID = &last.JoinedAt, &last.ID
+    }
+
+    if withMessages {
+        if bundle.Messages, err = s.exportMessages(ctx, room.ID); err != nil {
+            return nil, err
+        }
+    }
+    return bundle, nil
+}
+
+// exportMessages returns the room's latest text messages to everyone, oldest
+// first, with mentions as usernames.
+func (s *RoomBundleService) exportMessages(ctx context.Context, roomID uuid.UUID) ([]RoomBundleMessage, error) {
+    rows, err := s.db.GetRoomMessagesForExport(ctx, database.GetRoomMessagesForExportParams{RoomID: roomID, MaxMessages: MaxBundleMessages})
+    if err != nil {
+        return nil, err
+    }
+    var mentioned []uuid.UUID
+    for _, row := range rows {
+        mentioned = append(mentioned, row.Message.Mentions...)
+    }
+    usernames := make(map[uuid.UUID]string)
+    if len(mentioned) > 0 {
+        users, err := s.db.GetUsernamesByIDs(ctx, mentioned)
+        if err != nil {
+            return nil, err
+        }
+        for _, user := range users {
+            usernames[user.ID] = user.Username
+        }
+    }
+
+    messages := make([]RoomBundleMessage, 0, len(rows))
+    for _, row := range slices.Backward(rows) {
+        message := RoomBundleMessage{
+            ID:        row.Message.ID.String(),
+            Sender:    row.SenderUsername,
+            Content:   row.Message.Content,
+            Priority:  row.Message.Priority,
+            CreatedAt: row.Message.CreatedAt,
+        }
+        if len(row.Message.Metadata) > 0 && string(row.Message.Metadata) != "{}" {
+            message.Metadata = row.Message.Metadata
+        }
+        if row.Message.QuotedMessageID != nil {
+            message.QuotedMessageID = row.Message.QuotedMessageID.String()
+        }
+        for _, userID := range row.Message.Mentions {
+            if username, ok := usernames[userID]; ok {
+                message.Mentions = append(message.Mentions, username)
+            }
+        }
+        messages = append(messages, message)
+    }
+    return messages, nil
+}
+
+// Import creates a new room owned by ownerID from a bundle, in a single
+// transaction. Members and senders are matched to this server's users by
+// username: members without an account are skipped and reported, and
+// messages from senders without one are imported as the system bot's.
+// Imported messages are new messages, sent now; each keeps where it came
+// from, who sent it and when under "imported" in its metadata. Quotes
+// between imported messages are kept. Members start with every imported
+// message read.
+func (s *RoomBundleService) Import(ctx context.Context, ownerID uuid.UUID, bundle RoomBundle) (*RoomImport, error) {
+    if err := validateRoomBundle(&bundle); err != nil {
+        return nil, err
+    }
+    users, err := s.bundleUsers(ctx, bundle)
+    if err != nil {
+        return nil, err
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+    qtx := s.db.WithTx(tx)
+
+    config := bundle.Room
+    room, err := qtx.CreateRoom(ctx, database.CreateRoomParams{ID: uuid.New(), Name: config.Name, OwnerID: ownerID, Visibility: config.Visibility})
+    if err != nil {
+        return nil, err
+    }
+    if room, err = qtx.UpdateRoom(ctx, database.UpdateRoomParams{ID: room.ID, Topic: &config.Topic, Description: &config.Description}); err != nil {
+        return nil, err
+    }
+    room, err = qtx.SetRoomSettings(ctx, database.SetRoomSettingsParams{
+        ID:               room.ID,
+        MaxMessageSize:   config.MaxMessageSize,
+        AllowUrgent:      config.AllowUrgent,
+        Visibility:       config.Visibility,
+        StatsEnabled:     config.StatsEnabled,
+        RoomMentionRole:  config.RoomMentionRole,
+        SummariesEnabled: config.SummariesEnabled,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if config.Retention != nil {
+        room, err = qtx.SetRoomRetention(ctx, database.SetRoomRetentionParams{ID: room.ID, RetentionDays: config.Retention.Days, RetentionMaxMessages: config.Retention.MaxMessages})
+        if err != nil {
+            return nil, err
+        }
+    }
+    if len(config.Tags) > 0 {
+        if err := qtx.AddRoomTags(ctx, database.AddRoomTagsParams{RoomID: room.ID, Tags: config.Tags}); err != nil {
+            return nil, err
+        }
+    }
+    permissions := config.Permissions
+    _, err = SetRoomPermissions(ctx, qtx, room, ownerID, RoomPermissionsUpdate{Invite: &permissions.Invite, Attachments: &permissions.Attachments, Pin: &permissions.Pin})
+    if err != nil {
+        return nil, err
+    }
+
+    result := &RoomImport{SkippedMembers: []string{}}
+    // Messages go in before the members join, so that everything imported
+    // counts as read.
+    newIDs := make(map[string]uuid.UUID, len(bundle.Messages))
+    for _, message := range bundle.Messages {
+        params, err := importedMessage(room.ID, message, users, newIDs)
+        if err != nil {
+            return nil, err
+        }
+        if _, err := s.store.Create(ctx, qtx, params); err != nil {
+            return nil, err
+        }
+        newIDs[message.ID] = params.ID
+    }
+    result.Messages = len(bundle.Messages)
+
+    if err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: room.ID, UserID: ownerID}); err != nil {
+        return nil, err
+    }
+    if _, err := qtx.SetRoomMemberRole(ctx, database.SetRoomMemberRoleParams{RoomID: room.ID, UserID: ownerID, Role: RoomRoleOwner}); err != nil {
+        return nil, err
+    }
+    result.Members = 1
+    for _, member := range bundle.Members {
+        userID, ok := users[member.Username]
+        if !ok {
+            result.SkippedMembers = append(result.SkippedMembers, member.Username)
+            continue
+        }
+        if userID == ownerID {
+            continue
+        }
+        if err := qtx.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: room.ID, UserID: userID}); err != nil {
+            return nil, err
+        }
+        if _, err := qtx.SetRoomMemberRole(ctx, database.SetRoomMemberRoleParams{RoomID: room.ID, UserID: userID, Role: member.Role}); err != nil {
+            return nil, err
+        }
+        result.Members++
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, err
+    }
+    if result.Room, err = s.db.GetRoomByID(ctx, room.ID); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+// bundleUsers looks up the users the bundle names, as members, senders or
+// mentions, and returns the IDs of those with an account here by username.
+func (s *RoomBundleService) bundleUsers(ctx context.Context, bundle RoomBundle) (map[string]uuid.UUID, error) {
+    var usernames []string
+    for _, member := range bundle.Members {
+        usernames = append(usernames, member.Username)
+    }
+    for _, message := range bundle.Messages {
+        usernames = append(usernames, message.Sender)
+        usernames = append(usernames, message.Mentions...)
+    }
+    slices.Sort(usernames)
+    usernames = slices.Compact(usernames)
+
+    users := make(map[string]uuid.UUID, len(usernames))
+    if len(usernames) == 0 {
+        return users, nil
+    }
+    rows, err := s.db.GetUsersByUsernames(ctx, usernames)
+    if err != nil {
+        return nil, err
+    }
+    for _, row := range rows {
+        users[row.Username] = row.ID
+    }
+    return users, nil
+}
+
+// importedMessage builds the message an imported one becomes in roomID.
+// Only mentions of users who are imported as members are kept.
+func importedMessage(roomID uuid.UUID, message RoomBundleMessage, users map[string]uuid.UUID, newIDs map[string]uuid.UUID) (database.CreateMessageParams, error) {
+    metadata := map[string]any{}
+    if len(message.Metadata) > 0 {
+        if err := json.Unmarshal(message.Metadata, &metadata); err != nil || metadata == nil {
+            return database.CreateMessageParams{}, fmt.Errorf("%w: metadata of message %s is not an object", ErrInvalidRoomBundle, message.ID)
+        }
+    }
+    metadata["imported"] = map[string]any{"id": message.ID, "sender": message.Sender, "sent_at": message.CreatedAt}
+    data, err := json.Marshal(metadata)
+    if err != nil {
+        return database.CreateMessageParams{}, err
+    }
+
+    params := database.CreateMessageParams{
+        ID:       uuid.New(),
+        RoomID:   roomID,
+        SenderID: SystemUserID,
+        Content:  message.Content,
+        Metadata: data,
+        Kind:     MessageKindText,
+        Mentions: []uuid.UUID{},
+        Priority: message.Priority,
+    }
+    if senderID, ok := users[message.Sender]; ok {
+        params.SenderID = senderID
+    }
+    if quotedID, ok := newIDs[message.QuotedMessageID]; ok {
+        params.QuotedMessageID = &quotedID
+    }
+    for _, username := range message.Mentions {
+        if userID, ok := users[username]; ok {
+            params.Mentions = append(params.Mentions, userID)
+        }
+    }
+    return params, nil
+}
+
+// validateRoomBundle checks the parts of a bundle that do not depend on the
+// HTTP API's limits, normalizing its tags and filling in defaults.
+func validateRoomBundle(bundle *RoomBundle) error {
+    if bundle.Version != RoomBundleVersion {
+        return fmt.Errorf("%w: version must be %d", ErrInvalidRoomBundle, RoomBundleVersion)
+    }
+    config := &bundle.Room
+    if config.RoomMentionRole == "" {
+        config.RoomMentionRole = RoomRoleModerator
+    }
+    if !ValidRoomRole(config.RoomMentionRole) {
+        return fmt.Errorf("%w: room_mention_role must be member, moderator or owner", ErrInvalidRoomBundle)
+    }
+    tags, err := NormalizeRoomTags(config.Tags)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrInvalidRoomBundle, err)
+    }
+    config.Tags = tags
+    if retention := config.Retention; retention != nil && ((retention.Days != nil && *retention.Days < 1) || (retention.MaxMessages != nil && *retention.MaxMessages < 1)) {
+        return fmt.Errorf("%w: %v", ErrInvalidRoomBundle, ErrInvalidRetention)
+    }
+    for _, role := range []string{config.Permissions.Invite, config.Permissions.Attachments, config.Permissions.Pin} {
+        if role != "" && !ValidRoomRole(role) {
+            return fmt.Errorf("%w: %v", ErrInvalidRoomBundle, ErrInvalidPermissions)
+        }
+    }
+
+    if len(bundle.Members) > MaxBundleMembers {
+        return fmt.Errorf("%w: at most %d members can be imported", ErrInvalidRoomBundle, MaxBundleMembers)
+    }
+    for i, member := range bundle.Members {
+        if member.Username == "" {
+            return fmt.Errorf("%w: members need a username", ErrInvalidRoomBundle)
+        }
+        if member.Role == "" {
+            bundle.Members[i].Role = RoomRoleMember
+        } else if !ValidRoomRole(member.Role) {
+            return fmt.Errorf("%w: member %s has an unknown role", ErrInvalidRoomBundle, member.Username)
+        }
+    }
+
+    if len(bundle.Messages) > MaxBundleMessages {
+        return fmt.Errorf("%w: at most %d messages can be imported", ErrInvalidRoomBundle, MaxBundleMessages)
+    }
+    for i, message := range bundle.Messages {
+        if message.Content == "" {
+            return fmt.Errorf("%w: message %s has no content", ErrInvalidRoomBundle, message.ID)
+        }
+        switch message.Priority {
+        case "":
+            bundle.Messages[i].Priority = MessagePriorityNormal
+        case MessagePriorityNormal, MessagePriorityUrgent:
+        default:
+            return fmt.Errorf("%w: message %s has an unknown priority", ErrInvalidRoomBundle, message.ID)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// MaxRoomTTL is the longest a temporary room can last.
+const MaxRoomTTL = 30 * 24 * time.Hour
+
+// roomExpiryBatch is how many expired rooms are looked up at once.
+const roomExpiryBatch = 100
+
+// RoomExpiryService deletes temporary rooms once they expire.
+type RoomExpiryService struct {
+    db   *database.Queries
+    pool *pgxpool.Pool
+    hub  *Hub
+}
+
+// NewRoomExpiryService creates a new RoomExpiryService.
+func NewRoomExpiryService(db *database.Queries, pool *pgxpool.Pool, hub *Hub) *RoomExpiryService {
+    return &RoomExpiryService{db: db, pool: pool, hub: hub}
+}
+
+// Run deletes expired rooms every interval until ctx is cancelled.
+func (s *RoomExpiryService) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if err := s.DeleteExpired(ctx); err != nil {
+            log.Printf("deleting expired rooms failed: %v", err)
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// DeleteExpired deletes every room whose expiry has passed, as DeleteRoom
+// does, closing its live connections.
+func (s *RoomExpiryService) DeleteExpired(ctx context.Context) error {
+    for {
+        roomIDs, err := s.db.GetExpiredRooms(ctx, roomExpiryBatch)
+        if err != nil {
+            return err
+        }
+        deleted := 0
+        for _, roomID := range roomIDs {
+            if err := DeleteRoom(ctx, s.db, s.pool, s.hub, roomID); err != nil {
+                log.Printf("failed to delete expired room %s: %v", roomID, err)
+                continue
+            }
+            deleted++
+            log.Printf("Deleted expired room %s", roomID)
+        }
+        // Rooms that failed are tried again on the next run.
+        if len(roomIDs) < roomExpiryBatch || deleted == 0 {
+            return nil
+        }
+    }
+}
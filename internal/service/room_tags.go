@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MaxRoomTags is how many tags a room can have.
+const MaxRoomTags = 10
+
+var (
+    // ErrInvalidRoomTag is returned for tags that are not short slugs.
+    ErrInvalidRoomTag = errors.New("tags must be 1-32 lowercase letters, digits or '-', starting with a letter or digit")
+    // ErrTooManyRoomTags is returned when a room is given more than
+    // MaxRoomTags tags.
+    ErrTooManyRoomTags = errors.New("rooms can have at most 10 tags")
+)
+
+// roomTagPattern keeps tags usable as URL query values and directory labels.
+var roomTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{0,31}$`)
+
+// NormalizeRoomTag lowercases and trims a tag and checks that it is valid.
+func NormalizeRoomTag(tag string) (string, error) {
+    tag = strings.ToLower(strings.TrimSpace(tag))
+    if !roomTagPattern.MatchString(tag) {
+        return "", ErrInvalidRoomTag
+    }
+    return tag, nil
+}
+
+// NormalizeRoomTags normalizes a room's tags, dropping duplicates, and
+// returns them sorted.
+func NormalizeRoomTags(tags []string) ([]string, error) {
+    normalized := make([]string, 0, len(tags))
+    for _, tag := range tags {
+        tag, err := NormalizeRoomTag(tag)
+        if err != nil {
+            return nil, err
+        }
+        normalized = append(normalized, tag)
+    }
+    slices.Sort(normalized)
+    normalized = slices.Compact(normalized)
+    if len(normalized) > MaxRoomTags {
+        return nil, ErrTooManyRoomTags
+    }
+    return normalized, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// MaxSearchQueryLength is the longest a message search query can be, in
+// characters.
+const MaxSearchQueryLength = 200
+
+// ErrInvalidSearchQuery is returned for empty or overlong search queries.
+var ErrInvalidSearchQuery = errors.New("q must be 1-200 characters")
+
+// Search returns up to limit of the room's messages visible to the user that
+// match query, best match first, with sender profiles attached. Queries are
+// full-text, web search style: words must all appear, "quoted phrases" appear
+// as written, -word must not appear and "or" matches either side. Emoji must
+// appear too, whether the query writes them as emoji or as shortcodes.
+func (s *MessageService) Search(ctx context.Context, roomID, userID uuid.UUID, query string, limit int32) ([]*Message, error) {
+    q, err := s.parseSearchQuery(query)
+    if err != nil {
+        return nil, err
+    }
+
+    found, err := s.db.SearchRoomMessages(ctx, database.SearchRoomMessagesParams{
+        RoomID:     roomID,
+        Q:          q.text,
+        Emoji:      q.emoji,
+        UserID:     userID,
+        MaxResults: limit,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    rows := make([]database.Message, len(found))
+    senders := make([]*SenderProfile, len(found))
+    for i, row := range found {
+        rows[i] = row.Message
+        senders[i] = s.opts.DisplayNames.Profile(row.SenderUsername, row.SenderDisplayName, row.SenderAvatarUrl)
+    }
+
+    messages, err := s.hydrate(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    for i, message := range messages {
+        message.Sender = senders[i]
+    }
+    return messages, nil
+}
+
+// likeEscaper escapes the characters that LIKE patterns give a meaning to.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns the LIKE pattern, with \ as its escape character,
+// that matches text containing s as written.
+func ContainsPattern(s string) string {
+    return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// searchQuery is a message search query ready to run.
+type searchQuery struct {
+    // text is the full-text query.
+    text string
+    // emoji are the emoji the content must contain, which full-text search
+    // does not index.
+    emoji []string
+}
+
+// parseSearchQuery checks a search query and normalizes its shortcodes as
+// message content is, so that :smile: finds the messages with 😄 in them.
+// The emoji it then holds are matched as written, except those excluded with
+// a leading -, which are left to the full-text query.
+func (s *MessageService) parseSearchQuery(query string) (searchQuery, error) {
+    query = strings.TrimSpace(query)
+    if query == "" || utf8.RuneCountInString(query) > MaxSearchQueryLength {
+        return searchQuery{}, ErrInvalidSearchQuery
+    }
+    if s.opts.EmojiShortcodes {
+        query = NormalizeShortcodes(query)
+    }
+
+    q := searchQuery{text: query}
+    for _, field := range strings.Fields(query) {
+        if strings.HasPrefix(field, "-") {
+            continue
+        }
+        q.emoji = append(q.emoji, emojiRuns(field)...)
+    }
+    return q, nil
+}
+
+// emojiRuns returns the runs of emoji in s: pictographs along with the
+// joiners, variation selectors and skin tone modifiers that combine them.
+func emojiRuns(s string) []string {
+    var runs []string
+    start, pictograph := -1, false
+    for i, r := range s + " " {
+        if r == '\u200d' || r == '\ufe0f' || r >= 0x1f3fb && r <= 0x1f3ff || unicode.Is(unicode.So, r) {
+            if start < 0 {
+                start = i
+            }
+            pictograph = pictograph || unicode.Is(unicode.So, r)
+            continue
+        }
+        if start >= 0 && pictograph {
+            runs = append(runs, s[start:i])
+        }
+        start, pictograph = -1, false
+    }
+    return runs
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+    tests := []struct {
+        query      string
+        shortcodes bool
+        text       string
+        emoji      []string
+    }{
+        {query: " deploy ", shortcodes: true, text: "deploy"},
+        {query: ":smile: deploy", shortcodes: true, text: "😄 deploy", emoji: []string{"😄"}},
+        {query: "😄 deploy", shortcodes: true, text: "😄 deploy", emoji: []string{"😄"}},
+        {query: "ship it:rocket::+1:", shortcodes: true, text: "ship it🚀👍", emoji: []string{"🚀👍"}},
+        {query: "👍🏽 ❄️", shortcodes: true, text: "👍🏽 ❄️", emoji: []string{"👍🏽", "❄️"}},
+        {query: `"fixed the :bug:"`, shortcodes: true, text: `"fixed the 🐛"`, emoji: []string{"🐛"}},
+        {query: "fix -:bug:", shortcodes: true, text: "fix -🐛"},
+        {query: ":smile:", shortcodes: false, text: ":smile:"},
+    }
+    for _, tt := range tests {
+        s := &MessageService{opts: MessageOptions{EmojiShortcodes: tt.shortcodes}}
+        q, err := s.parseSearchQuery(tt.query)
+        if err != nil {
+            t.Fatalf("%q: %v", tt.query, err)
+        }
+        if q.text != tt.text || !reflect.DeepEqual(q.emoji, tt.emoji) {
+            t.Errorf("%q: got %q and emoji %q, want %q and %q", tt.query, q.text, q.emoji, tt.text, tt.emoji)
+        }
+    }
+
+    s := &MessageService{}
+    for _, query := range []string{"", "   "} {
+        if _, err := s.parseSearchQuery(query); err != ErrInvalidSearchQuery {
+            t.Errorf("%q: got %v, want ErrInvalidSearchQuery", query, err)
+        }
+    }
+}
+
+func TestContainsPattern(t *testing.T) {
+    for s, want := range map[string]string{
+        "general":    `%general%`,
+        "100%":       `%100\%%`,
+        "snake_case": `%snake\_case%`,
+        `C:\temp`:    `%C:\\temp%`,
+    } {
+        if got := ContainsPattern(s); got != want {
+            t.Errorf("%q: got %q, want %q", s, got, want)
+        }
+    }
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// UserSettings is the complete, effective configuration of a user's
+// notifications, privacy and do-not-disturb, with defaults applied, so that
+// clients can set up their settings screens from one document. Behavior
+// users cannot change yet is reported as the server applies it.
+type UserSettings struct {
+    // Language is the user's preferred language, which messages from others
+    // are translated into; absent when none is set.
+    Language      *string              `json:"language,omitempty" example:"fr"`
+    Notifications NotificationSettings `json:"notifications"`
+    Privacy       PrivacySettings      `json:"privacy"`
+    DND           DNDSettings          `json:"dnd"`
+}
+
+// NotificationSettings describes what the user is notified about.
+type NotificationSettings struct {
+    Push PushSettings `json:"push"`
+    Feed FeedSettings `json:"feed"`
+    // DefaultRoomLevel applies to every room not listed in Rooms, which
+    // holds the rooms the user chose a notification level for.
+    DefaultRoomLevel string                     `json:"default_room_level" example:"mentions"`
+    Rooms            []RoomNotificationSettings `json:"rooms"`
+}
+
+// PushSettings describes the push notifications sent while the user is not
+// connected, in rooms they have not muted.
+type PushSettings struct {
+    DirectMessages bool `json:"direct_messages" example:"true"`
+    Mentions       bool `json:"mentions" example:"true"`
+    // RoomMentions are @room and @here; those arriving within
+    // RoomMentionWindowSeconds of each other are pushed as one.
+    RoomMentions             bool `json:"room_mentions" example:"true"`
+    RoomMentionWindowSeconds int  `json:"room_mention_window_seconds" example:"30"`
+    // Urgent messages are pushed to every offline member of their room.
+    Urgent bool `json:"urgent" example:"true"`
+    // Preview reports whether notifications show message content. It is
+    // set for the whole server.
+    Preview bool `json:"preview" example:"true"`
+}
+
+// FeedSettings describes what is added to the user's activity feed. Mentions
+// and replies in muted rooms are left out.
+type FeedSettings struct {
+    Mentions bool `json:"mentions" example:"true"`
+    Replies  bool `json:"replies" example:"true"`
+    // Impersonations are the support sessions administrators start as the
+    // user.
+    Impersonations bool `json:"impersonations" example:"true"`
+}
+
+// PrivacySettings describes who else can act on the user's account and what
+// they see of the user.
+type PrivacySettings struct {
+    // SupportAccess is the user's unexpired support access grant; absent
+    // when there is none.
+    SupportAccess *SupportAccess `json:"support_access,omitempty"`
+    // ImpersonationEnabled reports whether administrators can use support
+    // access grants on this server.
+    ImpersonationEnabled bool `json:"impersonation_enabled" example:"false"`
+    // TypingIndicators reports whether others see when the user is typing.
+    TypingIndicators bool `json:"typing_indicators" example:"true"`
+}
+
+// DNDSettings describes the user's do-not-disturb mode. The server has no
+// do-not-disturb mode yet, so it is always off.
+type DNDSettings struct {
+    Enabled bool `json:"enabled" example:"false"`
+}
+
+// SettingsService assembles users' effective settings.
+type SettingsService struct {
+    db            *database.Queries
+    hub           *Hub
+    impersonation *ImpersonationService
+}
+
+// NewSettingsService creates a new SettingsService.
+func NewSettingsService(db *database.Queries, hub *Hub, impersonation *ImpersonationService) *SettingsService {
+    return &SettingsService{db: db, hub: hub, impersonation: impersonation}
+}
+
+// Settings returns the user's effective settings.
+func (s *SettingsService) Settings(ctx context.Context, userID uuid.UUID) (*UserSettings, error) {
+    user, err := s.db.GetUserByID(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    access, err := s.impersonation.Access(ctx, userID)
+    if err != nil && !errors.Is(err, ErrNoSupportAccess) {
+        return nil, err
+    }
+    levels, err := s.db.GetUserNotificationLevels(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    rooms := make([]RoomNotificationSettings, 0, len(levels))
+    for _, level := range levels {
+        rooms = append(rooms, RoomNotificationSettings{RoomID: level.RoomID.String(), Level: level.Level})
+    }
+    typing, err := typingIndicators(ctx, s.db, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    return &UserSettings{
+        Language: user.PreferredLanguage,
+        Notifications: NotificationSettings{
+            Push: PushSettings{
+                DirectMessages:           true,
+                Mentions:                 true,
+                RoomMentions:             true,
+                RoomMentionWindowSeconds: int(roomMentionPushWindow.Seconds()),
+                Urgent:                   true,
+                Preview:                  s.hub.PushTemplates().Preview,
+            },
+            Feed:             FeedSettings{Mentions: true, Replies: true, Impersonations: true},
+            DefaultRoomLevel: DefaultNotificationLevel,
+            Rooms:            rooms,
+        },
+        Privacy: PrivacySettings{
+            SupportAccess:        access,
+            ImpersonationEnabled: s.impersonation.Enabled(),
+            TypingIndicators:     typing,
+        },
+    }, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// ErrMessageNotFound is returned when a message does not exist or is not
+// visible to the user.
+var ErrMessageNotFound = errors.New("message not found")
+
+// StarredMessage is a message in a user's starred list.
+type StarredMessage struct {
+    *Message
+    StarredAt time.Time `json:"starred_at"`
+}
+
+// StarMessage adds a message to the user's starred list. Starring a message
+// twice is not an error.
+func (s *MessageService) StarMessage(ctx context.Context, userID, messageID uuid.UUID) error {
+    if _, err := s.visibleMessage(ctx, userID, messageID); err != nil {
+        return err
+    }
+    return s.db.StarMessage(ctx, database.StarMessageParams{UserID: userID, MessageID: messageID})
+}
+
+// UnstarMessage removes a message from the user's starred list.
+func (s *MessageService) UnstarMessage(ctx context.Context, userID, messageID uuid.UUID) error {
+    return s.db.UnstarMessage(ctx, database.UnstarMessageParams{UserID: userID, MessageID: messageID})
+}
+
+// GetStarredMessages returns up to limit of the user's starred messages across
+// all rooms they belong to, most recently starred first. When cursorStarredAt
+// is non-nil only messages starred before (cursorStarredAt, cursorID) are returned.
+func (s *MessageService) GetStarredMessages(ctx context.Context, userID uuid.UUID, limit int32, cursorStarredAt *time.Time, cursorID *uuid.UUID) ([]*StarredMessage, error) {
+    starred, err := s.db.GetStarredMessages(ctx, database.GetStarredMessagesParams{
+        UserID:          userID,
+        CursorStarredAt: cursorStarredAt,
+        CursorID:        cursorID,
+        MaxResults:      limit,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    rows := make([]database.Message, len(starred))
+    for i, row := range starred {
+        rows[i] = row.Message
+    }
+
+    messages, err := s.hydrate(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    result := make([]*StarredMessage, len(messages))
+    for i, message := range messages {
+        result[i] = &StarredMessage{Message: message, StarredAt: starred[i].StarredAt}
+    }
+    return result, nil
+}
+
+// visibleMessage loads a message the user can see: one in a room they belong
+// to that is either public or addressed to or sent by them.
+func (s *MessageService) visibleMessage(ctx context.Context, userID, messageID uuid.UUID) (database.Message, error) {
+    message, err := s.db.GetMessageByID(ctx, messageID)
+    if err != nil {
+        return database.Message{}, ErrMessageNotFound
+    }
+    if message.RecipientID != nil && *message.RecipientID != userID && message.SenderID != userID {
+        return database.Message{}, ErrMessageNotFound
+    }
+    isMember, err := s.db.IsRoomMember(ctx, database.IsRoomMemberParams{RoomID: message.RoomID, UserID: userID})
+    if err != nil {
+        return database.Message{}, err
+    }
+    if !isMember {
+        return database.Message{}, ErrMessageNotFound
+    }
+    return message, nil
+}
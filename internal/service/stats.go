@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // statsWindowDays is how far back activity and top senders are counted.
+    statsWindowDays = 30
+    // statsStreakDays is how far back streaks are looked for.
+    statsStreakDays = 365
+    // statsTopSenders is how many of the most active members are listed.
+    statsTopSenders = 5
+)
+
+// MaxDailyStatsDays is the longest range of days that daily stats are
+// returned for at once.
+const MaxDailyStatsDays = 366
+
+// ErrStatsNotReady is returned for rooms whose stats have not been computed
+// since they opted in.
+var ErrStatsNotReady = errors.New("stats have not been computed yet")
+
+// RoomStats summarizes a room's recent activity. Direct messages and bots are
+// left out.
+type RoomStats struct {
+    ComputedAt time.Time `json:"computed_at"`
+    // WindowDays is how many days Messages, BusiestHour and TopSenders cover.
+    WindowDays int   `json:"window_days" example:"30"`
+    Messages   int64 `json:"messages" example:"1234"`
+    // BusiestHour is the hour of the day, in UTC, with the most messages;
+    // absent when the room had none.
+    BusiestHour *int `json:"busiest_hour,omitempty" example:"14"`
+    // CurrentStreakDays counts the consecutive UTC days with messages up to
+    // today, or yesterday when nobody has written yet today.
+    CurrentStreakDays int `json:"current_streak_days" example:"12"`
+    // LongestStreakDays is the longest run of such days in the last year.
+    LongestStreakDays int           `json:"longest_streak_days" example:"40"`
+    TopSenders        []StatsSender `json:"top_senders"`
+}
+
+// StatsSender is one of a room's most active members.
+type StatsSender struct {
+    UserID   string `json:"user_id"`
+    Username string `json:"username"`
+    Messages int64  `json:"messages" example:"321"`
+}
+
+// DailyStats is a room's activity on one UTC day.
+type DailyStats struct {
+    Day           time.Time
+    Messages      int64
+    ActiveMembers int32
+}
+
+// StatsService computes engagement stats for the rooms that opted in.
+type StatsService struct {
+    db *database.Queries
+}
+
+// NewStatsService creates a new StatsService.
+func NewStatsService(db *database.Queries) *StatsService {
+    return &StatsService{db: db}
+}
+
+// Run recomputes the stats of every room that opted in every interval until
+// ctx is cancelled.
+func (s *StatsService) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if err := s.ComputeAll(ctx); err != nil {
+            log.Printf("stats aggregation failed: %v", err)
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// ComputeAll recomputes and stores the stats of every room that opted in and
+// is not archived.
+func (s *StatsService) ComputeAll(ctx context.Context) error {
+    roomIDs, err := s.db.GetStatsEnabledRooms(ctx)
+    if err != nil {
+        return err
+    }
+    for _, roomID := range roomIDs {
+        if err := s.computeRoom(ctx, roomID); err != nil {
+            log.Printf("stats aggregation of room %s failed: %v", roomID, err)
+        }
+    }
+    return nil
+}
+
+// Stats returns the room's stats as of the last aggregation.
+func (s *StatsService) Stats(ctx context.Context, roomID uuid.UUID) (*RoomStats, error) {
+    row, err := s.db.GetRoomStats(ctx, roomID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrStatsNotReady
+    }
+    if err != nil {
+        return nil, err
+    }
+    var stats RoomStats
+    if err := json.Unmarshal(row.Stats, &stats); err != nil {
+        return nil, err
+    }
+    stats.ComputedAt = row.ComputedAt
+    return &stats, nil
+}
+
+// DailyStats returns the room's daily stats from the UTC day of from through
+// that of to, oldest first. Days without messages, or from before the room
+// opted in, have zero counts.
+func (s *StatsService) DailyStats(ctx context.Context, roomID uuid.UUID, from, to time.Time) ([]DailyStats, error) {
+    from, to = utcDay(from), utcDay(to)
+    rows, err := s.db.GetRoomDailyStats(ctx, database.GetRoomDailyStatsParams{RoomID: roomID, StartDay: from, EndDay: to})
+    if err != nil {
+        return nil, err
+    }
+    var days []DailyStats
+    for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+        stats := DailyStats{Day: day}
+        if len(rows) > 0 && utcDay(rows[0].Day).Equal(day) {
+            stats.Messages, stats.ActiveMembers = rows[0].Messages, rows[0].ActiveMembers
+            rows = rows[1:]
+        }
+        days = append(days, stats)
+    }
+    return days, nil
+}
+
+// computeRoom aggregates one room's stats and stores them.
+func (s *StatsService) computeRoom(ctx context.Context, roomID uuid.UUID) error {
+    now := time.Now().UTC()
+    // The daily series only goes back a year when a room first opts in.
+    if _, err := s.db.UpsertRoomDailyStats(ctx, database.UpsertRoomDailyStatsParams{RoomID: roomID, Earliest: now.AddDate(0, 0, -statsStreakDays)}); err != nil {
+        return err
+    }
+    since := now.AddDate(0, 0, -statsWindowDays)
+    stats := RoomStats{WindowDays: statsWindowDays, TopSenders: []StatsSender{}}
+
+    hours, err := s.db.GetRoomHourlyActivity(ctx, database.GetRoomHourlyActivityParams{RoomID: roomID, Since: since})
+    if err != nil {
+        return err
+    }
+    var busiest int64
+    for _, h := range hours {
+        stats.Messages += h.Messages
+        if h.Messages > busiest {
+            busiest = h.Messages
+            hour := int(h.Hour)
+            stats.BusiestHour = &hour
+        }
+    }
+
+    days, err := s.db.GetRoomActiveDays(ctx, database.GetRoomActiveDaysParams{RoomID: roomID, Since: now.AddDate(0, 0, -statsStreakDays)})
+    if err != nil {
+        return err
+    }
+    stats.CurrentStreakDays, stats.LongestStreakDays = streaks(days, now)
+
+    senders, err := s.db.GetRoomTopSenders(ctx, database.GetRoomTopSendersParams{RoomID: roomID, Since: since, MaxResults: statsTopSenders})
+    if err != nil {
+        return err
+    }
+    for _, sender := range senders {
+        stats.TopSenders = append(stats.TopSenders, StatsSender{
+            UserID:   sender.SenderID.String(),
+            Username: sender.Username,
+            Messages: sender.Messages,
+        })
+    }
+
+    encoded, err := json.Marshal(stats)
+    if err != nil {
+        return err
+    }
+    return s.db.UpsertRoomStats(ctx, database.UpsertRoomStatsParams{RoomID: roomID, Stats: encoded})
+}
+
+// streaks returns the current and longest runs of consecutive days among
+// days, which are distinct and latest first. The current run has to reach
+// today or yesterday.
+func streaks(days []time.Time, now time.Time) (current, longest int) {
+    run, first := 0, 0
+    for i := range days {
+        if i > 0 && utcDay(days[i-1]).Sub(utcDay(days[i])) != 24*time.Hour {
+            if first == 0 {
+                first = run
+            }
+            run = 0
+        }
+        run++
+        longest = max(longest, run)
+    }
+    if first == 0 {
+        first = run
+    }
+    if len(days) > 0 && utcDay(now).Sub(utcDay(days[0])) <= 24*time.Hour {
+        current = first
+    }
+    return current, longest
+}
+
+// utcDay returns the start of t's day in UTC.
+func utcDay(t time.Time) time.Time {
+    t = t.UTC()
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
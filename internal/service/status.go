@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// EventPresenceStatus is the type of the event sent to the rooms a user is a
+// member of when they set or clear their custom status.
+const EventPresenceStatus = "presence.status"
+
+// MaxStatusTextLength is the longest a status text can be, in characters.
+const MaxStatusTextLength = 100
+
+var (
+    // ErrInvalidStatus is returned for statuses with an emoji that is not a
+    // single emoji or shortcode, or with overlong text.
+    ErrInvalidStatus = errors.New("a status needs an emoji that is a single emoji or a known :shortcode:, and text of at most 100 characters")
+    // ErrStatusExpiry is returned for statuses that would expire right away.
+    ErrStatusExpiry = errors.New("expires_at must be in the future")
+)
+
+// Status is a custom status a user shows beside their presence, such as an
+// emoji and "In a meeting".
+type Status struct {
+    Emoji string `json:"emoji,omitempty" example:"📅"`
+    Text  string `json:"text,omitempty" example:"In a meeting"`
+    // ExpiresAt is when the status is cleared; absent when it stays until
+    // the user changes it.
+    ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-09-04T06:00:00Z"`
+    UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// StatusUpdate carries a user's status in presence.status events. Status is
+// null once it has been cleared.
+type StatusUpdate struct {
+    UserID string  `json:"user_id"`
+    Status *Status `json:"status"`
+}
+
+// StatusService manages users' custom statuses.
+type StatusService struct {
+    db  *database.Queries
+    hub *Hub
+}
+
+// NewStatusService creates a new StatusService.
+func NewStatusService(db *database.Queries, hub *Hub) *StatusService {
+    return &StatusService{db: db, hub: hub}
+}
+
+// NormalizeStatus trims a status and checks its emoji, text and expiry. An
+// empty emoji and text together clear the status.
+func NormalizeStatus(emoji, text string, expiresAt *time.Time) (string, string, error) {
+    text = strings.TrimSpace(text)
+    if utf8.RuneCountInString(text) > MaxStatusTextLength {
+        return "", "", ErrInvalidStatus
+    }
+    if emoji = strings.TrimSpace(emoji); emoji != "" {
+        var err error
+        if emoji, err = normalizeReaction(emoji); err != nil {
+            return "", "", ErrInvalidStatus
+        }
+    }
+    if expiresAt != nil && !expiresAt.After(time.Now()) {
+        return "", "", ErrStatusExpiry
+    }
+    return emoji, text, nil
+}
+
+// SetStatus sets the user's status, replacing the current one, and sends it
+// to the rooms they are a member of. emoji and text must have been
+// normalized; when both are empty the status is cleared and nil is returned.
+// Nothing is sent when a status expires; clients hide it at its expires_at.
+func (s *StatusService) SetStatus(ctx context.Context, userID uuid.UUID, emoji, text string, expiresAt *time.Time) (*Status, error) {
+    var status *Status
+    if emoji == "" && text == "" {
+        if err := s.db.DeleteUserStatus(ctx, userID); err != nil {
+            return nil, err
+        }
+    } else {
+        row, err := s.db.SetUserStatus(ctx, database.SetUserStatusParams{
+            UserID:    userID,
+            Emoji:     emoji,
+            Text:      text,
+            ExpiresAt: expiresAt,
+        })
+        if err != nil {
+            return nil, err
+        }
+        status = statusFromRow(row)
+    }
+    go s.hub.announceStatus(userID, status)
+    return status, nil
+}
+
+// UserStatuses returns the statuses of those of the users who have one that
+// has not expired, by user.
+func UserStatuses(ctx context.Context, db *database.Queries, userIDs []uuid.UUID) (map[uuid.UUID]*Status, error) {
+    rows, err := db.GetUserStatuses(ctx, userIDs)
+    if err != nil {
+        return nil, err
+    }
+    statuses := make(map[uuid.UUID]*Status, len(rows))
+    for _, row := range rows {
+        statuses[row.UserID] = statusFromRow(row)
+    }
+    return statuses, nil
+}
+
+// announceStatus sends the user's status, or nil once it was cleared, to the
+// connected members of every room they are a member of.
+func (h *Hub) announceStatus(userID uuid.UUID, status *Status) {
+    roomIDs, err := h.messages.db.GetUserRoomIDs(context.Background(), userID)
+    if err != nil {
+        log.Printf("failed to load rooms of %s for status: %v", userID, err)
+        return
+    }
+
+    now := time.Now()
+    for _, roomID := range roomIDs {
+        h.Broadcast(&Message{
+            Type:      EventPresenceStatus,
+            SenderID:  userID.String(),
+            RoomID:    roomID.String(),
+            CreatedAt: now,
+            Status:    &StatusUpdate{UserID: userID.String(), Status: status},
+        })
+    }
+    h.Broadcast(&Message{
+        Type:          EventPresenceStatus,
+        SenderID:      userID.String(),
+        CreatedAt:     now,
+        Status:        &StatusUpdate{UserID: userID.String(), Status: status},
+        toSubscribers: true,
+    })
+}
+
+func statusFromRow(row database.UserStatus) *Status {
+    return &Status{
+        Emoji:     row.Emoji,
+        Text:      row.Text,
+        ExpiresAt: row.ExpiresAt,
+        UpdatedAt: row.UpdatedAt,
+    }
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// summarizeTimeout bounds a single call to the summarization provider.
+const summarizeTimeout = 30 * time.Second
+
+// maxSummaryInputBytes caps how much message content is sent to the provider
+// in one request; longer spans are summarized in several requests.
+const maxSummaryInputBytes = 64 * 1024
+
+// maxCachedSummaries bounds the summary cache; it is reset when full.
+const maxCachedSummaries = 500
+
+var (
+    // ErrSummariesDisabled is returned when no summarization provider is
+    // configured.
+    ErrSummariesDisabled = errors.New("summaries are not enabled")
+    // ErrRoomSummariesDisabled is returned for rooms that have not opted in
+    // to summaries.
+    ErrRoomSummariesDisabled = errors.New("summaries are not enabled for this room")
+    // ErrSummarizerFailed wraps errors from the summarization provider.
+    ErrSummarizerFailed = errors.New("summarization provider failed")
+)
+
+// SummaryMessage is all a summarizer learns about a message: who sent it,
+// when, and what it said.
+type SummaryMessage struct {
+    Sender  string    `json:"sender"`
+    Content string    `json:"content"`
+    SentAt  time.Time `json:"sent_at"`
+}
+
+// Summarizer condenses a span of messages, oldest first, into a short digest.
+type Summarizer interface {
+    Summarize(ctx context.Context, messages []SummaryMessage) (string, error)
+}
+
+// HTTPSummarizer posts the messages to an HTTP API as {"messages": [...]} and
+// expects {"summary": "..."} back.
+type HTTPSummarizer struct {
+    url    string
+    apiKey string
+    client *http.Client
+}
+
+// NewHTTPSummarizer creates a summarizer for the API at url. The API key, if
+// any, is sent as a bearer token.
+func NewHTTPSummarizer(url, apiKey string) *HTTPSummarizer {
+    return &HTTPSummarizer{
+        url:    url,
+        apiKey: apiKey,
+        client: &http.Client{Timeout: summarizeTimeout},
+    }
+}
+
+// Summarize sends the messages to the provider.
+func (s *HTTPSummarizer) Summarize(ctx context.Context, messages []SummaryMessage) (string, error) {
+    body, err := json.Marshal(map[string]any{"messages": messages})
+    if err != nil {
+        return "", err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+    if err != nil {
+        return "", err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if s.apiKey != "" {
+        req.Header.Set("Authorization", "Bearer "+s.apiKey)
+    }
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return "", fmt.Errorf("summarization provider returned status %d", resp.StatusCode)
+    }
+
+    var result struct {
+        Summary string `json:"summary"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return "", err
+    }
+    return result.Summary, nil
+}
+
+// RoomSummary is a digest of the messages of a room from FromSeq to ToSeq.
+type RoomSummary struct {
+    RoomID string `json:"room_id"`
+    // FromSeq and ToSeq are the sequence numbers of the first and last
+    // message summarized. When ToSeq is not the latest message of the room,
+    // the rest can be summarized by asking again with since=ToSeq.
+    FromSeq      int64     `json:"from_seq"`
+    ToSeq        int64     `json:"to_seq"`
+    MessageCount int       `json:"message_count"`
+    Summary      string    `json:"summary"`
+    GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// SummaryService produces catch-up summaries of rooms. Only room messages are
+// summarized, never direct messages, and summaries are cached in memory
+// rather than stored.
+type SummaryService struct {
+    messages   *MessageService
+    summarizer Summarizer
+    cache      *summaryCache
+}
+
+// NewSummaryService creates a new SummaryService. Summaries are disabled when
+// summarizer is nil.
+func NewSummaryService(messages *MessageService, summarizer Summarizer) *SummaryService {
+    return &SummaryService{
+        messages:   messages,
+        summarizer: summarizer,
+        cache:      newSummaryCache(),
+    }
+}
+
+// Summarize summarizes the messages of a room after sinceSeq, or after the
+// user's read cursor when sinceSeq is nil. Callers are responsible for
+// checking that the user is a member of the room.
+func (s *SummaryService) Summarize(ctx context.Context, room database.Room, userID uuid.UUID, sinceSeq *int64) (*RoomSummary, error) {
+    if s.summarizer == nil {
+        return nil, ErrSummariesDisabled
+    }
+    if !room.SummariesEnabled {
+        return nil, ErrRoomSummariesDisabled
+    }
+
+    var since int64
+    if sinceSeq != nil {
+        since = *sinceSeq
+    } else {
+        unreads, err := s.messages.roomUnreads(ctx, room.ID, []uuid.UUID{userID})
+        if err != nil {
+            return nil, err
+        }
+        unread, ok := unreads[userID.String()]
+        if !ok {
+            return nil, ErrNotRoomMember
+        }
+        since = unread.LastReadSeq
+    }
+
+    messages, err := s.messages.GetMissedMessages(ctx, room, userID, since, time.Time{})
+    if err != nil {
+        return nil, err
+    }
+
+    summary := &RoomSummary{RoomID: room.ID.String(), FromSeq: since, ToSeq: since}
+    var (
+        span []SummaryMessage
+        size int
+        key  = sha256.New()
+    )
+    for _, message := range messages {
+        // Direct messages stay between their sender and recipient.
+        if message.RecipientID != "" || message.Content == "" {
+            continue
+        }
+        if size+len(message.Content) > maxSummaryInputBytes && len(span) > 0 {
+            break
+        }
+        size += len(message.Content)
+
+        sender := "unknown"
+        if message.Sender != nil {
+            sender = message.Sender.Username
+        }
+        span = append(span, SummaryMessage{Sender: sender, Content: message.Content, SentAt: message.CreatedAt})
+        if len(span) == 1 {
+            summary.FromSeq = message.Seq
+        }
+        summary.ToSeq = message.Seq
+
+        // Edits and deletions change the span, so they are part of its key.
+        key.Write([]byte(message.ID))
+        if message.EditedAt != nil {
+            key.Write([]byte(message.EditedAt.Format(time.RFC3339Nano)))
+        }
+    }
+    summary.MessageCount = len(span)
+    if len(span) == 0 {
+        summary.GeneratedAt = time.Now()
+        return summary, nil
+    }
+
+    cacheKey := room.ID.String() + "|" + strconv.FormatInt(summary.FromSeq, 10) + "|" + strconv.FormatInt(summary.ToSeq, 10) + "|" + hex.EncodeToString(key.Sum(nil))
+    if cached, ok := s.cache.get(cacheKey); ok {
+        return cached, nil
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, summarizeTimeout)
+    defer cancel()
+    text, err := s.summarizer.Summarize(ctx, span)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrSummarizerFailed, err)
+    }
+    summary.Summary = text
+    summary.GeneratedAt = time.Now()
+    s.cache.put(cacheKey, summary)
+    return summary, nil
+}
+
+// summaryCache remembers summaries per span of messages, so members catching
+// up on the same span share one provider call.
+type summaryCache struct {
+    mu      sync.Mutex
+    entries map[string]*RoomSummary
+}
+
+func newSummaryCache() *summaryCache {
+    return &summaryCache{entries: make(map[string]*RoomSummary)}
+}
+
+func (c *summaryCache) get(key string) (*RoomSummary, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    summary, ok := c.entries[key]
+    return summary, ok
+}
+
+func (c *summaryCache) put(key string, summary *RoomSummary) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if len(c.entries) >= maxCachedSummaries {
+        c.entries = make(map[string]*RoomSummary)
+    }
+    c.entries[key] = summary
+}
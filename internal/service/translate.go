@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// translateTimeout bounds a single call to the translation provider.
+const translateTimeout = 5 * time.Second
+
+// maxCachedTranslations bounds the translation cache; it is reset when full.
+const maxCachedTranslations = 1000
+
+// Translator translates text into a target language.
+type Translator interface {
+    Translate(ctx context.Context, text, targetLanguage string) (string, error)
+}
+
+// HTTPTranslator calls a LibreTranslate-compatible HTTP API.
+type HTTPTranslator struct {
+    url    string
+    apiKey string
+    client *http.Client
+}
+
+// NewHTTPTranslator creates a translator for the API at url.
+func NewHTTPTranslator(url, apiKey string) *HTTPTranslator {
+    return &HTTPTranslator{
+        url:    url,
+        apiKey: apiKey,
+        client: &http.Client{Timeout: translateTimeout},
+    }
+}
+
+// Translate sends text to the provider, letting it detect the source language.
+func (t *HTTPTranslator) Translate(ctx context.Context, text, targetLanguage string) (string, error) {
+    body, err := json.Marshal(map[string]string{
+        "q":       text,
+        "source":  "auto",
+        "target":  targetLanguage,
+        "format":  "text",
+        "api_key": t.apiKey,
+    })
+    if err != nil {
+        return "", err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+    if err != nil {
+        return "", err
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := t.client.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return "", fmt.Errorf("translation provider returned status %d", resp.StatusCode)
+    }
+
+    var result struct {
+        TranslatedText string `json:"translatedText"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return "", err
+    }
+    return result.TranslatedText, nil
+}
+
+// translationCache remembers translations per message and language, so a
+// message is translated once no matter how many recipients share a language.
+type translationCache struct {
+    mu      sync.Mutex
+    entries map[string]string
+}
+
+func newTranslationCache() *translationCache {
+    return &translationCache{entries: make(map[string]string)}
+}
+
+func (c *translationCache) get(messageID, language string) (string, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    text, ok := c.entries[messageID+"|"+language]
+    return text, ok
+}
+
+func (c *translationCache) put(messageID, language, text string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if len(c.entries) >= maxCachedTranslations {
+        c.entries = make(map[string]string)
+    }
+    c.entries[messageID+"|"+language] = text
+}
+
+// translateFor returns a copy of message translated into language for a
+// single recipient. The original message is returned unchanged when no
+// translation is needed or the provider fails.
+func (h *Hub) translateFor(message *Message, language string) *Message {
+    if h.translator == nil || language == "" || message.Content == "" || message.Kind == MessageKindEncrypted {
+        return message
+    }
+
+    // Edited messages are cached under their edit time so that the new
+    // content is translated afresh.
+    cacheKey := message.ID
+    if message.EditedAt != nil {
+        cacheKey += "@" + message.EditedAt.Format(time.RFC3339Nano)
+    }
+    translated, ok := h.translations.get(cacheKey, language)
+    if !ok {
+        ctx, cancel := context.WithTimeout(context.Background(), translateTimeout)
+        defer cancel()
+
+        var err error
+        translated, err = h.translator.Translate(ctx, message.Content, language)
+        if err != nil {
+            log.Printf("translation to %s failed: %v", language, err)
+            return message
+        }
+        h.translations.put(cacheKey, language, translated)
+    }
+
+    copied := *message
+    copied.OriginalContent = message.Content
+    copied.Content = translated
+    copied.Language = language
+    return &copied
+}
@@ -0,0 +1,94 @@
+package service
+
+import "time"
+
+const (
+    // TypingInterval is the most often a user's typing frames are relayed to
+    // a room; those in between only keep the user typing.
+    TypingInterval = 3 * time.Second
+    // TypingTimeout is how long a user is shown typing after their last
+    // typing frame. Clients repeat {"typing": true} while the user types.
+    TypingTimeout = 10 * time.Second
+)
+
+// typingKey identifies a user typing in a room.
+type typingKey struct {
+    roomID string
+    userID string
+}
+
+// typingState tracks a user shown typing in a room.
+type typingState struct {
+    // relayedAt is when their last typing frame was relayed.
+    relayedAt time.Time
+    // expiry stops them typing after TypingTimeout; generation tells the
+    // stop it sends apart from those of timers already replaced.
+    expiry     *time.Timer
+    generation uint64
+}
+
+// routeTyping relays typing frames to the rest of their room, at most one per
+// user every TypingInterval. A user stays typing until they send
+// {"typing": false}, a message or no typing frame for TypingTimeout, or their
+// last connection to the room closes, and the room is then sent
+// {"typing": false} once. It runs on the hub goroutine.
+func (h *Hub) routeTyping(message *Message) {
+    key := typingKey{roomID: message.RoomID, userID: message.SenderID}
+    state := h.typing[key]
+
+    if message.typingExpiry != 0 {
+        if state != nil && state.generation == message.typingExpiry {
+            delete(h.typing, key)
+            h.fanOut(message, message.SenderID)
+        }
+        return
+    }
+    if message.Typing == nil || !message.Typing.Typing {
+        h.stopTyping(message.RoomID, message.SenderID)
+        return
+    }
+
+    if state == nil {
+        state = &typingState{}
+        h.typing[key] = state
+    } else {
+        state.expiry.Stop()
+    }
+    h.typingGeneration++
+    generation := h.typingGeneration
+    state.generation = generation
+    state.expiry = time.AfterFunc(TypingTimeout, func() {
+        h.broadcast <- typingStopped(key, generation)
+    })
+
+    if now := time.Now(); now.Sub(state.relayedAt) >= TypingInterval {
+        state.relayedAt = now
+        h.fanOut(message, message.SenderID)
+    }
+}
+
+// stopTyping sends the room {"typing": false} for the user if they are shown
+// typing in it. It runs on the hub goroutine.
+func (h *Hub) stopTyping(roomID, userID string) {
+    key := typingKey{roomID: roomID, userID: userID}
+    state := h.typing[key]
+    if state == nil {
+        return
+    }
+    state.expiry.Stop()
+    delete(h.typing, key)
+    h.fanOut(typingStopped(key, 0), userID)
+}
+
+// typingStopped reports that a user stopped typing. A non-zero generation
+// marks the stop sent when that expiry timer fires.
+func typingStopped(key typingKey, generation uint64) *Message {
+    return &Message{
+        Type:         EventTyping,
+        SenderID:     key.userID,
+        RoomID:       key.roomID,
+        CreatedAt:    time.Now(),
+        Typing:       &Typing{UserID: key.userID, Typing: false},
+        typingExpiry: generation,
+    }
+}
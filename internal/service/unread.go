@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// FrameRead is sent by clients to move their read cursor; FrameUnread is sent
+// by the server with a room's updated unread counts.
+const (
+    FrameRead   = "read"
+    FrameUnread = "unread"
+)
+
+// EventUnread is the type of hub traffic carrying unread counts. It is
+// delivered to every connection of its recipient, whichever room it is for.
+const EventUnread = "unread"
+
+// ErrNotRoomMember is returned when marking a room read for, or kicking, a user
+// who is not a member of it.
+var ErrNotRoomMember = errors.New("user is not a member of this room")
+
+// Unread holds a user's unread counts for a room: messages from others after
+// their read cursor, and how many of those mention them.
+type Unread struct {
+    RoomID      string `json:"room_id"`
+    Count       int64  `json:"count"`
+    Mentions    int64  `json:"mentions"`
+    LastReadSeq int64  `json:"last_read_seq"`
+}
+
+// ReadCursor is the payload of a read frame and of read marker updates. It
+// names the last message read either by Seq or by MessageID; when neither is
+// set, everything in the room is marked read.
+type ReadCursor struct {
+    Seq       int64  `json:"seq"`
+    MessageID string `json:"message_id,omitempty"`
+}
+
+// ErrInvalidReadCursor is returned for read cursors with a negative seq or a
+// malformed message ID.
+var ErrInvalidReadCursor = errors.New("read cursor must be {\"seq\": n} or {\"message_id\": id}")
+
+// Unreads returns the user's unread counts for every room they are a member of.
+func (s *MessageService) Unreads(ctx context.Context, userID uuid.UUID) ([]Unread, error) {
+    rows, err := s.db.GetUserUnreads(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    unreads := make([]Unread, 0, len(rows))
+    for _, row := range rows {
+        unreads = append(unreads, Unread{
+            RoomID:      row.RoomID.String(),
+            Count:       row.UnreadCount,
+            Mentions:    row.MentionCount,
+            LastReadSeq: row.LastReadSeq,
+        })
+    }
+    return unreads, nil
+}
+
+// roomUnreads returns the unread counts of the given users for a room, keyed
+// by user ID. Users who are not members of the room are left out.
+func (s *MessageService) roomUnreads(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID) (map[string]Unread, error) {
+    rows, err := s.db.GetRoomUnreads(ctx, database.GetRoomUnreadsParams{RoomID: roomID, UserIds: userIDs})
+    if err != nil {
+        return nil, err
+    }
+    unreads := make(map[string]Unread, len(rows))
+    for _, row := range rows {
+        unreads[row.UserID.String()] = Unread{
+            RoomID:      roomID.String(),
+            Count:       row.UnreadCount,
+            Mentions:    row.MentionCount,
+            LastReadSeq: row.LastReadSeq,
+        }
+    }
+    return unreads, nil
+}
+
+// MarkRead moves the user's read cursor for a room forward to the message the
+// cursor names, or to the latest message when it names none, and sends the
+// new unread counts to all of the user's connections, so every device shows
+// the same marker.
+func (h *Hub) MarkRead(ctx context.Context, roomID, userID uuid.UUID, cursor ReadCursor) (Unread, error) {
+    seq, err := h.readSeq(ctx, roomID, cursor)
+    if err != nil {
+        return Unread{}, err
+    }
+    _, err = h.messages.db.MarkRoomRead(ctx, database.MarkRoomReadParams{Seq: seq, RoomID: roomID, UserID: userID})
+    if errors.Is(err, pgx.ErrNoRows) {
+        return Unread{}, ErrNotRoomMember
+    }
+    if err != nil {
+        return Unread{}, err
+    }
+
+    unreads, err := h.messages.roomUnreads(ctx, roomID, []uuid.UUID{userID})
+    if err != nil {
+        return Unread{}, err
+    }
+    unread := unreads[userID.String()]
+    h.Broadcast(unreadEvent(userID.String(), unread))
+    return unread, nil
+}
+
+// readSeq resolves a read cursor to the sequence number it names, or nil for
+// the latest message.
+func (h *Hub) readSeq(ctx context.Context, roomID uuid.UUID, cursor ReadCursor) (*int64, error) {
+    if cursor.Seq < 0 {
+        return nil, ErrInvalidReadCursor
+    }
+    if cursor.MessageID != "" {
+        messageID, err := uuid.Parse(cursor.MessageID)
+        if err != nil {
+            return nil, ErrInvalidReadCursor
+        }
+        message, err := h.messages.db.GetMessageByID(ctx, messageID)
+        if errors.Is(err, pgx.ErrNoRows) || (err == nil && message.RoomID != roomID) {
+            return nil, ErrMessageNotFound
+        }
+        if err != nil {
+            return nil, err
+        }
+        return &message.Seq, nil
+    }
+    if cursor.Seq > 0 {
+        return &cursor.Seq, nil
+    }
+    return nil, nil
+}
+
+// pushUnreads sends updated unread counts for the message's room to the
+// connected users it may have changed them for.
+func (h *Hub) pushUnreads(message *Message, userIDs []string) {
+    roomID, err := uuid.Parse(message.RoomID)
+    if err != nil {
+        return
+    }
+    ids := make([]uuid.UUID, 0, len(userIDs))
+    for _, userID := range userIDs {
+        if id, err := uuid.Parse(userID); err == nil {
+            ids = append(ids, id)
+        }
+    }
+
+    unreads, err := h.messages.roomUnreads(context.Background(), roomID, ids)
+    if err != nil {
+        log.Printf("failed to count unread messages in room %s: %v", message.RoomID, err)
+        return
+    }
+    for userID, unread := range unreads {
+        h.Broadcast(unreadEvent(userID, unread))
+    }
+}
+
+// unreadEvent carries a user's unread counts for a room.
+func unreadEvent(userID string, unread Unread) *Message {
+    return &Message{
+        Type:        EventUnread,
+        RecipientID: userID,
+        RoomID:      unread.RoomID,
+        CreatedAt:   time.Now(),
+        Unread:      &unread,
+    }
+}
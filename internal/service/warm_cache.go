@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+const (
+    // DefaultWarmCacheRooms is how many of the busiest rooms are preloaded at
+    // startup.
+    DefaultWarmCacheRooms = 100
+    // warmCacheTTL is how long preloaded rooms are served from memory: long
+    // enough for the clients dropped by a deploy to reconnect.
+    warmCacheTTL = 10 * time.Minute
+    // warmCacheActivity is how far back rooms must have had messages to count
+    // as busy.
+    warmCacheActivity = 7 * 24 * time.Hour
+)
+
+// WarmCache holds the members and latest messages of the busiest rooms,
+// loaded at startup so that the wave of clients reconnecting after a deploy
+// is checked and caught up from memory instead of each querying Postgres.
+//
+// Entries are checked against the room they are used for: members only while
+// its member version is the one loaded, messages only while no message was
+// sent to it since. Edits and deletions made on this server drop the room's
+// messages; those made on other servers are missed until the entry expires
+// after warmCacheTTL. Entries are never loaded again once dropped or expired.
+//
+// It can also hold the preferred languages of the users expected to
+// reconnect. A language changed on this server drops the user's entry; one
+// changed on another server is missed until the entry expires.
+type WarmCache struct {
+    db *database.Queries
+
+    mu    sync.RWMutex
+    rooms map[uuid.UUID]*warmRoom
+    users map[uuid.UUID]*warmUser
+}
+
+type warmRoom struct {
+    expires       time.Time
+    memberVersion int64
+    members       map[uuid.UUID]bool
+    // lastMessageAt is the room's as of messages; nil once messages are
+    // dropped.
+    lastMessageAt *time.Time
+    // messages are the room's latest maxReplayMessages, oldest first, direct
+    // messages included.
+    messages []database.Message
+}
+
+type warmUser struct {
+    expires  time.Time
+    language string
+}
+
+// NewWarmCache creates a new, empty WarmCache.
+func NewWarmCache(db *database.Queries) *WarmCache {
+    return &WarmCache{db: db, rooms: make(map[uuid.UUID]*warmRoom), users: make(map[uuid.UUID]*warmUser)}
+}
+
+// Preload loads the rooms busiest over the last week, by their daily stats,
+// until maxRooms rooms are loaded, and returns how many it loaded. Rooms that
+// fail to load are left out.
+func (c *WarmCache) Preload(ctx context.Context, maxRooms int) (int, error) {
+    roomIDs, err := c.db.GetHotRooms(ctx, database.GetHotRoomsParams{
+        Since:    time.Now().Add(-warmCacheActivity),
+        MaxRooms: int32(maxRooms),
+    })
+    if err != nil {
+        return 0, err
+    }
+    return c.PreloadRooms(ctx, roomIDs, maxRooms)
+}
+
+// PreloadRooms loads the given rooms, in order and skipping those already
+// loaded, until maxRooms rooms are loaded, and returns how many it loaded.
+// Rooms that fail to load are left out.
+func (c *WarmCache) PreloadRooms(ctx context.Context, roomIDs []uuid.UUID, maxRooms int) (int, error) {
+    loaded := 0
+    for _, roomID := range roomIDs {
+        c.mu.RLock()
+        full, present := len(c.rooms) >= maxRooms, c.rooms[roomID] != nil
+        c.mu.RUnlock()
+        if full {
+            break
+        }
+        if present {
+            continue
+        }
+        room, err := c.load(ctx, roomID)
+        if err != nil {
+            if ctx.Err() != nil {
+                return loaded, ctx.Err()
+            }
+            continue
+        }
+        c.mu.Lock()
+        c.rooms[roomID] = room
+        c.mu.Unlock()
+        loaded++
+    }
+    return loaded, nil
+}
+
+// PreloadUsers loads the preferred languages of the given users, such as
+// those connected when the server last shut down, and returns how many it
+// loaded.
+func (c *WarmCache) PreloadUsers(ctx context.Context, userIDs []uuid.UUID) (int, error) {
+    rows, err := c.db.GetUserLanguages(ctx, userIDs)
+    if err != nil {
+        return 0, err
+    }
+    expires := time.Now().Add(warmCacheTTL)
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for _, row := range rows {
+        user := &warmUser{expires: expires}
+        if row.PreferredLanguage != nil {
+            user.language = *row.PreferredLanguage
+        }
+        c.users[row.ID] = user
+    }
+    return len(rows), nil
+}
+
+// load reads a room's members and latest messages. The room is read first, so
+// that changes made while the rest is read make the entry look stale rather
+// than current.
+func (c *WarmCache) load(ctx context.Context, roomID uuid.UUID) (*warmRoom, error) {
+    room, err := c.db.GetRoomByID(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    memberIDs, err := c.db.GetRoomMemberIDs(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    latest, err := c.db.GetRecentRoomMessages(ctx, database.GetRecentRoomMessagesParams{
+        RoomID:      roomID,
+        MaxMessages: maxReplayMessages,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    members := make(map[uuid.UUID]bool, len(memberIDs))
+    for _, userID := range memberIDs {
+        members[userID] = true
+    }
+    // The query returns newest first.
+    messages := make([]database.Message, len(latest))
+    for i, row := range latest {
+        messages[len(latest)-1-i] = row
+    }
+    lastMessageAt := room.LastMessageAt
+    if lastMessageAt == nil {
+        lastMessageAt = &time.Time{}
+    }
+    return &warmRoom{
+        expires:       time.Now().Add(warmCacheTTL),
+        memberVersion: room.MemberVersion,
+        members:       members,
+        lastMessageAt: lastMessageAt,
+        messages:      messages,
+    }, nil
+}
+
+// get returns the room's entry, or nil if it has none or it expired.
+func (c *WarmCache) get(roomID uuid.UUID) *warmRoom {
+    if c == nil {
+        return nil
+    }
+    c.mu.RLock()
+    entry := c.rooms[roomID]
+    c.mu.RUnlock()
+    if entry == nil || time.Now().After(entry.expires) {
+        return nil
+    }
+    return entry
+}
+
+// IsMember reports whether the user is known to be a member of the room, as
+// IsRoomMember would. False means the cache cannot tell, not that they are
+// not a member.
+func (c *WarmCache) IsMember(room database.Room, userID uuid.UUID) bool {
+    entry := c.get(room.ID)
+    return entry != nil && entry.memberVersion == room.MemberVersion && entry.members[userID]
+}
+
+// missedMessages returns the messages of the room visible to the user after
+// lastSeenSeq, or sent after since when lastSeenSeq is 0, oldest first and at
+// most maxReplayMessages, as GetMissedMessages would. ok is false when the
+// cache cannot answer, because it has no current entry for the room or does
+// not reach back far enough.
+func (c *WarmCache) missedMessages(room database.Room, userID uuid.UUID, lastSeenSeq int64, since time.Time) (rows []database.Message, ok bool) {
+    entry := c.get(room.ID)
+    if entry == nil {
+        return nil, false
+    }
+
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    lastMessageAt := room.LastMessageAt
+    if lastMessageAt == nil {
+        lastMessageAt = &time.Time{}
+    }
+    if entry.lastMessageAt == nil || !entry.lastMessageAt.Equal(*lastMessageAt) {
+        return nil, false
+    }
+    // Unless it holds fewer messages than it loads, which is all the room
+    // has, the cache must hold the last message seen or an older one.
+    if len(entry.messages) == maxReplayMessages {
+        oldest := entry.messages[0]
+        if lastSeenSeq > 0 && oldest.Seq > lastSeenSeq {
+            return nil, false
+        }
+        if lastSeenSeq == 0 && oldest.CreatedAt.After(since) {
+            return nil, false
+        }
+    }
+
+    rows = []database.Message{}
+    for _, message := range entry.messages {
+        if lastSeenSeq > 0 && message.Seq <= lastSeenSeq || lastSeenSeq == 0 && !message.CreatedAt.After(since) {
+            continue
+        }
+        if message.RecipientID != nil && *message.RecipientID != userID && message.SenderID != userID {
+            continue
+        }
+        rows = append(rows, message)
+        if len(rows) == maxReplayMessages {
+            break
+        }
+    }
+    return rows, true
+}
+
+// dropMessages forgets the room's messages after they changed.
+func (c *WarmCache) dropMessages(roomID uuid.UUID) {
+    if c == nil {
+        return
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if entry := c.rooms[roomID]; entry != nil {
+        entry.lastMessageAt = nil
+        entry.messages = nil
+    }
+}
+
+// Language returns the user's preferred language, empty when they have none,
+// as GetUserByID would. ok is false when the cache cannot tell.
+func (c *WarmCache) Language(userID uuid.UUID) (language string, ok bool) {
+    if c == nil {
+        return "", false
+    }
+    c.mu.RLock()
+    user := c.users[userID]
+    c.mu.RUnlock()
+    if user == nil || time.Now().After(user.expires) {
+        return "", false
+    }
+    return user.language, true
+}
+
+// DropUser forgets the user's preferred language after it changed.
+func (c *WarmCache) DropUser(userID uuid.UUID) {
+    if c == nil {
+        return
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    delete(c.users, userID)
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// Event types room webhooks can subscribe to.
+const (
+    WebhookEventMessage = "message"
+    WebhookEventJoin    = "join"
+    WebhookEventLeave   = "leave"
+    WebhookEventBan     = "ban"
+    WebhookEventPin     = "pin"
+)
+
+// webhookEvents lists the valid event types in documentation order.
+var webhookEvents = []string{WebhookEventMessage, WebhookEventJoin, WebhookEventLeave, WebhookEventBan, WebhookEventPin}
+
+// webhookTimeout bounds a single webhook delivery.
+const webhookTimeout = 5 * time.Second
+
+// JobTypeWebhookDelivery is the type of the jobs that post an event to a
+// webhook.
+const JobTypeWebhookDelivery = "webhook.delivery"
+
+// Headers sent with every webhook delivery.
+const (
+    WebhookEventHeader     = "X-Webhook-Event"
+    WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var (
+    // ErrInvalidWebhookURL is returned for webhook URLs that are not absolute
+    // http or https URLs.
+    ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https URL")
+    // ErrInvalidWebhookEvents is returned when a webhook subscribes to no
+    // events or to an unknown one.
+    ErrInvalidWebhookEvents = errors.New("webhook events must be one or more of message, join, leave, ban and pin")
+)
+
+// Webhook is an outbound webhook of a room. Secret is only set when the
+// webhook is created.
+type Webhook struct {
+    ID        string    `json:"id"`
+    RoomID    string    `json:"room_id"`
+    URL       string    `json:"url" example:"https://example.com/hooks/chat"`
+    Events    []string  `json:"events" example:"join,leave"`
+    Secret    string    `json:"secret,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is the JSON body posted to a webhook.
+type WebhookDelivery struct {
+    ID        string    `json:"id"`
+    Event     string    `json:"event"`
+    RoomID    string    `json:"room_id"`
+    Timestamp time.Time `json:"timestamp"`
+    Data      any       `json:"data"`
+}
+
+// MembershipChange is the data of join and leave deliveries. ActorID is set
+// when someone other than the user made the change, e.g. an owner approving a
+// join request or removing a member.
+type MembershipChange struct {
+    UserID  string `json:"user_id"`
+    ActorID string `json:"actor_id,omitempty"`
+}
+
+// webhookDeliveryJob is the payload of a webhook delivery job. Body is kept
+// as first encoded, so every attempt posts the same delivery ID.
+type webhookDeliveryJob struct {
+    WebhookID uuid.UUID       `json:"webhook_id"`
+    Event     string          `json:"event"`
+    Body      json.RawMessage `json:"body"`
+}
+
+// WebhookService manages room webhooks and delivers events to them.
+type WebhookService struct {
+    db     *database.Queries
+    jobs   *JobQueue
+    client *http.Client
+}
+
+// NewWebhookService creates a new WebhookService. Deliveries are queued as
+// jobs, which it registers to run.
+func NewWebhookService(db *database.Queries, jobs *JobQueue) *WebhookService {
+    s := &WebhookService{db: db, jobs: jobs, client: &http.Client{Timeout: webhookTimeout}}
+    jobs.Handle(JobTypeWebhookDelivery, s.runDelivery)
+    return s
+}
+
+// CreateWebhook adds a webhook to a room, subscribed to the given events, and
+// returns it with its signing secret.
+func (s *WebhookService) CreateWebhook(ctx context.Context, roomID, creatorID uuid.UUID, rawURL string, events []string) (*Webhook, error) {
+    events, err := validateWebhook(rawURL, events)
+    if err != nil {
+        return nil, err
+    }
+    secret, err := newWebhookSecret()
+    if err != nil {
+        return nil, err
+    }
+
+    row, err := s.db.CreateRoomWebhook(ctx, database.CreateRoomWebhookParams{
+        ID:        uuid.New(),
+        RoomID:    roomID,
+        Url:       rawURL,
+        Secret:    secret,
+        Events:    events,
+        CreatedBy: &creatorID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    webhook := webhookFromRow(row)
+    webhook.Secret = row.Secret
+    return webhook, nil
+}
+
+// GetWebhook returns a webhook by ID.
+func (s *WebhookService) GetWebhook(ctx context.Context, webhookID uuid.UUID) (database.RoomWebhook, error) {
+    return s.db.GetRoomWebhookByID(ctx, webhookID)
+}
+
+// ListWebhooks returns the webhooks of a room, oldest first.
+func (s *WebhookService) ListWebhooks(ctx context.Context, roomID uuid.UUID) ([]*Webhook, error) {
+    rows, err := s.db.GetRoomWebhooks(ctx, roomID)
+    if err != nil {
+        return nil, err
+    }
+    webhooks := make([]*Webhook, 0, len(rows))
+    for _, row := range rows {
+        webhooks = append(webhooks, webhookFromRow(row))
+    }
+    return webhooks, nil
+}
+
+// UpdateWebhook changes a webhook's URL and subscribed events. The secret is
+// kept.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhookID uuid.UUID, rawURL string, events []string) (*Webhook, error) {
+    events, err := validateWebhook(rawURL, events)
+    if err != nil {
+        return nil, err
+    }
+    row, err := s.db.UpdateRoomWebhook(ctx, database.UpdateRoomWebhookParams{ID: webhookID, Url: rawURL, Events: events})
+    if err != nil {
+        return nil, err
+    }
+    return webhookFromRow(row), nil
+}
+
+// DeleteWebhook removes a webhook.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+    return s.db.DeleteRoomWebhook(ctx, webhookID)
+}
+
+// Dispatch delivers an event to the room's webhooks subscribed to it, in the
+// background. Each delivery is a job, so failed ones are retried with
+// backoff and administrators can see the ones that are stuck.
+func (s *WebhookService) Dispatch(roomID uuid.UUID, event string, data any) {
+    go s.dispatch(roomID, event, data)
+}
+
+func (s *WebhookService) dispatch(roomID uuid.UUID, event string, data any) {
+    ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+    defer cancel()
+
+    webhooks, err := s.db.GetSubscribedWebhooks(ctx, database.GetSubscribedWebhooksParams{RoomID: roomID, Event: event})
+    if err != nil {
+        log.Printf("failed to load %s webhooks of room %s: %v", event, roomID, err)
+        return
+    }
+    if len(webhooks) == 0 {
+        return
+    }
+
+    body, err := json.Marshal(WebhookDelivery{
+        ID:        uuid.NewString(),
+        Event:     event,
+        RoomID:    roomID.String(),
+        Timestamp: time.Now(),
+        Data:      data,
+    })
+    if err != nil {
+        log.Printf("failed to encode %s webhook delivery: %v", event, err)
+        return
+    }
+    for _, webhook := range webhooks {
+        err := s.jobs.Enqueue(ctx, JobTypeWebhookDelivery, webhookDeliveryJob{WebhookID: webhook.ID, Event: event, Body: body})
+        if err != nil {
+            log.Printf("failed to queue webhook %s delivery: %v", webhook.ID, err)
+        }
+    }
+}
+
+// runDelivery runs a webhook delivery job. Deliveries to webhooks deleted
+// since are dropped.
+func (s *WebhookService) runDelivery(ctx context.Context, payload []byte) error {
+    var job webhookDeliveryJob
+    if err := json.Unmarshal(payload, &job); err != nil {
+        return fmt.Errorf("decode webhook delivery: %w", err)
+    }
+    webhook, err := s.db.GetRoomWebhookByID(ctx, job.WebhookID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return s.deliver(ctx, webhook, job.Event, job.Body)
+}
+
+// deliver posts body to a webhook, signed with the webhook's secret.
+func (s *WebhookService) deliver(ctx context.Context, webhook database.RoomWebhook, event string, body []byte) error {
+    ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    mac := hmac.New(sha256.New, []byte(webhook.Secret))
+    mac.Write(body)
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(WebhookEventHeader, event)
+    req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("%s returned status %d", webhook.Url, resp.StatusCode)
+    }
+    return nil
+}
+
+// validateWebhook checks a webhook's URL and returns its events without
+// duplicates.
+func validateWebhook(rawURL string, events []string) ([]string, error) {
+    u, err := url.Parse(rawURL)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return nil, ErrInvalidWebhookURL
+    }
+    if len(events) == 0 {
+        return nil, ErrInvalidWebhookEvents
+    }
+
+    seen := make(map[string]bool, len(events))
+    for _, event := range events {
+        seen[event] = true
+    }
+    deduped := make([]string, 0, len(seen))
+    for _, event := range webhookEvents {
+        if seen[event] {
+            deduped = append(deduped, event)
+            delete(seen, event)
+        }
+    }
+    if len(seen) > 0 {
+        return nil, ErrInvalidWebhookEvents
+    }
+    return deduped, nil
+}
+
+// newWebhookSecret returns a random secret for signing deliveries.
+func newWebhookSecret() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("generate webhook secret: %w", err)
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// webhookFromRow converts a webhook row, leaving out its secret.
+func webhookFromRow(row database.RoomWebhook) *Webhook {
+    return &Webhook{
+        ID:        row.ID.String(),
+        RoomID:    row.RoomID.String(),
+        URL:       row.Url,
+        Events:    row.Events,
+        CreatedAt: row.CreatedAt,
+    }
+}
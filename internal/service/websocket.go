@@ -1,31 +1,194 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to them.
 type Hub struct {
-    // Registered clients for each room.
-    clients map[string]map[string]*Client
+    // Registered clients for each room, by user. A user can be connected
+    // to a room from several devices at once.
+    clients map[string]map[string]clientSet
+    // presence counts the registered clients of each user, across rooms.
+    presence map[string]int
+    // encryptedRooms holds the conversations that opted in to end-to-end
+    // encryption while the hub was running.
+    encryptedRooms map[string]bool
     broadcast chan *Message
     register chan *Client
     unregister chan *Client
+    disconnect chan disconnectRequest
+    online chan onlineRequest
+    connections chan connectionsRequest
+    presenceQueries chan presenceRequest
+    // presenceStatuses asks for the presence of several users at once.
+    presenceStatuses chan presenceStatusRequest
+    // subscribe replaces the presence subscription of a client.
+    subscribe chan subscriptionRequest
+    // subscribers holds the clients subscribed to each user's presence.
+    subscribers map[string]clientSet
+    // typing holds the users shown typing in each room.
+    typing map[typingKey]*typingState
+    typingGeneration uint64
+    snapshots chan hubSnapshotRequest
+    // restored holds, for each room, the users the hub snapshot found
+    // connected to it who have not reconnected yet. They are listed online
+    // in presence snapshots until restoredExpiry fires.
+    restored map[string]map[string]bool
+    restoredExpiry <-chan time.Time
+    messages *MessageService
+    push PushSender
+    pushTemplates PushTemplates
+    roomMentions *mentionCoalescer
+    translator Translator
+    translations *translationCache
+    typingSettings *typingSettingsCache
+    flood *floodGuard
+    fairness *roomScheduler
+    // webhooks receives new room messages; nil when no webhooks are set up.
+    webhooks *WebhookService
+    // hooks observe every routed message.
+    hooks []func(Message)
+    latency *latencyTracker
+    // commands runs slash commands; nil when messages starting with a slash
+    // are sent as they are.
+    commands *CommandDispatcher
 }
 
 // Message represents a chat message.
 type Message struct {
-    SenderID    string `json:"sender_id"`
-    RecipientID string `json:"recipient_id,omitempty"` // Omit if empty for broadcast messages
-    RoomID      string `json:"room_id"`
-    Content     string `json:"content"`
+    // Type is empty for new messages and names the event for updates to
+    // existing ones, such as poll.updated.
+    Type        string    `json:"type,omitempty"`
+    ID          string    `json:"id,omitempty"`
+    // ClientMsgID is an optional idempotency key chosen by the sender. A
+    // message resent to its room with the same key is acknowledged but not
+    // stored again.
+    ClientMsgID string    `json:"client_msg_id,omitempty"`
+    Seq         int64     `json:"seq,omitempty"` // Monotonic sequence number used for replay on reconnect
+    SenderID    string    `json:"sender_id"`
+    RecipientID string    `json:"recipient_id,omitempty"` // Omit if empty for broadcast messages
+    RoomID      string    `json:"room_id"`
+    Content     string    `json:"content"`
+    Kind        string    `json:"kind,omitempty"`
+    // Priority is "normal" or "urgent". Urgent messages are pushed to every
+    // offline member of the room.
+    Priority  string    `json:"priority,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    // Metadata carries structured data attached by bots and clients. It is
+    // persisted and relayed untouched. Attachments are listed under
+    // "attachments", which rooms can restrict to some roles.
+    Metadata map[string]any `json:"metadata,omitempty"`
+    // OriginalContent and Language are set on per-recipient translated copies.
+    OriginalContent string `json:"original_content,omitempty"`
+    Language        string `json:"language,omitempty"`
+    // Poll is set on poll messages and their updates.
+    Poll *Poll `json:"poll,omitempty"`
+    // QuotedMessageID references the message this one replies to; Quote is a
+    // server-side snapshot of it.
+    QuotedMessageID string         `json:"quoted_message_id,omitempty"`
+    Quote           *QuotedMessage `json:"quote,omitempty"`
+    // Sender is included in history responses so clients need not fetch profiles.
+    Sender *SenderProfile `json:"sender,omitempty"`
+    // Mentions lists the IDs of users mentioned directly or through a group,
+    // so clients can highlight the message for them.
+    Mentions []string `json:"mentions,omitempty"`
+    // EditedAt is set once the message's content has been edited.
+    EditedAt *time.Time `json:"edited_at,omitempty"`
+    // Annotations holds structured data attached to the message after it
+    // was sent; message.annotated events carry the new annotation.
+    Annotations []Annotation `json:"annotations,omitempty"`
+    // Reactions holds the message's reaction counts; reactions.updated
+    // events carry the new counts.
+    Reactions []Reaction `json:"reactions,omitempty"`
+    // Deleted is set on messages.deleted events.
+    Deleted *DeletedMessages `json:"deleted,omitempty"`
+    // Purge is set on messages.purged events.
+    Purge *MessagePurge `json:"purge,omitempty"`
+    // Report is set on message.reported events in the admin channel.
+    Report *Report `json:"report,omitempty"`
+    // Invite is set on room.invited events.
+    Invite *Invite `json:"invite,omitempty"`
+    // Room is set on room.updated events.
+    Room *RoomUpdate `json:"room,omitempty"`
+    // Announcement is set on room.announcement events.
+    Announcement *AnnouncementUpdate `json:"announcement,omitempty"`
+    // Pin is set on message.pinned and message.unpinned events.
+    Pin *PinUpdate `json:"pin,omitempty"`
+    // Command is set on command.result events.
+    Command *CommandResult `json:"command,omitempty"`
+    // Members is set on members.changed events.
+    Members *MemberDelta `json:"members,omitempty"`
+    // Conversation is set on conversation.added events.
+    Conversation *Conversation `json:"conversation,omitempty"`
+    // Impersonation is set on account.impersonated events.
+    Impersonation *Impersonation `json:"impersonation,omitempty"`
+    // Folders is set on folders.changed events.
+    Folders []Folder `json:"folders,omitempty"`
+    // Error is set on error frames sent back to a client whose message was rejected.
+    Error *ErrorFrame `json:"error,omitempty"`
+    // Ack, Typing, Presence, Unread and Heartbeat are set on the events of
+    // the same name.
+    Ack       *Ack       `json:"-"`
+    Typing    *Typing    `json:"-"`
+    Presence  *Presence  `json:"-"`
+    Unread    *Unread    `json:"-"`
+    Heartbeat *Heartbeat `json:"-"`
+    // PresenceSnapshot is set on presence.snapshot events.
+    PresenceSnapshot *PresenceSnapshot `json:"-"`
+    // Status is set on presence.status events.
+    Status *StatusUpdate `json:"-"`
+    // PresenceSubscription is set on presence.subscribed events.
+    PresenceSubscription *PresenceSubscription `json:"-"`
+    // FrameID is the envelope ID of the client frame an ack or error answers.
+    FrameID string `json:"-"`
+    // receivedAt is when the server received a new message, and audience how
+    // many clients were connected to its room when it was fanned out; they
+    // time its delivery to each of them.
+    receivedAt time.Time
+    audience   int
+    // toSubscribers marks presence events about their sender that go to
+    // the clients subscribed to them rather than to a room.
+    toSubscribers bool
+    // typingExpiry is set on the typing stops sent when a typing expiry
+    // timer fires, to the timer's generation.
+    typingExpiry uint64
 }
 
+// EventError is the type of frames reporting a rejected client frame.
+const EventError = "error"
+
+// Error codes sent in error frames.
+const (
+    ErrorCodeMessageTooLarge = "message_too_large"
+    ErrorCodeInvalidMessage  = "invalid_message"
+    ErrorCodeReadOnly        = "read_only"
+)
+
+// ErrorFrame describes why a client's message was rejected.
+type ErrorFrame struct {
+    Code   string `json:"code"`
+    Reason string `json:"reason"`
+    // RetryAfter is the number of seconds to wait before sending again, when
+    // the rejection is temporary.
+    RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// clientSet holds the connections of a user to a room, one per device.
+type clientSet map[*Client]bool
+
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
     hub *Hub
@@ -33,16 +196,117 @@ type Client struct {
     send chan *Message
     userID string
     roomID string
+    // Preferred language of the user; messages from others are translated into it.
+    language string
+    // Largest message, in bytes, the client may send.
+    maxMessageSize int
+    // Messages to replay before switching to live broadcast.
+    backlog []*Message
+    // compactReplay sends the backlog as a single replay frame.
+    compactReplay bool
+    // readOnly clients only receive; every frame they send is rejected.
+    readOnly bool
+    // closeReason is sent in the close frame when the hub disconnects the
+    // client, such as when its user is kicked from the room.
+    closeReason string
+    // closeCode goes with closeReason; policy violation when unset.
+    closeCode int
+    connectedAt time.Time
+    // lastActive is when, in Unix nanoseconds, the client last sent a frame
+    // other than a heartbeat, or connected. The read pump sets it while the
+    // hub reads it.
+    lastActive atomic.Int64
+    // subscriptions holds the users the client receives presence for, when
+    // it subscribed to some; nil means its room's. Only the hub goroutine
+    // touches it.
+    subscriptions map[string]bool
+    // heartbeats tracks the heartbeat frames the client sends.
+    heartbeats heartbeatTracker
+    // encrypted is set once the client's conversation is end-to-end
+    // encrypted; plaintext message frames are then rejected. The hub sets it
+    // while the read pump reads it.
+    encrypted atomic.Bool
+}
+
+// disconnectRequest asks the hub to close a user's connection to a room, or
+// every connection to it when userID is empty.
+type disconnectRequest struct {
+    roomID string
+    userID string
+    reason string
+    code   int
+}
+
+// onlineRequest asks the hub which users are connected to a room.
+type onlineRequest struct {
+    roomID string
+    reply  chan map[string]bool
+}
+
+// HubOptions configures a Hub.
+type HubOptions struct {
+    // Flood limits how fast each user can send messages.
+    Flood FloodControl
+    // Fairness shares busy rooms between their senders.
+    Fairness RoomFairness
+    // Webhooks delivers new room messages to the rooms' webhooks. Optional.
+    Webhooks *WebhookService
+    // Push sets the content of push notifications; DefaultPushTemplates
+    // when zero.
+    Push PushTemplates
+    // Hooks are called, each on its own goroutine, with a copy of every
+    // message and event the hub routes. Optional.
+    Hooks []func(Message)
+    // SLO is the objective new messages are delivered against;
+    // DefaultBroadcastSLO when zero.
+    SLO BroadcastSLO
+    // Snapshot is the state the hub had when the server last shut down, to
+    // be ready for its clients reconnecting. Optional.
+    Snapshot *HubSnapshot
 }
 
 // NewHub creates and returns a new Hub
-func NewHub() *Hub {
-    return &Hub{
+func NewHub(messages *MessageService, providers Providers, opts HubOptions) *Hub {
+    if opts.Push == (PushTemplates{}) {
+        opts.Push = DefaultPushTemplates()
+    }
+    if opts.SLO == (BroadcastSLO{}) {
+        opts.SLO = DefaultBroadcastSLO()
+    }
+    h := &Hub{
+        messages:     messages,
+        push:         providers.Push,
+        pushTemplates: opts.Push,
+        translator:   providers.Translator,
+        translations: newTranslationCache(),
+        typingSettings: newTypingSettingsCache(),
+        flood:        newFloodGuard(opts.Flood),
+        fairness:     newRoomScheduler(opts.Fairness),
+        webhooks:     opts.Webhooks,
+        hooks:        opts.Hooks,
+        latency:      newLatencyTracker(opts.SLO),
         broadcast:  make(chan *Message),
         register:   make(chan *Client),
         unregister: make(chan *Client),
-        clients:    make(map[string]map[string]*Client),
+        disconnect: make(chan disconnectRequest),
+        online:     make(chan onlineRequest),
+        connections: make(chan connectionsRequest),
+        presenceQueries: make(chan presenceRequest),
+        presenceStatuses: make(chan presenceStatusRequest),
+        subscribe:  make(chan subscriptionRequest),
+        subscribers: make(map[string]clientSet),
+        typing:     make(map[typingKey]*typingState),
+        snapshots:  make(chan hubSnapshotRequest),
+        presence:   make(map[string]int),
+        encryptedRooms: make(map[string]bool),
+        clients:    make(map[string]map[string]clientSet),
+    }
+    if opts.Snapshot != nil {
+        h.restore(opts.Snapshot)
     }
+    h.roomMentions = newMentionCoalescer(roomMentionPushWindow, h.pushRoomMentions)
+    messages.online = h.OnlineUsers
+    return h
 }
 
 
@@ -50,7 +314,14 @@ const (
     writeWait = 10 * time.Second
     pongWait = 60 * time.Second
     pingPeriod = (pongWait * 9) / 10
-    maxMessageSize = 512
+)
+
+const (
+    // DefaultMaxMessageSize is the message size limit used when none is configured.
+    DefaultMaxMessageSize = 512
+    // MaxMessageSizeLimit is the largest limit that can be configured. Frames
+    // larger than it are not read at all and close the connection.
+    MaxMessageSizeLimit = 64 * 1024
 )
 
 func (h *Hub) Run() {
@@ -58,70 +329,409 @@ func (h *Hub) Run() {
         select {
         case client := <-h.register:
             if _, ok := h.clients[client.roomID]; !ok {
-                h.clients[client.roomID] = make(map[string]*Client)
+                h.clients[client.roomID] = make(map[string]clientSet)
+            }
+            devices, connected := h.clients[client.roomID][client.userID]
+            if !connected {
+                devices = make(clientSet)
+                h.clients[client.roomID][client.userID] = devices
+            }
+            devices[client] = true
+            h.trackPresence(client.userID, 1)
+            h.unrestore(client.roomID, client.userID)
+            if h.encryptedRooms[client.roomID] {
+                client.encrypted.Store(true)
             }
-            h.clients[client.roomID][client.userID] = client
+            h.fairness.setConnected(client.roomID, len(h.clients[client.roomID]))
             log.Printf("Client %s registered to room %s", client.userID, client.roomID)
+            if client.roomID != AdminChannel {
+                h.sendPresenceSnapshot(client)
+            }
+            // The room only hears of the user's first device.
+            if !connected {
+                h.fanOutPresence(presenceEvent(client, PresenceOnline), client.userID)
+            }
 
         case client := <-h.unregister:
-            if _, ok := h.clients[client.roomID]; ok {
-                if _, ok := h.clients[client.roomID][client.userID]; ok {
-                    delete(h.clients[client.roomID], client.userID)
-                    close(client.send)
-                    log.Printf("Client %s unregistered from room %s", client.userID, client.roomID)
-                }
+            // The hub may already have dropped the client.
+            if h.clients[client.roomID][client.userID][client] {
+                h.remove(client)
+                log.Printf("Client %s unregistered from room %s", client.userID, client.roomID)
             }
-        case message := <-h.broadcast:
-            if message.RecipientID != "" {
-                if client, ok := h.clients[message.RoomID][message.RecipientID]; ok {
-                    select {
-                    case client.send <- message:
-                    default:
-                        close(client.send)
-                        delete(h.clients[message.RoomID], client.userID)
-                    }
-                } else {
-                    log.Printf("Recipient %s not found in room %s", message.RecipientID, message.RoomID)
+        case req := <-h.disconnect:
+            for userID, devices := range h.clients[req.roomID] {
+                if req.userID != "" && userID != req.userID {
+                    continue
                 }
-            } else {
-                if clientsInRoom, ok := h.clients[message.RoomID]; ok {
-                    for _, client := range clientsInRoom {
-                        select {
-                        case client.send <- message:
-                        default:
-                            close(client.send)
-                            delete(h.clients[message.RoomID], client.userID)
-                        }
-                    }
+                for client := range devices {
+                    client.closeReason = req.reason
+                    client.closeCode = req.code
+                    h.remove(client)
+                    log.Printf("Client %s disconnected from room %s: %s", client.userID, client.roomID, req.reason)
                 }
             }
+        case req := <-h.online:
+            online := make(map[string]bool, len(h.clients[req.roomID]))
+            for userID := range h.clients[req.roomID] {
+                online[userID] = true
+            }
+            req.reply <- online
+        case req := <-h.connections:
+            req.reply <- h.listConnections(req.filter)
+        case req := <-h.presenceQueries:
+            req.reply <- h.presence[req.userID] > 0
+        case req := <-h.presenceStatuses:
+            req.reply <- h.presenceOf(req.userIDs)
+        case req := <-h.snapshots:
+            req.reply <- h.snapshot()
+        case <-h.restoredExpiry:
+            h.expireRestored()
+        case req := <-h.subscribe:
+            h.setSubscription(req)
+        case message := <-h.broadcast:
+            h.route(message)
+        }
+    }
+}
+
+// remove drops a registered client, closing its send channel so its write
+// pump closes the connection, and tells the room the user went offline when
+// it was their last device connected to it.
+func (h *Hub) remove(client *Client) {
+    if h.drop(client) {
+        h.stopTyping(client.roomID, client.userID)
+        h.fanOutPresence(presenceEvent(client, PresenceOffline), client.userID)
+    }
+}
+
+// drop unregisters a client and closes its send channel, reporting whether it
+// was the last device of its user connected to its room.
+func (h *Hub) drop(client *Client) bool {
+    h.unsubscribe(client)
+    devices := h.clients[client.roomID][client.userID]
+    delete(devices, client)
+    last := len(devices) == 0
+    if last {
+        delete(h.clients[client.roomID], client.userID)
+    }
+    h.trackPresence(client.userID, -1)
+    h.fairness.setConnected(client.roomID, len(h.clients[client.roomID]))
+    close(client.send)
+    return last
+}
+
+// route delivers a message according to its type: to a single recipient, to
+// the rest of the room for typing and presence, or to the whole room with
+// push notifications for those who are offline. Unread counts, invitations
+// and conversation.added events go to every connection of their recipient.
+func (h *Hub) route(message *Message) {
+    if message.toSubscribers {
+        h.sendToSubscribers(message)
+        return
+    }
+    for _, hook := range h.hooks {
+        go hook(*message)
+    }
+    if message.Type == EventConversationEncrypted {
+        h.markEncrypted(message.RoomID)
+    }
+    if message.Type == "" {
+        if message.receivedAt.IsZero() {
+            message.receivedAt = time.Now()
+        }
+        message.audience = len(h.clients[message.RoomID])
+        h.queueUnreads(message)
+        // Sending a message ends typing it.
+        h.stopTyping(message.RoomID, message.SenderID)
+    }
+    switch {
+    case message.Type == EventUnread || message.Type == EventInvite || message.Type == EventConversationAdded || message.Type == EventImpersonated || message.Type == EventFoldersChanged:
+        h.sendToUser(message.RecipientID, message)
+    case message.RecipientID != "":
+        if devices, ok := h.clients[message.RoomID][message.RecipientID]; ok {
+            for client := range devices {
+                h.send(client, message)
+            }
+        } else if message.Type == "" {
+            log.Printf("Recipient %s not found in room %s, sending push notification", message.RecipientID, message.RoomID)
+            go h.notifyOffline(message)
+        }
+    case message.Type == EventTyping:
+        h.routeTyping(message)
+    case message.Type == EventPresence || message.Type == EventPresenceOnline || message.Type == EventPresenceOffline:
+        h.fanOutPresence(message, message.SenderID)
+    case message.Type == EventPresenceStatus:
+        h.fanOutPresence(message, "")
+    default:
+        h.fanOut(message, "")
+        if message.Type != "" {
+            return
+        }
+        h.dispatchWebhooks(message)
+        online := make(map[string]bool, len(h.clients[message.RoomID]))
+        for userID := range h.clients[message.RoomID] {
+            online[userID] = true
+        }
+        if message.Priority == MessagePriorityUrgent {
+            // Everyone offline is pushed, so mentions need no separate push.
+            go h.escalate(message, online)
+            return
+        }
+        go h.notifyRoom(message, online)
+    }
+}
+
+// fanOut sends a message to every client in its room except those of
+// skipUserID.
+func (h *Hub) fanOut(message *Message, skipUserID string) {
+    for userID, devices := range h.clients[message.RoomID] {
+        if userID == skipUserID {
+            continue
+        }
+        for client := range devices {
+            h.send(client, message)
+        }
+    }
+}
+
+// sendToUser sends a message to all of a user's connections, in any room.
+func (h *Hub) sendToUser(userID string, message *Message) {
+    for _, clients := range h.clients {
+        for client := range clients[userID] {
+            h.send(client, message)
+        }
+    }
+}
+
+// queueUnreads has updated unread counts sent, in the background, to the
+// connected users a new message may concern: the recipient of a direct
+// message, or everyone but the sender for a room message. Users who are not
+// members of the room are filtered out when counting.
+func (h *Hub) queueUnreads(message *Message) {
+    connected := make(map[string]bool)
+    for _, clients := range h.clients {
+        for userID := range clients {
+            if userID == message.SenderID {
+                continue
+            }
+            if message.RecipientID == "" || userID == message.RecipientID {
+                connected[userID] = true
+            }
+        }
+    }
+    if len(connected) == 0 {
+        return
+    }
+    userIDs := make([]string, 0, len(connected))
+    for userID := range connected {
+        userIDs = append(userIDs, userID)
+    }
+    go h.pushUnreads(message, userIDs)
+}
+
+// send queues a message for a client, removing the client if it cannot keep
+// up. The room then hears the user went offline and stopped typing, as when
+// they disconnect, if it was their last device connected to it.
+func (h *Hub) send(client *Client, message *Message) {
+    select {
+    case client.send <- message:
+    default:
+        h.remove(client)
+    }
+}
+
+// presenceEvent announces that a client connected or left.
+func presenceEvent(client *Client, status string) *Message {
+    return &Message{
+        Type:      EventPresence,
+        SenderID:  client.userID,
+        RoomID:    client.roomID,
+        CreatedAt: time.Now(),
+        Presence:  &Presence{UserID: client.userID, Status: status},
+    }
+}
+
+// Broadcast delivers a server-originated message to the clients in its room.
+func (h *Hub) Broadcast(message *Message) {
+    h.broadcast <- message
+}
+
+// SetCommands has chat messages that are slash commands run by commands
+// instead of being sent. It must be called before clients connect.
+func (h *Hub) SetCommands(commands *CommandDispatcher) {
+    h.commands = commands
+}
+
+// PushTemplates returns the templates push notifications are built from.
+func (h *Hub) PushTemplates() PushTemplates {
+    return h.pushTemplates
+}
+
+// DisplayNames returns the policy deciding which names of users are shown.
+func (h *Hub) DisplayNames() DisplayNamePolicy {
+    return h.messages.opts.DisplayNames
+}
+
+// SLO returns the objective new messages are delivered against.
+func (h *Hub) SLO() BroadcastSLO {
+    return h.latency.slo
+}
+
+// BroadcastLatency summarizes how long new messages have taken from being
+// received to being written to each client, per room size.
+func (h *Hub) BroadcastLatency() []LatencySnapshot {
+    return h.latency.snapshot()
+}
+
+// Disconnect closes the user's live connections to the room, from every
+// device, with reason in the close frame.
+func (h *Hub) Disconnect(roomID, userID uuid.UUID, reason string) {
+    h.disconnect <- disconnectRequest{roomID: roomID.String(), userID: userID.String(), reason: reason}
+}
+
+// CloseRoom closes every live connection to the room, with reason in the
+// close frame. It is used once the room is gone, so the code is going away
+// rather than policy violation.
+func (h *Hub) CloseRoom(roomID uuid.UUID, reason string) {
+    h.disconnect <- disconnectRequest{roomID: roomID.String(), reason: reason, code: websocket.CloseGoingAway}
+}
+
+// OnlineUsers returns the IDs of the users connected to the room right now.
+func (h *Hub) OnlineUsers(roomID uuid.UUID) map[string]bool {
+    req := onlineRequest{roomID: roomID.String(), reply: make(chan map[string]bool, 1)}
+    h.online <- req
+    return <-req.reply
+}
+
+// broadcastUpdate delivers an event about an existing message to the clients
+// who can see it: the whole room, or only the two participants of a direct
+// message.
+func (h *Hub) broadcastUpdate(message database.Message, event *Message) {
+    if message.RecipientID == nil {
+        h.Broadcast(event)
+        return
+    }
+    for _, userID := range []uuid.UUID{message.SenderID, *message.RecipientID} {
+        targeted := *event
+        targeted.RecipientID = userID.String()
+        h.Broadcast(&targeted)
+    }
+}
+
+// dispatchWebhooks sends a new room message to the room's webhooks subscribed
+// to messages. Direct messages are never sent to webhooks.
+func (h *Hub) dispatchWebhooks(message *Message) {
+    if h.webhooks == nil {
+        return
+    }
+    roomID, err := uuid.Parse(message.RoomID)
+    if err != nil {
+        return
+    }
+    copied := *message
+    h.webhooks.Dispatch(roomID, WebhookEventMessage, &copied)
+}
+
+// notifyOffline sends a push notification for a direct message whose recipient
+// is not connected, unless they muted the room.
+func (h *Hub) notifyOffline(message *Message) {
+    roomID, err := uuid.Parse(message.RoomID)
+    if err != nil {
+        return
+    }
+    recipientID, err := uuid.Parse(message.RecipientID)
+    if err != nil {
+        return
+    }
+    level, err := roomNotificationLevel(context.Background(), h.messages.db, roomID, recipientID)
+    if err != nil {
+        log.Printf("failed to load notification level of %s: %v", message.RecipientID, err)
+        return
+    }
+    if level == NotificationLevelNone {
+        return
+    }
+
+    notification := h.pushNotification(message, h.pushTemplates.MessageTitle)
+    if message.Priority == MessagePriorityUrgent {
+        notification = h.urgentPush(message)
+    }
+    err = h.push.Push(context.Background(), message.RecipientID, notification)
+    if err != nil {
+        log.Printf("failed to push notification to %s: %v", message.RecipientID, err)
+    }
+}
+
+// notifyMentioned sends a push notification to mentioned users who are not
+// connected to the room.
+func (h *Hub) notifyMentioned(message *Message, userIDs []string) {
+    notification := h.pushNotification(message, h.pushTemplates.MentionTitle)
+    for _, userID := range userIDs {
+        err := h.push.Push(context.Background(), userID, notification)
+        if err != nil {
+            log.Printf("failed to push mention to %s: %v", userID, err)
         }
     }
 }
+
 // Upgrader exports the websocket upgrader for use in the handler package.
 var Upgrader = websocket.Upgrader{
     ReadBufferSize:  1024,
     WriteBufferSize: 1024,
+    // Replays are compressed for clients that negotiate permessage-deflate.
+    EnableCompression: true,
     CheckOrigin: func(r *http.Request) bool {
         return true // Allow all origins for development
     },
 }
 
+// ClientOptions configures a client connection.
+type ClientOptions struct {
+    // Language is the user's preferred language; messages from others are
+    // translated into it.
+    Language string
+    // MaxMessageSize is the largest message, in bytes, the client may send.
+    // Larger messages are rejected with an error frame.
+    MaxMessageSize int
+    // ReadOnly connections, such as the admin channel, cannot send frames.
+    ReadOnly bool
+    // CompactReplay sends missed messages as a single replay frame instead
+    // of a frame per message.
+    CompactReplay bool
+    // Encrypted connections are to an end-to-end encrypted conversation and
+    // may only send encrypted frames.
+    Encrypted bool
+}
+
 // NewClient creates a new client, registers it with the hub, and returns it.
-func NewClient(hub *Hub, conn *websocket.Conn, userID, roomID string) *Client {
+func NewClient(hub *Hub, conn *websocket.Conn, userID, roomID string, opts ClientOptions) *Client {
+    if opts.MaxMessageSize <= 0 || opts.MaxMessageSize > MaxMessageSizeLimit {
+        opts.MaxMessageSize = DefaultMaxMessageSize
+    }
     client := &Client{
         hub:  hub,
         conn: conn,
         send: make(chan *Message, 256),
         userID: userID,
         roomID: roomID, // Initialize the new roomID field
+        language: opts.Language,
+        maxMessageSize: opts.MaxMessageSize,
+        readOnly: opts.ReadOnly,
+        compactReplay: opts.CompactReplay,
+        connectedAt: time.Now(),
     }
+    client.encrypted.Store(opts.Encrypted)
+    client.lastActive.Store(client.connectedAt.UnixNano())
+    // Only replays are compressed; live frames are small and frequent.
+    conn.EnableWriteCompression(false)
     client.hub.register <- client
     return client
 }
 
-// Serve handles the connection and starts the read and write pumps.
-func (c *Client) Serve() {
+// Serve handles the connection and starts the read and write pumps. Any
+// backlog messages are written before live broadcasts.
+func (c *Client) Serve(backlog []*Message) {
+    c.backlog = backlog
     go c.readPump()
     go c.writePump()
 }
@@ -131,7 +741,7 @@ func (c *Client) readPump() {
         c.hub.unregister <- c
         c.conn.Close()
     }()
-    c.conn.SetReadLimit(maxMessageSize)
+    c.conn.SetReadLimit(MaxMessageSizeLimit)
     c.conn.SetReadDeadline(time.Now().Add(pongWait))
     c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
     for {
@@ -142,15 +752,195 @@ func (c *Client) readPump() {
             }
             break
         }
-        var message Message
-        if err := json.Unmarshal(p, &message); err != nil {
+        var env Envelope
+        if err := json.Unmarshal(p, &env); err != nil {
             log.Printf("unmarshal error: %v", err)
+            c.reject("", &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "frame is not a valid envelope"})
+            continue
+        }
+        if c.readOnly {
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeReadOnly, Reason: "this channel is read-only"})
             continue
         }
-        message.SenderID = c.userID
-        message.RoomID = c.roomID
+        // Heartbeats are sent by clients on their own, so they do not keep
+        // the user from being away.
+        if env.Type != FrameHeartbeat {
+            c.lastActive.Store(time.Now().UnixNano())
+        }
+        // Clients may only send chat messages, typing notifications, read
+        // cursors, heartbeats and presence subscriptions; everything else is
+        // server-originated.
+        switch env.Type {
+        case FrameMessage:
+            if c.encrypted.Load() {
+                c.reject(env.ID, &ErrorFrame{Code: ErrorCodeEncryptionRequired, Reason: ErrEncryptionRequired.Error()})
+                continue
+            }
+            c.handleMessage(env, MessageKindText)
+        case FrameEncrypted:
+            c.handleEncrypted(env)
+        case FrameTyping:
+            c.handleTyping(env)
+        case FrameRead:
+            c.handleRead(env)
+        case FrameHeartbeat:
+            c.handleHeartbeat(env)
+        case FramePresenceSubscribe:
+            c.handlePresenceSubscribe(env)
+        default:
+            c.reject(env.ID, &ErrorFrame{
+                Code:   ErrorCodeUnknownFrame,
+                Reason: fmt.Sprintf("frame type %q cannot be sent by clients", env.Type),
+            })
+        }
+    }
+}
+
+// handleMessage stores a chat message of the given kind, text or encrypted,
+// broadcasts it and acknowledges it to the sender.
+func (c *Client) handleMessage(env Envelope, kind string) {
+    receivedAt := time.Now()
+    if frame, ok := c.hub.flood.allow(c.userID); !ok {
+        c.reject(env.ID, frame)
+        return
+    }
+    if frame, ok := c.hub.fairness.allow(c.roomID, c.userID); !ok {
+        c.reject(env.ID, frame)
+        return
+    }
+    if len(env.Payload) > c.maxMessageSize {
+        c.reject(env.ID, &ErrorFrame{
+            Code:   ErrorCodeMessageTooLarge,
+            Reason: fmt.Sprintf("message exceeds the %d byte limit", c.maxMessageSize),
+        })
+        return
+    }
+    var message Message
+    if err := json.Unmarshal(env.Payload, &message); err != nil {
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "message payload is not valid JSON"})
+        return
+    }
+    // Ciphertext is never read, so it cannot hold a command.
+    if c.hub.commands != nil && kind == MessageKindText {
+        if name, args, ok := parseCommand(message.Content); ok {
+            c.hub.commands.run(c, env.ID, name, args, message.QuotedMessageID)
+            return
+        }
+        // A doubled slash sends a message that would otherwise be a command.
+        if strings.HasPrefix(message.Content, "//") {
+            message.Content = message.Content[1:]
+        }
+    }
+    message.receivedAt = receivedAt
+    message.SenderID = c.userID
+    message.RoomID = c.roomID
+    // Clients may only send chat messages; other kinds have their own endpoints.
+    message.Type = ""
+    message.Kind = kind
+    message.Poll = nil
+    message.Mentions = nil
+    message.EditedAt = nil
+    message.Annotations = nil
+    message.Deleted = nil
+    message.Error = nil
+    err := c.hub.messages.SaveMessage(context.Background(), &message)
+    if err != nil && !errors.Is(err, ErrDuplicateMessage) {
+        switch {
+        case errors.Is(err, ErrUrgentNotAllowed):
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeUrgentNotAllowed, Reason: err.Error()})
+        case errors.Is(err, ErrUrgentLimitReached):
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeUrgentLimit, Reason: err.Error()})
+        case errors.Is(err, ErrRoomMentionNotAllowed):
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeRoomMentionNotAllowed, Reason: err.Error()})
+        case errors.Is(err, ErrAttachmentsNotAllowed):
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeAttachmentsNotAllowed, Reason: err.Error()})
+        case errors.Is(err, ErrInvalidPriority), errors.Is(err, ErrInvalidClientMsgID):
+            c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: err.Error()})
+        default:
+            log.Printf("failed to save message: %v", err)
+        }
+        return
+    }
+    // A retry was already delivered; the sender only needs the original ack.
+    if err == nil {
         c.hub.broadcast <- &message
     }
+    c.hub.broadcast <- &Message{
+        Type:        EventAck,
+        SenderID:    c.userID,
+        RecipientID: c.userID,
+        RoomID:      c.roomID,
+        CreatedAt:   time.Now(),
+        Ack: &Ack{
+            MessageID:   message.ID,
+            ClientMsgID: message.ClientMsgID,
+            Seq:         message.Seq,
+            CreatedAt:   message.CreatedAt,
+        },
+        FrameID:     env.ID,
+    }
+}
+
+// handleTyping relays a typing notification to the rest of the room. Those of
+// users who turned typing indicators off are dropped without an error, so
+// their clients need not know about the setting.
+func (c *Client) handleTyping(env Envelope) {
+    var typing Typing
+    if err := json.Unmarshal(env.Payload, &typing); err != nil {
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: "typing payload is not valid JSON"})
+        return
+    }
+    if !c.hub.sharesTyping(c.userID) {
+        return
+    }
+    typing.UserID = c.userID
+    c.hub.broadcast <- &Message{
+        Type:      EventTyping,
+        SenderID:  c.userID,
+        RoomID:    c.roomID,
+        CreatedAt: time.Now(),
+        Typing:    &typing,
+    }
+}
+
+// handleRead moves the user's read cursor for the room. The new unread counts
+// are sent back as an unread frame.
+func (c *Client) handleRead(env Envelope) {
+    var cursor ReadCursor
+    if err := json.Unmarshal(env.Payload, &cursor); err != nil {
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: ErrInvalidReadCursor.Error()})
+        return
+    }
+    roomID, err := uuid.Parse(c.roomID)
+    if err != nil {
+        return
+    }
+    userID, err := uuid.Parse(c.userID)
+    if err != nil {
+        return
+    }
+    _, err = c.hub.MarkRead(context.Background(), roomID, userID, cursor)
+    switch {
+    case errors.Is(err, ErrInvalidReadCursor), errors.Is(err, ErrMessageNotFound):
+        c.reject(env.ID, &ErrorFrame{Code: ErrorCodeInvalidMessage, Reason: err.Error()})
+    case err != nil:
+        log.Printf("failed to mark room %s read for %s: %v", c.roomID, c.userID, err)
+    }
+}
+
+// reject sends an error frame answering the client frame with the given
+// envelope ID to this client only. It goes through the hub, which owns the
+// client's send channel.
+func (c *Client) reject(frameID string, frame *ErrorFrame) {
+    c.hub.broadcast <- &Message{
+        Type:        EventError,
+        SenderID:    c.userID,
+        RecipientID: c.userID,
+        RoomID:      c.roomID,
+        CreatedAt:   time.Now(),
+        Error:       frame,
+        FrameID:     frameID,
+    }
 }
 
 func (c *Client) writePump() {
@@ -160,16 +950,35 @@ func (c *Client) writePump() {
         c.conn.Close()
     }()
 
+    // Replay missed messages first, remembering the last sequence number so
+    // live messages already covered by the replay are not sent twice.
+    lastSeq, ok := c.replay()
+    if !ok {
+        return
+    }
+    c.backlog = nil
+
     for {
         select {
         case message, ok := <-c.send:
             c.conn.SetWriteDeadline(time.Now().Add(writeWait))
             if !ok {
-                c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+                closeFrame := []byte{}
+                if c.closeReason != "" {
+                    code := c.closeCode
+                    if code == 0 {
+                        code = websocket.ClosePolicyViolation
+                    }
+                    closeFrame = websocket.FormatCloseMessage(code, c.closeReason)
+                }
+                c.conn.WriteMessage(websocket.CloseMessage, closeFrame)
                 return
             }
+            if message.Seq != 0 && message.Seq <= lastSeq {
+                continue
+            }
 
-            messageBytes, err := json.Marshal(message)
+            messageBytes, err := c.frame(message)
             if err != nil {
                 log.Printf("json marshal error: %v", err)
                 return
@@ -180,21 +989,31 @@ func (c *Client) writePump() {
                 return
             }
             w.Write(messageBytes)
+            written := []*Message{message}
 
             n := len(c.send)
             for i := 0; i < n; i++ {
-                w.Write([]byte{'\n'})
                 nextMessage := <-c.send
-                nextMessageBytes, err := json.Marshal(nextMessage)
+                if nextMessage.Seq != 0 && nextMessage.Seq <= lastSeq {
+                    continue
+                }
+                w.Write([]byte{'\n'})
+                nextMessageBytes, err := c.frame(nextMessage)
                 if err != nil {
                     log.Printf("json marshal error: %v", err)
                     return
                 }
                 w.Write(nextMessageBytes)
+                written = append(written, nextMessage)
             }
             if err := w.Close(); err != nil {
                 return
             }
+            for _, m := range written {
+                if !m.receivedAt.IsZero() {
+                    c.hub.latency.observe(m.receivedAt, m.audience)
+                }
+            }
         case <-ticker.C:
             c.conn.SetWriteDeadline(time.Now().Add(writeWait))
             if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -202,4 +1021,22 @@ func (c *Client) writePump() {
             }
         }
     }
-}
\ No newline at end of file
+}
+
+// frame encodes a message as the envelope this client receives.
+func (c *Client) frame(message *Message) ([]byte, error) {
+    env, err := envelopeFor(c.localize(message))
+    if err != nil {
+        return nil, err
+    }
+    return json.Marshal(env)
+}
+
+// localize returns the message as this client should see it: translated into
+// the client's preferred language unless the client sent it.
+func (c *Client) localize(message *Message) *Message {
+    if message.SenderID == c.userID {
+        return message
+    }
+    return c.hub.translateFor(message, c.language)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// errNoDatabase is returned by every query of a hub under test.
+var errNoDatabase = errors.New("no database")
+
+// noDB is a database every query of which fails, for the hub's background
+// lookups to give up on.
+type noDB struct{}
+
+func (noDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+    return pgconn.CommandTag{}, errNoDatabase
+}
+
+func (noDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+    return nil, errNoDatabase
+}
+
+func (noDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+    return noRow{}
+}
+
+type noRow struct{}
+
+func (noRow) Scan(...any) error {
+    return errNoDatabase
+}
+
+// newTestHub starts a hub without a database or providers.
+func newTestHub() *Hub {
+    h := NewHub(NewMessageService(database.New(noDB{}), nil, MessageOptions{}), Providers{}, HubOptions{})
+    go h.Run()
+    return h
+}
+
+// connect registers a client to a room, with room for buffer frames.
+func connect(h *Hub, roomID string, buffer int) *Client {
+    client := &Client{hub: h, send: make(chan *Message, buffer), userID: uuid.NewString(), roomID: roomID}
+    h.register <- client
+    return client
+}
+
+// nextFrame returns the next frame queued for a client, failing the test when
+// none comes or the hub closed its channel.
+func nextFrame(t *testing.T, client *Client) *Message {
+    t.Helper()
+    select {
+    case message, ok := <-client.send:
+        if !ok {
+            t.Fatalf("client %s was disconnected", client.userID)
+        }
+        return message
+    case <-time.After(time.Second):
+        t.Fatalf("no frame for client %s", client.userID)
+        return nil
+    }
+}
+
+func TestSlowClientGoesOffline(t *testing.T) {
+    h := newTestHub()
+    roomID := uuid.NewString()
+
+    alice := connect(h, roomID, 16)
+    if frame := nextFrame(t, alice); frame.Type != EventPresenceSnapshot {
+        t.Fatalf("alice got %q, want the presence snapshot", frame.Type)
+    }
+    // Bob's snapshot fills his buffer.
+    bob := connect(h, roomID, 1)
+    if frame := nextFrame(t, alice); frame.Type != EventPresence || frame.Presence.Status != PresenceOnline {
+        t.Fatalf("alice got %q, want bob online", frame.Type)
+    }
+
+    h.Broadcast(&Message{
+        Type:      EventTyping,
+        SenderID:  bob.userID,
+        RoomID:    roomID,
+        CreatedAt: time.Now(),
+        Typing:    &Typing{UserID: bob.userID, Typing: true},
+    })
+    if frame := nextFrame(t, alice); frame.Type != EventTyping || !frame.Typing.Typing {
+        t.Fatalf("alice got %q, want bob typing", frame.Type)
+    }
+
+    // Bob cannot take the update, so the hub drops him.
+    h.Broadcast(&Message{Type: EventRoomUpdated, RoomID: roomID, CreatedAt: time.Now()})
+
+    var updated, stoppedTyping, offline bool
+    for i := 0; i < 3; i++ {
+        frame := nextFrame(t, alice)
+        switch {
+        case frame.Type == EventRoomUpdated:
+            updated = true
+        case frame.Type == EventTyping && frame.SenderID == bob.userID && !frame.Typing.Typing:
+            stoppedTyping = true
+        case frame.Type == EventPresence && frame.SenderID == bob.userID && frame.Presence.Status == PresenceOffline:
+            offline = true
+        default:
+            t.Fatalf("alice got unexpected %q frame", frame.Type)
+        }
+    }
+    if !updated || !stoppedTyping || !offline {
+        t.Fatalf("alice got room.updated %t, bob stopped typing %t, bob offline %t; want all", updated, stoppedTyping, offline)
+    }
+
+    if frame := nextFrame(t, bob); frame.Type != EventPresenceSnapshot {
+        t.Fatalf("bob got %q, want the presence snapshot", frame.Type)
+    }
+    if _, ok := <-bob.send; ok {
+        t.Fatal("bob is still connected")
+    }
+}
+
+func TestRestoredUsersListedOnline(t *testing.T) {
+    roomID := uuid.NewString()
+    carol, dave := uuid.NewString(), uuid.NewString()
+    h := NewHub(NewMessageService(database.New(noDB{}), nil, MessageOptions{}), Providers{}, HubOptions{
+        Snapshot: &HubSnapshot{TakenAt: time.Now(), Users: map[string][]string{roomID: {carol, dave}}},
+    })
+    // The test, not the clock, says when the snapshot is too old.
+    expire := make(chan time.Time)
+    h.restoredExpiry = expire
+    go h.Run()
+
+    alice := connect(h, roomID, 16)
+    frame := nextFrame(t, alice)
+    if frame.Type != EventPresenceSnapshot {
+        t.Fatalf("alice got %q, want the presence snapshot", frame.Type)
+    }
+    online := make(map[string]bool)
+    for _, userID := range frame.PresenceSnapshot.Online {
+        online[userID] = true
+    }
+    if !online[alice.userID] || !online[carol] || !online[dave] || len(online) != 3 {
+        t.Fatalf("presence snapshot listed %v, want alice and the restored users", frame.PresenceSnapshot.Online)
+    }
+
+    // Carol reconnects, so only dave is announced offline.
+    h.register <- &Client{hub: h, send: make(chan *Message, 16), userID: carol, roomID: roomID}
+    if frame := nextFrame(t, alice); frame.Type != EventPresence || frame.SenderID != carol || frame.Presence.Status != PresenceOnline {
+        t.Fatalf("alice got %q from %s, want carol online", frame.Type, frame.SenderID)
+    }
+    expire <- time.Now()
+    if frame := nextFrame(t, alice); frame.Type != EventPresence || frame.SenderID != dave || frame.Presence.Status != PresenceOffline {
+        t.Fatalf("alice got %q from %s, want dave offline", frame.Type, frame.SenderID)
+    }
+    select {
+    case frame := <-alice.send:
+        t.Fatalf("alice got unexpected %q frame from %s", frame.Type, frame.SenderID)
+    case <-time.After(100 * time.Millisecond):
+    }
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+var (
+    // SystemUserID is the ID of the built-in system bot account.
+    SystemUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+    // WelcomeRoomID is the room, owned by the system bot, in which new users
+    // receive their welcome message.
+    WelcomeRoomID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+)
+
+// DefaultWelcomeMessage is sent when no welcome message is configured.
+const DefaultWelcomeMessage = "Welcome to the chat, {username}!"
+
+// WelcomeOptions configures the onboarding message sent to new users.
+type WelcomeOptions struct {
+    Enabled bool
+    // Message is the text of the welcome message; {username} is replaced
+    // with the new user's name.
+    Message string
+    // RulesURL, if set, is linked from the message.
+    RulesURL string
+    // RoomIDs are rooms recommended to new users.
+    RoomIDs []uuid.UUID
+}
+
+// WelcomeLink is a link attached to the welcome message's metadata, pointing
+// either to a URL or to a room.
+type WelcomeLink struct {
+    Title  string `json:"title"`
+    URL    string `json:"url,omitempty"`
+    RoomID string `json:"room_id,omitempty"`
+}
+
+// WelcomeService sends new users a direct message from the system bot.
+type WelcomeService struct {
+    db    *database.Queries
+    store *MessageStore
+    opts  WelcomeOptions
+}
+
+// NewWelcomeService creates a new WelcomeService.
+func NewWelcomeService(db *database.Queries, store *MessageStore, opts WelcomeOptions) *WelcomeService {
+    if opts.Message == "" {
+        opts.Message = DefaultWelcomeMessage
+    }
+    return &WelcomeService{db: db, store: store, opts: opts}
+}
+
+// Welcome adds the user to the welcome room and sends them the onboarding
+// message there. It does nothing when welcome messages are disabled.
+func (s *WelcomeService) Welcome(ctx context.Context, user database.User) error {
+    if !s.opts.Enabled {
+        return nil
+    }
+
+    var lines []string
+    var links []WelcomeLink
+    if s.opts.RulesURL != "" {
+        lines = append(lines, "Please read the rules: "+s.opts.RulesURL)
+        links = append(links, WelcomeLink{Title: "Rules", URL: s.opts.RulesURL})
+    }
+    for _, roomID := range s.opts.RoomIDs {
+        room, err := s.db.GetRoomByID(ctx, roomID)
+        if err != nil {
+            log.Printf("welcome room %s not found: %v", roomID, err)
+            continue
+        }
+        lines = append(lines, fmt.Sprintf("Join #%s to get started.", room.Name))
+        links = append(links, WelcomeLink{Title: room.Name, RoomID: room.ID.String()})
+    }
+
+    content := strings.ReplaceAll(s.opts.Message, "{username}", user.Username)
+    if len(lines) > 0 {
+        content += "\n\n" + strings.Join(lines, "\n")
+    }
+    metadata := []byte("{}")
+    if len(links) > 0 {
+        var err error
+        if metadata, err = json.Marshal(map[string]any{"links": links}); err != nil {
+            return err
+        }
+    }
+
+    err := s.db.AddRoomMember(ctx, database.AddRoomMemberParams{RoomID: WelcomeRoomID, UserID: user.ID})
+    if err != nil {
+        return err
+    }
+    _, err = s.store.Create(ctx, s.db, database.CreateMessageParams{
+        ID:          uuid.New(),
+        RoomID:      WelcomeRoomID,
+        SenderID:    SystemUserID,
+        RecipientID: &user.ID,
+        Content:     content,
+        Metadata:    metadata,
+        Kind:        MessageKindText,
+        Mentions:    []uuid.UUID{},
+        Priority:    MessagePriorityNormal,
+    })
+    return err
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mxhdiqaim/go-chat-app/internal/database"
+)
+
+// MaxWorkspaceNameLength is the longest a workspace's name can be, in
+// characters.
+const MaxWorkspaceNameLength = 100
+
+// workspaceCacheTTL is how long the workspaces are served from memory before
+// they are read again, so that changes made on other servers are picked up.
+const workspaceCacheTTL = time.Minute
+
+var (
+    // ErrInvalidWorkspace is returned for workspaces without a name or with
+    // an overlong one, a domain that is not a host name, a logo that is not
+    // an http(s) URL, or colors that are not hex colors such as #1a73e8.
+    ErrInvalidWorkspace = errors.New("a workspace needs a name of 1-100 characters and a domain such as chat.example.com; logo_url must be an http(s) URL and colors hex colors such as #1a73e8")
+    // ErrWorkspaceDomainTaken is returned when a domain is already registered
+    // to another workspace.
+    ErrWorkspaceDomainTaken = errors.New("domain is already registered to a workspace")
+    // ErrWorkspaceNotFound is returned for unknown workspaces.
+    ErrWorkspaceNotFound = errors.New("workspace not found")
+)
+
+var (
+    // hostLabel matches a label of a host name.
+    hostLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+    // hexColor matches #rgb and #rrggbb colors.
+    hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// Branding is what clients show a server or workspace as.
+type Branding struct {
+    Name         string  `json:"name" example:"Acme Chat"`
+    LogoURL      *string `json:"logo_url,omitempty" example:"https://cdn.acme.com/logo.png"`
+    PrimaryColor *string `json:"primary_color,omitempty" example:"#1a73e8"`
+    AccentColor  *string `json:"accent_color,omitempty" example:"#fbbc04"`
+}
+
+// Workspace brands the server for requests made to its own domain, for
+// white-label deployments. Rooms and users are shared by every workspace.
+type Workspace struct {
+    ID     string `json:"id"`
+    Domain string `json:"domain" example:"chat.acme.com"`
+    Branding
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// workspaceKey is the context key of the workspace a request was made to.
+type workspaceKey struct{}
+
+// WithWorkspace marks ctx as belonging to a request made to the workspace's
+// domain.
+func WithWorkspace(ctx context.Context, workspace *Workspace) context.Context {
+    return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFrom returns the workspace the request behind ctx was made to, if
+// any.
+func WorkspaceFrom(ctx context.Context) (*Workspace, bool) {
+    workspace, ok := ctx.Value(workspaceKey{}).(*Workspace)
+    return workspace, ok
+}
+
+// WorkspaceService manages workspaces and finds the one a host belongs to.
+type WorkspaceService struct {
+    db *database.Queries
+    // defaults is the branding of requests to no workspace.
+    defaults Branding
+
+    mu       sync.Mutex
+    byDomain map[string]*Workspace
+    loadedAt time.Time
+}
+
+// NewWorkspaceService creates a new WorkspaceService. Requests made to no
+// workspace's domain are branded with defaults.
+func NewWorkspaceService(db *database.Queries, defaults Branding) *WorkspaceService {
+    return &WorkspaceService{db: db, defaults: defaults}
+}
+
+// DefaultBranding returns the branding of requests made to no workspace.
+func (s *WorkspaceService) DefaultBranding() Branding {
+    return s.defaults
+}
+
+// Workspaces returns every workspace, by domain.
+func (s *WorkspaceService) Workspaces(ctx context.Context) ([]Workspace, error) {
+    rows, err := s.db.GetWorkspaces(ctx)
+    if err != nil {
+        return nil, err
+    }
+    workspaces := make([]Workspace, 0, len(rows))
+    for _, row := range rows {
+        workspaces = append(workspaces, *workspaceFromRow(row))
+    }
+    return workspaces, nil
+}
+
+// Create registers a workspace for the domain.
+func (s *WorkspaceService) Create(ctx context.Context, domain string, branding Branding) (*Workspace, error) {
+    domain, branding, err := normalizeWorkspace(domain, branding)
+    if err != nil {
+        return nil, err
+    }
+    row, err := s.db.CreateWorkspace(ctx, database.CreateWorkspaceParams{
+        ID:           uuid.New(),
+        Name:         branding.Name,
+        Domain:       domain,
+        LogoUrl:      branding.LogoURL,
+        PrimaryColor: branding.PrimaryColor,
+        AccentColor:  branding.AccentColor,
+    })
+    if err != nil {
+        return nil, workspaceError(err)
+    }
+    s.invalidate()
+    return workspaceFromRow(row), nil
+}
+
+// Update replaces a workspace's domain and branding.
+func (s *WorkspaceService) Update(ctx context.Context, workspaceID uuid.UUID, domain string, branding Branding) (*Workspace, error) {
+    domain, branding, err := normalizeWorkspace(domain, branding)
+    if err != nil {
+        return nil, err
+    }
+    row, err := s.db.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
+        ID:           workspaceID,
+        Name:         branding.Name,
+        Domain:       domain,
+        LogoUrl:      branding.LogoURL,
+        PrimaryColor: branding.PrimaryColor,
+        AccentColor:  branding.AccentColor,
+    })
+    if err != nil {
+        return nil, workspaceError(err)
+    }
+    s.invalidate()
+    return workspaceFromRow(row), nil
+}
+
+// Delete removes a workspace; its domain is then served unbranded.
+func (s *WorkspaceService) Delete(ctx context.Context, workspaceID uuid.UUID) error {
+    n, err := s.db.DeleteWorkspace(ctx, workspaceID)
+    if err != nil {
+        return err
+    }
+    if n == 0 {
+        return ErrWorkspaceNotFound
+    }
+    s.invalidate()
+    return nil
+}
+
+// ForHost returns the workspace whose domain is host, a Host header with or
+// without a port, or nil if there is none. Workspaces are read at most once
+// a minute; changes made on this server are seen straight away.
+func (s *WorkspaceService) ForHost(ctx context.Context, host string) (*Workspace, error) {
+    if h, _, err := net.SplitHostPort(host); err == nil {
+        host = h
+    }
+    host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.byDomain == nil || time.Since(s.loadedAt) > workspaceCacheTTL {
+        rows, err := s.db.GetWorkspaces(ctx)
+        if err != nil {
+            return nil, err
+        }
+        s.byDomain = make(map[string]*Workspace, len(rows))
+        for _, row := range rows {
+            s.byDomain[row.Domain] = workspaceFromRow(row)
+        }
+        s.loadedAt = time.Now()
+    }
+    return s.byDomain[host], nil
+}
+
+// invalidate has the workspaces read again on the next lookup.
+func (s *WorkspaceService) invalidate() {
+    s.mu.Lock()
+    s.byDomain = nil
+    s.mu.Unlock()
+}
+
+// normalizeWorkspace trims a workspace's fields, lowercases its domain and
+// checks them. Empty optional fields are cleared.
+func normalizeWorkspace(domain string, branding Branding) (string, Branding, error) {
+    domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+    labels := strings.Split(domain, ".")
+    if len(domain) > 253 || len(labels) < 2 {
+        return "", Branding{}, ErrInvalidWorkspace
+    }
+    for _, label := range labels {
+        if !hostLabel.MatchString(label) {
+            return "", Branding{}, ErrInvalidWorkspace
+        }
+    }
+
+    branding.Name = strings.TrimSpace(branding.Name)
+    if branding.Name == "" || utf8.RuneCountInString(branding.Name) > MaxWorkspaceNameLength {
+        return "", Branding{}, ErrInvalidWorkspace
+    }
+    branding.LogoURL = trimOptional(branding.LogoURL)
+    if branding.LogoURL != nil {
+        u, err := url.Parse(*branding.LogoURL)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return "", Branding{}, ErrInvalidWorkspace
+        }
+    }
+    for _, color := range []**string{&branding.PrimaryColor, &branding.AccentColor} {
+        *color = trimOptional(*color)
+        if *color != nil && !hexColor.MatchString(**color) {
+            return "", Branding{}, ErrInvalidWorkspace
+        }
+    }
+    return domain, branding, nil
+}
+
+// trimOptional trims an optional field, clearing it when it is empty.
+func trimOptional(value *string) *string {
+    if value == nil {
+        return nil
+    }
+    trimmed := strings.TrimSpace(*value)
+    if trimmed == "" {
+        return nil
+    }
+    return &trimmed
+}
+
+// workspaceError maps a workspace write's error.
+func workspaceError(err error) error {
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+        return ErrWorkspaceDomainTaken
+    }
+    if errors.Is(err, pgx.ErrNoRows) {
+        return ErrWorkspaceNotFound
+    }
+    return err
+}
+
+func workspaceFromRow(row database.Workspace) *Workspace {
+    return &Workspace{
+        ID:     row.ID.String(),
+        Domain: row.Domain,
+        Branding: Branding{
+            Name:         row.Name,
+            LogoURL:      row.LogoUrl,
+            PrimaryColor: row.PrimaryColor,
+            AccentColor:  row.AccentColor,
+        },
+        CreatedAt: row.CreatedAt,
+        UpdatedAt: row.UpdatedAt,
+    }
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE messages (
+    id UUID PRIMARY KEY,
+    seq BIGSERIAL NOT NULL UNIQUE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_messages_room_seq ON messages (room_id, seq);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS messages;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE messages ADD COLUMN kind TEXT NOT NULL DEFAULT 'text';
+
+CREATE TABLE polls (
+    id UUID PRIMARY KEY,
+    message_id UUID NOT NULL UNIQUE REFERENCES messages(id) ON DELETE CASCADE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ
+);
+
+CREATE TABLE poll_options (
+    id UUID PRIMARY KEY,
+    poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE (poll_id, id),
+    UNIQUE (poll_id, position)
+);
+
+CREATE TABLE poll_votes (
+    poll_id UUID NOT NULL,
+    option_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    voted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (poll_id, user_id),
+    FOREIGN KEY (poll_id, option_id) REFERENCES poll_options(poll_id, id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+ALTER TABLE messages DROP COLUMN kind;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE messages ADD COLUMN quoted_message_id UUID REFERENCES messages(id) ON DELETE SET NULL;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE messages DROP COLUMN quoted_message_id;
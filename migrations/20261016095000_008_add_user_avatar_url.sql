@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE users ADD COLUMN avatar_url TEXT;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE users DROP COLUMN avatar_url;
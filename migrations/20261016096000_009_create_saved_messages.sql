@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE saved_messages (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, message_id)
+);
+
+CREATE INDEX idx_saved_messages_user_created ON saved_messages (user_id, created_at DESC);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS saved_messages;
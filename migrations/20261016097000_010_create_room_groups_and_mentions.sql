@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE room_groups (
+    id UUID PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (room_id, name)
+);
+
+CREATE TABLE room_group_members (
+    group_id UUID NOT NULL REFERENCES room_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, user_id)
+);
+
+ALTER TABLE messages ADD COLUMN mentions UUID[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications (user_id, created_at DESC);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS notifications;
+ALTER TABLE messages DROP COLUMN mentions;
+DROP TABLE IF EXISTS room_group_members;
+DROP TABLE IF EXISTS room_groups;
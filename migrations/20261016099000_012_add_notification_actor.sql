@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE notifications ADD COLUMN actor_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE notifications DROP COLUMN actor_id;
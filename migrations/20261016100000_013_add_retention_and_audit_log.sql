@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE rooms ADD COLUMN retention_days INT;
+ALTER TABLE rooms ADD COLUMN retention_max_messages INT;
+ALTER TABLE rooms ADD COLUMN retention_hold BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    room_id UUID REFERENCES rooms(id) ON DELETE SET NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_room_created ON audit_log (room_id, created_at DESC);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE rooms DROP COLUMN retention_hold;
+ALTER TABLE rooms DROP COLUMN retention_max_messages;
+ALTER TABLE rooms DROP COLUMN retention_days;
+ALTER TABLE users DROP COLUMN is_admin;
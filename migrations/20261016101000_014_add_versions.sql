@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE rooms ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE rooms ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE users DROP COLUMN updated_at;
+ALTER TABLE users DROP COLUMN version;
+ALTER TABLE rooms DROP COLUMN updated_at;
+ALTER TABLE rooms DROP COLUMN version;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE users ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The built-in system bot sends onboarding messages. Its password is not a
+-- valid bcrypt hash, so nobody can log in as it.
+INSERT INTO users (id, username, password, is_bot)
+VALUES ('00000000-0000-0000-0000-000000000001', 'system', '!', TRUE);
+
+-- New users are added to this room and welcomed there by direct message.
+INSERT INTO rooms (id, name, owner_id)
+VALUES ('00000000-0000-0000-0000-000000000001', 'system-welcome', '00000000-0000-0000-0000-000000000001');
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DELETE FROM rooms WHERE id = '00000000-0000-0000-0000-000000000001';
+DELETE FROM users WHERE id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE users DROP COLUMN is_bot;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- Each row is a previous version of a message, replaced at created_at.
+CREATE TABLE message_revisions (
+    id UUID PRIMARY KEY,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    edited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_revisions_message ON message_revisions (message_id, created_at);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS message_revisions;
+ALTER TABLE messages DROP COLUMN edited_at;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE rooms ADD COLUMN allow_urgent BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE messages ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal';
+
+CREATE INDEX idx_messages_urgent_sender ON messages (sender_id, created_at) WHERE priority = 'urgent';
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_messages_urgent_sender;
+ALTER TABLE messages DROP COLUMN priority;
+ALTER TABLE rooms DROP COLUMN allow_urgent;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE messages ADD COLUMN client_msg_id TEXT;
+
+CREATE UNIQUE INDEX idx_messages_sender_client_msg_id ON messages (sender_id, client_msg_id) WHERE client_msg_id IS NOT NULL;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_messages_sender_client_msg_id;
+ALTER TABLE messages DROP COLUMN client_msg_id;
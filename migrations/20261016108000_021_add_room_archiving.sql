@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- joined_at orders successors when a room's owner deletes their account;
+-- existing members count as having joined now.
+ALTER TABLE room_members ADD COLUMN joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE rooms ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN archived_at;
+ALTER TABLE room_members DROP COLUMN joined_at;
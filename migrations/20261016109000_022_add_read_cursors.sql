@@ -0,0 +1,12 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- last_read_seq is the sequence number of the last message the member has
+-- read. Existing members start with everything read.
+ALTER TABLE room_members ADD COLUMN last_read_seq BIGINT NOT NULL DEFAULT 0;
+
+UPDATE room_members AS rm
+SET last_read_seq = COALESCE((SELECT MAX(m.seq) FROM messages AS m WHERE m.room_id = rm.room_id), 0);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE room_members DROP COLUMN last_read_seq;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Private rooms are listed only to their members; joining one takes an
+-- owner's approval of a join request.
+ALTER TABLE rooms ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private'));
+
+CREATE TABLE room_join_requests (
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id)
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_join_requests;
+ALTER TABLE rooms DROP COLUMN visibility;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Outbound webhooks of a room. Each one is sent only the event types listed
+-- in events; the secret signs every delivery.
+CREATE TABLE room_webhooks (
+    id UUID PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_room_webhooks_room_id ON room_webhooks(room_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_webhooks;
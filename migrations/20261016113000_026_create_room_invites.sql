@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Pending invitations to join a room. Accepting or declining one deletes it;
+-- inviting the same user again renews it.
+CREATE TABLE room_invites (
+    id UUID PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (room_id, user_id)
+);
+
+CREATE INDEX idx_room_invites_user_id ON room_invites(user_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_invites;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Each member has a role in the room. Owners, the owner the room was created
+-- by included, have every right over it; co-owners become owners.
+ALTER TABLE room_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'moderator', 'member'));
+
+UPDATE room_members AS rm SET role = 'owner'
+WHERE EXISTS (SELECT 1 FROM room_co_owners AS co WHERE co.room_id = rm.room_id AND co.user_id = rm.user_id)
+    OR EXISTS (SELECT 1 FROM rooms AS r WHERE r.id = rm.room_id AND r.owner_id = rm.user_id);
+
+DROP TABLE room_co_owners;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+CREATE TABLE room_co_owners (
+    room_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    added_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id),
+    FOREIGN KEY (room_id, user_id) REFERENCES room_members(room_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO room_co_owners (room_id, user_id)
+SELECT rm.room_id, rm.user_id FROM room_members AS rm
+JOIN rooms AS r ON r.id = rm.room_id
+WHERE rm.role = 'owner' AND rm.user_id <> r.owner_id;
+
+ALTER TABLE room_members DROP COLUMN role;
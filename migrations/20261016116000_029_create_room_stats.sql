@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Rooms opt in to engagement stats, which a background job computes and
+-- stores as one JSON document per room.
+ALTER TABLE rooms ADD COLUMN stats_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE room_stats (
+    room_id UUID PRIMARY KEY REFERENCES rooms(id) ON DELETE CASCADE,
+    stats JSONB NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_stats;
+ALTER TABLE rooms DROP COLUMN IF EXISTS stats_enabled;
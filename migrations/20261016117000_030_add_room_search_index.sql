@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Room search matches names by substring and trigram similarity, both of
+-- which a trigram index serves.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_rooms_name_trgm ON rooms USING GIN (name gin_trgm_ops);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_rooms_name_trgm;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- A room's topic is a one-line summary of what it is about right now; its
+-- description explains the room at length. Room search matches both.
+ALTER TABLE rooms ADD COLUMN topic TEXT NOT NULL DEFAULT '';
+ALTER TABLE rooms ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_rooms_description_trgm ON rooms USING GIN (description gin_trgm_ops);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_rooms_description_trgm;
+ALTER TABLE rooms DROP COLUMN IF EXISTS description;
+ALTER TABLE rooms DROP COLUMN IF EXISTS topic;
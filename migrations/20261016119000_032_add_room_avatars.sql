@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Room avatars live in object storage. avatar_key is the storage key, kept
+-- so the old image can be deleted when the avatar changes.
+ALTER TABLE rooms ADD COLUMN avatar_url TEXT;
+ALTER TABLE rooms ADD COLUMN avatar_key TEXT;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN IF EXISTS avatar_key;
+ALTER TABLE rooms DROP COLUMN IF EXISTS avatar_url;
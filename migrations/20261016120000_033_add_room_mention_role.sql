@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- @room and @here mention a whole room at once, so only members with at
+-- least this role may use them.
+ALTER TABLE rooms ADD COLUMN room_mention_role TEXT NOT NULL DEFAULT 'moderator' CHECK (room_mention_role IN ('owner', 'moderator', 'member'));
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN IF EXISTS room_mention_role;
@@ -0,0 +1,83 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Every change to a room's members bumps the room's member_version and is
+-- logged, so clients holding a member list can catch up from their version
+-- instead of fetching the whole list. Memberships that predate the log are
+-- covered by starting existing rooms at version 1 with no changes logged,
+-- which sends their clients to the full list.
+ALTER TABLE rooms ADD COLUMN member_version BIGINT NOT NULL DEFAULT 0;
+UPDATE rooms SET member_version = 1 WHERE EXISTS (SELECT 1 FROM room_members WHERE room_id = rooms.id);
+
+CREATE TABLE room_member_changes (
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    version BIGINT NOT NULL,
+    user_id UUID NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('add', 'remove', 'role')),
+    role TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, version)
+);
+CREATE INDEX idx_room_member_changes_changed_at ON room_member_changes (changed_at);
+
+-- A trigger catches every change, cascades from deleted users included. Each
+-- change is also sent on the room_member_changes channel, on commit, for the
+-- hub to relay to connected members.
+-- +goose StatementBegin
+CREATE FUNCTION log_room_member_change() RETURNS trigger AS $$
+DECLARE
+    change_room_id UUID;
+    change_user_id UUID;
+    change_action TEXT;
+    change_role TEXT;
+    change_version BIGINT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        change_room_id := NEW.room_id;
+        change_user_id := NEW.user_id;
+        change_action := 'add';
+        change_role := NEW.role;
+    ELSIF TG_OP = 'DELETE' THEN
+        change_room_id := OLD.room_id;
+        change_user_id := OLD.user_id;
+        change_action := 'remove';
+    ELSE
+        IF NEW.role IS NOT DISTINCT FROM OLD.role THEN
+            RETURN NULL;
+        END IF;
+        change_room_id := NEW.room_id;
+        change_user_id := NEW.user_id;
+        change_action := 'role';
+        change_role := NEW.role;
+    END IF;
+
+    UPDATE rooms SET member_version = member_version + 1 WHERE id = change_room_id
+    RETURNING member_version INTO change_version;
+    IF NOT FOUND THEN
+        -- The room itself is being deleted.
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO room_member_changes (room_id, version, user_id, action, role)
+    VALUES (change_room_id, change_version, change_user_id, change_action, change_role);
+    PERFORM pg_notify('room_member_changes', json_build_object(
+        'room_id', change_room_id,
+        'version', change_version,
+        'user_id', change_user_id,
+        'action', change_action,
+        'role', change_role
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER room_member_changes
+AFTER INSERT OR DELETE OR UPDATE OF role ON room_members
+FOR EACH ROW EXECUTE FUNCTION log_room_member_change();
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS room_member_changes ON room_members;
+DROP FUNCTION IF EXISTS log_room_member_change();
+DROP TABLE IF EXISTS room_member_changes;
+ALTER TABLE rooms DROP COLUMN IF EXISTS member_version;
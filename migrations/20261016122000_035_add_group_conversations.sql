@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Group conversations are private rooms of kind 'group_dm'. They are only
+-- visible to their participants and never listed or searched.
+ALTER TABLE rooms ADD COLUMN kind TEXT NOT NULL DEFAULT 'room' CHECK (kind IN ('room', 'group_dm'));
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DELETE FROM rooms WHERE kind = 'group_dm';
+ALTER TABLE rooms DROP COLUMN IF EXISTS kind;
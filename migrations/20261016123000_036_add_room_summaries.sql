@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Rooms opt in to catch-up summaries, which send their messages to an
+-- external summarization provider.
+ALTER TABLE rooms ADD COLUMN summaries_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN IF EXISTS summaries_enabled;
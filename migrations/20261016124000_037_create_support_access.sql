@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Users grant support access for a limited time; while it lasts,
+-- administrators can impersonate them. Audit entries written during an
+-- impersonation record the administrator behind it.
+CREATE TABLE support_access_grants (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE audit_log ADD COLUMN impersonator_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE audit_log DROP COLUMN IF EXISTS impersonator_id;
+DROP TABLE IF EXISTS support_access_grants;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Rooms are tagged with categories such as "gaming" or "golang", so the room
+-- directory can be browsed and filtered by tag.
+CREATE TABLE room_tags (
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (room_id, tag)
+);
+
+CREATE INDEX idx_room_tags_tag ON room_tags (tag, room_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_tags;
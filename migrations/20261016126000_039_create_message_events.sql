@@ -0,0 +1,88 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- With MESSAGE_STORAGE=events, messages are stored as an append-only log of
+-- events: a message.created event is the immutable record of a message, and
+-- edits, deletions and annotations are later events about it. The messages,
+-- message_revisions and message_annotations tables become read models, built
+-- from the log by the projection below as each event is appended, so
+-- replaying the log into an empty database rebuilds them. Events go with
+-- their room and their actor; retention forgets the events of the messages
+-- it purges.
+CREATE TABLE message_events (
+    position BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    message_id UUID NOT NULL,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('message.created', 'message.edited', 'message.deleted', 'message.annotated')),
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_message_events_message ON message_events (message_id, position);
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_message_event_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'message_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER message_events_append_only
+BEFORE UPDATE ON message_events
+FOR EACH ROW EXECUTE FUNCTION reject_message_event_update();
+
+-- The projection applies each event to the read models in the statement that
+-- appends it, so they never lag behind the log. Snapshots of messages stored
+-- before the log existed are skipped, since the message is already there.
+-- +goose StatementBegin
+CREATE FUNCTION project_message_event() RETURNS trigger AS $$
+BEGIN
+    IF NEW.type = 'message.created' THEN
+        INSERT INTO messages (id, room_id, sender_id, recipient_id, content, metadata, kind, quoted_message_id, mentions, priority, client_msg_id, edited_at, created_at)
+        VALUES (
+            NEW.message_id,
+            NEW.room_id,
+            NEW.actor_id,
+            (NEW.data->>'recipient_id')::uuid,
+            NEW.data->>'content',
+            COALESCE(NEW.data->'metadata', '{}'::jsonb),
+            COALESCE(NEW.data->>'kind', 'text'),
+            (NEW.data->>'quoted_message_id')::uuid,
+            ARRAY(SELECT jsonb_array_elements_text(COALESCE(NEW.data->'mentions', '[]'::jsonb)))::uuid[],
+            COALESCE(NEW.data->>'priority', 'normal'),
+            NEW.data->>'client_msg_id',
+            (NEW.data->>'edited_at')::timestamptz,
+            NEW.created_at
+        )
+        ON CONFLICT (id) DO NOTHING;
+    ELSIF NEW.type = 'message.edited' THEN
+        INSERT INTO message_revisions (id, message_id, content, edited_by, created_at)
+        SELECT (NEW.data->>'revision_id')::uuid, m.id, m.content, NEW.actor_id, NEW.created_at
+        FROM messages AS m WHERE m.id = NEW.message_id;
+        UPDATE messages SET content = NEW.data->>'content', edited_at = NEW.created_at
+        WHERE id = NEW.message_id;
+    ELSIF NEW.type = 'message.deleted' THEN
+        DELETE FROM messages WHERE id = NEW.message_id;
+    ELSIF NEW.type = 'message.annotated' THEN
+        INSERT INTO message_annotations (id, message_id, author_id, kind, data, created_at)
+        SELECT (NEW.data->>'annotation_id')::uuid, m.id, NEW.actor_id, NEW.data->>'kind', COALESCE(NEW.data->'data', '{}'::jsonb), NEW.created_at
+        FROM messages AS m WHERE m.id = NEW.message_id
+        ON CONFLICT (id) DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER message_events_projection
+AFTER INSERT ON message_events
+FOR EACH ROW EXECUTE FUNCTION project_message_event();
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS message_events_projection ON message_events;
+DROP TRIGGER IF EXISTS message_events_append_only ON message_events;
+DROP FUNCTION IF EXISTS project_message_event();
+DROP FUNCTION IF EXISTS reject_message_event_update();
+DROP TABLE IF EXISTS message_events;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Shareable invite codes let anyone holding one join a room, private rooms
+-- included, until the code expires or has been used max_uses times.
+CREATE TABLE room_invite_codes (
+    code TEXT PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    max_uses INTEGER,
+    uses INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_room_invite_codes_room_id ON room_invite_codes(room_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_invite_codes;
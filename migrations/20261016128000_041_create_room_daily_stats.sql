@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- The stats job also keeps a daily time series per room, which owners export.
+-- Days are UTC; once over, a day's row is kept as is, so the series outlives
+-- the messages that retention purges.
+CREATE TABLE room_daily_stats (
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    messages BIGINT NOT NULL,
+    active_members INTEGER NOT NULL,
+    PRIMARY KEY (room_id, day)
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_daily_stats;
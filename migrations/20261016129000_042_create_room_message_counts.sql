@@ -0,0 +1,70 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Counters of each room's messages and of when the latest was sent, for the
+-- room directory, kept up to date by triggers so listing rooms does not count
+-- their messages. Direct messages are not counted. Deleting messages leaves
+-- last_message_at as it was.
+CREATE TABLE room_message_counts (
+    room_id UUID PRIMARY KEY REFERENCES rooms(id) ON DELETE CASCADE,
+    messages BIGINT NOT NULL DEFAULT 0,
+    last_message_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO room_message_counts (room_id, messages, last_message_at)
+SELECT room_id, COUNT(*), MAX(created_at)
+FROM messages
+WHERE recipient_id IS NULL
+GROUP BY room_id;
+
+-- The triggers run once per statement, so bulk inserts and deletes, such as
+-- retention purges, update each room's counter once.
+-- +goose StatementBegin
+CREATE FUNCTION count_inserted_messages() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO room_message_counts AS c (room_id, messages, last_message_at)
+    SELECT room_id, COUNT(*), MAX(created_at)
+    FROM inserted
+    WHERE recipient_id IS NULL
+    GROUP BY room_id
+    ON CONFLICT (room_id) DO UPDATE
+    SET messages = c.messages + EXCLUDED.messages,
+        last_message_at = GREATEST(c.last_message_at, EXCLUDED.last_message_at);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION count_deleted_messages() RETURNS trigger AS $$
+BEGIN
+    UPDATE room_message_counts AS c
+    SET messages = c.messages - d.messages
+    FROM (
+        SELECT room_id, COUNT(*) AS messages
+        FROM deleted
+        WHERE recipient_id IS NULL
+        GROUP BY room_id
+    ) AS d
+    WHERE c.room_id = d.room_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER room_message_counts_insert
+AFTER INSERT ON messages
+REFERENCING NEW TABLE AS inserted
+FOR EACH STATEMENT EXECUTE FUNCTION count_inserted_messages();
+
+CREATE TRIGGER room_message_counts_delete
+AFTER DELETE ON messages
+REFERENCING OLD TABLE AS deleted
+FOR EACH STATEMENT EXECUTE FUNCTION count_deleted_messages();
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS room_message_counts_delete ON messages;
+DROP TRIGGER IF EXISTS room_message_counts_insert ON messages;
+DROP FUNCTION IF EXISTS count_deleted_messages();
+DROP FUNCTION IF EXISTS count_inserted_messages();
+DROP TABLE IF EXISTS room_message_counts;
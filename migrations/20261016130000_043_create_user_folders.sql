@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Users sort the rooms and conversations they are in into named folders of
+-- their own. A room is in at most one of a user's folders; the folder's
+-- user_id is repeated in user_folder_rooms so that the primary key can enforce
+-- it, and the foreign key keeps it the folder's owner.
+CREATE TABLE user_folders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name),
+    UNIQUE (id, user_id)
+);
+
+CREATE TABLE user_folder_rooms (
+    user_id UUID NOT NULL,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    folder_id UUID NOT NULL,
+    PRIMARY KEY (user_id, room_id),
+    FOREIGN KEY (folder_id, user_id) REFERENCES user_folders(id, user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_folder_rooms_folder_id ON user_folder_rooms (folder_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS user_folder_rooms;
+DROP TABLE IF EXISTS user_folders;
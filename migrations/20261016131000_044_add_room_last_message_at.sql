@@ -0,0 +1,42 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- When each room's latest message was sent, so room listings can sort by
+-- activity without looking at every room's messages. NULL until the room has
+-- a message. A trigger keeps it up to date on every insert, whichever way the
+-- message is stored; deleting messages leaves it as it was.
+ALTER TABLE rooms ADD COLUMN last_message_at TIMESTAMPTZ;
+
+UPDATE rooms SET last_message_at = latest.created_at
+FROM (
+    SELECT room_id, MAX(created_at) AS created_at
+    FROM messages
+    GROUP BY room_id
+) AS latest
+WHERE rooms.id = latest.room_id;
+
+-- +goose StatementBegin
+CREATE FUNCTION touch_room_last_message() RETURNS trigger AS $$
+BEGIN
+    UPDATE rooms
+    SET last_message_at = GREATEST(rooms.last_message_at, latest.created_at)
+    FROM (
+        SELECT room_id, MAX(created_at) AS created_at
+        FROM inserted
+        GROUP BY room_id
+    ) AS latest
+    WHERE rooms.id = latest.room_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER rooms_last_message_at
+AFTER INSERT ON messages
+REFERENCING NEW TABLE AS inserted
+FOR EACH STATEMENT EXECUTE FUNCTION touch_room_last_message();
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS rooms_last_message_at ON messages;
+DROP FUNCTION IF EXISTS touch_room_last_message();
+ALTER TABLE rooms DROP COLUMN IF EXISTS last_message_at;
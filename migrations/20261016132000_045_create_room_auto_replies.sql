@@ -0,0 +1,22 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Automatic replies room owners set up: when a message mentions one of a
+-- rule's keywords, the system bot answers in the room with the rule's reply,
+-- at most once per cooldown for each sender.
+CREATE TABLE room_auto_replies (
+    id UUID PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    keywords TEXT[] NOT NULL,
+    reply TEXT NOT NULL,
+    cooldown_seconds INTEGER NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_room_auto_replies_room_id ON room_auto_replies (room_id, created_at);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_auto_replies;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- How much each member wants to be notified about a room: of every message
+-- ('all'), of mentions and direct messages ('mentions', the default when a
+-- member has no row) or of nothing ('none'). Rows go with the membership.
+CREATE TABLE room_notification_settings (
+    room_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    level TEXT NOT NULL CHECK (level IN ('all', 'mentions', 'none')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id),
+    FOREIGN KEY (room_id, user_id) REFERENCES room_members(room_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_room_notification_settings_user_id ON room_notification_settings (user_id);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS room_notification_settings;
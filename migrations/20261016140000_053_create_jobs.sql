@@ -0,0 +1,25 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Background work that has to survive restarts and failures, such as webhook
+-- deliveries, is queued here and run by the server's job workers. A job is
+-- claimed by setting it running; one still running after the lease ran out
+-- belonged to a worker that died, and is claimed again.
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_due ON jobs (run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_jobs_status ON jobs (status, type, created_at DESC);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Temporary rooms are created with an expiry, after which a background job
+-- deletes them along with everything in them. NULL for rooms that never
+-- expire.
+ALTER TABLE rooms ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_rooms_expires_at ON rooms (expires_at) WHERE expires_at IS NOT NULL;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_rooms_expires_at;
+ALTER TABLE rooms DROP COLUMN IF EXISTS expires_at;
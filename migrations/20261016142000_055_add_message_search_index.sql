@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Message search is full-text over message content. The 'simple'
+-- configuration does no stemming or stop words, so it works the same for
+-- every language messages are written in.
+CREATE INDEX idx_messages_content_fts ON messages USING GIN (to_tsvector('simple', content));
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_messages_content_fts;
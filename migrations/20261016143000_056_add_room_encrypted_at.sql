@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Set once a group conversation opts in to end-to-end encryption, after which
+-- only encrypted messages are accepted in it. Opting in cannot be undone.
+ALTER TABLE rooms ADD COLUMN encrypted_at TIMESTAMPTZ;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN IF EXISTS encrypted_at;
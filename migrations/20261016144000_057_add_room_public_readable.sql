@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Public-readable rooms have their recent messages served to anyone, without
+-- authentication, for embedding on websites.
+ALTER TABLE rooms ADD COLUMN public_readable BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE rooms DROP COLUMN IF EXISTS public_readable;
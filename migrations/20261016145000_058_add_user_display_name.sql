@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Display names are users' real names, shown instead of or beside usernames
+-- depending on the server's DISPLAY_NAME_POLICY. Empty means none is set.
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Custom statuses users show beside their presence, such as "in a meeting".
+CREATE TABLE user_statuses (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS user_statuses;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Message purges delete a room's messages by sender and/or time range as a
+-- background job, in batches, recording their progress as they go. They only
+-- cover messages sent before end_time, which is never later than when the
+-- purge was requested. Purges go with the moderator who requested them.
+CREATE TABLE message_purges (
+    id UUID PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID,
+    start_time TIMESTAMPTZ,
+    end_time TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded')),
+    total BIGINT NOT NULL,
+    deleted BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+CREATE INDEX idx_message_purges_room ON message_purges (room_id, created_at DESC);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS message_purges;
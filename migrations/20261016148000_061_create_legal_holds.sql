@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Legal holds preserve a user's or a room's messages until an administrator
+-- releases them: retention, bulk deletion and purges skip held messages, and
+-- rooms and accounts with held messages cannot be deleted. A message is held
+-- when its room or its sender is.
+CREATE TABLE legal_holds (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    room_id UUID REFERENCES rooms(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((user_id IS NULL) <> (room_id IS NULL))
+);
+CREATE UNIQUE INDEX idx_legal_holds_user ON legal_holds (user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_legal_holds_room ON legal_holds (room_id) WHERE room_id IS NOT NULL;
+
+-- +goose StatementBegin
+CREATE FUNCTION message_under_legal_hold(message_room_id UUID, message_sender_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM legal_holds
+        WHERE room_id = message_room_id OR user_id = message_sender_id
+    );
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP FUNCTION IF EXISTS message_under_legal_hold(UUID, UUID);
+DROP TABLE IF EXISTS legal_holds;
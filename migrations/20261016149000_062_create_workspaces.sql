@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Workspaces brand the server for white-label deployments: requests for a
+-- workspace's domain are served with its name, logo and colors. Domains are
+-- stored lowercase, without a port.
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    domain TEXT NOT NULL UNIQUE,
+    logo_url TEXT,
+    primary_color TEXT,
+    accent_color TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Client message IDs identify retries of a message to one room; the same ID
+-- sent to another room is another message.
+CREATE UNIQUE INDEX idx_messages_room_sender_client_msg_id ON messages (room_id, sender_id, client_msg_id) WHERE client_msg_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_messages_sender_client_msg_id;
+
+-- +goose Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+CREATE UNIQUE INDEX idx_messages_sender_client_msg_id ON messages (sender_id, client_msg_id) WHERE client_msg_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_messages_room_sender_client_msg_id;
@@ -0,0 +1,7 @@
+-- name: CreateMessageAnnotation :one
+INSERT INTO message_annotations (id, message_id, author_id, kind, data) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetMessageAnnotations :many
+SELECT * FROM message_annotations
+WHERE message_id = ANY(@message_ids::uuid[])
+ORDER BY created_at ASC, id ASC;
@@ -0,0 +1,15 @@
+-- name: SetRoomAnnouncement :one
+INSERT INTO room_announcements (room_id, content, set_by, expires_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (room_id) DO UPDATE
+SET content = EXCLUDED.content, set_by = EXCLUDED.set_by, expires_at = EXCLUDED.expires_at, created_at = NOW()
+RETURNING *;
+
+-- name: GetRoomAnnouncement :one
+-- Expired announcements are treated as removed.
+SELECT * FROM room_announcements
+WHERE room_id = $1 AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: DeleteRoomAnnouncement :execrows
+-- Only announcements that have not expired count as removed.
+DELETE FROM room_announcements
+WHERE room_id = $1 AND (expires_at IS NULL OR expires_at > NOW());
@@ -0,0 +1,2 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_log (id, actor_id, action, room_id, details, impersonator_id) VALUES ($1, $2, $3, $4, $5, $6);
@@ -0,0 +1,19 @@
+-- name: CreateRoomAutoReply :one
+INSERT INTO room_auto_replies (id, room_id, keywords, reply, cooldown_seconds, enabled, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: CountRoomAutoReplies :one
+SELECT COUNT(*) FROM room_auto_replies WHERE room_id = $1;
+
+-- name: GetRoomAutoReplies :many
+SELECT * FROM room_auto_replies WHERE room_id = $1 ORDER BY created_at, id;
+
+-- name: UpdateRoomAutoReply :one
+UPDATE room_auto_replies
+SET keywords = $3, reply = $4, cooldown_seconds = $5, enabled = $6, updated_at = NOW()
+WHERE id = $1 AND room_id = $2
+RETURNING *;
+
+-- name: DeleteRoomAutoReply :execrows
+DELETE FROM room_auto_replies WHERE id = $1 AND room_id = $2;
@@ -0,0 +1,21 @@
+-- name: BanRoomMember :one
+-- Banning someone who is already banned replaces the ban.
+INSERT INTO room_bans (room_id, user_id, banned_by, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (room_id, user_id) DO UPDATE
+SET banned_by = EXCLUDED.banned_by, created_at = NOW(), expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: UnbanRoomMember :execrows
+DELETE FROM room_bans WHERE room_id = $1 AND user_id = $2;
+
+-- name: IsRoomBanned :one
+SELECT EXISTS(
+    SELECT 1 FROM room_bans
+    WHERE room_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+);
+
+-- name: GetRoomBans :many
+SELECT * FROM room_bans
+WHERE room_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC;
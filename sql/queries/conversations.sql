@@ -0,0 +1,20 @@
+-- name: CreateGroupConversation :one
+INSERT INTO rooms (id, name, owner_id, visibility, kind) VALUES ($1, $2, $3, 'private', 'group_dm') RETURNING *;
+
+-- name: GetUserGroupConversations :many
+-- Lists the group conversations a user takes part in, most recently active
+-- first.
+SELECT r.* FROM rooms AS r
+JOIN room_members AS rm ON rm.room_id = r.id AND rm.user_id = $1
+WHERE r.kind = 'group_dm'
+ORDER BY COALESCE(r.last_message_at, r.created_at) DESC, r.id DESC;
+
+-- name: CountRoomMembers :one
+SELECT COUNT(*) FROM room_members WHERE room_id = $1;
+
+-- name: EnableRoomEncryption :one
+-- Opts a room in to end-to-end encryption. A room that already opted in keeps
+-- its original opt-in time.
+UPDATE rooms SET encrypted_at = COALESCE(encrypted_at, NOW()), version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: GetRoomDirectory :many
+-- Lists a page of the public rooms that are not archived, with their member
+-- and message counts, most members, most messages or most recently active
+-- first. The cursor holds the sort key and ID of the previous page's last
+-- room: cursor_count for member_count and message_count, cursor_time for
+-- last_activity.
+SELECT listed.*
+FROM (
+    SELECT r.id, r.name, r.topic, r.description, r.avatar_url, r.created_at,
+        ARRAY(SELECT tag FROM room_tags WHERE room_id = r.id ORDER BY tag)::text[] AS tags,
+        (SELECT COUNT(*) FROM room_members WHERE room_id = r.id) AS member_count,
+        COALESCE(c.messages, 0)::bigint AS message_count,
+        COALESCE(c.last_message_at, r.created_at) AS last_activity_at
+    FROM rooms AS r
+    LEFT JOIN room_message_counts AS c ON c.room_id = r.id
+    WHERE r.kind = 'room' AND r.visibility = 'public' AND r.archived_at IS NULL
+      AND (sqlc.narg(tag)::text IS NULL
+           OR EXISTS (SELECT 1 FROM room_tags WHERE room_id = r.id AND tag = sqlc.narg(tag)::text))
+) AS listed
+WHERE sqlc.narg(cursor_id)::uuid IS NULL OR CASE @sort::text
+    WHEN 'message_count' THEN (listed.message_count, listed.id) < (sqlc.narg(cursor_count)::bigint, sqlc.narg(cursor_id)::uuid)
+    WHEN 'last_activity' THEN (listed.last_activity_at, listed.id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::uuid)
+    ELSE (listed.member_count, listed.id) < (sqlc.narg(cursor_count)::bigint, sqlc.narg(cursor_id)::uuid)
+END
+ORDER BY
+    CASE WHEN @sort::text = 'message_count' THEN listed.message_count END DESC,
+    CASE WHEN @sort::text = 'last_activity' THEN listed.last_activity_at END DESC,
+    CASE WHEN @sort::text NOT IN ('message_count', 'last_activity') THEN listed.member_count END DESC,
+    listed.id DESC
+LIMIT @max_results;
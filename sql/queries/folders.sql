@@ -0,0 +1,49 @@
+-- name: CreateUserFolder :one
+-- Adds a folder after the user's other folders.
+INSERT INTO user_folders (id, user_id, name, position)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position) + 1, 0) FROM user_folders WHERE user_id = $2))
+RETURNING *;
+
+-- name: CountUserFolders :one
+SELECT COUNT(*) FROM user_folders WHERE user_id = $1;
+
+-- name: GetUserFolders :many
+SELECT * FROM user_folders WHERE user_id = $1 ORDER BY position, created_at;
+
+-- name: GetUserFolderRooms :many
+-- Lists the rooms in the user's folders, leaving out rooms the user has left.
+SELECT fr.folder_id, fr.room_id
+FROM user_folder_rooms AS fr
+JOIN room_members AS rm ON rm.room_id = fr.room_id AND rm.user_id = fr.user_id
+WHERE fr.user_id = $1
+ORDER BY fr.room_id;
+
+-- name: UpdateUserFolder :one
+-- Fields passed as NULL keep their current value.
+UPDATE user_folders
+SET name = COALESCE(sqlc.narg(name), name), position = COALESCE(sqlc.narg(position), position)
+WHERE id = @id AND user_id = @user_id
+RETURNING *;
+
+-- name: DeleteUserFolder :execrows
+DELETE FROM user_folders WHERE id = $1 AND user_id = $2;
+
+-- name: SetUserFolderRoom :exec
+-- Puts the room in the folder, taking it out of any other folder of the user.
+INSERT INTO user_folder_rooms (user_id, room_id, folder_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, room_id) DO UPDATE SET folder_id = EXCLUDED.folder_id;
+
+-- name: RemoveUserFolderRoom :execrows
+DELETE FROM user_folder_rooms WHERE user_id = $1 AND room_id = $2 AND folder_id = $3;
+
+-- name: GetUserSidebarRooms :many
+-- Lists the rooms and conversations the user is a member of, with the folder
+-- each is in, if any, and when it last had a message, most recent first.
+SELECT r.id, r.name, r.kind, fr.folder_id,
+    COALESCE(r.last_message_at, r.created_at) AS last_activity_at
+FROM room_members AS rm
+JOIN rooms AS r ON r.id = rm.room_id
+LEFT JOIN user_folder_rooms AS fr ON fr.user_id = rm.user_id AND fr.room_id = rm.room_id
+WHERE rm.user_id = $1
+ORDER BY last_activity_at DESC, r.id;
@@ -0,0 +1,43 @@
+-- name: CreateRoomGroup :one
+INSERT INTO room_groups (id, room_id, name, created_by) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetRoomGroupByID :one
+SELECT * FROM room_groups WHERE id = $1;
+
+-- name: GetRoomGroups :many
+SELECT * FROM room_groups WHERE room_id = $1 ORDER BY name;
+
+-- name: UpdateRoomGroupName :one
+UPDATE room_groups SET name = $2 WHERE id = $1 RETURNING *;
+
+-- name: DeleteRoomGroup :exec
+DELETE FROM room_groups WHERE id = $1;
+
+-- name: AddRoomGroupMembers :execrows
+-- Only users who are members of the room are added.
+INSERT INTO room_group_members (group_id, user_id)
+SELECT @group_id::uuid, rm.user_id FROM room_members AS rm
+WHERE rm.room_id = @room_id AND rm.user_id = ANY(@user_ids::uuid[])
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: ClearRoomGroupMembers :exec
+DELETE FROM room_group_members WHERE group_id = $1;
+
+-- name: GetRoomGroupMembers :many
+-- Members who have since left the room are not returned.
+SELECT gm.group_id, gm.user_id FROM room_group_members AS gm
+JOIN room_groups AS g ON g.id = gm.group_id
+JOIN room_members AS rm ON rm.room_id = g.room_id AND rm.user_id = gm.user_id
+WHERE g.room_id = $1;
+
+-- name: ResolveMentions :many
+-- Expands mentioned names into the IDs of room members, matching usernames
+-- and the room's groups in a single round trip.
+SELECT u.id FROM users AS u
+JOIN room_members AS rm ON rm.user_id = u.id AND rm.room_id = @room_id
+WHERE lower(u.username) = ANY(@names::text[])
+UNION
+SELECT gm.user_id FROM room_groups AS g
+JOIN room_group_members AS gm ON gm.group_id = g.id
+JOIN room_members AS rm ON rm.room_id = g.room_id AND rm.user_id = gm.user_id
+WHERE g.room_id = @room_id AND g.name = ANY(@names::text[]);
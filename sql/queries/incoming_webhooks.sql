@@ -0,0 +1,18 @@
+-- name: CreateIncomingWebhook :one
+INSERT INTO room_incoming_webhooks (id, room_id, name, token_hash, created_by) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CountRoomIncomingWebhooks :one
+SELECT COUNT(*) FROM room_incoming_webhooks WHERE room_id = $1;
+
+-- name: GetRoomIncomingWebhooks :many
+SELECT * FROM room_incoming_webhooks WHERE room_id = $1 ORDER BY created_at ASC, id ASC;
+
+-- name: GetIncomingWebhookByTokenHash :one
+SELECT * FROM room_incoming_webhooks WHERE token_hash = $1;
+
+-- name: TouchIncomingWebhook :exec
+UPDATE room_incoming_webhooks SET last_used_at = NOW() WHERE id = $1;
+
+-- name: DeleteIncomingWebhook :execrows
+DELETE FROM room_incoming_webhooks WHERE id = $1 AND room_id = $2;
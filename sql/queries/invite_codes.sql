@@ -0,0 +1,32 @@
+-- name: CreateRoomInviteCode :one
+INSERT INTO room_invite_codes (code, room_id, created_by, expires_at, max_uses)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetRoomInviteCode :one
+SELECT * FROM room_invite_codes WHERE code = $1;
+
+-- name: GetRoomInviteCodes :many
+-- Lists the room's usable codes, only those created by @created_by when set.
+SELECT * FROM room_invite_codes
+WHERE room_id = @room_id
+  AND (sqlc.narg(created_by)::uuid IS NULL OR created_by = sqlc.narg(created_by)::uuid)
+  AND expires_at > NOW()
+  AND (max_uses IS NULL OR uses < max_uses)
+ORDER BY created_at DESC;
+
+-- name: UseRoomInviteCode :one
+-- Counts a use of the code, unless it has expired or been used up.
+UPDATE room_invite_codes SET uses = uses + 1
+WHERE code = $1 AND expires_at > NOW() AND (max_uses IS NULL OR uses < max_uses)
+RETURNING *;
+
+-- name: DeleteRoomInviteCode :execrows
+DELETE FROM room_invite_codes WHERE room_id = $1 AND code = $2;
+
+-- name: PruneRoomInviteCodes :execrows
+DELETE FROM room_invite_codes
+WHERE expires_at < NOW() OR (max_uses IS NOT NULL AND uses >= max_uses);
+
+-- name: DeleteRoomInviteCodes :execrows
+DELETE FROM room_invite_codes WHERE room_id = $1;
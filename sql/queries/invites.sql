@@ -0,0 +1,25 @@
+-- name: CreateRoomInvite :one
+-- Inviting someone who already has an invitation renews it.
+INSERT INTO room_invites (id, room_id, user_id, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (room_id, user_id) DO UPDATE
+SET id = EXCLUDED.id, invited_by = EXCLUDED.invited_by, created_at = NOW(), expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: GetRoomInviteByID :one
+SELECT * FROM room_invites WHERE id = $1;
+
+-- name: GetRoomInviteForUser :one
+SELECT * FROM room_invites WHERE room_id = $1 AND user_id = $2;
+
+-- name: DeleteRoomInvite :execrows
+DELETE FROM room_invites WHERE id = $1;
+
+-- name: GetUserInvites :many
+SELECT i.*, r.name AS room_name FROM room_invites AS i
+JOIN rooms AS r ON r.id = i.room_id
+WHERE i.user_id = $1 AND i.expires_at > NOW()
+ORDER BY i.created_at DESC;
+
+-- name: DeleteRoomInvites :execrows
+DELETE FROM room_invites WHERE room_id = $1;
@@ -0,0 +1,60 @@
+-- name: EnqueueJob :exec
+INSERT INTO jobs (id, type, payload, max_attempts) VALUES ($1, $2, $3, $4);
+
+-- name: ClaimJobs :many
+-- Claims up to max_jobs due jobs, along with running jobs whose lease ran
+-- out, and counts the attempt. Jobs claimed by another worker are skipped.
+UPDATE jobs SET status = 'running', attempts = attempts + 1, updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE (status = 'pending' AND run_at <= NOW())
+       OR (status = 'running' AND updated_at < @lease_expired_before::timestamptz)
+    ORDER BY run_at
+    LIMIT @max_jobs
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs SET status = 'succeeded', last_error = NULL, updated_at = NOW()
+WHERE id = $1 AND status = 'running';
+
+-- name: RescheduleJob :exec
+UPDATE jobs SET status = 'pending', last_error = $2, run_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'running';
+
+-- name: FailJob :exec
+UPDATE jobs SET status = 'failed', last_error = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'running';
+
+-- name: GetJob :one
+SELECT * FROM jobs WHERE id = $1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(type)::text IS NULL OR type = sqlc.narg(type)::text)
+ORDER BY created_at DESC, id DESC
+LIMIT @max_results;
+
+-- name: RetryJob :one
+-- Queues a failed or cancelled job to run right away, with all its attempts
+-- available again.
+UPDATE jobs SET status = 'pending', attempts = 0, run_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status IN ('failed', 'cancelled')
+RETURNING *;
+
+-- name: CancelJob :one
+UPDATE jobs SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: GetJobQueueDepth :many
+-- Counts jobs by type and status, with when the longest waiting one was due.
+SELECT type, status, COUNT(*) AS count, MIN(run_at)::timestamptz AS oldest_run_at
+FROM jobs
+GROUP BY type, status
+ORDER BY type, status;
+
+-- name: DeleteFinishedJobs :execrows
+DELETE FROM jobs WHERE status IN ('succeeded', 'cancelled') AND updated_at < $1;
@@ -0,0 +1,13 @@
+-- name: CreateJoinRequest :exec
+-- Asking again while a request is pending changes nothing.
+INSERT INTO room_join_requests (room_id, user_id) VALUES ($1, $2)
+ON CONFLICT (room_id, user_id) DO NOTHING;
+
+-- name: DeleteJoinRequest :execrows
+DELETE FROM room_join_requests WHERE room_id = $1 AND user_id = $2;
+
+-- name: GetJoinRequests :many
+SELECT u.* FROM users AS u
+JOIN room_join_requests AS jr ON jr.user_id = u.id
+WHERE jr.room_id = $1
+ORDER BY jr.created_at ASC;
@@ -0,0 +1,30 @@
+-- name: CreateLegalHold :one
+INSERT INTO legal_holds (id, user_id, room_id, reason, placed_by) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetLegalHolds :many
+SELECT * FROM legal_holds ORDER BY created_at DESC;
+
+-- name: DeleteLegalHold :one
+DELETE FROM legal_holds WHERE id = $1
+RETURNING *;
+
+-- name: IsUserContentHeld :one
+-- Reports whether the user is under legal hold, or sent messages to a room
+-- that is.
+SELECT EXISTS (SELECT 1 FROM legal_holds AS h WHERE h.user_id = @user_id::uuid)
+    OR EXISTS (
+        SELECT 1 FROM messages AS m
+        JOIN legal_holds AS h ON h.room_id = m.room_id
+        WHERE m.sender_id = @user_id::uuid
+    ) AS held;
+
+-- name: IsRoomContentHeld :one
+-- Reports whether the room is under legal hold, or holds messages of a user
+-- who is.
+SELECT EXISTS (SELECT 1 FROM legal_holds AS h WHERE h.room_id = @room_id::uuid)
+    OR EXISTS (
+        SELECT 1 FROM messages AS m
+        JOIN legal_holds AS h ON h.user_id = m.sender_id
+        WHERE m.room_id = @room_id::uuid
+    ) AS held;
@@ -0,0 +1,9 @@
+-- name: GetRoomMemberChanges :many
+-- Lists the changes to a room's members after since_version, oldest first.
+SELECT version, user_id, action, role FROM room_member_changes
+WHERE room_id = @room_id AND version > @since_version
+ORDER BY version ASC
+LIMIT @max_results;
+
+-- name: PruneRoomMemberChanges :execrows
+DELETE FROM room_member_changes WHERE changed_at < $1;
@@ -0,0 +1,78 @@
+-- name: AppendMessageEvent :one
+INSERT INTO message_events (id, message_id, room_id, actor_id, type, data) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: AppendMessageDeletions :many
+-- Logs a message.deleted event for each of the room's messages given by ID
+-- or sent within the time range, and returns the deleted messages' IDs.
+-- Messages under legal hold are skipped.
+INSERT INTO message_events (id, message_id, room_id, actor_id, type, data)
+SELECT gen_random_uuid(), m.id, m.room_id, @actor_id::uuid, 'message.deleted', @data::jsonb
+FROM messages AS m
+WHERE m.room_id = @room_id
+  AND (sqlc.narg(ids)::uuid[] IS NULL OR m.id = ANY(sqlc.narg(ids)::uuid[]))
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR m.created_at >= sqlc.narg(start_time)::timestamptz)
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR m.created_at < sqlc.narg(end_time)::timestamptz)
+  AND NOT message_under_legal_hold(m.room_id, m.sender_id)
+ORDER BY m.seq
+RETURNING message_id;
+
+-- name: GetMessageEvents :many
+SELECT * FROM message_events
+WHERE message_id = $1
+ORDER BY position ASC;
+
+-- name: ListMessageEvents :many
+-- Pages through the log in order. Events appended after @settled_before are
+-- left out, so that readers never move past an event whose transaction has
+-- yet to commit.
+SELECT * FROM message_events
+WHERE position > @after AND created_at < @settled_before
+ORDER BY position ASC
+LIMIT @max_events;
+
+-- name: BackfillMessageEvents :execrows
+-- Logs every stored message without a message.created event as one holding
+-- the message as it is now, so that the log covers messages stored before
+-- event sourcing was turned on. The projection skips them.
+INSERT INTO message_events (id, message_id, room_id, actor_id, type, data, created_at)
+SELECT gen_random_uuid(), m.id, m.room_id, m.sender_id, 'message.created', jsonb_build_object(
+    'recipient_id', m.recipient_id,
+    'content', m.content,
+    'metadata', m.metadata,
+    'kind', m.kind,
+    'quoted_message_id', m.quoted_message_id,
+    'mentions', to_jsonb(m.mentions),
+    'priority', m.priority,
+    'client_msg_id', m.client_msg_id,
+    'edited_at', m.edited_at
+), m.created_at
+FROM messages AS m
+WHERE NOT EXISTS (
+    SELECT 1 FROM message_events AS e
+    WHERE e.message_id = m.id AND e.type = 'message.created'
+)
+ORDER BY m.seq;
+
+-- name: BackfillAnnotationEvents :execrows
+-- Logs every annotation without a message.annotated event, like
+-- BackfillMessageEvents does for messages.
+INSERT INTO message_events (id, message_id, room_id, actor_id, type, data, created_at)
+SELECT gen_random_uuid(), a.message_id, m.room_id, a.author_id, 'message.annotated', jsonb_build_object(
+    'annotation_id', a.id,
+    'kind', a.kind,
+    'data', a.data
+), a.created_at
+FROM message_annotations AS a
+JOIN messages AS m ON m.id = a.message_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM message_events AS e
+    WHERE e.message_id = a.message_id AND e.type = 'message.annotated'
+      AND e.data->>'annotation_id' = a.id::text
+)
+ORDER BY a.created_at, a.id;
+
+-- name: ForgetMessageEvents :execrows
+-- Deletes the events of the room's messages that are no longer stored.
+DELETE FROM message_events AS e
+WHERE e.room_id = $1
+  AND NOT EXISTS (SELECT 1 FROM messages AS m WHERE m.id = e.message_id);
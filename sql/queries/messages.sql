@@ -0,0 +1,63 @@
+-- name: CreateMessage :one
+INSERT INTO messages (id, room_id, sender_id, recipient_id, content, metadata, kind, quoted_message_id, mentions, priority, client_msg_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
+
+-- name: GetRoomMessagesAfterSeq :many
+SELECT * FROM messages
+WHERE room_id = @room_id AND seq > @seq
+  AND (recipient_id IS NULL OR recipient_id = @user_id::uuid OR sender_id = @user_id::uuid)
+ORDER BY seq ASC
+LIMIT @max_messages;
+
+-- name: GetRoomMessagesSince :many
+SELECT * FROM messages
+WHERE room_id = @room_id AND created_at > @since
+  AND (recipient_id IS NULL OR recipient_id = @user_id::uuid OR sender_id = @user_id::uuid)
+ORDER BY seq ASC
+LIMIT @max_messages;
+
+-- name: GetMessageByID :one
+SELECT * FROM messages WHERE id = $1;
+
+-- name: GetMessageByClientMsgID :one
+SELECT * FROM messages WHERE room_id = $1 AND sender_id = $2 AND client_msg_id = $3;
+
+-- name: GetMessagesByIDs :many
+SELECT * FROM messages WHERE id = ANY(@ids::uuid[]);
+
+-- name: GetLatestRoomMessages :many
+SELECT sqlc.embed(m),
+       u.username AS sender_username, u.avatar_url AS sender_avatar_url, u.display_name AS sender_display_name
+FROM messages AS m
+JOIN users AS u ON u.id = m.sender_id
+WHERE m.room_id = @room_id
+  AND (sqlc.narg(before_seq)::bigint IS NULL OR m.seq < sqlc.narg(before_seq)::bigint)
+  AND (m.recipient_id IS NULL OR m.recipient_id = @user_id::uuid OR m.sender_id = @user_id::uuid)
+ORDER BY m.seq DESC
+LIMIT @max_messages;
+
+-- name: DeleteRoomMessagesByIDs :many
+-- Messages under legal hold are skipped.
+DELETE FROM messages WHERE room_id = @room_id AND id = ANY(@ids::uuid[])
+  AND NOT message_under_legal_hold(room_id, sender_id)
+RETURNING id;
+
+-- name: DeleteRoomMessagesBetween :many
+-- Messages under legal hold are skipped.
+DELETE FROM messages
+WHERE room_id = @room_id AND created_at >= @start_time AND created_at < @end_time
+  AND NOT message_under_legal_hold(room_id, sender_id)
+RETURNING id;
+
+-- name: CountUrgentMessagesSince :one
+SELECT COUNT(*) FROM messages
+WHERE sender_id = $1 AND priority = 'urgent' AND created_at > $2;
+
+-- name: DeleteRoomMessages :execrows
+DELETE FROM messages WHERE room_id = $1;
+
+-- name: GetRecentRoomMessages :many
+-- Lists the room's latest messages, direct messages included, newest first.
+SELECT * FROM messages
+WHERE room_id = @room_id
+ORDER BY seq DESC
+LIMIT @max_messages;
@@ -0,0 +1,15 @@
+-- name: SetRoomNotificationLevel :exec
+INSERT INTO room_notification_settings (room_id, user_id, level)
+VALUES ($1, $2, $3)
+ON CONFLICT (room_id, user_id) DO UPDATE SET level = EXCLUDED.level, updated_at = NOW();
+
+-- name: GetRoomNotificationLevel :one
+SELECT level FROM room_notification_settings WHERE room_id = $1 AND user_id = $2;
+
+-- name: GetRoomNotificationLevels :many
+-- Lists the members of the room who chose a notification level.
+SELECT user_id, level FROM room_notification_settings WHERE room_id = $1;
+
+-- name: GetUserNotificationLevels :many
+-- Lists the rooms the user chose a notification level for.
+SELECT room_id, level FROM room_notification_settings WHERE user_id = $1 ORDER BY room_id;
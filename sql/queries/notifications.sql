@@ -0,0 +1,18 @@
+-- name: CreateNotifications :exec
+INSERT INTO notifications (user_id, room_id, message_id, kind, actor_id)
+SELECT unnest(@user_ids::uuid[]), @room_id::uuid, @message_id::uuid, @kind::text, @actor_id::uuid;
+
+-- name: GetUserFeed :many
+-- Notifications from rooms the user has since left are not returned.
+SELECT sqlc.embed(n), sqlc.embed(m), r.name AS room_name,
+       a.username AS actor_username, a.avatar_url AS actor_avatar_url, a.display_name AS actor_display_name
+FROM notifications AS n
+JOIN messages AS m ON m.id = n.message_id
+JOIN rooms AS r ON r.id = n.room_id
+LEFT JOIN users AS a ON a.id = n.actor_id
+WHERE n.user_id = @user_id
+  AND EXISTS (SELECT 1 FROM room_members AS rm WHERE rm.room_id = n.room_id AND rm.user_id = @user_id)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (n.created_at, n.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY n.created_at DESC, n.id DESC
+LIMIT @max_results;
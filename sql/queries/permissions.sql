@@ -0,0 +1,7 @@
+-- name: GetRoomPermissions :one
+SELECT settings FROM room_permissions WHERE room_id = $1;
+
+-- name: SetRoomPermissions :exec
+INSERT INTO room_permissions (room_id, settings, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (room_id) DO UPDATE SET settings = EXCLUDED.settings, updated_by = EXCLUDED.updated_by, updated_at = NOW();
@@ -0,0 +1,21 @@
+-- name: PinMessage :execrows
+INSERT INTO room_pins (message_id, room_id, pinned_by) VALUES ($1, $2, $3)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: UnpinMessage :execrows
+DELETE FROM room_pins WHERE message_id = $1;
+
+-- name: CountRoomPins :one
+SELECT COUNT(*) FROM room_pins WHERE room_id = $1;
+
+-- name: GetRoomPins :many
+-- Lists the room's pinned messages, most recently pinned first.
+SELECT sqlc.embed(m),
+       p.pinned_by, p.pinned_at
+FROM room_pins AS p
+JOIN messages AS m ON m.id = p.message_id
+WHERE p.room_id = $1
+ORDER BY p.pinned_at DESC, m.id DESC;
+
+-- name: DeleteRoomPins :execrows
+DELETE FROM room_pins WHERE room_id = $1;
@@ -0,0 +1,26 @@
+-- name: CreatePoll :one
+INSERT INTO polls (id, message_id, room_id, creator_id, question) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CreatePollOption :one
+INSERT INTO poll_options (id, poll_id, position, text) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetPollByID :one
+SELECT * FROM polls WHERE id = $1;
+
+-- name: GetPollByMessageID :one
+SELECT * FROM polls WHERE message_id = $1;
+
+-- name: GetPollResults :many
+SELECT o.id, o.position, o.text, COUNT(v.user_id) AS votes
+FROM poll_options AS o
+LEFT JOIN poll_votes AS v ON v.option_id = o.id
+WHERE o.poll_id = $1
+GROUP BY o.id
+ORDER BY o.position ASC;
+
+-- name: UpsertPollVote :exec
+INSERT INTO poll_votes (poll_id, option_id, user_id) VALUES ($1, $2, $3)
+ON CONFLICT (poll_id, user_id) DO UPDATE SET option_id = EXCLUDED.option_id, voted_at = NOW();
+
+-- name: ClosePoll :one
+UPDATE polls SET closed_at = NOW() WHERE id = $1 AND closed_at IS NULL RETURNING *;
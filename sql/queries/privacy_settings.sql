@@ -0,0 +1,7 @@
+-- name: SetUserTypingIndicators :exec
+INSERT INTO user_privacy_settings (user_id, typing_indicators)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET typing_indicators = EXCLUDED.typing_indicators, updated_at = NOW();
+
+-- name: GetUserTypingIndicators :one
+SELECT typing_indicators FROM user_privacy_settings WHERE user_id = $1;
@@ -0,0 +1,47 @@
+-- name: CreateMessagePurge :one
+INSERT INTO message_purges (id, room_id, requested_by, user_id, start_time, end_time, total)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetMessagePurge :one
+SELECT * FROM message_purges WHERE id = $1 AND room_id = $2;
+
+-- name: StartMessagePurge :one
+-- Finished purges match nothing, so a job run again after its purge finished
+-- does no harm.
+UPDATE message_purges SET status = 'running', updated_at = NOW()
+WHERE id = $1 AND status <> 'succeeded'
+RETURNING *;
+
+-- name: AddMessagePurgeProgress :exec
+UPDATE message_purges SET deleted = deleted + @deleted, last_error = NULL, updated_at = NOW()
+WHERE id = @id;
+
+-- name: SetMessagePurgeError :exec
+UPDATE message_purges SET last_error = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: FinishMessagePurge :one
+UPDATE message_purges SET status = 'succeeded', last_error = NULL, updated_at = NOW(), finished_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CountPurgeableMessages :one
+SELECT COUNT(*) FROM messages
+WHERE room_id = @room_id
+  AND (sqlc.narg(sender_id)::uuid IS NULL OR sender_id = sqlc.narg(sender_id)::uuid)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time)::timestamptz)
+  AND created_at < @end_time
+  AND NOT message_under_legal_hold(room_id, sender_id);
+
+-- name: GetPurgeableMessageIDs :many
+-- Lists the next batch of a purge's messages, oldest first. Messages under
+-- legal hold are left out, so purges skip them.
+SELECT id FROM messages
+WHERE room_id = @room_id
+  AND (sqlc.narg(sender_id)::uuid IS NULL OR sender_id = sqlc.narg(sender_id)::uuid)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time)::timestamptz)
+  AND created_at < @end_time
+  AND NOT message_under_legal_hold(room_id, sender_id)
+ORDER BY seq
+LIMIT @max_messages;
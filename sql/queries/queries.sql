@@ -7,41 +7,206 @@ SELECT * FROM users WHERE username = $1;
 -- name: GetUserByID :one
 SELECT * FROM users WHERE id = $1;
 
+-- name: GetUserLanguages :many
+-- Returns the preferred languages of the users, for the warm cache.
+SELECT id, preferred_language FROM users WHERE id = ANY(@ids::uuid[]);
+
 -- name: GetAllUsers :many
+-- Deprecated: returns the whole table; use ListUsers.
 SELECT * FROM users;
 
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE (sqlc.narg(q)::text IS NULL OR username ILIKE '%' || sqlc.narg(q)::text || '%')
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at > sqlc.narg(created_after)::timestamptz)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (created_at, id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT @max_results;
+
 -- name: UpdateUser :one
-UPDATE users SET username = $2, password = $3 WHERE id = $1 RETURNING *;
+UPDATE users SET username = $2, password = $3, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1;
 
 -- name: CreateRoom :one
-INSERT INTO rooms (id, name, owner_id) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO rooms (id, name, owner_id, visibility, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: ListRooms :many
+-- Lists a page of the rooms visible to a user, newest, most recently active
+-- or largest first. Private rooms are only listed to their owners and
+-- members, and group conversations never. The cursor holds the sort key and ID of the previous page's last
+-- room: cursor_time for created_at and last_activity, cursor_count for
+-- member_count.
+SELECT sqlc.embed(listed), listed.member_count, listed.last_activity_at, listed.tags
+FROM (
+    SELECT r.*,
+        (SELECT COUNT(*) FROM room_members WHERE room_id = r.id) AS member_count,
+        COALESCE(r.last_message_at, r.created_at) AS last_activity_at,
+        ARRAY(SELECT tag FROM room_tags WHERE room_id = r.id ORDER BY tag)::text[] AS tags
+    FROM rooms AS r
+    WHERE r.kind = 'room'
+      AND (r.visibility = 'public' OR r.owner_id = @user_id
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = @user_id))
+      AND (sqlc.narg(owned_by)::uuid IS NULL OR r.owner_id = sqlc.narg(owned_by)::uuid
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = sqlc.narg(owned_by)::uuid AND role = 'owner'))
+      AND (sqlc.narg(member_of)::uuid IS NULL
+           OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = sqlc.narg(member_of)::uuid))
+      AND (sqlc.narg(visibility)::text IS NULL OR r.visibility = sqlc.narg(visibility)::text)
+      AND (sqlc.narg(tag)::text IS NULL
+           OR EXISTS (SELECT 1 FROM room_tags WHERE room_id = r.id AND tag = sqlc.narg(tag)::text))
+) AS listed
+WHERE sqlc.narg(cursor_id)::uuid IS NULL OR CASE @sort::text
+    WHEN 'member_count' THEN (listed.member_count, listed.id) < (sqlc.narg(cursor_count)::bigint, sqlc.narg(cursor_id)::uuid)
+    WHEN 'last_activity' THEN (listed.last_activity_at, listed.id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::uuid)
+    ELSE (listed.created_at, listed.id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::uuid)
+END
+ORDER BY
+    CASE WHEN @sort::text = 'member_count' THEN listed.member_count END DESC,
+    CASE WHEN @sort::text = 'last_activity' THEN listed.last_activity_at END DESC,
+    listed.created_at DESC,
+    listed.id DESC
+LIMIT @max_results;
 
--- name: GetRooms :many
-SELECT * FROM rooms ORDER BY created_at DESC;
+-- name: SearchRooms :many
+-- Finds the rooms visible to a user whose names contain the query or are
+-- similar to it, or whose descriptions contain it. Name matches come first,
+-- best match first. Group conversations are never found. @pattern is the
+-- query as a LIKE pattern, escaped with \.
+SELECT * FROM rooms
+WHERE kind = 'room'
+  AND (visibility = 'public' OR owner_id = @user_id
+       OR EXISTS (SELECT 1 FROM room_members WHERE room_id = rooms.id AND user_id = @user_id))
+  AND (name ILIKE @pattern::text ESCAPE '\' OR name % @q::text OR description ILIKE @pattern::text ESCAPE '\')
+ORDER BY similarity(name, @q::text) DESC, created_at DESC, id DESC
+LIMIT @max_results;
 
 -- name: GetRoomByID :one
 SELECT * FROM rooms WHERE id = $1;
 
 -- name: UpdateRoom :one
-UPDATE rooms SET name = $2 WHERE id = $1 RETURNING *;
+-- Fields passed as NULL keep their current value.
+UPDATE rooms SET name = COALESCE(sqlc.narg(name), name), topic = COALESCE(sqlc.narg(topic), topic),
+    description = COALESCE(sqlc.narg(description), description), version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
+
+-- name: SetRoomAvatar :one
+UPDATE rooms SET avatar_url = $2, avatar_key = $3, version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
 
 -- name: DeleteRoom :exec
 DELETE FROM rooms WHERE id = $1;
 
+-- name: GetExpiredRooms :many
+-- Lists the rooms whose expiry has passed, longest expired first. Rooms under
+-- legal hold, or holding messages that are, are kept until it is released.
+SELECT id FROM rooms
+WHERE expires_at <= NOW()
+  AND NOT EXISTS (SELECT 1 FROM messages AS m WHERE m.room_id = rooms.id AND message_under_legal_hold(m.room_id, m.sender_id))
+  AND NOT EXISTS (SELECT 1 FROM legal_holds AS h WHERE h.room_id = rooms.id)
+ORDER BY expires_at
+LIMIT @max_rooms;
+
 -- name: SearchUsers :many
 SELECT * FROM users WHERE username ILIKE $1;
 
 -- name: AddRoomMember :exec
-INSERT INTO room_members (room_id, user_id) VALUES ($1, $2);
+-- New members start with everything sent before they joined read.
+INSERT INTO room_members (room_id, user_id, last_read_seq)
+SELECT $1, $2, COALESCE(MAX(seq), 0) FROM messages WHERE room_id = $1;
 
 -- name: RemoveRoomMember :exec
 DELETE FROM room_members WHERE room_id = $1 AND user_id = $2;
 
+-- name: DeleteRoomMembers :execrows
+DELETE FROM room_members WHERE room_id = $1;
+
 -- name: IsRoomMember :one
-SELECT EXISTS(SELECT 1 FROM room_members WHERE room_id = $1 AND user_id = $2);
+SELECT EXISTS(SELECT 1 FROM room_members WHERE room_id = $1 AND user_id = $2)
+    AND NOT EXISTS(
+        SELECT 1 FROM room_bans
+        WHERE room_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+    );
+
+-- name: GetRoomMemberIDs :many
+-- Lists the room's members the way IsRoomMember sees them: banned users are
+-- left out.
+SELECT rm.user_id FROM room_members AS rm
+WHERE rm.room_id = $1
+  AND NOT EXISTS(
+      SELECT 1 FROM room_bans AS b
+      WHERE b.room_id = rm.room_id AND b.user_id = rm.user_id AND (b.expires_at IS NULL OR b.expires_at > NOW())
+  );
+
+-- name: GetUserRoomIDs :many
+SELECT room_id FROM room_members WHERE user_id = $1;
+
+-- name: GetRoomMates :many
+-- Returns those of the users who share a room with the user.
+SELECT DISTINCT other.user_id FROM room_members AS mine
+JOIN room_members AS other ON other.room_id = mine.room_id
+WHERE mine.user_id = @user_id AND other.user_id = ANY(@user_ids::uuid[]);
 
 -- name: GetRoomMembers :many
-SELECT u.id, u.username FROM users AS u JOIN room_members AS rm ON u.id = rm.user_id WHERE rm.room_id = $1;
\ No newline at end of file
+SELECT u.id, u.username, u.display_name FROM users AS u JOIN room_members AS rm ON u.id = rm.user_id WHERE rm.room_id = $1;
+
+-- name: GetRoomMembersPage :many
+SELECT u.id, u.username, u.is_bot, u.display_name, rm.role, rm.joined_at FROM room_members AS rm
+JOIN users AS u ON u.id = rm.user_id
+WHERE rm.room_id = @room_id
+  AND (sqlc.narg(cursor_joined_at)::timestamptz IS NULL
+       OR (rm.joined_at, rm.user_id) > (sqlc.narg(cursor_joined_at)::timestamptz, sqlc.narg(cursor_user_id)::uuid))
+ORDER BY rm.joined_at ASC, rm.user_id ASC
+LIMIT @max_results;
+
+-- name: SetUserPreferredLanguage :one
+UPDATE users SET preferred_language = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
+
+-- name: SetUserDisplayName :one
+UPDATE users SET display_name = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
+
+-- name: SetRoomSettings :one
+UPDATE rooms SET max_message_size = $2, allow_urgent = $3, visibility = $4, stats_enabled = $5, room_mention_role = $6, summaries_enabled = $7, public_readable = $8, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
+
+-- name: GetRoomsOwnedBy :many
+SELECT * FROM rooms WHERE owner_id = $1 ORDER BY created_at ASC FOR UPDATE;
+
+-- name: GetRoomSuccessor :one
+-- Picks who inherits a room from its owner: other owners first, then
+-- moderators, administrators and other members, longest-standing first.
+-- Bots never inherit rooms.
+SELECT rm.user_id FROM room_members AS rm
+JOIN users AS u ON u.id = rm.user_id
+WHERE rm.room_id = @room_id AND rm.user_id <> @owner_id AND NOT u.is_bot
+ORDER BY rm.role = 'owner' DESC, rm.role = 'moderator' DESC, u.is_admin DESC, rm.joined_at ASC
+LIMIT 1;
+
+-- name: TransferRoomOwnership :one
+UPDATE rooms SET owner_id = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ArchiveRoom :one
+UPDATE rooms SET owner_id = $2, archived_at = NOW(), version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserContentUsage :one
+-- Sums up what a user has posted, for the account deletion report. Storage
+-- counts the bytes of message content and metadata.
+SELECT COUNT(*) AS message_count,
+       COUNT(*) FILTER (WHERE kind = 'poll') AS poll_count,
+       COALESCE(SUM(octet_length(content) + octet_length(metadata::text)), 0)::bigint AS storage_bytes
+FROM messages
+WHERE sender_id = $1;
@@ -0,0 +1,18 @@
+-- name: AddMessageReaction :execrows
+INSERT INTO message_reactions (message_id, user_id, emoji) VALUES ($1, $2, $3)
+ON CONFLICT (message_id, user_id, emoji) DO NOTHING;
+
+-- name: RemoveMessageReaction :execrows
+DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3;
+
+-- name: GetMessageReactionCounts :many
+SELECT message_id, emoji, COUNT(*) AS count
+FROM message_reactions
+WHERE message_id = ANY(@message_ids::uuid[])
+GROUP BY message_id, emoji
+ORDER BY message_id, MIN(created_at) ASC, emoji ASC;
+
+-- name: GetUserMessageReactions :many
+SELECT emoji FROM message_reactions
+WHERE message_id = $1 AND user_id = $2
+ORDER BY created_at ASC;
@@ -0,0 +1,12 @@
+-- name: CreateMessageReport :one
+INSERT INTO message_reports (id, message_id, room_id, reporter_id, reason) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetRoomReports :many
+-- Lists a room's reports, newest first, with the reported message.
+SELECT r.id, r.message_id, r.room_id, r.reporter_id, r.reason, r.created_at,
+       m.sender_id AS message_sender_id, m.content AS message_content
+FROM message_reports AS r
+JOIN messages AS m ON m.id = r.message_id
+WHERE r.room_id = $1
+ORDER BY r.created_at DESC
+LIMIT $2;
@@ -0,0 +1,22 @@
+-- name: GetRoomsWithRetention :many
+SELECT * FROM rooms
+WHERE (retention_days IS NOT NULL OR retention_max_messages IS NOT NULL)
+  AND NOT retention_hold;
+
+-- name: SetRoomRetention :one
+UPDATE rooms SET retention_days = $2, retention_max_messages = $3, retention_hold = $4, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING *;
+
+-- name: PurgeMessagesBefore :execrows
+-- Messages under legal hold are kept.
+DELETE FROM messages WHERE room_id = $1 AND created_at < $2
+  AND NOT message_under_legal_hold(room_id, sender_id);
+
+-- name: PurgeMessagesBeyondCount :execrows
+-- Deletes all but the newest @keep messages of the room. Messages under legal
+-- hold are kept on top of those.
+DELETE FROM messages
+WHERE room_id = @room_id
+  AND seq <= (SELECT m.seq FROM messages AS m WHERE m.room_id = @room_id ORDER BY m.seq DESC OFFSET @keep LIMIT 1)
+  AND NOT message_under_legal_hold(room_id, sender_id);
@@ -0,0 +1,14 @@
+-- name: EditMessage :one
+-- Replaces a message's content, keeping the previous content as a revision.
+WITH revision AS (
+    INSERT INTO message_revisions (id, message_id, content, edited_by)
+    SELECT @revision_id, m.id, m.content, @edited_by FROM messages AS m WHERE m.id = @id
+)
+UPDATE messages SET content = @content, edited_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: GetMessageRevisions :many
+SELECT * FROM message_revisions
+WHERE message_id = $1
+ORDER BY created_at ASC, id ASC;
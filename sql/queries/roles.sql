@@ -0,0 +1,11 @@
+-- name: GetRoomMemberRole :one
+SELECT role FROM room_members WHERE room_id = $1 AND user_id = $2;
+
+-- name: SetRoomMemberRole :execrows
+UPDATE room_members SET role = $3 WHERE room_id = $1 AND user_id = $2;
+
+-- name: GetRoomMembersByRole :many
+SELECT u.* FROM users AS u
+JOIN room_members AS rm ON rm.user_id = u.id
+WHERE rm.room_id = $1 AND rm.role = $2
+ORDER BY rm.joined_at ASC;
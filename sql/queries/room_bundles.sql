@@ -0,0 +1,15 @@
+-- name: GetRoomMessagesForExport :many
+-- Returns the room's latest text messages to everyone, newest first, with
+-- their senders' usernames. Direct messages within the room are left out.
+SELECT sqlc.embed(m), u.username AS sender_username
+FROM messages AS m
+JOIN users AS u ON u.id = m.sender_id
+WHERE m.room_id = @room_id AND m.recipient_id IS NULL AND m.kind = 'text'
+ORDER BY m.seq DESC
+LIMIT @max_messages;
+
+-- name: GetUsernamesByIDs :many
+SELECT id, username FROM users WHERE id = ANY(@ids::uuid[]);
+
+-- name: GetUsersByUsernames :many
+SELECT id, username FROM users WHERE username = ANY(@usernames::text[]);
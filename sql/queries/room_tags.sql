@@ -0,0 +1,22 @@
+-- name: GetRoomTags :many
+SELECT tag FROM room_tags WHERE room_id = $1 ORDER BY tag;
+
+-- name: DeleteRoomTags :exec
+DELETE FROM room_tags WHERE room_id = $1;
+
+-- name: AddRoomTags :exec
+INSERT INTO room_tags (room_id, tag)
+SELECT @room_id::uuid, unnest(@tags::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetTagDirectory :many
+-- Lists the tags of the rooms visible to a user with how many of those rooms
+-- carry each, most used first. Group conversations are left out.
+SELECT t.tag, COUNT(*) AS room_count
+FROM room_tags AS t
+JOIN rooms AS r ON r.id = t.room_id
+WHERE r.kind = 'room'
+  AND (r.visibility = 'public' OR r.owner_id = @user_id
+       OR EXISTS (SELECT 1 FROM room_members WHERE room_id = r.id AND user_id = @user_id))
+GROUP BY t.tag
+ORDER BY room_count DESC, t.tag ASC;
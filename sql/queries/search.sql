@@ -0,0 +1,17 @@
+-- name: SearchRoomMessages :many
+-- Finds the room's messages visible to a user whose content matches a web
+-- search style query, such as `deploy -staging "release notes"`, best match
+-- first, then newest. Full-text search does not index emoji, so the @emoji
+-- in the query must also appear in the content as written; a query of emoji
+-- alone matches on them only.
+SELECT sqlc.embed(m),
+       u.username AS sender_username, u.avatar_url AS sender_avatar_url, u.display_name AS sender_display_name
+FROM messages AS m
+JOIN users AS u ON u.id = m.sender_id
+WHERE m.room_id = @room_id
+  AND (to_tsvector('simple', m.content) @@ websearch_to_tsquery('simple', @q::text)
+       OR cardinality(@emoji::text[]) > 0 AND numnode(websearch_to_tsquery('simple', @q::text)) = 0)
+  AND NOT EXISTS (SELECT 1 FROM unnest(@emoji::text[]) AS e(emoji) WHERE strpos(m.content, e.emoji) = 0)
+  AND (m.recipient_id IS NULL OR m.recipient_id = @user_id::uuid OR m.sender_id = @user_id::uuid)
+ORDER BY ts_rank(to_tsvector('simple', m.content), websearch_to_tsquery('simple', @q::text)) DESC, m.seq DESC
+LIMIT @max_results;
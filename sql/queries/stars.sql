@@ -0,0 +1,18 @@
+-- name: StarMessage :exec
+INSERT INTO saved_messages (user_id, message_id) VALUES ($1, $2)
+ON CONFLICT (user_id, message_id) DO NOTHING;
+
+-- name: UnstarMessage :exec
+DELETE FROM saved_messages WHERE user_id = $1 AND message_id = $2;
+
+-- name: GetStarredMessages :many
+SELECT sqlc.embed(m),
+       s.created_at AS starred_at
+FROM saved_messages AS s
+JOIN messages AS m ON m.id = s.message_id
+WHERE s.user_id = @user_id
+  AND EXISTS (SELECT 1 FROM room_members AS rm WHERE rm.room_id = m.room_id AND rm.user_id = @user_id)
+  AND (sqlc.narg(cursor_starred_at)::timestamptz IS NULL
+       OR (s.created_at, m.id) < (sqlc.narg(cursor_starred_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY s.created_at DESC, m.id DESC
+LIMIT @max_results;
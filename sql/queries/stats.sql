@@ -0,0 +1,75 @@
+-- name: GetStatsEnabledRooms :many
+SELECT id FROM rooms WHERE stats_enabled AND archived_at IS NULL;
+
+-- name: GetRoomHourlyActivity :many
+-- Counts a room's messages since a time by hour of the day, in UTC. Direct
+-- messages are private and not counted.
+SELECT EXTRACT(HOUR FROM created_at AT TIME ZONE 'UTC')::int AS hour, COUNT(*) AS messages
+FROM messages
+WHERE room_id = $1 AND recipient_id IS NULL AND created_at >= @since
+GROUP BY hour
+ORDER BY hour;
+
+-- name: GetRoomActiveDays :many
+-- Lists the UTC days since a time on which the room had messages, latest
+-- first.
+SELECT DISTINCT (created_at AT TIME ZONE 'UTC')::date AS day
+FROM messages
+WHERE room_id = $1 AND recipient_id IS NULL AND created_at >= @since
+ORDER BY day DESC;
+
+-- name: GetRoomTopSenders :many
+SELECT m.sender_id, u.username, COUNT(*) AS messages
+FROM messages AS m
+JOIN users AS u ON u.id = m.sender_id
+WHERE m.room_id = $1 AND m.recipient_id IS NULL AND m.created_at >= @since AND NOT u.is_bot
+GROUP BY m.sender_id, u.username
+ORDER BY messages DESC, u.username ASC
+LIMIT @max_results;
+
+-- name: UpsertRoomStats :exec
+INSERT INTO room_stats (room_id, stats, computed_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (room_id) DO UPDATE SET stats = EXCLUDED.stats, computed_at = EXCLUDED.computed_at;
+
+-- name: GetRoomStats :one
+SELECT * FROM room_stats WHERE room_id = $1;
+
+-- name: UpsertRoomDailyStats :execrows
+-- Counts the room's messages and the members who sent them, bots left out,
+-- per UTC day. Only the days since the last one aggregated, that one included
+-- as it may have been partial, are counted again; for a room without any,
+-- the days since @earliest.
+INSERT INTO room_daily_stats (room_id, day, messages, active_members)
+SELECT m.room_id, (m.created_at AT TIME ZONE 'UTC')::date AS day,
+       COUNT(*), COUNT(DISTINCT m.sender_id) FILTER (WHERE NOT u.is_bot)
+FROM messages AS m
+JOIN users AS u ON u.id = m.sender_id
+WHERE m.room_id = @room_id AND m.recipient_id IS NULL
+  AND m.created_at >= COALESCE(
+      (SELECT MAX(day) FROM room_daily_stats WHERE room_id = @room_id),
+      @earliest::date
+  )::timestamp AT TIME ZONE 'UTC'
+GROUP BY m.room_id, day
+ON CONFLICT (room_id, day) DO UPDATE
+SET messages = EXCLUDED.messages, active_members = EXCLUDED.active_members;
+
+-- name: GetRoomDailyStats :many
+-- Lists the room's daily stats from @start_day through @end_day, oldest first.
+SELECT * FROM room_daily_stats
+WHERE room_id = @room_id AND day >= @start_day AND day <= @end_day
+ORDER BY day;
+
+-- name: GetHotRooms :many
+-- Picks the unarchived rooms with messages since @since, busiest first by
+-- their daily stats over that time, then most recently active.
+SELECT r.id FROM rooms AS r
+JOIN room_message_counts AS c ON c.room_id = r.id
+LEFT JOIN (
+    SELECT room_id, SUM(messages) AS messages FROM room_daily_stats
+    WHERE day >= (@since::timestamptz AT TIME ZONE 'UTC')::date
+    GROUP BY room_id
+) AS s ON s.room_id = r.id
+WHERE r.archived_at IS NULL AND c.last_message_at >= @since
+ORDER BY COALESCE(s.messages, 0) DESC, c.last_message_at DESC
+LIMIT @max_rooms;
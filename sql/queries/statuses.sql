@@ -0,0 +1,13 @@
+-- name: SetUserStatus :one
+INSERT INTO user_statuses (user_id, emoji, text, expires_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET emoji = EXCLUDED.emoji, text = EXCLUDED.text, expires_at = EXCLUDED.expires_at, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteUserStatus :exec
+DELETE FROM user_statuses WHERE user_id = $1;
+
+-- name: GetUserStatuses :many
+-- Expired statuses are treated as cleared.
+SELECT * FROM user_statuses
+WHERE user_id = ANY(@user_ids::uuid[]) AND (expires_at IS NULL OR expires_at > NOW());
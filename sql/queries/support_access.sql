@@ -0,0 +1,11 @@
+-- name: GrantSupportAccess :one
+INSERT INTO support_access_grants (user_id, expires_at) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET granted_at = NOW(), expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: GetSupportAccess :one
+-- Expired grants are not returned.
+SELECT * FROM support_access_grants WHERE user_id = $1 AND expires_at > NOW();
+
+-- name: RevokeSupportAccess :execrows
+DELETE FROM support_access_grants WHERE user_id = $1;
@@ -0,0 +1,36 @@
+-- name: GetUserUnreads :many
+-- Counts, for every room the user is a member of, the messages from others
+-- after the user's read cursor and how many of them mention the user.
+SELECT rm.room_id, rm.last_read_seq,
+       COUNT(m.id) AS unread_count,
+       COUNT(m.id) FILTER (WHERE rm.user_id = ANY(m.mentions)) AS mention_count
+FROM room_members AS rm
+LEFT JOIN messages AS m
+  ON m.room_id = rm.room_id AND m.seq > rm.last_read_seq AND m.sender_id <> rm.user_id
+  AND (m.recipient_id IS NULL OR m.recipient_id = rm.user_id)
+WHERE rm.user_id = $1
+GROUP BY rm.room_id, rm.last_read_seq
+ORDER BY rm.room_id;
+
+-- name: GetRoomUnreads :many
+-- Like GetUserUnreads, for one room and the given members of it.
+SELECT rm.user_id, rm.last_read_seq,
+       COUNT(m.id) AS unread_count,
+       COUNT(m.id) FILTER (WHERE rm.user_id = ANY(m.mentions)) AS mention_count
+FROM room_members AS rm
+LEFT JOIN messages AS m
+  ON m.room_id = rm.room_id AND m.seq > rm.last_read_seq AND m.sender_id <> rm.user_id
+  AND (m.recipient_id IS NULL OR m.recipient_id = rm.user_id)
+WHERE rm.room_id = @room_id AND rm.user_id = ANY(@user_ids::uuid[])
+GROUP BY rm.user_id, rm.last_read_seq;
+
+-- name: MarkRoomRead :one
+-- Moves the member's read cursor forward to seq, or to the room's latest
+-- message when seq is null. The cursor never moves back.
+UPDATE room_members
+SET last_read_seq = GREATEST(last_read_seq, COALESCE(
+    sqlc.narg(seq)::bigint,
+    (SELECT MAX(m.seq) FROM messages AS m WHERE m.room_id = @room_id),
+    0))
+WHERE room_id = @room_id AND user_id = @user_id
+RETURNING last_read_seq;
@@ -0,0 +1,20 @@
+-- name: CreateRoomWebhook :one
+INSERT INTO room_webhooks (id, room_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetRoomWebhooks :many
+SELECT * FROM room_webhooks WHERE room_id = $1 ORDER BY created_at ASC;
+
+-- name: GetRoomWebhookByID :one
+SELECT * FROM room_webhooks WHERE id = $1;
+
+-- name: UpdateRoomWebhook :one
+UPDATE room_webhooks SET url = $2, events = $3 WHERE id = $1
+RETURNING *;
+
+-- name: DeleteRoomWebhook :exec
+DELETE FROM room_webhooks WHERE id = $1;
+
+-- name: GetSubscribedWebhooks :many
+SELECT * FROM room_webhooks WHERE room_id = @room_id AND @event::text = ANY(events);
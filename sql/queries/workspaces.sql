@@ -0,0 +1,16 @@
+-- name: CreateWorkspace :one
+INSERT INTO workspaces (id, name, domain, logo_url, primary_color, accent_color)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetWorkspaces :many
+SELECT * FROM workspaces ORDER BY domain;
+
+-- name: UpdateWorkspace :one
+UPDATE workspaces
+SET name = $2, domain = $3, logo_url = $4, primary_color = $5, accent_color = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWorkspace :execrows
+DELETE FROM workspaces WHERE id = $1;